@@ -4,7 +4,7 @@ startingpath="$(pwd)"
 basepath="$(cd "$(dirname "$0")" && pwd)"
 cd "$basepath/../Source"
 
-Sources="actutils.go automaticcomponenttoolkit.go buildbindingcdynamic.go buildbindingcpp.go buildbindinggo.go buildbindingnode.go buildbindingpascal.go buildbindingpython.go buildimplementationcpp.go buildimplementationpascal.go componentdefinition.go componentdiff.go languagewriter.go languagec.go languagepascal.go"
+Sources="actutils.go automaticcomponenttoolkit.go buildbindingcdynamic.go buildbindingcpp.go buildbindingcsharp.go buildbindingfortran.go buildbindinggo.go buildbindingjava.go buildbindinglua.go buildbindingnode.go buildbindingpascal.go buildbindingpython.go buildbindingrust.go buildbindingswift.go buildimplementationcpp.go buildimplementationfortran.go buildimplementationgo.go buildimplementationpascal.go componentdefinition.go componentdefinitionformats.go componentdefinitionschema.go componentdiff.go externalgenerator.go generators.go languagewriter.go languagec.go languagepascal.go templates.go yaml.go"
 GOARCH="amd64"
 
 echo "Build act.exe"
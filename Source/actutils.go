@@ -34,11 +34,75 @@ SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 package main
 
 import (
+	"fmt"
+	"io/ioutil"
+	"log"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
 // FileExists returns true if and only if the file in a given path exists
 func FileExists(path string) (bool) {
 	_, err := os.Stat(path); 
 	return !os.IsNotExist(err);
-}
\ No newline at end of file
+}
+
+// ForceRecreation specifies which kinds of files, that are usually generated only once, are recreated on every run
+type ForceRecreation struct {
+	Stubs bool
+	Examples bool
+	Projects bool
+}
+
+// ParseForceRecreation parses a comma separated list of artifact kinds ("all", "stubs", "examples", "projects")
+func ParseForceRecreation(kinds string, forceRecreation *ForceRecreation) (error) {
+	for _, kind := range strings.Split(kinds, ",") {
+		switch (strings.ToLower(strings.TrimSpace(kind))) {
+			case "all":
+				forceRecreation.Stubs = true
+				forceRecreation.Examples = true
+				forceRecreation.Projects = true
+			case "stub", "stubs":
+				forceRecreation.Stubs = true
+			case "example", "examples":
+				forceRecreation.Examples = true
+			case "project", "projects":
+				forceRecreation.Projects = true
+			default:
+				return fmt.Errorf ("unknown kind of artifact \"%s\" to recreate", kind)
+		}
+	}
+	return nil
+}
+
+// backupFolderName is the name of the folder that holds the backups of overwritten files
+const backupFolderName = ".act_backup"
+
+// backupTimestamp names the backup folder of this run of ACT
+var backupTimestamp = time.Now().Format("20060102-150405")
+
+// BackupFile copies a file that is about to be overwritten into the folder ".act_backup/<timestamp>"
+// next to it. An existing backup is never overwritten.
+func BackupFile(path string) (error) {
+	if !FileExists(path) {
+		return nil
+	}
+	backupFolder := filepath.Join(filepath.Dir(path), backupFolderName, backupTimestamp)
+	backupPath := filepath.Join(backupFolder, filepath.Base(path))
+	if FileExists(backupPath) {
+		log.Printf("Keeping existing backup \"%s\"", backupPath)
+		return nil
+	}
+	log.Printf("Backing up \"%s\" to \"%s\"", path, backupPath)
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(backupFolder, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(backupPath, bytes, 0644)
+}
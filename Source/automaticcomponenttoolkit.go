@@ -45,9 +45,10 @@ import (
 const (
 	eACTModeGenerate = 0
 	eACTModeDiff = 1
+	eACTModeConvert = 2
 )
 
-func readComponentDefinition(FileName string, ACTVersion string) (ComponentDefinition, error) {
+func readComponentDefinition(FileName string, ACTVersion string, strict bool) (ComponentDefinition, error) {
 	var component ComponentDefinition
 
 	file, err := os.Open(FileName);
@@ -59,12 +60,29 @@ func readComponentDefinition(FileName string, ACTVersion string) (ComponentDefin
 	if (err != nil) {
 		return component, err
 	}
-	
-	component.ACTVersion = ACTVersion
-	err = xml.Unmarshal(bytes, &component)
+
+	// files without a known extension are read as XML
+	format, err := GetComponentDefinitionFormat(FileName)
+	if (err != nil) {
+		format = eComponentDefinitionFormatXML
+	}
+	if (format == eComponentDefinitionFormatXML) {
+		issues, err := CheckComponentDefinitionXMLStructure(bytes)
+		if (err != nil) {
+			return component, err
+		}
+		for _, issue := range issues {
+			log.Printf ("Warning: %s: %s", FileName, issue);
+		}
+		if (strict && (len(issues) > 0)) {
+			return component, fmt.Errorf ("%s contains %d unknown elements, attributes or texts", FileName, len(issues))
+		}
+	}
+	component, err = UnmarshalComponentDefinition(bytes, format)
 	if (err != nil) {
 		return component, err
 	}
+	component.ACTVersion = ACTVersion
 	return component, nil
 }
 
@@ -72,14 +90,32 @@ func main () {
 	ACTVersion := "1.3.2"
 	fmt.Fprintln(os.Stdout, "Automatic Component Toolkit v" + ACTVersion)
 	if (len (os.Args) < 2) {
-		log.Fatal ("Please run with the Interface Description XML as command line parameter.");
-		log.Fatal ("To specify a path for the generated source code use the optional flag \"-o ABSOLUTE_PATH_TO_OUTPUT_FOLDER\"");
-		log.Fatal ("To create a diff between two versions of an Interface Description XML use the optional flagg \"-d OTHER_IDL_FILE\"");
+		log.Printf ("Please run with the Interface Description XML, JSON or YAML file as command line parameter.");
+		log.Printf ("To specify a path for the generated source code use the optional flag \"-o ABSOLUTE_PATH_TO_OUTPUT_FOLDER\"");
+		log.Printf ("To create a diff between two versions of an Interface Description XML use the optional flagg \"-d OTHER_IDL_FILE\"");
+		log.Printf ("To convert the Interface Description into XML, JSON or YAML use the optional flag \"-convert OUTPUT_FILE\" (.xml, .json, .yaml)");
+		log.Printf ("To treat unknown elements and attributes in the Interface Description XML as errors use the optional flag \"-strict\"");
+		log.Printf ("To export the schema of the Interface Description run with \"-schema ACT.xsd\" or \"-schema ACT.schema.json\"");
+		log.Printf ("To recreate stubs, examples and project files that are usually generated only once use the optional flag \"-f\"");
+		log.Printf ("To recreate only some kinds of these files use the optional flag \"-force stubs,examples,projects\"");
+		log.Printf ("To override the built-in templates of the generated code use the optional flag \"-templates TEMPLATE_FOLDER\"");
+		log.Fatal ("To generate code for a language with an external executable use the optional flag \"-plugin LANGUAGE=EXECUTABLE\"");
 	}
 	if os.Args[1] == "-v" {
 		fmt.Fprintln(os.Stdout, "Version: "+ACTVersion)
 		return
 	}
+	if os.Args[1] == "-schema" {
+		if (len(os.Args) < 3) {
+			log.Fatal ("Missing value for command line flag \"-schema\"");
+		}
+		log.Printf ("Exporting schema to \"%s\"", os.Args[2]);
+		err := ExportComponentDefinitionSchema(os.Args[2])
+		if (err != nil) {
+			log.Fatal (err);
+		}
+		return
+	}
 	log.Printf ("---------------------------------------\n");
 
 	mode := eACTModeGenerate
@@ -88,22 +124,70 @@ func main () {
 		log.Fatal(err)
 	}
 	diffFile := ""
-	if (len (os.Args) >= 4) {
-		if os.Args[2] == "-o" {
-			outfolderBase = os.Args[3]
+	convertFile := ""
+	var forceRecreation ForceRecreation
+	templateFolder := ""
+	strict := false
+	for argIdx := 2; argIdx < len(os.Args); argIdx++ {
+		switch (os.Args[argIdx]) {
+			case "-o", "-d", "-convert", "-force", "-templates", "-plugin": {
+				if (argIdx + 1 >= len(os.Args)) {
+					log.Fatalf ("Missing value for command line flag \"%s\"", os.Args[argIdx]);
+				}
+			}
 		}
 
-		if os.Args[2] == "-d" {
-			diffFile = os.Args[3]
-			mode = eACTModeDiff
+		switch (os.Args[argIdx]) {
+			case "-o": {
+				argIdx++
+				outfolderBase = os.Args[argIdx]
+			}
+			case "-d": {
+				argIdx++
+				diffFile = os.Args[argIdx]
+				mode = eACTModeDiff
+			}
+			case "-convert": {
+				argIdx++
+				convertFile = os.Args[argIdx]
+				mode = eACTModeConvert
+			}
+			case "-strict": {
+				strict = true
+			}
+			case "-f": {
+				err = ParseForceRecreation("all", &forceRecreation)
+			}
+			case "-force": {
+				argIdx++
+				err = ParseForceRecreation(os.Args[argIdx], &forceRecreation)
+			}
+			case "-templates": {
+				argIdx++
+				templateFolder = os.Args[argIdx]
+			}
+			case "-plugin": {
+				argIdx++
+				err = RegisterExternalGenerator(os.Args[argIdx])
+			}
+			default:
+				log.Fatalf ("Unknown command line flag \"%s\"", os.Args[argIdx]);
+		}
+		if (err != nil) {
+			log.Fatal (err);
 		}
 	}
 	if (mode == eACTModeGenerate) {
 		log.Printf("Output directory: " + outfolderBase)
 	}
+
+	err = LoadTemplates(templateFolder)
+	if (err != nil) {
+		log.Fatal (err);
+	}
 	
 	log.Printf ("Loading Component Description File" );
-	component, err := readComponentDefinition(os.Args[1], ACTVersion)
+	component, err := readComponentDefinition(os.Args[1], ACTVersion, strict)
 	if (err != nil) {
 		log.Fatal (err);
 	}
@@ -114,9 +198,18 @@ func main () {
 		log.Fatal (err);
 	}
 
+	if (mode == eACTModeConvert) {
+		log.Printf ("Converting Component Description to \"%s\"", convertFile);
+		err = WriteComponentDefinition(component, convertFile)
+		if (err != nil) {
+			log.Fatal (err);
+		}
+		return
+	}
+
 	if (mode == eACTModeDiff) {
 		log.Printf ("Loading Component Description File to compare to" );
-		componentB, err := readComponentDefinition(diffFile, ACTVersion)
+		componentB, err := readComponentDefinition(diffFile, ACTVersion, strict)
 		if (err != nil) {
 			log.Fatal (err);
 		}
@@ -147,9 +240,31 @@ func main () {
 
 
 
+	bindingGeneratorList := make([]Generator, len(component.BindingList.Bindings))
+	for bindingindex, binding := range component.BindingList.Bindings {
+		bindingGeneratorList[bindingindex], err = GetBindingGenerator(binding.Language)
+		if (err != nil) {
+			log.Fatal (err);
+		}
+		err = bindingGeneratorList[bindingindex].Validate(component, bindingOptions(binding, forceRecreation))
+		if (err != nil) {
+			log.Fatal (err);
+		}
+	}
+	implementationGeneratorList := make([]Generator, len(component.ImplementationList.Implementations))
+	for implementationindex, implementation := range component.ImplementationList.Implementations {
+		implementationGeneratorList[implementationindex], err = GetImplementationGenerator(implementation.Language)
+		if (err != nil) {
+			log.Fatal (err);
+		}
+		err = implementationGeneratorList[implementationindex].Validate(component, implementationOptions(implementation, forceRecreation))
+		if (err != nil) {
+			log.Fatal (err);
+		}
+	}
+
 	outputFolder := path.Join(outfolderBase, component.NameSpace + "_component");
 	outputFolderBindings := path.Join(outputFolder, "Bindings")
-	outputFolderExamples := path.Join(outputFolder, "Examples")
 	outputFolderImplementations := path.Join(outputFolder, "Implementations")
 	
 	err  = os.MkdirAll(outputFolder, os.ModePerm);
@@ -164,7 +279,10 @@ func main () {
 	if err != nil {
 		log.Fatal (err);
 	}
-	licenseFile.WritePlainLicenseHeader(component, "", false);
+	err = licenseFile.WritePlainLicenseHeader(component, "", false)
+	if err != nil {
+		log.Fatal (err);
+	}
 
 	if (len(component.BindingList.Bindings) > 0) {
 		err  = os.MkdirAll(outputFolderBindings, os.ModePerm);
@@ -172,184 +290,11 @@ func main () {
 			log.Fatal (err);
 		}
 	}
-	for bindingindex := 0; bindingindex < len(component.BindingList.Bindings); bindingindex++ {
-		binding := component.BindingList.Bindings[bindingindex];
-		indentString := getIndentationString(binding.Indentation)
+	for bindingindex, binding := range component.BindingList.Bindings {
 		log.Printf ("Exporting Interface Binding for Languge \"%s\"", binding.Language);
-		
-		switch (binding.Language) {
-			case "C": {
-				outputFolderBindingC := outputFolderBindings + "/C";
-
-				err  = os.MkdirAll(outputFolderBindingC, os.ModePerm);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-				
-				err = BuildBindingC(component, outputFolderBindingC)
-				if (err != nil) {
-					log.Fatal (err);
-				}
-			}
-
-			case "CDynamic": {
-				outputFolderBindingCDynamic := outputFolderBindings + "/CDynamic";
-
-				err  = os.MkdirAll(outputFolderBindingCDynamic, os.ModePerm);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-				
-				CTypesHeaderName := path.Join(outputFolderBindingCDynamic, component.BaseName + "_types.h");
-				err = CreateCTypesHeader (component, CTypesHeaderName);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-				
-				err = BuildBindingCDynamic(component, outputFolderBindingCDynamic, indentString);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-			}
-
-			case "CppDynamic": {
-				outputFolderBindingCppDynamic := outputFolderBindings + "/CppDynamic";
-				err  = os.MkdirAll(outputFolderBindingCppDynamic, os.ModePerm);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-				outputFolderExampleCppDynamic := outputFolderExamples + "/CppDynamic";
-				err  = os.MkdirAll(outputFolderExampleCppDynamic, os.ModePerm);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-
-				CTypesHeaderName := path.Join(outputFolderBindingCppDynamic, component.BaseName + "_types.h");
-				err = CreateCTypesHeader (component, CTypesHeaderName);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-				
-				err = BuildBindingCppDynamic(component, outputFolderBindingCppDynamic, outputFolderExampleCppDynamic, indentString);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-			}
-
-			case "Cpp": {
-				outputFolderBindingCpp := outputFolderBindings + "/Cpp";
-				err  = os.MkdirAll(outputFolderBindingCpp, os.ModePerm);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-
-				outputFolderExampleCPP := outputFolderExamples + "/CPP";
-				err  = os.MkdirAll(outputFolderExampleCPP, os.ModePerm);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-
-				CTypesHeaderName := path.Join(outputFolderBindingCpp, component.BaseName + "_types.h");
-				err = CreateCTypesHeader (component, CTypesHeaderName);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-				
-				CHeaderName := path.Join(outputFolderBindingCpp, component.BaseName + ".h");
-				err = CreateCHeader (component, CHeaderName);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-				
-				err = BuildBindingCPP(component, outputFolderBindingCpp, outputFolderExampleCPP, indentString);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-			}
-
-			case "Go": {
-				outputFolderBindingGo := outputFolderBindings + "/Go";
-
-				err  = os.MkdirAll(outputFolderBindingGo, os.ModePerm);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-
-				err := BuildBindingGo(component, outputFolderBindingGo);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-			}
-
-			case "Node": {
-				outputFolderBindingNode := outputFolderBindings + "/NodeJS";
-
-				err  = os.MkdirAll(outputFolderBindingNode, os.ModePerm);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-				
-				CTypesHeaderName := path.Join(outputFolderBindingNode, component.BaseName + "_types.h");
-				err = CreateCTypesHeader (component, CTypesHeaderName);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-				
-				err = BuildBindingCDynamic(component, outputFolderBindingNode, indentString);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-				
-				err := BuildBindingNode(component, outputFolderBindingNode, indentString);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-			}
-			
-			case "Pascal": {
-				outputFolderBindingPascal := outputFolderBindings + "/Pascal";
-				err  = os.MkdirAll(outputFolderBindingPascal, os.ModePerm);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-
-				outputFolderExamplePascal := outputFolderExamples + "/Pascal";
-				err  = os.MkdirAll(outputFolderExamplePascal, os.ModePerm);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-				
-				err = BuildBindingPascalDynamic(component, outputFolderBindingPascal, outputFolderExamplePascal, indentString);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-			}
-
-			case "Python": {
-				outputFolderBindingPython := outputFolderBindings + "/Python";
-				err  = os.MkdirAll(outputFolderBindingPython, os.ModePerm);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-
-				outputFolderExamplePython := outputFolderExamples + "/Python";
-				err  = os.MkdirAll(outputFolderExamplePython, os.ModePerm);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-				
-				err = BuildBindingPythonDynamic(component, outputFolderBindingPython, outputFolderExamplePython, indentString);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-			}
-			
-			case"Fortran": {
-				log.Printf ("Interface binding for language \"%s\" is not yet supported.", binding.Language);
-			}
-
-			default:
-				log.Fatal ("Unknown binding export");
+		err = bindingGeneratorList[bindingindex].Generate(component, outputFolder, bindingOptions(binding, forceRecreation))
+		if (err != nil) {
+			log.Fatal (err);
 		}
 	}
 
@@ -359,72 +304,11 @@ func main () {
 			log.Fatal (err);
 		}
 	}
-	for implementationindex := 0; implementationindex < len(component.ImplementationList.Implementations); implementationindex++ {
-		implementation := component.ImplementationList.Implementations[implementationindex];
+	for implementationindex, implementation := range component.ImplementationList.Implementations {
 		log.Printf ("Exporting Implementation Interface for Language \"%s\"", implementation.Language);
-		
-		switch (implementation.Language) {
-			case "Cpp": {
-				outputFolderImplementationProject := outputFolderImplementations + "/Cpp";
-				outputFolderImplementationCpp := outputFolderImplementations + "/Cpp/Interfaces";
-				outputFolderImplementationCppStub := outputFolderImplementations + "/Cpp/Stub";
-
-				err  = os.MkdirAll(outputFolderImplementationCpp, os.ModePerm);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-
-				err  = os.MkdirAll(outputFolderImplementationCppStub, os.ModePerm);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-
-				CTypesHeaderName := path.Join(outputFolderImplementationCpp, component.BaseName + "_types.h");
-				err = CreateCTypesHeader (component, CTypesHeaderName);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-				
-				CHeaderName := path.Join(outputFolderImplementationCpp, component.BaseName + ".h");
-				err = CreateCHeader (component, CHeaderName);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-				
-				err = BuildImplementationCPP(component, outputFolderImplementationCpp, outputFolderImplementationCppStub,
-					outputFolderImplementationProject, implementation);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-			}
-
-			case "Pascal": {
-				outputFolderImplementationProject := outputFolderImplementations + "/Pascal";
-				outputFolderImplementationPascal := outputFolderImplementations + "/Pascal/Interfaces";
-				outputFolderImplementationPascalStub := outputFolderImplementations + "/Pascal/Stub";
-
-				err  = os.MkdirAll(outputFolderImplementationPascal, os.ModePerm);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-
-				err  = os.MkdirAll(outputFolderImplementationPascalStub, os.ModePerm);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-				
-				err = BuildImplementationPascal(component, outputFolderImplementationPascal, outputFolderImplementationPascalStub,
-					outputFolderImplementationProject, implementation);
-				if (err != nil) {
-					log.Fatal (err);
-				}
-			}
-			
-			case "Fortran": {
-				log.Printf ("Implementation in language \"%s\" is not yet supported.", implementation.Language);
-			}
-			default:
-				log.Fatal ("Unknown export");
+		err = implementationGeneratorList[implementationindex].Generate(component, outputFolder, implementationOptions(implementation, forceRecreation))
+		if (err != nil) {
+			log.Fatal (err);
 		}
 	}
 
@@ -56,9 +56,12 @@ func BuildBindingCDynamic(component ComponentDefinition, outputFolder string, in
 	if err != nil {
 		return err;
 	}
-	dynhfile.WriteCLicenseHeader(component,
+	err = dynhfile.WriteCLicenseHeader(component,
 		fmt.Sprintf("This is an autogenerated plain C Header file in order to allow an easy\n use of %s", libraryname),
 		true)
+	if err != nil {
+		return err
+	}
 	err = buildDynamicCHeader(component, dynhfile, namespace, baseName, false)
 	if err != nil {
 		return err;
@@ -70,9 +73,12 @@ func BuildBindingCDynamic(component ComponentDefinition, outputFolder string, in
 	if err != nil {
 		return err;
 	}
-	dyncppfile.WriteCLicenseHeader(component,
+	err = dyncppfile.WriteCLicenseHeader(component,
 		fmt.Sprintf("This is an autogenerated plain C Header file in order to allow an easy\n use of %s", libraryname),
 		true)
+	if err != nil {
+		return err
+	}
 	
 	err = buildDynamicCppImplementation(component, dyncppfile, namespace, baseName)
 	if err != nil {
@@ -83,12 +89,10 @@ func BuildBindingCDynamic(component ComponentDefinition, outputFolder string, in
 }
 
 func buildDynamicCHeader(component ComponentDefinition, w LanguageWriter, NameSpace string, BaseName string, headerOnly bool) error {
-	w.Writeln("#ifndef __%s_DYNAMICHEADER", strings.ToUpper(NameSpace))
-	w.Writeln("#define __%s_DYNAMICHEADER", strings.ToUpper(NameSpace))
-	w.Writeln("")
-
-	w.Writeln("#include \"%s_types.h\"", BaseName)
-	w.Writeln("")
+	err := w.WriteTemplate("cdynamicheader.begin", NewTemplateData(component))
+	if err != nil {
+		return err
+	}
 
 	for i := 0; i < len(component.Classes); i++ {
 		class := component.Classes[i]
@@ -157,10 +161,7 @@ func buildDynamicCHeader(component ComponentDefinition, w LanguageWriter, NameSp
 		w.Writeln("")
 	}
 	
-	w.Writeln("#endif // __%s_DYNAMICHEADER", strings.ToUpper(NameSpace))
-	w.Writeln("")
-
-	return nil
+	return w.WriteTemplate("cdynamicheader.end", NewTemplateData(component))
 }
 
 
@@ -324,10 +325,22 @@ func buildDynamicCppImplementation(component ComponentDefinition, w LanguageWrit
 }
 
 
-func writeDynamicCPPMethodDeclaration(method ComponentDefinitionMethod, w LanguageWriter, NameSpace string, ClassName string, isGlobal bool) error {
+func writeDynamicCPPMethodDeclaration(method ComponentDefinitionMethod, w LanguageWriter, NameSpace string, ClassName string, isGlobal bool, cpp17 bool) error {
 	parameters := ""
 	returntype := "void"
 
+	if cpp17 {
+		returntype, parameters, _, _, err := getCPP17Method(method, NameSpace, ClassName, "", "", "", "")
+		if err != nil {
+			return err
+		}
+		if isCPPGetter(method, NameSpace) {
+			returntype = "[[nodiscard]] " + returntype
+		}
+		w.Writeln("    %s %s (%s);", returntype, method.MethodName, parameters);
+		return nil
+	}
+
 	for k := 0; k < len(method.Params); k++ {
 
 		param := method.Params[k]
@@ -381,7 +394,7 @@ func writeDynamicCPPMethodDeclaration(method ComponentDefinitionMethod, w Langua
 
 
 
-func writeDynamicCPPMethod(method ComponentDefinitionMethod, w LanguageWriter, NameSpace string, ClassName string, isGlobal bool, includeComments bool) error {
+func writeDynamicCPPMethod(method ComponentDefinitionMethod, w LanguageWriter, NameSpace string, ClassName string, isGlobal bool, includeComments bool, cpp17 bool) error {
 
 	CMethodName := ""
 	requiresInitCall := false;
@@ -414,6 +427,26 @@ func writeDynamicCPPMethod(method ComponentDefinitionMethod, w LanguageWriter, N
 	cppClassPrefix := "C" + NameSpace
 	cppClassName := cppClassPrefix + ClassName
 
+	if cpp17 {
+		returntype, parameters, commentcodeLines, bodyLines, err := getCPP17Method(method, NameSpace, ClassName, CMethodName, checkErrorCode, callParameters, makeSharedParameter)
+		if err != nil {
+			return err
+		}
+
+		w.Writeln("  ")
+		if (includeComments) {
+			w.Writeln("  /**")
+			w.Writeln("  * %s::%s - %s", cppClassName, method.MethodName, method.MethodDescription)
+			w.Writelns("  ", commentcodeLines)
+			w.Writeln("  */")
+		}
+		w.Writeln("  inline %s %s::%s (%s)", returntype, cppClassName, method.MethodName, parameters)
+		w.Writeln("  {")
+		w.Writelns("    ", bodyLines)
+		w.Writeln("  }")
+		return nil
+	}
+
 	for k := 0; k < len(method.Params); k++ {
 
 		param := method.Params[k]
@@ -446,10 +479,10 @@ func writeDynamicCPPMethod(method ComponentDefinitionMethod, w LanguageWriter, N
 				initCallParameter = callParameter;
 				parameters = parameters + fmt.Sprintf("const %s & %s", cppParamType, variableName);
 			case "handle":
-				functionCodeLines = append(functionCodeLines, fmt.Sprintf("%sHandle h%s = nullptr;", NameSpace, param.ParamName))
-				functionCodeLines = append(functionCodeLines, fmt.Sprintf("if (%s != nullptr) {", variableName))
-				functionCodeLines = append(functionCodeLines, fmt.Sprintf("  h%s = %s->GetHandle ();", param.ParamName, variableName))
-				functionCodeLines = append(functionCodeLines, fmt.Sprintf("};"))
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("%sHandle h%s = nullptr;", NameSpace, param.ParamName))
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("if (%s != nullptr) {", variableName))
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("  h%s = %s->GetHandle ();", param.ParamName, variableName))
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("};"))
 				callParameter = "h" + param.ParamName;
 				initCallParameter = callParameter;
 				parameters = parameters + fmt.Sprintf("%s %s", cppParamType, variableName)
@@ -581,11 +614,7 @@ func writeDynamicCPPMethod(method ComponentDefinitionMethod, w LanguageWriter, N
 		w.Writeln("  */")
 	}
 	
-	if (isGlobal) {	
-		w.Writeln("  inline %s %s::%s (%s)", returntype, cppClassName, method.MethodName, parameters)
-	} else {
-		w.Writeln("  %s %s::%s (%s)", returntype, cppClassName, method.MethodName, parameters)
-	}
+	w.Writeln("  inline %s %s::%s (%s)", returntype, cppClassName, method.MethodName, parameters)
 
 	w.Writeln("  {")
 	w.Writelns("    ", definitionCodeLines)
@@ -601,8 +630,53 @@ func writeDynamicCPPMethod(method ComponentDefinitionMethod, w LanguageWriter, N
 	return nil
 }
 
+// writeDynamicCPPAsyncMethodDeclaration declares the asynchronous variant of a method
+func writeDynamicCPPAsyncMethodDeclaration(component ComponentDefinition, method ComponentDefinitionMethod, w LanguageWriter, NameSpace string, cpp17 bool) error {
+	returntype, parameters, isCancellable, _, err := getCPPAsyncMethod(component, method, NameSpace, "", cpp17)
+	if err != nil {
+		return err
+	}
+	if isCancellable {
+		if parameters != "" {
+			parameters = parameters + ", "
+		}
+		parameters = parameters + fmt.Sprintf("C%sCancellationToken cancellationToken = C%sCancellationToken ()", NameSpace, NameSpace)
+	}
 
-func buildDynamicCppHeader(component ComponentDefinition, w LanguageWriter, NameSpace string, BaseName string) error {
+	w.Writeln("    %s %sAsync (%s);", returntype, method.MethodName, parameters)
+
+	return nil
+}
+
+// writeDynamicCPPAsyncMethod implements the asynchronous variant of a method, which calls the method on a worker thread
+func writeDynamicCPPAsyncMethod(component ComponentDefinition, method ComponentDefinitionMethod, w LanguageWriter, NameSpace string, ClassName string, cpp17 bool) error {
+	// The wrapper is no object of the library, which the call would need to keep
+	objectClassName := ClassName
+	if ClassName == "Wrapper" {
+		objectClassName = ""
+	}
+	returntype, parameters, isCancellable, bodyLines, err := getCPPAsyncMethod(component, method, NameSpace, objectClassName, cpp17)
+	if err != nil {
+		return err
+	}
+	if isCancellable {
+		if parameters != "" {
+			parameters = parameters + ", "
+		}
+		parameters = parameters + fmt.Sprintf("C%sCancellationToken cancellationToken", NameSpace)
+	}
+
+	w.Writeln("  ")
+	w.Writeln("  inline %s C%s%s::%sAsync (%s)", returntype, NameSpace, ClassName, method.MethodName, parameters)
+	w.Writeln("  {")
+	w.Writelns("    ", bodyLines)
+	w.Writeln("  }")
+
+	return nil
+}
+
+
+func buildDynamicCppHeader(component ComponentDefinition, w LanguageWriter, NameSpace string, BaseName string, cpp17 bool) error {
 
 	global := component.Global
 	
@@ -625,6 +699,18 @@ func buildDynamicCppHeader(component ComponentDefinition, w LanguageWriter, Name
 	w.Writeln("#include <memory>")
 	w.Writeln("#include <vector>")
 	w.Writeln("#include <exception>")
+	if component.HasAsyncMethods() {
+		w.Writeln("#include <atomic>")
+		w.Writeln("#include <future>")
+	}
+	if component.HasAsyncMethods() || cpp17 {
+		w.Writeln("#include <tuple>")
+	}
+	if cpp17 {
+		w.Writeln("#include <array>")
+		w.Writeln("#include <optional>")
+		w.Writeln("#include <string_view>")
+	}
 	w.Writeln("")
 
 	w.Writeln("namespace %s {", NameSpace)
@@ -683,6 +769,15 @@ func buildDynamicCppHeader(component ComponentDefinition, w LanguageWriter, Name
 	w.Writeln("  }")
 	w.Writeln("")
 	w.Writeln("  /**")
+	w.Writeln("  * Exception Constructor with the error message of the component.")
+	w.Writeln("  */")
+	w.Writeln("  E%sException (%sResult errorCode, const std::string & errorMessage)", NameSpace, NameSpace)
+	w.Writeln("    : m_errorMessage(errorMessage.empty() ? \"%s Error \" + std::to_string (errorCode) : errorMessage)", NameSpace)
+	w.Writeln("  {")
+	w.Writeln("    m_errorCode = errorCode;")
+	w.Writeln("  }")
+	w.Writeln("")
+	w.Writeln("  /**")
 	w.Writeln("  * Returns error code")
 	w.Writeln("  */")
 	w.Writeln("  %sResult getErrorCode ()", NameSpace)
@@ -703,12 +798,19 @@ func buildDynamicCppHeader(component ComponentDefinition, w LanguageWriter, Name
 
 	w.Writeln("")
 
-	err := writeCPPInputVector(w, NameSpace)
+	err := writeCPPInputVector(w, NameSpace, cpp17)
 	if err != nil {
 		return err
 	}
 	w.Writeln("")
 
+	if component.HasAsyncMethods() {
+		err = writeCPPAsyncSupport(component, w, NameSpace)
+		if err != nil {
+			return err
+		}
+	}
+
 	w.Writeln("/*************************************************************************************************************************")
 	w.Writeln(" Class %sWrapper ", cppClassPrefix)
 	w.Writeln("**************************************************************************************************************************/")
@@ -739,8 +841,25 @@ func buildDynamicCppHeader(component ComponentDefinition, w LanguageWriter, Name
 	
 	w.Writeln("  void CheckError(%sHandle handle, %sResult nResult)", NameSpace, NameSpace)
 	w.Writeln("  {")
-	w.Writeln("    if (nResult != 0) ")
-	w.Writeln("      throw E%sException (nResult);", NameSpace)
+	errorMethod, hasErrorMethod := global.GetErrorMethod()
+	if hasErrorMethod {
+		w.Writeln("    if (nResult != 0) {")
+		w.Writeln("      std::string sErrorMessage;")
+		w.Writeln("      if (m_WrapperTable.m_%s != nullptr) {", errorMethod.MethodName)
+		w.Writeln("        %s_uint32 nNeededChars = 0;", NameSpace)
+		w.Writeln("        bool bHasError = false;")
+		w.Writeln("        if ((m_WrapperTable.m_%s (handle, 0, &nNeededChars, nullptr, &bHasError) == 0) && bHasError) {", errorMethod.MethodName)
+		w.Writeln("          std::vector<char> buffer (nNeededChars + 1);")
+		w.Writeln("          if (m_WrapperTable.m_%s (handle, nNeededChars + 1, &nNeededChars, &buffer[0], &bHasError) == 0)", errorMethod.MethodName)
+		w.Writeln("            sErrorMessage = std::string (&buffer[0]);")
+		w.Writeln("        }")
+		w.Writeln("      }")
+		w.Writeln("      throw E%sException (nResult, sErrorMessage);", NameSpace)
+		w.Writeln("    }")
+	} else {
+		w.Writeln("    if (nResult != 0) ")
+		w.Writeln("      throw E%sException (nResult);", NameSpace)
+	}
 	w.Writeln("  }")
 	w.Writeln("  ")
 	
@@ -749,10 +868,16 @@ func buildDynamicCppHeader(component ComponentDefinition, w LanguageWriter, Name
 	for j := 0; j < len(global.Methods); j++ {
 		method := global.Methods[j]
 
-		err := writeDynamicCPPMethodDeclaration(method, w, NameSpace, "Wrapper", true)
+		err := writeDynamicCPPMethodDeclaration(method, w, NameSpace, "Wrapper", true, cpp17)
 		if err != nil {
 			return err
 		}
+		if method.Async {
+			err = writeDynamicCPPAsyncMethodDeclaration(component, method, w, NameSpace, cpp17)
+			if err != nil {
+				return err
+			}
+		}
 	}
 		
 	w.Writeln("")
@@ -779,7 +904,12 @@ func buildDynamicCppHeader(component ComponentDefinition, w LanguageWriter, Name
 	w.Writeln(" Class %sBaseClass ", cppClassPrefix)
 	w.Writeln("**************************************************************************************************************************/")
 
-	w.Writeln("class %sBaseClass {", cppClassPrefix)
+	if component.HasAsyncMethods() {
+		// The asynchronous calls keep the object alive
+		w.Writeln("class %sBaseClass : public std::enable_shared_from_this<%sBaseClass> {", cppClassPrefix, cppClassPrefix)
+	} else {
+		w.Writeln("class %sBaseClass {", cppClassPrefix)
+	}
 	w.Writeln("protected:")
 	w.Writeln("  /* Wrapper Object that created the class..*/")
 	w.Writeln("  %sWrapper * m_pWrapper;", cppClassPrefix)
@@ -855,10 +985,16 @@ func buildDynamicCppHeader(component ComponentDefinition, w LanguageWriter, Name
 		for j := 0; j < len(class.Methods); j++ {
 			method := class.Methods[j]
 
-			err := writeDynamicCPPMethodDeclaration(method, w, NameSpace, cppClassName, true)
+			err := writeDynamicCPPMethodDeclaration(method, w, NameSpace, cppClassName, true, cpp17)
 			if err != nil {
 				return err
 			}
+			if method.Async {
+				err = writeDynamicCPPAsyncMethodDeclaration(component, method, w, NameSpace, cpp17)
+				if err != nil {
+					return err
+				}
+			}
 		}
 		w.Writeln("};")
 	}
@@ -867,10 +1003,16 @@ func buildDynamicCppHeader(component ComponentDefinition, w LanguageWriter, Name
 	for j := 0; j < len(global.Methods); j++ {
 		method := global.Methods[j]
 
-		err := writeDynamicCPPMethod(method, w, NameSpace, "Wrapper", true, true)
+		err := writeDynamicCPPMethod(method, w, NameSpace, "Wrapper", true, true, cpp17)
 		if err != nil {
 			return err
 		}
+		if method.Async {
+			err = writeDynamicCPPAsyncMethod(component, method, w, NameSpace, "Wrapper", cpp17)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	w.Writeln("")
@@ -914,10 +1056,16 @@ func buildDynamicCppHeader(component ComponentDefinition, w LanguageWriter, Name
 		w.Writeln("   */")
 		for j := 0; j < len(class.Methods); j++ {
 			method := class.Methods[j]
-			err := writeDynamicCPPMethod(method, w, NameSpace, class.ClassName, false, false)
+			err := writeDynamicCPPMethod(method, w, NameSpace, class.ClassName, false, false, cpp17)
 			if err != nil {
 				return err
 			}
+			if method.Async {
+				err = writeDynamicCPPAsyncMethod(component, method, w, NameSpace, class.ClassName, cpp17)
+				if err != nil {
+					return err
+				}
+			}
 		}
 	}
 		
@@ -935,9 +1083,9 @@ func buildDynamicCppHeader(component ComponentDefinition, w LanguageWriter, Name
 
 
 // BuildBindingCppDynamic builds dynamic headeronly C++-bindings of a library's API in form of dynamically loaded functions
-// handles.
-func BuildBindingCppDynamic(component ComponentDefinition, outputFolder string, outputFolderExample string, indentString string) error {
-	forceRecreation := false
+// handles. The cppStandard "17" selects the C++17 style of the methods.
+func BuildBindingCppDynamic(component ComponentDefinition, outputFolder string, outputFolderExample string, indentString string, cppStandard string, forceRecreation ForceRecreation) error {
+	cpp17 := (cppStandard == "17")
 
 	namespace := component.NameSpace;
 	libraryname := component.LibraryName;
@@ -949,9 +1097,12 @@ func BuildBindingCppDynamic(component ComponentDefinition, outputFolder string,
 	if err != nil {
 		return err;
 	}
-	dynhfile.WriteCLicenseHeader(component,
+	err = dynhfile.WriteCLicenseHeader(component,
 		fmt.Sprintf("This is an autogenerated plain C Header file in order to allow an easy\n use of %s", libraryname),
 		true)
+	if err != nil {
+		return err
+	}
 	err = buildDynamicCHeader(component, dynhfile, namespace, baseName, true)
 	if err != nil {
 		return err;
@@ -963,41 +1114,64 @@ func BuildBindingCppDynamic(component ComponentDefinition, outputFolder string,
 	if err != nil {
 		return err;
 	}
-	dynhppfile.WriteCLicenseHeader(component,
+	err = dynhppfile.WriteCLicenseHeader(component,
 		fmt.Sprintf("This is an autogenerated C++ Header file in order to allow an easy\n use of %s", libraryname),
 		true)
-	err = buildDynamicCppHeader(component, dynhppfile, namespace, baseName)
+	if err != nil {
+		return err
+	}
+	err = buildDynamicCppHeader(component, dynhppfile, namespace, baseName, cpp17)
 	if err != nil {
 		return err;
 	}
 	
 	if (len(outputFolderExample) > 0) {
 		DynamicCPPExample := path.Join(outputFolderExample, namespace+"_example"+".cpp");
-		if (forceRecreation || !FileExists(DynamicCPPExample)) {
+		if (forceRecreation.Examples || !FileExists(DynamicCPPExample)) {
+			err = BackupFile(DynamicCPPExample)
+			if err != nil {
+				return err;
+			}
 			log.Printf("Creating \"%s\"", DynamicCPPExample)
 			dyncppexamplefile, err := CreateLanguageFile (DynamicCPPExample, "  ")
 			if err != nil {
 				return err;
 			}
-			dyncppexamplefile.WriteCLicenseHeader(component,
+			err = dyncppexamplefile.WriteCLicenseHeader(component,
 				fmt.Sprintf("This is an autogenerated C++ application that demonstrates the\n usage of the Dynamic C++ bindings of %s", libraryname),
 				true)
-			buildDynamicCppExample(component, dyncppexamplefile, outputFolder)
+			if err != nil {
+				return err
+			}
+			err = buildDynamicCppExample(component, dyncppexamplefile, outputFolder, cpp17)
+			if err != nil {
+				return err;
+			}
 		} else {
 			log.Printf("Omitting recreation of C++Dynamic example file \"%s\"", DynamicCPPExample)
 		}
 
 		DynamicCPPCMake := path.Join(outputFolderExample, "CMakeLists.txt");
-		if (forceRecreation || !FileExists(DynamicCPPCMake)) {
+		if (forceRecreation.Projects || !FileExists(DynamicCPPCMake)) {
+			err = BackupFile(DynamicCPPCMake)
+			if err != nil {
+				return err;
+			}
 			log.Printf("Creating \"%s\"", DynamicCPPCMake)
 			dyncppcmake, err := CreateLanguageFile (DynamicCPPCMake, "	")
 			if err != nil {
 				return err;
 			}
-			dyncppcmake.WriteCMakeLicenseHeader(component,
+			err = dyncppcmake.WriteCMakeLicenseHeader(component,
 				fmt.Sprintf("This is an autogenerated CMake Project that demonstrates the\n usage of the Dynamic C++ bindings of %s", libraryname),
 				true)
-			buildDynamicCppExampleCMake(component, dyncppcmake, outputFolder)
+			if err != nil {
+				return err
+			}
+			err = buildDynamicCppExampleCMake(component, dyncppcmake, outputFolder, cpp17)
+			if err != nil {
+				return err;
+			}
 		} else {
 			log.Printf("Omitting recreation of C++Dynamic example file \"%s\"", DynamicCPPCMake)
 		}
@@ -1007,51 +1181,16 @@ func BuildBindingCppDynamic(component ComponentDefinition, outputFolder string,
 }
 
 
-func buildDynamicCppExample(componentdefinition ComponentDefinition, w LanguageWriter, outputFolder string) error {
-	NameSpace := componentdefinition.NameSpace
-	BaseName := componentdefinition.BaseName
-
-	w.Writeln("#include <iostream>")
-	w.Writeln("#include \"%s_dynamic.hpp\"", strings.ToLower(BaseName) )
-	w.Writeln("")
-	w.Writeln("")
-
-	w.Writeln("int main()")
-	w.Writeln("{")
-	w.Writeln("  try")
-	w.Writeln("  {")
-	w.Writeln("    std::string libpath = (\"\"); // TODO: put the location of the %s-library file here.", NameSpace)
-	w.Writeln("    auto wrapper = %s::C%sWrapper::loadLibrary(libpath + \"/%s.\"); // TODO: add correct suffix of the library", NameSpace, NameSpace, BaseName)
-	w.Writeln("    unsigned int nMajor, nMinor, nMicro;")
-	w.Writeln("    wrapper->GetLibraryVersion(nMajor, nMinor, nMicro);")
-	w.Writeln("    std::cout << \"%s.Version = \" << nMajor << \".\" << nMinor << \".\" << nMicro << std::endl;", NameSpace);
-	w.Writeln("  }")
-	w.Writeln("  catch (std::exception &e)")
-	w.Writeln("  {")
-	w.Writeln("    std::cout << e.what() << std::endl;")
-	w.Writeln("    return 1;")
-	w.Writeln("  }")
-	w.Writeln("  return 0;")
-	w.Writeln("}")
-	w.Writeln("")
-
-	return nil
+func buildDynamicCppExample(componentdefinition ComponentDefinition, w LanguageWriter, outputFolder string, cpp17 bool) error {
+	data := NewTemplateData(componentdefinition)
+	data.Cpp17 = cpp17
+	return w.WriteTemplate("cppdynamicexample", data)
 }
 
-func buildDynamicCppExampleCMake(componentdefinition ComponentDefinition, w LanguageWriter, outputFolder string) error {
-	NameSpace := componentdefinition.NameSpace
-
-	w.Writeln("cmake_minimum_required(VERSION 3.5)")
-
-	w.Writeln("")
-	w.Writeln("project(%sExample_CPPDynamic)", NameSpace)
-	w.Writeln("set (CMAKE_CXX_STANDARD 11)")
-	w.Writeln("add_executable(%sExample_CPPDynamic \"${CMAKE_CURRENT_SOURCE_DIR}/%s_example.cpp\")", NameSpace, NameSpace)
-	w.Writeln("if (UNIX)")
-	w.Writeln("  target_link_libraries(%sExample_CPPDynamic ${CMAKE_DL_LIBS})", NameSpace)
-	w.Writeln("endif (UNIX)")
+func buildDynamicCppExampleCMake(componentdefinition ComponentDefinition, w LanguageWriter, outputFolder string, cpp17 bool) error {
+	data := NewTemplateData(componentdefinition)
+	data.Cpp17 = cpp17
 	// TODO: calculate relative path from ExampleOutputFolder to OUTPUTFOLDER based on CURRENT_SOURCE_DIR
-	outputFolder = strings.Replace(outputFolder, string(filepath.Separator), "/", -1)
-	w.Writeln("target_include_directories(%sExample_CPPDynamic PRIVATE \"%s\")", NameSpace, outputFolder)
-	return nil
+	data.BindingFolder = strings.Replace(outputFolder, string(filepath.Separator), "/", -1)
+	return w.WriteTemplate("cppdynamicexample.cmake", data)
 }
@@ -35,6 +35,7 @@ SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"log"
 	"path"
@@ -43,12 +44,13 @@ import (
 )
 
 // BuildBindingCPP builds C++-bindings of a library's API in form of automatically implemented C++-
-// wrapper classes.
-func BuildBindingCPP(component ComponentDefinition, outputFolder string, outputFolderExample string, indentString string) error {
+// wrapper classes. If headerOnly is set, the implementation is part of the header instead of a separate .cpp file.
+// The cppStandard "17" selects the C++17 style of the methods.
+func BuildBindingCPP(component ComponentDefinition, outputFolder string, outputFolderExample string, indentString string, headerOnly bool, cppStandard string) error {
 	namespace := component.NameSpace;
 	libraryname := component.LibraryName;
 	baseName := component.BaseName;
-	forceRecreation := true
+	cpp17 := (cppStandard == "17")
 
 	CppHeaderName := path.Join(outputFolder, baseName+".hpp");
 	log.Printf("Creating \"%s\"", CppHeaderName)
@@ -56,77 +58,92 @@ func BuildBindingCPP(component ComponentDefinition, outputFolder string, outputF
 	if err != nil {
 		return err
 	}
-
-	CppImplName := path.Join(outputFolder, baseName+".cpp");
-	log.Printf("Creating \"%s\"", CppImplName)
-	cppfile, err :=CreateLanguageFile(CppImplName, indentString)
+	err = WriteLicenseHeader(hppfile.Writer, component,
+		fmt.Sprintf("This is an autogenerated C++ Header file in order to allow an easy use\n of %s", libraryname),
+		true)
 	if err != nil {
 		return err
 	}
 
-	WriteLicenseHeader(hppfile.Writer, component,
-		fmt.Sprintf("This is an autogenerated C++ Header file in order to allow an easy use\n of %s", libraryname),
-		true)
-	WriteLicenseHeader(cppfile.Writer, component,
-		fmt.Sprintf("This is an autogenerated C++ Wrapper Implementation file in order to allow \nan easy use of %s", libraryname),
-		true)
+	var cppfile LanguageWriter
+	if !headerOnly {
+		CppImplName := path.Join(outputFolder, baseName+".cpp");
+		log.Printf("Creating \"%s\"", CppImplName)
+		cppfile, err =CreateLanguageFile(CppImplName, indentString)
+		if err != nil {
+			return err
+		}
+		err = WriteLicenseHeader(cppfile.Writer, component,
+			fmt.Sprintf("This is an autogenerated C++ Wrapper Implementation file in order to allow \nan easy use of %s", libraryname),
+			true)
+		if err != nil {
+			return err
+		}
+	}
 
-	err = buildCPPHeaderAndImplementation(component, hppfile, cppfile, namespace, baseName)
+	err = buildCPPHeaderAndImplementation(component, hppfile, cppfile, namespace, baseName, headerOnly, cpp17)
 	if err != nil {
 		return err
 	}
 
 	if (len(outputFolderExample) > 0) {
+		// The C++ example and its project are always recreated
 		CPPExample := path.Join(outputFolderExample, namespace+"_example"+".cpp");
-		if (forceRecreation || !FileExists(CPPExample)) {
-			log.Printf("Creating \"%s\"", CPPExample)
-			cppexamplefile, err := CreateLanguageFile (CPPExample, "  ")
-			if err != nil {
-				return err;
-			}
-			cppexamplefile.WriteCLicenseHeader(component,
-				fmt.Sprintf("This is an autogenerated C++ application that demonstrates the\n usage of the C++ bindings of %s", libraryname),
-				true)
-			buildCppExample(component, cppexamplefile, outputFolder)
-		} else {
-			log.Printf("Omitting recreation of C++Dynamic example file \"%s\"", CPPExample)
+		log.Printf("Creating \"%s\"", CPPExample)
+		cppexamplefile, err := CreateLanguageFile (CPPExample, "  ")
+		if err != nil {
+			return err;
+		}
+		err = cppexamplefile.WriteCLicenseHeader(component,
+			fmt.Sprintf("This is an autogenerated C++ application that demonstrates the\n usage of the C++ bindings of %s", libraryname),
+			true)
+		if err != nil {
+			return err
+		}
+		err = buildCppExample(component, cppexamplefile, outputFolder, cpp17)
+		if err != nil {
+			return err;
 		}
 
 		CPPCMake := path.Join(outputFolderExample, "CMakeLists.txt");
-		if (forceRecreation || !FileExists(CPPCMake)) {
-			log.Printf("Creating \"%s\"", CPPCMake)
-			cppcmake, err := CreateLanguageFile (CPPCMake, "	")
-			if err != nil {
-				return err;
-			}
-			cppcmake.WriteCMakeLicenseHeader(component,
-				fmt.Sprintf("This is an autogenerated CMake Project that demonstrates the\n usage of the C++ bindings of %s", libraryname),
-				true)
-			buildCppExampleCMake(component, cppcmake, outputFolder)
-		} else {
-			log.Printf("Omitting recreation of C++Dynamic example file \"%s\"", CPPCMake)
+		log.Printf("Creating \"%s\"", CPPCMake)
+		cppcmake, err := CreateLanguageFile (CPPCMake, "	")
+		if err != nil {
+			return err;
+		}
+		err = cppcmake.WriteCMakeLicenseHeader(component,
+			fmt.Sprintf("This is an autogenerated CMake Project that demonstrates the\n usage of the C++ bindings of %s", libraryname),
+			true)
+		if err != nil {
+			return err
+		}
+		err = buildCppExampleCMake(component, cppcmake, outputFolder, headerOnly, cpp17)
+		if err != nil {
+			return err;
 		}
 	}
 	return nil
 }
 
-func buildCPPHeaderAndImplementation(component ComponentDefinition, w LanguageWriter, cppimplw LanguageWriter, NameSpace string, BaseName string) error {
-	// Header start code
-	w.Writeln("")
-	w.Writeln("#ifndef __%s_CPPHEADER", strings.ToUpper(NameSpace))
-	w.Writeln("#define __%s_CPPHEADER", strings.ToUpper(NameSpace))
-	w.Writeln("")
-
-	w.Writeln("#include \"%s.h\"", BaseName)
-
-	w.Writeln("#include <string>")
-	w.Writeln("#include <memory>")
-	w.Writeln("#include <vector>")
-	w.Writeln("#include <exception>")
-	w.Writeln("")
+// buildCPPHeaderAndImplementation writes the header and the implementation of the C++-bindings. In the header-only mode,
+// the inline implementation follows the declarations in the header, and cppimplw is not used.
+func buildCPPHeaderAndImplementation(component ComponentDefinition, w LanguageWriter, cppimplw LanguageWriter, NameSpace string, BaseName string, headerOnly bool, cpp17 bool) error {
+	templateData := NewTemplateData(component)
+	templateData.HeaderOnly = headerOnly
+	templateData.Cpp17 = cpp17
+
+	inlinePrefix := ""
+	var implementation bytes.Buffer
+	if headerOnly {
+		inlinePrefix = "inline "
+		cppimplw = LanguageWriter{IndentString: w.IndentString, Writer: &implementation}
+	}
 
-	w.Writeln("namespace %s {", NameSpace)
-	w.Writeln("")
+	// Header start code
+	err := w.WriteTemplate("cppheader.begin", templateData)
+	if err != nil {
+		return err
+	}
 
 	w.Writeln("/*************************************************************************************************************************")
 	w.Writeln(" Forward Declaration of all classes ")
@@ -177,6 +194,11 @@ func buildCPPHeaderAndImplementation(component ComponentDefinition, w LanguageWr
 	w.Writeln("    E%sException (%sResult errorCode);", NameSpace, NameSpace)
 	w.Writeln("")
 	w.Writeln("    /**")
+	w.Writeln("    * Exception Constructor with the error message of the component.")
+	w.Writeln("    */")
+	w.Writeln("    E%sException (%sResult errorCode, const std::string & errorMessage);", NameSpace, NameSpace)
+	w.Writeln("")
+	w.Writeln("    /**")
 	w.Writeln("    * Returns error code")
 	w.Writeln("    */")
 	w.Writeln("    %sResult getErrorCode ();", NameSpace)
@@ -190,18 +212,30 @@ func buildCPPHeaderAndImplementation(component ComponentDefinition, w LanguageWr
 	w.Writeln("};")
 
 	w.Writeln("")
-	err := writeCPPInputVector(w, NameSpace)
+	err = writeCPPInputVector(w, NameSpace, cpp17)
 	if err != nil {
 		return err
 	}
 	w.Writeln("")
 
+	if component.HasAsyncMethods() {
+		err = writeCPPAsyncSupport(component, w, NameSpace)
+		if err != nil {
+			return err
+		}
+	}
+
 	w.Writeln("")
 	w.Writeln("/*************************************************************************************************************************")
 	w.Writeln(" Class %sBaseClass ", cppClassPrefix)
 	w.Writeln("**************************************************************************************************************************/")
 
-	w.Writeln("class %sBaseClass {", cppClassPrefix)
+	if component.HasAsyncMethods() {
+		// The asynchronous calls keep the object alive
+		w.Writeln("class %sBaseClass : public std::enable_shared_from_this<%sBaseClass> {", cppClassPrefix, cppClassPrefix)
+	} else {
+		w.Writeln("class %sBaseClass {", cppClassPrefix)
+	}
 	w.Writeln("protected:")
 
 	w.Writeln("  /* Handle to Instance in library*/")
@@ -229,28 +263,34 @@ func buildCPPHeaderAndImplementation(component ComponentDefinition, w LanguageWr
 	w.Writeln("};")
 
 	// Implementation start code
-	cppimplw.Writeln("#include \"%s.hpp\"", BaseName)
-	cppimplw.Writeln("")
-	cppimplw.Writeln("#include <vector>")
-	cppimplw.Writeln("")
-	cppimplw.Writeln("namespace %s {", NameSpace)
-	cppimplw.Writeln("")
+	if !headerOnly {
+		err = cppimplw.WriteTemplate("cppimplementation.begin", templateData)
+		if err != nil {
+			return err
+		}
+	}
 	cppimplw.Writeln("/*************************************************************************************************************************")
 	cppimplw.Writeln(" Class E%sException ", NameSpace)
 	cppimplw.Writeln("**************************************************************************************************************************/")
-	cppimplw.Writeln("  E%sException::E%sException(%sResult errorCode)", NameSpace, NameSpace, NameSpace)
+	cppimplw.Writeln("  %sE%sException::E%sException(%sResult errorCode)", inlinePrefix, NameSpace, NameSpace, NameSpace)
 	cppimplw.Writeln("    : m_errorMessage(\"%s Error \" + std::to_string (errorCode))", NameSpace)
 	cppimplw.Writeln("  {")
 	cppimplw.Writeln("    m_errorCode = errorCode;")
 	cppimplw.Writeln("  }")
 	cppimplw.Writeln("")
-	cppimplw.Writeln("  %sResult E%sException::getErrorCode ()", NameSpace, NameSpace)
+	cppimplw.Writeln("  %sE%sException::E%sException(%sResult errorCode, const std::string & errorMessage)", inlinePrefix, NameSpace, NameSpace, NameSpace)
+	cppimplw.Writeln("    : m_errorMessage(errorMessage.empty() ? \"%s Error \" + std::to_string (errorCode) : errorMessage)", NameSpace)
+	cppimplw.Writeln("  {")
+	cppimplw.Writeln("    m_errorCode = errorCode;")
+	cppimplw.Writeln("  }")
+	cppimplw.Writeln("")
+	cppimplw.Writeln("  %s%sResult E%sException::getErrorCode ()", inlinePrefix, NameSpace, NameSpace)
 	cppimplw.Writeln("  {")
 	cppimplw.Writeln("    return m_errorCode;")
 	cppimplw.Writeln("  }")
 
 	cppimplw.Writeln("")
-	cppimplw.Writeln("  const char* E%sException::what () const noexcept", NameSpace)
+	cppimplw.Writeln("  %sconst char* E%sException::what () const noexcept", inlinePrefix, NameSpace)
 	cppimplw.Writeln("  {")
 	cppimplw.Writeln("    return m_errorMessage.c_str();")
 	cppimplw.Writeln("  }")
@@ -260,22 +300,22 @@ func buildCPPHeaderAndImplementation(component ComponentDefinition, w LanguageWr
 	cppimplw.Writeln(" Class %sBaseClass ", cppClassPrefix)
 	cppimplw.Writeln("**************************************************************************************************************************/")
 	cppimplw.Writeln("")
-	cppimplw.Writeln("%sBaseClass::%sBaseClass(%sHandle pHandle)", cppClassPrefix, cppClassPrefix, NameSpace)
+	cppimplw.Writeln("%s%sBaseClass::%sBaseClass(%sHandle pHandle)", inlinePrefix, cppClassPrefix, cppClassPrefix, NameSpace)
 	cppimplw.Writeln("{")
 	cppimplw.Writeln("  m_pHandle = pHandle;")
 	cppimplw.Writeln("}")
 	cppimplw.Writeln("")
-	cppimplw.Writeln("%sBaseClass::~%sBaseClass()", cppClassPrefix, cppClassPrefix)
+	cppimplw.Writeln("%s%sBaseClass::~%sBaseClass()", inlinePrefix, cppClassPrefix, cppClassPrefix)
 	cppimplw.Writeln("{")
 	cppimplw.Writeln("  %sWrapper::%s(this);", cppClassPrefix, component.Global.ReleaseMethod)
 	cppimplw.Writeln("}")
 	cppimplw.Writeln("")
-	cppimplw.Writeln("void %sBaseClass::CheckError(%sResult nResult)", cppClassPrefix, NameSpace)
+	cppimplw.Writeln("%svoid %sBaseClass::CheckError(%sResult nResult)", inlinePrefix, cppClassPrefix, NameSpace)
 	cppimplw.Writeln("{")
 	cppimplw.Writeln("  %sWrapper::CheckError(m_pHandle, nResult);", cppClassPrefix)
 	cppimplw.Writeln("}")
 	cppimplw.Writeln("")
-	cppimplw.Writeln("%sHandle %sBaseClass::GetHandle()", NameSpace, cppClassPrefix)
+	cppimplw.Writeln("%s%sHandle %sBaseClass::GetHandle()", inlinePrefix, NameSpace, cppClassPrefix)
 	cppimplw.Writeln("{")
 	cppimplw.Writeln("  return m_pHandle;")
 	cppimplw.Writeln("}")
@@ -311,17 +351,23 @@ func buildCPPHeaderAndImplementation(component ComponentDefinition, w LanguageWr
 		cppimplw.Writeln("/**")
 		cppimplw.Writeln("* %s::%s - Constructor for %s class.", cppClassName, cppClassName, class.ClassName)
 		cppimplw.Writeln("*/")
-		cppimplw.Writeln("%s::%s (%sHandle pHandle)", cppClassName, cppClassName, NameSpace)
+		cppimplw.Writeln("%s%s::%s (%sHandle pHandle)", inlinePrefix, cppClassName, cppClassName, NameSpace)
 		cppimplw.Writeln("  : %s (pHandle)", cppParentClassName)
 		cppimplw.Writeln("{ }")
 
 		for j := 0; j < len(class.Methods); j++ {
 			method := class.Methods[j]
 
-			err := writeCPPMethod(method, w, cppimplw, NameSpace, class.ClassName, false)
+			err := writeCPPMethod(method, w, cppimplw, NameSpace, class.ClassName, false, headerOnly, cpp17)
 			if err != nil {
 				return err
 			}
+			if method.Async {
+				err = writeCPPAsyncMethod(component, method, w, cppimplw, NameSpace, class.ClassName, false, headerOnly, cpp17)
+				if err != nil {
+					return err
+				}
+			}
 
 		}
 
@@ -344,36 +390,65 @@ func buildCPPHeaderAndImplementation(component ComponentDefinition, w LanguageWr
 	for j := 0; j < len(global.Methods); j++ {
 		method := global.Methods[j]
 
-		err := writeCPPMethod(method, w, cppimplw, NameSpace, "Wrapper", true)
+		err := writeCPPMethod(method, w, cppimplw, NameSpace, "Wrapper", true, headerOnly, cpp17)
 		if err != nil {
 			return err
 		}
+		if method.Async {
+			err = writeCPPAsyncMethod(component, method, w, cppimplw, NameSpace, "Wrapper", true, headerOnly, cpp17)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	w.Writeln("};")
 
-	w.Writeln("")
-	w.Writeln("};")
-	w.Writeln("")
-	w.Writeln("#endif // __%s_CPPHEADER", strings.ToUpper(NameSpace))
-	w.Writeln("")
+	if !headerOnly {
+		err = w.WriteTemplate("cppheader.end", templateData)
+		if err != nil {
+			return err
+		}
+	}
 
 	cppimplw.Writeln("")
-	cppimplw.Writeln("void %sWrapper::CheckError(%sHandle handle, %sResult nResult)", cppClassPrefix, NameSpace, NameSpace)
+	cppimplw.Writeln("%svoid %sWrapper::CheckError(%sHandle handle, %sResult nResult)", inlinePrefix, cppClassPrefix, NameSpace, NameSpace)
 	cppimplw.Writeln("{")
-	cppimplw.Writeln("  if (nResult != 0) ")
-	cppimplw.Writeln("    throw E%sException (nResult);", NameSpace)
+	errorMethod, hasErrorMethod := component.Global.GetErrorMethod()
+	if hasErrorMethod {
+		errorExport := fmt.Sprintf("%s_%s%s", strings.ToLower(NameSpace), strings.ToLower(errorMethod.MethodName), errorMethod.DLLSuffix)
+		cppimplw.Writeln("  if (nResult != 0) {")
+		cppimplw.Writeln("    std::string sErrorMessage;")
+		cppimplw.Writeln("    %s_uint32 nNeededChars = 0;", NameSpace)
+		cppimplw.Writeln("    bool bHasError = false;")
+		cppimplw.Writeln("    if ((%s (handle, 0, &nNeededChars, nullptr, &bHasError) == 0) && bHasError) {", errorExport)
+		cppimplw.Writeln("      std::vector<char> buffer (nNeededChars + 1);")
+		cppimplw.Writeln("      if (%s (handle, nNeededChars + 1, &nNeededChars, &buffer[0], &bHasError) == 0)", errorExport)
+		cppimplw.Writeln("        sErrorMessage = std::string (&buffer[0]);")
+		cppimplw.Writeln("    }")
+		cppimplw.Writeln("    throw E%sException (nResult, sErrorMessage);", NameSpace)
+		cppimplw.Writeln("  }")
+	} else {
+		cppimplw.Writeln("  if (nResult != 0) ")
+		cppimplw.Writeln("    throw E%sException (nResult);", NameSpace)
+	}
 	cppimplw.Writeln("}")
 	cppimplw.Writeln("")
 
-	cppimplw.Writeln("")
-	cppimplw.Writeln("}; // end namespace %s", NameSpace)
-	cppimplw.Writeln("")
-
-	return nil
+	if headerOnly {
+		w.Writeln("")
+		_, err = w.Writer.Write(implementation.Bytes())
+		if err != nil {
+			return err
+		}
+		return w.WriteTemplate("cppheader.end", templateData)
+	}
+	return cppimplw.WriteTemplate("cppimplementation.end", templateData)
 }
 
-func writeCPPInputVector(w LanguageWriter, NameSpace string) (error) {
+// writeCPPInputVector writes the view of the arrays passed to the library. In the C++17 style, it can view any contiguous
+// array like a std::span.
+func writeCPPInputVector(w LanguageWriter, NameSpace string, cpp17 bool) (error) {
 	w.Writeln("/*************************************************************************************************************************")
 	w.Writeln(" Class C%sInputVector", NameSpace)
 	w.Writeln("**************************************************************************************************************************/")
@@ -396,6 +471,20 @@ func writeCPPInputVector(w LanguageWriter, NameSpace string) (error) {
 	w.Writeln("  {")
 	w.Writeln("  }")
 	w.Writeln("  ")
+	if cpp17 {
+		w.Writeln("  template <size_t N>")
+		w.Writeln("  constexpr C%sInputVector( const std::array<T, N>& arr) noexcept", NameSpace)
+		w.Writeln("    : m_data( arr.data() ), m_size( N )")
+		w.Writeln("  {")
+		w.Writeln("  }")
+		w.Writeln("  ")
+		w.Writeln("  template <size_t N>")
+		w.Writeln("  constexpr C%sInputVector( const T (&arr)[N]) noexcept", NameSpace)
+		w.Writeln("    : m_data( arr ), m_size( N )")
+		w.Writeln("  {")
+		w.Writeln("  }")
+		w.Writeln("  ")
+	}
 	w.Writeln("  const T* data() const")
 	w.Writeln("  {")
 	w.Writeln("    return m_data;")
@@ -406,6 +495,28 @@ func writeCPPInputVector(w LanguageWriter, NameSpace string) (error) {
 	w.Writeln("    return m_size;")
 	w.Writeln("  }")
 	w.Writeln("  ")
+	if cpp17 {
+		w.Writeln("  [[nodiscard]] bool empty() const noexcept")
+		w.Writeln("  {")
+		w.Writeln("    return m_size == 0;")
+		w.Writeln("  }")
+		w.Writeln("  ")
+		w.Writeln("  const T* begin() const noexcept")
+		w.Writeln("  {")
+		w.Writeln("    return m_data;")
+		w.Writeln("  }")
+		w.Writeln("  ")
+		w.Writeln("  const T* end() const noexcept")
+		w.Writeln("  {")
+		w.Writeln("    return m_data + m_size;")
+		w.Writeln("  }")
+		w.Writeln("  ")
+		w.Writeln("  const T& operator[]( size_t index) const")
+		w.Writeln("  {")
+		w.Writeln("    return m_data[index];")
+		w.Writeln("  }")
+		w.Writeln("  ")
+	}
 	w.Writeln("};")
 	return nil
 }
@@ -520,7 +631,7 @@ func getBindingCppVariableName (param ComponentDefinitionParam) (string) {
 	return "";
 }
 
-func writeCPPMethod(method ComponentDefinitionMethod, w LanguageWriter, cppimplw LanguageWriter, NameSpace string, ClassName string, isGlobal bool) error {
+func writeCPPMethod(method ComponentDefinitionMethod, w LanguageWriter, cppimplw LanguageWriter, NameSpace string, ClassName string, isGlobal bool, headerOnly bool, cpp17 bool) error {
 
 	CMethodName := ""
 	requiresInitCall := false;
@@ -552,6 +663,40 @@ func writeCPPMethod(method ComponentDefinitionMethod, w LanguageWriter, cppimplw
 	cppClassPrefix := "C" + NameSpace
 	cppClassName := cppClassPrefix + ClassName
 
+	inlinePrefix := ""
+	if headerOnly {
+		inlinePrefix = "inline "
+	}
+
+	if cpp17 {
+		returntype, parameters, commentcodeLines, bodyLines, err := getCPP17Method(method, NameSpace, ClassName, CMethodName, checkErrorCode, callParameters, "")
+		if err != nil {
+			return err
+		}
+		nodiscardPrefix := ""
+		if isCPPGetter(method, NameSpace) {
+			nodiscardPrefix = "[[nodiscard]] "
+		}
+
+		w.Writeln("")
+		w.Writeln("  /**")
+		w.Writeln("  * %s::%s - %s", cppClassName, method.MethodName, method.MethodDescription)
+		w.Writelns("  ", commentcodeLines)
+		w.Writeln("  */")
+		w.Writeln("  %s%s%s %s (%s);", nodiscardPrefix, staticPrefix, returntype, method.MethodName, parameters)
+
+		cppimplw.Writeln("")
+		cppimplw.Writeln("/**")
+		cppimplw.Writeln("* %s::%s - %s", cppClassName, method.MethodName, method.MethodDescription)
+		cppimplw.Writelns("", commentcodeLines)
+		cppimplw.Writeln("*/")
+		cppimplw.Writeln("%s%s %s::%s (%s)", inlinePrefix, returntype, cppClassName, method.MethodName, parameters)
+		cppimplw.Writeln("{")
+		cppimplw.Writelns("  ", bodyLines)
+		cppimplw.Writeln("}")
+		return nil
+	}
+
 	for k := 0; k < len(method.Params); k++ {
 
 		param := method.Params[k]
@@ -584,10 +729,10 @@ func writeCPPMethod(method ComponentDefinitionMethod, w LanguageWriter, cppimplw
 				initCallParameter = callParameter;
 				parameters = parameters + fmt.Sprintf("const %s & %s", cppParamType, variableName);
 			case "handle":
-				functionCodeLines = append(functionCodeLines, fmt.Sprintf("%sHandle h%s = nullptr;", NameSpace, param.ParamName))
-				functionCodeLines = append(functionCodeLines, fmt.Sprintf("if (%s != nullptr) {", variableName))
-				functionCodeLines = append(functionCodeLines, fmt.Sprintf("  h%s = %s->GetHandle ();", param.ParamName, variableName))
-				functionCodeLines = append(functionCodeLines, fmt.Sprintf("};"))
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("%sHandle h%s = nullptr;", NameSpace, param.ParamName))
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("if (%s != nullptr) {", variableName))
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("  h%s = %s->GetHandle ();", param.ParamName, variableName))
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("};"))
 				callParameter = "h" + param.ParamName;
 				initCallParameter = callParameter;
 				parameters = parameters + fmt.Sprintf("%s %s", cppParamType, variableName)
@@ -724,7 +869,7 @@ func writeCPPMethod(method ComponentDefinitionMethod, w LanguageWriter, cppimplw
 	cppimplw.Writeln("* %s::%s - %s", cppClassName, method.MethodName, method.MethodDescription)
 	cppimplw.Writelns("", commentcodeLines)
 	cppimplw.Writeln("*/")
-	cppimplw.Writeln("%s %s::%s (%s)", returntype, cppClassName, method.MethodName, parameters)
+	cppimplw.Writeln("%s%s %s::%s (%s)", inlinePrefix, returntype, cppClassName, method.MethodName, parameters)
 	cppimplw.Writeln("{")
 	cppimplw.Writelns("  ", definitionCodeLines)
 	if (requiresInitCall) {
@@ -740,56 +885,530 @@ func writeCPPMethod(method ComponentDefinitionMethod, w LanguageWriter, cppimplw
 	return nil
 }
 
+// getCPP17OutputType returns the type of an output of a method in the C++17 style. Handles are optional, as the library
+// may return a null handle.
+func getCPP17OutputType(param ComponentDefinitionParam, NameSpace string) (string) {
+	if param.ParamType == "handle" {
+		return fmt.Sprintf("std::optional<P%s%s>", NameSpace, param.ParamClass)
+	}
+	return getBindingCppParamType(param, NameSpace, false)
+}
 
-func buildCppExample(componentdefinition ComponentDefinition, w LanguageWriter, outputFolder string) error {
-	NameSpace := componentdefinition.NameSpace
-	BaseName := componentdefinition.BaseName
+// getCPP17ReturnType returns the return type of a method in the C++17 style, which returns all outputs of the method.
+// Several outputs are returned as tuple.
+func getCPP17ReturnType(method ComponentDefinitionMethod, NameSpace string) (string) {
+	outputTypes := []string{}
+	for _, param := range method.Params {
+		if (param.ParamPass == "out") || (param.ParamPass == "return") {
+			outputTypes = append(outputTypes, getCPP17OutputType(param, NameSpace))
+		}
+	}
 
-	w.Writeln("#include <iostream>")
-	w.Writeln("#include \"%s.hpp\"", strings.ToLower(BaseName) )
-	w.Writeln("")
+	switch len(outputTypes) {
+	case 0:
+		return "void"
+	case 1:
+		return outputTypes[0]
+	}
+	return fmt.Sprintf("std::tuple<%s>", strings.Join(outputTypes, ", "))
+}
+
+// isCPPGetter checks whether the result of a method must not be discarded in the C++17 style
+func isCPPGetter(method ComponentDefinitionMethod, NameSpace string) (bool) {
+	if getCPP17ReturnType(method, NameSpace) == "void" {
+		return false
+	}
+	return strings.HasPrefix(method.MethodName, "Get") || strings.HasPrefix(method.MethodName, "Is") || strings.HasPrefix(method.MethodName, "Has")
+}
+
+// getCPP17Method returns the return type, the parameters, the comment and the body of a method in the C++17 style, which
+// takes strings as std::string_view and arrays as views, and returns its outputs instead of writing them to references.
+// instanceParameter is the first argument of the calls of the library, and makeSharedParameter the first argument of
+// the constructors of returned classes.
+func getCPP17Method(method ComponentDefinitionMethod, NameSpace string, ClassName string, CMethodName string, checkErrorCode string, instanceParameter string, makeSharedParameter string) (string, string, []string, []string, error) {
+	requiresInitCall := false
+	initCallParameters := instanceParameter
+	callParameters := instanceParameter
+	parameters := ""
+
+	definitionCodeLines := []string{}
+	functionCodeLines := []string{}
+	postCallCodeLines := []string{}
+	commentcodeLines := []string{}
+	outputNames := []string{}
+
+	cppClassPrefix := "C" + NameSpace
+
+	for _, param := range method.Params {
+		variableName := getBindingCppVariableName(param)
+
+		callParameter := ""
+		initCallParameter := ""
+
+		switch param.ParamPass {
+		case "in":
+			if parameters != "" {
+				parameters = parameters + ", "
+			}
+
+			cppParamType := getBindingCppParamType(param, NameSpace, true)
+			commentcodeLines = append(commentcodeLines, fmt.Sprintf("* @param[in] %s - %s", variableName, param.ParamDescription))
+
+			switch param.ParamType {
+			case "string":
+				// The library expects a null-terminated string, which a view does not guarantee
+				callParameter = fmt.Sprintf("std::string (%s).c_str()", variableName)
+				parameters = parameters + fmt.Sprintf("std::string_view %s", variableName)
+			case "struct":
+				callParameter = "&" + variableName
+				parameters = parameters + fmt.Sprintf("const %s & %s", cppParamType, variableName)
+			case "structarray", "basicarray":
+				callParameter = fmt.Sprintf("(%s_uint64)%s.size(), %s.data()", NameSpace, variableName, variableName)
+				parameters = parameters + fmt.Sprintf("%s %s", cppParamType, variableName)
+			case "handle":
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("%sHandle h%s = nullptr;", NameSpace, param.ParamName))
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("if (%s != nullptr) {", variableName))
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("  h%s = %s->GetHandle ();", param.ParamName, variableName))
+				definitionCodeLines = append(definitionCodeLines, "}")
+				callParameter = "h" + param.ParamName
+				parameters = parameters + fmt.Sprintf("%s %s", cppParamType, variableName)
+			default:
+				callParameter = variableName
+				parameters = parameters + fmt.Sprintf("const %s %s", cppParamType, variableName)
+			}
+			initCallParameter = callParameter
+
+		case "out", "return":
+			outputType := getCPP17OutputType(param, NameSpace)
+			commentcodeLines = append(commentcodeLines, fmt.Sprintf("* @return %s - %s", variableName, param.ParamDescription))
+			outputNames = append(outputNames, variableName)
+
+			switch param.ParamType {
+			case "string":
+				requiresInitCall = true
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("%s %s;", outputType, variableName))
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("%s_uint32 bytesNeeded%s = 0;", NameSpace, param.ParamName))
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("%s_uint32 bytesWritten%s = 0;", NameSpace, param.ParamName))
+				initCallParameter = fmt.Sprintf("0, &bytesNeeded%s, nullptr", param.ParamName)
+
+				functionCodeLines = append(functionCodeLines, fmt.Sprintf("std::vector<char> buffer%s;", param.ParamName))
+				functionCodeLines = append(functionCodeLines, fmt.Sprintf("buffer%s.resize(bytesNeeded%s + 2);", param.ParamName, param.ParamName))
+
+				callParameter = fmt.Sprintf("bytesNeeded%s + 2, &bytesWritten%s, &buffer%s[0]", param.ParamName, param.ParamName, param.ParamName)
+
+				postCallCodeLines = append(postCallCodeLines, fmt.Sprintf("buffer%s[bytesNeeded%s + 1] = 0;", param.ParamName, param.ParamName))
+				postCallCodeLines = append(postCallCodeLines, fmt.Sprintf("%s = std::string(&buffer%s[0]);", variableName, param.ParamName))
+
+			case "handle":
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("%s %s;", outputType, variableName))
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("%sHandle h%s = nullptr;", NameSpace, param.ParamName))
+				callParameter = fmt.Sprintf("&h%s", param.ParamName)
+				initCallParameter = callParameter
+
+				postCallCodeLines = append(postCallCodeLines, fmt.Sprintf("if (h%s != nullptr) {", param.ParamName))
+				postCallCodeLines = append(postCallCodeLines, fmt.Sprintf("  %s = std::make_shared<%s%s> (%sh%s);", variableName, cppClassPrefix, param.ParamClass, makeSharedParameter, param.ParamName))
+				postCallCodeLines = append(postCallCodeLines, "}")
+
+			case "structarray", "basicarray":
+				if param.ParamPass == "return" {
+					return "", "", nil, nil, fmt.Errorf("can not return %s \"%s\" for %s.%s (%s)", param.ParamType, param.ParamPass, ClassName, method.MethodName, param.ParamName)
+				}
+				requiresInitCall = true
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("%s %s;", outputType, variableName))
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("%s_uint64 elementsNeeded%s = 0;", NameSpace, param.ParamName))
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("%s_uint64 elementsWritten%s = 0;", NameSpace, param.ParamName))
+				initCallParameter = fmt.Sprintf("0, &elementsNeeded%s, nullptr", param.ParamName)
+
+				functionCodeLines = append(functionCodeLines, fmt.Sprintf("%s.resize(elementsNeeded%s);", variableName, param.ParamName))
+				callParameter = fmt.Sprintf("elementsNeeded%s, &elementsWritten%s, %s.data()", param.ParamName, param.ParamName, variableName)
+
+			case "struct":
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("%s %s;", outputType, variableName))
+				callParameter = "&" + variableName
+				initCallParameter = callParameter
+
+			case "enum":
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("%s %s = (%s) 0;", outputType, variableName, outputType))
+				callParameter = "&" + variableName
+				initCallParameter = callParameter
+
+			case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "bool", "single", "double":
+				definitionCodeLines = append(definitionCodeLines, fmt.Sprintf("%s %s = 0;", outputType, variableName))
+				callParameter = "&" + variableName
+				initCallParameter = callParameter
+
+			default:
+				return "", "", nil, nil, fmt.Errorf("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName)
+			}
+
+		default:
+			return "", "", nil, nil, fmt.Errorf("invalid method parameter passing \"%s\" for %s.%s (%s)", param.ParamPass, ClassName, method.MethodName, param.ParamName)
+		}
+
+		if callParameters != "" {
+			callParameters = callParameters + ", "
+		}
+		callParameters = callParameters + callParameter
+		if initCallParameters != "" {
+			initCallParameters = initCallParameters + ", "
+		}
+		initCallParameters = initCallParameters + initCallParameter
+	}
+
+	bodyLines := definitionCodeLines
+	if requiresInitCall {
+		bodyLines = append(bodyLines, fmt.Sprintf("%s %s (%s) );", checkErrorCode, CMethodName, initCallParameters))
+	}
+	bodyLines = append(bodyLines, functionCodeLines...)
+	bodyLines = append(bodyLines, fmt.Sprintf("%s %s (%s) );", checkErrorCode, CMethodName, callParameters))
+	bodyLines = append(bodyLines, postCallCodeLines...)
+
+	switch len(outputNames) {
+	case 0:
+	case 1:
+		bodyLines = append(bodyLines, fmt.Sprintf("return %s;", outputNames[0]))
+	default:
+		movedOutputs := []string{}
+		for _, outputName := range outputNames {
+			movedOutputs = append(movedOutputs, fmt.Sprintf("std::move (%s)", outputName))
+		}
+		bodyLines = append(bodyLines, fmt.Sprintf("return std::make_tuple (%s);", strings.Join(movedOutputs, ", ")))
+	}
+
+	return getCPP17ReturnType(method, NameSpace), parameters, commentcodeLines, bodyLines, nil
+}
+
+
+// validateBindingCpp checks that the C++ standard of the bindings is supported
+func validateBindingCpp(component ComponentDefinition, options GeneratorOptions) error {
+	switch options.CppStandard {
+	case "", "11", "17":
+		return nil
+	}
+	return fmt.Errorf ("unsupported C++ standard \"%s\", use \"11\" or \"17\"", options.CppStandard)
+}
+
+func buildCppExample(componentdefinition ComponentDefinition, w LanguageWriter, outputFolder string, cpp17 bool) error {
+	data := NewTemplateData(componentdefinition)
+	data.Cpp17 = cpp17
+	return w.WriteTemplate("cppexample", data)
+}
+
+func buildCppExampleCMake(componentdefinition ComponentDefinition, w LanguageWriter, outputFolder string, headerOnly bool, cpp17 bool) error {
+	data := NewTemplateData(componentdefinition)
+	data.HeaderOnly = headerOnly
+	data.Cpp17 = cpp17
+	// TODO: calculate relative path from ExampleOutputFolder to OUTPUTFOLDER based on CURRENT_SOURCE_DIR
+	data.BindingFolder = strings.Replace(outputFolder, string(filepath.Separator), "/", -1)
+	return w.WriteTemplate("cppexample.cmake", data)
+}
+
+// writeCPPAsyncSupport writes the cancellation token of asynchronous calls, and the callbacks through which they are cancelled
+func writeCPPAsyncSupport(component ComponentDefinition, w LanguageWriter, NameSpace string) (error) {
+	w.Writeln("/*************************************************************************************************************************")
+	w.Writeln(" Class C%sCancellationToken", NameSpace)
+	w.Writeln("**************************************************************************************************************************/")
+	w.Writeln("class C%sCancellationToken {", NameSpace)
+	w.Writeln("private:")
+	w.Writeln("  ")
+	w.Writeln("  std::shared_ptr<std::atomic<bool>> m_pCancelled;")
+	w.Writeln("  ")
+	w.Writeln("public:")
+	w.Writeln("  ")
+	w.Writeln("  C%sCancellationToken ()", NameSpace)
+	w.Writeln("    : m_pCancelled (std::make_shared<std::atomic<bool>> (false))")
+	w.Writeln("  {")
+	w.Writeln("  }")
+	w.Writeln("  ")
+	w.Writeln("  /**")
+	w.Writeln("  * C%sCancellationToken::Cancel - Cancels the asynchronous calls, that have been passed the token or a copy of it.", NameSpace)
+	w.Writeln("  * The calls are aborted the next time they report their progress.")
+	w.Writeln("  */")
+	w.Writeln("  void Cancel ()")
+	w.Writeln("  {")
+	w.Writeln("    m_pCancelled->store (true);")
+	w.Writeln("  }")
+	w.Writeln("  ")
+	w.Writeln("  bool IsCancelled () const")
+	w.Writeln("  {")
+	w.Writeln("    return m_pCancelled->load ();")
+	w.Writeln("  }")
+	w.Writeln("  ")
+	w.Writeln("};")
 	w.Writeln("")
 
-	w.Writeln("int main()")
-	w.Writeln("{")
-	w.Writeln("  try")
+	cancellationTypes := component.GetCancellationTypes()
+	if len(cancellationTypes) == 0 {
+		return nil
+	}
+
+	w.Writeln("/*************************************************************************************************************************")
+	w.Writeln(" Class C%sCancellationScope", NameSpace)
+	w.Writeln("**************************************************************************************************************************/")
+	w.Writeln("template <typename F>")
+	w.Writeln("class C%sCancellationScope {", NameSpace)
+	w.Writeln("private:")
+	w.Writeln("  ")
+	w.Writeln("  F m_pCallback;")
+	w.Writeln("  C%sCancellationToken m_CancellationToken;", NameSpace)
+	w.Writeln("  C%sCancellationScope * m_pOuterScope;", NameSpace)
+	w.Writeln("  ")
+	w.Writeln("  static C%sCancellationScope *& innermostScope ()", NameSpace)
 	w.Writeln("  {")
-	w.Writeln("    unsigned int nMajor, nMinor, nMicro;")
-	w.Writeln("    %s::C%sWrapper::GetLibraryVersion(nMajor, nMinor, nMicro);", NameSpace, NameSpace)
-	w.Writeln("    std::cout << \"%s.Version = \" << nMajor << \".\" << nMinor << \".\" << nMicro << std::endl;", NameSpace);
+	w.Writeln("    static thread_local C%sCancellationScope * pInnermostScope = nullptr;", NameSpace)
+	w.Writeln("    return pInnermostScope;")
 	w.Writeln("  }")
-	w.Writeln("  catch (std::exception &e)")
+	w.Writeln("  ")
+	w.Writeln("public:")
+	w.Writeln("  ")
+	w.Writeln("  /**")
+	w.Writeln("  * C%sCancellationScope - Keeps the callback and the cancellation token of an asynchronous call on its worker thread.", NameSpace)
+	w.Writeln("  */")
+	w.Writeln("  C%sCancellationScope (F pCallback, const C%sCancellationToken & cancellationToken)", NameSpace, NameSpace)
+	w.Writeln("    : m_pCallback (pCallback), m_CancellationToken (cancellationToken), m_pOuterScope (innermostScope ())")
 	w.Writeln("  {")
-	w.Writeln("    std::cout << e.what() << std::endl;")
-	w.Writeln("    return 1;")
+	w.Writeln("    innermostScope () = this;")
 	w.Writeln("  }")
-	w.Writeln("  return 0;")
-	w.Writeln("}")
+	w.Writeln("  ")
+	w.Writeln("  ~C%sCancellationScope ()", NameSpace)
+	w.Writeln("  {")
+	w.Writeln("    innermostScope () = m_pOuterScope;")
+	w.Writeln("  }")
+	w.Writeln("  ")
+	w.Writeln("  F GetCallback () const")
+	w.Writeln("  {")
+	w.Writeln("    return m_pCallback;")
+	w.Writeln("  }")
+	w.Writeln("  ")
+	w.Writeln("  bool IsCancelled () const")
+	w.Writeln("  {")
+	w.Writeln("    return m_CancellationToken.IsCancelled ();")
+	w.Writeln("  }")
+	w.Writeln("  ")
+	w.Writeln("  static C%sCancellationScope * GetInnermostScope ()", NameSpace)
+	w.Writeln("  {")
+	w.Writeln("    return innermostScope ();")
+	w.Writeln("  }")
+	w.Writeln("  ")
+	w.Writeln("};")
+
+	for _, functiontype := range component.Functions {
+		abortIndex, isCancellationType := cancellationTypes[functiontype.FunctionName]
+		if !isCancellationType {
+			continue
+		}
+		parameters, names, err := getCCallbackParameters(functiontype, NameSpace)
+		if err != nil {
+			return err
+		}
+		scopeType := fmt.Sprintf("C%sCancellationScope<%s%s>", NameSpace, NameSpace, functiontype.FunctionName)
+
+		w.Writeln("")
+		w.Writeln("/**")
+		w.Writeln("* Cancellable%s - Calls the %s of the asynchronous call on the current thread, and aborts the call, if it has been cancelled", functiontype.FunctionName, functiontype.FunctionName)
+		w.Writeln("*/")
+		w.Writeln("inline void Cancellable%s (%s)", functiontype.FunctionName, parameters)
+		w.Writeln("{")
+		w.Writeln("  %s * pScope = %s::GetInnermostScope ();", scopeType, scopeType)
+		w.Writeln("  if (pScope == nullptr)")
+		w.Writeln("    return;")
+		w.Writeln("  if (pScope->GetCallback () != nullptr)")
+		w.Writeln("    pScope->GetCallback () (%s);", strings.Join(names, ", "))
+		w.Writeln("  if ((%s != nullptr) && pScope->IsCancelled ())", names[abortIndex])
+		w.Writeln("    *%s = true;", names[abortIndex])
+		w.Writeln("}")
+	}
 	w.Writeln("")
 
 	return nil
 }
 
-func buildCppExampleCMake(componentdefinition ComponentDefinition, w LanguageWriter, outputFolder string) error {
-	NameSpace := componentdefinition.NameSpace
-	BaseName := componentdefinition.BaseName
+// getCPPAsyncMethod returns the return type, the parameters and the body of the asynchronous variant of a method, which
+// calls the method on a worker thread. Its last parameter is a cancellation token, if the method has a progress callback.
+// The call holds a reference to the object of ClassName, which the caller may release before the call has completed, or
+// calls a global method, if ClassName is empty.
+func getCPPAsyncMethod(component ComponentDefinition, method ComponentDefinitionMethod, NameSpace string, ClassName string, cpp17 bool) (string, string, bool, []string, error) {
+	cancellationParam, _, isCancellable := component.GetCancellationCallback(method)
+
+	parameters := ""
+	arguments := ""
+	copyLines := []string{}
+	outputLines := []string{}
+	outputTypes := []string{}
+	outputNames := []string{}
+	returnIndex := -1
+
+	for _, param := range method.Params {
+		variableName := getBindingCppVariableName(param)
+
+		switch param.ParamPass {
+		case "in":
+			cppParamType := getBindingCppParamType(param, NameSpace, true)
+			argument := variableName
+
+			if parameters != "" {
+				parameters = parameters + ", "
+			}
+			switch param.ParamType {
+			case "string":
+				if cpp17 {
+					// The characters are copied, as the call may outlive the string of the caller
+					parameters = parameters + fmt.Sprintf("std::string_view %s", variableName)
+					argument = variableName + "Copy"
+					copyLines = append(copyLines, fmt.Sprintf("std::string %s (%s);", argument, variableName))
+				} else {
+					parameters = parameters + fmt.Sprintf("const %s & %s", cppParamType, variableName)
+				}
+			case "struct":
+				parameters = parameters + fmt.Sprintf("const %s & %s", cppParamType, variableName)
+			case "structarray", "basicarray":
+				// The elements are copied, as the call may outlive the buffer of the caller
+				if cpp17 {
+					parameters = parameters + fmt.Sprintf("%s %s", cppParamType, variableName)
+				} else {
+					parameters = parameters + fmt.Sprintf("const %s & %s", cppParamType, variableName)
+				}
+				argument = param.ParamName + "Elements"
+				copyLines = append(copyLines, fmt.Sprintf("%s %s (%s.data (), %s.data () + %s.size ());", getBindingCppParamType(param, NameSpace, false), argument, variableName, variableName, variableName))
+			case "handle":
+				parameters = parameters + fmt.Sprintf("%s %s", cppParamType, variableName)
+			case "functiontype":
+				parameters = parameters + fmt.Sprintf("const %s %s", cppParamType, variableName)
+				if isCancellable && (param.ParamName == cancellationParam.ParamName) {
+					argument = "Cancellable" + param.ParamClass
+				}
+			default:
+				parameters = parameters + fmt.Sprintf("const %s %s", cppParamType, variableName)
+			}
+
+			if arguments != "" {
+				arguments = arguments + ", "
+			}
+			arguments = arguments + argument
+
+		case "out":
+			if cpp17 {
+				// The method returns its outputs in the C++17 style
+				continue
+			}
+			cppParamType := getBindingCppParamType(param, NameSpace, false)
+			outputLines = append(outputLines, fmt.Sprintf("%s %s;", cppParamType, variableName))
+			outputTypes = append(outputTypes, cppParamType)
+			outputNames = append(outputNames, variableName)
+
+			if arguments != "" {
+				arguments = arguments + ", "
+			}
+			arguments = arguments + variableName
+
+		case "return":
+			returnIndex = len(outputNames)
+			outputTypes = append(outputTypes, getBindingCppParamType(param, NameSpace, false))
+			outputNames = append(outputNames, "result" + param.ParamName)
+
+		default:
+			return "", "", false, nil, fmt.Errorf("invalid method parameter passing \"%s\" for %s (%s)", param.ParamPass, method.MethodName, param.ParamName)
+		}
+	}
+
+	// Several outputs are returned as tuple
+	returntype := "void"
+	call := fmt.Sprintf("%s (%s);", method.MethodName, arguments)
+	returnLine := ""
+	if cpp17 {
+		// The method already returns all outputs
+		returntype = getCPP17ReturnType(method, NameSpace)
+		if returntype != "void" {
+			call = "return " + call
+		}
+	} else {
+		switch len(outputNames) {
+		case 0:
+		case 1:
+			returntype = outputTypes[0]
+			if returnIndex == 0 {
+				call = "return " + call
+			} else {
+				returnLine = fmt.Sprintf("return %s;", outputNames[0])
+			}
+		default:
+			returntype = fmt.Sprintf("std::tuple<%s>", strings.Join(outputTypes, ", "))
+			if returnIndex >= 0 {
+				call = fmt.Sprintf("%s %s = %s", outputTypes[returnIndex], outputNames[returnIndex], call)
+			}
+			returnLine = fmt.Sprintf("return std::make_tuple (%s);", strings.Join(outputNames, ", "))
+		}
+	}
+
+	bodyLines := copyLines
+	if ClassName != "" {
+		bodyLines = append(bodyLines, fmt.Sprintf("P%s%s pThis = std::static_pointer_cast<C%s%s> (shared_from_this ());", NameSpace, ClassName, NameSpace, ClassName))
+		call = strings.Replace(call, method.MethodName + " (", "pThis->" + method.MethodName + " (", 1)
+	}
+	bodyLines = append(bodyLines, fmt.Sprintf("return std::async (std::launch::async, [=] () -> %s {", returntype))
+	if isCancellable {
+		bodyLines = append(bodyLines, fmt.Sprintf("  C%sCancellationScope<%s%s> cancellationScope (%s, cancellationToken);", NameSpace, NameSpace, cancellationParam.ParamClass, getBindingCppVariableName(cancellationParam)))
+	}
+	for _, line := range outputLines {
+		bodyLines = append(bodyLines, "  " + line)
+	}
+	bodyLines = append(bodyLines, "  " + call)
+	if returnLine != "" {
+		bodyLines = append(bodyLines, "  " + returnLine)
+	}
+	bodyLines = append(bodyLines, "});")
+
+	return fmt.Sprintf("std::future<%s>", returntype), parameters, isCancellable, bodyLines, nil
+}
+
+// writeCPPAsyncMethod declares and implements the asynchronous variant of a method
+func writeCPPAsyncMethod(component ComponentDefinition, method ComponentDefinitionMethod, w LanguageWriter, cppimplw LanguageWriter, NameSpace string, ClassName string, isGlobal bool, headerOnly bool, cpp17 bool) error {
+	objectClassName := ClassName
+	if isGlobal {
+		objectClassName = ""
+	}
+	returntype, parameters, isCancellable, bodyLines, err := getCPPAsyncMethod(component, method, NameSpace, objectClassName, cpp17)
+	if err != nil {
+		return err
+	}
 
-	w.Writeln("cmake_minimum_required(VERSION 3.5)")
+	staticPrefix := ""
+	if isGlobal {
+		staticPrefix = "static "
+	}
+	inlinePrefix := ""
+	if headerOnly {
+		inlinePrefix = "inline "
+	}
+	cppClassName := "C" + NameSpace + ClassName
+
+	declarationParameters := parameters
+	if isCancellable {
+		if parameters != "" {
+			parameters = parameters + ", "
+			declarationParameters = declarationParameters + ", "
+		}
+		parameters = parameters + fmt.Sprintf("C%sCancellationToken cancellationToken", NameSpace)
+		declarationParameters = declarationParameters + fmt.Sprintf("C%sCancellationToken cancellationToken = C%sCancellationToken ()", NameSpace, NameSpace)
+	}
 
 	w.Writeln("")
-	w.Writeln("project(%sExample_CPP)", NameSpace)
-	w.Writeln("set (CMAKE_CXX_STANDARD 11)")
-	// TODO: calculate relative path from ExampleOutputFolder to OUTPUTFOLDER based on CURRENT_SOURCE_DIR
-	linkfolder := strings.Replace(outputFolder, string(filepath.Separator), "/", -2)
-	w.Writeln("link_directories(\"%s\") # TODO: put the correct path of the import library here", linkfolder)
+	w.Writeln("  /**")
+	w.Writeln("  * %s::%sAsync - Calls %s on a worker thread", cppClassName, method.MethodName, method.MethodName)
+	if isCancellable {
+		w.Writeln("  * @param[in] cancellationToken - Cancels the call")
+	}
+	w.Writeln("  */")
+	w.Writeln("  %s%s %sAsync (%s);", staticPrefix, returntype, method.MethodName, declarationParameters)
 
-	outputFolder = strings.Replace(outputFolder, string(filepath.Separator), "/", -1)
-	w.Writeln("add_executable(%sExample_CPP \"${CMAKE_CURRENT_SOURCE_DIR}/%s_example.cpp\"", NameSpace, NameSpace)
-	w.Writeln("  \"%s/%s.cpp\")", outputFolder, BaseName)
-	w.Writeln("target_link_libraries(%sExample_CPP %s)", NameSpace, BaseName)
+	cppimplw.Writeln("")
+	cppimplw.Writeln("/**")
+	cppimplw.Writeln("* %s::%sAsync - Calls %s on a worker thread", cppClassName, method.MethodName, method.MethodName)
+	cppimplw.Writeln("*/")
+	cppimplw.Writeln("%s%s %s::%sAsync (%s)", inlinePrefix, returntype, cppClassName, method.MethodName, parameters)
+	cppimplw.Writeln("{")
+	cppimplw.Writelns("  ", bodyLines)
+	cppimplw.Writeln("}")
 
-	// TODO: calculate relative path from ExampleOutputFolder to OUTPUTFOLDER based on CURRENT_SOURCE_DIR
-	w.Writeln("target_include_directories(%sExample_CPP PRIVATE \"%s\")", NameSpace, outputFolder)
-	
 	return nil
 }
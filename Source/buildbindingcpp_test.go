@@ -0,0 +1,246 @@
+/*++
+
+Copyright (C) 2018 Autodesk Inc. (Original Author)
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+--*/
+
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+// buildbindingcpp_test.go
+// Tests of the C++ binding generators
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const cppAsyncTestComponent = `<?xml version="1.0" encoding="UTF-8"?>
+<component xmlns="http://schemas.autodesk.com/netfabb/automaticcomponenttoolkit/2018" libraryname="Async Library" namespace="As" copyright="Example" year="2024" basename="as" version="1.0.0">
+	<license>
+		<line value="All rights reserved." />
+	</license>
+	<bindings>
+		<binding language="Cpp" indentation="4spaces" />
+		<binding language="CppDynamic" indentation="4spaces" />
+	</bindings>
+	<errors>
+		<error name="NOTIMPLEMENTED" code="1" description="functionality not implemented" />
+		<error name="INVALIDPARAM" code="2" description="an invalid parameter was passed" />
+		<error name="INVALIDCAST" code="3" description="a type cast failed" />
+		<error name="BUFFERTOOSMALL" code="4" description="a provided buffer is too small" />
+		<error name="GENERICEXCEPTION" code="5" description="a generic exception occurred" />
+		<error name="COULDNOTLOADLIBRARY" code="6" description="the library could not be loaded" />
+		<error name="COULDNOTFINDLIBRARYEXPORT" code="7" description="a required exported symbol could not be found in the library" />
+	</errors>
+	<class name="Base" description="The base class">
+	</class>
+	<class name="Worker" parent="Base" description="A worker">
+		<method name="Wait" async="true" description="Waits and returns the number of released instances">
+			<param name="Milliseconds" type="uint32" pass="in" description="The time to wait" />
+			<param name="Released" type="uint32" pass="return" description="The number of released instances" />
+		</method>
+	</class>
+	<global releasemethod="ReleaseInstance" versionmethod="GetVersion">
+		<method name="ReleaseInstance" description="Releases an instance">
+			<param name="Instance" type="handle" class="BaseClass" pass="in" description="The instance" />
+		</method>
+		<method name="GetVersion" description="Returns the version">
+			<param name="Major" type="uint32" pass="out" description="The major version" />
+			<param name="Minor" type="uint32" pass="out" description="The minor version" />
+			<param name="Micro" type="uint32" pass="out" description="The micro version" />
+		</method>
+		<method name="CreateWorker" description="Creates a worker">
+			<param name="Instance" type="handle" class="Worker" pass="return" description="The worker" />
+		</method>
+		<method name="GetReleased" description="Returns the number of released instances">
+			<param name="Released" type="uint32" pass="return" description="The number of released instances" />
+		</method>
+	</global>
+</component>
+`
+
+// cppAsyncTestLibrary counts the released instances, so a call can tell whether its worker has been released
+const cppAsyncTestLibrary = `#include <stdint.h>
+#include <stdlib.h>
+#include <unistd.h>
+
+static volatile uint32_t released = 0;
+
+int32_t as_worker_wait (void * pWorker, uint32_t nMilliseconds, uint32_t * pReleased)
+{
+	usleep (nMilliseconds * 1000);
+	*pReleased = released;
+	return 0;
+}
+int32_t as_releaseinstance (void * pInstance) { free (pInstance); released++; return 0; }
+int32_t as_getversion (uint32_t * a, uint32_t * b, uint32_t * c) { *a = 1; *b = 0; *c = 0; return 0; }
+int32_t as_createworker (void ** pInstance) { *pInstance = calloc (1, 16); return 0; }
+int32_t as_getreleased (uint32_t * pReleased) { *pReleased = released; return 0; }
+`
+
+// The programs release the worker before they wait for the result of its call
+const cppAsyncTestProgram = `#include <iostream>
+#include "as.hpp"
+
+int main (int argc, char ** argv)
+{
+	try {
+		As::PAsWorker worker = As::CAsWrapper::CreateWorker ();
+		std::future<As_uint32> released = worker->WaitAsync (200);
+		worker.reset ();
+		As_uint32 releasedDuringCall = released.get ();
+		std::cout << releasedDuringCall << " " << As::CAsWrapper::GetReleased () << std::endl;
+	} catch (std::exception & e) {
+		std::cout << e.what () << std::endl;
+		return 1;
+	}
+	return 0;
+}
+`
+
+const cppDynamicAsyncTestProgram = `#include <iostream>
+#include "as_dynamic.hpp"
+
+int main (int argc, char ** argv)
+{
+	try {
+		As::PAsWrapper wrapper = As::CAsWrapper::loadLibrary (argv[1]);
+		As::PAsWorker worker = wrapper->CreateWorker ();
+		std::future<As_uint32> released = worker->WaitAsync (200);
+		worker.reset ();
+		As_uint32 releasedDuringCall = released.get ();
+		std::cout << releasedDuringCall << " " << wrapper->GetReleased () << std::endl;
+	} catch (std::exception & e) {
+		std::cout << e.what () << std::endl;
+		return 1;
+	}
+	return 0;
+}
+`
+
+func buildCppAsyncTestBindings(t *testing.T) (string) {
+	component, err := UnmarshalComponentDefinition([]byte(cppAsyncTestComponent), eComponentDefinitionFormatXML)
+	if (err != nil) {
+		t.Fatal(err)
+	}
+	err = CheckComponentDefinition(component)
+	if (err != nil) {
+		t.Fatal(err)
+	}
+	outputFolder := t.TempDir()
+	options := GeneratorOptions{Indentation: "4spaces"}
+	err = generateBindingCpp(component, outputFolder, options)
+	if (err != nil) {
+		t.Fatal(err)
+	}
+	err = generateBindingCppDynamic(component, outputFolder, options)
+	if (err != nil) {
+		t.Fatal(err)
+	}
+	return outputFolder
+}
+
+func TestCppAsyncMethodsKeepTheObject(t *testing.T) {
+	outputFolder := buildCppAsyncTestBindings(t)
+	for _, fileNames := range [][]string{{filepath.Join("Cpp", "as.hpp"), filepath.Join("Cpp", "as.cpp")}, {filepath.Join("CppDynamic", "as_dynamic.hpp")}} {
+		fileName := strings.Join(fileNames, ", ")
+		header := ""
+		for _, name := range fileNames {
+			data, err := ioutil.ReadFile(filepath.Join(outputFolder, "Bindings", name))
+			if (err != nil) {
+				t.Fatal(err)
+			}
+			header = header + string(data)
+		}
+		for _, expected := range []string{
+			"class CAsBaseClass : public std::enable_shared_from_this<CAsBaseClass> {",
+			"PAsWorker pThis = std::static_pointer_cast<CAsWorker> (shared_from_this ());",
+			"return pThis->Wait (nMilliseconds);",
+		} {
+			if !strings.Contains(header, expected) {
+				t.Errorf("%s does not contain %q", fileName, expected)
+			}
+		}
+	}
+}
+
+func TestCppAsyncMethodsOutliveTheObject(t *testing.T) {
+	compiler, err := exec.LookPath("g++")
+	if (err != nil) {
+		t.Skip("g++ is not available")
+	}
+
+	outputFolder := buildCppAsyncTestBindings(t)
+	files := map[string]string{
+		"library.c": cppAsyncTestLibrary,
+		"program.cpp": cppAsyncTestProgram,
+		"program_dynamic.cpp": cppDynamicAsyncTestProgram,
+	}
+	for fileName, content := range files {
+		err = ioutil.WriteFile(filepath.Join(outputFolder, fileName), []byte(content), 0644)
+		if (err != nil) {
+			t.Fatal(err)
+		}
+	}
+
+	libraryName := filepath.Join(outputFolder, "libas.so")
+	output, err := exec.Command(compiler, "-x", "c", "-shared", "-fPIC", "-o", libraryName, filepath.Join(outputFolder, "library.c")).CombinedOutput()
+	if (err != nil) {
+		t.Skipf("the test library cannot be built: %v\n%s", err, output)
+	}
+
+	programs := []struct {
+		name string
+		arguments []string
+	}{
+		{"program", []string{"program.cpp", filepath.Join(outputFolder, "Bindings", "Cpp", "as.cpp"), "-I" + filepath.Join(outputFolder, "Bindings", "Cpp"), "-L" + outputFolder, "-las", "-Wl,-rpath," + outputFolder}},
+		{"program_dynamic", []string{"program_dynamic.cpp", "-I" + filepath.Join(outputFolder, "Bindings", "CppDynamic"), "-ldl"}},
+	}
+	for _, program := range programs {
+		programName := filepath.Join(outputFolder, program.name)
+		arguments := append([]string{"-std=c++11", "-pthread", "-o", programName}, program.arguments...)
+		command := exec.Command(compiler, arguments...)
+		command.Dir = outputFolder
+		output, err = command.CombinedOutput()
+		if (err != nil) {
+			t.Fatalf("%s: %v\n%s", program.name, err, output)
+		}
+
+		output, err = exec.Command(programName, libraryName).CombinedOutput()
+		if (err != nil) {
+			t.Fatalf("%s: %v\n%s", program.name, err, output)
+		}
+		// The worker is released after the call has completed, not while it is running
+		if (strings.TrimSpace(string(output)) != "0 1") {
+			t.Errorf("%s returned %q, expected \"0 1\"", program.name, strings.TrimSpace(string(output)))
+		}
+	}
+}
@@ -0,0 +1,888 @@
+/*++
+
+Copyright (C) 2018 Autodesk Inc. (Original Author)
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+--*/
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+// buildbindingcsharp.go
+// functions to generate a C#-binding of a library's API, that calls the library with P/Invoke.
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+)
+
+// BuildBindingCSharp builds a C#-binding of a library's API, that calls the library with P/Invoke
+func BuildBindingCSharp(component ComponentDefinition, outputFolder string, indentString string) error {
+	NameSpace := component.NameSpace;
+	libraryname := component.LibraryName;
+
+	ProjectName := path.Join(outputFolder, NameSpace + ".csproj");
+	log.Printf ("Creating \"%s\"", ProjectName);
+	projectfile, err := os.Create(ProjectName);
+	if (err != nil) {
+		return err;
+	}
+	defer projectfile.Close();
+
+	fmt.Fprintf (projectfile, "<Project Sdk=\"Microsoft.NET.Sdk\">\n");
+	fmt.Fprintf (projectfile, "\n");
+	fmt.Fprintf (projectfile, "  <PropertyGroup>\n");
+	fmt.Fprintf (projectfile, "    <TargetFrameworks>netstandard2.1;net8.0</TargetFrameworks>\n");
+	fmt.Fprintf (projectfile, "    <RootNamespace>%s</RootNamespace>\n", NameSpace);
+	fmt.Fprintf (projectfile, "    <AssemblyName>%s</AssemblyName>\n", NameSpace);
+	fmt.Fprintf (projectfile, "    <Version>%s</Version>\n", component.Version);
+	fmt.Fprintf (projectfile, "    <Description>C# binding of %s</Description>\n", libraryname);
+	fmt.Fprintf (projectfile, "  </PropertyGroup>\n");
+	fmt.Fprintf (projectfile, "\n");
+	fmt.Fprintf (projectfile, "</Project>\n");
+
+	CSharpFileName := path.Join(outputFolder, NameSpace + ".cs");
+	log.Printf ("Creating \"%s\"", CSharpFileName);
+	csharpfile, err := CreateLanguageFile (CSharpFileName, indentString);
+	if (err != nil) {
+		return err;
+	}
+
+	err = csharpfile.WriteCLicenseHeader(component,
+		fmt.Sprintf ("This is an autogenerated C# file in order to allow an easy\n use of %s", libraryname),
+		true)
+	if err != nil {
+		return err
+	}
+
+	return buildCSharpBinding (component, csharpfile);
+}
+
+// validateBindingCSharp checks that the type and method names of the C#-binding are unique
+func validateBindingCSharp (component ComponentDefinition, options GeneratorOptions) (error) {
+	NameSpace := component.NameSpace;
+	identifiers := make (map[string]string);
+	for _, identifier := range []string {NameSpace, "Wrapper", "Internal", "BaseClass", "E" + NameSpace + "Exception"} {
+		identifiers[identifier] = "the C# binding";
+	}
+
+	add := func (identifier string, source string) (error) {
+		previous, ok := identifiers[identifier];
+		if (ok) {
+			return fmt.Errorf ("the C# type \"%s\" of %s collides with %s", identifier, source, previous);
+		}
+		identifiers[identifier] = source;
+		return nil;
+	}
+
+	for _, errorcode := range component.Errors.Errors {
+		err := add (errorcode.Name + "Exception", "error " + errorcode.Name);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, enum := range component.Enums {
+		err := add (enum.Name, "enum " + enum.Name);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, structinfo := range component.Structs {
+		err := add (structinfo.Name, "struct " + structinfo.Name);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, functiontype := range component.Functions {
+		err := add (functiontype.FunctionName, "functiontype " + functiontype.FunctionName);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, class := range component.Classes {
+		err := add (class.ClassName, "class " + class.ClassName);
+		if (err != nil) {
+			return err;
+		}
+	}
+
+	// C# does not allow members with the name of their type, and the binding defines some members itself
+	checkMethods := func (methods []ComponentDefinitionMethod, ClassName string, reserved []string) (error) {
+		for _, method := range methods {
+			if (method.MethodName == ClassName) {
+				return fmt.Errorf ("the C# method %s.%s has the name of its class", ClassName, method.MethodName);
+			}
+			for _, name := range reserved {
+				if (method.MethodName == name) {
+					return fmt.Errorf ("the C# method %s.%s collides with the C# binding", ClassName, method.MethodName);
+				}
+			}
+		}
+		return nil;
+	}
+	for _, class := range component.Classes {
+		err := checkMethods (class.Methods, class.ClassName, []string {"Handle", "Dispose", "Equals", "GetHashCode", "GetType", "ToString", "Finalize", "MemberwiseClone"});
+		if (err != nil) {
+			return err;
+		}
+	}
+	return checkMethods (component.Global.Methods, "Wrapper", []string {"LoadLibrary", "Equals", "GetHashCode", "GetType", "ToString", "ReferenceEquals"});
+}
+
+// csharpKeywords are the keywords of C#, that need to be escaped with an @ as identifiers
+var csharpKeywords = map[string]bool {
+	"abstract": true, "as": true, "base": true, "bool": true, "break": true, "byte": true, "case": true, "catch": true,
+	"char": true, "checked": true, "class": true, "const": true, "continue": true, "decimal": true, "default": true,
+	"delegate": true, "do": true, "double": true, "else": true, "enum": true, "event": true, "explicit": true,
+	"extern": true, "false": true, "finally": true, "fixed": true, "float": true, "for": true, "foreach": true,
+	"goto": true, "if": true, "implicit": true, "in": true, "int": true, "interface": true, "internal": true, "is": true,
+	"lock": true, "long": true, "namespace": true, "new": true, "null": true, "object": true, "operator": true,
+	"out": true, "override": true, "params": true, "private": true, "protected": true, "public": true,
+	"readonly": true, "ref": true, "return": true, "sbyte": true, "sealed": true, "short": true, "sizeof": true,
+	"stackalloc": true, "static": true, "string": true, "struct": true, "switch": true, "this": true, "throw": true,
+	"true": true, "try": true, "typeof": true, "uint": true, "ulong": true, "unchecked": true, "unsafe": true,
+	"ushort": true, "using": true, "virtual": true, "void": true, "volatile": true, "while": true,
+}
+
+// getCSharpParamName returns the camel case name of a parameter, e.g. "Value" for "value"
+func getCSharpParamName (name string) (string) {
+	paramName := strings.ToLower (name[0:1]) + name[1:];
+	if (csharpKeywords[paramName]) {
+		return "@" + paramName;
+	}
+	return paramName;
+}
+
+// getCSharpBasicType returns the C# type of a scalar type of the IDL
+func getCSharpBasicType (paramType string) (string, error) {
+	switch (paramType) {
+		case "uint8":
+			return "Byte", nil;
+		case "uint16":
+			return "UInt16", nil;
+		case "uint32":
+			return "UInt32", nil;
+		case "uint64":
+			return "UInt64", nil;
+		case "int8":
+			return "SByte", nil;
+		case "int16":
+			return "Int16", nil;
+		case "int32":
+			return "Int32", nil;
+		case "int64":
+			return "Int64", nil;
+		case "bool":
+			return "Boolean", nil;
+		case "single":
+			return "Single", nil;
+		case "double":
+			return "Double", nil;
+	}
+	return "", fmt.Errorf ("invalid basic type \"%s\"", paramType);
+}
+
+// getCSharpParamType returns the C# type of a parameter in the public methods
+func getCSharpParamType (param ComponentDefinitionParam) (string, error) {
+	switch (param.ParamType) {
+		case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "bool", "single", "double":
+			return getCSharpBasicType (param.ParamType);
+		case "string":
+			return "String", nil;
+		case "enum", "struct", "handle", "functiontype":
+			return param.ParamClass, nil;
+		case "basicarray":
+			basicType, err := getCSharpBasicType (param.ParamClass);
+			if (err != nil) {
+				return "", err;
+			}
+			return basicType + "[]", nil;
+		case "structarray":
+			return param.ParamClass + "[]", nil;
+	}
+	return "", fmt.Errorf ("invalid parameter type \"%s\"", param.ParamType);
+}
+
+// writeCSharpComment writes an XML documentation comment
+func writeCSharpComment (w LanguageWriter, indent string, description string, params [][2]string, returns string) {
+	w.Writeln (indent + "/// <summary>");
+	w.Writeln (indent + "/// %s", description);
+	w.Writeln (indent + "/// </summary>");
+	for _, param := range params {
+		w.Writeln (indent + "/// <param name=\"%s\">%s</param>", strings.TrimPrefix (param[0], "@"), param[1]);
+	}
+	if (returns != "") {
+		w.Writeln (indent + "/// <returns>%s</returns>", returns);
+	}
+}
+
+func buildCSharpBinding (component ComponentDefinition, w LanguageWriter) (error) {
+	NameSpace := component.NameSpace;
+
+	w.Writeln ("");
+	w.Writeln ("using System;");
+	w.Writeln ("using System.Runtime.InteropServices;");
+	w.Writeln ("using System.Text;");
+	w.Writeln ("");
+	w.Writeln ("namespace %s", NameSpace);
+	w.Writeln ("{");
+
+	err := writeCSharpTypes (component, w);
+	if (err != nil) {
+		return err;
+	}
+	err = writeCSharpExceptions (component, w);
+	if (err != nil) {
+		return err;
+	}
+	err = writeCSharpInternal (component, w);
+	if (err != nil) {
+		return err;
+	}
+
+	releaseExport := GetCExportName (NameSpace, "", ComponentDefinitionMethod {MethodName: component.Global.ReleaseMethod}, true);
+
+	w.Writeln ("");
+	w.Writeln ("  /// <summary>");
+	w.Writeln ("  /// BaseClass is the base of all classes of %s and releases their instances.", component.LibraryName);
+	w.Writeln ("  /// </summary>");
+	w.Writeln ("  public abstract class BaseClass : IDisposable");
+	w.Writeln ("  {");
+	w.Writeln ("    private IntPtr handle;");
+	w.Writeln ("");
+	w.Writeln ("    protected BaseClass (IntPtr handle)");
+	w.Writeln ("    {");
+	w.Writeln ("      this.handle = handle;");
+	w.Writeln ("    }");
+	w.Writeln ("");
+	w.Writeln ("    ~BaseClass ()");
+	w.Writeln ("    {");
+	w.Writeln ("      Dispose (false);");
+	w.Writeln ("    }");
+	w.Writeln ("");
+	w.Writeln ("    /// <summary>");
+	w.Writeln ("    /// The handle of the instance, or IntPtr.Zero after it has been released");
+	w.Writeln ("    /// </summary>");
+	w.Writeln ("    public IntPtr Handle");
+	w.Writeln ("    {");
+	w.Writeln ("      get { return handle; }");
+	w.Writeln ("    }");
+	w.Writeln ("");
+	w.Writeln ("    /// <summary>");
+	w.Writeln ("    /// Releases the instance");
+	w.Writeln ("    /// </summary>");
+	w.Writeln ("    public void Dispose ()");
+	w.Writeln ("    {");
+	w.Writeln ("      Dispose (true);");
+	w.Writeln ("      GC.SuppressFinalize (this);");
+	w.Writeln ("    }");
+	w.Writeln ("");
+	w.Writeln ("    protected virtual void Dispose (bool disposing)");
+	w.Writeln ("    {");
+	w.Writeln ("      if (handle != IntPtr.Zero) {");
+	w.Writeln ("        Internal.%s (handle);", releaseExport);
+	w.Writeln ("        handle = IntPtr.Zero;");
+	w.Writeln ("      }");
+	w.Writeln ("    }");
+	w.Writeln ("  }");
+
+	for _, class := range component.Classes {
+		err = writeCSharpClass (component, class, w);
+		if (err != nil) {
+			return err;
+		}
+	}
+
+	err = writeCSharpWrapper (component, w);
+	if (err != nil) {
+		return err;
+	}
+
+	w.Writeln ("");
+	w.Writeln ("}");
+	return nil;
+}
+
+// writeCSharpTypes writes the enums, structs and delegates of the component
+func writeCSharpTypes (component ComponentDefinition, w LanguageWriter) (error) {
+	for _, enum := range component.Enums {
+		w.Writeln ("");
+		w.Writeln ("  public enum %s : Int32", enum.Name);
+		w.Writeln ("  {");
+		for i, option := range enum.Options {
+			separator := ",";
+			if (i == len (enum.Options) - 1) {
+				separator = "";
+			}
+			w.Writeln ("    %s = %d%s", option.Name, option.Value, separator);
+		}
+		w.Writeln ("  }");
+	}
+
+	for _, structinfo := range component.Structs {
+		w.Writeln ("");
+		w.Writeln ("  [StructLayout (LayoutKind.Sequential, Pack = 1)]");
+		w.Writeln ("  public struct %s", structinfo.Name);
+		w.Writeln ("  {");
+		for _, member := range structinfo.Members {
+			memberType := member.Class;
+			if (member.Type != "enum") {
+				basicType, err := getCSharpBasicType (member.Type);
+				if (err != nil) {
+					return fmt.Errorf ("invalid member \"%s\" of struct \"%s\": %s", member.Name, structinfo.Name, err.Error ());
+				}
+				memberType = basicType;
+			}
+
+			if (member.Rows > 0) {
+				// The C arrays of the struct are flattened in the order of their memory layout
+				count := member.Rows;
+				if (member.Columns > 0) {
+					count = member.Rows * member.Columns;
+				}
+				if (member.Type == "bool") {
+					w.Writeln ("    [MarshalAs (UnmanagedType.ByValArray, SizeConst = %d, ArraySubType = UnmanagedType.U1)]", count);
+				} else {
+					w.Writeln ("    [MarshalAs (UnmanagedType.ByValArray, SizeConst = %d)]", count);
+				}
+				memberType = memberType + "[]";
+			} else if (member.Type == "bool") {
+				w.Writeln ("    [MarshalAs (UnmanagedType.U1)]");
+			}
+			w.Writeln ("    public %s %s;", memberType, member.Name);
+		}
+		w.Writeln ("  }");
+	}
+
+	for _, functiontype := range component.Functions {
+		parameters := []string {};
+		comments := [][2]string {};
+		for _, param := range functiontype.Params {
+			attribute := "";
+			paramType := "";
+			switch (param.ParamType) {
+				case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "single", "double":
+					basicType, err := getCSharpBasicType (param.ParamType);
+					if (err != nil) {
+						return err;
+					}
+					paramType = basicType;
+				case "bool":
+					attribute = "[MarshalAs (UnmanagedType.U1)] ";
+					paramType = "Boolean";
+				case "enum":
+					paramType = param.ParamClass;
+				case "string":
+					if (param.ParamPass != "in") {
+						return fmt.Errorf ("parameter type \"%s\" of %s (%s) is only supported as input by C# callbacks", param.ParamType, functiontype.FunctionName, param.ParamName);
+					}
+					attribute = "[MarshalAs (UnmanagedType.LPUTF8Str)] ";
+					paramType = "String";
+				case "handle":
+					paramType = "IntPtr";
+				default:
+					return fmt.Errorf ("parameter type \"%s\" of %s (%s) is not supported by C# callbacks", param.ParamType, functiontype.FunctionName, param.ParamName);
+			}
+			if (param.ParamPass != "in") {
+				paramType = "out " + paramType;
+			}
+			paramName := getCSharpParamName (param.ParamName);
+			parameters = append (parameters, attribute + paramType + " " + paramName);
+			comments = append (comments, [2]string {paramName, param.ParamDescription});
+		}
+
+		w.Writeln ("");
+		writeCSharpComment (w, "  ", functiontype.FunctionDescription, comments, "");
+		w.Writeln ("  [UnmanagedFunctionPointer (CallingConvention.Cdecl)]");
+		w.Writeln ("  public delegate void %s (%s);", functiontype.FunctionName, strings.Join (parameters, ", "));
+	}
+
+	return nil;
+}
+
+// writeCSharpExceptions writes an exception class for every error of the component
+func writeCSharpExceptions (component ComponentDefinition, w LanguageWriter) (error) {
+	NameSpace := component.NameSpace;
+	_, hasErrorMethod := component.Global.GetErrorMethod ();
+
+	w.Writeln ("");
+	w.Writeln ("  /// <summary>");
+	w.Writeln ("  /// E%sException is thrown if a function of %s fails", NameSpace, component.LibraryName);
+	w.Writeln ("  /// </summary>");
+	w.Writeln ("  public class E%sException : Exception", NameSpace);
+	w.Writeln ("  {");
+	w.Writeln ("    private readonly Int32 errorCode;");
+	w.Writeln ("");
+	if (hasErrorMethod) {
+		w.Writeln ("    public E%sException (Int32 errorCode, String errorName, String errorDescription, String errorMessage = null)", NameSpace);
+		w.Writeln ("      : base (String.IsNullOrEmpty (errorMessage) ? String.Format (\"%s Error {0} ({1}: {2})\", errorCode, errorName, errorDescription)", component.LibraryName);
+		w.Writeln ("        : String.Format (\"%s Error {0} ({1}: {2}): {3}\", errorCode, errorName, errorDescription, errorMessage))", component.LibraryName);
+	} else {
+		w.Writeln ("    public E%sException (Int32 errorCode, String errorName, String errorDescription)", NameSpace);
+		w.Writeln ("      : base (String.Format (\"%s Error {0} ({1}: {2})\", errorCode, errorName, errorDescription))", component.LibraryName);
+	}
+	w.Writeln ("    {");
+	w.Writeln ("      this.errorCode = errorCode;");
+	w.Writeln ("    }");
+	w.Writeln ("");
+	w.Writeln ("    /// <summary>");
+	w.Writeln ("    /// The error code that %s returned", component.LibraryName);
+	w.Writeln ("    /// </summary>");
+	w.Writeln ("    public Int32 ErrorCode");
+	w.Writeln ("    {");
+	w.Writeln ("      get { return errorCode; }");
+	w.Writeln ("    }");
+	w.Writeln ("  }");
+
+	for _, errorcode := range component.Errors.Errors {
+		w.Writeln ("");
+		w.Writeln ("  /// <summary>");
+		w.Writeln ("  /// %sException is thrown for the error %s: %s", errorcode.Name, errorcode.Name, errorcode.Description);
+		w.Writeln ("  /// </summary>");
+		w.Writeln ("  public class %sException : E%sException", errorcode.Name, NameSpace);
+		w.Writeln ("  {");
+		w.Writeln ("    public const Int32 Code = %d;", errorcode.Code);
+		w.Writeln ("");
+		if (hasErrorMethod) {
+			w.Writeln ("    public %sException (String errorMessage = null)", errorcode.Name);
+			w.Writeln ("      : base (Code, \"%s\", \"%s\", errorMessage)", errorcode.Name, errorcode.Description);
+		} else {
+			w.Writeln ("    public %sException ()", errorcode.Name);
+			w.Writeln ("      : base (Code, \"%s\", \"%s\")", errorcode.Name, errorcode.Description);
+		}
+		w.Writeln ("    {");
+		w.Writeln ("    }");
+		w.Writeln ("  }");
+	}
+
+	return nil;
+}
+
+// getCSharpImportParameters returns the parameters of the P/Invoke declaration of a method, named like the parameters of the C-header
+func getCSharpImportParameters (method ComponentDefinitionMethod, NameSpace string, ClassName string) ([]string, error) {
+	importParams := []string {};
+	for _, param := range method.Params {
+		cParams, err := generateCParameter (param, ClassName, method.MethodName, NameSpace);
+		if (err != nil) {
+			return nil, err;
+		}
+
+		baseType := "";
+		switch (param.ParamType) {
+			case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "single", "double":
+				basicType, err := getCSharpBasicType (param.ParamType);
+				if (err != nil) {
+					return nil, err;
+				}
+				baseType = basicType;
+			case "bool":
+				baseType = "[MarshalAs (UnmanagedType.U1)] Boolean";
+			case "enum", "struct", "functiontype":
+				baseType = param.ParamClass;
+			case "handle":
+				baseType = "IntPtr";
+			case "string":
+				baseType = "Byte[]";
+			case "basicarray", "structarray":
+				elementType := param.ParamClass;
+				if (param.ParamType == "basicarray") {
+					basicType, err := getCSharpBasicType (param.ParamClass);
+					if (err != nil) {
+						return nil, err;
+					}
+					elementType = basicType;
+				}
+				if (param.ParamClass == "bool") {
+					baseType = "[MarshalAs (UnmanagedType.LPArray, ArraySubType = UnmanagedType.U1)] " + elementType + "[]";
+				} else {
+					baseType = elementType + "[]";
+				}
+			default:
+				return nil, fmt.Errorf ("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName);
+		}
+
+		switch (param.ParamPass) {
+			case "in":
+				switch (param.ParamType) {
+					case "struct":
+						importParams = append (importParams, fmt.Sprintf ("[In] ref %s %s", baseType, cParams[0].ParamName));
+					case "basicarray", "structarray":
+						importParams = append (importParams, fmt.Sprintf ("UInt64 %s", cParams[0].ParamName), fmt.Sprintf ("[In] %s %s", baseType, cParams[1].ParamName));
+					default:
+						importParams = append (importParams, fmt.Sprintf ("%s %s", baseType, cParams[0].ParamName));
+				}
+			case "out", "return":
+				switch (param.ParamType) {
+					case "string":
+						importParams = append (importParams, fmt.Sprintf ("UInt32 %s", cParams[0].ParamName), fmt.Sprintf ("out UInt32 %s", cParams[1].ParamName), fmt.Sprintf ("[Out] %s %s", baseType, cParams[2].ParamName));
+					case "basicarray", "structarray":
+						importParams = append (importParams, fmt.Sprintf ("UInt64 %s", cParams[0].ParamName), fmt.Sprintf ("out UInt64 %s", cParams[1].ParamName), fmt.Sprintf ("[Out] %s %s", baseType, cParams[2].ParamName));
+					case "functiontype":
+						return nil, fmt.Errorf ("method parameter type \"%s\" of param pass \"%s\" is not implemented for %s.%s (%s)", param.ParamType, param.ParamPass, ClassName, method.MethodName, param.ParamName);
+					case "bool":
+						importParams = append (importParams, fmt.Sprintf ("[MarshalAs (UnmanagedType.U1)] out Boolean %s", cParams[0].ParamName));
+					default:
+						importParams = append (importParams, fmt.Sprintf ("out %s %s", baseType, cParams[0].ParamName));
+				}
+			default:
+				return nil, fmt.Errorf ("invalid method parameter passing \"%s\" for %s.%s (%s)", param.ParamPass, ClassName, method.MethodName, param.ParamName);
+		}
+	}
+	return importParams, nil;
+}
+
+// writeCSharpInternal writes the P/Invoke declarations of the exported functions and the conversions of errors and strings
+func writeCSharpInternal (component ComponentDefinition, w LanguageWriter) (error) {
+	NameSpace := component.NameSpace;
+
+	w.Writeln ("");
+	w.Writeln ("  internal static class Internal");
+	w.Writeln ("  {");
+	w.Writeln ("    internal const String DllName = \"%s\";", component.BaseName);
+
+	writeImports := func (methods []ComponentDefinitionMethod, ClassName string, isGlobal bool) (error) {
+		for _, method := range methods {
+			importParams, err := getCSharpImportParameters (method, NameSpace, ClassName);
+			if (err != nil) {
+				return err;
+			}
+			if (!isGlobal) {
+				importParams = append ([]string {"IntPtr " + ClassName}, importParams...);
+			}
+			exportName := GetCExportName (NameSpace, ClassName, method, isGlobal);
+			w.Writeln ("");
+			w.Writeln ("    [DllImport (DllName, EntryPoint = \"%s\", CallingConvention = CallingConvention.Cdecl)]", exportName);
+			w.Writeln ("    internal static extern Int32 %s (%s);", exportName, strings.Join (importParams, ", "));
+		}
+		return nil;
+	}
+	for _, class := range component.Classes {
+		err := writeImports (class.Methods, class.ClassName, false);
+		if (err != nil) {
+			return err;
+		}
+	}
+	err := writeImports (component.Global.Methods, "Wrapper", true);
+	if (err != nil) {
+		return err;
+	}
+
+	errorMethod, hasErrorMethod := component.Global.GetErrorMethod ();
+	w.Writeln ("");
+	if (hasErrorMethod) {
+		errorExport := GetCExportName (NameSpace, "", errorMethod, true);
+		w.Writeln ("    internal static void CheckError (Int32 errorCode)");
+		w.Writeln ("    {");
+		w.Writeln ("      CheckError (errorCode, IntPtr.Zero);");
+		w.Writeln ("    }");
+		w.Writeln ("");
+		w.Writeln ("    internal static void CheckError (Int32 errorCode, IntPtr instance)");
+		w.Writeln ("    {");
+		w.Writeln ("      if (errorCode == 0) {");
+		w.Writeln ("        return;");
+		w.Writeln ("      }");
+		w.Writeln ("");
+		w.Writeln ("      String errorMessage = GetErrorMessage (instance);");
+		w.Writeln ("      switch (errorCode) {");
+		for _, errorcode := range component.Errors.Errors {
+			w.Writeln ("        case %d: throw new %sException (errorMessage);", errorcode.Code, errorcode.Name);
+		}
+		w.Writeln ("        default: throw new E%sException (errorCode, \"UNKNOWN\", \"unknown error\", errorMessage);", NameSpace);
+		w.Writeln ("      }");
+		w.Writeln ("    }");
+		w.Writeln ("");
+		w.Writeln ("    private static String GetErrorMessage (IntPtr instance)");
+		w.Writeln ("    {");
+		w.Writeln ("      UInt32 neededChars = 0;");
+		w.Writeln ("      Boolean hasError;");
+		w.Writeln ("      if ((%s (instance, 0, out neededChars, null, out hasError) != 0) || !hasError) {", errorExport);
+		w.Writeln ("        return null;");
+		w.Writeln ("      }");
+		w.Writeln ("");
+		w.Writeln ("      Byte[] buffer = new Byte[neededChars + 1];");
+		w.Writeln ("      if (%s (instance, (UInt32) buffer.Length, out neededChars, buffer, out hasError) != 0) {", errorExport);
+		w.Writeln ("        return null;");
+		w.Writeln ("      }");
+		w.Writeln ("      return FromUTF8 (buffer);");
+		w.Writeln ("    }");
+	} else {
+		w.Writeln ("    internal static void CheckError (Int32 errorCode)");
+		w.Writeln ("    {");
+		w.Writeln ("      switch (errorCode) {");
+		w.Writeln ("        case 0: return;");
+		for _, errorcode := range component.Errors.Errors {
+			w.Writeln ("        case %d: throw new %sException ();", errorcode.Code, errorcode.Name);
+		}
+		w.Writeln ("        default: throw new E%sException (errorCode, \"UNKNOWN\", \"unknown error\");", NameSpace);
+		w.Writeln ("      }");
+		w.Writeln ("    }");
+	}
+	w.Writeln ("");
+	w.Writeln ("    internal static Byte[] ToUTF8 (String value)");
+	w.Writeln ("    {");
+	w.Writeln ("      Byte[] bytes = Encoding.UTF8.GetBytes (value);");
+	w.Writeln ("      Array.Resize (ref bytes, bytes.Length + 1);");
+	w.Writeln ("      return bytes;");
+	w.Writeln ("    }");
+	w.Writeln ("");
+	w.Writeln ("    internal static String FromUTF8 (Byte[] buffer)");
+	w.Writeln ("    {");
+	w.Writeln ("      Int32 length = Array.IndexOf (buffer, (Byte) 0);");
+	w.Writeln ("      if (length < 0) {");
+	w.Writeln ("        length = buffer.Length;");
+	w.Writeln ("      }");
+	w.Writeln ("      return Encoding.UTF8.GetString (buffer, 0, length);");
+	w.Writeln ("    }");
+	w.Writeln ("  }");
+
+	return nil;
+}
+
+// isCSharpMethodInherited returns whether a parent class already has a method of the name, which the method then hides
+func isCSharpMethodInherited (component ComponentDefinition, class ComponentDefinitionClass, methodName string) (bool) {
+	parentName := class.ParentClass;
+	for (parentName != "") {
+		found := false;
+		for _, parent := range component.Classes {
+			if (parent.ClassName != parentName) {
+				continue;
+			}
+			for _, method := range parent.Methods {
+				if (method.MethodName == methodName) {
+					return true;
+				}
+			}
+			parentName = parent.ParentClass;
+			found = true;
+			break;
+		}
+		if (!found) {
+			break;
+		}
+	}
+	return false;
+}
+
+// writeCSharpClass writes the class definition of a class of the component
+func writeCSharpClass (component ComponentDefinition, class ComponentDefinitionClass, w LanguageWriter) (error) {
+	parentClass := class.ParentClass;
+	if (parentClass == "") {
+		parentClass = "BaseClass";
+	}
+
+	w.Writeln ("");
+	description := class.ClassDescription;
+	if (description == "") {
+		description = fmt.Sprintf ("The class %s of %s", class.ClassName, component.LibraryName);
+	}
+	writeCSharpComment (w, "  ", description, nil, "");
+	w.Writeln ("  public class %s : %s", class.ClassName, parentClass);
+	w.Writeln ("  {");
+	w.Writeln ("    internal %s (IntPtr handle)", class.ClassName);
+	w.Writeln ("      : base (handle)");
+	w.Writeln ("    {");
+	w.Writeln ("    }");
+
+	for _, method := range class.Methods {
+		modifiers := "public";
+		if (isCSharpMethodInherited (component, class, method.MethodName)) {
+			modifiers = "public new";
+		}
+		err := writeCSharpMethod (component, method, w, class.ClassName, false, modifiers);
+		if (err != nil) {
+			return err;
+		}
+	}
+	w.Writeln ("  }");
+	return nil;
+}
+
+// writeCSharpWrapper writes the static class of the global functions of the component
+func writeCSharpWrapper (component ComponentDefinition, w LanguageWriter) (error) {
+	w.Writeln ("");
+	w.Writeln ("  /// <summary>");
+	w.Writeln ("  /// Wrapper gives access to the global functions of %s", component.LibraryName);
+	w.Writeln ("  /// </summary>");
+	w.Writeln ("  public static class Wrapper");
+	w.Writeln ("  {");
+	w.Writeln ("#if NETCOREAPP3_0_OR_GREATER");
+	w.Writeln ("    private static IntPtr libraryHandle = IntPtr.Zero;");
+	w.Writeln ("");
+	w.Writeln ("    /// <summary>");
+	w.Writeln ("    /// Loads %s from a file, instead of searching the library \"%s\" in the default paths.", component.LibraryName, component.BaseName);
+	w.Writeln ("    /// It must be called before any other function of the binding.");
+	w.Writeln ("    /// </summary>");
+	w.Writeln ("    /// <param name=\"fileName\">The file name of the library</param>");
+	w.Writeln ("    public static void LoadLibrary (String fileName)");
+	w.Writeln ("    {");
+	w.Writeln ("      IntPtr handle = NativeLibrary.Load (fileName);");
+	w.Writeln ("      if (libraryHandle == IntPtr.Zero) {");
+	w.Writeln ("        NativeLibrary.SetDllImportResolver (typeof (Wrapper).Assembly, (libraryName, assembly, searchPath) =>");
+	w.Writeln ("          (libraryName == Internal.DllName) ? libraryHandle : IntPtr.Zero);");
+	w.Writeln ("      }");
+	w.Writeln ("      libraryHandle = handle;");
+	w.Writeln ("    }");
+	w.Writeln ("#endif");
+
+	for _, method := range component.Global.Methods {
+		if (method.MethodName == component.Global.ReleaseMethod) {
+			continue;
+		}
+		err := writeCSharpMethod (component, method, w, "Wrapper", true, "public static");
+		if (err != nil) {
+			return err;
+		}
+	}
+	w.Writeln ("  }");
+	return nil;
+}
+
+// csharpStringOutput passes the buffer of a string output parameter as byte array
+var csharpStringOutput = CStringOutputSyntax {
+	Declaration: "UInt32 needed%[1]s = 0;",
+	NeededChars: "needed%[1]s",
+	Allocation: "Byte[] buffer%[1]s = new Byte[%[2]s];",
+	QueryArguments: "0, out needed%[1]s, null",
+	CallArguments: "(UInt32) buffer%[1]s.Length, out needed%[1]s, buffer%[1]s",
+};
+
+// writeCSharpMethod writes a method, that calls the exported function of the library and converts its parameters
+func writeCSharpMethod (component ComponentDefinition, method ComponentDefinitionMethod, w LanguageWriter, ClassName string, isGlobal bool, modifiers string) (error) {
+	NameSpace := component.NameSpace;
+
+	parameters := []string {};
+	comments := [][2]string {};
+	returnComment := "";
+	returnType := "void";
+	var calls CBufferCalls;
+	conversions := []string {};
+	keepAlive := []string {};
+	returnValue := "";
+
+	if (!isGlobal) {
+		calls.AddArgument ("Handle");
+	}
+
+	for _, param := range method.Params {
+		paramName := getCSharpParamName (param.ParamName);
+		localName := strings.TrimPrefix (paramName, "@");
+		localName = strings.ToUpper (localName[0:1]) + localName[1:];
+		paramType, err := getCSharpParamType (param);
+		if (err != nil) {
+			return fmt.Errorf ("%s for %s.%s (%s)", err.Error (), ClassName, method.MethodName, param.ParamName);
+		}
+
+		switch (param.ParamPass) {
+		case "in":
+			parameters = append (parameters, paramType + " " + paramName);
+			comments = append (comments, [2]string {paramName, param.ParamDescription});
+			argument := paramName;
+			switch (param.ParamType) {
+				case "string":
+					calls.Declarations = append (calls.Declarations, fmt.Sprintf ("Byte[] bytes%s = Internal.ToUTF8 (%s);", localName, paramName));
+					argument = "bytes" + localName;
+				case "struct":
+					argument = "ref " + paramName;
+				case "basicarray", "structarray":
+					argument = fmt.Sprintf ("(UInt64) %s.Length, %s", paramName, paramName);
+				case "handle":
+					argument = fmt.Sprintf ("(%s != null) ? %s.Handle : IntPtr.Zero", paramName, paramName);
+				case "functiontype":
+					keepAlive = append (keepAlive, paramName);
+			}
+			calls.AddArgument (argument);
+
+		case "out", "return":
+			variable := paramName;
+			if (param.ParamPass == "return") {
+				variable = "result" + localName;
+				returnType = paramType;
+				returnComment = param.ParamDescription;
+				returnValue = variable;
+			} else {
+				parameters = append (parameters, "out " + paramType + " " + paramName);
+				comments = append (comments, [2]string {paramName, param.ParamDescription});
+			}
+
+			switch (param.ParamType) {
+				case "string":
+					calls.AddStringOutput (csharpStringOutput, localName);
+					conversions = append (conversions, fmt.Sprintf ("%s%s = Internal.FromUTF8 (buffer%s);", getCSharpDeclaration (param, paramType), variable, localName));
+				case "basicarray", "structarray":
+					elementType := strings.TrimSuffix (paramType, "[]");
+					calls.Declarations = append (calls.Declarations, fmt.Sprintf ("UInt64 needed%s = 0;", localName));
+					calls.AddBufferArgument (fmt.Sprintf ("0, out needed%s, null", localName), fmt.Sprintf ("needed%s, out needed%s, %s", localName, localName, variable),
+						fmt.Sprintf ("%s%s = new %s[needed%s];", getCSharpDeclaration (param, paramType), variable, elementType, localName));
+				case "handle":
+					calls.Declarations = append (calls.Declarations, fmt.Sprintf ("IntPtr handle%s = IntPtr.Zero;", localName));
+					calls.AddArgument ("out handle" + localName);
+					conversions = append (conversions, fmt.Sprintf ("%s%s = (handle%s != IntPtr.Zero) ? new %s (handle%s) : null;", getCSharpDeclaration (param, paramType), variable, localName, param.ParamClass, localName));
+				default:
+					if (param.ParamPass == "return") {
+						calls.Declarations = append (calls.Declarations, fmt.Sprintf ("%s %s;", paramType, variable));
+					}
+					calls.AddArgument ("out " + variable);
+			}
+
+		default:
+			return fmt.Errorf ("invalid method parameter passing \"%s\" for %s.%s (%s)", param.ParamPass, ClassName, method.MethodName, param.ParamName);
+		}
+	}
+
+	exportName := GetCExportName (NameSpace, ClassName, method, isGlobal);
+
+	w.Writeln ("");
+	writeCSharpComment (w, "    ", method.MethodDescription, comments, returnComment);
+	w.Writeln ("    %s %s %s (%s)", modifiers, returnType, method.MethodName, strings.Join (parameters, ", "));
+	w.Writeln ("    {");
+	w.Writelns ("      ", calls.Declarations);
+	checkInstance := "";
+	_, hasErrorMethod := component.Global.GetErrorMethod ();
+	if (hasErrorMethod && !isGlobal) {
+		checkInstance = ", Handle";
+	}
+	calls.WriteCalls (w, "      ", fmt.Sprintf ("Internal.CheckError (Internal.%s (%%s)%s);", exportName, checkInstance), nil);
+	for _, name := range keepAlive {
+		w.Writeln ("      GC.KeepAlive (%s);", name);
+	}
+	w.Writelns ("      ", conversions);
+	if (returnValue != "") {
+		w.Writeln ("      return %s;", returnValue);
+	}
+	w.Writeln ("    }");
+
+	return nil;
+}
+
+// getCSharpDeclaration returns the type declaration of the local variable of a return value
+func getCSharpDeclaration (param ComponentDefinitionParam, paramType string) (string) {
+	if (param.ParamPass == "return") {
+		return paramType + " ";
+	}
+	return "";
+}
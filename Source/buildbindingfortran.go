@@ -0,0 +1,1053 @@
+/*++
+
+Copyright (C) 2018 Autodesk Inc. (Original Author)
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+--*/
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+// buildbindingfortran.go
+// functions to generate a Fortran module of a library's API, that calls the library via iso_c_binding.
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"strings"
+)
+
+// fortranMaxLineLength is the length after which the arguments of a statement are continued on the next line.
+// Free form Fortran allows 132 characters, which leaves room for the end of the statement.
+const fortranMaxLineLength = 100;
+
+// BuildBindingFortran builds a Fortran module of a library's API, that calls the library via iso_c_binding
+func BuildBindingFortran(component ComponentDefinition, outputFolder string, indentString string) error {
+	libraryname := component.LibraryName;
+	baseName := component.BaseName;
+
+	FortranFileName := path.Join(outputFolder, baseName + ".f90");
+	log.Printf ("Creating \"%s\"", FortranFileName);
+	fortranfile, err := CreateLanguageFile (FortranFileName, indentString);
+	if (err != nil) {
+		return err;
+	}
+
+	err = fortranfile.WriteFortranLicenseHeader(component,
+		fmt.Sprintf ("This is an autogenerated Fortran file in order to allow an easy\n use of %s", libraryname),
+		true)
+	if err != nil {
+		return err
+	}
+
+	return buildFortranBinding (component, fortranfile);
+}
+
+// validateBindingFortran checks that the identifiers of the Fortran module are unique, as Fortran is not case sensitive
+func validateBindingFortran (component ComponentDefinition, options GeneratorOptions) (error) {
+	NameSpace := component.NameSpace;
+
+	identifiers := make (map[string]string);
+	add := func (identifier string, source string) (error) {
+		if (len (identifier) > 63) {
+			return fmt.Errorf ("the Fortran identifier \"%s\" of %s is longer than 63 characters", identifier, source);
+		}
+		previous, ok := identifiers[strings.ToUpper (identifier)];
+		if (ok) {
+			return fmt.Errorf ("the Fortran identifier \"%s\" of %s collides with %s", identifier, source, previous);
+		}
+		identifiers[strings.ToUpper (identifier)] = source;
+		return nil;
+	}
+
+	for _, identifier := range getFortranReservedNames (component) {
+		err := add (identifier, "the Fortran binding");
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, errorcode := range component.Errors.Errors {
+		err := add (getFortranErrorConstant (NameSpace, errorcode.Name), "error " + errorcode.Name);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, enum := range component.Enums {
+		for _, option := range enum.Options {
+			err := add (getFortranEnumConstant (NameSpace, enum.Name, option.Name), "enum " + enum.Name + "." + option.Name);
+			if (err != nil) {
+				return err;
+			}
+		}
+	}
+	for _, structinfo := range component.Structs {
+		for _, identifier := range []string {getFortranTypeName (NameSpace, structinfo.Name), getFortranStructSize (NameSpace, structinfo.Name),
+			getFortranPackName (NameSpace, structinfo.Name, true), getFortranPackName (NameSpace, structinfo.Name, false)} {
+			err := add (identifier, "struct " + structinfo.Name);
+			if (err != nil) {
+				return err;
+			}
+		}
+	}
+	for _, functiontype := range component.Functions {
+		err := add (getFortranTypeName (NameSpace, functiontype.FunctionName), "functiontype " + functiontype.FunctionName);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, class := range component.Classes {
+		err := add (getFortranTypeName (NameSpace, class.ClassName), "class " + class.ClassName);
+		if (err != nil) {
+			return err;
+		}
+	}
+
+	// The parameters must neither collide with the module nor with the locals of the wrapper procedures
+	checkParams := func (params []ComponentDefinitionParam, source string) (error) {
+		names := make (map[string]bool);
+		for _, name := range []string {"SELF", "ERROR", "ERRORCODE", "I"} {
+			names[name] = true;
+		}
+		for _, param := range params {
+			name := strings.ToUpper (param.ParamName);
+			if (names[name]) || (identifiers[name] != "") {
+				return fmt.Errorf ("the Fortran name of parameter \"%s\" of %s collides with the Fortran binding", param.ParamName, source);
+			}
+			for _, prefix := range []string {"NEEDED_", "BUFFER_", "VALUE_"} {
+				if (strings.HasPrefix (name, prefix)) {
+					return fmt.Errorf ("the Fortran name of parameter \"%s\" of %s collides with the Fortran binding", param.ParamName, source);
+				}
+			}
+			names[name] = true;
+		}
+		return nil;
+	}
+
+	checkMethods := func (methods []ComponentDefinitionMethod, ClassName string, isGlobal bool) (error) {
+		for _, method := range methods {
+			source := "method " + ClassName + "." + method.MethodName;
+			if (isGlobal) {
+				source = "global method " + method.MethodName;
+			}
+			CMethodName := GetCExportName (NameSpace, ClassName, method, isGlobal);
+			err := add (CMethodName, source);
+			if (err != nil) {
+				return err;
+			}
+			err = add ("c_" + CMethodName, source);
+			if (err != nil) {
+				return err;
+			}
+		}
+		return nil;
+	}
+	for _, class := range component.Classes {
+		err := checkMethods (class.Methods, class.ClassName, false);
+		if (err != nil) {
+			return err;
+		}
+	}
+	err := checkMethods (component.Global.Methods, "Wrapper", true);
+	if (err != nil) {
+		return err;
+	}
+
+	for _, class := range component.Classes {
+		for _, method := range class.Methods {
+			err := checkParams (method.Params, "method " + class.ClassName + "." + method.MethodName);
+			if (err != nil) {
+				return err;
+			}
+		}
+	}
+	for _, method := range component.Global.Methods {
+		err := checkParams (method.Params, "global method " + method.MethodName);
+		if (err != nil) {
+			return err;
+		}
+	}
+	return nil;
+}
+
+// getFortranReservedNames returns the module level names that the Fortran binding declares itself
+func getFortranReservedNames (component ComponentDefinition) ([]string) {
+	NameSpace := component.NameSpace;
+	lowerNameSpace := strings.ToLower (NameSpace);
+	upperNameSpace := strings.ToUpper (NameSpace);
+	return []string {lowerNameSpace, lowerNameSpace + "_types", lowerNameSpace + "_impl",
+		upperNameSpace + "_VERSION_MAJOR", upperNameSpace + "_VERSION_MINOR", upperNameSpace + "_VERSION_MICRO",
+		upperNameSpace + "_SUCCESS", getFortranTypeName (NameSpace, "BaseClass"),
+		lowerNameSpace + "_checkerror", lowerNameSpace + "_errormessage", lowerNameSpace + "_fromcstring",
+		lowerNameSpace + "_lasterrormessage"};
+}
+
+func getFortranErrorConstant (NameSpace string, name string) (string) {
+	return strings.ToUpper (NameSpace) + "_ERROR_" + strings.ToUpper (name);
+}
+
+func getFortranEnumConstant (NameSpace string, enumName string, optionName string) (string) {
+	return strings.ToUpper (NameSpace + "_" + enumName + "_" + optionName);
+}
+
+func getFortranTypeName (NameSpace string, name string) (string) {
+	return NameSpace + "_" + name;
+}
+
+func getFortranStructSize (NameSpace string, structName string) (string) {
+	return strings.ToUpper (NameSpace + "_" + structName) + "_SIZE";
+}
+
+func getFortranPackName (NameSpace string, structName string, pack bool) (string) {
+	if (pack) {
+		return strings.ToLower (NameSpace + "_pack_" + structName);
+	}
+	return strings.ToLower (NameSpace + "_unpack_" + structName);
+}
+
+// getFortranBasicType returns the interoperable Fortran type of a basic IDL type
+func getFortranBasicType (paramType string) (string, error) {
+	switch (paramType) {
+		case "uint8", "int8":
+			return "integer(c_int8_t)", nil;
+		case "uint16", "int16":
+			return "integer(c_int16_t)", nil;
+		case "uint32", "int32":
+			return "integer(c_int32_t)", nil;
+		case "uint64", "int64":
+			return "integer(c_int64_t)", nil;
+		case "bool":
+			return "logical(c_bool)", nil;
+		case "single":
+			return "real(c_float)", nil;
+		case "double":
+			return "real(c_double)", nil;
+		case "enum":
+			return "integer(c_int)", nil;
+	}
+	return "", fmt.Errorf ("invalid basic type \"%s\" for Fortran", paramType);
+}
+
+// getFortranBasicSize returns the size in bytes of a basic IDL type
+func getFortranBasicSize (paramType string) (int, error) {
+	switch (paramType) {
+		case "uint8", "int8", "bool":
+			return 1, nil;
+		case "uint16", "int16":
+			return 2, nil;
+		case "uint32", "int32", "single", "enum":
+			return 4, nil;
+		case "uint64", "int64", "double":
+			return 8, nil;
+	}
+	return 0, fmt.Errorf ("invalid basic type \"%s\" for Fortran", paramType);
+}
+
+// getFortranMemberSize returns the size in bytes of a struct member
+func getFortranMemberSize (member ComponentDefinitionMember) (int, error) {
+	size, err := getFortranBasicSize (member.Type);
+	if (err != nil) {
+		return 0, err;
+	}
+	if (member.Rows > 0) {
+		size = size * member.Rows;
+		if (member.Columns > 0) {
+			size = size * member.Columns;
+		}
+	}
+	return size, nil;
+}
+
+// getFortranStructSizeInBytes returns the size in bytes of a packed struct
+func getFortranStructSizeInBytes (structinfo ComponentDefinitionStruct) (int, error) {
+	size := 0;
+	for _, member := range structinfo.Members {
+		memberSize, err := getFortranMemberSize (member);
+		if (err != nil) {
+			return 0, err;
+		}
+		size = size + memberSize;
+	}
+	return size, nil;
+}
+
+// writeFortranStatement writes a statement with a list of items and continues it on the next line if it gets too long
+func writeFortranStatement (w LanguageWriter, indent string, start string, items []string, end string) {
+	lines := []string {start};
+	for index, item := range items {
+		text := item;
+		if (index < len (items) - 1) {
+			text = text + ",";
+		} else {
+			text = text + end;
+		}
+		line := lines[len (lines) - 1];
+		if (index > 0) {
+			if (len (indent) + len (line) + len (item) + 2 > fortranMaxLineLength) {
+				lines = append (lines, text);
+				continue;
+			}
+			line = line + " ";
+		}
+		lines[len (lines) - 1] = line + text;
+	}
+	if (len (items) == 0) {
+		lines[0] = lines[0] + end;
+	}
+	for index, line := range lines {
+		if (index < len (lines) - 1) {
+			line = line + " &";
+		}
+		if (index == 0) {
+			w.Writeln (indent + "%s", line);
+		} else {
+			w.Writeln (indent + "    %s", line);
+		}
+	}
+}
+
+// writeFortranLines writes statements, whose leading spaces are indented like the format of Writeln
+func writeFortranLines (w LanguageWriter, indent string, lines []string) {
+	for _, line := range lines {
+		statement := strings.TrimLeft (line, " ");
+		w.Writeln (indent + line[:len (line) - len (statement)] + "%s", statement);
+	}
+}
+
+// writeFortranComment writes a Fortran comment with the description of the IDL
+func writeFortranComment (w LanguageWriter, indent string, description string) {
+	if (description == "") {
+		return;
+	}
+	for _, line := range strings.Split (description, "\n") {
+		w.Writeln (indent + "! %s", strings.TrimSpace (line));
+	}
+}
+
+// getFortranClassesInOrder returns the classes of the component, such that every parent precedes its children
+func getFortranClassesInOrder (component ComponentDefinition) ([]ComponentDefinitionClass) {
+	classes := []ComponentDefinitionClass {};
+	written := make (map[string]bool);
+	var addClass func (class ComponentDefinitionClass);
+	addClass = func (class ComponentDefinitionClass) {
+		if (written[class.ClassName]) {
+			return;
+		}
+		written[class.ClassName] = true;
+		if (class.ParentClass != "") {
+			for _, parent := range component.Classes {
+				if (parent.ClassName == class.ParentClass) {
+					addClass (parent);
+				}
+			}
+		}
+		classes = append (classes, class);
+	}
+	for _, class := range component.Classes {
+		addClass (class);
+	}
+	return classes;
+}
+
+// writeFortranTypeDeclarations writes the constants, enums, structs and function types of a component
+func writeFortranTypeDeclarations (component ComponentDefinition, w LanguageWriter) (error) {
+	NameSpace := component.NameSpace;
+	upperNameSpace := strings.ToUpper (NameSpace);
+
+	w.Writeln ("  ! Version of the interface");
+	w.Writeln ("  integer(c_int32_t), parameter, public :: %s_VERSION_MAJOR = %d", upperNameSpace, majorVersion (component.Version));
+	w.Writeln ("  integer(c_int32_t), parameter, public :: %s_VERSION_MINOR = %d", upperNameSpace, minorVersion (component.Version));
+	w.Writeln ("  integer(c_int32_t), parameter, public :: %s_VERSION_MICRO = %d", upperNameSpace, microVersion (component.Version));
+	w.Writeln ("");
+
+	w.Writeln ("  ! Error codes");
+	w.Writeln ("  integer(c_int32_t), parameter, public :: %s_SUCCESS = 0", upperNameSpace);
+	for _, errorcode := range component.Errors.Errors {
+		w.Writeln ("  integer(c_int32_t), parameter, public :: %s = %d", getFortranErrorConstant (NameSpace, errorcode.Name), errorcode.Code);
+	}
+
+	for _, enum := range component.Enums {
+		w.Writeln ("");
+		w.Writeln ("  ! Enum %s", enum.Name);
+		w.Writeln ("  enum, bind(c)");
+		enumerators := []string {};
+		for _, option := range enum.Options {
+			enumerator := getFortranEnumConstant (NameSpace, enum.Name, option.Name);
+			w.Writeln ("    enumerator :: %s = %d", enumerator, option.Value);
+			enumerators = append (enumerators, enumerator);
+		}
+		w.Writeln ("  end enum");
+		writeFortranStatement (w, "  ", "public :: ", enumerators, "");
+	}
+
+	for _, structinfo := range component.Structs {
+		size, err := getFortranStructSizeInBytes (structinfo);
+		if (err != nil) {
+			return err;
+		}
+		w.Writeln ("");
+		w.Writeln ("  ! Struct %s, which is passed to the library packed into %d bytes", structinfo.Name, size);
+		w.Writeln ("  integer, parameter, public :: %s = %d", getFortranStructSize (NameSpace, structinfo.Name), size);
+		w.Writeln ("  type, public :: %s", getFortranTypeName (NameSpace, structinfo.Name));
+		for _, member := range structinfo.Members {
+			memberType, err := getFortranBasicType (member.Type);
+			if (err != nil) {
+				return err;
+			}
+			if (member.Rows > 0) {
+				if (member.Columns > 0) {
+					memberType = fmt.Sprintf ("%s, dimension(%d, %d)", memberType, member.Rows, member.Columns);
+				} else {
+					memberType = fmt.Sprintf ("%s, dimension(%d)", memberType, member.Rows);
+				}
+			}
+			initialValue := "0";
+			if (member.Type == "bool") {
+				initialValue = ".false.";
+			}
+			w.Writeln ("    %s :: %s = %s", memberType, member.Name, initialValue);
+		}
+		w.Writeln ("  end type %s", getFortranTypeName (NameSpace, structinfo.Name));
+	}
+
+	if (len (component.Functions) > 0) {
+		w.Writeln ("");
+		w.Writeln ("  abstract interface");
+		for index, functiontype := range component.Functions {
+			if (index > 0) {
+				w.Writeln ("");
+			}
+			err := writeFortranFunctionType (component, functiontype, w);
+			if (err != nil) {
+				return err;
+			}
+		}
+		w.Writeln ("  end interface");
+
+		functiontypes := []string {};
+		for _, functiontype := range component.Functions {
+			functiontypes = append (functiontypes, getFortranTypeName (NameSpace, functiontype.FunctionName));
+		}
+		writeFortranStatement (w, "  ", "public :: ", functiontypes, "");
+	}
+	return nil;
+}
+
+// writeFortranFunctionType writes the abstract interface of a function type
+func writeFortranFunctionType (component ComponentDefinition, functiontype ComponentDefinitionFunctionType, w LanguageWriter) (error) {
+	names := []string {};
+	declarations := []string {};
+	for _, param := range functiontype.Params {
+		paramType := "";
+		switch (param.ParamType) {
+			case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "bool", "single", "double", "enum":
+				basicType, err := getFortranBasicType (param.ParamType);
+				if (err != nil) {
+					return err;
+				}
+				paramType = basicType;
+			case "string":
+				paramType = "type(c_ptr)";
+				if (param.ParamPass != "in") {
+					return fmt.Errorf ("parameter type \"%s\" of %s (%s) is only supported as input by Fortran callbacks", param.ParamType, functiontype.FunctionName, param.ParamName);
+				}
+			case "handle":
+				paramType = "type(c_ptr)";
+			default:
+				return fmt.Errorf ("parameter type \"%s\" of %s (%s) is not supported by Fortran callbacks", param.ParamType, functiontype.FunctionName, param.ParamName);
+		}
+		if (param.ParamPass == "in") {
+			paramType = paramType + ", value";
+		} else {
+			paramType = paramType + ", intent(out)";
+		}
+		names = append (names, param.ParamName);
+		declarations = append (declarations, fmt.Sprintf ("%s :: %s", paramType, param.ParamName));
+	}
+
+	typeName := getFortranTypeName (component.NameSpace, functiontype.FunctionName);
+	writeFortranComment (w, "    ", functiontype.FunctionDescription);
+	writeFortranStatement (w, "    ", "subroutine " + typeName + "(", names, ") bind(c)");
+	w.Writeln ("      use, intrinsic :: iso_c_binding");
+	w.Writelns ("      ", declarations);
+	w.Writeln ("    end subroutine %s", typeName);
+	return nil;
+}
+
+// writeFortranStructProcedures writes the procedures that pack structs into and unpack them from bytes
+func writeFortranStructProcedures (component ComponentDefinition, w LanguageWriter) (error) {
+	NameSpace := component.NameSpace;
+	for _, structinfo := range component.Structs {
+		typeName := getFortranTypeName (NameSpace, structinfo.Name);
+		packName := getFortranPackName (NameSpace, structinfo.Name, true);
+		unpackName := getFortranPackName (NameSpace, structinfo.Name, false);
+
+		w.Writeln ("  ! Packs a %s into the bytes of the C interface", typeName);
+		w.Writeln ("  subroutine %s(value, buffer)", packName);
+		w.Writeln ("    type(%s), intent(in) :: value", typeName);
+		w.Writeln ("    character(kind=c_char), dimension(:), intent(out) :: buffer");
+		w.Writeln ("");
+		offset := 0;
+		for _, member := range structinfo.Members {
+			size, err := getFortranMemberSize (member);
+			if (err != nil) {
+				return err;
+			}
+			w.Writeln ("    buffer(%d:%d) = transfer(value%%%s, c_null_char, %d)", offset + 1, offset + size, member.Name, size);
+			offset = offset + size;
+		}
+		w.Writeln ("  end subroutine %s", packName);
+		w.Writeln ("");
+
+		w.Writeln ("  ! Unpacks a %s from the bytes of the C interface", typeName);
+		w.Writeln ("  subroutine %s(buffer, value)", unpackName);
+		w.Writeln ("    character(kind=c_char), dimension(:), intent(in) :: buffer");
+		w.Writeln ("    type(%s), intent(out) :: value", typeName);
+		w.Writeln ("");
+		offset = 0;
+		for _, member := range structinfo.Members {
+			size, err := getFortranMemberSize (member);
+			if (err != nil) {
+				return err;
+			}
+			if (member.Rows > 0) {
+				w.Writeln ("    value%%%s = reshape(transfer(buffer(%d:%d), value%%%s), shape(value%%%s))",
+					member.Name, offset + 1, offset + size, member.Name, member.Name);
+			} else {
+				w.Writeln ("    value%%%s = transfer(buffer(%d:%d), value%%%s)", member.Name, offset + 1, offset + size, member.Name);
+			}
+			offset = offset + size;
+		}
+		w.Writeln ("  end subroutine %s", unpackName);
+		w.Writeln ("");
+	}
+	return nil;
+}
+
+// fortranCDummy is a dummy argument of the C interface of an exported function
+type fortranCDummy struct {
+	name string
+	declaration string
+}
+
+// getFortranCDummies returns the dummy arguments of the C interface of a method
+func getFortranCDummies (method ComponentDefinitionMethod, NameSpace string, ClassName string, isGlobal bool) ([]fortranCDummy, error) {
+	dummies := []fortranCDummy {};
+	if (!isGlobal) {
+		dummies = append (dummies, fortranCDummy {"p" + ClassName, "type(c_ptr), value"});
+	}
+
+	for _, param := range method.Params {
+		cParams, err := generateCParameter (param, ClassName, method.MethodName, NameSpace);
+		if (err != nil) {
+			return nil, err;
+		}
+
+		declarations := []string {};
+		if (param.ParamPass == "in") {
+			switch (param.ParamType) {
+				case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "bool", "single", "double", "enum":
+					basicType, err := getFortranBasicType (param.ParamType);
+					if (err != nil) {
+						return nil, err;
+					}
+					declarations = []string {basicType + ", value"};
+				case "string", "struct":
+					declarations = []string {"character(kind=c_char), dimension(*), intent(in)"};
+				case "basicarray":
+					basicType, err := getFortranBasicType (param.ParamClass);
+					if (err != nil) {
+						return nil, err;
+					}
+					declarations = []string {"integer(c_int64_t), value", basicType + ", dimension(*), intent(in)"};
+				case "structarray":
+					declarations = []string {"integer(c_int64_t), value", "character(kind=c_char), dimension(*), intent(in)"};
+				case "handle":
+					declarations = []string {"type(c_ptr), value"};
+				case "functiontype":
+					declarations = []string {"type(c_funptr), value"};
+			}
+		} else {
+			switch (param.ParamType) {
+				case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "bool", "single", "double", "enum":
+					basicType, err := getFortranBasicType (param.ParamType);
+					if (err != nil) {
+						return nil, err;
+					}
+					declarations = []string {basicType + ", intent(out)"};
+				case "struct":
+					declarations = []string {"character(kind=c_char), dimension(*), intent(out)"};
+				case "string":
+					declarations = []string {"integer(c_int32_t), value", "integer(c_int32_t), intent(out)", "type(c_ptr), value"};
+				case "basicarray", "structarray":
+					declarations = []string {"integer(c_int64_t), value", "integer(c_int64_t), intent(out)", "type(c_ptr), value"};
+				case "handle":
+					declarations = []string {"type(c_ptr), intent(out)"};
+			}
+		}
+		if (len (declarations) != len (cParams)) {
+			return nil, fmt.Errorf ("invalid parameter type \"%s\" for %s.%s (%s) in Fortran", param.ParamType, ClassName, method.MethodName, param.ParamName);
+		}
+		for index, cParam := range cParams {
+			dummies = append (dummies, fortranCDummy {cParam.ParamName, declarations[index]});
+		}
+	}
+	return dummies, nil;
+}
+
+// writeFortranCFunction writes the heading and the dummy declarations of a function with the C interface of an exported method
+func writeFortranCFunction (w LanguageWriter, indent string, functionName string, dummies []fortranCDummy, bindingLabel string) {
+	names := []string {};
+	for _, dummy := range dummies {
+		names = append (names, dummy.name);
+	}
+	writeFortranStatement (w, indent, "function " + functionName + "(", names, fmt.Sprintf (") bind(c, name=\"%s\")", bindingLabel));
+	w.Writeln (indent + "  use, intrinsic :: iso_c_binding");
+	w.Writeln (indent + "  integer(c_int32_t) :: %s", functionName);
+	for _, dummy := range dummies {
+		w.Writeln (indent + "  %s :: %s", dummy.declaration, dummy.name);
+	}
+}
+
+func buildFortranBinding (component ComponentDefinition, w LanguageWriter) (error) {
+	NameSpace := component.NameSpace;
+	moduleName := strings.ToLower (NameSpace);
+	lowerNameSpace := strings.ToLower (NameSpace);
+
+	w.Writeln ("");
+	w.Writeln ("module %s", moduleName);
+	w.Writeln ("  use, intrinsic :: iso_c_binding");
+	w.Writeln ("  use, intrinsic :: iso_fortran_env, only: error_unit");
+	w.Writeln ("  implicit none");
+	w.Writeln ("  private");
+	w.Writeln ("");
+
+	err := writeFortranTypeDeclarations (component, w);
+	if (err != nil) {
+		return err;
+	}
+
+	w.Writeln ("");
+	w.Writeln ("  ! Base type of all classes, which holds the handle of an instance of the library");
+	w.Writeln ("  type, public :: %s", getFortranTypeName (NameSpace, "BaseClass"));
+	w.Writeln ("    type(c_ptr) :: handle = c_null_ptr");
+	w.Writeln ("  end type %s", getFortranTypeName (NameSpace, "BaseClass"));
+	for _, class := range getFortranClassesInOrder (component) {
+		parentClass := class.ParentClass;
+		if (parentClass == "") {
+			parentClass = "BaseClass";
+		}
+		w.Writeln ("");
+		writeFortranComment (w, "  ", class.ClassDescription);
+		w.Writeln ("  type, public, extends(%s) :: %s", getFortranTypeName (NameSpace, parentClass), getFortranTypeName (NameSpace, class.ClassName));
+		w.Writeln ("  end type %s", getFortranTypeName (NameSpace, class.ClassName));
+	}
+
+	type fortranMethod struct {
+		method ComponentDefinitionMethod
+		ClassName string
+		isGlobal bool
+	}
+	methods := []fortranMethod {};
+	for _, class := range component.Classes {
+		for _, method := range class.Methods {
+			methods = append (methods, fortranMethod {method, class.ClassName, false});
+		}
+	}
+	for _, method := range component.Global.Methods {
+		methods = append (methods, fortranMethod {method, "Wrapper", true});
+	}
+
+	w.Writeln ("");
+	w.Writeln ("  ! Functions exported by the library");
+	w.Writeln ("  interface");
+	for index, item := range methods {
+		if (index > 0) {
+			w.Writeln ("");
+		}
+		dummies, err := getFortranCDummies (item.method, NameSpace, item.ClassName, item.isGlobal);
+		if (err != nil) {
+			return err;
+		}
+		CMethodName := GetCExportName (NameSpace, item.ClassName, item.method, item.isGlobal);
+		writeFortranCFunction (w, "    ", "c_" + CMethodName, dummies, CMethodName);
+		w.Writeln ("    end function c_%s", CMethodName);
+	}
+	w.Writeln ("  end interface");
+	w.Writeln ("");
+
+	procedures := []string {lowerNameSpace + "_errormessage"};
+	for _, item := range methods {
+		procedures = append (procedures, GetCExportName (NameSpace, item.ClassName, item.method, item.isGlobal));
+	}
+	writeFortranStatement (w, "  ", "public :: ", procedures, "");
+	w.Writeln ("");
+	w.Writeln ("contains");
+	w.Writeln ("");
+
+	w.Writeln ("  ! Returns the name and the description of an error code");
+	w.Writeln ("  function %s_errormessage(errorcode) result(message)", lowerNameSpace);
+	w.Writeln ("    integer(c_int32_t), intent(in) :: errorcode");
+	w.Writeln ("    character(len=:), allocatable :: message");
+	w.Writeln ("");
+	w.Writeln ("    select case (errorcode)");
+	w.Writeln ("    case (%s_SUCCESS)", strings.ToUpper (NameSpace));
+	w.Writeln ("      message = 'SUCCESS: no error'");
+	for _, errorcode := range component.Errors.Errors {
+		w.Writeln ("    case (%s)", getFortranErrorConstant (NameSpace, errorcode.Name));
+		w.Writeln ("      message = '%s: %s'", strings.ToUpper (errorcode.Name), errorcode.Description);
+	}
+	w.Writeln ("    case default");
+	w.Writeln ("      message = 'UNKNOWN: unknown error'");
+	w.Writeln ("    end select");
+	w.Writeln ("  end function %s_errormessage", lowerNameSpace);
+	w.Writeln ("");
+
+	errorMethod, hasErrorMethod := component.Global.GetErrorMethod ();
+	if (hasErrorMethod) {
+		errorFunction := "c_" + GetCExportName (NameSpace, "", errorMethod, true);
+		w.Writeln ("  ! Returns the last error message of an instance, or an empty string if there is none");
+		w.Writeln ("  function %s_lasterrormessage(instance) result(message)", lowerNameSpace);
+		w.Writeln ("    type(c_ptr), intent(in) :: instance");
+		w.Writeln ("    character(len=:), allocatable :: message");
+		w.Writeln ("    integer(c_int32_t) :: needed");
+		w.Writeln ("    logical(c_bool) :: haserror");
+		w.Writeln ("    character(kind=c_char), dimension(:), allocatable, target :: buffer");
+		w.Writeln ("");
+		w.Writeln ("    message = ''");
+		w.Writeln ("    if (%s(instance, 0_c_int32_t, needed, c_null_ptr, haserror) /= %s_SUCCESS) return", errorFunction, strings.ToUpper (NameSpace));
+		w.Writeln ("    if (.not. haserror) return");
+		w.Writeln ("    allocate (buffer(needed + 1))");
+		w.Writeln ("    if (%s(instance, int(size(buffer), c_int32_t), needed, c_loc(buffer), haserror) == %s_SUCCESS) then", errorFunction, strings.ToUpper (NameSpace));
+		w.Writeln ("      call %s_fromcstring(buffer, message)", lowerNameSpace);
+		w.Writeln ("    end if");
+		w.Writeln ("  end function %s_lasterrormessage", lowerNameSpace);
+		w.Writeln ("");
+		w.Writeln ("  ! Returns the error code if it is requested, otherwise stops the program with the error message if the call failed");
+		w.Writeln ("  subroutine %s_checkerror(errorcode, errorresult, instance)", lowerNameSpace);
+		w.Writeln ("    integer(c_int32_t), intent(in) :: errorcode");
+		w.Writeln ("    integer(c_int32_t), intent(out), optional :: errorresult");
+		w.Writeln ("    type(c_ptr), intent(in), optional :: instance");
+		w.Writeln ("    character(len=:), allocatable :: message");
+		w.Writeln ("");
+		w.Writeln ("    if (present(errorresult)) then");
+		w.Writeln ("      errorresult = errorcode");
+		w.Writeln ("    else if (errorcode /= %s_SUCCESS) then", strings.ToUpper (NameSpace));
+		w.Writeln ("      if (present(instance)) then");
+		w.Writeln ("        message = %s_lasterrormessage(instance)", lowerNameSpace);
+		w.Writeln ("      else");
+		w.Writeln ("        message = %s_lasterrormessage(c_null_ptr)", lowerNameSpace);
+		w.Writeln ("      end if");
+		w.Writeln ("      if (len(message) > 0) then");
+		w.Writeln ("        write (error_unit, '(a, a, i0, a, a)') '%s error: ', %s_errormessage(errorcode) // ' (', errorcode, '): ', message",
+			strings.Replace (component.LibraryName, "'", "''", -1), lowerNameSpace);
+		w.Writeln ("      else");
+		w.Writeln ("        write (error_unit, '(a, a, i0, a)') '%s error: ', %s_errormessage(errorcode) // ' (', errorcode, ')'",
+			strings.Replace (component.LibraryName, "'", "''", -1), lowerNameSpace);
+		w.Writeln ("      end if");
+		w.Writeln ("      error stop");
+		w.Writeln ("    end if");
+		w.Writeln ("  end subroutine %s_checkerror", lowerNameSpace);
+		w.Writeln ("");
+	} else {
+		w.Writeln ("  ! Returns the error code if it is requested, otherwise stops the program if the call failed");
+		w.Writeln ("  subroutine %s_checkerror(errorcode, errorresult)", lowerNameSpace);
+		w.Writeln ("    integer(c_int32_t), intent(in) :: errorcode");
+		w.Writeln ("    integer(c_int32_t), intent(out), optional :: errorresult");
+		w.Writeln ("");
+		w.Writeln ("    if (present(errorresult)) then");
+		w.Writeln ("      errorresult = errorcode");
+		w.Writeln ("    else if (errorcode /= %s_SUCCESS) then", strings.ToUpper (NameSpace));
+		w.Writeln ("      write (error_unit, '(a, a, i0, a)') '%s error: ', %s_errormessage(errorcode) // ' (', errorcode, ')'",
+			strings.Replace (component.LibraryName, "'", "''", -1), lowerNameSpace);
+		w.Writeln ("      error stop");
+		w.Writeln ("    end if");
+		w.Writeln ("  end subroutine %s_checkerror", lowerNameSpace);
+		w.Writeln ("");
+	}
+
+	w.Writeln ("  ! Converts a null terminated buffer of the library into a Fortran string");
+	w.Writeln ("  subroutine %s_fromcstring(buffer, string)", lowerNameSpace);
+	w.Writeln ("    character(kind=c_char), dimension(:), intent(in) :: buffer");
+	w.Writeln ("    character(len=:), allocatable, intent(out) :: string");
+	w.Writeln ("    integer :: length, i");
+	w.Writeln ("");
+	w.Writeln ("    length = 0");
+	w.Writeln ("    do while (length < size(buffer))");
+	w.Writeln ("      if (buffer(length + 1) == c_null_char) exit");
+	w.Writeln ("      length = length + 1");
+	w.Writeln ("    end do");
+	w.Writeln ("    allocate (character(len=length) :: string)");
+	w.Writeln ("    do i = 1, length");
+	w.Writeln ("      string(i:i) = buffer(i)");
+	w.Writeln ("    end do");
+	w.Writeln ("  end subroutine %s_fromcstring", lowerNameSpace);
+	w.Writeln ("");
+
+	err = writeFortranStructProcedures (component, w);
+	if (err != nil) {
+		return err;
+	}
+
+	for _, item := range methods {
+		err = writeFortranMethod (component, item.method, w, item.ClassName, item.isGlobal);
+		if (err != nil) {
+			return err;
+		}
+	}
+
+	w.Writeln ("end module %s", moduleName);
+	return nil;
+}
+
+// writeFortranMethod writes the wrapper procedure of a method, that converts the Fortran arguments for the library
+func writeFortranMethod (component ComponentDefinition, method ComponentDefinitionMethod, w LanguageWriter, ClassName string, isGlobal bool) (error) {
+	NameSpace := component.NameSpace;
+	upperNameSpace := strings.ToUpper (NameSpace);
+	CMethodName := GetCExportName (NameSpace, ClassName, method, isGlobal);
+	isRelease := isGlobal && (method.MethodName == component.Global.ReleaseMethod);
+
+	names := []string {};
+	declarations := []string {};
+	locals := []string {};
+	preCall := []string {};
+	queryArgs := []string {};
+	callArgs := []string {};
+	allocations := []string {};
+	postCall := []string {};
+	comments := []string {};
+	needsLoop := false;
+
+	if (!isGlobal) {
+		names = append (names, "self");
+		declarations = append (declarations, fmt.Sprintf ("class(%s), intent(in) :: self", getFortranTypeName (NameSpace, ClassName)));
+		queryArgs = append (queryArgs, "self%handle");
+		callArgs = append (callArgs, "self%handle");
+	}
+
+	for _, param := range method.Params {
+		name := param.ParamName;
+		names = append (names, name);
+		comments = append (comments, fmt.Sprintf ("%s - %s", name, param.ParamDescription));
+
+		args := []string {};
+		queries := []string {};
+		if (param.ParamPass == "in") {
+			switch (param.ParamType) {
+				case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "single", "double", "enum":
+					basicType, err := getFortranBasicType (param.ParamType);
+					if (err != nil) {
+						return err;
+					}
+					declarations = append (declarations, fmt.Sprintf ("%s, intent(in) :: %s", basicType, name));
+					args = []string {name};
+
+				case "bool":
+					declarations = append (declarations, fmt.Sprintf ("logical, intent(in) :: %s", name));
+					args = []string {fmt.Sprintf ("logical(%s, c_bool)", name)};
+
+				case "string":
+					declarations = append (declarations, fmt.Sprintf ("character(len=*), intent(in) :: %s", name));
+					args = []string {name + " // c_null_char"};
+
+				case "struct":
+					declarations = append (declarations, fmt.Sprintf ("type(%s), intent(in) :: %s", getFortranTypeName (NameSpace, param.ParamClass), name));
+					locals = append (locals, fmt.Sprintf ("character(kind=c_char), dimension(%s) :: buffer_%s", getFortranStructSize (NameSpace, param.ParamClass), name));
+					preCall = append (preCall, fmt.Sprintf ("call %s(%s, buffer_%s)", getFortranPackName (NameSpace, param.ParamClass, true), name, name));
+					args = []string {"buffer_" + name};
+
+				case "basicarray":
+					if (param.ParamClass == "bool") {
+						declarations = append (declarations, fmt.Sprintf ("logical, dimension(:), intent(in) :: %s", name));
+						args = []string {fmt.Sprintf ("int(size(%s), c_int64_t)", name), fmt.Sprintf ("logical(%s, c_bool)", name)};
+					} else {
+						basicType, err := getFortranBasicType (param.ParamClass);
+						if (err != nil) {
+							return err;
+						}
+						declarations = append (declarations, fmt.Sprintf ("%s, dimension(:), intent(in) :: %s", basicType, name));
+						args = []string {fmt.Sprintf ("int(size(%s), c_int64_t)", name), name};
+					}
+
+				case "structarray":
+					structSize := getFortranStructSize (NameSpace, param.ParamClass);
+					declarations = append (declarations, fmt.Sprintf ("type(%s), dimension(:), intent(in) :: %s", getFortranTypeName (NameSpace, param.ParamClass), name));
+					locals = append (locals, fmt.Sprintf ("character(kind=c_char), dimension(:), allocatable :: buffer_%s", name));
+					preCall = append (preCall,
+						fmt.Sprintf ("allocate (buffer_%s(max(size(%s), 1) * %s))", name, name, structSize),
+						fmt.Sprintf ("do i = 1, size(%s)", name),
+						fmt.Sprintf ("  call %s(%s(i), buffer_%s((i - 1) * %s + 1:i * %s))", getFortranPackName (NameSpace, param.ParamClass, true), name, name, structSize, structSize),
+						"end do");
+					args = []string {fmt.Sprintf ("int(size(%s), c_int64_t)", name), "buffer_" + name};
+					needsLoop = true;
+
+				case "handle":
+					intent := "in";
+					if (isRelease) {
+						intent = "inout";
+						postCall = append (postCall, name + "%handle = c_null_ptr");
+					}
+					declarations = append (declarations, fmt.Sprintf ("class(%s), intent(%s) :: %s", getFortranTypeName (NameSpace, param.ParamClass), intent, name));
+					args = []string {name + "%handle"};
+
+				case "functiontype":
+					declarations = append (declarations, fmt.Sprintf ("procedure(%s) :: %s", getFortranTypeName (NameSpace, param.ParamClass), name));
+					args = []string {fmt.Sprintf ("c_funloc(%s)", name)};
+
+				default:
+					return fmt.Errorf ("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName);
+			}
+			queries = args;
+		} else {
+			switch (param.ParamType) {
+				case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "single", "double", "enum":
+					basicType, err := getFortranBasicType (param.ParamType);
+					if (err != nil) {
+						return err;
+					}
+					declarations = append (declarations, fmt.Sprintf ("%s, intent(out) :: %s", basicType, name));
+					args = []string {name};
+					queries = args;
+
+				case "bool":
+					declarations = append (declarations, fmt.Sprintf ("logical, intent(out) :: %s", name));
+					locals = append (locals, fmt.Sprintf ("logical(c_bool) :: value_%s", name));
+					postCall = append (postCall, fmt.Sprintf ("%s = value_%s", name, name));
+					args = []string {"value_" + name};
+					queries = args;
+
+				case "struct":
+					declarations = append (declarations, fmt.Sprintf ("type(%s), intent(out) :: %s", getFortranTypeName (NameSpace, param.ParamClass), name));
+					locals = append (locals, fmt.Sprintf ("character(kind=c_char), dimension(%s) :: buffer_%s", getFortranStructSize (NameSpace, param.ParamClass), name));
+					postCall = append (postCall, fmt.Sprintf ("call %s(buffer_%s, %s)", getFortranPackName (NameSpace, param.ParamClass, false), name, name));
+					args = []string {"buffer_" + name};
+					queries = args;
+
+				case "string":
+					declarations = append (declarations, fmt.Sprintf ("character(len=:), allocatable, intent(out) :: %s", name));
+					locals = append (locals,
+						fmt.Sprintf ("integer(c_int32_t) :: needed_%s", name),
+						fmt.Sprintf ("character(kind=c_char), dimension(:), allocatable, target :: buffer_%s", name));
+					allocations = append (allocations, fmt.Sprintf ("allocate (buffer_%s(needed_%s + 1))", name, name));
+					postCall = append (postCall, fmt.Sprintf ("call %s_fromcstring(buffer_%s, %s)", strings.ToLower (NameSpace), name, name));
+					queries = []string {"0_c_int32_t", "needed_" + name, "c_null_ptr"};
+					args = []string {fmt.Sprintf ("int(size(buffer_%s), c_int32_t)", name), "needed_" + name, fmt.Sprintf ("c_loc(buffer_%s)", name)};
+
+				case "basicarray":
+					basicType, err := getFortranBasicType (param.ParamClass);
+					if (err != nil) {
+						return err;
+					}
+					if (param.ParamClass == "bool") {
+						declarations = append (declarations, fmt.Sprintf ("logical, dimension(:), allocatable, intent(out) :: %s", name));
+						postCall = append (postCall, fmt.Sprintf ("%s = logical(buffer_%s(1:needed_%s))", name, name, name));
+					} else {
+						declarations = append (declarations, fmt.Sprintf ("%s, dimension(:), allocatable, intent(out) :: %s", basicType, name));
+						postCall = append (postCall, fmt.Sprintf ("%s = buffer_%s(1:needed_%s)", name, name, name));
+					}
+					locals = append (locals,
+						fmt.Sprintf ("integer(c_int64_t) :: needed_%s", name),
+						fmt.Sprintf ("%s, dimension(:), allocatable, target :: buffer_%s", basicType, name));
+					allocations = append (allocations, fmt.Sprintf ("allocate (buffer_%s(max(needed_%s, 1_c_int64_t)))", name, name));
+					queries = []string {"0_c_int64_t", "needed_" + name, "c_null_ptr"};
+					args = []string {fmt.Sprintf ("int(size(buffer_%s), c_int64_t)", name), "needed_" + name, fmt.Sprintf ("c_loc(buffer_%s)", name)};
+
+				case "structarray":
+					structSize := getFortranStructSize (NameSpace, param.ParamClass);
+					declarations = append (declarations, fmt.Sprintf ("type(%s), dimension(:), allocatable, intent(out) :: %s", getFortranTypeName (NameSpace, param.ParamClass), name));
+					locals = append (locals,
+						fmt.Sprintf ("integer(c_int64_t) :: needed_%s", name),
+						fmt.Sprintf ("character(kind=c_char), dimension(:), allocatable, target :: buffer_%s", name));
+					allocations = append (allocations, fmt.Sprintf ("allocate (buffer_%s(max(needed_%s, 1_c_int64_t) * %s))", name, name, structSize));
+					postCall = append (postCall,
+						fmt.Sprintf ("allocate (%s(needed_%s))", name, name),
+						fmt.Sprintf ("do i = 1, size(%s)", name),
+						fmt.Sprintf ("  call %s(buffer_%s((i - 1) * %s + 1:i * %s), %s(i))", getFortranPackName (NameSpace, param.ParamClass, false), name, structSize, structSize, name),
+						"end do");
+					queries = []string {"0_c_int64_t", "needed_" + name, "c_null_ptr"};
+					args = []string {fmt.Sprintf ("int(size(buffer_%s) / %s, c_int64_t)", name, structSize), "needed_" + name, fmt.Sprintf ("c_loc(buffer_%s)", name)};
+					needsLoop = true;
+
+				case "handle":
+					declarations = append (declarations, fmt.Sprintf ("type(%s), intent(out) :: %s", getFortranTypeName (NameSpace, param.ParamClass), name));
+					args = []string {name + "%handle"};
+					queries = args;
+
+				default:
+					return fmt.Errorf ("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName);
+			}
+		}
+		queryArgs = append (queryArgs, queries...);
+		callArgs = append (callArgs, args...);
+	}
+
+	names = append (names, "error");
+	declarations = append (declarations, "integer(c_int32_t), intent(out), optional :: error");
+	comments = append (comments, "error - receives the error code of the call; the program stops on failure if it is absent");
+	locals = append (locals, "integer(c_int32_t) :: errorcode");
+	if (needsLoop) {
+		locals = append (locals, "integer :: i");
+	}
+
+	writeFortranComment (w, "  ", method.MethodDescription);
+	w.Writeln ("  !");
+	for _, comment := range comments {
+		w.Writeln ("  ! %s", comment);
+	}
+	writeFortranStatement (w, "  ", "subroutine " + CMethodName + "(", names, ")");
+	for _, declaration := range declarations {
+		w.Writeln ("    %s", declaration);
+	}
+	for _, local := range locals {
+		w.Writeln ("    %s", local);
+	}
+	w.Writeln ("");
+	writeFortranLines (w, "    ", preCall);
+
+	if (len (allocations) > 0) {
+		writeFortranStatement (w, "    ", "errorcode = c_" + CMethodName + "(", queryArgs, ")");
+		w.Writeln ("    if (errorcode == %s_SUCCESS) then", upperNameSpace);
+		writeFortranLines (w, "      ", allocations);
+		writeFortranStatement (w, "      ", "errorcode = c_" + CMethodName + "(", callArgs, ")");
+		w.Writeln ("    end if");
+	} else {
+		writeFortranStatement (w, "    ", "errorcode = c_" + CMethodName + "(", callArgs, ")");
+	}
+
+	if (len (postCall) > 0) {
+		w.Writeln ("    if (errorcode == %s_SUCCESS) then", upperNameSpace);
+		writeFortranLines (w, "      ", postCall);
+		w.Writeln ("    end if");
+	}
+	_, hasErrorMethod := component.Global.GetErrorMethod ();
+	if (hasErrorMethod && !isGlobal) {
+		w.Writeln ("    call %s_checkerror(errorcode, error, self%%handle)", strings.ToLower (NameSpace));
+	} else {
+		w.Writeln ("    call %s_checkerror(errorcode, error)", strings.ToLower (NameSpace));
+	}
+	w.Writeln ("  end subroutine %s", CMethodName);
+	w.Writeln ("");
+	return nil;
+}
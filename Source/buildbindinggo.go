@@ -41,300 +41,450 @@ import (
 	"path"
 	"errors"
 	"strings"
+	"unicode"
 )
 
 // BuildBindingGo builds Go-bindings of a library's API
-func BuildBindingGo(component ComponentDefinition, outputFolder string) error {
-	namespace := component.NameSpace;
+func BuildBindingGo(component ComponentDefinition, outputFolder string, importPath string) error {
 	libraryname := component.LibraryName;
 	baseName := component.BaseName;
 
+	if (importPath == "") {
+		importPath = getGoPackageName (component);
+	}
+
+	GoModName := path.Join(outputFolder, "go.mod");
+	log.Printf ("Creating \"%s\"", GoModName);
+	gomodfile, err := os.Create(GoModName);
+	if (err != nil) {
+		return err;
+	}
+	defer gomodfile.Close();
+
+	fmt.Fprintf (gomodfile, "module %s\n", importPath);
+	fmt.Fprintf (gomodfile, "\n");
+	fmt.Fprintf (gomodfile, "go 1.13\n");
+
 	GoIntfName := path.Join(outputFolder, baseName + ".go");
 	log.Printf ("Creating \"%s\"", GoIntfName);
 	gofile, err := os.Create(GoIntfName);
 	if (err != nil) {
-		log.Fatal (err);
+		return err;
 	}
+	defer gofile.Close();
 
 	GoImplName := path.Join(outputFolder, baseName + "_impl.go");
 	log.Printf ("Creating \"%s\"", GoImplName);
 	goimplfile, err := os.Create(GoImplName);
 	if (err != nil) {
-		log.Fatal (err);
+		return err;
 	}
+	defer goimplfile.Close();
 
-	WriteLicenseHeader(gofile, component,
+	err = WriteLicenseHeader(gofile, component,
 		fmt.Sprintf ("This is an autogenerated Go wrapper file in order to allow an easy\n use of %s", libraryname),
-		true);
-	WriteLicenseHeader(goimplfile, component,
+		true)
+	if err != nil {
+		return err
+	}
+	err = WriteLicenseHeader(goimplfile, component,
 		fmt.Sprintf ("This is an autogenerated Go implementation file in order to allow an easy\n use of %s", libraryname),
-		true);
+		true)
+	if err != nil {
+		return err
+	}
 
-	return buildGoWrapper (component, gofile, goimplfile, namespace);
-}
+	err = buildGoWrapper (component, gofile, goimplfile, component.NameSpace);
+	if (err != nil) {
+		return err;
+	}
 
-func buildGoWrapper (component ComponentDefinition, w io.Writer, implw io.Writer, NameSpace string) (error) {
+	if (len (component.Functions) > 0) {
+		GoCallbacksName := path.Join(outputFolder, baseName + "_callbacks.go");
+		log.Printf ("Creating \"%s\"", GoCallbacksName);
+		gocallbacksfile, err := os.Create(GoCallbacksName);
+		if (err != nil) {
+			return err;
+		}
+		defer gocallbacksfile.Close();
 
-	global := component.Global;
+		err = WriteLicenseHeader(gocallbacksfile, component,
+			fmt.Sprintf ("This is an autogenerated Go file that dispatches the callbacks\n of %s to Go functions", libraryname),
+			true)
+		if err != nil {
+			return err
+		}
 
-	fmt.Fprintf (w, "\n");
-	fmt.Fprintf (w, "package main\n");
-	fmt.Fprintf (w, "\n");
+		err = buildGoCallbacks (component, gocallbacksfile, component.NameSpace);
+		if (err != nil) {
+			return err;
+		}
+	}
 
-	fmt.Fprintf (w, "\n");
-	fmt.Fprintf (w, "/*************************************************************************************************************************\n");
-	fmt.Fprintf (w, " Declaration of enums\n");
-	fmt.Fprintf (w, "**************************************************************************************************************************/\n");
-	fmt.Fprintf (w, "\n");
+	return nil;
+}
 
-	for i := 0; i < len(component.Enums); i++ {
-		enum := component.Enums[i];
-		fmt.Fprintf (w, "type E%s%s int\n", NameSpace, enum.Name);
+// validateBindingGo checks that the identifiers of the Go binding are unique within its package
+func validateBindingGo (component ComponentDefinition, options GeneratorOptions) (error) {
+	if (strings.ContainsAny (options.ImportPath, " \t\\")) {
+		return fmt.Errorf ("invalid Go import path \"%s\"", options.ImportPath);
+	}
 
-		fmt.Fprintf (w, "const (\n");
-		
-		for j := 0; j < len(enum.Options); j++ {			
-				
-			option := enum.Options[j];
-			fmt.Fprintf (w, "    e%s_%s = %d\n", enum.Name, option.Name, option.Value);
+	return validateGoIdentifiers (component, []string {"Handle", "Interface", "Implementation", "ImplementationHandle", "Wrapper", "LoadWrapper", "ErrorCode", "Error"}, "the Go binding");
+}
+
+// validateGoIdentifiers checks that the Go identifiers of the component's types are unique and do not collide with the reserved identifiers
+func validateGoIdentifiers (component ComponentDefinition, reserved []string, owner string) (error) {
+	identifiers := make (map[string]string);
+	for _, identifier := range reserved {
+		identifiers[identifier] = owner;
+	}
+
+	add := func (identifier string, source string) (error) {
+		previous, ok := identifiers[identifier];
+		if (ok) {
+			return fmt.Errorf ("the Go identifier \"%s\" of %s collides with %s", identifier, source, previous);
+		}
+		identifiers[identifier] = source;
+		return nil;
+	}
+
+	for _, enum := range component.Enums {
+		err := add (getGoIdentifier (enum.Name), "enum " + enum.Name);
+		if (err != nil) {
+			return err;
+		}
+		for _, option := range enum.Options {
+			err = add (getGoIdentifier (enum.Name) + getGoIdentifier (option.Name), "option " + enum.Name + "." + option.Name);
+			if (err != nil) {
+				return err;
+			}
+		}
+	}
+	for _, structinfo := range component.Structs {
+		err := add (getGoIdentifier (structinfo.Name), "struct " + structinfo.Name);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, functiontype := range component.Functions {
+		err := add (getGoIdentifier (functiontype.FunctionName), "functiontype " + functiontype.FunctionName);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, class := range component.Classes {
+		err := add (getGoIdentifier (class.ClassName), "class " + class.ClassName);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, errorcode := range component.Errors.Errors {
+		err := add ("Error" + errorcode.Name, "error " + errorcode.Name);
+		if (err != nil) {
+			return err;
 		}
-		
-		fmt.Fprintf (w, ")\n");
-		fmt.Fprintf (w, "\n");
 	}
 	
-	
-	fmt.Fprintf (w, "\n");
+	return nil;
+}
 
-	if len(component.Structs) > 0 {
-		fmt.Fprintf (w, "/*************************************************************************************************************************\n");
-		fmt.Fprintf (w, " Declaration of structs\n");
-		fmt.Fprintf (w, "**************************************************************************************************************************/\n");
-		fmt.Fprintf (w, "\n");
-			
-		for i := 0; i < len(component.Structs); i++ {
-			structinfo := component.Structs[i];
-			fmt.Fprintf (w, "type s%s%s struct {\n", NameSpace, structinfo.Name);
-			
-			for j := 0; j < len(structinfo.Members); j++ {			
+// getGoPackageName returns the name of the package of the Go binding
+func getGoPackageName (component ComponentDefinition) (string) {
+	return strings.ToLower (component.NameSpace);
+}
 
-				member := structinfo.Members[j];
-			
-				arraysuffix := "";
-				if (member.Rows > 0) {
-					if (member.Columns > 0) {
-						arraysuffix = fmt.Sprintf ("[%d][%d]", member.Columns, member.Rows)
-					} else {
-						arraysuffix = fmt.Sprintf ("[%d]",member.Rows)
-					}
-				}
-			
-				switch (member.Type) {
-					case "uint8":
-						fmt.Fprintf (w, "    %s%s uint8;\n", member.Name, arraysuffix);
-					case "uint16":
-						fmt.Fprintf (w, "    %s%s uint16;\n", member.Name, arraysuffix);
-					case "uint32":
-						fmt.Fprintf (w, "    %s%s uint32;\n", member.Name, arraysuffix);
-					case "uint64":				
-						fmt.Fprintf (w, "    %s%s uint64;\n", member.Name, arraysuffix);
-					case "int8":
-						fmt.Fprintf (w, "    %s%s int8;\n", member.Name, arraysuffix);
-					case "int16":
-						fmt.Fprintf (w, "    %s%s int16;\n", member.Name, arraysuffix);
-					case "int32":
-						fmt.Fprintf (w, "    %s%s int32;\n", member.Name, arraysuffix);
-					case "int64":				
-						fmt.Fprintf (w, "    %s%s int64;\n", member.Name, arraysuffix);
-					case "bool":				
-						fmt.Fprintf (w, "    %s%s bool;\n", member.Name, arraysuffix);
-					case "single":
-						fmt.Fprintf (w, "    %s%s float32;\n", member.Name, arraysuffix);
-					case "double":
-						fmt.Fprintf (w, "    %s%s float64;\n", member.Name, arraysuffix);
-					case "string":
-						return fmt.Errorf ("it is not possible for struct s%s%s to contain a string value", NameSpace, structinfo.Name);
-					case "handle":
-						return fmt.Errorf ("it is not possible for struct s%s%s to contain a handle value", NameSpace, structinfo.Name);
-					case "enum":
-						fmt.Fprintf (w, "    %s%s E%s%s;\n", member.Name, arraysuffix, NameSpace, member.Class);
-				}
-				
-			}
-			
-			fmt.Fprintf (w, "}\n");
-			fmt.Fprintf (w, "\n");
+// getGoIdentifier turns a name of the IDL into an exported Go identifier
+func getGoIdentifier (name string) (string) {
+	if (name == "") {
+		return name;
+	}
+	runes := []rune (name);
+	runes[0] = unicode.ToUpper (runes[0]);
+	return string (runes);
+}
+
+// writeGoDocComment writes a Go doc comment of an identifier with the description of the IDL
+func writeGoDocComment (w io.Writer, indent string, identifier string, description string) {
+	if (description == "") {
+		return;
+	}
+	for i, line := range strings.Split (description, "\n") {
+		if (i == 0) {
+			fmt.Fprintf (w, "%s// %s - %s\n", indent, identifier, strings.TrimSpace (line));
+		} else {
+			fmt.Fprintf (w, "%s// %s\n", indent, strings.TrimSpace (line));
 		}
-		
-		fmt.Fprintf (w, "\n");
+	}
+}
+
+func buildGoWrapper (component ComponentDefinition, w io.Writer, implw io.Writer, NameSpace string) (error) {
 
+	global := component.Global;
+	packageName := getGoPackageName (component);
+
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// Package %s is the Go binding of %s.\n", packageName, component.LibraryName);
+	fmt.Fprintf (w, "// Load the library with LoadWrapper and create its classes with the methods of the Wrapper.\n");
+	fmt.Fprintf (w, "package %s\n", packageName);
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "import (\n");
+	fmt.Fprintf (w, "    \"fmt\"\n");
+	fmt.Fprintf (w, ")\n");
+	fmt.Fprintf (w, "\n");
+
+	fmt.Fprintf (w, "\n");
+	err := writeGoTypeDeclarations (component, w, NameSpace);
+	if (err != nil) {
+		return err;
 	}
 
+	fmt.Fprintf (w, "// Error is returned by the methods of this package, if a function of %s fails.\n", component.LibraryName);
+	fmt.Fprintf (w, "// It wraps the ErrorCode that the function returned.\n");
+	_, hasErrorMethod := component.Global.GetErrorMethod ();
+	fmt.Fprintf (w, "type Error struct {\n");
+	fmt.Fprintf (w, "    Method string\n");
+	fmt.Fprintf (w, "    Code ErrorCode\n");
+	if (hasErrorMethod) {
+		fmt.Fprintf (w, "    // Message is the error message of %s, if it provides one.\n", component.LibraryName);
+		fmt.Fprintf (w, "    Message string\n");
+	}
+	fmt.Fprintf (w, "}\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "func (err *Error) Error () (string) {\n");
+	if (hasErrorMethod) {
+		fmt.Fprintf (w, "    if (err.Message != \"\") {\n");
+		fmt.Fprintf (w, "        return err.Method + \": \" + err.Code.Error () + \": \" + err.Message;\n");
+		fmt.Fprintf (w, "    }\n");
+	}
+	fmt.Fprintf (w, "    return err.Method + \": \" + err.Code.Error ();\n");
+	fmt.Fprintf (w, "}\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// Unwrap returns the error code of the error.\n");
+	fmt.Fprintf (w, "func (err *Error) Unwrap () (error) {\n");
+	fmt.Fprintf (w, "    return err.Code;\n");
+	fmt.Fprintf (w, "}\n");
+	fmt.Fprintf (w, "\n");
+
 	fmt.Fprintf (w, "/*************************************************************************************************************************\n");
 	fmt.Fprintf (w, " Declaration of interfaces\n");
 	fmt.Fprintf (w, "**************************************************************************************************************************/\n");
 	fmt.Fprintf (w, "\n");
-	fmt.Fprintf (w, "type %sHandle interface {\n", NameSpace);
+	fmt.Fprintf (w, "// Handle is the handle of an instance of a class of %s.\n", component.LibraryName);
+	fmt.Fprintf (w, "type Handle interface {\n");
 	fmt.Fprintf (w, "    Close() error\n");
 	fmt.Fprintf (w, "}\n");
 	fmt.Fprintf (w, "\n");
 
 	
 	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "package main\n");
+	fmt.Fprintf (implw, "package %s\n", packageName);
 	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "// #include <string.h>\n");
+	err = writeGoCPreamble (component, implw, NameSpace);
+	if (err != nil) {
+		return err;
+	}
 	fmt.Fprintf (implw, "import \"C\"\n");
 	fmt.Fprintf (implw, "\n");
 	fmt.Fprintf (implw, "import (\n");
-	fmt.Fprintf (implw, "    \"fmt\"\n");
+	if (len (component.Structs) > 0) {
+		fmt.Fprintf (implw, "    \"bytes\"\n");
+		fmt.Fprintf (implw, "    \"encoding/binary\"\n");
+	}
 	fmt.Fprintf (implw, "    \"errors\"\n");
-	fmt.Fprintf (implw, "    \"syscall\"\n");
 	fmt.Fprintf (implw, "    \"unsafe\"\n");
 	fmt.Fprintf (implw, ")\n");
 	fmt.Fprintf (implw, "\n");
 
 
-	fmt.Fprintf (implw, "type %sImplementation struct {\n", NameSpace);
-	fmt.Fprintf (implw, "    Initialized bool\n");
-	fmt.Fprintf (implw, "    DLLHandle syscall.Handle\n");
+	fmt.Fprintf (implw, "// Implementation implements Interface by calling the functions of %s.\n", component.LibraryName);
+	fmt.Fprintf (implw, "type Implementation struct {\n");
+	fmt.Fprintf (implw, "    initialized bool\n");
+	fmt.Fprintf (implw, "    libraryHandle unsafe.Pointer\n");
 	
 	
 	for i := 0; i < len(component.Classes); i++ {
 		class := component.Classes[i];
 		for j := 0; j < len(class.Methods); j++ {
-			method := class.Methods[j];
-			fmt.Fprintf (implw, "    %s_%s_%s%s uintptr\n", NameSpace, strings.ToLower (class.ClassName), strings.ToLower (method.MethodName), method.DLLSuffix);	
+			fmt.Fprintf (implw, "    %s unsafe.Pointer\n", GetCExportName (NameSpace, class.ClassName, class.Methods[j], false));	
 		}
 	}
 	
 	for j := 0; j < len(global.Methods); j++ {
-		method := global.Methods[j];
-
-		fmt.Fprintf (implw, "    %s_%s%s uintptr\n", NameSpace, strings.ToLower (method.MethodName), method.DLLSuffix);	
+		fmt.Fprintf (implw, "    %s unsafe.Pointer\n", GetCExportName (NameSpace, "", global.Methods[j], true));	
+	}
+	usesCallbacks := (len (component.Functions) > 0);
+	if (usesCallbacks) {
+		fmt.Fprintf (implw, "    callbacks callbackReferences\n");
 	}
 	
 	fmt.Fprintf (implw, "}\n");
 	fmt.Fprintf (implw, "\n");
 
 
-	fmt.Fprintf (implw, "type %sImplementationHandle interface {\n", NameSpace);
-	fmt.Fprintf (implw, "    %sHandle\n", NameSpace);
+	releaseMethod, err := getGoGlobalMethod (component, component.Global.ReleaseMethod);
+	if (err != nil) {
+		return err;
+	}
+	releaseParams, err := GenerateCParameters (releaseMethod, "Wrapper", NameSpace);
+	if (err != nil) {
+		return err;
+	}
+	if (len (releaseParams) != 1) {
+		return fmt.Errorf ("release method \"%s\" must have exactly one parameter", releaseMethod.MethodName);
+	}
+	releaseFunction := GetCExportName (NameSpace, "", releaseMethod, true);
+
+	fmt.Fprintf (implw, "// ImplementationHandle is a Handle of an instance that was created by an Implementation.\n");
+	fmt.Fprintf (implw, "type ImplementationHandle interface {\n");
+	fmt.Fprintf (implw, "    Handle\n");
 	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "    GetDLLInHandle () (uintptr)\n");
-	fmt.Fprintf (implw, "    GetDLLOutHandle () (uintptr)\n");
-	fmt.Fprintf (implw, "    GetWrapper () (*%sImplementation)\n", NameSpace);
+	fmt.Fprintf (implw, "    GetDLLInHandle () (unsafe.Pointer)\n");
+	fmt.Fprintf (implw, "    GetDLLOutHandle () (*unsafe.Pointer)\n");
+	fmt.Fprintf (implw, "    GetWrapper () (*Implementation)\n");
+	if (usesCallbacks) {
+		fmt.Fprintf (implw, "    getCallbacks () (*callbackReferences)\n");
+	}
 	fmt.Fprintf (implw, "}\n");
 	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "type %sImplementationHandleStruct struct {\n", NameSpace);
-	fmt.Fprintf (implw, "    Implementation * %sImplementation\n", NameSpace);
-	fmt.Fprintf (implw, "    DLLhandle uintptr\n");
+	fmt.Fprintf (implw, "type implementationHandle struct {\n");
+	fmt.Fprintf (implw, "    implementation * Implementation\n");
+	fmt.Fprintf (implw, "    dllHandle unsafe.Pointer\n");
+	if (usesCallbacks) {
+		fmt.Fprintf (implw, "    callbacks callbackReferences\n");
+	}
 	fmt.Fprintf (implw, "}\n");
 	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func (handle *%sImplementationHandleStruct) Close () (error) {\n", NameSpace);
+	fmt.Fprintf (implw, "func (handle *implementationHandle) Close () (error) {\n");
 	fmt.Fprintf (implw, "\n");	
-	fmt.Fprintf (implw, "    if (handle.DLLhandle != 0) {\n");
-	fmt.Fprintf (implw, "        if (handle.Implementation == nil) {\n");
+	fmt.Fprintf (implw, "    if (handle.dllHandle != nil) {\n");
+	fmt.Fprintf (implw, "        if (handle.implementation == nil) {\n");
 	fmt.Fprintf (implw, "            return errors.New (\"Uninitialized DLL Implementation Handle\");\n");
 	fmt.Fprintf (implw, "        }\n");
 	fmt.Fprintf (implw, "\n");	
-	fmt.Fprintf (implw, "        dllhandle := handle.DLLhandle;\n");
-	fmt.Fprintf (implw, "        handle.DLLhandle = 0;\n");
+	fmt.Fprintf (implw, "        err := handle.implementation.checkInitialized ();\n");
+	fmt.Fprintf (implw, "        if (err != nil) {\n");
+	fmt.Fprintf (implw, "            return err;\n");
+	fmt.Fprintf (implw, "        }\n");
+	fmt.Fprintf (implw, "\n");	
+	fmt.Fprintf (implw, "        dllHandle := handle.dllHandle;\n");
+	fmt.Fprintf (implw, "        handle.dllHandle = nil;\n");
 	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "        return handle.Implementation.CallFunction (handle.Implementation.%s_%s, dllhandle);\n", NameSpace, strings.ToLower (component.Global.ReleaseMethod));
+	if (usesCallbacks) {
+		fmt.Fprintf (implw, "        defer handle.callbacks.releaseAll ();\n");
+	}
+	fmt.Fprintf (implw, "        return checkError (\"%s\", C.%s_call (handle.implementation.%s, C.%s (dllHandle)));\n",
+		releaseMethod.MethodName, releaseFunction, releaseFunction, releaseParams[0].ParamType);
 	fmt.Fprintf (implw, "    }\n");
 	fmt.Fprintf (implw, "\n");
 	fmt.Fprintf (implw, "    return nil;\n");
-    fmt.Fprintf (implw, "}\n");
-    fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func (handle *%sImplementationHandleStruct) GetDLLInHandle () (uintptr) {\n", NameSpace);
-	fmt.Fprintf (implw, "    return handle.DLLhandle;\n");
-	fmt.Fprintf (implw, "}\n");
-	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func (handle *%sImplementationHandleStruct) GetDLLOutHandle () (uintptr) {\n", NameSpace);
-	fmt.Fprintf (implw, "    return uintptr (unsafe.Pointer (&handle.DLLhandle));\n");
-	fmt.Fprintf (implw, "}\n");
-	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func (handle *%sImplementationHandleStruct) GetWrapper () (*%sImplementation) {\n", NameSpace, NameSpace);
-	fmt.Fprintf (implw, "    return handle.Implementation;\n");
-	fmt.Fprintf (implw, "}\n");
-	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func Int64OutValue (reference * int64) uintptr {\n");
-	fmt.Fprintf (implw, "    return uintptr (unsafe.Pointer (reference));\n");
-	fmt.Fprintf (implw, "}\n");
-	fmt.Fprintf (implw, "func UInt64OutValue (reference * uint64) uintptr {\n");
-	fmt.Fprintf (implw, "    return uintptr (unsafe.Pointer (reference));\n");
 	fmt.Fprintf (implw, "}\n");
 	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func Float32OutValue (reference * float32) uintptr {\n");
-	fmt.Fprintf (implw, "    return uintptr (unsafe.Pointer (reference));\n");
+	fmt.Fprintf (implw, "func (handle *implementationHandle) GetDLLInHandle () (unsafe.Pointer) {\n");
+	fmt.Fprintf (implw, "    return handle.dllHandle;\n");
 	fmt.Fprintf (implw, "}\n");
 	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func Float64OutValue (reference * float64) uintptr {\n");
-	fmt.Fprintf (implw, "    return uintptr (unsafe.Pointer (reference));\n");
+	fmt.Fprintf (implw, "func (handle *implementationHandle) GetDLLOutHandle () (*unsafe.Pointer) {\n");
+	fmt.Fprintf (implw, "    return &handle.dllHandle;\n");
 	fmt.Fprintf (implw, "}\n");
 	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func Int64InValue (value int64) uintptr {\n");
-	fmt.Fprintf (implw, "    return uintptr (value);\n");
+	fmt.Fprintf (implw, "func (handle *implementationHandle) GetWrapper () (*Implementation) {\n");
+	fmt.Fprintf (implw, "    return handle.implementation;\n");
 	fmt.Fprintf (implw, "}\n");
 	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func UInt8InValue (value uint8) uintptr {\n");
-	fmt.Fprintf (implw, "    return uintptr (value);\n");
-	fmt.Fprintf (implw, "}\n");
-	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func UInt16InValue (value uint16) uintptr {\n");
-	fmt.Fprintf (implw, "    return uintptr (value);\n");
-	fmt.Fprintf (implw, "}\n");
-	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func UInt32InValue (value uint32) uintptr {\n");
-	fmt.Fprintf (implw, "    return uintptr (value);\n");
-	fmt.Fprintf (implw, "}\n");
-	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func Uint64InValue (value uint64) uintptr {\n");
-	fmt.Fprintf (implw, "    return uintptr (value);\n");
-	fmt.Fprintf (implw, "}\n");
-	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func Float32InValue (value float32) uintptr {\n");
-	fmt.Fprintf (implw, "    return uintptr (*(*uint32)(unsafe.Pointer(&value)));\n");
-	fmt.Fprintf (implw, "}\n");
-	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func Float64InValue (value float64) uintptr {\n");
-	fmt.Fprintf (implw, "    return uintptr (*(*uint64)(unsafe.Pointer(&value)));\n");
-	fmt.Fprintf (implw, "}\n");
-	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func StringInValue (value string) uintptr {\n");
-	fmt.Fprintf (implw, "    bytePtr, err := syscall.BytePtrFromString (value);\n");
-	fmt.Fprintf (implw, "    if err != nil {\n");
-	fmt.Fprintf (implw, "        return 0;\n");
-	fmt.Fprintf (implw, "    }	\n");
-	fmt.Fprintf (implw, "    return uintptr(unsafe.Pointer (bytePtr));\n");
-	fmt.Fprintf (implw, "}\n");
-	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func PtrOutValue (ptr * uintptr) uintptr {\n");
-	fmt.Fprintf (implw, "    return uintptr (unsafe.Pointer (ptr));\n");
-	fmt.Fprintf (implw, "}\n");
-	fmt.Fprintf (implw, "func BytesOutValue (bytePtr * []byte) uintptr {\n");
-	fmt.Fprintf (implw, "    return uintptr (unsafe.Pointer (bytePtr));\n");
-	fmt.Fprintf (implw, "}\n");
-	
-	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func Get%sErrorMessage	(errorcode uint32) (string) {\n", NameSpace);
-	fmt.Fprintf (implw, "    switch (errorcode) {\n");
-	
-	for i := 0; i < len(component.Errors.Errors); i++ {
-		errorcode := component.Errors.Errors[i];
-		fmt.Fprintf (implw, "        case %d: return \"%s\";\n", errorcode.Code, errorcode.Name);
+	if (usesCallbacks) {
+		fmt.Fprintf (implw, "func (handle *implementationHandle) getCallbacks () (*callbackReferences) {\n");
+		fmt.Fprintf (implw, "    return &handle.callbacks;\n");
+		fmt.Fprintf (implw, "}\n");
+		fmt.Fprintf (implw, "\n");
 	}
-	
-	fmt.Fprintf (implw, "        default:\n");
-	fmt.Fprintf (implw, "            return \"unknown\";\n");
+	fmt.Fprintf (implw, "func checkError (method string, errorcode C.%sResult) (error) {\n", NameSpace);
+	fmt.Fprintf (implw, "    if (errorcode != 0) {\n");
+	fmt.Fprintf (implw, "        return &Error {Method: method, Code: ErrorCode (errorcode)};\n");
 	fmt.Fprintf (implw, "    }\n");
+	fmt.Fprintf (implw, "\n");
+	fmt.Fprintf (implw, "    return nil;\n");
 	fmt.Fprintf (implw, "}\n");
 
+	errorMethod, hasErrorMethod := component.Global.GetErrorMethod ();
+	if (hasErrorMethod) {
+		errorFunction := GetCExportName (NameSpace, "", errorMethod, true);
+		fmt.Fprintf (implw, "\n");
+		fmt.Fprintf (implw, "// checkInstanceError returns an error with the error message of the instance, if the errorcode is not 0.\n");
+		fmt.Fprintf (implw, "func (implementation *Implementation) checkInstanceError (method string, instance unsafe.Pointer, errorcode C.%sResult) (error) {\n", NameSpace);
+		fmt.Fprintf (implw, "    if (errorcode != 0) {\n");
+		fmt.Fprintf (implw, "        return &Error {Method: method, Code: ErrorCode (errorcode), Message: implementation.getErrorMessage (instance)};\n");
+		fmt.Fprintf (implw, "    }\n");
+		fmt.Fprintf (implw, "\n");
+		fmt.Fprintf (implw, "    return nil;\n");
+		fmt.Fprintf (implw, "}\n");
+		fmt.Fprintf (implw, "\n");
+		fmt.Fprintf (implw, "// getErrorMessage returns the last error message of the instance, or an empty string if there is none.\n");
+		fmt.Fprintf (implw, "func (implementation *Implementation) getErrorMessage (instance unsafe.Pointer) (string) {\n");
+		fmt.Fprintf (implw, "    var neededChars C.%s_uint32 = 0;\n", NameSpace);
+		fmt.Fprintf (implw, "    var hasError C.bool = false;\n");
+		fmt.Fprintf (implw, "    if (C.%s_call (implementation.%s, C.%s_BaseClass (instance), 0, &neededChars, nil, &hasError) != 0) || !bool (hasError) {\n", errorFunction, errorFunction, NameSpace);
+		fmt.Fprintf (implw, "        return \"\";\n");
+		fmt.Fprintf (implw, "    }\n");
+		fmt.Fprintf (implw, "\n");
+		fmt.Fprintf (implw, "    buffer := make ([]byte, neededChars + 1);\n");
+		fmt.Fprintf (implw, "    if (C.%s_call (implementation.%s, C.%s_BaseClass (instance), C.%s_uint32 (len (buffer)), &neededChars, (*C.char) (unsafe.Pointer (&buffer[0])), &hasError) != 0) {\n", errorFunction, errorFunction, NameSpace, NameSpace);
+		fmt.Fprintf (implw, "        return \"\";\n");
+		fmt.Fprintf (implw, "    }\n");
+		fmt.Fprintf (implw, "\n");
+		fmt.Fprintf (implw, "    return C.GoString ((*C.char) (unsafe.Pointer (&buffer[0])));\n");
+		fmt.Fprintf (implw, "}\n");
+	}
+
+	if (len (component.Structs) > 0) {
+		writeGoNativeByteOrder (implw);
+		fmt.Fprintf (implw, "// packStructs writes a struct or a slice of structs with the packed layout of the C structs.\n");
+		fmt.Fprintf (implw, "func packStructs (structs interface{}) ([]byte, error) {\n");
+		fmt.Fprintf (implw, "    var buffer bytes.Buffer;\n");
+		fmt.Fprintf (implw, "    err := binary.Write (&buffer, nativeByteOrder, structs);\n");
+		fmt.Fprintf (implw, "    if (err != nil) {\n");
+		fmt.Fprintf (implw, "        return nil, err;\n");
+		fmt.Fprintf (implw, "    }\n");
+		fmt.Fprintf (implw, "    return buffer.Bytes (), nil;\n");
+		fmt.Fprintf (implw, "}\n");
+		fmt.Fprintf (implw, "\n");
+		fmt.Fprintf (implw, "// unpackStructs reads a struct or a slice of structs from the packed layout of the C structs.\n");
+		fmt.Fprintf (implw, "func unpackStructs (buffer []byte, structs interface{}) (error) {\n");
+		fmt.Fprintf (implw, "    return binary.Read (bytes.NewReader (buffer), nativeByteOrder, structs);\n");
+		fmt.Fprintf (implw, "}\n");
+		fmt.Fprintf (implw, "\n");
+		fmt.Fprintf (implw, "func getBufferPointer (buffer []byte) (unsafe.Pointer) {\n");
+		fmt.Fprintf (implw, "    if (len (buffer) == 0) {\n");
+		fmt.Fprintf (implw, "        return nil;\n");
+		fmt.Fprintf (implw, "    }\n");
+		fmt.Fprintf (implw, "    return unsafe.Pointer (&buffer[0]);\n");
+		fmt.Fprintf (implw, "}\n");
+	}
+
+	for _, functiontype := range component.Functions {
+		functionName := getGoIdentifier (functiontype.FunctionName);
+		fmt.Fprintf (implw, "\n");
+		fmt.Fprintf (implw, "// register%s returns the C function pointer that calls callback and the slot it occupies.\n", functionName);
+		fmt.Fprintf (implw, "// The slot must be released by callbackTable%s.release, once the library does not call it any more.\n", functionName);
+		fmt.Fprintf (implw, "func register%s (callback %s) (C.%s%s, int, error) {\n", functionName, functionName, NameSpace, functiontype.FunctionName);
+		fmt.Fprintf (implw, "    if (callback == nil) {\n");
+		fmt.Fprintf (implw, "        return nil, -1, nil;\n");
+		fmt.Fprintf (implw, "    }\n");
+		fmt.Fprintf (implw, "\n");
+		fmt.Fprintf (implw, "    slot, ok := callbackTable%s.register (*(*uintptr) (unsafe.Pointer (&callback)), callback);\n", functionName);
+		fmt.Fprintf (implw, "    if (!ok) {\n");
+		fmt.Fprintf (implw, "        return nil, -1, errors.New (\"%s: too many different %s functions in use\");\n", packageName, functionName);
+		fmt.Fprintf (implw, "    }\n");
+		fmt.Fprintf (implw, "\n");
+		fmt.Fprintf (implw, "    return C.%s_slot (C.int (slot)), slot, nil;\n", strings.ToLower (NameSpace + "_" + functiontype.FunctionName));
+		fmt.Fprintf (implw, "}\n");
+	}
+
 	
 	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func (implementation *%sImplementation) GetWrapperHandle (handle %sHandle) (%sImplementationHandle, error) {\n", NameSpace, NameSpace, NameSpace);
-	fmt.Fprintf (implw, "    implementation_handle, ok := handle.(%sImplementationHandle);\n", NameSpace);
+	fmt.Fprintf (implw, "// GetWrapperHandle checks that the handle was created by this implementation.\n");
+	fmt.Fprintf (implw, "func (implementation *Implementation) GetWrapperHandle (handle Handle) (ImplementationHandle, error) {\n");
+	fmt.Fprintf (implw, "    implementation_handle, ok := handle.(ImplementationHandle);\n");
 	fmt.Fprintf (implw, "    if ok {\n");
 	fmt.Fprintf (implw, "        handle_implementation := implementation_handle.GetWrapper ();\n");
 	fmt.Fprintf (implw, "        if (handle_implementation == implementation) {\n");
@@ -345,117 +495,119 @@ func buildGoWrapper (component ComponentDefinition, w io.Writer, implw io.Writer
 	fmt.Fprintf (implw, "    return nil, errors.New (\"Could not cast DLL handle.\");	\n");
 	fmt.Fprintf (implw, "}\n");
 	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func (implementation *%sImplementation) Initialize (DLLFileName string) error {	\n", NameSpace);
-	fmt.Fprintf (implw, "    implementation.Initialized = false;\n");
-	fmt.Fprintf (implw, "    implementation.DLLHandle = 0;\n");
+	fmt.Fprintf (implw, "func loadFunction (libraryHandle unsafe.Pointer, functionName string) (unsafe.Pointer, error) {\n");
+	fmt.Fprintf (implw, "    pFunctionName := C.CString (functionName);\n");
+	fmt.Fprintf (implw, "    defer C.free (unsafe.Pointer (pFunctionName));\n");
 	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "    dllHandle, err := syscall.LoadLibrary(DLLFileName);\n");
-	fmt.Fprintf (implw, "    if (err != nil) {\n");
-	fmt.Fprintf (implw, "        return err;\n");
+	fmt.Fprintf (implw, "    function := C.%s_loadsymbol (libraryHandle, pFunctionName);\n", strings.ToLower (NameSpace));
+	fmt.Fprintf (implw, "    if (function == nil) {\n");
+	fmt.Fprintf (implw, "        return nil, errors.New (\"Could not get function \" + functionName);\n");
+	fmt.Fprintf (implw, "    }\n");
+	fmt.Fprintf (implw, "\n");
+	fmt.Fprintf (implw, "    return function, nil;\n");
+	fmt.Fprintf (implw, "}\n");
+	fmt.Fprintf (implw, "\n");
+	fmt.Fprintf (implw, "// Initialize loads %s from a shared library.\n", component.LibraryName);
+	fmt.Fprintf (implw, "func (implementation *Implementation) Initialize (libraryFileName string) error {	\n");
+	fmt.Fprintf (implw, "    implementation.initialized = false;\n");
+	fmt.Fprintf (implw, "    implementation.libraryHandle = nil;\n");
+	fmt.Fprintf (implw, "\n");
+	fmt.Fprintf (implw, "    pLibraryFileName := C.CString (libraryFileName);\n");
+	fmt.Fprintf (implw, "    defer C.free (unsafe.Pointer (pLibraryFileName));\n");
+	fmt.Fprintf (implw, "\n");
+	fmt.Fprintf (implw, "    libraryHandle := C.%s_loadlibrary (pLibraryFileName);\n", strings.ToLower (NameSpace));
+	fmt.Fprintf (implw, "    if (libraryHandle == nil) {\n");
+	fmt.Fprintf (implw, "        return errors.New (\"Could not load library \" + libraryFileName);\n");
 	fmt.Fprintf (implw, "    }\n");
 	fmt.Fprintf (implw, "\n");
+	fmt.Fprintf (implw, "    var err error = nil;\n");
 
+	functionNames := []string{};
 	for i := 0; i < len(component.Classes); i++ {
 		class := component.Classes[i];
 		for j := 0; j < len(class.Methods); j++ {
-			method := class.Methods[j];
-			functionName := fmt.Sprintf ("%s_%s_%s%s", strings.ToLower (NameSpace), strings.ToLower (class.ClassName), strings.ToLower (method.MethodName), method.DLLSuffix);
-			fmt.Fprintf (implw, "    implementation.%s_%s_%s%s, err = syscall.GetProcAddress (dllHandle, \"%s\");\n", NameSpace, strings.ToLower (class.ClassName), strings.ToLower (method.MethodName), method.DLLSuffix, functionName);
-			fmt.Fprintf (implw, "    if (err != nil) {\n");
-			fmt.Fprintf (implw, "        return errors.New (\"Could not get function %s: \" + err.Error());\n", functionName);
-			fmt.Fprintf (implw, "    }\n");
-			fmt.Fprintf (implw, "    \n");
+			functionNames = append (functionNames, GetCExportName (NameSpace, class.ClassName, class.Methods[j], false));
 		}
 	}
-	
 	for j := 0; j < len(global.Methods); j++ {
-		method := global.Methods[j];
-		
-		functionName := fmt.Sprintf ("%s_%s%s", strings.ToLower (NameSpace), strings.ToLower (method.MethodName), method.DLLSuffix);
-		fmt.Fprintf (implw, "    implementation.%s_%s%s, err = syscall.GetProcAddress (dllHandle, \"%s\");\n", NameSpace, strings.ToLower (method.MethodName), method.DLLSuffix, functionName);
+		functionNames = append (functionNames, GetCExportName (NameSpace, "", global.Methods[j], true));
+	}
+	
+	for _, functionName := range functionNames {
+		fmt.Fprintf (implw, "    implementation.%s, err = loadFunction (libraryHandle, \"%s\");\n", functionName, functionName);
 		fmt.Fprintf (implw, "    if (err != nil) {\n");
-		fmt.Fprintf (implw, "        return errors.New (\"Could not get function %s: \" + err.Error());\n", functionName);
+		fmt.Fprintf (implw, "        C.%s_unloadlibrary (libraryHandle);\n", strings.ToLower (NameSpace));
+		fmt.Fprintf (implw, "        return err;\n");
 		fmt.Fprintf (implw, "    }\n");
 		fmt.Fprintf (implw, "    \n");
 	}
 	
-	
-	fmt.Fprintf (implw, "    implementation.DLLHandle =  dllHandle;\n");
-	fmt.Fprintf (implw, "    implementation.Initialized = true;	\n");
+	fmt.Fprintf (implw, "    implementation.libraryHandle = libraryHandle;\n");
+	fmt.Fprintf (implw, "    implementation.initialized = true;	\n");
 	fmt.Fprintf (implw, "    return nil;\n");
 	fmt.Fprintf (implw, "}\n");
 	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func (implementation *%sImplementation) NewHandle () (%sImplementationHandle) {	\n", NameSpace, NameSpace);
-	fmt.Fprintf (implw, "    handle := new (%sImplementationHandleStruct);\n", NameSpace);
-	fmt.Fprintf (implw, "    handle.Implementation = implementation;\n");
-	fmt.Fprintf (implw, "    handle.DLLhandle = 0;	\n");
+	fmt.Fprintf (implw, "// NewHandle returns an empty handle of this implementation.\n");
+	fmt.Fprintf (implw, "func (implementation *Implementation) NewHandle () (ImplementationHandle) {	\n");
+	fmt.Fprintf (implw, "    handle := new (implementationHandle);\n");
+	fmt.Fprintf (implw, "    handle.implementation = implementation;\n");
+	fmt.Fprintf (implw, "    handle.dllHandle = nil;	\n");
 	fmt.Fprintf (implw, "    return handle;	\n");
 	fmt.Fprintf (implw, "}\n");	
 	fmt.Fprintf (implw, "\n");
-	fmt.Fprintf (implw, "func (implementation *%sImplementation) CallFunction (funcptr uintptr, parameters ... uintptr) (error) {\n", NameSpace);
-	fmt.Fprintf (implw, "    var ret uintptr;\n");
-	fmt.Fprintf (implw, "    if (!implementation.Initialized) {\n");
+	fmt.Fprintf (implw, "func (implementation *Implementation) checkInitialized () (error) {\n");
+	fmt.Fprintf (implw, "    if (!implementation.initialized) {\n");
 	fmt.Fprintf (implw, "        return errors.New (\"%s Implementation has not been initialized!\");\n", NameSpace);
 	fmt.Fprintf (implw, "    }\n");
-	fmt.Fprintf (implw, "	\n");
-	fmt.Fprintf (implw, "    switch len(parameters) { \n");
-	fmt.Fprintf (implw, "        case 0: ret, _, _ = syscall.Syscall (funcptr, 0, 0, 0, 0); \n");
-	fmt.Fprintf (implw, "        case 1: ret, _, _ = syscall.Syscall (funcptr, 1, uintptr (parameters[0]), 0, 0); \n");
-	fmt.Fprintf (implw, "        case 2: ret, _, _ = syscall.Syscall (funcptr, 2, uintptr (parameters[0]), uintptr (parameters[1]), 0); \n");
-	fmt.Fprintf (implw, "        case 3: ret, _, _ = syscall.Syscall (funcptr, 3, uintptr (parameters[0]), uintptr (parameters[1]), uintptr (parameters[2])); \n");
-	fmt.Fprintf (implw, "        case 4: ret, _, _ = syscall.Syscall6 (funcptr, 4, uintptr (parameters[0]), uintptr (parameters[1]), uintptr (parameters[2]), uintptr (parameters[3]), 0, 0); \n");
-	fmt.Fprintf (implw, "        case 5: ret, _, _ = syscall.Syscall6 (funcptr, 5, uintptr (parameters[0]), uintptr (parameters[1]), uintptr (parameters[2]), uintptr (parameters[3]), uintptr (parameters[4]), 0);	\n");
-	fmt.Fprintf (implw, "        case 6: ret, _, _ = syscall.Syscall6 (funcptr, 6, uintptr (parameters[0]), uintptr (parameters[1]), uintptr (parameters[2]), uintptr (parameters[3]), uintptr (parameters[4]), uintptr (parameters[5]));\n");
-	fmt.Fprintf (implw, "        case 7: ret, _, _ = syscall.Syscall9 (funcptr, 7, uintptr (parameters[0]), uintptr (parameters[1]), uintptr (parameters[2]), uintptr (parameters[3]), uintptr (parameters[4]), uintptr (parameters[5]), uintptr (parameters[6]), 0, 0);\n");
-	fmt.Fprintf (implw, "        case 8: ret, _, _ = syscall.Syscall9 (funcptr, 8, uintptr (parameters[0]), uintptr (parameters[1]), uintptr (parameters[2]), uintptr (parameters[3]), uintptr (parameters[4]), uintptr (parameters[5]), uintptr (parameters[6]), uintptr (parameters[7]), 0);\n");
-	fmt.Fprintf (implw, "        case 9: ret, _, _ = syscall.Syscall9 (funcptr, 9, uintptr (parameters[0]), uintptr (parameters[1]), uintptr (parameters[2]), uintptr (parameters[3]), uintptr (parameters[4]), uintptr (parameters[5]), uintptr (parameters[6]), uintptr (parameters[7]), uintptr (parameters[8]));\n");
-	fmt.Fprintf (implw, "        case 10: ret, _, _ = syscall.Syscall12 (funcptr, 10, uintptr (parameters[0]), uintptr (parameters[1]), uintptr (parameters[2]), uintptr (parameters[3]), uintptr (parameters[4]), uintptr (parameters[5]), uintptr (parameters[6]), uintptr (parameters[7]), uintptr (parameters[8]), uintptr (parameters[9]), 0, 0);\n");
-	fmt.Fprintf (implw, "        case 11: ret, _, _ = syscall.Syscall12 (funcptr, 11, uintptr (parameters[0]), uintptr (parameters[1]), uintptr (parameters[2]), uintptr (parameters[3]), uintptr (parameters[4]), uintptr (parameters[5]), uintptr (parameters[6]), uintptr (parameters[7]), uintptr (parameters[8]), uintptr (parameters[9]), uintptr (parameters[10]), 0);\n");
-	fmt.Fprintf (implw, "        case 12: ret, _, _ = syscall.Syscall12 (funcptr, 12, uintptr (parameters[0]), uintptr (parameters[1]), uintptr (parameters[2]), uintptr (parameters[3]), uintptr (parameters[4]), uintptr (parameters[5]), uintptr (parameters[6]), uintptr (parameters[7]), uintptr (parameters[8]), uintptr (parameters[9]), uintptr (parameters[10]), uintptr (parameters[11]));\n");
-	fmt.Fprintf (implw, "        default: \n");
-	fmt.Fprintf (implw, "            return errors.New (\"Invalid DLL function parameter count!\");\n");
-	fmt.Fprintf (implw, "    }\n");
-	fmt.Fprintf (implw, "    \n");
-	fmt.Fprintf (implw, "    if (int(ret) != 0) {\n");
-	fmt.Fprintf (implw, "        return errors.New (fmt.Sprintf (\"%s Error: %%.04x (%%s)\", int(ret), Get%sErrorMessage (uint32(ret))));\n", NameSpace, NameSpace);
-	fmt.Fprintf (implw, "    }\n");
 	fmt.Fprintf (implw, "\n");
 	fmt.Fprintf (implw, "    return nil;\n");
 	fmt.Fprintf (implw, "}\n");
 	fmt.Fprintf (implw, "\n");
 	
 	
-	fmt.Fprintf (w, "type %sGoInterface interface {\n", NameSpace);
+	fmt.Fprintf (w, "// Interface declares the functions of %s, that are called by the classes of this package.\n", component.LibraryName);
+	fmt.Fprintf (w, "type Interface interface {\n");
 	
-	spacing := "    ";
+	spacing := "";
 	classdefinitions := "";
 
    		
 	for i := 0; i < len(component.Classes); i++ {
 	
 		class := component.Classes[i];
+		className := getGoIdentifier (class.ClassName);
 		
 		classdefinitions = classdefinitions + fmt.Sprintf ("\n");
 		classdefinitions = classdefinitions + fmt.Sprintf ("/*************************************************************************************************************************\n");
-		classdefinitions = classdefinitions + fmt.Sprintf (" Class definition %s%s\n", NameSpace, class.ClassName);
+		classdefinitions = classdefinitions + fmt.Sprintf (" Class definition %s\n", className);
 		classdefinitions = classdefinitions + fmt.Sprintf ("**************************************************************************************************************************/\n");
 		classdefinitions = classdefinitions + fmt.Sprintf ("\n");
-		classdefinitions = classdefinitions + fmt.Sprintf ("%stype %s%s struct {\n", spacing, NameSpace, class.ClassName);
-		classdefinitions = classdefinitions + fmt.Sprintf ("%s    Interface %sGoInterface;\n", spacing, NameSpace);
-		classdefinitions = classdefinitions + fmt.Sprintf ("%s    Handle %sHandle;\n", spacing, NameSpace);
+		if (class.ClassDescription != "") {
+			classdefinitions = classdefinitions + fmt.Sprintf ("%s// %s - %s\n", spacing, className, class.ClassDescription);
+		}
+		classdefinitions = classdefinitions + fmt.Sprintf ("%stype %s struct {\n", spacing, className);
+		if (class.ParentClass != "") {
+			classdefinitions = classdefinitions + fmt.Sprintf ("%s    %s;\n", spacing, getGoIdentifier (class.ParentClass));
+		} else {
+			classdefinitions = classdefinitions + fmt.Sprintf ("%s    Interface Interface;\n", spacing);
+			classdefinitions = classdefinitions + fmt.Sprintf ("%s    Handle Handle;\n", spacing);
+		}
 		classdefinitions = classdefinitions + fmt.Sprintf ("%s}\n", spacing);
 		classdefinitions = classdefinitions + fmt.Sprintf ("%s\n", spacing);
 
-		classdefinitions = classdefinitions + fmt.Sprintf ("%sfunc (instance *%s%s) Close () (error) {\n", spacing, NameSpace, class.ClassName);
-		classdefinitions = classdefinitions + fmt.Sprintf ("%s    return instance.Handle.Close ();\n", spacing);
-		classdefinitions = classdefinitions + fmt.Sprintf ("%s}\n", spacing);
-		classdefinitions = classdefinitions + fmt.Sprintf ("%s\n", spacing);
+		if (class.ParentClass == "") {
+			classdefinitions = classdefinitions + fmt.Sprintf ("%s// Close releases the instance.\n", spacing);
+			classdefinitions = classdefinitions + fmt.Sprintf ("%sfunc (instance *%s) Close () (error) {\n", spacing, className);
+			classdefinitions = classdefinitions + fmt.Sprintf ("%s    return instance.Handle.Close ();\n", spacing);
+			classdefinitions = classdefinitions + fmt.Sprintf ("%s}\n", spacing);
+			classdefinitions = classdefinitions + fmt.Sprintf ("%s\n", spacing);
+		}
 		
 
 		for 	j := 0; j < len(class.Methods); j++ {
 			method := class.Methods[j];
 			
-			err := writeGoMethod (method, w, implw, NameSpace, class.ClassName, false, &classdefinitions);
+			err := writeGoMethod (method, w, implw, NameSpace, class.ClassName, false, hasErrorMethod, false, &classdefinitions);
 			if (err != nil) {
 				return err;
 			}
@@ -465,72 +617,444 @@ func buildGoWrapper (component ComponentDefinition, w io.Writer, implw io.Writer
 
 	classdefinitions = classdefinitions + fmt.Sprintf ("\n");
 	classdefinitions = classdefinitions + fmt.Sprintf ("/*************************************************************************************************************************\n");
-	classdefinitions = classdefinitions + fmt.Sprintf (" Class definition %sWrapper\n", NameSpace);
+	classdefinitions = classdefinitions + fmt.Sprintf (" Class definition Wrapper\n");
 	classdefinitions = classdefinitions + fmt.Sprintf ("**************************************************************************************************************************/\n");
 	classdefinitions = classdefinitions + fmt.Sprintf ("\n");
-	classdefinitions = classdefinitions + fmt.Sprintf ("%stype %sWrapper struct {\n", spacing, NameSpace);
-	classdefinitions = classdefinitions + fmt.Sprintf ("%s    Interface %sGoInterface;\n", spacing, NameSpace);
+	classdefinitions = classdefinitions + fmt.Sprintf ("%s// Wrapper gives access to the global functions of %s.\n", spacing, component.LibraryName);
+	classdefinitions = classdefinitions + fmt.Sprintf ("%stype Wrapper struct {\n", spacing);
+	classdefinitions = classdefinitions + fmt.Sprintf ("%s    Interface Interface;\n", spacing);
 	classdefinitions = classdefinitions + fmt.Sprintf ("%s}\n", spacing);
 	classdefinitions = classdefinitions + fmt.Sprintf ("%s\n", spacing);
 	
-	global = component.Global;
 	for j := 0; j < len(global.Methods); j++ {
 		method := global.Methods[j];
 			
-		err := writeGoMethod (method, w, implw, NameSpace, "Wrapper", true, &classdefinitions);
+		releasesCallbacks := (len (component.Functions) > 0) && (method.MethodName == component.Global.ReleaseMethod);
+		err := writeGoMethod (method, w, implw, NameSpace, "Wrapper", true, hasErrorMethod && (method.MethodName != component.Global.ErrorMethod), releasesCallbacks, &classdefinitions);
+		if (err != nil) {
+			return err;
+		}
+	}
+	
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "}\n");
+	fmt.Fprintf (w, "\n");
+	
+	fmt.Fprintf (w, "%s", classdefinitions);
+	
+	fmt.Fprintf (implw, "// LoadWrapper loads %s from a shared library.\n", component.LibraryName);
+	fmt.Fprintf (implw, "func LoadWrapper (libraryFileName string) (Wrapper, error) {\n");
+	fmt.Fprintf (implw, "    var wrapper Wrapper;\n");
+	fmt.Fprintf (implw, "    var instance Implementation;\n");
+	fmt.Fprintf (implw, "    \n");
+	fmt.Fprintf (implw, "    err := instance.Initialize (libraryFileName);\n");
+	fmt.Fprintf (implw, "    if (err != nil) {\n");
+	fmt.Fprintf (implw, "        return wrapper, err;\n");
+	fmt.Fprintf (implw, "    }\n");
+	fmt.Fprintf (implw, "    \n");
+	fmt.Fprintf (implw, "    wrapper.Interface = &instance;\n");
+	fmt.Fprintf (implw, "    \n");
+	fmt.Fprintf (implw, "    return wrapper, nil;\n");
+	fmt.Fprintf (implw, "}\n");
+	fmt.Fprintf (implw, "\n");
+		
+	
+	return nil;
+}
+
+
+
+// writeGoTypeDeclarations writes the enums, structs, function types and error codes of the component
+func writeGoTypeDeclarations (component ComponentDefinition, w io.Writer, NameSpace string) (error) {
+	packageName := getGoPackageName (component);
+
+	fmt.Fprintf (w, "/*************************************************************************************************************************\n");
+	fmt.Fprintf (w, " Declaration of enums\n");
+	fmt.Fprintf (w, "**************************************************************************************************************************/\n");
+	fmt.Fprintf (w, "\n");
+
+	for i := 0; i < len(component.Enums); i++ {
+		enum := component.Enums[i];
+		enumName := getGoIdentifier (enum.Name);
+		fmt.Fprintf (w, "// %s is the enum e%s%s of %s.\n", enumName, NameSpace, enum.Name, component.LibraryName);
+		fmt.Fprintf (w, "type %s int32\n", enumName);
+		fmt.Fprintf (w, "\n");
+
+		fmt.Fprintf (w, "const (\n");
+		
+		for j := 0; j < len(enum.Options); j++ {			
+				
+			option := enum.Options[j];
+			fmt.Fprintf (w, "    %s%s %s = %d\n", enumName, getGoIdentifier (option.Name), enumName, option.Value);
+		}
+		
+		fmt.Fprintf (w, ")\n");
+		fmt.Fprintf (w, "\n");
+	}
+	
+	
+	fmt.Fprintf (w, "\n");
+
+	if len(component.Structs) > 0 {
+		fmt.Fprintf (w, "/*************************************************************************************************************************\n");
+		fmt.Fprintf (w, " Declaration of structs\n");
+		fmt.Fprintf (w, "**************************************************************************************************************************/\n");
+		fmt.Fprintf (w, "\n");
+			
+		for i := 0; i < len(component.Structs); i++ {
+			structinfo := component.Structs[i];
+			fmt.Fprintf (w, "// %s is the struct s%s%s of %s.\n", getGoIdentifier (structinfo.Name), NameSpace, structinfo.Name, component.LibraryName);
+			fmt.Fprintf (w, "type %s struct {\n", getGoIdentifier (structinfo.Name));
+			
+			for j := 0; j < len(structinfo.Members); j++ {			
+
+				member := structinfo.Members[j];
+				memberName := getGoIdentifier (member.Name);
+			
+				arraysuffix := "";
+				if (member.Rows > 0) {
+					if (member.Columns > 0) {
+						arraysuffix = fmt.Sprintf ("[%d][%d]", member.Columns, member.Rows)
+					} else {
+						arraysuffix = fmt.Sprintf ("[%d]",member.Rows)
+					}
+				}
+			
+				switch (member.Type) {
+					case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "bool", "single", "double":
+						goType, err := getGoBasicType (member.Type);
+						if (err != nil) {
+							return err;
+						}
+						fmt.Fprintf (w, "    %s %s%s;\n", memberName, arraysuffix, goType);
+					case "string":
+						return fmt.Errorf ("it is not possible for struct s%s%s to contain a string value", NameSpace, structinfo.Name);
+					case "handle":
+						return fmt.Errorf ("it is not possible for struct s%s%s to contain a handle value", NameSpace, structinfo.Name);
+					case "enum":
+						fmt.Fprintf (w, "    %s %s%s;\n", memberName, arraysuffix, getGoIdentifier (member.Class));
+				}
+				
+			}
+			
+			fmt.Fprintf (w, "}\n");
+			fmt.Fprintf (w, "\n");
+		}
+		
+		fmt.Fprintf (w, "\n");
+
+	}
+
+	if len(component.Functions) > 0 {
+		fmt.Fprintf (w, "/*************************************************************************************************************************\n");
+		fmt.Fprintf (w, " Declaration of function types\n");
+		fmt.Fprintf (w, "**************************************************************************************************************************/\n");
+		fmt.Fprintf (w, "\n");
+
+		for i := 0; i < len(component.Functions); i++ {
+			functiontype := component.Functions[i];
+			functionName := getGoIdentifier (functiontype.FunctionName);
+
+			parameters, returnvalues, comments, err := getGoFunctionTypeSignature (functiontype);
+			if (err != nil) {
+				return err;
+			}
+
+			fmt.Fprintf (w, "// %s is the function type %s%s of %s.\n", functionName, NameSpace, functiontype.FunctionName, component.LibraryName);
+			if (functiontype.FunctionDescription != "") {
+				fmt.Fprintf (w, "// %s\n", strings.TrimSpace (functiontype.FunctionDescription));
+			}
+			if (comments != "") {
+				fmt.Fprintf (w, "//\n");
+				fmt.Fprintf (w, "%s", comments);
+			}
+			fmt.Fprintf (w, "type %s func (%s) (%s)\n", functionName, parameters, returnvalues);
+			fmt.Fprintf (w, "\n");
+		}
+
+		fmt.Fprintf (w, "\n");
+	}
+
+	fmt.Fprintf (w, "/*************************************************************************************************************************\n");
+	fmt.Fprintf (w, " Declaration of errors\n");
+	fmt.Fprintf (w, "**************************************************************************************************************************/\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// ErrorCode is an error code of %s.\n", component.LibraryName);
+	fmt.Fprintf (w, "// Use errors.Is to compare the errors returned by this package with the error codes.\n");
+	fmt.Fprintf (w, "type ErrorCode int32\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "const (\n");
+	for i := 0; i < len(component.Errors.Errors); i++ {
+		errorcode := component.Errors.Errors[i];
+		writeGoDocComment (w, "    ", "Error" + errorcode.Name, errorcode.Description);
+		fmt.Fprintf (w, "    Error%s ErrorCode = %d\n", errorcode.Name, errorcode.Code);
+	}
+	fmt.Fprintf (w, ")\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// Error returns the description of the error code.\n");
+	fmt.Fprintf (w, "func (errorCode ErrorCode) Error () (string) {\n");
+	fmt.Fprintf (w, "    switch (errorCode) {\n");
+	for i := 0; i < len(component.Errors.Errors); i++ {
+		errorcode := component.Errors.Errors[i];
+		description := errorcode.Description;
+		if (description == "") {
+			description = strings.ToLower (errorcode.Name);
+		}
+		fmt.Fprintf (w, "        case Error%s: return \"%s: %s (%s)\";\n", errorcode.Name, packageName, strings.Replace (description, "\"", "\\\"", -1), errorcode.Name);
+	}
+	fmt.Fprintf (w, "        default:\n");
+	fmt.Fprintf (w, "            return fmt.Sprintf (\"%s: unknown error %%d\", int32 (errorCode));\n", packageName);
+	fmt.Fprintf (w, "    }\n");
+	fmt.Fprintf (w, "}\n");
+	fmt.Fprintf (w, "\n");
+	return nil;
+}
+
+
+// writeGoNativeByteOrder writes the detection of the byte order, in which the packed structs are exchanged with C
+func writeGoNativeByteOrder (w io.Writer) {
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// nativeByteOrder is the byte order in which the packed structs are exchanged with the library.\n");
+	fmt.Fprintf (w, "var nativeByteOrder = getNativeByteOrder ();\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "func getNativeByteOrder () (binary.ByteOrder) {\n");
+	fmt.Fprintf (w, "    var value uint16 = 1;\n");
+	fmt.Fprintf (w, "    if (*(*byte) (unsafe.Pointer (&value)) == 1) {\n");
+	fmt.Fprintf (w, "        return binary.LittleEndian;\n");
+	fmt.Fprintf (w, "    }\n");
+	fmt.Fprintf (w, "    return binary.BigEndian;\n");
+	fmt.Fprintf (w, "}\n");
+	fmt.Fprintf (w, "\n");
+}
+
+func getGoBasicType (paramType string) (string, error) {
+	
+	switch (paramType) {
+		case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "bool":
+			return paramType, nil;
+	
+		case "single":
+			return "float32", nil;
+			
+		case "double":
+			return "float64", nil;
+		
+			
+	}
+		
+	return "", errors.New ("Invalid basic type: " + paramType);
+		
+}
+
+
+func getGoGlobalMethod (component ComponentDefinition, methodName string) (ComponentDefinitionMethod, error) {
+	for j := 0; j < len(component.Global.Methods); j++ {
+		method := component.Global.Methods[j];
+		if (method.MethodName == methodName) {
+			return method, nil;
+		}
+	}
+	
+	return ComponentDefinitionMethod{}, fmt.Errorf ("unknown global method \"%s\"", methodName);
+}
+
+
+// writeGoCPreamble writes the C part of the cgo implementation file. It loads the library with
+// dlopen/dlsym or LoadLibrary/GetProcAddress and declares a trampoline for every exported function,
+// that calls the function pointer with the C signature of the export, and the callback slots of the function types.
+func writeGoCPreamble (component ComponentDefinition, implw io.Writer, NameSpace string) (error) {
+	nameSpaceLower := strings.ToLower (NameSpace);
+
+	fmt.Fprintf (implw, "/*\n");
+	fmt.Fprintf (implw, "#cgo linux LDFLAGS: -ldl\n");
+	fmt.Fprintf (implw, "#include <stdlib.h>\n");
+	fmt.Fprintf (implw, "#include <stdint.h>\n");
+	fmt.Fprintf (implw, "#include <stdbool.h>\n");
+	fmt.Fprintf (implw, "\n");
+	fmt.Fprintf (implw, "#ifdef _WIN32\n");
+	fmt.Fprintf (implw, "#include <windows.h>\n");
+	fmt.Fprintf (implw, "\n");
+	fmt.Fprintf (implw, "static void * %s_loadlibrary (const char * pFileName) { return (void *) LoadLibraryA (pFileName); }\n", nameSpaceLower);
+	fmt.Fprintf (implw, "static void * %s_loadsymbol (void * pLibrary, const char * pSymbolName) { return (void *) GetProcAddress ((HMODULE) pLibrary, pSymbolName); }\n", nameSpaceLower);
+	fmt.Fprintf (implw, "static void %s_unloadlibrary (void * pLibrary) { FreeLibrary ((HMODULE) pLibrary); }\n", nameSpaceLower);
+	fmt.Fprintf (implw, "#else\n");
+	fmt.Fprintf (implw, "#include <dlfcn.h>\n");
+	fmt.Fprintf (implw, "\n");
+	fmt.Fprintf (implw, "static void * %s_loadlibrary (const char * pFileName) { return dlopen (pFileName, RTLD_LAZY); }\n", nameSpaceLower);
+	fmt.Fprintf (implw, "static void * %s_loadsymbol (void * pLibrary, const char * pSymbolName) { return dlsym (pLibrary, pSymbolName); }\n", nameSpaceLower);
+	fmt.Fprintf (implw, "static void %s_unloadlibrary (void * pLibrary) { dlclose (pLibrary); }\n", nameSpaceLower);
+	fmt.Fprintf (implw, "#endif\n");
+	fmt.Fprintf (implw, "\n");
+
+	err := writeGoCTypes (component, implw, NameSpace, "void *");
+	if (err != nil) {
+		return err;
+	}
+
+	for _, functiontype := range component.Functions {
+		err := writeGoCCallbackSlots (functiontype, implw, NameSpace);
+		if (err != nil) {
+			return err;
+		}
+	}
+	
+	for i := 0; i < len(component.Classes); i++ {
+		class := component.Classes[i];
+		for j := 0; j < len(class.Methods); j++ {
+			err := writeGoCTrampoline (class.Methods[j], implw, NameSpace, class.ClassName, false);
+			if (err != nil) {
+				return err;
+			}
+		}
+	}
+	
+	for j := 0; j < len(component.Global.Methods); j++ {
+		err := writeGoCTrampoline (component.Global.Methods[j], implw, NameSpace, "Wrapper", true);
+		if (err != nil) {
+			return err;
+		}
+	}
+
+	fmt.Fprintf (implw, "*/\n");
+	return nil;
+}
+
+
+// writeGoCTypes writes the C types of the component into a cgo preamble. Structs are only passed
+// as packed byte buffers, so they remain opaque to cgo.
+func writeGoCTypes (component ComponentDefinition, implw io.Writer, NameSpace string, handleType string) (error) {
+	for _, basicType := range []string {"uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64"} {
+		fmt.Fprintf (implw, "typedef %s_t %s_%s;\n", basicType, NameSpace, basicType);
+	}
+	fmt.Fprintf (implw, "typedef float %s_single;\n", NameSpace);
+	fmt.Fprintf (implw, "typedef double %s_double;\n", NameSpace);
+	fmt.Fprintf (implw, "typedef int32_t %sResult;\n", NameSpace);
+	fmt.Fprintf (implw, "typedef %s %sHandle;\n", handleType, NameSpace);
+	fmt.Fprintf (implw, "typedef %sHandle %s_BaseClass;\n", NameSpace, NameSpace);
+	for i := 0; i < len(component.Classes); i++ {
+		fmt.Fprintf (implw, "typedef %sHandle %s_%s;\n", NameSpace, NameSpace, component.Classes[i].ClassName);
+	}
+	for i := 0; i < len(component.Enums); i++ {
+		fmt.Fprintf (implw, "typedef int32_t e%s%s;\n", NameSpace, component.Enums[i].Name);
+	}
+	for i := 0; i < len(component.Structs); i++ {
+		fmt.Fprintf (implw, "typedef void s%s%s;\n", NameSpace, component.Structs[i].Name);
+	}
+	for _, functiontype := range component.Functions {
+		cParams, err := getGoCallbackCParameters (functiontype, NameSpace);
 		if (err != nil) {
 			return err;
 		}
+		types := "";
+		for k, cParam := range cParams {
+			if (k > 0) {
+				types = types + ", ";
+			}
+			types = types + cParam.ParamType;
+		}
+		fmt.Fprintf (implw, "typedef void (*%s%s) (%s);\n", NameSpace, functiontype.FunctionName, types);
+	}
+
+	return nil;
+}
+
+
+// goCallbackSlots is the number of C functions that are generated for each function type
+const goCallbackSlots = 32;
+
+// writeGoCCallbackSlots writes the C functions that forward the calls of a function type to its exported Go dispatcher.
+// Each of them stands for one Go function, as the C function pointer cannot carry the Go function along.
+func writeGoCCallbackSlots (functiontype ComponentDefinitionFunctionType, implw io.Writer, NameSpace string) (error) {
+	cParams, err := getGoCallbackCParameters (functiontype, NameSpace);
+	if (err != nil) {
+		return err;
 	}
 	
-	fmt.Fprintf (w, "\n");
-	fmt.Fprintf (w, "}\n");
-	fmt.Fprintf (w, "\n");
-	
-	fmt.Fprintf (w, classdefinitions);
-	
-	fmt.Fprintf (implw, "func %sLoadWrapper (DllFileName string) (%sWrapper, error) {\n", NameSpace, NameSpace);
-	fmt.Fprintf (implw, "    var Wrapper %sWrapper;\n", NameSpace);
-	fmt.Fprintf (implw, "    var Instance %sImplementation;\n", NameSpace);
-	fmt.Fprintf (implw, "    \n");
-	fmt.Fprintf (implw, "    err := Instance.Initialize (DllFileName);\n");
-	fmt.Fprintf (implw, "    if (err != nil) {\n");
-	fmt.Fprintf (implw, "        return Wrapper, err;\n");
-	fmt.Fprintf (implw, "    }\n");
-	fmt.Fprintf (implw, "    \n");
-	fmt.Fprintf (implw, "    Wrapper.Interface = &Instance;\n");
-	fmt.Fprintf (implw, "    \n");
-	fmt.Fprintf (implw, "    return Wrapper, nil;\n");
-	fmt.Fprintf (implw, "}\n");
+	slotName := strings.ToLower (NameSpace + "_" + functiontype.FunctionName);
+	typeName := NameSpace + functiontype.FunctionName;
+	parameters := "";
+	types := "int";
+	names := "";
+	for k, cParam := range cParams {
+		if (k > 0) {
+			parameters = parameters + ", ";
+		}
+		parameters = parameters + cParam.ParamType + " " + cParam.ParamName;
+		types = types + ", " + cParam.ParamType;
+		names = names + ", " + cParam.ParamName;
+	}
+	if (parameters == "") {
+		parameters = "void";
+	}
+
 	fmt.Fprintf (implw, "\n");
-		
-	
+	fmt.Fprintf (implw, "extern void dispatch%s (%s);\n", typeName, types);
+	fmt.Fprintf (implw, "\n");
+	for slot := 0; slot < goCallbackSlots; slot++ {
+		fmt.Fprintf (implw, "static void %s_%d (%s) { dispatch%s (%d%s); }\n", slotName, slot, parameters, typeName, slot, names);
+	}
+	fmt.Fprintf (implw, "\n");
+	fmt.Fprintf (implw, "static %s %s_slots[%d] = {\n", typeName, slotName, goCallbackSlots);
+	for slot := 0; slot < goCallbackSlots; slot++ {
+		separator := ",";
+		if (slot == goCallbackSlots - 1) {
+			separator = "";
+		}
+		fmt.Fprintf (implw, "	%s_%d%s\n", slotName, slot, separator);
+	}
+	fmt.Fprintf (implw, "};\n");
+	fmt.Fprintf (implw, "\n");
+	fmt.Fprintf (implw, "static %s %s_slot (int nSlot) { return %s_slots[nSlot]; }\n", typeName, slotName, slotName);
+
 	return nil;
 }
 
 
+func writeGoCTrampoline (method ComponentDefinitionMethod, implw io.Writer, NameSpace string, ClassName string, isGlobal bool) (error) {
+	cParams, err := GenerateCParameters (method, ClassName, NameSpace);
+	if (err != nil) {
+		return err;
+	}
+	
+	if (!isGlobal) {
+		cParams = append ([]CParameter{ CParameter{ ParamType: fmt.Sprintf ("%s_%s", NameSpace, ClassName), ParamName: "p" + ClassName } }, cParams...);
+	}
 
-func getGoBasicType (paramType string) (string, error) {
+	parameters := "void * pFunction";
+	types := "";
+	names := "";
+	for k, cParam := range cParams {
+		if (k > 0) {
+			types = types + ", ";
+			names = names + ", ";
+		}
+		parameters = parameters + ", " + cParam.ParamType + " " + cParam.ParamName;
+		types = types + cParam.ParamType;
+		names = names + cParam.ParamName;
+	}
 	
-	switch (paramType) {
-		case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "bool":
-			return paramType, nil;
+	fmt.Fprintf (implw, "\n");
+	fmt.Fprintf (implw, "static %sResult %s_call (%s)\n", NameSpace, GetCExportName (NameSpace, ClassName, method, isGlobal), parameters);
+	fmt.Fprintf (implw, "{\n");
+	fmt.Fprintf (implw, "	return ((%sResult (*) (%s)) pFunction) (%s);\n", NameSpace, types, names);
+	fmt.Fprintf (implw, "}\n");
 	
-		case "single":
-			return "float32", nil;
-			
-		case "double":
-			return "float64", nil;
-		
-			
-	}
-		
-	return "", errors.New ("Invalid basic type: " + paramType);
-		
+	return nil;
 }
 
 
-func writeGoMethod (method ComponentDefinitionMethod, w io.Writer, implw io.Writer, NameSpace string, ClassName string, isGlobal bool, classdefinitions * string) (error) {
+// getGoStringOutput returns how a string output parameter passes its byte slice
+func getGoStringOutput (NameSpace string) (CStringOutputSyntax) {
+	return CStringOutputSyntax {
+		Declaration: "var neededfor%[1]s C." + NameSpace + "_uint32 = 0;",
+		NeededChars: "neededfor%[1]s",
+		Allocation: "buffer%[1]s := make ([]byte, %[2]s);",
+		QueryArguments: "0, &neededfor%[1]s, nil",
+		CallArguments: "C." + NameSpace + "_uint32 (len (buffer%[1]s)), &neededfor%[1]s, (*C.char) (unsafe.Pointer (&buffer%[1]s[0]))",
+	};
+}
+
+func writeGoMethod (method ComponentDefinitionMethod, w io.Writer, implw io.Writer, NameSpace string, ClassName string, isGlobal bool, doErrorMessages bool, releasesCallbacks bool, classdefinitions * string) (error) {
 
 	parameters := "";
 	callparameters := "";
@@ -575,7 +1099,7 @@ func writeGoMethod (method ComponentDefinitionMethod, w io.Writer, implw io.Writ
 					errorreturn = errorreturn + fmt.Sprintf ("make ([]%s, 0), ", basicType);
 					
 				case "structarray":
-					errorreturn = errorreturn + fmt.Sprintf ("make ([]s%s%s, 0), ", NameSpace, param.ParamClass);
+					errorreturn = errorreturn + fmt.Sprintf ("make ([]%s, 0), ", getGoIdentifier (param.ParamClass));
 					
 				default:
 					return fmt.Errorf ("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName);
@@ -584,8 +1108,27 @@ func writeGoMethod (method ComponentDefinitionMethod, w io.Writer, implw io.Writ
 	}
 	errorreturn = errorreturn + "err";
 	
-
-	if (!isGlobal) {
+	
+	CMethodName := GetCExportName (NameSpace, ClassName, method, isGlobal);
+	implfunctionpointer := "implementation." + CMethodName;
+	var calls CBufferCalls;
+	calls.AddArgument (implfunctionpointer);
+	goMethodName := getGoIdentifier (method.MethodName);
+	goClassName := getGoIdentifier (ClassName);
+	errorMethodName := goClassName + "." + goMethodName;
+	checkErrorCall := fmt.Sprintf ("checkError (\"%s\", ", errorMethodName);
+	if (isGlobal) {
+		errorMethodName = goMethodName;
+		checkErrorCall = fmt.Sprintf ("checkError (\"%s\", ", errorMethodName);
+		if (doErrorMessages) {
+			checkErrorCall = fmt.Sprintf ("implementation.checkInstanceError (\"%s\", nil, ", errorMethodName);
+		}
+	} else {
+		calls.AddArgument (fmt.Sprintf ("C.%s_%s (implementation_%s.GetDLLInHandle ())", NameSpace, ClassName, strings.ToLower (ClassName)));
+		if (doErrorMessages) {
+			checkErrorCall = fmt.Sprintf ("implementation.checkInstanceError (\"%s\", implementation_%s.GetDLLInHandle (), ", errorMethodName, strings.ToLower (ClassName));
+		}
+	
 		implcasts = implcasts + fmt.Sprintf ("%s\n", spacing);
 		implcasts = implcasts + fmt.Sprintf ("%simplementation_%s, err := implementation.GetWrapperHandle (%s);\n", spacing, strings.ToLower (ClassName), ClassName);
 		implcasts = implcasts + fmt.Sprintf ("%sif (err != nil) {\n", spacing);
@@ -593,8 +1136,12 @@ func writeGoMethod (method ComponentDefinitionMethod, w io.Writer, implw io.Writ
 		implcasts = implcasts + fmt.Sprintf ("%s}\n", spacing);
 	}
 
-	implcommandparameters := "";
-	implcommandpreparation := "";
+	// The library may store a callback and call it later, so the instance keeps its slot
+	callbackOwner := "implementation.callbacks";
+	if (!isGlobal) {
+		callbackOwner = fmt.Sprintf ("implementation_%s.getCallbacks ()", strings.ToLower (ClassName));
+	}
+
 	implcommandpost := "";
 
 	classreturnvariables := "";
@@ -604,6 +1151,12 @@ func writeGoMethod (method ComponentDefinitionMethod, w io.Writer, implw io.Writ
 	
 	for k := 0; k < len(method.Params); k++ {
 		param := method.Params [k];
+		cParams, err := generateCParameter (param, ClassName, method.MethodName, NameSpace);
+		if (err != nil) {
+			return err;
+		}
+		cParamType := strings.TrimSpace (strings.TrimPrefix (strings.TrimSuffix (cParams[0].ParamType, "*"), "const "));
+		
 		switch (param.ParamPass) {				
 		case "in":
 		
@@ -614,94 +1167,55 @@ func writeGoMethod (method ComponentDefinitionMethod, w io.Writer, implw io.Writ
 				callparameters = callparameters + ", ";
 			}
 			
+			commandparameter := "";
+			
 			switch (param.ParamType) {
-				case "uint8":
-					comments = comments + fmt.Sprintf("    * @param[in] n%s - %s\n", param.ParamName, param.ParamDescription);
-					parameters = parameters + fmt.Sprintf ("n%s uint8", param.ParamName)
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", UInt8InValue (n%s)", param.ParamName);
-					callparameters = callparameters + "n" + param.ParamName;
-
-				case "uint16":
-					comments = comments + fmt.Sprintf("    * @param[in] n%s - %s\n", param.ParamName, param.ParamDescription);
-					parameters = parameters + fmt.Sprintf ("n%s uint16", param.ParamName)
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", UInt16InValue (n%s)", param.ParamName);
-					callparameters = callparameters + "n" + param.ParamName;
-				
-				case "uint32":
-					comments = comments + fmt.Sprintf("    * @param[in] n%s - %s\n", param.ParamName, param.ParamDescription);
-					parameters = parameters + fmt.Sprintf ("n%s uint32", param.ParamName)
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", UInt32InValue (n%s)", param.ParamName);
-					callparameters = callparameters + "n" + param.ParamName;
-				
-				case "uint64":
-					comments = comments + fmt.Sprintf("    * @param[in] n%s - %s\n", param.ParamName, param.ParamDescription);
-					parameters = parameters + fmt.Sprintf ("n%s uint64", param.ParamName)
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", UInt64InValue (n%s)", param.ParamName);
-					callparameters = callparameters + "n" + param.ParamName;
-				
-				case "int8":
-					comments = comments + fmt.Sprintf("    * @param[in] n%s - %s\n", param.ParamName, param.ParamDescription);
-					parameters = parameters + fmt.Sprintf ("n%s int8", param.ParamName)
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", Int8InValue (n%s)", param.ParamName);
+				case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64":
+					comments = comments + fmt.Sprintf("//   n%s - %s\n", param.ParamName, param.ParamDescription);
+					parameters = parameters + fmt.Sprintf ("n%s %s", param.ParamName, param.ParamType)
+					commandparameter = fmt.Sprintf ("C.%s (n%s)", cParamType, param.ParamName);
 					callparameters = callparameters + "n" + param.ParamName;
 
-				case "int16":
-					comments = comments + fmt.Sprintf("    * @param[in] n%s - %s\n", param.ParamName, param.ParamDescription);
-					parameters = parameters + fmt.Sprintf ("n%s int16", param.ParamName)
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", Int16InValue (n%s)", param.ParamName);
-					callparameters = callparameters + "n" + param.ParamName;
-					
-				case "int32":
-					comments = comments + fmt.Sprintf("    * @param[in] n%s - %s\n", param.ParamName, param.ParamDescription);
-					parameters = parameters + fmt.Sprintf ("n%s int32", param.ParamName)
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", Int32InValue (n%s)", param.ParamName);
-					callparameters = callparameters + "n" + param.ParamName;
-					
-				case "int64":
-					comments = comments + fmt.Sprintf("    * @param[in] n%s - %s\n", param.ParamName, param.ParamDescription);
-					parameters = parameters + fmt.Sprintf ("n%s int64", param.ParamName)
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", Int64InValue (n%s)", param.ParamName);
-					callparameters = callparameters + "n" + param.ParamName;
-					
 				case "bool":
-					comments = comments + fmt.Sprintf("    * @param[in] b%s - %s\n", param.ParamName, param.ParamDescription);
-					impldeclarations = impldeclarations + fmt.Sprintf ("%svar n%s uint32 = 0;\n", spacing, param.ParamName);
-					impldeclarations = impldeclarations + fmt.Sprintf ("%sif (b%s) {\n", spacing, param.ParamName);
-					impldeclarations = impldeclarations + fmt.Sprintf ("%s    n%s = 1;\n", spacing, param.ParamName);
-					impldeclarations = impldeclarations + fmt.Sprintf ("%s}\n", spacing);
-					impldeclarations = impldeclarations + fmt.Sprintf ("%s\n", spacing);
+					comments = comments + fmt.Sprintf("//   b%s - %s\n", param.ParamName, param.ParamDescription);
 					parameters = parameters + fmt.Sprintf ("b%s bool", param.ParamName)
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", UInt32InValue (n%s)", param.ParamName);
+					commandparameter = fmt.Sprintf ("C.bool (b%s)", param.ParamName);
 					callparameters = callparameters + "b" + param.ParamName;
 					
 				case "single":				
-					comments = comments + fmt.Sprintf("    * @param[in] f%s - %s\n", param.ParamName, param.ParamDescription);
+					comments = comments + fmt.Sprintf("//   f%s - %s\n", param.ParamName, param.ParamDescription);
 					parameters = parameters + fmt.Sprintf ("f%s float32", param.ParamName)
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", Float32InValue (f%s)", param.ParamName);
+					commandparameter = fmt.Sprintf ("C.%s (f%s)", cParamType, param.ParamName);
 					callparameters = callparameters + "f" + param.ParamName;
 
 				case "double":				
-					comments = comments + fmt.Sprintf("    * @param[in] d%s - %s\n", param.ParamName, param.ParamDescription);
+					comments = comments + fmt.Sprintf("//   d%s - %s\n", param.ParamName, param.ParamDescription);
 					parameters = parameters + fmt.Sprintf ("d%s float64", param.ParamName)
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", Float64InValue (d%s)", param.ParamName);
+					commandparameter = fmt.Sprintf ("C.%s (d%s)", cParamType, param.ParamName);
 					callparameters = callparameters + "d" + param.ParamName;
 												
 				case "string":
-					comments = comments + fmt.Sprintf("    * @param[in] s%s - %s\n", param.ParamName, param.ParamDescription);
+					comments = comments + fmt.Sprintf("//   s%s - %s\n", param.ParamName, param.ParamDescription);
 					parameters = parameters + fmt.Sprintf ("s%s string", param.ParamName)
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", StringInValue (s%s)", param.ParamName);
+					impldeclarations = impldeclarations + fmt.Sprintf ("%sp%s := C.CString (s%s);\n", spacing, param.ParamName, param.ParamName);
+					impldeclarations = impldeclarations + fmt.Sprintf ("%sdefer C.free (unsafe.Pointer (p%s));\n", spacing, param.ParamName);
+					commandparameter = fmt.Sprintf ("p%s", param.ParamName);
 					callparameters = callparameters + "s" + param.ParamName;
 
 				case "enum":				
-					comments = comments + fmt.Sprintf("    * @param[in] e%s - %s\n", param.ParamName, param.ParamDescription);
-					parameters = parameters + fmt.Sprintf ("e%s E%s%s", param.ParamName, NameSpace, param.ParamClass)
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", uintptr (e%s)", param.ParamName);
+					comments = comments + fmt.Sprintf("//   e%s - %s\n", param.ParamName, param.ParamDescription);
+					parameters = parameters + fmt.Sprintf ("e%s %s", param.ParamName, getGoIdentifier (param.ParamClass))
+					commandparameter = fmt.Sprintf ("C.%s (e%s)", cParamType, param.ParamName);
 					callparameters = callparameters + "e" + param.ParamName;
 
 				case "struct":
-					comments = comments + fmt.Sprintf("    * @param[in] s%s - %s\n", param.ParamName, param.ParamDescription);
-					parameters = parameters + fmt.Sprintf ("s%s s%s%s", param.ParamName, NameSpace, param.ParamClass)
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", uintptr (unsafe.Pointer (&s%s))", param.ParamName);
+					comments = comments + fmt.Sprintf("//   s%s - %s\n", param.ParamName, param.ParamDescription);
+					parameters = parameters + fmt.Sprintf ("s%s %s", param.ParamName, getGoIdentifier (param.ParamClass))
+					implcasts = implcasts + fmt.Sprintf ("%sbuffer%s, err := packStructs (&s%s);\n", spacing, param.ParamName, param.ParamName);
+					implcasts = implcasts + fmt.Sprintf ("%sif (err != nil) {\n", spacing);
+					implcasts = implcasts + fmt.Sprintf ("%s    return %s;\n", spacing, errorreturn);
+					implcasts = implcasts + fmt.Sprintf ("%s}\n", spacing);
+					commandparameter = fmt.Sprintf ("getBufferPointer (buffer%s)", param.ParamName);
 					callparameters = callparameters + "s" + param.ParamName;
 
 				case "basicarray":
@@ -710,27 +1224,44 @@ func writeGoMethod (method ComponentDefinitionMethod, w io.Writer, implw io.Writ
 						return err;
 					}
 					
-					comments = comments + fmt.Sprintf("    * @param[in] %s - %s\n", param.ParamName, param.ParamDescription);
+					comments = comments + fmt.Sprintf("//   %s - %s\n", param.ParamName, param.ParamDescription);
 					parameters = parameters + fmt.Sprintf ("%s []%s", param.ParamName, basicType)
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", 0, 0");
+					elementType := getGoCType (cParams[1].ParamType);
+					impldeclarations = impldeclarations + fmt.Sprintf ("%svar p%s %s = nil;\n", spacing, param.ParamName, elementType);
+					impldeclarations = impldeclarations + fmt.Sprintf ("%sif (len (%s) > 0) {\n", spacing, param.ParamName);
+					impldeclarations = impldeclarations + fmt.Sprintf ("%s    p%s = (%s) (unsafe.Pointer (&%s[0]));\n", spacing, param.ParamName, elementType, param.ParamName);
+					impldeclarations = impldeclarations + fmt.Sprintf ("%s}\n", spacing);
+					commandparameter = fmt.Sprintf ("%s (len (%s)), p%s", getGoCType (cParams[0].ParamType), param.ParamName, param.ParamName);
 					callparameters = callparameters + param.ParamName;
 
 
 				case "structarray":					
-					comments = comments + fmt.Sprintf("    * @param[in] %s - %s\n", param.ParamName, param.ParamDescription);
-					parameters = parameters + fmt.Sprintf ("%s []s%s%s", param.ParamName, NameSpace, param.ParamClass)
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", 0, 0");
+					comments = comments + fmt.Sprintf("//   %s - %s\n", param.ParamName, param.ParamDescription);
+					parameters = parameters + fmt.Sprintf ("%s []%s", param.ParamName, getGoIdentifier (param.ParamClass))
+					implcasts = implcasts + fmt.Sprintf ("%sbuffer%s, err := packStructs (%s);\n", spacing, param.ParamName, param.ParamName);
+					implcasts = implcasts + fmt.Sprintf ("%sif (err != nil) {\n", spacing);
+					implcasts = implcasts + fmt.Sprintf ("%s    return %s;\n", spacing, errorreturn);
+					implcasts = implcasts + fmt.Sprintf ("%s}\n", spacing);
+					commandparameter = fmt.Sprintf ("%s (len (%s)), getBufferPointer (buffer%s)", getGoCType (cParams[0].ParamType), param.ParamName, param.ParamName);
 					callparameters = callparameters + param.ParamName;
 
 				case "functiontype":
-					comments = comments + fmt.Sprintf("    * @param[in] p%s - %s\n", param.ParamName, param.ParamDescription);
-					parameters = parameters + fmt.Sprintf ("p%s int64", param.ParamName)
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", 0");
+					comments = comments + fmt.Sprintf("//   p%s - %s\n", param.ParamName, param.ParamDescription);
+					parameters = parameters + fmt.Sprintf ("p%s %s", param.ParamName, getGoIdentifier (param.ParamClass))
+					implcasts = implcasts + fmt.Sprintf ("%sp%sFunction, p%sSlot, err := register%s (p%s);\n", spacing, param.ParamName, param.ParamName, getGoIdentifier (param.ParamClass), param.ParamName);
+					implcasts = implcasts + fmt.Sprintf ("%sif (err != nil) {\n", spacing);
+					implcasts = implcasts + fmt.Sprintf ("%s    return %s;\n", spacing, errorreturn);
+					implcasts = implcasts + fmt.Sprintf ("%s}\n", spacing);
+					implcasts = implcasts + fmt.Sprintf ("%sdefer func () {\n", spacing);
+					implcasts = implcasts + fmt.Sprintf ("%s    %s.keep (\"%s.%s\", func () { callbackTable%s.release (p%sSlot); }, err != nil);\n",
+						spacing, callbackOwner, method.MethodName, param.ParamName, getGoIdentifier (param.ParamClass), param.ParamName);
+					implcasts = implcasts + fmt.Sprintf ("%s} ();\n", spacing);
+					commandparameter = fmt.Sprintf ("p%sFunction", param.ParamName);
 					callparameters = callparameters + "p" + param.ParamName;
 				
 				case "handle":
-					comments = comments + fmt.Sprintf("    * @param[in] %s - %s\n", param.ParamName, param.ParamDescription);
-					parameters = parameters + fmt.Sprintf ("%s %sHandle", param.ParamName, NameSpace)
+					comments = comments + fmt.Sprintf("//   %s - %s\n", param.ParamName, param.ParamDescription);
+					parameters = parameters + fmt.Sprintf ("%s Handle", param.ParamName)
 				
 					implcasts = implcasts + fmt.Sprintf ("%simplementation_%s, err := implementation.GetWrapperHandle (%s);\n", spacing, strings.ToLower (param.ParamName), param.ParamName);
 					implcasts = implcasts + fmt.Sprintf ("%sif (err != nil) {\n", spacing);
@@ -738,152 +1269,61 @@ func writeGoMethod (method ComponentDefinitionMethod, w io.Writer, implw io.Writ
 					implcasts = implcasts + fmt.Sprintf ("%s}\n", spacing);
 					implcasts = implcasts + fmt.Sprintf ("%s\n", spacing);
 				
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", implementation_%s.GetDLLInHandle ()", strings.ToLower (param.ParamName));
+					commandparameter = fmt.Sprintf ("C.%s (implementation_%s.GetDLLInHandle ())", cParamType, strings.ToLower (param.ParamName));
+					if (releasesCallbacks) {
+						implcommandpost = implcommandpost + fmt.Sprintf ("%simplementation_%s.getCallbacks ().releaseAll ();\n", spacing, strings.ToLower (param.ParamName));
+					}
 					callparameters = callparameters + param.ParamName;
 				
 				default:
 					return fmt.Errorf ("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName);
 			}
 			
+			calls.AddArgument (commandparameter);
+			
 		case "out", "return":
 		
+			commandparameter := "";
+			
 			switch (param.ParamType) {
-				case "uint8":				
-					comments = comments + fmt.Sprintf ("    * @return %s\n", param.ParamDescription);
-					returnvalues = returnvalues + fmt.Sprintf ("uint8, ")
-					impldeclarations = impldeclarations + fmt.Sprintf ("%svar n%s int64 = 0;\n", spacing, param.ParamName);
-					implreturnvalues = implreturnvalues + fmt.Sprintf ("uint8(n%s), ", param.ParamName);
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", Int64OutValue (&n%s)", param.ParamName);
-					classreturnvariables = classreturnvariables + "n" + param.ParamName + ", ";
-					classreturnstring = classreturnstring + "n" + param.ParamName + ", ";
-					classreturntypes = classreturntypes + fmt.Sprintf ("uint8, ");
-
-				case "uint16":				
-					comments = comments + fmt.Sprintf ("    * @return %s\n", param.ParamDescription);
-					returnvalues = returnvalues + fmt.Sprintf ("uint16, ")
-					impldeclarations = impldeclarations + fmt.Sprintf ("%svar n%s int64 = 0;\n", spacing, param.ParamName);
-					implreturnvalues = implreturnvalues + fmt.Sprintf ("uint16(n%s), ", param.ParamName);
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", Int64OutValue (&n%s)", param.ParamName);
-					classreturnvariables = classreturnvariables + "n" + param.ParamName + ", ";
-					classreturnstring = classreturnstring + "n" + param.ParamName + ", ";
-					classreturntypes = classreturntypes + fmt.Sprintf ("uint16, ");
-					
-				case "uint32":				
-					comments = comments + fmt.Sprintf ("    * @return %s\n", param.ParamDescription);
-					returnvalues = returnvalues + fmt.Sprintf ("uint32, ")
-					impldeclarations = impldeclarations + fmt.Sprintf ("%svar n%s int64 = 0;\n", spacing, param.ParamName);
-					implreturnvalues = implreturnvalues + fmt.Sprintf ("uint32(n%s), ", param.ParamName);
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", Int64OutValue (&n%s)", param.ParamName);
-					classreturnvariables = classreturnvariables + "n" + param.ParamName + ", ";
-					classreturnstring = classreturnstring + "n" + param.ParamName + ", ";
-					classreturntypes = classreturntypes + fmt.Sprintf ("uint32, ");
-					
-				case "uint64":
-					comments = comments + fmt.Sprintf ("    * @return %s\n", param.ParamDescription);
-					returnvalues = returnvalues + fmt.Sprintf ("uint64, ")
-					impldeclarations = impldeclarations + fmt.Sprintf ("%svar n%s uint64 = 0;\n", spacing, param.ParamName);
-					implreturnvalues = implreturnvalues + fmt.Sprintf ("n%s, ", param.ParamName);
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", UInt64OutValue (&n%s)", param.ParamName);
-					classreturnvariables = classreturnvariables + "n" + param.ParamName + ", ";
-					classreturnstring = classreturnstring + "n" + param.ParamName + ", ";
-					classreturntypes = classreturntypes + fmt.Sprintf ("uint64, ");
-				
-				case "int8":				
-					comments = comments + fmt.Sprintf ("    * @return %s\n", param.ParamDescription);
-					returnvalues = returnvalues + fmt.Sprintf ("int8, ")
-					impldeclarations = impldeclarations + fmt.Sprintf ("%svar n%s int64 = 0;\n", spacing, param.ParamName);
-					implreturnvalues = implreturnvalues + fmt.Sprintf ("int8(n%s), ", param.ParamName);
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", Int64OutValue (&n%s)", param.ParamName);
-					classreturnvariables = classreturnvariables + "n" + param.ParamName + ", ";
-					classreturnstring = classreturnstring + "n" + param.ParamName + ", ";
-					classreturntypes = classreturntypes + fmt.Sprintf ("int8, ");
-
-				case "int16":				
-					comments = comments + fmt.Sprintf ("    * @return %s\n", param.ParamDescription);
-					returnvalues = returnvalues + fmt.Sprintf ("int16, ")
-					impldeclarations = impldeclarations + fmt.Sprintf ("%svar n%s int64 = 0;\n", spacing, param.ParamName);
-					implreturnvalues = implreturnvalues + fmt.Sprintf ("int16(n%s), ", param.ParamName);
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", Int64OutValue (&n%s)", param.ParamName);
-					classreturnvariables = classreturnvariables + "n" + param.ParamName + ", ";
-					classreturnstring = classreturnstring + "n" + param.ParamName + ", ";
-					classreturntypes = classreturntypes + fmt.Sprintf ("int16, ");
-					
-				case "int32":				
-					comments = comments + fmt.Sprintf ("    * @return %s\n", param.ParamDescription);
-					returnvalues = returnvalues + fmt.Sprintf ("int32, ")
-					impldeclarations = impldeclarations + fmt.Sprintf ("%svar n%s int64 = 0;\n", spacing, param.ParamName);
-					implreturnvalues = implreturnvalues + fmt.Sprintf ("int32(n%s), ", param.ParamName);
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", Int64OutValue (&n%s)", param.ParamName);
-					classreturnvariables = classreturnvariables + "n" + param.ParamName + ", ";
-					classreturnstring = classreturnstring + "n" + param.ParamName + ", ";
-					classreturntypes = classreturntypes + fmt.Sprintf ("int32, ");
+				case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "single", "double":
+					goType, err := getGoBasicType (param.ParamType);
+					if (err != nil) {
+						return err;
+					}
+					prefix := "n";
+					if (param.ParamType == "single") {
+						prefix = "f";
+					}
+					if (param.ParamType == "double") {
+						prefix = "d";
+					}
 					
-				case "int64":
-					comments = comments + fmt.Sprintf ("    * @return %s\n", param.ParamDescription);
-					returnvalues = returnvalues + fmt.Sprintf ("int64, ")
-					impldeclarations = impldeclarations + fmt.Sprintf ("%svar n%s int64 = 0;\n", spacing, param.ParamName);
-					implreturnvalues = implreturnvalues + fmt.Sprintf ("n%s, ", param.ParamName);
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", Int64OutValue (&n%s)", param.ParamName);
-					classreturnvariables = classreturnvariables + "n" + param.ParamName + ", ";
-					classreturnstring = classreturnstring + "n" + param.ParamName + ", ";
-					classreturntypes = classreturntypes + fmt.Sprintf ("int64, ");
+					comments = comments + fmt.Sprintf ("//   returns %s\n", param.ParamDescription);
+					returnvalues = returnvalues + fmt.Sprintf ("%s, ", goType)
+					impldeclarations = impldeclarations + fmt.Sprintf ("%svar %s%s C.%s = 0;\n", spacing, prefix, param.ParamName, cParamType);
+					implreturnvalues = implreturnvalues + fmt.Sprintf ("%s (%s%s), ", goType, prefix, param.ParamName);
+					commandparameter = fmt.Sprintf ("&%s%s", prefix, param.ParamName);
+					classreturnvariables = classreturnvariables + prefix + param.ParamName + ", ";
+					classreturnstring = classreturnstring + prefix + param.ParamName + ", ";
+					classreturntypes = classreturntypes + fmt.Sprintf ("%s, ", goType);
 
 				case "bool":
-					comments = comments + fmt.Sprintf("    * @return %s\n", param.ParamDescription);
+					comments = comments + fmt.Sprintf ("//   returns %s\n", param.ParamDescription);
 					returnvalues = returnvalues + fmt.Sprintf ("bool, ")
-					impldeclarations = impldeclarations + fmt.Sprintf ("%svar b%s int64 = 0;\n", spacing, param.ParamName);
-					implreturnvalues = implreturnvalues + fmt.Sprintf ("(b%s != 0), ", param.ParamName);
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", Int64OutValue (&b%s)", param.ParamName);
+					impldeclarations = impldeclarations + fmt.Sprintf ("%svar b%s C.bool = false;\n", spacing, param.ParamName);
+					implreturnvalues = implreturnvalues + fmt.Sprintf ("bool (b%s), ", param.ParamName);
+					commandparameter = fmt.Sprintf ("&b%s", param.ParamName);
 					classreturnvariables = classreturnvariables + "b" + param.ParamName + ", ";
 					classreturnstring = classreturnstring + "b" + param.ParamName + ", ";
 					classreturntypes = classreturntypes + fmt.Sprintf ("bool, ");
-
-				case "single":				
-					comments = comments + fmt.Sprintf ("    * @return %s\n", param.ParamDescription);
-					returnvalues = returnvalues + fmt.Sprintf ("float32, ")
-					impldeclarations = impldeclarations + fmt.Sprintf ("%svar f%s float32 = 0;\n", spacing, param.ParamName);
-					implreturnvalues = implreturnvalues + fmt.Sprintf ("f%s, ", param.ParamName);
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", Float32OutValue (&f%s)", param.ParamName);
-					classreturnvariables = classreturnvariables + "f" + param.ParamName + ", ";
-					classreturnstring = classreturnstring + "f" + param.ParamName + ", ";
-					classreturntypes = classreturntypes + fmt.Sprintf ("float32, ");
-
-				case "double":
-					comments = comments + fmt.Sprintf ("    * @return %s\n", param.ParamDescription);
-					returnvalues = returnvalues + fmt.Sprintf ("float64, ")
-					impldeclarations = impldeclarations + fmt.Sprintf ("%svar d%s float64 = 0;\n", spacing, param.ParamName);
-					implreturnvalues = implreturnvalues + fmt.Sprintf ("d%s, ", param.ParamName);
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", Float64OutValue (&d%s)", param.ParamName);
-					classreturnvariables = classreturnvariables + "d" + param.ParamName + ", ";
-					classreturnstring = classreturnstring + "d" + param.ParamName + ", ";
-					classreturntypes = classreturntypes + fmt.Sprintf ("float64, ");
 					
 				case "string":
-					comments = comments + fmt.Sprintf("    * @return %s\n", param.ParamDescription);
+					comments = comments + fmt.Sprintf ("//   returns %s\n", param.ParamDescription);
 				
-					implcommandpreparation = implcommandpreparation + fmt.Sprintf ("%svar neededfor%s int64 = 0;\n", spacing, param.ParamName);
-					implcommandpreparation = implcommandpreparation + fmt.Sprintf ("%svar filledin%s int64 = 0;\n", spacing, param.ParamName);
-					implcommandpreparation = implcommandpreparation + fmt.Sprintf ("%serr = implementation.CallFunction (implementation.%s_%s_%s%s, implementation_%s.GetDLLInHandle()%s, Int64InValue (0), Int64InValue (0), Int64OutValue (&neededfor%s));\n", spacing, NameSpace, strings.ToLower(ClassName), strings.ToLower(method.MethodName), method.DLLSuffix, strings.ToLower(ClassName), implcommandparameters, param.ParamName);
-					implcommandpreparation = implcommandpreparation + fmt.Sprintf ("%sif (err != nil) {\n", spacing);
-					implcommandpreparation = implcommandpreparation + fmt.Sprintf ("%s    return %s;\n", spacing, errorreturn);
-					implcommandpreparation = implcommandpreparation + fmt.Sprintf ("%s}\n", spacing);
-					implcommandpreparation = implcommandpreparation + fmt.Sprintf ("%s\n", spacing);
-					implcommandpreparation = implcommandpreparation + fmt.Sprintf ("%sif (neededfor%s < 0) {\n", spacing, param.ParamName);
-					implcommandpreparation = implcommandpreparation + fmt.Sprintf ("%s    err = errors.New (\"%s_%s: invalid character count (%s)\");\n", spacing, ClassName, method.MethodName, param.ParamName);						
-					implcommandpreparation = implcommandpreparation + fmt.Sprintf ("%s    return %s;\n", spacing, errorreturn);
-					implcommandpreparation = implcommandpreparation + fmt.Sprintf ("%s}\n", spacing);
-					implcommandpreparation = implcommandpreparation + fmt.Sprintf ("%s\n", spacing);
-					implcommandpreparation = implcommandpreparation + fmt.Sprintf ("%sbufferSize%s := neededfor%s + 1;\n", spacing, param.ParamName, param.ParamName);
-					implcommandpreparation = implcommandpreparation + fmt.Sprintf ("%sbuffer%s := make([]byte, bufferSize%s);\n", spacing, param.ParamName, param.ParamName);
-					implcommandpreparation = implcommandpreparation + fmt.Sprintf ("%s\n", spacing);
-
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", uintptr (unsafe.Pointer (&buffer%s[0])), Int64InValue (bufferSize%s), Int64OutValue (&filledin%s)", param.ParamName, param.ParamName, param.ParamName);
-
-					implcommandpost = implcommandpost + fmt.Sprintf ("%sif (filledin%s < 0) {\n", spacing, param.ParamName);
-					implcommandpost = implcommandpost + fmt.Sprintf ("%s    err = errors.New (\"%s_%s: invalid buffer size (%s)\");\n", spacing, ClassName, method.MethodName, param.ParamName);						
-					implcommandpost = implcommandpost + fmt.Sprintf ("%s    return %s;\n", spacing, errorreturn);
-					implcommandpost = implcommandpost + fmt.Sprintf ("%s}\n\n", spacing);
-					implreturnvalues = implreturnvalues + fmt.Sprintf ("string (buffer%s[:filledin%s]), ", param.ParamName, param.ParamName);
+					calls.AddStringOutput (getGoStringOutput (NameSpace), param.ParamName);
+
+					implreturnvalues = implreturnvalues + fmt.Sprintf ("C.GoString ((*C.char) (unsafe.Pointer (&buffer%s[0]))), ", param.ParamName);
 				
 					returnvalues = returnvalues + fmt.Sprintf ("string, ")
 					classreturnvariables = classreturnvariables + "s" + param.ParamName + ", ";
@@ -891,14 +1331,14 @@ func writeGoMethod (method ComponentDefinitionMethod, w io.Writer, implw io.Writ
 					classreturntypes = classreturntypes + fmt.Sprintf ("string, ");
 
 				case "enum":
-					comments = comments + fmt.Sprintf ("    * @return %s\n", param.ParamDescription);
-					returnvalues = returnvalues + fmt.Sprintf ("E%s%s, ", NameSpace, param.ParamClass)
-					impldeclarations = impldeclarations + fmt.Sprintf ("%svar e%s uint64 = 0;\n", spacing, param.ParamName);
-					implreturnvalues = implreturnvalues + fmt.Sprintf ("E%s%s (e%s), ", NameSpace, param.ParamClass, param.ParamName);
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", UInt64OutValue (&e%s)", param.ParamName);
+					comments = comments + fmt.Sprintf ("//   returns %s\n", param.ParamDescription);
+					returnvalues = returnvalues + fmt.Sprintf ("%s, ", getGoIdentifier (param.ParamClass))
+					impldeclarations = impldeclarations + fmt.Sprintf ("%svar e%s C.%s = 0;\n", spacing, param.ParamName, cParamType);
+					implreturnvalues = implreturnvalues + fmt.Sprintf ("%s (e%s), ", getGoIdentifier (param.ParamClass), param.ParamName);
+					commandparameter = fmt.Sprintf ("&e%s", param.ParamName);
 					classreturnvariables = classreturnvariables + "e" + param.ParamName + ", ";
 					classreturnstring = classreturnstring + "e" + param.ParamName + ", ";
-					classreturntypes = classreturntypes + fmt.Sprintf ("E%s%s, ", NameSpace, param.ParamClass);
+					classreturntypes = classreturntypes + fmt.Sprintf ("%s, ", getGoIdentifier (param.ParamClass));
 
 
 				case "basicarray":
@@ -907,152 +1347,438 @@ func writeGoMethod (method ComponentDefinitionMethod, w io.Writer, implw io.Writ
 						return err;
 					}
 					
-					comments = comments + fmt.Sprintf ("    * @return %s\n", param.ParamDescription);
+					comments = comments + fmt.Sprintf ("//   returns %s\n", param.ParamDescription);
 					returnvalues = returnvalues + fmt.Sprintf ("[]%s, ", basicType)
-					impldeclarations = impldeclarations + fmt.Sprintf ("%sarray%s := make ([]%s, 0);\n", spacing, param.ParamName, basicType);
+					elementType := getGoCType (cParams[2].ParamType);
+					calls.Declarations = append (calls.Declarations, fmt.Sprintf ("var neededfor%s %s = 0;", param.ParamName, strings.TrimPrefix (getGoCType (cParams[1].ParamType), "*")));
+					calls.AddBufferArgument (fmt.Sprintf ("0, &neededfor%s, nil", param.ParamName),
+						fmt.Sprintf ("%s (len (array%s)), &neededfor%s, p%s", getGoCType (cParams[0].ParamType), param.ParamName, param.ParamName, param.ParamName),
+						fmt.Sprintf ("array%s := make ([]%s, neededfor%s);", param.ParamName, basicType, param.ParamName),
+						fmt.Sprintf ("var p%s %s = nil;", param.ParamName, elementType),
+						fmt.Sprintf ("if (len (array%s) > 0) {", param.ParamName),
+						fmt.Sprintf ("    p%s = (%s) (unsafe.Pointer (&array%s[0]));", param.ParamName, elementType, param.ParamName),
+						"}");
 					implreturnvalues = implreturnvalues + fmt.Sprintf ("array%s, ", param.ParamName);
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", 0, 0, 0");
 					classreturnvariables = classreturnvariables + "array" + param.ParamName + ", ";
 					classreturnstring = classreturnstring + "array" + param.ParamName + ", ";
 					classreturntypes = classreturntypes + fmt.Sprintf ("[]%s, ", basicType);
 
 				case "structarray":					
-					comments = comments + fmt.Sprintf ("    * @return %s\n", param.ParamDescription);
-					returnvalues = returnvalues + fmt.Sprintf ("[]s%s%s, ", NameSpace, param.ParamClass)
-					impldeclarations = impldeclarations + fmt.Sprintf ("%sarray%s := make ([]s%s%s, 0);\n", spacing, param.ParamName, NameSpace, param.ParamClass);
+					comments = comments + fmt.Sprintf ("//   returns %s\n", param.ParamDescription);
+					returnvalues = returnvalues + fmt.Sprintf ("[]%s, ", getGoIdentifier (param.ParamClass))
+					calls.Declarations = append (calls.Declarations, fmt.Sprintf ("var neededfor%s %s = 0;", param.ParamName, strings.TrimPrefix (getGoCType (cParams[1].ParamType), "*")));
+					calls.AddBufferArgument (fmt.Sprintf ("0, &neededfor%s, nil", param.ParamName),
+						fmt.Sprintf ("%s (len (array%s)), &neededfor%s, getBufferPointer (buffer%s)", getGoCType (cParams[0].ParamType), param.ParamName, param.ParamName, param.ParamName),
+						fmt.Sprintf ("array%s := make ([]%s, neededfor%s);", param.ParamName, getGoIdentifier (param.ParamClass), param.ParamName),
+						fmt.Sprintf ("buffer%s := make ([]byte, binary.Size (array%s));", param.ParamName, param.ParamName));
+					implcommandpost = implcommandpost + fmt.Sprintf ("%serr = unpackStructs (buffer%s, array%s);\n", spacing, param.ParamName, param.ParamName);
+					implcommandpost = implcommandpost + fmt.Sprintf ("%sif (err != nil) {\n", spacing);
+					implcommandpost = implcommandpost + fmt.Sprintf ("%s    return %s;\n", spacing, errorreturn);
+					implcommandpost = implcommandpost + fmt.Sprintf ("%s}\n", spacing);
 					implreturnvalues = implreturnvalues + fmt.Sprintf ("array%s, ", param.ParamName);
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", 0, 0, 0");
 					classreturnvariables = classreturnvariables + "array" + param.ParamName + ", ";
 					classreturnstring = classreturnstring + "array" + param.ParamName + ", ";
-					classreturntypes = classreturntypes + fmt.Sprintf ("[]s%s%s, ", NameSpace, param.ParamClass);
-					
-				case "functiontype":
-					comments = comments + fmt.Sprintf ("    * @return %s\n", param.ParamDescription);
-					returnvalues = returnvalues + fmt.Sprintf ("uint64, ")
-					impldeclarations = impldeclarations + fmt.Sprintf ("%svar p%s uint64 = 0;\n", spacing, param.ParamName);
-					implreturnvalues = implreturnvalues + fmt.Sprintf ("p%s, ", param.ParamName);
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", UInt64OutValue (&p%s)", param.ParamName);
-					classreturnvariables = classreturnvariables + "p" + param.ParamName + ", ";
-					classreturnstring = classreturnstring + "p" + param.ParamName + ", ";
-					classreturntypes = classreturntypes + fmt.Sprintf ("uint64, ");
+					classreturntypes = classreturntypes + fmt.Sprintf ("[]%s, ", getGoIdentifier (param.ParamClass));
 
 				case "struct":
-					comments = comments + fmt.Sprintf ("    * @return %s\n", param.ParamDescription);
-					returnvalues = returnvalues + fmt.Sprintf ("s%s%s, ", NameSpace, param.ParamClass)
-					impldeclarations = impldeclarations + fmt.Sprintf ("%svar s%s s%s%s;\n", spacing, param.ParamName, NameSpace, param.ParamClass);
+					comments = comments + fmt.Sprintf ("//   returns %s\n", param.ParamDescription);
+					returnvalues = returnvalues + fmt.Sprintf ("%s, ", getGoIdentifier (param.ParamClass))
+					impldeclarations = impldeclarations + fmt.Sprintf ("%svar s%s %s;\n", spacing, param.ParamName, getGoIdentifier (param.ParamClass));
+					impldeclarations = impldeclarations + fmt.Sprintf ("%sbuffer%s := make ([]byte, binary.Size (&s%s));\n", spacing, param.ParamName, param.ParamName);
+					implcommandpost = implcommandpost + fmt.Sprintf ("%serr = unpackStructs (buffer%s, &s%s);\n", spacing, param.ParamName, param.ParamName);
+					implcommandpost = implcommandpost + fmt.Sprintf ("%sif (err != nil) {\n", spacing);
+					implcommandpost = implcommandpost + fmt.Sprintf ("%s    return %s;\n", spacing, errorreturn);
+					implcommandpost = implcommandpost + fmt.Sprintf ("%s}\n", spacing);
 					implreturnvalues = implreturnvalues + fmt.Sprintf ("s%s, ", param.ParamName);
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", 0");
+					commandparameter = fmt.Sprintf ("getBufferPointer (buffer%s)", param.ParamName);
 					classreturnvariables = classreturnvariables + "s" + param.ParamName + ", ";
 					classreturnstring = classreturnstring + "s" + param.ParamName + ", ";
-					classreturntypes = classreturntypes + fmt.Sprintf ("s%s%s, ", NameSpace, param.ParamClass);
+					classreturntypes = classreturntypes + fmt.Sprintf ("%s, ", getGoIdentifier (param.ParamClass));
 					
 				case "handle":
-					comments = comments + fmt.Sprintf("    * @return %s\n", param.ParamDescription);
-					returnvalues = returnvalues + fmt.Sprintf ("%sHandle, ", NameSpace)
+					comments = comments + fmt.Sprintf ("//   returns %s\n", param.ParamDescription);
+					returnvalues = returnvalues + fmt.Sprintf ("Handle, ")
 					impldeclarations = impldeclarations + fmt.Sprintf ("%sh%s := implementation.NewHandle();\n", spacing, param.ParamName);
+					impldeclarations = impldeclarations + fmt.Sprintf ("%svar p%s C.%s = nil;\n", spacing, param.ParamName, cParamType);
 
-					implcommandparameters = implcommandparameters + fmt.Sprintf (", h%s.GetDLLOutHandle ()", param.ParamName);
+					commandparameter = fmt.Sprintf ("&p%s", param.ParamName);
+					implcommandpost = implcommandpost + fmt.Sprintf ("%s*h%s.GetDLLOutHandle () = unsafe.Pointer (p%s);\n", spacing, param.ParamName, param.ParamName);
 					
 					implreturnvalues = implreturnvalues + fmt.Sprintf ("h%s, ", param.ParamName);
 					classreturnvariables = classreturnvariables + "h" + param.ParamName + ", ";
-					classreturnimplementation = classreturnimplementation + fmt.Sprintf ("%s    var c%s %s%s;\n", spacing, param.ParamName, NameSpace, param.ParamClass);
-					classreturnimplementation = classreturnimplementation + fmt.Sprintf ("%s    c%s.Interface = instance.Interface;\n", spacing, param.ParamName);
-					classreturnimplementation = classreturnimplementation + fmt.Sprintf ("%s    c%s.Handle = h%s;\n", spacing, param.ParamName, param.ParamName);
+					classreturnimplementation = classreturnimplementation + fmt.Sprintf ("    var c%s %s;\n", param.ParamName, getGoIdentifier (param.ParamClass));
+					classreturnimplementation = classreturnimplementation + fmt.Sprintf ("    c%s.Interface = instance.Interface;\n", param.ParamName);
+					classreturnimplementation = classreturnimplementation + fmt.Sprintf ("    c%s.Handle = h%s;\n", param.ParamName, param.ParamName);
 					
 					classreturnstring = classreturnstring + "c" + param.ParamName + ", ";
-					classreturntypes = classreturntypes + fmt.Sprintf ("%s%s, ", NameSpace, param.ParamClass);
+					classreturntypes = classreturntypes + fmt.Sprintf ("%s, ", getGoIdentifier (param.ParamClass));
 					
 
 				default:
 					return fmt.Errorf ("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName);
 			}
 			
+			if (commandparameter != "") {
+				calls.AddArgument (commandparameter);
+			}
+			
 		default:
 			return fmt.Errorf ("invalid method parameter passing \"%s\" for %s.%s (%s)", param.ParamPass, ClassName, method.MethodName, param.ParamName);
 		}
 		
 	} 
 	
-	fmt.Fprintf (w, "\n");
-	fmt.Fprintf (w, "    /**\n");
-	fmt.Fprintf (w, "    * %s\n", method.MethodDescription);
-	fmt.Fprintf (w, "    *\n");
-	fmt.Fprintf (w, "    * @param[in] %s - %s instance.\n", ClassName, ClassName);
-	fmt.Fprintf (w, comments);
-	fmt.Fprintf (w, "    */\n");
+	for _, declaration := range calls.Declarations {
+		impldeclarations = impldeclarations + spacing + declaration + "\n";
+	}
+	impldeclarations = impldeclarations + fmt.Sprintf ("%s\n", spacing);
+	impldeclarations = impldeclarations + fmt.Sprintf ("%serr = implementation.checkInitialized ();\n", spacing);
+	impldeclarations = impldeclarations + fmt.Sprintf ("%sif (err != nil) {\n", spacing);
+	impldeclarations = impldeclarations + fmt.Sprintf ("%s    return %s;\n", spacing, errorreturn);
+	impldeclarations = impldeclarations + fmt.Sprintf ("%s}\n", spacing);
 	
-	handleparameter := "";
+	if (comments != "") && (method.MethodDescription != "") {
+		comments = "//\n" + comments;
+	}
 	
-	if isGlobal {
-		fmt.Fprintf (w, "    %s (%s) (%serror)\n", method.MethodName, parameters, returnvalues);
-	} else {
-		handleparameter = fmt.Sprintf ("%s %sHandle", ClassName, NameSpace);
+	interfaceMethodName := goMethodName;
+	handleparameter := "";
+	if (!isGlobal) {
+		interfaceMethodName = goClassName + "_" + goMethodName;
+		handleparameter = fmt.Sprintf ("%s Handle", ClassName);
 		if (parameters != "") {
 			handleparameter = handleparameter + ", ";
 		}
-		
-		fmt.Fprintf (w, "    %s_%s (%s%s) (%serror)\n", ClassName, method.MethodName, handleparameter, parameters, returnvalues);
-
 	}
 	
+	fmt.Fprintf (w, "\n");
+	writeGoDocComment (w, "    ", interfaceMethodName, method.MethodDescription);
+	fmt.Fprintf (w, "%s", strings.Replace (comments, "//", "    //", -1));
+	fmt.Fprintf (w, "    %s (%s%s) (%serror)\n", interfaceMethodName, handleparameter, parameters, returnvalues);
 	
 	
-	if isGlobal {
-	
-		// Implementation
-		fmt.Fprintf (implw, "func (implementation *%sImplementation) %s (%s%s) (%serror) {\n", NameSpace, method.MethodName, handleparameter, parameters, returnvalues);
-		fmt.Fprintf (implw, impldeclarations);
-		fmt.Fprintf (implw, implcasts);
-		fmt.Fprintf (implw, "\n");
-		fmt.Fprintf (implw, implcommandpreparation);
-		
-		fmt.Fprintf (implw, "    err = implementation.CallFunction (implementation.%s_%s%s%s);\n", NameSpace, strings.ToLower (method.MethodName), method.DLLSuffix, implcommandparameters);
-		
-	} else {
-	
-		// Implementation
-		fmt.Fprintf (implw, "func (implementation *%sImplementation) %s_%s (%s%s) (%serror) {\n", NameSpace, ClassName, method.MethodName, handleparameter, parameters, returnvalues);
-		fmt.Fprintf (implw, impldeclarations);
-		fmt.Fprintf (implw, implcasts);
-		fmt.Fprintf (implw, "\n");
-		fmt.Fprintf (implw, implcommandpreparation);
-
-		fmt.Fprintf (implw, "    err = implementation.CallFunction (implementation.%s_%s_%s%s, implementation_%s.GetDLLInHandle()%s);\n", NameSpace, strings.ToLower (ClassName), strings.ToLower (method.MethodName), method.DLLSuffix, strings.ToLower (ClassName), implcommandparameters);
-	
-	}
-		
-	fmt.Fprintf (implw, "    if (err != nil) {\n");
-	fmt.Fprintf (implw, "        return %s;\n", errorreturn);
-	fmt.Fprintf (implw, "    }\n");
-	fmt.Fprintf (implw, "    \n");
+	// Implementation
+	fmt.Fprintf (implw, "func (implementation *Implementation) %s (%s%s) (%serror) {\n", interfaceMethodName, handleparameter, parameters, returnvalues);
+	fmt.Fprintf (implw, impldeclarations);
+	fmt.Fprintf (implw, implcasts);
+	fmt.Fprintf (implw, "\n");
+	implwriter := LanguageWriter {Writer: implw, IndentString: "  "};
+	calls.WriteCalls (implwriter, spacing, fmt.Sprintf ("err = %sC.%s_call (%%s));", checkErrorCall, CMethodName),
+		[]string {"if (err != nil) {", "    return " + errorreturn + ";", "}", ""});
 	fmt.Fprintf (implw, implcommandpost);
 	fmt.Fprintf (implw, "    return %serr;\n", implreturnvalues);
 	fmt.Fprintf (implw, "}\n");
 	fmt.Fprintf (implw, "\n");
 
 	
+	classcomment := "";
+	if (method.MethodDescription != "") {
+		classcomment = fmt.Sprintf ("// %s - %s\n", goMethodName, method.MethodDescription);
+	}
+	classcall := "";
 	if isGlobal {
-	
-		*classdefinitions = *classdefinitions + fmt.Sprintf ("%sfunc (instance *%s%s) %s (%s) (%serror) {\n", spacing, NameSpace, ClassName, method.MethodName, parameters, classreturntypes);
-		*classdefinitions = *classdefinitions + fmt.Sprintf ("%s    %serror := instance.Interface.%s (%s);\n", spacing, classreturnvariables, method.MethodName, callparameters);
-		*classdefinitions = *classdefinitions + fmt.Sprintf ("%s", classreturnimplementation);
-		*classdefinitions = *classdefinitions + fmt.Sprintf ("%s    return %serror;\n", spacing, classreturnstring);
-		*classdefinitions = *classdefinitions + fmt.Sprintf ("%s}\n", spacing);
-		*classdefinitions = *classdefinitions + fmt.Sprintf ("%s\n", spacing);
-		
+		classcall = fmt.Sprintf ("instance.Interface.%s (%s)", interfaceMethodName, callparameters);
 	} else {
-
 		if (callparameters != "") {
 			callparameters = ", " + callparameters;
 		}
+		classcall = fmt.Sprintf ("instance.Interface.%s (instance.Handle%s)", interfaceMethodName, callparameters);
+	}
 	
-		*classdefinitions = *classdefinitions + fmt.Sprintf ("%sfunc (instance *%s%s) %s (%s) (%serror) {\n", spacing, NameSpace, ClassName, method.MethodName, parameters, classreturntypes);
-		*classdefinitions = *classdefinitions + fmt.Sprintf ("%s    %serror := instance.Interface.%s_%s (instance.Handle%s);\n", spacing, classreturnvariables, ClassName, method.MethodName, callparameters);
-		*classdefinitions = *classdefinitions + fmt.Sprintf ("%s", classreturnimplementation);
-		*classdefinitions = *classdefinitions + fmt.Sprintf ("%s    return %serror;\n", spacing, classreturnstring);
-		*classdefinitions = *classdefinitions + fmt.Sprintf ("%s}\n", spacing);
-		*classdefinitions = *classdefinitions + fmt.Sprintf ("%s\n", spacing);
-	
+	*classdefinitions = *classdefinitions + classcomment + comments;
+	*classdefinitions = *classdefinitions + fmt.Sprintf ("func (instance *%s) %s (%s) (%serror) {\n", goClassName, goMethodName, parameters, classreturntypes);
+	*classdefinitions = *classdefinitions + fmt.Sprintf ("    %serr := %s;\n", classreturnvariables, classcall);
+	*classdefinitions = *classdefinitions + fmt.Sprintf ("%s", classreturnimplementation);
+	*classdefinitions = *classdefinitions + fmt.Sprintf ("    return %serr;\n", classreturnstring);
+	*classdefinitions = *classdefinitions + fmt.Sprintf ("}\n");
+	*classdefinitions = *classdefinitions + fmt.Sprintf ("\n");
+
+	return nil;
+}
+
+
+// getGoCType returns the cgo name of a C parameter type
+func getGoCType (cParamType string) (string) {
+	baseType := strings.TrimSpace (strings.TrimPrefix (strings.TrimSpace (cParamType), "const "));
+	pointers := "";
+	for strings.HasSuffix (baseType, "*") {
+		baseType = strings.TrimSpace (strings.TrimSuffix (baseType, "*"));
+		pointers = pointers + "*";
+	}
+	return pointers + "C." + baseType;
+}
+
+
+// getGoCallbackParamType returns the Go type and the name prefix of a parameter of a function type
+func getGoCallbackParamType (functiontype ComponentDefinitionFunctionType, param ComponentDefinitionParam) (string, string, error) {
+	switch (param.ParamType) {
+		case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64":
+			return param.ParamType, "n", nil;
+		case "single":
+			return "float32", "f", nil;
+		case "double":
+			return "float64", "d", nil;
+		case "bool":
+			return "bool", "b", nil;
+		case "enum":
+			return getGoIdentifier (param.ParamClass), "e", nil;
+		case "string":
+			if (param.ParamPass == "in") {
+				return "string", "s", nil;
+			}
+	}
+
+	return "", "", fmt.Errorf ("parameter type \"%s\" of %s (%s) is not supported by Go callbacks", param.ParamType, functiontype.FunctionName, param.ParamName);
+}
+
+
+// getGoFunctionTypeSignature returns the parameters, the return values and the parameter comments of the Go func type of a function type
+func getGoFunctionTypeSignature (functiontype ComponentDefinitionFunctionType) (string, string, string, error) {
+	parameters := "";
+	returnvalues := "";
+	comments := "";
+
+	for _, param := range functiontype.Params {
+		goType, prefix, err := getGoCallbackParamType (functiontype, param);
+		if (err != nil) {
+			return "", "", "", err;
+		}
+
+		switch (param.ParamPass) {
+			case "in":
+				if (parameters != "") {
+					parameters = parameters + ", ";
+				}
+				parameters = parameters + prefix + param.ParamName + " " + goType;
+				comments = comments + fmt.Sprintf ("//   %s%s - %s\n", prefix, param.ParamName, param.ParamDescription);
+
+			case "out", "return":
+				if (returnvalues != "") {
+					returnvalues = returnvalues + ", ";
+				}
+				returnvalues = returnvalues + goType;
+				comments = comments + fmt.Sprintf ("//   returns %s\n", param.ParamDescription);
+
+			default:
+				return "", "", "", fmt.Errorf ("invalid parameter passing \"%s\" of %s (%s)", param.ParamPass, functiontype.FunctionName, param.ParamName);
+		}
+	}
+
+	return parameters, returnvalues, comments, nil;
+}
+
+
+// getGoCallbackCParameters returns the C parameters of a function type as they are declared in the C header
+func getGoCallbackCParameters (functiontype ComponentDefinitionFunctionType, NameSpace string) ([]CParameter, error) {
+	cParams := make ([]CParameter, 0);
+
+	for _, param := range functiontype.Params {
+		_, prefix, err := getGoCallbackParamType (functiontype, param);
+		if (err != nil) {
+			return nil, err;
+		}
+		cParamTypeName, err := getCParameterTypeName (param.ParamType, NameSpace, param.ParamClass);
+		if (err != nil) {
+			return nil, err;
+		}
+
+		if (param.ParamPass == "in") {
+			if (param.ParamType == "string") {
+				prefix = "p";
+			}
+			cParams = append (cParams, CParameter{ ParamType: cParamTypeName, ParamName: prefix + param.ParamName });
+		} else {
+			cParams = append (cParams, CParameter{ ParamType: cParamTypeName + " *", ParamName: "p" + param.ParamName });
+		}
+	}
+
+	return cParams, nil;
+}
+
+
+// buildGoCallbacks writes the exported Go functions that the C function pointers of the function types call.
+// cgo does not allow definitions in the preamble of a file with exports, so they live apart from the implementation file.
+func buildGoCallbacks (component ComponentDefinition, w io.Writer, NameSpace string) (error) {
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "package %s\n", getGoPackageName (component));
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "/*\n");
+	fmt.Fprintf (w, "#include <stdint.h>\n");
+	fmt.Fprintf (w, "#include <stdbool.h>\n");
+	fmt.Fprintf (w, "\n");
+	err := writeGoCTypes (component, w, NameSpace, "void *");
+	if (err != nil) {
+		return err;
+	}
+	fmt.Fprintf (w, "*/\n");
+	fmt.Fprintf (w, "import \"C\"\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "import (\n");
+	fmt.Fprintf (w, "    \"sync\"\n");
+	fmt.Fprintf (w, ")\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// callbackSlots is the number of distinct Go functions of a function type that can be in use at the same time.\n");
+	fmt.Fprintf (w, "const callbackSlots = %d;\n", goCallbackSlots);
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// callbackTable maps the C function pointers of a function type to the Go functions that they call.\n");
+	fmt.Fprintf (w, "// A C function pointer carries no context, so each distinct Go function occupies one of the slots\n");
+	fmt.Fprintf (w, "// while the library may call it. Calls that pass the same function share its slot.\n");
+	fmt.Fprintf (w, "type callbackTable struct {\n");
+	fmt.Fprintf (w, "    mutex sync.Mutex\n");
+	fmt.Fprintf (w, "    identities [callbackSlots]uintptr\n");
+	fmt.Fprintf (w, "    functions [callbackSlots]interface{}\n");
+	fmt.Fprintf (w, "    references [callbackSlots]int\n");
+	fmt.Fprintf (w, "}\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "func (table *callbackTable) register (identity uintptr, function interface{}) (int, bool) {\n");
+	fmt.Fprintf (w, "    table.mutex.Lock ();\n");
+	fmt.Fprintf (w, "    defer table.mutex.Unlock ();\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "    free := -1;\n");
+	fmt.Fprintf (w, "    for slot := 0; slot < callbackSlots; slot++ {\n");
+	fmt.Fprintf (w, "        if (table.references[slot] == 0) {\n");
+	fmt.Fprintf (w, "            if (free < 0) {\n");
+	fmt.Fprintf (w, "                free = slot;\n");
+	fmt.Fprintf (w, "            }\n");
+	fmt.Fprintf (w, "        } else if (table.identities[slot] == identity) {\n");
+	fmt.Fprintf (w, "            table.references[slot]++;\n");
+	fmt.Fprintf (w, "            return slot, true;\n");
+	fmt.Fprintf (w, "        }\n");
+	fmt.Fprintf (w, "    }\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "    if (free < 0) {\n");
+	fmt.Fprintf (w, "        return 0, false;\n");
+	fmt.Fprintf (w, "    }\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "    table.identities[free] = identity;\n");
+	fmt.Fprintf (w, "    table.functions[free] = function;\n");
+	fmt.Fprintf (w, "    table.references[free] = 1;\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "    return free, true;\n");
+	fmt.Fprintf (w, "}\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// release frees the slot when its last reference is released.\n");
+	fmt.Fprintf (w, "func (table *callbackTable) release (slot int) {\n");
+	fmt.Fprintf (w, "    table.mutex.Lock ();\n");
+	fmt.Fprintf (w, "    defer table.mutex.Unlock ();\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "    if (slot < 0) || (slot >= callbackSlots) || (table.references[slot] == 0) {\n");
+	fmt.Fprintf (w, "        return;\n");
+	fmt.Fprintf (w, "    }\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "    table.references[slot]--;\n");
+	fmt.Fprintf (w, "    if (table.references[slot] == 0) {\n");
+	fmt.Fprintf (w, "        table.identities[slot] = 0;\n");
+	fmt.Fprintf (w, "        table.functions[slot] = nil;\n");
+	fmt.Fprintf (w, "    }\n");
+	fmt.Fprintf (w, "}\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "func (table *callbackTable) lookup (slot int) (interface{}) {\n");
+	fmt.Fprintf (w, "    table.mutex.Lock ();\n");
+	fmt.Fprintf (w, "    defer table.mutex.Unlock ();\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "    if (slot < 0) || (slot >= callbackSlots) {\n");
+	fmt.Fprintf (w, "        return nil;\n");
+	fmt.Fprintf (w, "    }\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "    return table.functions[slot];\n");
+	fmt.Fprintf (w, "}\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// callbackReferences holds the slots of the callbacks, that were last passed to the callback parameters\n");
+	fmt.Fprintf (w, "// of an instance or of the global functions, as the library may store and call them later.\n");
+	fmt.Fprintf (w, "type callbackReferences struct {\n");
+	fmt.Fprintf (w, "    mutex sync.Mutex\n");
+	fmt.Fprintf (w, "    releases map[string]func ()\n");
+	fmt.Fprintf (w, "}\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// keep holds the slot of a callback in place of the previous callback of the parameter.\n");
+	fmt.Fprintf (w, "// If the call failed, the library still uses the previous callback, so the new one is released instead.\n");
+	fmt.Fprintf (w, "func (references *callbackReferences) keep (parameter string, release func (), failed bool) {\n");
+	fmt.Fprintf (w, "    if (failed) {\n");
+	fmt.Fprintf (w, "        release ();\n");
+	fmt.Fprintf (w, "        return;\n");
+	fmt.Fprintf (w, "    }\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "    references.mutex.Lock ();\n");
+	fmt.Fprintf (w, "    if (references.releases == nil) {\n");
+	fmt.Fprintf (w, "        references.releases = make (map[string]func ());\n");
+	fmt.Fprintf (w, "    }\n");
+	fmt.Fprintf (w, "    previous := references.releases[parameter];\n");
+	fmt.Fprintf (w, "    references.releases[parameter] = release;\n");
+	fmt.Fprintf (w, "    references.mutex.Unlock ();\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "    if (previous != nil) {\n");
+	fmt.Fprintf (w, "        previous ();\n");
+	fmt.Fprintf (w, "    }\n");
+	fmt.Fprintf (w, "}\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// releaseAll releases the slots, when the instance is released.\n");
+	fmt.Fprintf (w, "func (references *callbackReferences) releaseAll () {\n");
+	fmt.Fprintf (w, "    references.mutex.Lock ();\n");
+	fmt.Fprintf (w, "    releases := references.releases;\n");
+	fmt.Fprintf (w, "    references.releases = nil;\n");
+	fmt.Fprintf (w, "    references.mutex.Unlock ();\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "    for _, release := range releases {\n");
+	fmt.Fprintf (w, "        release ();\n");
+	fmt.Fprintf (w, "    }\n");
+	fmt.Fprintf (w, "}\n");
+
+	for _, functiontype := range component.Functions {
+		functionName := getGoIdentifier (functiontype.FunctionName);
+		cParams, err := getGoCallbackCParameters (functiontype, NameSpace);
+		if (err != nil) {
+			return err;
+		}
+
+		exportparameters := "nSlot C.int";
+		callparameters := "";
+		resultvariables := "";
+		resultassignments := "";
+		for k, param := range functiontype.Params {
+			goType, prefix, err := getGoCallbackParamType (functiontype, param);
+			if (err != nil) {
+				return err;
+			}
+			exportparameters = exportparameters + fmt.Sprintf (", %s %s", cParams[k].ParamName, getGoCType (cParams[k].ParamType));
+
+			if (param.ParamPass == "in") {
+				if (callparameters != "") {
+					callparameters = callparameters + ", ";
+				}
+				if (param.ParamType == "string") {
+					callparameters = callparameters + fmt.Sprintf ("C.GoString (%s)", cParams[k].ParamName);
+				} else {
+					callparameters = callparameters + fmt.Sprintf ("%s (%s)", goType, cParams[k].ParamName);
+				}
+			} else {
+				if (resultvariables != "") {
+					resultvariables = resultvariables + ", ";
+				}
+				resultvariables = resultvariables + prefix + param.ParamName;
+				resultassignments = resultassignments + fmt.Sprintf ("    if (%s != nil) {\n", cParams[k].ParamName);
+				resultassignments = resultassignments + fmt.Sprintf ("        *%s = %s (%s%s);\n", cParams[k].ParamName, strings.TrimPrefix (getGoCType (cParams[k].ParamType), "*"), prefix, param.ParamName);
+				resultassignments = resultassignments + fmt.Sprintf ("    }\n");
+			}
+		}
+
+		fmt.Fprintf (w, "\n");
+		fmt.Fprintf (w, "var callbackTable%s callbackTable;\n", functionName);
+		fmt.Fprintf (w, "\n");
+		fmt.Fprintf (w, "//export dispatch%s%s\n", NameSpace, functiontype.FunctionName);
+		fmt.Fprintf (w, "func dispatch%s%s (%s) {\n", NameSpace, functiontype.FunctionName, exportparameters);
+		fmt.Fprintf (w, "    callback, ok := callbackTable%s.lookup (int (nSlot)).(%s);\n", functionName, functionName);
+		fmt.Fprintf (w, "    if (!ok) {\n");
+		fmt.Fprintf (w, "        return;\n");
+		fmt.Fprintf (w, "    }\n");
+		fmt.Fprintf (w, "\n");
+		if (resultvariables != "") {
+			fmt.Fprintf (w, "    %s := callback (%s);\n", resultvariables, callparameters);
+			fmt.Fprintf (w, "%s", resultassignments);
+		} else {
+			fmt.Fprintf (w, "    callback (%s);\n", callparameters);
+		}
+		fmt.Fprintf (w, "}\n");
 	}
 
 	return nil;
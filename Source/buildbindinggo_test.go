@@ -0,0 +1,238 @@
+/*++
+
+Copyright (C) 2018 Autodesk Inc. (Original Author)
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+--*/
+
+
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+// buildbindinggo_test.go
+// Tests of the Go binding generator
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const goCallbackTestComponent = `<?xml version="1.0" encoding="UTF-8"?>
+<component xmlns="http://schemas.autodesk.com/netfabb/automaticcomponenttoolkit/2018" libraryname="Callback Library" namespace="Cb" copyright="Example" year="2024" basename="cb" version="1.0.0">
+	<license>
+		<line value="All rights reserved." />
+	</license>
+	<bindings>
+		<binding language="Go" indentation="4spaces" importpath="example.com/cb" />
+	</bindings>
+	<errors>
+		<error name="NOTIMPLEMENTED" code="1" description="functionality not implemented" />
+		<error name="INVALIDPARAM" code="2" description="an invalid parameter was passed" />
+		<error name="INVALIDCAST" code="3" description="a type cast failed" />
+		<error name="BUFFERTOOSMALL" code="4" description="a provided buffer is too small" />
+		<error name="GENERICEXCEPTION" code="5" description="a generic exception occurred" />
+		<error name="COULDNOTLOADLIBRARY" code="6" description="the library could not be loaded" />
+		<error name="COULDNOTFINDLIBRARYEXPORT" code="7" description="a required exported symbol could not be found in the library" />
+	</errors>
+	<functiontype name="Tick" description="Reports a tick">
+		<param name="Value" type="uint32" pass="in" description="The value" />
+	</functiontype>
+	<class name="Base" description="The base class">
+	</class>
+	<class name="Worker" parent="Base" description="A worker">
+		<method name="SetTick" description="Stores the tick callback">
+			<param name="Tick" type="functiontype" class="Tick" pass="in" description="The callback" />
+		</method>
+		<method name="Fire" description="Calls the stored callback">
+			<param name="Count" type="uint32" pass="in" description="The number of calls" />
+		</method>
+	</class>
+	<global baseclassname="Base" releasemethod="ReleaseInstance" versionmethod="GetVersion">
+		<method name="ReleaseInstance" description="Releases an instance">
+			<param name="Instance" type="handle" class="Base" pass="in" description="The instance" />
+		</method>
+		<method name="GetVersion" description="Returns the version">
+			<param name="Major" type="uint32" pass="out" description="The major version" />
+			<param name="Minor" type="uint32" pass="out" description="The minor version" />
+			<param name="Micro" type="uint32" pass="out" description="The micro version" />
+		</method>
+		<method name="CreateWorker" description="Creates a worker">
+			<param name="Instance" type="handle" class="Worker" pass="return" description="The worker" />
+		</method>
+	</global>
+</component>
+`
+
+// goCallbackTestLibrary stores the callback of a worker in SetTick and calls it in Fire
+const goCallbackTestLibrary = `#include <stdint.h>
+#include <stdlib.h>
+
+typedef void (*CbTick) (uint32_t);
+typedef struct { CbTick tick; } Worker;
+
+int32_t cb_worker_settick (void * pWorker, CbTick pTick) { ((Worker *) pWorker)->tick = pTick; return 0; }
+int32_t cb_worker_fire (void * pWorker, uint32_t nCount)
+{
+	Worker * worker = (Worker *) pWorker;
+	for (uint32_t i = 0; i < nCount; i++)
+		if (worker->tick) worker->tick (i);
+	return 0;
+}
+int32_t cb_releaseinstance (void * pInstance) { free (pInstance); return 0; }
+int32_t cb_getversion (uint32_t * a, uint32_t * b, uint32_t * c) { *a = 1; *b = 0; *c = 0; return 0; }
+int32_t cb_createworker (void ** pInstance) { *pInstance = calloc (1, sizeof (Worker)); return 0; }
+`
+
+const goCallbackTestProgram = `package main
+
+import (
+	"fmt"
+	"os"
+
+	"example.com/cb"
+)
+
+func main () {
+	wrapper, err := cb.LoadWrapper (os.Args[1]);
+	if (err != nil) {
+		panic (err);
+	}
+	first, _ := wrapper.CreateWorker ();
+	second, _ := wrapper.CreateWorker ();
+	firstCalls, secondCalls := 0, 0;
+	if err := first.SetTick (func (value uint32) { firstCalls++; }); (err != nil) {
+		panic (err);
+	}
+	if err := second.SetTick (func (value uint32) { secondCalls++; }); (err != nil) {
+		panic (err);
+	}
+	// More distinct funcs than slots must not take over the slots of the stored callbacks
+	for i := 0; i < 40; i++ {
+		other, _ := wrapper.CreateWorker ();
+		if err := other.SetTick (func (value uint32) {}); (err != nil) {
+			panic (err);
+		}
+		other.Close ();
+	}
+	first.Fire (10);
+	second.Fire (3);
+	fmt.Println (firstCalls, secondCalls);
+	first.Close ();
+	second.Close ();
+}
+`
+
+func buildGoCallbackTestBinding(t *testing.T) (string) {
+	component, err := UnmarshalComponentDefinition([]byte(goCallbackTestComponent), eComponentDefinitionFormatXML)
+	if (err != nil) {
+		t.Fatal(err)
+	}
+	err = CheckComponentDefinition(component)
+	if (err != nil) {
+		t.Fatal(err)
+	}
+	outputFolder := filepath.Join(t.TempDir(), "binding")
+	err = os.Mkdir(outputFolder, os.ModePerm)
+	if (err != nil) {
+		t.Fatal(err)
+	}
+	err = BuildBindingGo(component, outputFolder, "example.com/cb")
+	if (err != nil) {
+		t.Fatal(err)
+	}
+	return outputFolder
+}
+
+func TestGoBindingKeepsCallbackSlots(t *testing.T) {
+	outputFolder := buildGoCallbackTestBinding(t)
+	data, err := ioutil.ReadFile(filepath.Join(outputFolder, "cb_impl.go"))
+	if (err != nil) {
+		t.Fatal(err)
+	}
+	implementation := string(data)
+	for _, expected := range []string{
+		"implementation_worker.getCallbacks ().keep (\"SetTick.Tick\", func () { callbackTableTick.release (pTickSlot); }, err != nil);",
+		"defer handle.callbacks.releaseAll ();",
+		"implementation_instance.getCallbacks ().releaseAll ();",
+	} {
+		if !strings.Contains(implementation, expected) {
+			t.Errorf("cb_impl.go does not contain %q", expected)
+		}
+	}
+	if strings.Contains(implementation, "defer callbackTableTick.release") {
+		t.Error("cb_impl.go releases the callback slot when the call returns")
+	}
+}
+
+func TestGoBindingCallsStoredCallbacks(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if (err != nil) {
+		t.Skip("go is not available")
+	}
+	compiler, err := exec.LookPath("gcc")
+	if (err != nil) {
+		t.Skip("gcc is not available")
+	}
+
+	outputFolder := buildGoCallbackTestBinding(t)
+	testFolder := filepath.Dir(outputFolder)
+	files := map[string]string{
+		"library.c": goCallbackTestLibrary,
+		filepath.Join("program", "main.go"): goCallbackTestProgram,
+		filepath.Join("program", "go.mod"): "module program\n\ngo 1.13\n\nrequire example.com/cb v0.0.0\n\nreplace example.com/cb => ../binding\n",
+	}
+	err = os.Mkdir(filepath.Join(testFolder, "program"), os.ModePerm)
+	if (err != nil) {
+		t.Fatal(err)
+	}
+	for fileName, content := range files {
+		err = ioutil.WriteFile(filepath.Join(testFolder, fileName), []byte(content), 0644)
+		if (err != nil) {
+			t.Fatal(err)
+		}
+	}
+
+	libraryName := filepath.Join(testFolder, "libcb.so")
+	output, err := exec.Command(compiler, "-shared", "-fPIC", "-o", libraryName, filepath.Join(testFolder, "library.c")).CombinedOutput()
+	if (err != nil) {
+		t.Skipf("the test library cannot be built: %v\n%s", err, output)
+	}
+
+	command := exec.Command(goTool, "run", ".", libraryName)
+	command.Dir = filepath.Join(testFolder, "program")
+	command.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "CGO_ENABLED=1")
+	output, err = command.CombinedOutput()
+	if (err != nil) {
+		t.Fatalf("%v\n%s", err, output)
+	}
+	if (strings.TrimSpace(string(output)) != "10 3") {
+		t.Errorf("the stored callbacks were called %s times, expected 10 3", strings.TrimSpace(string(output)))
+	}
+}
@@ -0,0 +1,1054 @@
+/*++
+
+Copyright (C) 2018 Autodesk Inc. (Original Author)
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+--*/
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+// buildbindingjava.go
+// functions to generate a Java-binding of a library's API, that calls the library with JNA.
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+)
+
+// BuildBindingJava builds a Java-binding of a library's API, that calls the library with JNA
+func BuildBindingJava(component ComponentDefinition, outputFolder string, indentString string) error {
+	NameSpace := component.NameSpace;
+	libraryname := component.LibraryName;
+	packageName := getJavaPackageName (component);
+
+	PomName := path.Join(outputFolder, "pom.xml");
+	log.Printf ("Creating \"%s\"", PomName);
+	pomfile, err := os.Create(PomName);
+	if (err != nil) {
+		return err;
+	}
+	defer pomfile.Close();
+
+	fmt.Fprintf (pomfile, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n");
+	fmt.Fprintf (pomfile, "<project xmlns=\"http://maven.apache.org/POM/4.0.0\" xmlns:xsi=\"http://www.w3.org/2001/XMLSchema-instance\"\n");
+	fmt.Fprintf (pomfile, "  xsi:schemaLocation=\"http://maven.apache.org/POM/4.0.0 http://maven.apache.org/xsd/maven-4.0.0.xsd\">\n");
+	fmt.Fprintf (pomfile, "  <modelVersion>4.0.0</modelVersion>\n");
+	fmt.Fprintf (pomfile, "\n");
+	fmt.Fprintf (pomfile, "  <groupId>%s</groupId>\n", packageName);
+	fmt.Fprintf (pomfile, "  <artifactId>%s</artifactId>\n", strings.ToLower (component.BaseName));
+	fmt.Fprintf (pomfile, "  <version>%s</version>\n", component.Version);
+	fmt.Fprintf (pomfile, "  <name>%s</name>\n", libraryname);
+	fmt.Fprintf (pomfile, "  <description>Java binding of %s</description>\n", libraryname);
+	fmt.Fprintf (pomfile, "\n");
+	fmt.Fprintf (pomfile, "  <properties>\n");
+	fmt.Fprintf (pomfile, "    <maven.compiler.source>1.8</maven.compiler.source>\n");
+	fmt.Fprintf (pomfile, "    <maven.compiler.target>1.8</maven.compiler.target>\n");
+	fmt.Fprintf (pomfile, "    <project.build.sourceEncoding>UTF-8</project.build.sourceEncoding>\n");
+	fmt.Fprintf (pomfile, "  </properties>\n");
+	fmt.Fprintf (pomfile, "\n");
+	fmt.Fprintf (pomfile, "  <dependencies>\n");
+	fmt.Fprintf (pomfile, "    <dependency>\n");
+	fmt.Fprintf (pomfile, "      <groupId>net.java.dev.jna</groupId>\n");
+	fmt.Fprintf (pomfile, "      <artifactId>jna</artifactId>\n");
+	fmt.Fprintf (pomfile, "      <version>5.14.0</version>\n");
+	fmt.Fprintf (pomfile, "    </dependency>\n");
+	fmt.Fprintf (pomfile, "  </dependencies>\n");
+	fmt.Fprintf (pomfile, "</project>\n");
+
+	outputFolderSources := path.Join(outputFolder, "src", "main", "java", packageName);
+	err = os.MkdirAll(outputFolderSources, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	JavaFileName := path.Join(outputFolderSources, NameSpace + ".java");
+	log.Printf ("Creating \"%s\"", JavaFileName);
+	javafile, err := CreateLanguageFile (JavaFileName, indentString);
+	if (err != nil) {
+		return err;
+	}
+
+	err = javafile.WriteCLicenseHeader(component,
+		fmt.Sprintf ("This is an autogenerated Java file in order to allow an easy\n use of %s", libraryname),
+		true)
+	if err != nil {
+		return err
+	}
+
+	return buildJavaBinding (component, javafile);
+}
+
+// validateBindingJava checks that the type and method names of the Java-binding are unique
+func validateBindingJava (component ComponentDefinition, options GeneratorOptions) (error) {
+	NameSpace := component.NameSpace;
+	identifiers := make (map[string]string);
+	for _, identifier := range []string {NameSpace, "Wrapper", "NativeFunctions", "BaseClass", NameSpace + "Exception"} {
+		identifiers[identifier] = "the Java binding";
+	}
+
+	add := func (identifier string, source string) (error) {
+		previous, ok := identifiers[identifier];
+		if (ok) {
+			return fmt.Errorf ("the Java type \"%s\" of %s collides with %s", identifier, source, previous);
+		}
+		identifiers[identifier] = source;
+		return nil;
+	}
+
+	for _, errorcode := range component.Errors.Errors {
+		err := add (errorcode.Name + "Exception", "error " + errorcode.Name);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, enum := range component.Enums {
+		err := add (enum.Name, "enum " + enum.Name);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, structinfo := range component.Structs {
+		err := add (structinfo.Name, "struct " + structinfo.Name);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, functiontype := range component.Functions {
+		err := add (functiontype.FunctionName, "functiontype " + functiontype.FunctionName);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, class := range component.Classes {
+		err := add (class.ClassName, "class " + class.ClassName);
+		if (err != nil) {
+			return err;
+		}
+	}
+
+	// The methods must not collide with the final methods of Object and the methods of the binding
+	checkMethods := func (methods []ComponentDefinitionMethod, ClassName string, reserved []string) (error) {
+		for _, method := range methods {
+			for _, name := range reserved {
+				if (getJavaMethodName (method.MethodName) == name) {
+					return fmt.Errorf ("the Java method %s.%s collides with the Java binding", ClassName, method.MethodName);
+				}
+			}
+		}
+		return nil;
+	}
+	objectMethods := []string {"getClass", "hashCode", "equals", "toString", "notify", "notifyAll", "wait", "finalize"};
+	for _, class := range component.Classes {
+		err := checkMethods (class.Methods, class.ClassName, append ([]string {"getHandle", "close"}, objectMethods...));
+		if (err != nil) {
+			return err;
+		}
+
+		// Java overrides methods of the parent classes with the same parameters, which is only possible for the same result type
+		for _, method := range class.Methods {
+			parentMethod, parentClass, found := findJavaParentMethod (component, class, method);
+			if (found && (getJavaResultType (class.ClassName, method) != getJavaResultType (parentClass, parentMethod))) {
+				return fmt.Errorf ("the Java method %s.%s overrides %s.%s with a different result", class.ClassName, method.MethodName, parentClass, parentMethod.MethodName);
+			}
+		}
+	}
+	return checkMethods (component.Global.Methods, "Wrapper", objectMethods);
+}
+
+// getJavaPackageName returns the package of the Java-binding, i.e. the lowercase namespace of the component
+func getJavaPackageName (component ComponentDefinition) (string) {
+	return strings.ToLower (component.NameSpace);
+}
+
+// javaKeywords are the keywords of Java, that can not be used as identifiers
+var javaKeywords = map[string]bool {
+	"abstract": true, "assert": true, "boolean": true, "break": true, "byte": true, "case": true, "catch": true,
+	"char": true, "class": true, "const": true, "continue": true, "default": true, "do": true, "double": true,
+	"else": true, "enum": true, "extends": true, "final": true, "finally": true, "float": true, "for": true,
+	"goto": true, "if": true, "implements": true, "import": true, "instanceof": true, "int": true, "interface": true,
+	"long": true, "native": true, "new": true, "package": true, "private": true, "protected": true, "public": true,
+	"return": true, "short": true, "static": true, "strictfp": true, "super": true, "switch": true,
+	"synchronized": true, "this": true, "throw": true, "throws": true, "transient": true, "try": true, "void": true,
+	"volatile": true, "while": true, "true": true, "false": true, "null": true, "var": true, "record": true,
+	"yield": true,
+}
+
+// getJavaMethodName returns the lower camel case name of a method or parameter, e.g. "getValue" for "GetValue"
+func getJavaMethodName (name string) (string) {
+	javaName := strings.ToLower (name[0:1]) + name[1:];
+	if (javaKeywords[javaName]) {
+		return javaName + "_";
+	}
+	return javaName;
+}
+
+// getJavaBasicType returns the Java type of a scalar type of the IDL. Unsigned types are stored in the signed type of the same size.
+func getJavaBasicType (paramType string) (string, error) {
+	switch (paramType) {
+		case "uint8", "int8":
+			return "byte", nil;
+		case "uint16", "int16":
+			return "short", nil;
+		case "uint32", "int32":
+			return "int", nil;
+		case "uint64", "int64":
+			return "long", nil;
+		case "bool":
+			return "boolean", nil;
+		case "single":
+			return "float", nil;
+		case "double":
+			return "double", nil;
+	}
+	return "", fmt.Errorf ("invalid basic type \"%s\"", paramType);
+}
+
+// getJavaReferenceType returns the JNA type, that points to an output of a scalar type
+func getJavaReferenceType (paramType string) (string) {
+	switch (paramType) {
+		case "uint8", "int8", "bool":
+			return "ByteByReference";
+		case "uint16", "int16":
+			return "ShortByReference";
+		case "uint32", "int32", "enum":
+			return "IntByReference";
+		case "uint64", "int64":
+			return "LongByReference";
+		case "single":
+			return "FloatByReference";
+		case "double":
+			return "DoubleByReference";
+	}
+	return "PointerByReference";
+}
+
+// getJavaParamType returns the Java type of a parameter in the public methods
+func getJavaParamType (param ComponentDefinitionParam) (string, error) {
+	switch (param.ParamType) {
+		case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "bool", "single", "double":
+			return getJavaBasicType (param.ParamType);
+		case "string":
+			return "String", nil;
+		case "enum", "struct", "handle", "functiontype":
+			return param.ParamClass, nil;
+		case "basicarray":
+			basicType, err := getJavaBasicType (param.ParamClass);
+			if (err != nil) {
+				return "", err;
+			}
+			return basicType + "[]", nil;
+		case "structarray":
+			return param.ParamClass + "[]", nil;
+	}
+	return "", fmt.Errorf ("invalid parameter type \"%s\"", param.ParamType);
+}
+
+// getJavaResultType returns the result type of a method: void, the type of its only output or a class of all outputs
+func getJavaResultType (ClassName string, method ComponentDefinitionMethod) (string) {
+	outputs := []ComponentDefinitionParam {};
+	for _, param := range method.Params {
+		if (param.ParamPass != "in") {
+			outputs = append (outputs, param);
+		}
+	}
+	if (len (outputs) == 0) {
+		return "void";
+	}
+	if (len (outputs) == 1) {
+		resultType, err := getJavaParamType (outputs[0]);
+		if (err != nil) {
+			return "";
+		}
+		return resultType;
+	}
+	return ClassName + "." + method.MethodName + "Result";
+}
+
+// getJavaInputTypes returns the parameter types of the public method, which Java uses to find overridden methods
+func getJavaInputTypes (method ComponentDefinitionMethod) (string) {
+	types := []string {};
+	for _, param := range method.Params {
+		if (param.ParamPass == "in") {
+			paramType, _ := getJavaParamType (param);
+			types = append (types, paramType);
+		}
+	}
+	return strings.Join (types, ",");
+}
+
+// findJavaParentMethod finds the method of a parent class, that a method of a class overrides in Java
+func findJavaParentMethod (component ComponentDefinition, class ComponentDefinitionClass, method ComponentDefinitionMethod) (ComponentDefinitionMethod, string, bool) {
+	parentName := class.ParentClass;
+	for (parentName != "") {
+		found := false;
+		for _, parent := range component.Classes {
+			if (parent.ClassName != parentName) {
+				continue;
+			}
+			for _, parentMethod := range parent.Methods {
+				if ((getJavaMethodName (parentMethod.MethodName) == getJavaMethodName (method.MethodName)) && (getJavaInputTypes (parentMethod) == getJavaInputTypes (method))) {
+					return parentMethod, parent.ClassName, true;
+				}
+			}
+			parentName = parent.ParentClass;
+			found = true;
+			break;
+		}
+		if (!found) {
+			break;
+		}
+	}
+	return ComponentDefinitionMethod {}, "", false;
+}
+
+// writeJavaComment writes a Javadoc comment
+func writeJavaComment (w LanguageWriter, indent string, description string, params [][2]string, returns string) {
+	w.Writeln (indent + "/**");
+	w.Writeln (indent + " * %s", description);
+	if ((len (params) > 0) || (returns != "")) {
+		w.Writeln (indent + " *");
+	}
+	for _, param := range params {
+		w.Writeln (indent + " * @param %s %s", param[0], param[1]);
+	}
+	if (returns != "") {
+		w.Writeln (indent + " * @return %s", returns);
+	}
+	w.Writeln (indent + " */");
+}
+
+func buildJavaBinding (component ComponentDefinition, w LanguageWriter) (error) {
+	NameSpace := component.NameSpace;
+
+	w.Writeln ("");
+	w.Writeln ("package %s;", getJavaPackageName (component));
+	w.Writeln ("");
+	w.Writeln ("import com.sun.jna.Callback;");
+	w.Writeln ("import com.sun.jna.Library;");
+	w.Writeln ("import com.sun.jna.Memory;");
+	w.Writeln ("import com.sun.jna.Native;");
+	w.Writeln ("import com.sun.jna.Pointer;");
+	w.Writeln ("import com.sun.jna.Structure;");
+	w.Writeln ("import com.sun.jna.ptr.ByteByReference;");
+	w.Writeln ("import com.sun.jna.ptr.DoubleByReference;");
+	w.Writeln ("import com.sun.jna.ptr.FloatByReference;");
+	w.Writeln ("import com.sun.jna.ptr.IntByReference;");
+	w.Writeln ("import com.sun.jna.ptr.LongByReference;");
+	w.Writeln ("import com.sun.jna.ptr.PointerByReference;");
+	w.Writeln ("import com.sun.jna.ptr.ShortByReference;");
+	w.Writeln ("import java.nio.charset.StandardCharsets;");
+	w.Writeln ("import java.util.Arrays;");
+	w.Writeln ("");
+	w.Writeln ("/**");
+	w.Writeln (" * Java binding of %s.", component.LibraryName);
+	w.Writeln (" * Load the library with {@link Wrapper#Wrapper(String)} and create its classes with the methods of the wrapper.");
+	w.Writeln (" * Unsigned integers are stored in the signed Java types of the same size.");
+	w.Writeln (" */");
+	w.Writeln ("public final class %s {", NameSpace);
+	w.Writeln ("");
+	w.Writeln ("  private %s () {", NameSpace);
+	w.Writeln ("  }");
+
+	err := writeJavaTypes (component, w);
+	if (err != nil) {
+		return err;
+	}
+	err = writeJavaExceptions (component, w);
+	if (err != nil) {
+		return err;
+	}
+	err = writeJavaNativeFunctions (component, w);
+	if (err != nil) {
+		return err;
+	}
+
+	releaseExport := GetCExportName (NameSpace, "", ComponentDefinitionMethod {MethodName: component.Global.ReleaseMethod}, true);
+
+	w.Writeln ("");
+	w.Writeln ("  /**");
+	w.Writeln ("   * BaseClass is the base of all classes of %s and releases their instances.", component.LibraryName);
+	w.Writeln ("   */");
+	w.Writeln ("  public static abstract class BaseClass implements AutoCloseable {");
+	w.Writeln ("    protected final Wrapper wrapper;");
+	w.Writeln ("    private Pointer handle;");
+	w.Writeln ("");
+	w.Writeln ("    protected BaseClass (Wrapper wrapper, Pointer handle) {");
+	w.Writeln ("      this.wrapper = wrapper;");
+	w.Writeln ("      this.handle = handle;");
+	w.Writeln ("    }");
+	w.Writeln ("");
+	w.Writeln ("    /**");
+	w.Writeln ("     * Returns the handle of the instance, or null after it has been released.");
+	w.Writeln ("     *");
+	w.Writeln ("     * @return the handle of the instance");
+	w.Writeln ("     */");
+	w.Writeln ("    public Pointer getHandle () {");
+	w.Writeln ("      return handle;");
+	w.Writeln ("    }");
+	w.Writeln ("");
+	w.Writeln ("    /**");
+	w.Writeln ("     * Releases the instance.");
+	w.Writeln ("     */");
+	w.Writeln ("    @Override");
+	w.Writeln ("    public void close () {");
+	w.Writeln ("      if (handle != null) {");
+	w.Writeln ("        Pointer releasedHandle = handle;");
+	w.Writeln ("        handle = null;");
+	w.Writeln ("        checkError (wrapper.lib.%s (releasedHandle));", releaseExport);
+	w.Writeln ("      }");
+	w.Writeln ("    }");
+	w.Writeln ("  }");
+
+	for _, class := range component.Classes {
+		err = writeJavaClass (component, class, w);
+		if (err != nil) {
+			return err;
+		}
+	}
+
+	err = writeJavaWrapper (component, w);
+	if (err != nil) {
+		return err;
+	}
+
+	writeJavaHelpers (component, w);
+
+	w.Writeln ("}");
+	return nil;
+}
+
+// writeJavaTypes writes the enums, structs and callbacks of the component
+func writeJavaTypes (component ComponentDefinition, w LanguageWriter) (error) {
+	for _, enum := range component.Enums {
+		w.Writeln ("");
+		w.Writeln ("  public enum %s {", enum.Name);
+		for i, option := range enum.Options {
+			separator := ",";
+			if (i == len (enum.Options) - 1) {
+				separator = ";";
+			}
+			w.Writeln ("    %s (%d)%s", option.Name, option.Value, separator);
+		}
+		w.Writeln ("");
+		w.Writeln ("    private final int value;");
+		w.Writeln ("");
+		w.Writeln ("    private %s (int value) {", enum.Name);
+		w.Writeln ("      this.value = value;");
+		w.Writeln ("    }");
+		w.Writeln ("");
+		w.Writeln ("    public int getValue () {");
+		w.Writeln ("      return value;");
+		w.Writeln ("    }");
+		w.Writeln ("");
+		w.Writeln ("    public static %s fromValue (int value) {", enum.Name);
+		w.Writeln ("      for (%s option : values ()) {", enum.Name);
+		w.Writeln ("        if (option.value == value) {");
+		w.Writeln ("          return option;");
+		w.Writeln ("        }");
+		w.Writeln ("      }");
+		w.Writeln ("      throw new IllegalArgumentException (\"invalid value \" + value + \" of enum %s\");", enum.Name);
+		w.Writeln ("    }");
+		w.Writeln ("  }");
+	}
+
+	for _, structinfo := range component.Structs {
+		fieldNames := []string {};
+		for _, member := range structinfo.Members {
+			fieldNames = append (fieldNames, "\"" + member.Name + "\"");
+		}
+
+		w.Writeln ("");
+		w.Writeln ("  /**");
+		w.Writeln ("   * %s has the packed layout of the struct s%s%s. Bools are stored as bytes, enums as their values,", structinfo.Name, component.NameSpace, structinfo.Name);
+		w.Writeln ("   * and arrays are flattened in the order of their memory layout.");
+		w.Writeln ("   */");
+		w.Writeln ("  @Structure.FieldOrder ({%s})", strings.Join (fieldNames, ", "));
+		w.Writeln ("  public static class %s extends Structure {", structinfo.Name);
+		for _, member := range structinfo.Members {
+			memberType := "int";
+			if (member.Type != "enum") {
+				basicType, err := getJavaBasicType (member.Type);
+				if (err != nil) {
+					return fmt.Errorf ("invalid member \"%s\" of struct \"%s\": %s", member.Name, structinfo.Name, err.Error ());
+				}
+				memberType = basicType;
+				if (member.Type == "bool") {
+					memberType = "byte";
+				}
+			}
+
+			if (member.Rows > 0) {
+				count := member.Rows;
+				if (member.Columns > 0) {
+					count = member.Rows * member.Columns;
+				}
+				w.Writeln ("    public %s[] %s = new %s[%d];", memberType, member.Name, memberType, count);
+			} else {
+				w.Writeln ("    public %s %s;", memberType, member.Name);
+			}
+		}
+		w.Writeln ("");
+		w.Writeln ("    public %s () {", structinfo.Name);
+		w.Writeln ("      super (ALIGN_NONE);");
+		w.Writeln ("    }");
+		w.Writeln ("");
+		w.Writeln ("    public %s (Pointer pointer) {", structinfo.Name);
+		w.Writeln ("      super (pointer, ALIGN_NONE);");
+		w.Writeln ("      read ();");
+		w.Writeln ("    }");
+		w.Writeln ("  }");
+	}
+
+	for _, functiontype := range component.Functions {
+		parameters := []string {};
+		comments := [][2]string {};
+		for _, param := range functiontype.Params {
+			paramType := "";
+			if (param.ParamPass != "in") {
+				// The callback writes its outputs to the pointers, e.g. with setByte (0, value)
+				paramType = "Pointer";
+			} else {
+				switch (param.ParamType) {
+					case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "single", "double":
+						basicType, err := getJavaBasicType (param.ParamType);
+						if (err != nil) {
+							return err;
+						}
+						paramType = basicType;
+					case "bool":
+						paramType = "byte";
+					case "enum":
+						paramType = "int";
+					case "string":
+						paramType = "String";
+					case "handle":
+						paramType = "Pointer";
+					default:
+						return fmt.Errorf ("parameter type \"%s\" of %s (%s) is not supported by Java callbacks", param.ParamType, functiontype.FunctionName, param.ParamName);
+				}
+			}
+			paramName := getJavaMethodName (param.ParamName);
+			parameters = append (parameters, paramType + " " + paramName);
+			comments = append (comments, [2]string {paramName, param.ParamDescription});
+		}
+
+		w.Writeln ("");
+		w.Writeln ("  public interface %s extends Callback {", functiontype.FunctionName);
+		writeJavaComment (w, "    ", functiontype.FunctionDescription, comments, "");
+		w.Writeln ("    void invoke (%s);", strings.Join (parameters, ", "));
+		w.Writeln ("  }");
+	}
+
+	return nil;
+}
+
+// writeJavaExceptions writes an exception class for every error of the component
+func writeJavaExceptions (component ComponentDefinition, w LanguageWriter) (error) {
+	NameSpace := component.NameSpace;
+	errorMethod, hasErrorMethod := component.Global.GetErrorMethod ();
+
+	w.Writeln ("");
+	w.Writeln ("  /**");
+	w.Writeln ("   * %sException is thrown if a function of %s fails.", NameSpace, component.LibraryName);
+	w.Writeln ("   */");
+	w.Writeln ("  public static class %sException extends RuntimeException {", NameSpace);
+	w.Writeln ("    private final int errorCode;");
+	w.Writeln ("");
+	w.Writeln ("    public %sException (int errorCode, String errorName, String errorDescription) {", NameSpace);
+	if (hasErrorMethod) {
+		w.Writeln ("      this (errorCode, errorName, errorDescription, null);");
+		w.Writeln ("    }");
+		w.Writeln ("");
+		w.Writeln ("    public %sException (int errorCode, String errorName, String errorDescription, String errorMessage) {", NameSpace);
+		w.Writeln ("      super (\"%s Error \" + errorCode + \" (\" + errorName + \": \" + errorDescription + \")\"", component.LibraryName);
+		w.Writeln ("        + (((errorMessage != null) && !errorMessage.isEmpty ()) ? \": \" + errorMessage : \"\"));");
+	} else {
+		w.Writeln ("      super (\"%s Error \" + errorCode + \" (\" + errorName + \": \" + errorDescription + \")\");", component.LibraryName);
+	}
+	w.Writeln ("      this.errorCode = errorCode;");
+	w.Writeln ("    }");
+	w.Writeln ("");
+	w.Writeln ("    public int getErrorCode () {");
+	w.Writeln ("      return errorCode;");
+	w.Writeln ("    }");
+	w.Writeln ("  }");
+
+	for _, errorcode := range component.Errors.Errors {
+		w.Writeln ("");
+		w.Writeln ("  /**");
+		w.Writeln ("   * %sException is thrown for the error %s: %s", errorcode.Name, errorcode.Name, errorcode.Description);
+		w.Writeln ("   */");
+		w.Writeln ("  public static class %sException extends %sException {", errorcode.Name, NameSpace);
+		w.Writeln ("    public static final int CODE = %d;", errorcode.Code);
+		w.Writeln ("");
+		w.Writeln ("    public %sException () {", errorcode.Name);
+		w.Writeln ("      super (CODE, \"%s\", \"%s\");", errorcode.Name, errorcode.Description);
+		w.Writeln ("    }");
+		if (hasErrorMethod) {
+			w.Writeln ("");
+			w.Writeln ("    public %sException (String errorMessage) {", errorcode.Name);
+			w.Writeln ("      super (CODE, \"%s\", \"%s\", errorMessage);", errorcode.Name, errorcode.Description);
+			w.Writeln ("    }");
+		}
+		w.Writeln ("  }");
+	}
+
+	w.Writeln ("");
+	w.Writeln ("  private static void checkError (int errorCode) {");
+	w.Writeln ("    switch (errorCode) {");
+	w.Writeln ("      case 0: return;");
+	for _, errorcode := range component.Errors.Errors {
+		w.Writeln ("      case %d: throw new %sException ();", errorcode.Code, errorcode.Name);
+	}
+	w.Writeln ("      default: throw new %sException (errorCode, \"UNKNOWN\", \"unknown error\");", NameSpace);
+	w.Writeln ("    }");
+	w.Writeln ("  }");
+
+	if (hasErrorMethod) {
+		errorExport := GetCExportName (NameSpace, "", errorMethod, true);
+		w.Writeln ("");
+		w.Writeln ("  private static void checkError (Wrapper wrapper, Pointer instance, int errorCode) {");
+		w.Writeln ("    if (errorCode == 0) {");
+		w.Writeln ("      return;");
+		w.Writeln ("    }");
+		w.Writeln ("");
+		w.Writeln ("    String errorMessage = getErrorMessage (wrapper, instance);");
+		w.Writeln ("    switch (errorCode) {");
+		for _, errorcode := range component.Errors.Errors {
+			w.Writeln ("      case %d: throw new %sException (errorMessage);", errorcode.Code, errorcode.Name);
+		}
+		w.Writeln ("      default: throw new %sException (errorCode, \"UNKNOWN\", \"unknown error\", errorMessage);", NameSpace);
+		w.Writeln ("    }");
+		w.Writeln ("  }");
+		w.Writeln ("");
+		w.Writeln ("  private static String getErrorMessage (Wrapper wrapper, Pointer instance) {");
+		w.Writeln ("    IntByReference neededChars = new IntByReference ();");
+		w.Writeln ("    ByteByReference hasError = new ByteByReference ();");
+		w.Writeln ("    if ((wrapper.lib.%s (instance, 0, neededChars, null, hasError) != 0) || (hasError.getValue () == 0)) {", errorExport);
+		w.Writeln ("      return null;");
+		w.Writeln ("    }");
+		w.Writeln ("");
+		w.Writeln ("    byte[] buffer = new byte[neededChars.getValue () + 1];");
+		w.Writeln ("    if (wrapper.lib.%s (instance, buffer.length, neededChars, buffer, hasError) != 0) {", errorExport);
+		w.Writeln ("      return null;");
+		w.Writeln ("    }");
+		w.Writeln ("    return fromUTF8 (buffer);");
+		w.Writeln ("  }");
+	}
+
+	return nil;
+}
+
+// getJavaNativeParameters returns the parameters of the JNA declaration of a method, named like the parameters of the C-header
+func getJavaNativeParameters (method ComponentDefinitionMethod, NameSpace string, ClassName string) ([]string, error) {
+	nativeParams := []string {};
+	for _, param := range method.Params {
+		cParams, err := generateCParameter (param, ClassName, method.MethodName, NameSpace);
+		if (err != nil) {
+			return nil, err;
+		}
+
+		switch (param.ParamPass) {
+			case "in":
+				switch (param.ParamType) {
+					case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "single", "double":
+						basicType, err := getJavaBasicType (param.ParamType);
+						if (err != nil) {
+							return nil, err;
+						}
+						nativeParams = append (nativeParams, basicType + " " + cParams[0].ParamName);
+					case "bool":
+						nativeParams = append (nativeParams, "byte " + cParams[0].ParamName);
+					case "enum":
+						nativeParams = append (nativeParams, "int " + cParams[0].ParamName);
+					case "string":
+						nativeParams = append (nativeParams, "byte[] " + cParams[0].ParamName);
+					case "struct", "functiontype":
+						nativeParams = append (nativeParams, param.ParamClass + " " + cParams[0].ParamName);
+					case "handle":
+						nativeParams = append (nativeParams, "Pointer " + cParams[0].ParamName);
+					case "basicarray":
+						elementType, err := getJavaBasicType (param.ParamClass);
+						if (err != nil) {
+							return nil, err;
+						}
+						if (param.ParamClass == "bool") {
+							elementType = "byte";
+						}
+						nativeParams = append (nativeParams, "long " + cParams[0].ParamName, elementType + "[] " + cParams[1].ParamName);
+					case "structarray":
+						nativeParams = append (nativeParams, "long " + cParams[0].ParamName, "Pointer " + cParams[1].ParamName);
+					default:
+						return nil, fmt.Errorf ("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName);
+				}
+			case "out", "return":
+				switch (param.ParamType) {
+					case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "single", "double", "bool", "enum", "handle":
+						nativeParams = append (nativeParams, getJavaReferenceType (param.ParamType) + " " + cParams[0].ParamName);
+					case "struct":
+						nativeParams = append (nativeParams, param.ParamClass + " " + cParams[0].ParamName);
+					case "string":
+						nativeParams = append (nativeParams, "int " + cParams[0].ParamName, "IntByReference " + cParams[1].ParamName, "byte[] " + cParams[2].ParamName);
+					case "basicarray":
+						elementType, err := getJavaBasicType (param.ParamClass);
+						if (err != nil) {
+							return nil, err;
+						}
+						if (param.ParamClass == "bool") {
+							elementType = "byte";
+						}
+						nativeParams = append (nativeParams, "long " + cParams[0].ParamName, "LongByReference " + cParams[1].ParamName, elementType + "[] " + cParams[2].ParamName);
+					case "structarray":
+						nativeParams = append (nativeParams, "long " + cParams[0].ParamName, "LongByReference " + cParams[1].ParamName, "Pointer " + cParams[2].ParamName);
+					default:
+						return nil, fmt.Errorf ("method parameter type \"%s\" of param pass \"%s\" is not implemented for %s.%s (%s)", param.ParamType, param.ParamPass, ClassName, method.MethodName, param.ParamName);
+				}
+			default:
+				return nil, fmt.Errorf ("invalid method parameter passing \"%s\" for %s.%s (%s)", param.ParamPass, ClassName, method.MethodName, param.ParamName);
+		}
+	}
+	return nativeParams, nil;
+}
+
+// writeJavaNativeFunctions writes the JNA interface of the exported functions of the library
+func writeJavaNativeFunctions (component ComponentDefinition, w LanguageWriter) (error) {
+	NameSpace := component.NameSpace;
+
+	w.Writeln ("");
+	w.Writeln ("  private interface NativeFunctions extends Library {");
+	writeFunctions := func (methods []ComponentDefinitionMethod, ClassName string, isGlobal bool) (error) {
+		for _, method := range methods {
+			nativeParams, err := getJavaNativeParameters (method, NameSpace, ClassName);
+			if (err != nil) {
+				return err;
+			}
+			if (!isGlobal) {
+				nativeParams = append ([]string {"Pointer " + ClassName}, nativeParams...);
+			}
+			w.Writeln ("    int %s (%s);", GetCExportName (NameSpace, ClassName, method, isGlobal), strings.Join (nativeParams, ", "));
+		}
+		return nil;
+	}
+	for _, class := range component.Classes {
+		err := writeFunctions (class.Methods, class.ClassName, false);
+		if (err != nil) {
+			return err;
+		}
+	}
+	err := writeFunctions (component.Global.Methods, "Wrapper", true);
+	if (err != nil) {
+		return err;
+	}
+	w.Writeln ("  }");
+
+	return nil;
+}
+
+// writeJavaClass writes the class definition of a class of the component
+func writeJavaClass (component ComponentDefinition, class ComponentDefinitionClass, w LanguageWriter) (error) {
+	parentClass := class.ParentClass;
+	if (parentClass == "") {
+		parentClass = "BaseClass";
+	}
+
+	w.Writeln ("");
+	description := class.ClassDescription;
+	if (description == "") {
+		description = fmt.Sprintf ("The class %s of %s.", class.ClassName, component.LibraryName);
+	}
+	writeJavaComment (w, "  ", description, nil, "");
+	w.Writeln ("  public static class %s extends %s {", class.ClassName, parentClass);
+	w.Writeln ("");
+	w.Writeln ("    protected %s (Wrapper wrapper, Pointer handle) {", class.ClassName);
+	w.Writeln ("      super (wrapper, handle);");
+	w.Writeln ("    }");
+
+	for _, method := range class.Methods {
+		err := writeJavaMethod (component, method, w, class.ClassName, false);
+		if (err != nil) {
+			return err;
+		}
+	}
+	w.Writeln ("  }");
+	return nil;
+}
+
+// writeJavaWrapper writes the class, that loads the library and gives access to its global functions
+func writeJavaWrapper (component ComponentDefinition, w LanguageWriter) (error) {
+	w.Writeln ("");
+	w.Writeln ("  /**");
+	w.Writeln ("   * Wrapper loads %s and gives access to its global functions.", component.LibraryName);
+	w.Writeln ("   */");
+	w.Writeln ("  public static class Wrapper {");
+	w.Writeln ("    private final NativeFunctions lib;");
+	w.Writeln ("");
+	w.Writeln ("    /**");
+	w.Writeln ("     * Loads %s.", component.LibraryName);
+	w.Writeln ("     *");
+	w.Writeln ("     * @param fileName the file name of the library, or its name \"%s\" to search it in the default paths", component.BaseName);
+	w.Writeln ("     */");
+	w.Writeln ("    public Wrapper (String fileName) {");
+	w.Writeln ("      lib = Native.load (fileName, NativeFunctions.class);");
+	w.Writeln ("    }");
+
+	for _, method := range component.Global.Methods {
+		if (method.MethodName == component.Global.ReleaseMethod) {
+			continue;
+		}
+		err := writeJavaMethod (component, method, w, "Wrapper", true);
+		if (err != nil) {
+			return err;
+		}
+	}
+	w.Writeln ("  }");
+	return nil;
+}
+
+// writeJavaHelpers writes the conversions of strings, bools and struct arrays
+func writeJavaHelpers (component ComponentDefinition, w LanguageWriter) {
+	w.Writeln ("");
+	w.Writeln ("  private static byte[] toUTF8 (String value) {");
+	w.Writeln ("    byte[] bytes = value.getBytes (StandardCharsets.UTF_8);");
+	w.Writeln ("    return Arrays.copyOf (bytes, bytes.length + 1);");
+	w.Writeln ("  }");
+	w.Writeln ("");
+	w.Writeln ("  private static String fromUTF8 (byte[] buffer) {");
+	w.Writeln ("    int length = 0;");
+	w.Writeln ("    while ((length < buffer.length) && (buffer[length] != 0)) {");
+	w.Writeln ("      length++;");
+	w.Writeln ("    }");
+	w.Writeln ("    return new String (buffer, 0, length, StandardCharsets.UTF_8);");
+	w.Writeln ("  }");
+	w.Writeln ("");
+	w.Writeln ("  private static byte[] toBytes (boolean[] values) {");
+	w.Writeln ("    byte[] bytes = new byte[values.length];");
+	w.Writeln ("    for (int i = 0; i < values.length; i++) {");
+	w.Writeln ("      bytes[i] = (byte) (values[i] ? 1 : 0);");
+	w.Writeln ("    }");
+	w.Writeln ("    return bytes;");
+	w.Writeln ("  }");
+	w.Writeln ("");
+	w.Writeln ("  private static boolean[] toBooleans (byte[] bytes) {");
+	w.Writeln ("    boolean[] values = new boolean[bytes.length];");
+	w.Writeln ("    for (int i = 0; i < bytes.length; i++) {");
+	w.Writeln ("      values[i] = (bytes[i] != 0);");
+	w.Writeln ("    }");
+	w.Writeln ("    return values;");
+	w.Writeln ("  }");
+	w.Writeln ("");
+	w.Writeln ("  private static Pointer writeStructures (Structure[] values) {");
+	w.Writeln ("    if (values.length == 0) {");
+	w.Writeln ("      return null;");
+	w.Writeln ("    }");
+	w.Writeln ("    int size = values[0].size ();");
+	w.Writeln ("    Memory memory = new Memory ((long) size * values.length);");
+	w.Writeln ("    for (int i = 0; i < values.length; i++) {");
+	w.Writeln ("      values[i].write ();");
+	w.Writeln ("      memory.write ((long) size * i, values[i].getPointer ().getByteArray (0, size), 0, size);");
+	w.Writeln ("    }");
+	w.Writeln ("    return memory;");
+	w.Writeln ("  }");
+}
+
+// javaStringOutput passes the buffer of a string output parameter as byte array
+var javaStringOutput = CStringOutputSyntax {
+	Declaration: "IntByReference needed%[1]s = new IntByReference ();",
+	NeededChars: "needed%[1]s.getValue ()",
+	Allocation: "byte[] buffer%[1]s = new byte[%[2]s];",
+	QueryArguments: "0, needed%[1]s, null",
+	CallArguments: "buffer%[1]s.length, needed%[1]s, buffer%[1]s",
+};
+
+// writeJavaMethod writes a method, that calls the exported function of the library and converts its parameters
+func writeJavaMethod (component ComponentDefinition, method ComponentDefinitionMethod, w LanguageWriter, ClassName string, isGlobal bool) (error) {
+	NameSpace := component.NameSpace;
+
+	parameters := []string {};
+	comments := [][2]string {};
+	var calls CBufferCalls;
+	conversions := []string {};
+	results := []string {};
+	resultTypes := []string {};
+	resultNames := []string {};
+	resultComments := []string {};
+
+	// The classes call the library of their wrapper
+	wrapperName := "wrapper";
+	if (isGlobal) {
+		wrapperName = "this";
+	} else {
+		calls.AddArgument ("getHandle ()");
+	}
+
+	for _, param := range method.Params {
+		paramName := getJavaMethodName (param.ParamName);
+		localName := param.ParamName;
+		paramType, err := getJavaParamType (param);
+		if (err != nil) {
+			return fmt.Errorf ("%s for %s.%s (%s)", err.Error (), ClassName, method.MethodName, param.ParamName);
+		}
+
+		switch (param.ParamPass) {
+		case "in":
+			parameters = append (parameters, paramType + " " + paramName);
+			comments = append (comments, [2]string {paramName, param.ParamDescription});
+			argument := paramName;
+			switch (param.ParamType) {
+				case "bool":
+					argument = fmt.Sprintf ("(byte) (%s ? 1 : 0)", paramName);
+				case "enum":
+					argument = paramName + ".getValue ()";
+				case "string":
+					argument = fmt.Sprintf ("toUTF8 (%s)", paramName);
+				case "basicarray":
+					if (param.ParamClass == "bool") {
+						argument = fmt.Sprintf ("%s.length, toBytes (%s)", paramName, paramName);
+					} else {
+						argument = fmt.Sprintf ("%s.length, %s", paramName, paramName);
+					}
+				case "structarray":
+					calls.Declarations = append (calls.Declarations, fmt.Sprintf ("Pointer memory%s = writeStructures (%s);", localName, paramName));
+					argument = fmt.Sprintf ("%s.length, memory%s", paramName, localName);
+				case "handle":
+					argument = fmt.Sprintf ("(%s != null) ? %s.getHandle () : null", paramName, paramName);
+			}
+			calls.AddArgument (argument);
+
+		case "out", "return":
+			resultTypes = append (resultTypes, paramType);
+			resultNames = append (resultNames, paramName);
+			resultComments = append (resultComments, param.ParamDescription);
+			result := "";
+
+			switch (param.ParamType) {
+				case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "single", "double", "bool", "enum":
+					referenceType := getJavaReferenceType (param.ParamType);
+					calls.Declarations = append (calls.Declarations, fmt.Sprintf ("%s reference%s = new %s ();", referenceType, localName, referenceType));
+					calls.AddArgument ("reference" + localName);
+					switch (param.ParamType) {
+						case "bool":
+							result = fmt.Sprintf ("(reference%s.getValue () != 0)", localName);
+						case "enum":
+							result = fmt.Sprintf ("%s.fromValue (reference%s.getValue ())", param.ParamClass, localName);
+						default:
+							result = fmt.Sprintf ("reference%s.getValue ()", localName);
+					}
+				case "struct":
+					calls.Declarations = append (calls.Declarations, fmt.Sprintf ("%s structure%s = new %s ();", param.ParamClass, localName, param.ParamClass));
+					calls.AddArgument ("structure" + localName);
+					result = "structure" + localName;
+				case "handle":
+					calls.Declarations = append (calls.Declarations, fmt.Sprintf ("PointerByReference reference%s = new PointerByReference ();", localName));
+					calls.AddArgument ("reference" + localName);
+					result = fmt.Sprintf ("(reference%s.getValue () != null) ? new %s (%s, reference%s.getValue ()) : null", localName, param.ParamClass, wrapperName, localName);
+				case "string":
+					calls.AddStringOutput (javaStringOutput, localName);
+					result = fmt.Sprintf ("fromUTF8 (buffer%s)", localName);
+				case "basicarray":
+					elementType, err := getJavaBasicType (param.ParamClass);
+					if (err != nil) {
+						return err;
+					}
+					if (param.ParamClass == "bool") {
+						elementType = "byte";
+						result = fmt.Sprintf ("toBooleans (array%s)", localName);
+					} else {
+						result = "array" + localName;
+					}
+					calls.Declarations = append (calls.Declarations, fmt.Sprintf ("LongByReference needed%s = new LongByReference ();", localName));
+					calls.AddBufferArgument (fmt.Sprintf ("0, needed%s, null", localName), fmt.Sprintf ("array%s.length, needed%s, array%s", localName, localName, localName),
+						fmt.Sprintf ("%s[] array%s = new %s[(int) needed%s.getValue ()];", elementType, localName, elementType, localName));
+				case "structarray":
+					calls.Declarations = append (calls.Declarations, fmt.Sprintf ("LongByReference needed%s = new LongByReference ();", localName));
+					calls.Declarations = append (calls.Declarations, fmt.Sprintf ("int size%s = new %s ().size ();", localName, param.ParamClass));
+					calls.AddBufferArgument (fmt.Sprintf ("0, needed%s, null", localName), fmt.Sprintf ("needed%s.getValue (), needed%s, memory%s", localName, localName, localName),
+						fmt.Sprintf ("Memory memory%s = (needed%s.getValue () > 0) ? new Memory (size%s * needed%s.getValue ()) : null;", localName, localName, localName, localName));
+					conversions = append (conversions,
+						fmt.Sprintf ("%s[] array%s = new %s[(int) needed%s.getValue ()];", param.ParamClass, localName, param.ParamClass, localName),
+						fmt.Sprintf ("for (int i = 0; i < array%s.length; i++) {", localName),
+						fmt.Sprintf ("  array%s[i] = new %s (memory%s.share ((long) size%s * i));", localName, param.ParamClass, localName, localName),
+						"}");
+					result = "array" + localName;
+				default:
+					return fmt.Errorf ("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName);
+			}
+			results = append (results, result);
+
+		default:
+			return fmt.Errorf ("invalid method parameter passing \"%s\" for %s.%s (%s)", param.ParamPass, ClassName, method.MethodName, param.ParamName);
+		}
+	}
+
+	exportName := GetCExportName (NameSpace, ClassName, method, isGlobal);
+	resultType := getJavaResultType (ClassName, method);
+	returnComment := "";
+	if (len (results) == 1) {
+		returnComment = resultComments[0];
+	} else if (len (results) > 1) {
+		// Multiple outputs are returned in a class with a field for each output
+		resultType = method.MethodName + "Result";
+		returnComment = "the outputs " + strings.Join (resultNames, ", ");
+		w.Writeln ("");
+		writeJavaComment (w, "    ", "The outputs of " + getJavaMethodName (method.MethodName) + ".", nil, "");
+		w.Writeln ("    public static class %s {", resultType);
+		for i, name := range resultNames {
+			w.Writeln ("      /** %s */", resultComments[i]);
+			w.Writeln ("      public %s %s;", resultTypes[i], name);
+		}
+		w.Writeln ("    }");
+	}
+
+	w.Writeln ("");
+	writeJavaComment (w, "    ", method.MethodDescription, comments, returnComment);
+	w.Writeln ("    public %s %s (%s) {", resultType, getJavaMethodName (method.MethodName), strings.Join (parameters, ", "));
+	w.Writelns ("      ", calls.Declarations);
+	checkError := "checkError (";
+	_, hasErrorMethod := component.Global.GetErrorMethod ();
+	if (hasErrorMethod) {
+		if (isGlobal) {
+			checkError = fmt.Sprintf ("checkError (%s, null, ", wrapperName);
+		} else {
+			checkError = fmt.Sprintf ("checkError (%s, getHandle (), ", wrapperName);
+		}
+	}
+	calls.WriteCalls (w, "      ", fmt.Sprintf ("%s%s.lib.%s (%%s));", checkError, wrapperName, exportName), nil);
+	w.Writelns ("      ", conversions);
+	if (len (results) == 1) {
+		w.Writeln ("      return %s;", results[0]);
+	} else if (len (results) > 1) {
+		w.Writeln ("      %s methodResult = new %s ();", resultType, resultType);
+		for i, name := range resultNames {
+			w.Writeln ("      methodResult.%s = %s;", name, results[i]);
+		}
+		w.Writeln ("      return methodResult;");
+	}
+	w.Writeln ("    }");
+
+	return nil;
+}
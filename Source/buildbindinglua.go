@@ -0,0 +1,750 @@
+/*++
+
+Copyright (C) 2018 Autodesk Inc. (Original Author)
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+--*/
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+// buildbindinglua.go
+// functions to generate a Lua module of a library's API on top of the dynamic C wrapper table.
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// BuildBindingLua builds a Lua module in C++ of a library's API, that calls the library through the dynamic C wrapper table
+func BuildBindingLua(component ComponentDefinition, outputFolder string, indentString string) error {
+	libraryname := component.LibraryName;
+	baseName := component.BaseName;
+
+	LuaImpl := path.Join(outputFolder, baseName + "_lua.cpp");
+	log.Printf("Creating \"%s\"", LuaImpl)
+	luafile, err := CreateLanguageFile(LuaImpl, indentString)
+	if err != nil {
+		return err;
+	}
+	err = luafile.WriteCLicenseHeader(component,
+		fmt.Sprintf("This is an autogenerated Lua module in order to allow an easy\n use of %s from Lua", libraryname),
+		true)
+	if err != nil {
+		return err
+	}
+
+	err = buildLuaModule(component, luafile)
+	if err != nil {
+		return err;
+	}
+
+	LuaCMake := path.Join(outputFolder, "CMakeLists.txt");
+	log.Printf("Creating \"%s\"", LuaCMake)
+	cmakefile, err := CreateLanguageFile(LuaCMake, "  ")
+	if err != nil {
+		return err;
+	}
+	err = cmakefile.WriteCMakeLicenseHeader(component,
+		fmt.Sprintf("This is an autogenerated CMake Project of the Lua module of %s", libraryname),
+		true)
+	if err != nil {
+		return err
+	}
+	return cmakefile.WriteTemplate("luabinding.cmake", NewTemplateData(component))
+}
+
+// validateBindingLua checks that the names of the Lua module are unique and valid for C
+func validateBindingLua (component ComponentDefinition, options GeneratorOptions) (error) {
+	if (!regexp.MustCompile ("^[a-zA-Z_][a-zA-Z0-9_]*$").MatchString (component.BaseName)) {
+		return fmt.Errorf ("the basename \"%s\" is not a valid name of the Lua module luaopen_%s", component.BaseName, component.BaseName);
+	}
+
+	names := make (map[string]string);
+	names["LoadLibrary"] = "the Lua binding";
+	names["Errors"] = "the Lua binding";
+	add := func (name string, source string) (error) {
+		previous, ok := names[name];
+		if (ok) {
+			return fmt.Errorf ("the Lua name \"%s\" of %s collides with %s", name, source, previous);
+		}
+		names[name] = source;
+		return nil;
+	}
+	for _, enum := range component.Enums {
+		err := add (enum.Name, "enum " + enum.Name);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, method := range component.Global.Methods {
+		err := add (method.MethodName, "global method " + method.MethodName);
+		if (err != nil) {
+			return err;
+		}
+	}
+
+	checkParams := func (method ComponentDefinitionMethod, source string) (error) {
+		for _, param := range method.Params {
+			switch (param.ParamName) {
+				case "Self", "WrapperTable", "Element":
+					return fmt.Errorf ("the parameter %s of %s collides with the Lua binding", param.ParamName, source);
+			}
+		}
+		return nil;
+	}
+	for _, class := range component.Classes {
+		for _, method := range class.Methods {
+			err := checkParams (method, "method " + class.ClassName + "." + method.MethodName);
+			if (err != nil) {
+				return err;
+			}
+		}
+	}
+	for _, method := range component.Global.Methods {
+		err := checkParams (method, "global method " + method.MethodName);
+		if (err != nil) {
+			return err;
+		}
+	}
+	return nil;
+}
+
+// getLuaClassMetatableName returns the name of the metatable of the instances of a class
+func getLuaClassMetatableName (NameSpace string, ClassName string) (string) {
+	return NameSpace + "." + ClassName;
+}
+
+// getLuaClassNamesArray returns the name of the array of the metatable names a handle of a class accepts
+func getLuaClassNamesArray (component ComponentDefinition, ClassName string) (string) {
+	for _, class := range component.Classes {
+		if (class.ClassName == ClassName) {
+			return fmt.Sprintf ("g_%s%sClassNames", component.NameSpace, ClassName);
+		}
+	}
+	// handles of the base class of all classes, e.g. of the release method
+	return fmt.Sprintf ("g_%sClassNames", component.NameSpace);
+}
+
+// isLuaClassDerivedFrom checks if a class is or derives from another class
+func isLuaClassDerivedFrom (component ComponentDefinition, class ComponentDefinitionClass, ParentName string) (bool) {
+	for depth := 0; depth <= len (component.Classes); depth++ {
+		if (class.ClassName == ParentName) {
+			return true;
+		}
+		found := false;
+		for _, parent := range component.Classes {
+			if (parent.ClassName == class.ParentClass) {
+				class = parent;
+				found = true;
+				break;
+			}
+		}
+		if (!found) {
+			return false;
+		}
+	}
+	return false;
+}
+
+// getLuaClassHierarchy returns a class and its parent classes, starting with the root class
+func getLuaClassHierarchy (component ComponentDefinition, class ComponentDefinitionClass) ([]ComponentDefinitionClass) {
+	hierarchy := []ComponentDefinitionClass {class};
+	for (len (hierarchy) <= len (component.Classes)) {
+		found := false;
+		for _, parent := range component.Classes {
+			if (parent.ClassName == hierarchy[0].ParentClass) {
+				hierarchy = append ([]ComponentDefinitionClass {parent}, hierarchy...);
+				found = true;
+				break;
+			}
+		}
+		if (!found) {
+			break;
+		}
+	}
+	return hierarchy;
+}
+
+// writeLuaLines writes lines of C code, whose leading pairs of spaces are indentations
+func writeLuaLines (w LanguageWriter, prefix string, lines []string) {
+	for _, line := range lines {
+		code := strings.TrimLeft (line, " ");
+		w.Writeln(prefix + strings.Repeat (" ", len (line) - len (code)) + "%s", code);
+	}
+}
+
+// getLuaElementRead returns the C expression, that converts the value at an index of the Lua stack to a scalar type
+func getLuaElementRead (NameSpace string, elementType string, index string, name string) (string, error) {
+	switch (elementType) {
+		case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64":
+			return fmt.Sprintf ("(%s_%s) to%sInteger (L, %s, \"%s\")", NameSpace, elementType, NameSpace, index, name), nil;
+		case "single", "double":
+			return fmt.Sprintf ("(%s_%s) to%sNumber (L, %s, \"%s\")", NameSpace, elementType, NameSpace, index, name), nil;
+		case "bool":
+			return fmt.Sprintf ("to%sBoolean (L, %s, \"%s\")", NameSpace, index, name), nil;
+		case "enum":
+			return fmt.Sprintf ("(int) to%sInteger (L, %s, \"%s\")", NameSpace, index, name), nil;
+	}
+	return "", fmt.Errorf ("invalid element type \"%s\" for Lua (%s)", elementType, name);
+}
+
+// getLuaArrayElementType returns the C type of the elements of an array parameter
+func getLuaArrayElementType (NameSpace string, param ComponentDefinitionParam) (string) {
+	if (param.ParamType == "structarray") {
+		return fmt.Sprintf ("s%s%s", NameSpace, param.ParamClass);
+	}
+	if (param.ParamClass == "bool") {
+		return "bool";
+	}
+	return fmt.Sprintf ("%s_%s", NameSpace, param.ParamClass);
+}
+
+// getLuaElementPush returns the C statement, that pushes a scalar value onto the Lua stack
+func getLuaElementPush (elementType string, value string) (string, error) {
+	switch (elementType) {
+		case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "enum":
+			return fmt.Sprintf ("lua_pushinteger (L, (lua_Integer) %s);", value), nil;
+		case "single", "double":
+			return fmt.Sprintf ("lua_pushnumber (L, (lua_Number) %s);", value), nil;
+		case "bool":
+			return fmt.Sprintf ("lua_pushboolean (L, %s ? 1 : 0);", value), nil;
+	}
+	return "", fmt.Errorf ("invalid element type \"%s\" for Lua", elementType);
+}
+
+func buildLuaModule (component ComponentDefinition, w LanguageWriter) (error) {
+	NameSpace := component.NameSpace;
+
+	w.Writeln("");
+	err := w.WriteTemplate("luabinding.begin", NewTemplateData(component))
+	if (err != nil) {
+		return err;
+	}
+
+	w.Writeln("static const char * get%sErrorName (%sResult nErrorCode)", NameSpace, NameSpace);
+	w.Writeln("{");
+	w.Writeln("  switch (nErrorCode) {");
+	for _, errorcode := range component.Errors.Errors {
+		w.Writeln("    case %s_ERROR_%s: return \"%s\";", strings.ToUpper (NameSpace), errorcode.Name, errorcode.Name);
+	}
+	w.Writeln("    default: return \"UNKNOWN\";");
+	w.Writeln("  }");
+	w.Writeln("}");
+	w.Writeln("");
+	w.Writeln("static const char * get%sErrorDescription (%sResult nErrorCode)", NameSpace, NameSpace);
+	w.Writeln("{");
+	w.Writeln("  switch (nErrorCode) {");
+	for _, errorcode := range component.Errors.Errors {
+		w.Writeln("    case %s_ERROR_%s: return \"%s\";", strings.ToUpper (NameSpace), errorcode.Name, strings.Replace (errorcode.Description, "\"", "\\\"", -1));
+	}
+	w.Writeln("    default: return \"unknown error\";");
+	w.Writeln("  }");
+	w.Writeln("}");
+	w.Writeln("");
+
+	w.Writeln("/*************************************************************************************************************************")
+	w.Writeln(" Metatable names of the classes and their subclasses")
+	w.Writeln("**************************************************************************************************************************/")
+	w.Writeln("");
+	allNames := [] string {};
+	for _, class := range component.Classes {
+		allNames = append (allNames, fmt.Sprintf ("\"%s\"", getLuaClassMetatableName (NameSpace, class.ClassName)));
+	}
+	w.Writeln("static const char * const g_%sClassNames[] = { %s };", NameSpace, strings.Join (append (allNames, "nullptr"), ", "));
+	for _, class := range component.Classes {
+		names := [] string {};
+		for _, subclass := range component.Classes {
+			if (isLuaClassDerivedFrom (component, subclass, class.ClassName)) {
+				names = append (names, fmt.Sprintf ("\"%s\"", getLuaClassMetatableName (NameSpace, subclass.ClassName)));
+			}
+		}
+		w.Writeln("static const char * const g_%s%sClassNames[] = { %s };", NameSpace, class.ClassName, strings.Join (append (names, "nullptr"), ", "));
+	}
+	w.Writeln("");
+
+	err = writeLuaStructConversions (component, w);
+	if (err != nil) {
+		return err;
+	}
+
+	for _, class := range component.Classes {
+		w.Writeln("/*************************************************************************************************************************")
+		w.Writeln(" Class %s", class.ClassName)
+		w.Writeln("**************************************************************************************************************************/")
+		for _, method := range class.Methods {
+			w.Writeln("");
+			err = writeLuaMethod (component, method, w, class.ClassName, false);
+			if (err != nil) {
+				return err;
+			}
+		}
+		w.Writeln("");
+		w.Writeln("static const luaL_Reg g_%s%sMethods[] = {", NameSpace, class.ClassName);
+		// the methods of the parent classes come first, so that the methods of the class override them
+		for _, parent := range getLuaClassHierarchy (component, class) {
+			for _, method := range parent.Methods {
+				w.Writeln("  { \"%s\", lua%s%s_%s },", method.MethodName, NameSpace, parent.ClassName, method.MethodName);
+			}
+		}
+		w.Writeln("  { nullptr, nullptr }");
+		w.Writeln("};");
+		w.Writeln("");
+	}
+
+	w.Writeln("/*************************************************************************************************************************")
+	w.Writeln(" Global functions")
+	w.Writeln("**************************************************************************************************************************/")
+	w.Writeln("");
+	w.Writeln("static int lua%s_LoadLibrary (lua_State * L)", NameSpace);
+	w.Writeln("{");
+	w.Writeln("  s%sDynamicWrapperTable * pWrapperTable = (s%sDynamicWrapperTable *) lua_touserdata (L, lua_upvalueindex (1));", NameSpace, NameSpace);
+	w.Writeln("  const char * pFileName = luaL_checkstring (L, 1);");
+	w.Writeln("  if (pWrapperTable->m_LibraryHandle != nullptr)");
+	w.Writeln("    return luaL_error (L, \"the library of %s has already been loaded\");", NameSpace);
+	w.Writeln("  check%sError (L, Load%sWrapperTable (pWrapperTable, pFileName));", NameSpace, NameSpace);
+	w.Writeln("  return 0;");
+	w.Writeln("}");
+	for _, method := range component.Global.Methods {
+		w.Writeln("");
+		if (method.MethodName == component.Global.ReleaseMethod) {
+			writeLuaReleaseMethod (component, method, w);
+			continue;
+		}
+		err = writeLuaMethod (component, method, w, "", true);
+		if (err != nil) {
+			return err;
+		}
+	}
+	w.Writeln("");
+	w.Writeln("static const luaL_Reg g_%sFunctions[] = {", NameSpace);
+	w.Writeln("  { \"LoadLibrary\", lua%s_LoadLibrary },", NameSpace);
+	for _, method := range component.Global.Methods {
+		w.Writeln("  { \"%s\", lua%s_%s },", method.MethodName, NameSpace, method.MethodName);
+	}
+	w.Writeln("  { nullptr, nullptr }");
+	w.Writeln("};");
+	w.Writeln("");
+
+	writeLuaOpen (component, w);
+	return nil;
+}
+
+// writeLuaStructConversions writes the functions, that convert the structs from and to Lua tables
+func writeLuaStructConversions (component ComponentDefinition, w LanguageWriter) (error) {
+	NameSpace := component.NameSpace;
+
+	if (len (component.Structs) == 0) {
+		return nil;
+	}
+	w.Writeln("/*************************************************************************************************************************")
+	w.Writeln(" Conversion of structs from and to tables")
+	w.Writeln("**************************************************************************************************************************/")
+	w.Writeln("");
+
+	for _, structinfo := range component.Structs {
+		CStructName := "s" + NameSpace + structinfo.Name;
+		w.Writeln("static void read%s%s (lua_State * L, int nIndex, %s * pValue, const char * pName)", NameSpace, structinfo.Name, CStructName);
+		w.Writeln("{");
+		w.Writeln("  nIndex = lua_absindex (L, nIndex);");
+		w.Writeln("  if (!lua_istable (L, nIndex))");
+		w.Writeln("    luaL_error (L, \"%%s must be a table\", pName);");
+		w.Writeln("  memset (pValue, 0, sizeof (%s));", CStructName);
+		for _, member := range structinfo.Members {
+			read, err := getLuaElementRead (NameSpace, member.Type, "-1", structinfo.Name + "." + member.Name);
+			if (err != nil) {
+				return err;
+			}
+			target := "pValue->m_" + member.Name;
+			if (member.Type == "enum") {
+				target = target + "%s.m_code";
+			} else {
+				target = target + "%s";
+			}
+			w.Writeln("");
+			w.Writeln("  lua_getfield (L, nIndex, \"%s\");", member.Name);
+			if (member.Rows == 0) {
+				w.Writeln("  " + fmt.Sprintf (target, "") + " = %s;", read);
+			} else if (member.Columns == 0) {
+				w.Writeln("  if (is%sTable (L, -1, \"%s.%s\")) {", NameSpace, structinfo.Name, member.Name);
+				w.Writeln("    for (int nRow = 0; nRow < %d; nRow++) {", member.Rows);
+				w.Writeln("      lua_geti (L, -1, nRow + 1);");
+				w.Writeln("      " + fmt.Sprintf (target, "[nRow]") + " = %s;", read);
+				w.Writeln("      lua_pop (L, 1);");
+				w.Writeln("    }");
+				w.Writeln("  }");
+			} else {
+				w.Writeln("  if (is%sTable (L, -1, \"%s.%s\")) {", NameSpace, structinfo.Name, member.Name);
+				w.Writeln("    for (int nColumn = 0; nColumn < %d; nColumn++) {", member.Columns);
+				w.Writeln("      lua_geti (L, -1, nColumn + 1);");
+				w.Writeln("      if (is%sTable (L, -1, \"%s.%s\")) {", NameSpace, structinfo.Name, member.Name);
+				w.Writeln("        for (int nRow = 0; nRow < %d; nRow++) {", member.Rows);
+				w.Writeln("          lua_geti (L, -1, nRow + 1);");
+				w.Writeln("          " + fmt.Sprintf (target, "[nColumn][nRow]") + " = %s;", read);
+				w.Writeln("          lua_pop (L, 1);");
+				w.Writeln("        }");
+				w.Writeln("      }");
+				w.Writeln("      lua_pop (L, 1);");
+				w.Writeln("    }");
+				w.Writeln("  }");
+			}
+			w.Writeln("  lua_pop (L, 1);");
+		}
+		w.Writeln("}");
+		w.Writeln("");
+
+		w.Writeln("static void push%s%s (lua_State * L, const %s * pValue)", NameSpace, structinfo.Name, CStructName);
+		w.Writeln("{");
+		w.Writeln("  lua_createtable (L, 0, %d);", len (structinfo.Members));
+		for _, member := range structinfo.Members {
+			value := "pValue->m_" + member.Name + "%s";
+			if (member.Type == "enum") {
+				value = value + ".m_code";
+			}
+			if (member.Rows == 0) {
+				push, err := getLuaElementPush (member.Type, fmt.Sprintf (value, ""));
+				if (err != nil) {
+					return err;
+				}
+				w.Writeln("  %s", push);
+			} else if (member.Columns == 0) {
+				push, err := getLuaElementPush (member.Type, fmt.Sprintf (value, "[nRow]"));
+				if (err != nil) {
+					return err;
+				}
+				w.Writeln("  lua_createtable (L, %d, 0);", member.Rows);
+				w.Writeln("  for (int nRow = 0; nRow < %d; nRow++) {", member.Rows);
+				w.Writeln("    %s", push);
+				w.Writeln("    lua_seti (L, -2, nRow + 1);");
+				w.Writeln("  }");
+			} else {
+				push, err := getLuaElementPush (member.Type, fmt.Sprintf (value, "[nColumn][nRow]"));
+				if (err != nil) {
+					return err;
+				}
+				w.Writeln("  lua_createtable (L, %d, 0);", member.Columns);
+				w.Writeln("  for (int nColumn = 0; nColumn < %d; nColumn++) {", member.Columns);
+				w.Writeln("    lua_createtable (L, %d, 0);", member.Rows);
+				w.Writeln("    for (int nRow = 0; nRow < %d; nRow++) {", member.Rows);
+				w.Writeln("      %s", push);
+				w.Writeln("      lua_seti (L, -2, nRow + 1);");
+				w.Writeln("    }");
+				w.Writeln("    lua_seti (L, -2, nColumn + 1);");
+				w.Writeln("  }");
+			}
+			w.Writeln("  lua_setfield (L, -2, \"%s\");", member.Name);
+		}
+		w.Writeln("}");
+		w.Writeln("");
+	}
+	return nil;
+}
+
+// writeLuaReleaseMethod writes the release method, which invalidates the userdata of the instance
+func writeLuaReleaseMethod (component ComponentDefinition, method ComponentDefinitionMethod, w LanguageWriter) {
+	NameSpace := component.NameSpace;
+
+	w.Writeln("// %s", method.MethodDescription);
+	w.Writeln("static int lua%s_%s (lua_State * L)", NameSpace, method.MethodName);
+	w.Writeln("{");
+	w.Writeln("  s%sDynamicWrapperTable * pWrapperTable = get%sWrapperTable (L);", NameSpace, NameSpace);
+	w.Writeln("  s%sLuaInstance * pInstance = to%sInstance (L, 1, g_%sClassNames);", NameSpace, NameSpace, NameSpace);
+	w.Writeln("  if (pInstance->m_Handle != nullptr) {");
+	w.Writeln("    %sResult nErrorCode = pWrapperTable->m_%s (pInstance->m_Handle);", NameSpace, method.MethodName);
+	w.Writeln("    pInstance->m_Handle = nullptr;");
+	w.Writeln("    check%sError (L, nErrorCode);", NameSpace);
+	w.Writeln("  }");
+	w.Writeln("  return 0;");
+	w.Writeln("}");
+}
+
+// writeLuaMethod writes the Lua C function of a method, that converts the parameters and calls the wrapper table
+func writeLuaMethod (component ComponentDefinition, method ComponentDefinitionMethod, w LanguageWriter, ClassName string, isGlobal bool) (error) {
+	NameSpace := component.NameSpace;
+
+	tableEntry := "m_" + method.MethodName;
+	functionName := fmt.Sprintf ("lua%s_%s", NameSpace, method.MethodName);
+	if (!isGlobal) {
+		tableEntry = fmt.Sprintf ("m_%s_%s", ClassName, method.MethodName);
+		functionName = fmt.Sprintf ("lua%s%s_%s", NameSpace, ClassName, method.MethodName);
+	}
+
+	inputs := [] string {};
+	declarations := [] string {};
+	allocations := [] string {};
+	pushes := [] string {};
+	queryArgs := [] string {};
+	callArgs := [] string {};
+	argIndex := 1;
+	outputCount := 0;
+	if (!isGlobal) {
+		inputs = append (inputs, fmt.Sprintf ("%sHandle hSelf = check%sInstance (L, 1, %s, false);", NameSpace, NameSpace, getLuaClassNamesArray (component, ClassName)));
+		queryArgs = append (queryArgs, "hSelf");
+		callArgs = append (callArgs, "hSelf");
+		argIndex = 2;
+	}
+
+	for _, param := range method.Params {
+		name := param.ParamName;
+		args := [] string {};
+		queries := [] string {};
+
+		if (param.ParamPass == "in") {
+			switch (param.ParamType) {
+				case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64":
+					inputs = append (inputs, fmt.Sprintf ("%s_%s n%s = (%s_%s) luaL_checkinteger (L, %d);", NameSpace, param.ParamType, name, NameSpace, param.ParamType, argIndex));
+					args = []string {"n" + name};
+				case "single", "double":
+					inputs = append (inputs, fmt.Sprintf ("%s_%s d%s = (%s_%s) luaL_checknumber (L, %d);", NameSpace, param.ParamType, name, NameSpace, param.ParamType, argIndex));
+					args = []string {"d" + name};
+				case "bool":
+					inputs = append (inputs, fmt.Sprintf ("luaL_checktype (L, %d, LUA_TBOOLEAN);", argIndex));
+					inputs = append (inputs, fmt.Sprintf ("bool b%s = lua_toboolean (L, %d) != 0;", name, argIndex));
+					args = []string {"b" + name};
+				case "string":
+					inputs = append (inputs, fmt.Sprintf ("const char * p%s = luaL_checkstring (L, %d);", name, argIndex));
+					args = []string {"p" + name};
+				case "enum":
+					inputs = append (inputs, fmt.Sprintf ("e%s%s e%s = (e%s%s) luaL_checkinteger (L, %d);", NameSpace, param.ParamClass, name, NameSpace, param.ParamClass, argIndex));
+					args = []string {"e" + name};
+				case "struct":
+					inputs = append (inputs, fmt.Sprintf ("s%s%s s%s;", NameSpace, param.ParamClass, name));
+					inputs = append (inputs, fmt.Sprintf ("luaL_checktype (L, %d, LUA_TTABLE);", argIndex));
+					inputs = append (inputs, fmt.Sprintf ("read%s%s (L, %d, &s%s, \"%s\");", NameSpace, param.ParamClass, argIndex, name, name));
+					args = []string {"&s" + name};
+				case "basicarray", "structarray":
+					elementType := getLuaArrayElementType (NameSpace, param);
+					inputs = append (inputs, fmt.Sprintf ("luaL_checktype (L, %d, LUA_TTABLE);", argIndex));
+					inputs = append (inputs, fmt.Sprintf ("%s_uint64 n%sCount = (%s_uint64) luaL_len (L, %d);", NameSpace, name, NameSpace, argIndex));
+					inputs = append (inputs, fmt.Sprintf ("%s * p%sBuffer = (%s *) new%sBuffer (L, n%sCount, sizeof (%s));", elementType, name, elementType, NameSpace, name, elementType));
+					inputs = append (inputs, fmt.Sprintf ("for (%s_uint64 nElement = 0; nElement < n%sCount; nElement++) {", NameSpace, name));
+					inputs = append (inputs, fmt.Sprintf ("  lua_geti (L, %d, (lua_Integer) nElement + 1);", argIndex));
+					if (param.ParamType == "structarray") {
+						inputs = append (inputs, fmt.Sprintf ("  read%s%s (L, -1, &p%sBuffer[nElement], \"%s\");", NameSpace, param.ParamClass, name, name));
+					} else {
+						read, err := getLuaElementRead (NameSpace, param.ParamClass, "-1", name);
+						if (err != nil) {
+							return err;
+						}
+						inputs = append (inputs, fmt.Sprintf ("  p%sBuffer[nElement] = %s;", name, read));
+					}
+					inputs = append (inputs, "  lua_pop (L, 1);");
+					inputs = append (inputs, "}");
+					args = []string {fmt.Sprintf ("n%sCount", name), fmt.Sprintf ("p%sBuffer", name)};
+				case "handle":
+					inputs = append (inputs, fmt.Sprintf ("%sHandle h%s = check%sInstance (L, %d, %s, true);", NameSpace, name, NameSpace, argIndex, getLuaClassNamesArray (component, param.ParamClass)));
+					args = []string {"h" + name};
+				case "functiontype":
+					inputs = append (inputs, fmt.Sprintf ("%s%s p%s = (%s%s) check%sCallback (L, %d);", NameSpace, param.ParamClass, name, NameSpace, param.ParamClass, NameSpace, argIndex));
+					args = []string {"p" + name};
+				default:
+					return fmt.Errorf ("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName);
+			}
+			queries = args;
+			argIndex++;
+		} else {
+			outputCount++;
+			switch (param.ParamType) {
+				case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "single", "double", "bool":
+					cType := NameSpace + "_" + param.ParamType;
+					variable := "n" + name;
+					switch (param.ParamType) {
+						case "bool":
+							cType = "bool";
+							variable = "b" + name;
+						case "single", "double":
+							variable = "d" + name;
+					}
+					declarations = append (declarations, fmt.Sprintf ("%s %s = 0;", cType, variable));
+					args = []string {"&" + variable};
+					queries = args;
+					push, err := getLuaElementPush (param.ParamType, variable);
+					if (err != nil) {
+						return err;
+					}
+					pushes = append (pushes, push);
+				case "enum":
+					declarations = append (declarations, fmt.Sprintf ("e%s%s e%s = (e%s%s) 0;", NameSpace, param.ParamClass, name, NameSpace, param.ParamClass));
+					args = []string {"&e" + name};
+					queries = args;
+					pushes = append (pushes, fmt.Sprintf ("lua_pushinteger (L, (lua_Integer) e%s);", name));
+				case "struct":
+					declarations = append (declarations, fmt.Sprintf ("s%s%s s%s;", NameSpace, param.ParamClass, name));
+					declarations = append (declarations, fmt.Sprintf ("memset (&s%s, 0, sizeof (s%s));", name, name));
+					args = []string {"&s" + name};
+					queries = args;
+					pushes = append (pushes, fmt.Sprintf ("push%s%s (L, &s%s);", NameSpace, param.ParamClass, name));
+				case "string":
+					declarations = append (declarations, fmt.Sprintf ("%s_uint32 n%sNeededChars = 0;", NameSpace, name));
+					allocations = append (allocations, fmt.Sprintf ("char * p%sBuffer = (char *) new%sBuffer (L, n%sNeededChars + 1, sizeof (char));", name, NameSpace, name));
+					queries = []string {"0", fmt.Sprintf ("&n%sNeededChars", name), "nullptr"};
+					args = []string {fmt.Sprintf ("n%sNeededChars + 1", name), fmt.Sprintf ("&n%sNeededChars", name), fmt.Sprintf ("p%sBuffer", name)};
+					pushes = append (pushes, fmt.Sprintf ("lua_pushstring (L, p%sBuffer);", name));
+				case "basicarray", "structarray":
+					elementType := getLuaArrayElementType (NameSpace, param);
+					push := "";
+					if (param.ParamType == "structarray") {
+						push = fmt.Sprintf ("push%s%s (L, &p%sBuffer[nElement]);", NameSpace, param.ParamClass, name);
+					} else {
+						elementPush, err := getLuaElementPush (param.ParamClass, fmt.Sprintf ("p%sBuffer[nElement]", name));
+						if (err != nil) {
+							return err;
+						}
+						push = elementPush;
+					}
+					declarations = append (declarations, fmt.Sprintf ("%s_uint64 n%sNeededCount = 0;", NameSpace, name));
+					allocations = append (allocations, fmt.Sprintf ("%s * p%sBuffer = (%s *) new%sBuffer (L, n%sNeededCount, sizeof (%s));", elementType, name, elementType, NameSpace, name, elementType));
+					queries = []string {"0", fmt.Sprintf ("&n%sNeededCount", name), "nullptr"};
+					args = []string {fmt.Sprintf ("n%sNeededCount", name), fmt.Sprintf ("&n%sNeededCount", name), fmt.Sprintf ("p%sBuffer", name)};
+					pushes = append (pushes, fmt.Sprintf ("lua_createtable (L, (int) n%sNeededCount, 0);", name));
+					pushes = append (pushes, fmt.Sprintf ("for (%s_uint64 nElement = 0; nElement < n%sNeededCount; nElement++) {", NameSpace, name));
+					pushes = append (pushes, "  " + push);
+					pushes = append (pushes, "  lua_seti (L, -2, (lua_Integer) nElement + 1);");
+					pushes = append (pushes, "}");
+				case "handle":
+					declarations = append (declarations, fmt.Sprintf ("%sHandle h%s = nullptr;", NameSpace, name));
+					args = []string {"&h" + name};
+					queries = args;
+					pushes = append (pushes, fmt.Sprintf ("push%sInstance (L, h%s, \"%s\");", NameSpace, name, getLuaClassMetatableName (NameSpace, param.ParamClass)));
+				default:
+					return fmt.Errorf ("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName);
+			}
+		}
+		queryArgs = append (queryArgs, queries...);
+		callArgs = append (callArgs, args...);
+	}
+
+	w.Writeln("// %s", method.MethodDescription);
+	w.Writeln("static int %s (lua_State * L)", functionName);
+	w.Writeln("{");
+	checkError := fmt.Sprintf ("check%sError (L, ", NameSpace);
+	_, hasErrorMethod := component.Global.GetErrorMethod ();
+	if (hasErrorMethod) {
+		if (isGlobal) {
+			checkError = fmt.Sprintf ("check%sInstanceError (L, pWrapperTable, nullptr, ", NameSpace);
+		} else {
+			checkError = fmt.Sprintf ("check%sInstanceError (L, pWrapperTable, hSelf, ", NameSpace);
+		}
+	}
+	w.Writeln("  s%sDynamicWrapperTable * pWrapperTable = get%sWrapperTable (L);", NameSpace, NameSpace);
+	writeLuaLines (w, "  ", inputs);
+	writeLuaLines (w, "  ", declarations);
+	if (len (allocations) > 0) {
+		w.Writeln("  %spWrapperTable->%s (%s));", checkError, tableEntry, strings.Join (queryArgs, ", "));
+		writeLuaLines (w, "  ", allocations);
+	}
+	w.Writeln("  %spWrapperTable->%s (%s));", checkError, tableEntry, strings.Join (callArgs, ", "));
+	writeLuaLines (w, "  ", pushes);
+	w.Writeln("  return %d;", outputCount);
+	w.Writeln("}");
+	return nil;
+}
+
+// writeLuaOpen writes the finalizers and the function luaopen_<basename>, that creates the module table
+func writeLuaOpen (component ComponentDefinition, w LanguageWriter) {
+	NameSpace := component.NameSpace;
+	NameSpaceUpper := strings.ToUpper (NameSpace);
+
+	w.Writeln("/*************************************************************************************************************************")
+	w.Writeln(" Finalizers and module table")
+	w.Writeln("**************************************************************************************************************************/")
+	w.Writeln("");
+	w.Writeln("static int lua%sInstance_gc (lua_State * L)", NameSpace);
+	w.Writeln("{");
+	w.Writeln("  s%sDynamicWrapperTable * pWrapperTable = (s%sDynamicWrapperTable *) lua_touserdata (L, lua_upvalueindex (1));", NameSpace, NameSpace);
+	w.Writeln("  s%sLuaInstance * pInstance = (s%sLuaInstance *) lua_touserdata (L, 1);", NameSpace, NameSpace);
+	w.Writeln("  if ((pInstance != nullptr) && (pInstance->m_Handle != nullptr) && (pWrapperTable->m_LibraryHandle != nullptr)) {");
+	w.Writeln("    pWrapperTable->m_%s (pInstance->m_Handle);", component.Global.ReleaseMethod);
+	w.Writeln("    pInstance->m_Handle = nullptr;");
+	w.Writeln("  }");
+	w.Writeln("  return 0;");
+	w.Writeln("}");
+	w.Writeln("");
+	w.Writeln("static int lua%sWrapperTable_gc (lua_State * L)", NameSpace);
+	w.Writeln("{");
+	w.Writeln("  Release%sWrapperTable ((s%sDynamicWrapperTable *) lua_touserdata (L, 1));", NameSpace, NameSpace);
+	w.Writeln("  return 0;");
+	w.Writeln("}");
+	w.Writeln("");
+	w.Writeln("static const luaL_Reg g_%sInstanceMetamethods[] = {", NameSpace);
+	w.Writeln("  { \"__gc\", lua%sInstance_gc },", NameSpace);
+	w.Writeln("#if LUA_VERSION_NUM >= 504");
+	w.Writeln("  { \"__close\", lua%sInstance_gc },", NameSpace);
+	w.Writeln("#endif // LUA_VERSION_NUM");
+	w.Writeln("  { nullptr, nullptr }");
+	w.Writeln("};");
+	w.Writeln("");
+	w.Writeln("extern \"C\" %s_LUA_DECLSPEC int luaopen_%s (lua_State * L)", NameSpaceUpper, component.BaseName);
+	w.Writeln("{");
+	w.Writeln("  luaL_checkversion (L);");
+	w.Writeln("  lua_newtable (L);");
+	w.Writeln("");
+	w.Writeln("  // the wrapper table is the upvalue of all functions and is released after all instances");
+	w.Writeln("  s%sDynamicWrapperTable * pWrapperTable = (s%sDynamicWrapperTable *) lua_newuserdata (L, sizeof (s%sDynamicWrapperTable));", NameSpace, NameSpace, NameSpace);
+	w.Writeln("  Init%sWrapperTable (pWrapperTable);", NameSpace);
+	w.Writeln("  luaL_newmetatable (L, %s_LUA_WRAPPERTABLE);", NameSpaceUpper);
+	w.Writeln("  lua_pushcfunction (L, lua%sWrapperTable_gc);", NameSpace);
+	w.Writeln("  lua_setfield (L, -2, \"__gc\");");
+	w.Writeln("  lua_setmetatable (L, -2);");
+	w.Writeln("");
+	w.Writeln("  luaL_newmetatable (L, %s_LUA_ERROR);", NameSpaceUpper);
+	w.Writeln("  lua_pushcfunction (L, lua%sError_tostring);", NameSpace);
+	w.Writeln("  lua_setfield (L, -2, \"__tostring\");");
+	w.Writeln("  lua_pop (L, 1);");
+	for _, class := range component.Classes {
+		w.Writeln("");
+		w.Writeln("  luaL_newmetatable (L, \"%s\");", getLuaClassMetatableName (NameSpace, class.ClassName));
+		w.Writeln("  lua_pushvalue (L, -2);");
+		w.Writeln("  luaL_setfuncs (L, g_%sInstanceMetamethods, 1);", NameSpace);
+		w.Writeln("  lua_newtable (L);");
+		w.Writeln("  lua_pushvalue (L, -3);");
+		w.Writeln("  luaL_setfuncs (L, g_%s%sMethods, 1);", NameSpace, class.ClassName);
+		w.Writeln("  lua_setfield (L, -2, \"__index\");");
+		w.Writeln("  lua_pop (L, 1);");
+	}
+	w.Writeln("");
+	w.Writeln("  luaL_setfuncs (L, g_%sFunctions, 1);", NameSpace);
+	for _, enum := range component.Enums {
+		w.Writeln("");
+		w.Writeln("  lua_createtable (L, 0, %d);", len (enum.Options));
+		for _, option := range enum.Options {
+			w.Writeln("  lua_pushinteger (L, %d);", option.Value);
+			w.Writeln("  lua_setfield (L, -2, \"%s\");", option.Name);
+		}
+		w.Writeln("  lua_setfield (L, -2, \"%s\");", enum.Name);
+	}
+	w.Writeln("");
+	w.Writeln("  lua_createtable (L, 0, %d);", len (component.Errors.Errors));
+	for _, errorcode := range component.Errors.Errors {
+		w.Writeln("  lua_pushinteger (L, %s_ERROR_%s);", NameSpaceUpper, errorcode.Name);
+		w.Writeln("  lua_setfield (L, -2, \"%s\");", errorcode.Name);
+	}
+	w.Writeln("  lua_setfield (L, -2, \"Errors\");");
+	w.Writeln("  return 1;");
+	w.Writeln("}");
+}
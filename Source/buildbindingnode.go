@@ -42,6 +42,9 @@ import (
 	"strings"
 )
 
+// NodeAPIVersion is the version of Node-API, that the NodeJS-bindings require
+const NodeAPIVersion = 6
+
 // BuildBindingNode builds NodeJS-bindings of a library's API
 func BuildBindingNode(component ComponentDefinition, outputFolder string, indentString string) error {
 	namespace := component.NameSpace
@@ -54,9 +57,12 @@ func BuildBindingNode(component ComponentDefinition, outputFolder string, indent
 	if err != nil {
 		log.Fatal(err)
 	}
-	WriteLicenseHeader(nodeaddonfile, component,
+	err = WriteLicenseHeader(nodeaddonfile, component,
 		fmt.Sprintf("This is an autogenerated C++ Implementation file for the Node addon class \n of %s", libraryname),
 		true)
+	if err != nil {
+		return err
+	}
 
 	NodeWrapperHeaderName := path.Join(outputFolder, baseName + "_nodewrapper.h")
 	log.Printf("Creating \"%s\"", NodeWrapperHeaderName)
@@ -64,9 +70,12 @@ func BuildBindingNode(component ComponentDefinition, outputFolder string, indent
 	if err != nil {
 		log.Fatal(err)
 	}
-	WriteLicenseHeader(nodewrapperhfile, component,
+	err = WriteLicenseHeader(nodewrapperhfile, component,
 		fmt.Sprintf("This is an autogenerated C++ Header file for the Node wrapper class \n of %s", libraryname),
 		true)
+	if err != nil {
+		return err
+	}
 
 	NodeWrapperImplName := path.Join(outputFolder, baseName + "_nodewrapper.cc")
 	log.Printf("Creating \"%s\"", NodeWrapperImplName)
@@ -74,9 +83,12 @@ func BuildBindingNode(component ComponentDefinition, outputFolder string, indent
 	if err != nil {
 		log.Fatal(err)
 	}
-	WriteLicenseHeader(nodewrapperccfile, component,
+	err = WriteLicenseHeader(nodewrapperccfile, component,
 		fmt.Sprintf("This is an autogenerated C++ Implementation file for the Node wrapper class \n of %s", libraryname),
 		true)
+	if err != nil {
+		return err
+	}
 
 	err = buildNodeAddOnImplementation(component, nodeaddonfile, namespace, baseName)
 	if err != nil {
@@ -90,354 +102,455 @@ func BuildBindingNode(component ComponentDefinition, outputFolder string, indent
 		log.Fatal(err)
 	}
 	buildNodeBindingGyp (component, bindinggypfile, indentString);
-	
+
+	NodePackageName := path.Join(outputFolder, "package.json")
+	log.Printf("Creating \"%s\"", NodePackageName)
+	packagefile, err := os.Create(NodePackageName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	buildNodePackageJSON (component, packagefile, indentString);
+
+	NodeDeclarationName := path.Join(outputFolder, baseName + "_nodeaddon.d.ts");
+	log.Printf("Creating \"%s\"", NodeDeclarationName)
+	declarationfile, err := os.Create(NodeDeclarationName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = WriteLicenseHeader(declarationfile, component,
+		fmt.Sprintf("This is an autogenerated TypeScript declaration file for the Node addon \n of %s", libraryname),
+		true)
+	if err != nil {
+		return err
+	}
+	err = buildNodeTypeScriptDeclaration(component, declarationfile, indentString)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	return buildNodeWrapperClass(component, nodewrapperhfile, nodewrapperccfile, namespace, baseName)
 }
 
 func buildNodeAddOnImplementation(component ComponentDefinition, w io.Writer, NameSpace string, BaseName string) error {
 
 	fmt.Fprintf(w, "\n")
-	fmt.Fprintf(w, "#include <node.h>\n")
+	fmt.Fprintf(w, "#include <node_api.h>\n")
 	fmt.Fprintf(w, "#include \"%s_nodewrapper.h\"\n", BaseName)
 	fmt.Fprintf(w, "\n")
-	fmt.Fprintf(w, "using namespace v8;\n")
-	fmt.Fprintf(w, "\n")
-	fmt.Fprintf(w, "void Load%s (const FunctionCallbackInfo<Value>& args)\n", NameSpace)
+	fmt.Fprintf(w, "napi_value Load%s (napi_env env, napi_callback_info info)\n", NameSpace)
 	fmt.Fprintf(w, "{\n")
-	fmt.Fprintf(w, "    Isolate* isolate = Isolate::GetCurrent();\n")
-	fmt.Fprintf(w, "    HandleScope scope(isolate);\n")
-	fmt.Fprintf(w, "    args.GetReturnValue().Set (C%sWrapper::NewInstance());\n", NameSpace)
+	fmt.Fprintf(w, "    try {\n")
+	fmt.Fprintf(w, "        size_t argc = 1;\n")
+	fmt.Fprintf(w, "        napi_value args[1] = { nullptr };\n")
+	fmt.Fprintf(w, "        C%sBaseClass::CheckStatus (env, napi_get_cb_info (env, info, &argc, args, nullptr, nullptr));\n", NameSpace)
+	fmt.Fprintf(w, "        return C%sWrapper::NewInstance (env, args[0]);\n", NameSpace)
+	fmt.Fprintf(w, "    } catch (E%sException & E) {\n", NameSpace)
+	fmt.Fprintf(w, "        C%sBaseClass::RaiseError (env, E.what(), E.getErrorCode ());\n", NameSpace)
+	fmt.Fprintf(w, "    } catch (std::exception & E) {\n")
+	fmt.Fprintf(w, "        C%sBaseClass::RaiseError (env, E.what());\n", NameSpace)
+	fmt.Fprintf(w, "    }\n")
+	fmt.Fprintf(w, "    return nullptr;\n")
 	fmt.Fprintf(w, "}\n")
 	fmt.Fprintf(w, "\n")
-	fmt.Fprintf(w, "void InitAll(Handle<Object> exports, Handle<Object> module)\n")
+	fmt.Fprintf(w, "napi_value InitAll (napi_env env, napi_value exports)\n")
 	fmt.Fprintf(w, "{\n")
+	fmt.Fprintf(w, "    try {\n")
+	fmt.Fprintf(w, "        // The module exports the function, that loads the library\n")
+	fmt.Fprintf(w, "        napi_value loadFunction = nullptr;\n")
+	fmt.Fprintf(w, "        C%sBaseClass::CheckStatus (env, napi_create_function (env, \"Load%s\", NAPI_AUTO_LENGTH, Load%s, nullptr, &loadFunction));\n", NameSpace, NameSpace, NameSpace)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "        // The classes are properties of the function, e.g. for instanceof\n")
+	fmt.Fprintf(w, "        C%sBaseClass::InitError (env, loadFunction);\n", NameSpace)
 	for i := 0; i < len(component.Classes); i++ {
 		class := component.Classes[i]
-		fmt.Fprintf(w, "    C%s%s::Init();\n", NameSpace, class.ClassName)
+		fmt.Fprintf(w, "        C%s%s::Init (env, loadFunction);\n", NameSpace, class.ClassName)
 	}
-	fmt.Fprintf(w, "    C%sWrapper::Init();\n", NameSpace)
-	fmt.Fprintf(w, "    NODE_SET_METHOD(module, \"exports\", Load%s);\n", NameSpace)
+	fmt.Fprintf(w, "        C%sWrapper::Init (env, loadFunction);\n", NameSpace)
+	if component.HasAsyncMethods() {
+		fmt.Fprintf(w, "        C%sCallbackScope::InitExit (env);\n", NameSpace)
+	}
+	for i := 0; i < len(component.Classes); i++ {
+		class := component.Classes[i]
+		if class.ParentClass != "" {
+			fmt.Fprintf(w, "        C%sBaseClass::InitParentClass (env, loadFunction, \"%s\", \"%s\");\n", NameSpace, class.ClassName, class.ParentClass)
+		}
+	}
+	fmt.Fprintf(w, "        return loadFunction;\n")
+	fmt.Fprintf(w, "    } catch (std::exception & E) {\n")
+	fmt.Fprintf(w, "        C%sBaseClass::RaiseError (env, E.what());\n", NameSpace)
+	fmt.Fprintf(w, "    }\n")
+	fmt.Fprintf(w, "    return exports;\n")
 	fmt.Fprintf(w, "}\n")
 	fmt.Fprintf(w, "\n")
 
-	fmt.Fprintf(w, "NODE_MODULE(%s_nodeaddon, InitAll)\n", strings.ToLower(NameSpace))
+	fmt.Fprintf(w, "NAPI_MODULE(NODE_GYP_MODULE_NAME, InitAll)\n")
 	fmt.Fprintf(w, "\n")
 
 	return nil
 }
 
-func writeNodeMethodImplementation(method ComponentDefinitionMethod, implw io.Writer, NameSpace string, ClassName string, isGlobal bool) error {
+// nodeMethodVariable is a local variable of a method, and a member of the worker of its asynchronous variant
+type nodeMethodVariable struct {
+	Type string
+	Name string
+	Value string
+	Arguments string
+	AsyncArguments string
+}
 
-	returnvariablecount := 0
-	returndeclaration := ""
-	inputdeclaration := ""
-	inputcheck := ""
-	returncode := ""
-	functioncode := "";
-	requiresInitCall := false;
+// nodeMethodBuffer is the buffer of an output, which is allocated once the library has returned its size
+type nodeMethodBuffer struct {
+	Declarations []string
+	Member nodeMethodVariable
+	Allocation string
+}
 
-	spacing := "        "
+// nodeMethodCode is the code of a method, which the synchronous and the asynchronous variant of the method share
+type nodeMethodCode struct {
+	InputCount int
+	InputVariables []nodeMethodVariable
+	ReturnVariables []nodeMethodVariable
+	Buffers []nodeMethodBuffer
+	RequiresInitCall bool
+	InitCallParameters string
+	CallParameters string
+	AsyncCallParameters string
+	KeepCode []string
+	ReturnCode []string
+	AsyncReturnCode []string
+	ReturnValues []string
+	ReturnNames []string
+}
+
+// getNodeMethodCode converts the parameters of a method. The AbortSignal of an asynchronous call is passed to the
+// callback scope of its cancellation callback.
+func getNodeMethodCode(method ComponentDefinitionMethod, NameSpace string, ClassName string, cancellationParamName string) (nodeMethodCode, error) {
+	var code nodeMethodCode
+
+	signalArgument := "nullptr"
+	for _, param := range method.Params {
+		if param.ParamPass == "in" {
+			code.InputCount = code.InputCount + 1
+		}
+	}
+	if cancellationParamName != "" {
+		signalArgument = fmt.Sprintf("args[%d]", code.InputCount)
+	}
+
+	inputcount := 0
+	functionTypes := make(map[string]bool)
 
-	callParameters := ""
-	initCallParameters := ""
-	
 	for k := 0; k < len(method.Params); k++ {
-	
+
 		initCallParameter := "";
 		callParameter := "";
-	
+		asyncCallParameter := "";
+
 		param := method.Params[k]
 		switch param.ParamPass {
 		case "in":
 
-			inputcheckfunction := ""
+			argument := fmt.Sprintf("args[%d]", inputcount)
+			errorMessage := fmt.Sprintf("Expected %s parameter %d (%s)", param.ParamType, inputcount, param.ParamName)
+			inputcount = inputcount + 1
 
 			switch param.ParamType {
-			case "uint8":
-				inputcheckfunction = "IsUint32"
-				inputdeclaration = inputdeclaration + fmt.Sprintf("%sunsigned char n%s = (unsigned char) args[%d]->Uint32Value ();\n", spacing, param.ParamName, k)
+			case "uint8", "uint16", "uint32":
+				code.InputVariables = append(code.InputVariables, nodeMethodVariable{Type: fmt.Sprintf("%s_%s", NameSpace, param.ParamType), Name: "n" + param.ParamName, Value: fmt.Sprintf("(%s_%s) getUInt32 (env, %s, \"%s\")", NameSpace, param.ParamType, argument, errorMessage)})
 				callParameter = "n" + param.ParamName;
-				initCallParameter = callParameter;
-
-			case "uint16":
-				inputcheckfunction = "IsUint32"
-				inputdeclaration = inputdeclaration + fmt.Sprintf("%sunsigned short n%s = (unsigned short) args[%d]->Uint32Value ();\n", spacing, param.ParamName, k)
-				callParameter = "n" + param.ParamName
-				initCallParameter = callParameter;
-
-			case "uint32":
-				inputcheckfunction = "IsUint32"
-				inputdeclaration = inputdeclaration + fmt.Sprintf("%sunsigned int n%s = (unsigned int) args[%d]->IntegerValue ();\n", spacing, param.ParamName, k)
-				callParameter = "n" + param.ParamName
-				initCallParameter = callParameter;
 
-			case "uint64":
-				inputcheckfunction = "IsNumber"
-				inputdeclaration = inputdeclaration + fmt.Sprintf("%sunsigned long long n%s = (unsigned long long) args[%d]->IntegerValue ();\n", spacing, param.ParamName, k)
-				callParameter = "n" + param.ParamName
-				initCallParameter = callParameter;
-
-			case "int8":
-				inputcheckfunction = "IsInt32"
-				inputdeclaration = inputdeclaration + fmt.Sprintf("%s char n%s = (char) args[%d]->Int32Value ();\n", spacing, param.ParamName, k)
+			case "int8", "int16", "int32":
+				code.InputVariables = append(code.InputVariables, nodeMethodVariable{Type: fmt.Sprintf("%s_%s", NameSpace, param.ParamType), Name: "n" + param.ParamName, Value: fmt.Sprintf("(%s_%s) getInt32 (env, %s, \"%s\")", NameSpace, param.ParamType, argument, errorMessage)})
 				callParameter = "n" + param.ParamName;
-				initCallParameter = callParameter;
-
-			case "int16":
-				inputcheckfunction = "IsInt32"
-				inputdeclaration = inputdeclaration + fmt.Sprintf("%s short n%s = (short) args[%d]->Int32Value ();\n", spacing, param.ParamName, k)
-				callParameter = "n" + param.ParamName
-				initCallParameter = callParameter;
 
-			case "int32":
-				inputcheckfunction = "IsInt32"
-				inputdeclaration = inputdeclaration + fmt.Sprintf("%s int n%s = (int) args[%d]->IntegerValue ();\n", spacing, param.ParamName, k)
+			case "uint64":
+				code.InputVariables = append(code.InputVariables, nodeMethodVariable{Type: NameSpace + "_uint64", Name: "n" + param.ParamName, Value: fmt.Sprintf("getUInt64 (env, %s, \"%s\")", argument, errorMessage)})
 				callParameter = "n" + param.ParamName
-				initCallParameter = callParameter;
 
 			case "int64":
-				inputcheckfunction = "IsNumber"
-				inputdeclaration = inputdeclaration + fmt.Sprintf("%slong long n%s = (long long) args[%d]->IntegerValue ();\n", spacing, param.ParamName, k)
+				code.InputVariables = append(code.InputVariables, nodeMethodVariable{Type: NameSpace + "_int64", Name: "n" + param.ParamName, Value: fmt.Sprintf("getInt64 (env, %s, \"%s\")", argument, errorMessage)})
 				callParameter = "n" + param.ParamName
-				initCallParameter = callParameter;
 
 			case "string":
-				inputcheckfunction = "IsString"
-
-				inputdeclaration = inputdeclaration + fmt.Sprintf("%sv8::String::Utf8Value s%sUTF8 (args[%d]->ToString());\n", spacing, param.ParamName, k)
-				inputdeclaration = inputdeclaration + fmt.Sprintf("%sstd::string s%s = *s%sUTF8;\n", spacing, param.ParamName, param.ParamName)
+				code.InputVariables = append(code.InputVariables, nodeMethodVariable{Type: "std::string", Name: "s" + param.ParamName, Value: fmt.Sprintf("getString (env, %s, \"%s\")", argument, errorMessage)})
 				callParameter = "s" + param.ParamName + ".c_str()"
-				initCallParameter = callParameter;
 
 			case "basicarray":
-				callParameter = "nullptr, 0";
-				initCallParameter = callParameter;
+				elementType, err := getCParameterTypeName(param.ParamClass, NameSpace, "")
+				if err != nil {
+					return code, err
+				}
+				getElement, err := getNodeElementGetter(param.ParamClass)
+				if err != nil {
+					return code, err
+				}
+				code.InputVariables = append(code.InputVariables, nodeMethodVariable{Type: fmt.Sprintf("std::unique_ptr<%s[]>", elementType), Name: fmt.Sprintf("p%sElements", param.ParamName)})
+				code.InputVariables = append(code.InputVariables, nodeMethodVariable{Type: NameSpace + "_uint64", Name: fmt.Sprintf("n%sCount", param.ParamName), Value: "0"})
+				arrayType := getNodeTypedArrayType(param.ParamClass)
+				getter := ""
+				if arrayType != "" {
+					getter = fmt.Sprintf("getTypedArray<%s> (env, %s, %s, %s, p%sElements, n%sCount, \"%s\")", elementType, argument, arrayType, getElement, param.ParamName, param.ParamName, errorMessage)
+				} else {
+					getter = fmt.Sprintf("getArray<%s> (env, %s, %s, p%sElements, n%sCount, \"%s\")", elementType, argument, getElement, param.ParamName, param.ParamName, errorMessage)
+				}
+				code.InputVariables = append(code.InputVariables, nodeMethodVariable{Type: fmt.Sprintf("const %s *", elementType), Name: fmt.Sprintf("p%sBuffer", param.ParamName), Value: getter})
+				callParameter = fmt.Sprintf("n%sCount, p%sBuffer", param.ParamName, param.ParamName);
 
 			case "structarray":
-				callParameter = "nullptr, 0";
-				initCallParameter = callParameter;
+				code.InputVariables = append(code.InputVariables, nodeMethodVariable{Type: fmt.Sprintf("std::unique_ptr<s%s%s[]>", NameSpace, param.ParamClass), Name: fmt.Sprintf("p%sElements", param.ParamName)})
+				code.InputVariables = append(code.InputVariables, nodeMethodVariable{Type: NameSpace + "_uint64", Name: fmt.Sprintf("n%sCount", param.ParamName), Value: "0"})
+				code.InputVariables = append(code.InputVariables, nodeMethodVariable{Type: fmt.Sprintf("const s%s%s *", NameSpace, param.ParamClass), Name: fmt.Sprintf("p%sBuffer", param.ParamName), Value: fmt.Sprintf("getArray<s%s%s> (env, %s, getStruct%s, p%sElements, n%sCount, \"%s\")", NameSpace, param.ParamClass, argument, param.ParamClass, param.ParamName, param.ParamName, errorMessage)})
+				callParameter = fmt.Sprintf("n%sCount, p%sBuffer", param.ParamName, param.ParamName);
 
 			case "functiontype":
-				callParameter = "nullptr";
-				initCallParameter = callParameter;
-				
+				// The callback calls the innermost JS function of its type, so each type may only be passed once. The
+				// instance keeps the JS function once the call has succeeded, as the library may call it later.
+				if functionTypes[param.ParamClass] {
+					return code, fmt.Errorf("can not pass several callbacks of type \"%s\" to %s.%s in NodeJS", param.ParamClass, ClassName, method.MethodName)
+				}
+				functionTypes[param.ParamClass] = true
+				signal := "nullptr"
+				if param.ParamName == cancellationParamName {
+					signal = signalArgument
+				}
+				code.InputVariables = append(code.InputVariables, nodeMethodVariable{Type: fmt.Sprintf("C%sCallbackScope", NameSpace), Name: "callbackScope" + param.ParamName,
+					Arguments: fmt.Sprintf("env, %s, \"%s\", \"%s\"", argument, param.ParamClass, errorMessage),
+					AsyncArguments: fmt.Sprintf("env, %s, %s, \"%s\", \"%s\"", argument, signal, param.ParamClass, errorMessage)})
+				callParameter = fmt.Sprintf("getCallback%s (callbackScope%s)", param.ParamClass, param.ParamName);
+				code.KeepCode = append(code.KeepCode, fmt.Sprintf("pThis->keepCallbackSlot (\"%s.%s\", callbackScope%s.releaseSlot ());", method.MethodName, param.ParamName, param.ParamName))
+
 			case "bool":
-				inputcheckfunction = "IsBoolean"
-				inputdeclaration = inputdeclaration + fmt.Sprintf("%sbool b%s = args[%d]->BooleanValue ();\n", spacing, param.ParamName, k)
+				code.InputVariables = append(code.InputVariables, nodeMethodVariable{Type: "bool", Name: "b" + param.ParamName, Value: fmt.Sprintf("getBool (env, %s, \"%s\")", argument, errorMessage)})
 				callParameter = "b" + param.ParamName
-				initCallParameter = callParameter;
 
 			case "single":
-				inputcheckfunction = "IsNumber"
-				inputdeclaration = inputdeclaration + fmt.Sprintf("%sfloat f%s = (float) args[%d]->NumberValue ();\n", spacing, param.ParamName, k)
+				code.InputVariables = append(code.InputVariables, nodeMethodVariable{Type: NameSpace + "_single", Name: "f" + param.ParamName, Value: fmt.Sprintf("(%s_single) getDouble (env, %s, \"%s\")", NameSpace, argument, errorMessage)})
 				callParameter = "f" + param.ParamName
-				initCallParameter = callParameter;
 
 			case "double":
-				inputcheckfunction = "IsNumber"
-				inputdeclaration = inputdeclaration + fmt.Sprintf("%sdouble d%s = (double) args[%d]->NumberValue ();\n", spacing, param.ParamName, k)
+				code.InputVariables = append(code.InputVariables, nodeMethodVariable{Type: NameSpace + "_double", Name: "d" + param.ParamName, Value: fmt.Sprintf("getDouble (env, %s, \"%s\")", argument, errorMessage)})
 				callParameter = "d" + param.ParamName
-				initCallParameter = callParameter;
 
 			case "enum":
-				inputcheckfunction = "IsUint32"
-				inputdeclaration = inputdeclaration + fmt.Sprintf("%sunsigned int e%s = (unsigned int) args[%d]->IntegerValue ();\n", spacing, param.ParamName, k)
-				callParameter = fmt.Sprintf("(e%s%s) e%s", NameSpace, param.ParamClass, param.ParamName)
-				initCallParameter = callParameter;
+				code.InputVariables = append(code.InputVariables, nodeMethodVariable{Type: fmt.Sprintf("e%s%s", NameSpace, param.ParamClass), Name: "e" + param.ParamName, Value: fmt.Sprintf("(e%s%s) getInt32 (env, %s, \"%s\")", NameSpace, param.ParamClass, argument, errorMessage)})
+				callParameter = "e" + param.ParamName
 
 			case "struct":
-				callParameter = "nullptr";
-				initCallParameter = callParameter;
-				//return fmt.Errorf("parameter type \"%s\" not yet supported for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName)
+				code.InputVariables = append(code.InputVariables, nodeMethodVariable{Type: fmt.Sprintf("s%s%s", NameSpace, param.ParamClass), Name: "s" + param.ParamName, Value: fmt.Sprintf("getStruct%s (env, %s, \"%s\")", param.ParamClass, argument, errorMessage)})
+				callParameter = "&s" + param.ParamName;
 
 			case "handle":
-				inputcheckfunction = "IsObject"
-
-				inputdeclaration = inputdeclaration + fmt.Sprintf("%sLocal<Object> obj%s = args[%d]->ToObject();\n", spacing, param.ParamName, k)
-				inputdeclaration = inputdeclaration + fmt.Sprintf("%sC%s%s * instance%s = ObjectWrap::Unwrap<C%s%s>(obj%s);\n", spacing, NameSpace, param.ParamClass, param.ParamName, NameSpace, param.ParamClass, param.ParamName)
-				inputdeclaration = inputdeclaration + fmt.Sprintf("%sif (instance%s == nullptr)\n", spacing, param.ParamName)
-				inputdeclaration = inputdeclaration + fmt.Sprintf("%s    throw std::runtime_error(\"Invalid Object parameter %d (%s)\");\n", spacing, k, param.ParamName)
-				inputdeclaration = inputdeclaration + fmt.Sprintf("%s%sHandle h%s = instance%s->getHandle (args.Holder ());\n", spacing, NameSpace, param.ParamName, param.ParamName)
-
+				code.InputVariables = append(code.InputVariables, nodeMethodVariable{Type: NameSpace + "Handle", Name: "h" + param.ParamName, Value: fmt.Sprintf("getObjectHandle (env, %s, \"%s\")", argument, errorMessage)})
 				callParameter = "h" + param.ParamName
-				initCallParameter = callParameter;
 
 			default:
-				return fmt.Errorf("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName)
-
-			}
+				return code, fmt.Errorf("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName)
 
-			if inputcheckfunction != "" {
-				inputcheck = inputcheck + fmt.Sprintf("%sif (!args[%d]->%s()) {\n", spacing, k, inputcheckfunction)
-				inputcheck = inputcheck + fmt.Sprintf("%s    throw std::runtime_error (\"Expected %s parameter %d (%s)\");\n", spacing, param.ParamType, k, param.ParamName)
-				inputcheck = inputcheck + fmt.Sprintf("%s}\n", spacing)
 			}
+			initCallParameter = callParameter;
 
 		case "out", "return":
 
-			returnvariablecount = returnvariablecount + 1
+			returnvalue := ""
 
 			switch param.ParamType {
-			case "uint8":
-				returndeclaration = returndeclaration + fmt.Sprintf("%sunsigned char nReturn%s = 0;\n", spacing, param.ParamName)
-				callParameter = "&nReturn" + param.ParamName
-				initCallParameter = callParameter;
-
-				returncode = returncode + fmt.Sprintf("%sargs.GetReturnValue().Set(Integer::New (isolate, nReturn%s));\n", spacing, param.ParamName)
-
-			case "uint16":
-				returndeclaration = returndeclaration + fmt.Sprintf("%sunsigned short nReturn%s = 0;\n", spacing, param.ParamName)
+			case "uint8", "uint16", "uint32":
+				code.ReturnVariables = append(code.ReturnVariables, nodeMethodVariable{Type: fmt.Sprintf("%s_%s", NameSpace, param.ParamType), Name: "nReturn" + param.ParamName, Value: "0"})
 				callParameter = "&nReturn" + param.ParamName
 				initCallParameter = callParameter;
 
-				returncode = returncode + fmt.Sprintf("%sargs.GetReturnValue().Set(Integer::New (isolate, nReturn%s));\n", spacing, param.ParamName)
+				returnvalue = fmt.Sprintf("createUInt32 (env, nReturn%s)", param.ParamName)
 
-			case "uint32":
-				returndeclaration = returndeclaration + fmt.Sprintf("%sunsigned int nReturn%s = 0;\n", spacing, param.ParamName)
+			case "int8", "int16", "int32":
+				code.ReturnVariables = append(code.ReturnVariables, nodeMethodVariable{Type: fmt.Sprintf("%s_%s", NameSpace, param.ParamType), Name: "nReturn" + param.ParamName, Value: "0"})
 				callParameter = "&nReturn" + param.ParamName
 				initCallParameter = callParameter;
 
-				returncode = returncode + fmt.Sprintf("%sargs.GetReturnValue().Set(Integer::New (isolate, nReturn%s));\n", spacing, param.ParamName)
+				returnvalue = fmt.Sprintf("createInt32 (env, nReturn%s)", param.ParamName)
 
 			case "uint64":
-				returndeclaration = returndeclaration + fmt.Sprintf("%sunsigned long long nReturn%s = 0;\n", spacing, param.ParamName)
-				callParameter = "&nReturn" + param.ParamName
-				initCallParameter = callParameter;
-
-				returncode = returncode + fmt.Sprintf("%sargs.GetReturnValue().Set(Integer::New (isolate, nReturn%s));\n", spacing, param.ParamName)
-
-			case "int8":
-				returndeclaration = returndeclaration + fmt.Sprintf("%schar nReturn%s = 0;\n", spacing, param.ParamName)
-				callParameter = "&nReturn" + param.ParamName
-				initCallParameter = callParameter;
-
-				returncode = returncode + fmt.Sprintf("%sargs.GetReturnValue().Set(Integer::New (isolate, nReturn%s));\n", spacing, param.ParamName)
-
-			case "int16":
-				returndeclaration = returndeclaration + fmt.Sprintf("%s short nReturn%s = 0;\n", spacing, param.ParamName)
-				callParameter = "&nReturn" + param.ParamName
-				initCallParameter = callParameter;
-
-				returncode = returncode + fmt.Sprintf("%sargs.GetReturnValue().Set(Integer::New (isolate, nReturn%s));\n", spacing, param.ParamName)
-
-			case "int32":
-				returndeclaration = returndeclaration + fmt.Sprintf("%s int nReturn%s = 0;\n", spacing, param.ParamName)
+				code.ReturnVariables = append(code.ReturnVariables, nodeMethodVariable{Type: NameSpace + "_uint64", Name: "nReturn" + param.ParamName, Value: "0"})
 				callParameter = "&nReturn" + param.ParamName
 				initCallParameter = callParameter;
 
-				returncode = returncode + fmt.Sprintf("%sargs.GetReturnValue().Set(Integer::New (isolate, nReturn%s));\n", spacing, param.ParamName)
+				returnvalue = fmt.Sprintf("createUInt64 (env, nReturn%s)", param.ParamName)
 
 			case "int64":
-				returndeclaration = returndeclaration + fmt.Sprintf("%s long long nReturn%s = 0;\n", spacing, param.ParamName)
+				code.ReturnVariables = append(code.ReturnVariables, nodeMethodVariable{Type: NameSpace + "_int64", Name: "nReturn" + param.ParamName, Value: "0"})
 				callParameter = "&nReturn" + param.ParamName
 				initCallParameter = callParameter;
 
-				returncode = returncode + fmt.Sprintf("%sargs.GetReturnValue().Set(Integer::New (isolate, nReturn%s));\n", spacing, param.ParamName)
+				returnvalue = fmt.Sprintf("createInt64 (env, nReturn%s)", param.ParamName)
 
 			case "string":
-				requiresInitCall = true;
-			
-				returndeclaration = returndeclaration + fmt.Sprintf("%sunsigned int bytesNeeded%s = 0;\n", spacing, param.ParamName)
-				returndeclaration = returndeclaration + fmt.Sprintf("%sunsigned int bytesWritten%s = 0;\n", spacing, param.ParamName)
+				code.RequiresInitCall = true;
+
+				code.ReturnVariables = append(code.ReturnVariables, nodeMethodVariable{Type: NameSpace + "_uint32", Name: "bytesNeeded" + param.ParamName, Value: "0"})
+				code.ReturnVariables = append(code.ReturnVariables, nodeMethodVariable{Type: NameSpace + "_uint32", Name: "bytesWritten" + param.ParamName, Value: "0"})
 				initCallParameter = fmt.Sprintf("0, &bytesNeeded%s, nullptr", param.ParamName);
 
-				functioncode = functioncode + fmt.Sprintf("%sstd::vector<char> buffer%s;\n", spacing, param.ParamName)
-				functioncode = functioncode + fmt.Sprintf("%sbuffer%s.resize(bytesNeeded%s + 2);\n", spacing, param.ParamName, param.ParamName)
+				code.Buffers = append(code.Buffers, nodeMethodBuffer{
+					Declarations: []string{fmt.Sprintf("std::vector<char> buffer%s (bytesNeeded%s + 1);", param.ParamName, param.ParamName)},
+					Member: nodeMethodVariable{Type: "std::vector<char>", Name: "buffer" + param.ParamName},
+					Allocation: fmt.Sprintf("buffer%s.resize (bytesNeeded%s + 1);", param.ParamName, param.ParamName)})
 
-				callParameter = fmt.Sprintf("bytesNeeded%s, &bytesWritten%s, &buffer%s[0]", param.ParamName, param.ParamName, param.ParamName)
+				callParameter = fmt.Sprintf("bytesNeeded%s + 1, &bytesWritten%s, &buffer%s[0]", param.ParamName, param.ParamName, param.ParamName)
+
+				code.ReturnCode = append(code.ReturnCode, fmt.Sprintf("buffer%s[bytesNeeded%s] = 0;", param.ParamName, param.ParamName))
+				code.AsyncReturnCode = append(code.AsyncReturnCode, fmt.Sprintf("buffer%s[bytesNeeded%s] = 0;", param.ParamName, param.ParamName))
+				returnvalue = fmt.Sprintf("createString (env, &buffer%s[0])", param.ParamName)
 
-				returncode = returncode + fmt.Sprintf("%sbuffer%s[bytesNeeded%s + 1] = 0;\n", spacing, param.ParamName, param.ParamName);
-				returncode = returncode + fmt.Sprintf("%sargs.GetReturnValue().Set(String::NewFromUtf8 (isolate, &buffer%s[0]));\n", spacing, param.ParamName);
-			
 			case "bool":
-				returndeclaration = returndeclaration + fmt.Sprintf("%sbool bReturn%s = false;\n", spacing, param.ParamName)
+				code.ReturnVariables = append(code.ReturnVariables, nodeMethodVariable{Type: "bool", Name: "bReturn" + param.ParamName, Value: "false"})
 				callParameter = "&bReturn" + param.ParamName
 				initCallParameter = callParameter;
 
-				returncode = returncode + fmt.Sprintf("%sargs.GetReturnValue().Set(Boolean::New (isolate, bReturn%s));\n", spacing, param.ParamName)
+				returnvalue = fmt.Sprintf("createBool (env, bReturn%s)", param.ParamName)
 
 			case "single":
-				returndeclaration = returndeclaration + fmt.Sprintf("%sfloat fReturn%s = 0.0f;\n", spacing, param.ParamName)
+				code.ReturnVariables = append(code.ReturnVariables, nodeMethodVariable{Type: NameSpace + "_single", Name: "fReturn" + param.ParamName, Value: "0.0f"})
 				callParameter = "&fReturn" + param.ParamName
 				initCallParameter = callParameter;
 
-				returncode = returncode + fmt.Sprintf("%sargs.GetReturnValue().Set(Number::New (isolate, fReturn%s));\n", spacing, param.ParamName)
+				returnvalue = fmt.Sprintf("createDouble (env, fReturn%s)", param.ParamName)
 
 			case "double":
-				returndeclaration = returndeclaration + fmt.Sprintf("%sdouble dReturn%s = 0.0;\n", spacing, param.ParamName)
+				code.ReturnVariables = append(code.ReturnVariables, nodeMethodVariable{Type: NameSpace + "_double", Name: "dReturn" + param.ParamName, Value: "0.0"})
 				callParameter = "&dReturn" + param.ParamName
 				initCallParameter = callParameter;
 
-				returncode = returncode + fmt.Sprintf("%sargs.GetReturnValue().Set(Number::New (isolate, dReturn%s));\n", spacing, param.ParamName)
+				returnvalue = fmt.Sprintf("createDouble (env, dReturn%s)", param.ParamName)
 
 			case "enum":
-				returndeclaration = returndeclaration + fmt.Sprintf("%se%s%s eReturn%s;\n", spacing, NameSpace, param.ParamClass, param.ParamName)
+				code.ReturnVariables = append(code.ReturnVariables, nodeMethodVariable{Type: fmt.Sprintf("e%s%s", NameSpace, param.ParamClass), Name: "eReturn" + param.ParamName, Value: fmt.Sprintf("(e%s%s) 0", NameSpace, param.ParamClass)})
 				callParameter = "&eReturn" + param.ParamName
 				initCallParameter = callParameter;
 
-				returncode = returncode + fmt.Sprintf("%sargs.GetReturnValue().Set(Integer::New (isolate, (int) eReturn%s));\n", spacing, param.ParamName)
+				returnvalue = fmt.Sprintf("createInt32 (env, (%s_int32) eReturn%s)", NameSpace, param.ParamName)
 
 			case "struct":
-				returndeclaration = returndeclaration + fmt.Sprintf("%ss%s%s sReturn%s;\n", spacing, NameSpace, param.ParamClass, param.ParamName)
+				code.ReturnVariables = append(code.ReturnVariables, nodeMethodVariable{Type: fmt.Sprintf("s%s%s", NameSpace, param.ParamClass), Name: "sReturn" + param.ParamName})
 				callParameter = "&sReturn" + param.ParamName
 				initCallParameter = callParameter;
 
-				//return fmt.Errorf("can not return struct \"%s\" for %s.%s (%s) yet in nodejs", param.ParamType, ClassName, method.MethodName, param.ParamName)
+				returnvalue = fmt.Sprintf("createStruct%s (env, sReturn%s)", param.ParamClass, param.ParamName)
 
 			case "basicarray":
-				callParameter = "nullptr, 0, nullptr";
-				initCallParameter = callParameter;
+				code.RequiresInitCall = true;
+
+				elementType, err := getCParameterTypeName(param.ParamClass, NameSpace, "")
+				if err != nil {
+					return code, err
+				}
+				code.ReturnVariables = append(code.ReturnVariables, nodeMethodVariable{Type: NameSpace + "_uint64", Name: fmt.Sprintf("n%sNeededCount", param.ParamName), Value: "0"})
+				initCallParameter = fmt.Sprintf("0, &n%sNeededCount, nullptr", param.ParamName);
+
+				bufferMember := nodeMethodVariable{Type: fmt.Sprintf("std::unique_ptr<%s[]>", elementType), Name: fmt.Sprintf("p%sBuffer", param.ParamName)}
+				bufferAllocation := fmt.Sprintf("p%sBuffer.reset (new %s[(size_t) n%sNeededCount]);", param.ParamName, elementType, param.ParamName)
+
+				// Basic arrays are returned as TypedArray, into whose memory the library writes the elements
+				arrayType := getNodeTypedArrayType(param.ParamClass)
+				if arrayType != "" {
+					code.Buffers = append(code.Buffers, nodeMethodBuffer{
+						Declarations: []string{
+							fmt.Sprintf("%s * p%sBuffer = nullptr;", elementType, param.ParamName),
+							fmt.Sprintf("napi_value array%s = createTypedArray (env, %s, n%sNeededCount, sizeof (%s), (void **) &p%sBuffer);", param.ParamName, arrayType, param.ParamName, elementType, param.ParamName)},
+						Member: bufferMember,
+						Allocation: bufferAllocation})
+					callParameter = fmt.Sprintf("n%sNeededCount, &n%sNeededCount, p%sBuffer", param.ParamName, param.ParamName, param.ParamName)
+					asyncCallParameter = fmt.Sprintf("n%sNeededCount, &n%sNeededCount, p%sBuffer.get ()", param.ParamName, param.ParamName, param.ParamName)
+
+					// The TypedArray can only be created on the main thread
+					code.AsyncReturnCode = append(code.AsyncReturnCode,
+						fmt.Sprintf("void * p%sData = nullptr;", param.ParamName),
+						fmt.Sprintf("napi_value array%s = createTypedArray (env, %s, n%sNeededCount, sizeof (%s), &p%sData);", param.ParamName, arrayType, param.ParamName, elementType, param.ParamName),
+						fmt.Sprintf("if (n%sNeededCount > 0)", param.ParamName),
+						fmt.Sprintf("    memcpy (p%sData, p%sBuffer.get (), (size_t) n%sNeededCount * sizeof (%s));", param.ParamName, param.ParamName, param.ParamName, elementType))
+					returnvalue = "array" + param.ParamName
+				} else {
+					createElement, err := getNodeElementCreator(param.ParamClass)
+					if err != nil {
+						return code, err
+					}
+					code.Buffers = append(code.Buffers, nodeMethodBuffer{
+						Declarations: []string{fmt.Sprintf("std::unique_ptr<%s[]> p%sBuffer (new %s[(size_t) n%sNeededCount]);", elementType, param.ParamName, elementType, param.ParamName)},
+						Member: bufferMember,
+						Allocation: bufferAllocation})
+					callParameter = fmt.Sprintf("n%sNeededCount, &n%sNeededCount, p%sBuffer.get ()", param.ParamName, param.ParamName, param.ParamName)
+					returnvalue = fmt.Sprintf("createArray (env, p%sBuffer.get (), n%sNeededCount, %s)", param.ParamName, param.ParamName, createElement)
+				}
 
 			case "structarray":
-				callParameter = "nullptr, 0, nullptr";
-				initCallParameter = callParameter;
+				code.RequiresInitCall = true;
+
+				code.ReturnVariables = append(code.ReturnVariables, nodeMethodVariable{Type: NameSpace + "_uint64", Name: fmt.Sprintf("n%sNeededCount", param.ParamName), Value: "0"})
+				initCallParameter = fmt.Sprintf("0, &n%sNeededCount, nullptr", param.ParamName);
+
+				code.Buffers = append(code.Buffers, nodeMethodBuffer{
+					Declarations: []string{fmt.Sprintf("std::unique_ptr<s%s%s[]> p%sBuffer (new s%s%s[(size_t) n%sNeededCount]);", NameSpace, param.ParamClass, param.ParamName, NameSpace, param.ParamClass, param.ParamName)},
+					Member: nodeMethodVariable{Type: fmt.Sprintf("std::unique_ptr<s%s%s[]>", NameSpace, param.ParamClass), Name: fmt.Sprintf("p%sBuffer", param.ParamName)},
+					Allocation: fmt.Sprintf("p%sBuffer.reset (new s%s%s[(size_t) n%sNeededCount]);", param.ParamName, NameSpace, param.ParamClass, param.ParamName)})
+				callParameter = fmt.Sprintf("n%sNeededCount, &n%sNeededCount, p%sBuffer.get ()", param.ParamName, param.ParamName, param.ParamName)
+				returnvalue = fmt.Sprintf("createArray (env, p%sBuffer.get (), n%sNeededCount, createStruct%s)", param.ParamName, param.ParamName, param.ParamClass)
 
 			case "functiontype":
-				callParameter = "nullptr";
-				initCallParameter = callParameter;
-				
+				return code, fmt.Errorf("can not return callback \"%s\" for %s.%s (%s) in NodeJS", param.ParamClass, ClassName, method.MethodName, param.ParamName)
+
 			case "handle":
-				returndeclaration = returndeclaration + fmt.Sprintf("%s%sHandle hReturn%s = nullptr;\n", spacing, NameSpace, param.ParamName)
+				code.ReturnVariables = append(code.ReturnVariables, nodeMethodVariable{Type: NameSpace + "Handle", Name: "hReturn" + param.ParamName, Value: "nullptr"})
 				callParameter = "&hReturn" + param.ParamName
 				initCallParameter = callParameter;
 
-				returncode = returncode + fmt.Sprintf("%sLocal<Object> instanceObj%s = C%s%s::NewInstance (args.Holder(), hReturn%s);\n", spacing, param.ParamName, NameSpace, param.ParamClass, param.ParamName)
-				returncode = returncode + fmt.Sprintf("%sargs.GetReturnValue().Set(instanceObj%s);\n", spacing, param.ParamName)
+				returnvalue = fmt.Sprintf("C%s%s::NewInstance (env, pThis->getSharedWrapperTable (), hReturn%s)", NameSpace, param.ParamClass, param.ParamName)
 
 			default:
-				return fmt.Errorf("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName)
+				return code, fmt.Errorf("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName)
+
+			}
 
+			if returnvalue != "" {
+				code.ReturnValues = append(code.ReturnValues, returnvalue)
+				code.ReturnNames = append(code.ReturnNames, param.ParamName)
 			}
 		}
-		
-		if callParameters != "" {
-			callParameters = callParameters + ", " + callParameter;
+
+		if asyncCallParameter == "" {
+			asyncCallParameter = callParameter
+		}
+
+		if code.CallParameters != "" {
+			code.CallParameters = code.CallParameters + ", " + callParameter;
+			code.AsyncCallParameters = code.AsyncCallParameters + ", " + asyncCallParameter;
 		} else {
-			callParameters = callParameters + callParameter;
+			code.CallParameters = code.CallParameters + callParameter;
+			code.AsyncCallParameters = code.AsyncCallParameters + asyncCallParameter;
 		}
 
-		if initCallParameters != "" {
-			initCallParameters = initCallParameters + ", " + initCallParameter;
+		if code.InitCallParameters != "" {
+			code.InitCallParameters = code.InitCallParameters + ", " + initCallParameter;
 		} else {
-			initCallParameters = initCallParameters + initCallParameter;
+			code.InitCallParameters = code.InitCallParameters + initCallParameter;
 		}
-		
-	}
 
-	fmt.Fprintf(implw, "\n")
+	}
 
-	fmt.Fprintf(implw, "void C%s%s::%s (const FunctionCallbackInfo<Value>& args) \n", NameSpace, ClassName, method.MethodName)
-	fmt.Fprintf(implw, "{\n")
-	fmt.Fprintf(implw, "    Isolate* isolate = Isolate::GetCurrent();\n")
-	fmt.Fprintf(implw, "    HandleScope scope(isolate);\n")
-	fmt.Fprintf(implw, "    try {\n")
+	return code, nil
+}
 
-	fmt.Fprintf(implw, inputcheck)
-	fmt.Fprintf(implw, inputdeclaration)
-	
-	fmt.Fprintf(implw, returndeclaration)
+// getDeclaration returns the declaration of the variable in the method
+func (variable nodeMethodVariable) getDeclaration() string {
+	if variable.Arguments != "" {
+		return fmt.Sprintf("%s %s (%s);", variable.Type, variable.Name, variable.Arguments)
+	}
+	if variable.Value != "" {
+		return fmt.Sprintf("%s %s = %s;", variable.Type, variable.Name, variable.Value)
+	}
+	return fmt.Sprintf("%s %s;", variable.Type, variable.Name)
+}
 
-	fmt.Fprintf(implw, "%ss%sDynamicWrapperTable * wrapperTable = C%sBaseClass::getDynamicWrapperTable (args.Holder());\n", spacing, NameSpace, NameSpace)
+// writeNodeLibraryCall writes the call of the library function, which returns the sizes of the buffers first, if required
+func writeNodeLibraryCall(method ComponentDefinitionMethod, code nodeMethodCode, implw io.Writer, NameSpace string, ClassName string, isGlobal bool, doErrorMessages bool, spacing string, bufferLines []string, callParameters string) {
+	fmt.Fprintf(implw, "%ss%sDynamicWrapperTable * wrapperTable = pThis->getDynamicWrapperTable ();\n", spacing, NameSpace)
 	fmt.Fprintf(implw, "%sif (wrapperTable == nullptr)\n", spacing)
 	fmt.Fprintf(implw, "%s    throw std::runtime_error (\"Could not get wrapper table for %s method %s.\");\n", spacing, NameSpace, method.MethodName)
 
@@ -449,9 +562,19 @@ func writeNodeMethodImplementation(method ComponentDefinitionMethod, implw io.Wr
 		fmt.Fprintf(implw, "%s    throw std::runtime_error (\"Could not call %s method %s::%s.\");\n", spacing, NameSpace, ClassName, method.MethodName)
 	}
 
-	
-	if (requiresInitCall) {
-	
+
+	checkErrorParameters := ""
+	if doErrorMessages {
+		if isGlobal {
+			checkErrorParameters = "wrapperTable, nullptr, "
+		} else {
+			checkErrorParameters = "wrapperTable, pThis->getHandle (), "
+		}
+	}
+
+	if (code.RequiresInitCall) {
+
+		initCallParameters := code.InitCallParameters
 		if isGlobal {
 			fmt.Fprintf(implw, "%s%sResult initErrorCode = wrapperTable->m_%s (%s);\n", spacing, NameSpace, method.MethodName, initCallParameters)
 
@@ -459,15 +582,17 @@ func writeNodeMethodImplementation(method ComponentDefinitionMethod, implw io.Wr
 			if initCallParameters != "" {
 				initCallParameters = ", " + initCallParameters
 			}
-			fmt.Fprintf(implw, "%s%sResult initErrorCode = wrapperTable->m_%s_%s (C%sBaseClass::getHandle (args.Holder())%s);\n", spacing, NameSpace, ClassName, method.MethodName, NameSpace, initCallParameters)
+			fmt.Fprintf(implw, "%s%sResult initErrorCode = wrapperTable->m_%s_%s (pThis->getHandle ()%s);\n", spacing, NameSpace, ClassName, method.MethodName, initCallParameters)
 		}
-	
-	
-		fmt.Fprintf(implw, "%sCheckError (initErrorCode);\n", spacing)
+
+
+		fmt.Fprintf(implw, "%sCheckError (%sinitErrorCode);\n", spacing, checkErrorParameters)
+	}
+
+	for _, line := range bufferLines {
+		fmt.Fprintf(implw, "%s%s\n", spacing, line)
 	}
-	
-	fmt.Fprintf(implw, functioncode)
-	
+
 	if isGlobal {
 		fmt.Fprintf(implw, "%s%sResult errorCode = wrapperTable->m_%s (%s);\n", spacing, NameSpace, method.MethodName, callParameters)
 
@@ -475,17 +600,203 @@ func writeNodeMethodImplementation(method ComponentDefinitionMethod, implw io.Wr
 		if callParameters != "" {
 			callParameters = ", " + callParameters
 		}
-		fmt.Fprintf(implw, "%s%sResult errorCode = wrapperTable->m_%s_%s (C%sBaseClass::getHandle (args.Holder())%s);\n", spacing, NameSpace, ClassName, method.MethodName, NameSpace, callParameters)
+		fmt.Fprintf(implw, "%s%sResult errorCode = wrapperTable->m_%s_%s (pThis->getHandle ()%s);\n", spacing, NameSpace, ClassName, method.MethodName, callParameters)
+	}
+
+
+	fmt.Fprintf(implw, "%sCheckError (%serrorCode);\n", spacing, checkErrorParameters)
+}
+
+// writeNodeReturnValues returns the output parameters, several output parameters as the properties of an object
+func writeNodeReturnValues(code nodeMethodCode, implw io.Writer, spacing string) {
+	if len(code.ReturnValues) == 1 {
+		fmt.Fprintf(implw, "%sreturn %s;\n", spacing, code.ReturnValues[0])
+	}
+	if len(code.ReturnValues) > 1 {
+		fmt.Fprintf(implw, "%snapi_value result = createObject (env);\n", spacing)
+		for i := 0; i < len(code.ReturnValues); i++ {
+			fmt.Fprintf(implw, "%ssetProperty (env, result, \"%s\", %s);\n", spacing, code.ReturnNames[i], code.ReturnValues[i])
+		}
+		fmt.Fprintf(implw, "%sreturn result;\n", spacing)
+	}
+}
+
+func writeNodeMethodImplementation(method ComponentDefinitionMethod, implw io.Writer, NameSpace string, ClassName string, isGlobal bool, doErrorMessages bool, hasCallbacks bool) error {
+
+	spacing := "        "
+
+	code, err := getNodeMethodCode(method, NameSpace, ClassName, "")
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(implw, "\n")
+
+	fmt.Fprintf(implw, "napi_value C%s%s::%s (napi_env env, napi_callback_info info)\n", NameSpace, ClassName, method.MethodName)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    try {\n")
+
+	fmt.Fprintf(implw, "%snapi_value thisObject = nullptr;\n", spacing)
+	if code.InputCount > 0 {
+		fmt.Fprintf(implw, "%ssize_t argc = %d;\n", spacing, code.InputCount)
+		fmt.Fprintf(implw, "%snapi_value args[%d];\n", spacing, code.InputCount)
+		fmt.Fprintf(implw, "%sCheckStatus (env, napi_get_cb_info (env, info, &argc, args, &thisObject, nullptr));\n", spacing)
+	} else {
+		fmt.Fprintf(implw, "%sCheckStatus (env, napi_get_cb_info (env, info, nullptr, nullptr, &thisObject, nullptr));\n", spacing)
+	}
+	fmt.Fprintf(implw, "%sC%sBaseClass * pThis = getInstance (env, thisObject);\n", spacing, NameSpace)
+	if hasCallbacks {
+		fmt.Fprintf(implw, "%sC%sSynchronousCall synchronousCall (env);\n", spacing, NameSpace)
+	}
+
+	for _, variable := range code.InputVariables {
+		fmt.Fprintf(implw, "%s%s\n", spacing, variable.getDeclaration())
+	}
+	for _, variable := range code.ReturnVariables {
+		fmt.Fprintf(implw, "%s%s\n", spacing, variable.getDeclaration())
+	}
+
+	bufferLines := []string{}
+	for _, buffer := range code.Buffers {
+		bufferLines = append(bufferLines, buffer.Declarations...)
+	}
+	writeNodeLibraryCall(method, code, implw, NameSpace, ClassName, isGlobal, doErrorMessages, spacing, bufferLines, code.CallParameters)
+
+	for _, line := range code.KeepCode {
+		fmt.Fprintf(implw, "%s%s\n", spacing, line)
+	}
+	for _, line := range code.ReturnCode {
+		fmt.Fprintf(implw, "%s%s\n", spacing, line)
+	}
+
+	writeNodeReturnValues(code, implw, spacing)
+
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "    } catch (E%sException & E) {\n", NameSpace)
+	fmt.Fprintf(implw, "        RaiseError (env, E.what(), E.getErrorCode ());\n")
+	fmt.Fprintf(implw, "    } catch (std::exception & E) {\n")
+	fmt.Fprintf(implw, "        RaiseError (env, E.what());\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "    return nullptr;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+
+	return nil
+}
+
+// writeNodeAsyncMethodImplementation writes the asynchronous variant of a method, which converts the parameters on
+// the main thread, calls the library on a worker thread and settles the returned promise with the results
+func writeNodeAsyncMethodImplementation(component ComponentDefinition, method ComponentDefinitionMethod, implw io.Writer, NameSpace string, ClassName string, isGlobal bool, doErrorMessages bool) error {
+
+	cancellationParam, _, isCancellable := component.GetCancellationCallback(method)
+	cancellationParamName := ""
+	if isCancellable {
+		cancellationParamName = cancellationParam.ParamName
+	}
+
+	code, err := getNodeMethodCode(method, NameSpace, ClassName, cancellationParamName)
+	if err != nil {
+		return err
+	}
+
+	argumentCount := code.InputCount
+	if isCancellable {
+		argumentCount = argumentCount + 1
+	}
+
+	workerClassName := fmt.Sprintf("C%sWorker", method.MethodName)
+	spacing := "            "
+
+	fmt.Fprintf(implw, "\n")
+
+	fmt.Fprintf(implw, "napi_value C%s%s::%sAsync (napi_env env, napi_callback_info info)\n", NameSpace, ClassName, method.MethodName)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    class %s : public C%sAsyncWorker {\n", workerClassName, NameSpace)
+	fmt.Fprintf(implw, "    private:\n")
+	for _, variable := range code.InputVariables {
+		fmt.Fprintf(implw, "        %s %s;\n", variable.Type, variable.Name)
+	}
+	for _, variable := range code.ReturnVariables {
+		fmt.Fprintf(implw, "        %s %s;\n", variable.Type, variable.Name)
+	}
+	for _, buffer := range code.Buffers {
+		fmt.Fprintf(implw, "        %s %s;\n", buffer.Member.Type, buffer.Member.Name)
+	}
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "    public:\n")
+
+	// The worker is constructed on the main thread, which converts the parameters
+	initializers := fmt.Sprintf("C%sAsyncWorker (env, thisObject, argc, args)", NameSpace)
+	for _, variable := range code.InputVariables {
+		if variable.AsyncArguments != "" {
+			initializers = initializers + fmt.Sprintf(", %s (%s)", variable.Name, variable.AsyncArguments)
+		}
+	}
+	fmt.Fprintf(implw, "        %s (napi_env env, napi_value thisObject, size_t argc, napi_value * args)\n", workerClassName)
+	fmt.Fprintf(implw, "            : %s\n", initializers)
+	fmt.Fprintf(implw, "        {\n")
+	for _, variable := range code.InputVariables {
+		if variable.AsyncArguments != "" {
+			fmt.Fprintf(implw, "%saddCallbackScope (&%s);\n", spacing, variable.Name)
+		} else if variable.Value != "" {
+			fmt.Fprintf(implw, "%s%s = %s;\n", spacing, variable.Name, variable.Value)
+		}
+	}
+	for _, variable := range code.ReturnVariables {
+		if variable.Value != "" {
+			fmt.Fprintf(implw, "%s%s = %s;\n", spacing, variable.Name, variable.Value)
+		}
 	}
-	
-	
-	fmt.Fprintf(implw, "%sCheckError (errorCode);\n", spacing)
+	fmt.Fprintf(implw, "        }\n")
+	fmt.Fprintf(implw, "\n")
 
-	fmt.Fprintf(implw, returncode)
+	fmt.Fprintf(implw, "        void execute ()\n")
+	fmt.Fprintf(implw, "        {\n")
+	fmt.Fprintf(implw, "%sC%sBaseClass * pThis = m_pThis;\n", spacing, NameSpace)
+	bufferLines := []string{}
+	for _, buffer := range code.Buffers {
+		bufferLines = append(bufferLines, buffer.Allocation)
+	}
+	writeNodeLibraryCall(method, code, implw, NameSpace, ClassName, isGlobal, doErrorMessages, spacing, bufferLines, code.AsyncCallParameters)
+	fmt.Fprintf(implw, "        }\n")
+	fmt.Fprintf(implw, "\n")
 
+	fmt.Fprintf(implw, "        napi_value complete (napi_env env)\n")
+	fmt.Fprintf(implw, "        {\n")
+	// The instance keeps the callbacks, and handles are returned as objects, which share its wrapper table
+	if (len(code.KeepCode) > 0) || strings.Contains(strings.Join(code.ReturnValues, " "), "pThis->") {
+		fmt.Fprintf(implw, "%sC%sBaseClass * pThis = m_pThis;\n", spacing, NameSpace)
+	}
+	for _, line := range code.KeepCode {
+		fmt.Fprintf(implw, "%s%s\n", spacing, line)
+	}
+	if len(code.ReturnValues) == 0 {
+		fmt.Fprintf(implw, "%sreturn nullptr;\n", spacing)
+	} else {
+		for _, line := range code.AsyncReturnCode {
+			fmt.Fprintf(implw, "%s%s\n", spacing, line)
+		}
+		writeNodeReturnValues(code, implw, spacing)
+	}
+	fmt.Fprintf(implw, "        }\n")
+	fmt.Fprintf(implw, "    };\n")
 	fmt.Fprintf(implw, "\n")
+
+	fmt.Fprintf(implw, "    try {\n")
+	fmt.Fprintf(implw, "        napi_value thisObject = nullptr;\n")
+	if argumentCount > 0 {
+		fmt.Fprintf(implw, "        size_t argc = %d;\n", argumentCount)
+		fmt.Fprintf(implw, "        napi_value args[%d];\n", argumentCount)
+		fmt.Fprintf(implw, "        CheckStatus (env, napi_get_cb_info (env, info, &argc, args, &thisObject, nullptr));\n")
+		fmt.Fprintf(implw, "        return C%sAsyncWorker::Queue (env, new %s (env, thisObject, %d, args), \"%s\");\n", NameSpace, workerClassName, argumentCount, method.MethodName)
+	} else {
+		fmt.Fprintf(implw, "        CheckStatus (env, napi_get_cb_info (env, info, nullptr, nullptr, &thisObject, nullptr));\n")
+		fmt.Fprintf(implw, "        return C%sAsyncWorker::Queue (env, new %s (env, thisObject, 0, nullptr), \"%s\");\n", NameSpace, workerClassName, method.MethodName)
+	}
+	fmt.Fprintf(implw, "    } catch (E%sException & E) {\n", NameSpace)
+	fmt.Fprintf(implw, "        return C%sAsyncWorker::Reject (env, createError (env, E.what(), E.getErrorCode ()));\n", NameSpace)
 	fmt.Fprintf(implw, "    } catch (std::exception & E) {\n")
-	fmt.Fprintf(implw, "        RaiseError (isolate, E.what());\n")
+	fmt.Fprintf(implw, "        return C%sAsyncWorker::Reject (env, createError (env, E.what()));\n", NameSpace)
 	fmt.Fprintf(implw, "    }\n")
 	fmt.Fprintf(implw, "}\n")
 	fmt.Fprintf(implw, "\n")
@@ -493,49 +804,239 @@ func writeNodeMethodImplementation(method ComponentDefinitionMethod, implw io.Wr
 	return nil
 }
 
+// writeNodeReleaseMethod writes the release method, which clears the handle of the object, so that it is not released again by the garbage collector
+func writeNodeReleaseMethod(method ComponentDefinitionMethod, implw io.Writer, NameSpace string) {
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "napi_value C%sWrapper::%s (napi_env env, napi_callback_info info)\n", NameSpace, method.MethodName)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    try {\n")
+	fmt.Fprintf(implw, "        napi_value thisObject = nullptr;\n")
+	fmt.Fprintf(implw, "        size_t argc = 1;\n")
+	fmt.Fprintf(implw, "        napi_value args[1];\n")
+	fmt.Fprintf(implw, "        CheckStatus (env, napi_get_cb_info (env, info, &argc, args, &thisObject, nullptr));\n")
+	fmt.Fprintf(implw, "        C%sBaseClass * pThis = getInstance (env, thisObject);\n", NameSpace)
+	fmt.Fprintf(implw, "        C%sBaseClass * pInstance = getInstance (env, args[0]);\n", NameSpace)
+	fmt.Fprintf(implw, "        s%sDynamicWrapperTable * wrapperTable = pThis->getDynamicWrapperTable ();\n", NameSpace)
+	fmt.Fprintf(implw, "        if ((wrapperTable == nullptr) || (wrapperTable->m_%s == nullptr))\n", method.MethodName)
+	fmt.Fprintf(implw, "            throw std::runtime_error (\"Could not call %s method %s.\");\n", NameSpace, method.MethodName)
+	fmt.Fprintf(implw, "        if (pInstance->getHandle () != nullptr) {\n")
+	fmt.Fprintf(implw, "            %sResult errorCode = wrapperTable->m_%s (pInstance->getHandle ());\n", NameSpace, method.MethodName)
+	fmt.Fprintf(implw, "            pInstance->clearHandle ();\n")
+	fmt.Fprintf(implw, "            CheckError (errorCode);\n")
+	fmt.Fprintf(implw, "        }\n")
+	fmt.Fprintf(implw, "    } catch (E%sException & E) {\n", NameSpace)
+	fmt.Fprintf(implw, "        RaiseError (env, E.what(), E.getErrorCode ());\n")
+	fmt.Fprintf(implw, "    } catch (std::exception & E) {\n")
+	fmt.Fprintf(implw, "        RaiseError (env, E.what());\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "    return nullptr;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+}
+
 func buildNodeWrapperClass(component ComponentDefinition, w io.Writer, implw io.Writer, NameSpace string, BaseName string) error {
+	errorMethod, hasErrorMethod := component.Global.GetErrorMethod()
+	hasAsyncMethods := component.HasAsyncMethods()
 
 	fmt.Fprintf(w, "\n")
 	fmt.Fprintf(w, "#ifndef %s_NODEWRAPPER_H\n", strings.ToUpper(NameSpace))
 	fmt.Fprintf(w, "#define %s_NODEWRAPPER_H\n", strings.ToUpper(NameSpace))
 	fmt.Fprintf(w, "\n")
 	fmt.Fprintf(w, "#include \"%s_dynamic.h\"\n", strings.ToLower(NameSpace))
-	fmt.Fprintf(w, "#include <node.h>\n")
-	fmt.Fprintf(w, "#include <node_object_wrap.h>\n")
+	fmt.Fprintf(w, "#include <node_api.h>\n")
+	if hasAsyncMethods {
+		fmt.Fprintf(w, "#include <atomic>\n")
+	}
+	fmt.Fprintf(w, "#include <condition_variable>\n")
+	fmt.Fprintf(w, "#include <functional>\n")
+	fmt.Fprintf(w, "#include <map>\n")
+	fmt.Fprintf(w, "#include <memory>\n")
+	fmt.Fprintf(w, "#include <mutex>\n")
+	fmt.Fprintf(w, "#include <stdexcept>\n")
 	fmt.Fprintf(w, "#include <string>\n")
+	fmt.Fprintf(w, "#include <thread>\n")
+	fmt.Fprintf(w, "#include <vector>\n")
 	fmt.Fprintf(w, "\n")
 
-	fmt.Fprintf(w, "#define NODEWRAPPER_FIELDCOUNT 4\n")
-	fmt.Fprintf(w, "#define NODEWRAPPER_TABLEINDEX 2\n")
-	fmt.Fprintf(w, "#define NODEWRAPPER_HANDLEINDEX 3\n")
-
 	fmt.Fprintf(w, "/*************************************************************************************************************************\n")
-	fmt.Fprintf(w, " Forward declarations \n")
+	fmt.Fprintf(w, " Class E%sException \n", NameSpace)
 	fmt.Fprintf(w, "**************************************************************************************************************************/\n")
-
 	fmt.Fprintf(w, "\n")
-	fmt.Fprintf(w, "class C%sWrapper;\n", NameSpace)
+	fmt.Fprintf(w, "class E%sException : public std::runtime_error {\n", NameSpace)
+	fmt.Fprintf(w, "private:\n")
+	fmt.Fprintf(w, "    %sResult m_errorCode;\n", NameSpace)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "public:\n")
+	fmt.Fprintf(w, "    E%sException (%sResult errorCode, const std::string & sMessage)\n", NameSpace, NameSpace)
+	fmt.Fprintf(w, "        : std::runtime_error (sMessage), m_errorCode (errorCode)\n")
+	fmt.Fprintf(w, "    {\n")
+	fmt.Fprintf(w, "    }\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    %sResult getErrorCode () const\n", NameSpace)
+	fmt.Fprintf(w, "    {\n")
+	fmt.Fprintf(w, "        return m_errorCode;\n")
+	fmt.Fprintf(w, "    }\n")
+	fmt.Fprintf(w, "};\n")
+	fmt.Fprintf(w, "\n")
 	fmt.Fprintf(w, "\n")
 
+	buildNodeCallbackSlot(w, NameSpace)
+	buildNodeCallbackScope(w, NameSpace, hasAsyncMethods)
+
 	fmt.Fprintf(w, "/*************************************************************************************************************************\n")
 	fmt.Fprintf(w, " Class C%sBaseClass \n", NameSpace)
 	fmt.Fprintf(w, "**************************************************************************************************************************/\n")
 	fmt.Fprintf(w, "\n")
 
-	fmt.Fprintf(w, "class C%sBaseClass : public node::ObjectWrap {\n", NameSpace)
+	fmt.Fprintf(w, "class C%sBaseClass {\n", NameSpace)
 	fmt.Fprintf(w, "private:\n")
+	fmt.Fprintf(w, "    std::shared_ptr<s%sDynamicWrapperTable> m_pWrapperTable;\n", NameSpace)
+	fmt.Fprintf(w, "    %sHandle m_Handle;\n", NameSpace)
+	fmt.Fprintf(w, "    std::map<std::string, std::unique_ptr<C%sCallbackSlot>> m_CallbackSlots;\n", NameSpace)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    static napi_ref errorConstructor;\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    static napi_value New (napi_env env, napi_callback_info info);\n")
+	fmt.Fprintf(w, "    static void Finalize (napi_env env, void * pData, void * pHint);\n")
+	fmt.Fprintf(w, "    static napi_value NewError (napi_env env, napi_callback_info info);\n")
+	fmt.Fprintf(w, "\n")
 	fmt.Fprintf(w, "protected:\n")
+	fmt.Fprintf(w, "    static void defineClass (napi_env env, const char * pClassName, const std::vector<napi_property_descriptor> & methods, napi_ref * pConstructor, napi_value exports);\n")
+	fmt.Fprintf(w, "    static void inheritClass (napi_env env, napi_value classFunction, napi_value parentFunction);\n")
+	fmt.Fprintf(w, "    static napi_value newInstance (napi_env env, napi_ref constructor, C%sBaseClass * pInstance);\n", NameSpace)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    static %s_uint32 getUInt32 (napi_env env, napi_value value, const char * pErrorMessage);\n", NameSpace)
+	fmt.Fprintf(w, "    static %s_int32 getInt32 (napi_env env, napi_value value, const char * pErrorMessage);\n", NameSpace)
+	fmt.Fprintf(w, "    static %s_uint64 getUInt64 (napi_env env, napi_value value, const char * pErrorMessage);\n", NameSpace)
+	fmt.Fprintf(w, "    static %s_int64 getInt64 (napi_env env, napi_value value, const char * pErrorMessage);\n", NameSpace)
+	fmt.Fprintf(w, "    static %s_double getDouble (napi_env env, napi_value value, const char * pErrorMessage);\n", NameSpace)
+	fmt.Fprintf(w, "    static bool getBool (napi_env env, napi_value value, const char * pErrorMessage);\n")
+	fmt.Fprintf(w, "    static std::string getString (napi_env env, napi_value value, const char * pErrorMessage);\n")
+	fmt.Fprintf(w, "    static %sHandle getObjectHandle (napi_env env, napi_value value, const char * pErrorMessage);\n", NameSpace)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    static napi_value createUInt32 (napi_env env, %s_uint32 nValue);\n", NameSpace)
+	fmt.Fprintf(w, "    static napi_value createInt32 (napi_env env, %s_int32 nValue);\n", NameSpace)
+	fmt.Fprintf(w, "    static napi_value createUInt64 (napi_env env, %s_uint64 nValue);\n", NameSpace)
+	fmt.Fprintf(w, "    static napi_value createInt64 (napi_env env, %s_int64 nValue);\n", NameSpace)
+	fmt.Fprintf(w, "    static napi_value createDouble (napi_env env, %s_double dValue);\n", NameSpace)
+	fmt.Fprintf(w, "    static napi_value createBool (napi_env env, bool bValue);\n")
+	fmt.Fprintf(w, "    static napi_value createString (napi_env env, const char * pValue);\n")
+	fmt.Fprintf(w, "    static napi_value createNull (napi_env env);\n")
+	fmt.Fprintf(w, "    static napi_value createObject (napi_env env);\n")
+	fmt.Fprintf(w, "    static void setProperty (napi_env env, napi_value object, const char * pName, napi_value value);\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    static napi_valuetype getValueType (napi_env env, napi_value value);\n")
+	fmt.Fprintf(w, "    static napi_value getProperty (napi_env env, napi_value object, const char * pName);\n")
+	fmt.Fprintf(w, "    static napi_value getArrayElement (napi_env env, napi_value array, uint32_t nIndex, const char * pErrorMessage);\n")
+	fmt.Fprintf(w, "    static void setArrayElement (napi_env env, napi_value array, uint32_t nIndex, napi_value value);\n")
+	fmt.Fprintf(w, "    static napi_value createArray (napi_env env, size_t nLength);\n")
+	fmt.Fprintf(w, "    static napi_value createTypedArray (napi_env env, napi_typedarray_type arrayType, %s_uint64 nCount, size_t nElementSize, void ** ppData);\n", NameSpace)
+	fmt.Fprintf(w, "\n")
+
+	for i := 0; i < len(component.Structs); i++ {
+		structinfo := component.Structs[i]
+		fmt.Fprintf(w, "    static s%s%s getStruct%s (napi_env env, napi_value value, const char * pErrorMessage);\n", NameSpace, structinfo.Name, structinfo.Name)
+		fmt.Fprintf(w, "    static napi_value createStruct%s (napi_env env, s%s%s value);\n", structinfo.Name, NameSpace, structinfo.Name)
+	}
+	if len(component.Structs) > 0 {
+		fmt.Fprintf(w, "\n")
+	}
+
+	fmt.Fprintf(w, "    // Arrays are passed as JS Array, whose elements are converted one by one\n")
+	fmt.Fprintf(w, "    template <typename T, typename V> static const T * getArray (napi_env env, napi_value value, V (* getElement) (napi_env, napi_value, const char *), std::unique_ptr<T[]> & pElements, %s_uint64 & nCount, const char * pErrorMessage)\n", NameSpace)
+	fmt.Fprintf(w, "    {\n")
+	fmt.Fprintf(w, "        bool bIsArray = false;\n")
+	fmt.Fprintf(w, "        if ((napi_is_array (env, value, &bIsArray) != napi_ok) || !bIsArray)\n")
+	fmt.Fprintf(w, "            throw std::runtime_error (pErrorMessage);\n")
+	fmt.Fprintf(w, "        uint32_t nLength = 0;\n")
+	fmt.Fprintf(w, "        CheckStatus (env, napi_get_array_length (env, value, &nLength));\n")
+	fmt.Fprintf(w, "        pElements.reset (new T[nLength]);\n")
+	fmt.Fprintf(w, "        for (uint32_t nIndex = 0; nIndex < nLength; nIndex++)\n")
+	fmt.Fprintf(w, "            pElements[nIndex] = (T) getElement (env, getArrayElement (env, value, nIndex, pErrorMessage), pErrorMessage);\n")
+	fmt.Fprintf(w, "        nCount = nLength;\n")
+	fmt.Fprintf(w, "        return pElements.get ();\n")
+	fmt.Fprintf(w, "    }\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    // A TypedArray of the element type is passed without copying its elements\n")
+	fmt.Fprintf(w, "    template <typename T, typename V> static const T * getTypedArray (napi_env env, napi_value value, napi_typedarray_type arrayType, V (* getElement) (napi_env, napi_value, const char *), std::unique_ptr<T[]> & pElements, %s_uint64 & nCount, const char * pErrorMessage)\n", NameSpace)
+	fmt.Fprintf(w, "    {\n")
+	fmt.Fprintf(w, "        bool bIsTypedArray = false;\n")
+	fmt.Fprintf(w, "        CheckStatus (env, napi_is_typedarray (env, value, &bIsTypedArray));\n")
+	fmt.Fprintf(w, "        if (!bIsTypedArray)\n")
+	fmt.Fprintf(w, "            return getArray<T> (env, value, getElement, pElements, nCount, pErrorMessage);\n")
+	fmt.Fprintf(w, "        napi_typedarray_type valueArrayType = napi_uint8_array;\n")
+	fmt.Fprintf(w, "        size_t nLength = 0;\n")
+	fmt.Fprintf(w, "        void * pData = nullptr;\n")
+	fmt.Fprintf(w, "        CheckStatus (env, napi_get_typedarray_info (env, value, &valueArrayType, &nLength, &pData, nullptr, nullptr));\n")
+	fmt.Fprintf(w, "        if (valueArrayType != arrayType)\n")
+	fmt.Fprintf(w, "            throw std::runtime_error (pErrorMessage);\n")
+	fmt.Fprintf(w, "        nCount = nLength;\n")
+	fmt.Fprintf(w, "        return (const T *) pData;\n")
+	fmt.Fprintf(w, "    }\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    template <typename T, typename V> static napi_value createArray (napi_env env, const T * pElements, %s_uint64 nCount, napi_value (* createElement) (napi_env, V))\n", NameSpace)
+	fmt.Fprintf(w, "    {\n")
+	fmt.Fprintf(w, "        napi_value result = createArray (env, (size_t) nCount);\n")
+	fmt.Fprintf(w, "        for (%s_uint64 nIndex = 0; nIndex < nCount; nIndex++)\n", NameSpace)
+	fmt.Fprintf(w, "            setArrayElement (env, result, (uint32_t) nIndex, createElement (env, pElements[nIndex]));\n")
+	fmt.Fprintf(w, "        return result;\n")
+	fmt.Fprintf(w, "    }\n")
+	fmt.Fprintf(w, "\n")
 	fmt.Fprintf(w, "public:\n")
-	fmt.Fprintf(w, "    C%sBaseClass ();\n", NameSpace)
-	fmt.Fprintf(w, "    static void RaiseError (v8::Isolate * isolate, std::string Message);\n")
+	fmt.Fprintf(w, "    C%sBaseClass (std::shared_ptr<s%sDynamicWrapperTable> pWrapperTable, %sHandle pHandle);\n", NameSpace, NameSpace, NameSpace)
+	fmt.Fprintf(w, "    virtual ~C%sBaseClass ();\n", NameSpace)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    static void InitError (napi_env env, napi_value exports);\n")
+	fmt.Fprintf(w, "    static void InitParentClass (napi_env env, napi_value exports, const char * pClassName, const char * pParentClassName);\n")
+	fmt.Fprintf(w, "    static void RaiseError (napi_env env, std::string Message);\n")
+	fmt.Fprintf(w, "    static void RaiseError (napi_env env, std::string Message, %sResult errorCode);\n", NameSpace)
+	fmt.Fprintf(w, "    static napi_value createError (napi_env env, std::string Message);\n")
+	fmt.Fprintf(w, "    static napi_value createError (napi_env env, std::string Message, %sResult errorCode);\n", NameSpace)
 	fmt.Fprintf(w, "    static void CheckError (%sResult errorCode);\n", NameSpace)
-	fmt.Fprintf(w, "    static void setHandle (%sHandle pHandle);\n", NameSpace)
-	fmt.Fprintf(w, "    static %sHandle getHandle (v8::Handle<v8::Object> objecthandle);\n", NameSpace)
-	fmt.Fprintf(w, "    static s%sDynamicWrapperTable * getDynamicWrapperTable (v8::Handle<v8::Object> objecthandle);\n", NameSpace)
+	if hasErrorMethod {
+		fmt.Fprintf(w, "    static void CheckError (s%sDynamicWrapperTable * wrapperTable, %sHandle handle, %sResult errorCode);\n", NameSpace, NameSpace, NameSpace)
+	}
+	fmt.Fprintf(w, "    static void CheckStatus (napi_env env, napi_status status);\n")
+	fmt.Fprintf(w, "    static C%sBaseClass * getInstance (napi_env env, napi_value object);\n", NameSpace)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    %sHandle getHandle ();\n", NameSpace)
+	fmt.Fprintf(w, "    void clearHandle ();\n")
+	fmt.Fprintf(w, "    void keepCallbackSlot (const std::string & sParameterName, std::unique_ptr<C%sCallbackSlot> pSlot);\n", NameSpace)
+	fmt.Fprintf(w, "    s%sDynamicWrapperTable * getDynamicWrapperTable ();\n", NameSpace)
+	fmt.Fprintf(w, "    std::shared_ptr<s%sDynamicWrapperTable> getSharedWrapperTable ();\n", NameSpace)
+
+	if len(component.Functions) > 0 {
+		fmt.Fprintf(w, "\n")
+		fmt.Fprintf(w, "    // Callbacks, that call the JS function passed to the running method, or kept in the slot nSlot of their type\n")
+	}
+	for i := 0; i < len(component.Functions); i++ {
+		functiontype := component.Functions[i]
+		parameters, names, err := getCCallbackParameters(functiontype, NameSpace)
+		if err != nil {
+			return err
+		}
+		arguments := "nSlot"
+		if len(names) > 0 {
+			arguments = arguments + ", " + strings.Join(names, ", ")
+		}
+		fmt.Fprintf(w, "    template <int nSlot> static void callback%s (%s)\n", functiontype.FunctionName, parameters)
+		fmt.Fprintf(w, "    {\n")
+		fmt.Fprintf(w, "        call%s (%s);\n", functiontype.FunctionName, arguments)
+		fmt.Fprintf(w, "    }\n")
+		if len(parameters) > 0 {
+			parameters = ", " + parameters
+		}
+		fmt.Fprintf(w, "    static void call%s (int nSlot%s);\n", functiontype.FunctionName, parameters)
+		fmt.Fprintf(w, "    static %s%s getCallback%s (C%sCallbackScope & callbackScope);\n", NameSpace, functiontype.FunctionName, functiontype.FunctionName, NameSpace)
+	}
 	fmt.Fprintf(w, "};\n")
 	fmt.Fprintf(w, "\n")
 	fmt.Fprintf(w, "\n")
 
+	if hasAsyncMethods {
+		buildNodeAsyncWorker(w, NameSpace)
+	}
+
 	for i := 0; i < len(component.Classes); i++ {
 		class := component.Classes[i]
 
@@ -544,21 +1045,23 @@ func buildNodeWrapperClass(component ComponentDefinition, w io.Writer, implw io.
 		fmt.Fprintf(w, "**************************************************************************************************************************/\n")
 		fmt.Fprintf(w, "class C%s%s : public C%sBaseClass {\n", NameSpace, class.ClassName, NameSpace)
 		fmt.Fprintf(w, "private:\n")
-		fmt.Fprintf(w, "    static void New(const v8::FunctionCallbackInfo<v8::Value>& args);\n")
-		fmt.Fprintf(w, "    static v8::Persistent<v8::Function> constructor;\n")
+		fmt.Fprintf(w, "    static napi_ref constructor;\n")
 
 		for j := 0; j < len(class.Methods); j++ {
 			method := class.Methods[j]
-			fmt.Fprintf(w, "    static void %s (const v8::FunctionCallbackInfo<v8::Value>& args);\n", method.MethodName)
+			fmt.Fprintf(w, "    static napi_value %s (napi_env env, napi_callback_info info);\n", method.MethodName)
+			if method.Async {
+				fmt.Fprintf(w, "    static napi_value %sAsync (napi_env env, napi_callback_info info);\n", method.MethodName)
+			}
 		}
 
 		fmt.Fprintf(w, "\n")
 		fmt.Fprintf(w, "public:\n")
-		fmt.Fprintf(w, "    C%s%s ();\n", NameSpace, class.ClassName)
-		fmt.Fprintf(w, "    ~C%s%s ();\n", NameSpace, class.ClassName)
+		fmt.Fprintf(w, "    C%s%s (std::shared_ptr<s%sDynamicWrapperTable> pWrapperTable, %sHandle pHandle);\n", NameSpace, class.ClassName, NameSpace, NameSpace)
 		fmt.Fprintf(w, "    \n")
-		fmt.Fprintf(w, "    static void Init();\n")
-		fmt.Fprintf(w, "    static v8::Local<v8::Object> NewInstance(v8::Local<v8::Object>, %sHandle pHandle);\n", NameSpace)
+		fmt.Fprintf(w, "    static void Init (napi_env env, napi_value exports);\n")
+		fmt.Fprintf(w, "    static void addMethods (std::vector<napi_property_descriptor> & methods);\n")
+		fmt.Fprintf(w, "    static napi_value NewInstance (napi_env env, std::shared_ptr<s%sDynamicWrapperTable> pWrapperTable, %sHandle pHandle);\n", NameSpace, NameSpace)
 		fmt.Fprintf(w, "    \n")
 		fmt.Fprintf(w, "};\n")
 		fmt.Fprintf(w, "\n")
@@ -572,21 +1075,22 @@ func buildNodeWrapperClass(component ComponentDefinition, w io.Writer, implw io.
 	fmt.Fprintf(w, "\n")
 	fmt.Fprintf(w, "class C%sWrapper : public C%sBaseClass {\n", NameSpace, NameSpace)
 	fmt.Fprintf(w, "private:\n")
-	fmt.Fprintf(w, "    static void New(const v8::FunctionCallbackInfo<v8::Value>& args);\n")
-	fmt.Fprintf(w, "    static v8::Persistent<v8::Function> constructor;\n")
+	fmt.Fprintf(w, "    static napi_ref constructor;\n")
 
 	global := component.Global
 	for j := 0; j < len(global.Methods); j++ {
 		method := global.Methods[j]
-		fmt.Fprintf(w, "    static void %s(const v8::FunctionCallbackInfo<v8::Value>& args);\n", method.MethodName)
+		fmt.Fprintf(w, "    static napi_value %s (napi_env env, napi_callback_info info);\n", method.MethodName)
+		if method.Async {
+			fmt.Fprintf(w, "    static napi_value %sAsync (napi_env env, napi_callback_info info);\n", method.MethodName)
+		}
 	}
 
 	fmt.Fprintf(w, "\n")
 	fmt.Fprintf(w, "public:\n")
-	fmt.Fprintf(w, "    C%sWrapper ();\n", NameSpace)
-	fmt.Fprintf(w, "    ~C%sWrapper();\n", NameSpace)
-	fmt.Fprintf(w, "    static void Init();\n")
-	fmt.Fprintf(w, "    static v8::Local<v8::Object> NewInstance();\n")
+	fmt.Fprintf(w, "    C%sWrapper (std::shared_ptr<s%sDynamicWrapperTable> pWrapperTable);\n", NameSpace, NameSpace)
+	fmt.Fprintf(w, "    static void Init (napi_env env, napi_value exports);\n")
+	fmt.Fprintf(w, "    static napi_value NewInstance (napi_env env, napi_value libraryName);\n")
 	fmt.Fprintf(w, "};\n")
 
 	fmt.Fprintf(w, "\n")
@@ -594,66 +1098,494 @@ func buildNodeWrapperClass(component ComponentDefinition, w io.Writer, implw io.
 	fmt.Fprintf(w, "\n")
 
 	fmt.Fprintf(implw, "\n")
-	fmt.Fprintf(implw, "#include <node.h>\n")
+	fmt.Fprintf(implw, "#include <node_api.h>\n")
 	fmt.Fprintf(implw, "#include \"%s_nodewrapper.h\"\n", BaseName)
+	fmt.Fprintf(implw, "#include <cstring>\n")
 	fmt.Fprintf(implw, "\n")
-	fmt.Fprintf(implw, "#include <Windows.h>\n")
-	fmt.Fprintf(implw, "\n")
-	fmt.Fprintf(implw, "using namespace v8;\n")
-	fmt.Fprintf(implw, "\n")
-	fmt.Fprintf(implw, "Persistent<Function> C%sWrapper::constructor;\n", NameSpace)
+	fmt.Fprintf(implw, "napi_ref C%sBaseClass::errorConstructor = nullptr;\n", NameSpace)
+	fmt.Fprintf(implw, "napi_ref C%sWrapper::constructor = nullptr;\n", NameSpace)
 	for i := 0; i < len(component.Classes); i++ {
 		class := component.Classes[i]
-		fmt.Fprintf(implw, "Persistent<Function> C%s%s::constructor;\n", NameSpace, class.ClassName)
+		fmt.Fprintf(implw, "napi_ref C%s%s::constructor = nullptr;\n", NameSpace, class.ClassName)
 	}
 	fmt.Fprintf(implw, "\n")
 
+	buildNodeCallbackSlotImplementation(implw, NameSpace)
+	buildNodeCallbackScopeImplementation(implw, NameSpace, hasAsyncMethods)
+	if hasAsyncMethods {
+		buildNodeAsyncWorkerImplementation(implw, NameSpace)
+	}
+
 	fmt.Fprintf(implw, "/*************************************************************************************************************************\n")
 	fmt.Fprintf(implw, " Class C%sBaseClass Implementation\n", NameSpace)
 	fmt.Fprintf(implw, "**************************************************************************************************************************/\n")
 	fmt.Fprintf(implw, "\n")
-	fmt.Fprintf(implw, "C%sBaseClass::C%sBaseClass ()\n", NameSpace, NameSpace)
-	fmt.Fprintf(implw, "    : node::ObjectWrap ()\n")
+	fmt.Fprintf(implw, "C%sBaseClass::C%sBaseClass (std::shared_ptr<s%sDynamicWrapperTable> pWrapperTable, %sHandle pHandle)\n", NameSpace, NameSpace, NameSpace, NameSpace)
+	fmt.Fprintf(implw, "    : m_pWrapperTable (pWrapperTable), m_Handle (pHandle)\n")
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "C%sBaseClass::~C%sBaseClass ()\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // The instance is released, when its object is garbage collected\n")
+	fmt.Fprintf(implw, "    if ((m_Handle != nullptr) && (m_pWrapperTable.get () != nullptr) && (m_pWrapperTable->m_%s != nullptr))\n", component.Global.ReleaseMethod)
+	fmt.Fprintf(implw, "        m_pWrapperTable->m_%s (m_Handle);\n", component.Global.ReleaseMethod)
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sBaseClass::RaiseError (napi_env env, std::string Message)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    bool bIsExceptionPending = false;\n")
+	fmt.Fprintf(implw, "    if ((napi_is_exception_pending (env, &bIsExceptionPending) == napi_ok) && !bIsExceptionPending) {\n")
+	fmt.Fprintf(implw, "        napi_throw_error (env, nullptr, Message.c_str ());\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sBaseClass::RaiseError (napi_env env, std::string Message, %sResult errorCode)\n", NameSpace, NameSpace)
 	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // Errors of the library are thrown as %sError, which carries the error code\n", NameSpace)
+	fmt.Fprintf(implw, "    bool bIsExceptionPending = false;\n")
+	fmt.Fprintf(implw, "    if ((napi_is_exception_pending (env, &bIsExceptionPending) != napi_ok) || bIsExceptionPending)\n")
+	fmt.Fprintf(implw, "        return;\n")
+	fmt.Fprintf(implw, "    napi_value error = createError (env, Message, errorCode);\n")
+	fmt.Fprintf(implw, "    if (error != nullptr) {\n")
+	fmt.Fprintf(implw, "        napi_throw (env, error);\n")
+	fmt.Fprintf(implw, "    } else {\n")
+	fmt.Fprintf(implw, "        RaiseError (env, Message);\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "napi_value C%sBaseClass::createError (napi_env env, std::string Message)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    napi_value message = nullptr;\n")
+	fmt.Fprintf(implw, "    napi_value error = nullptr;\n")
+	fmt.Fprintf(implw, "    if ((napi_create_string_utf8 (env, Message.c_str (), NAPI_AUTO_LENGTH, &message) != napi_ok) ||\n")
+	fmt.Fprintf(implw, "        (napi_create_error (env, nullptr, message, &error) != napi_ok))\n")
+	fmt.Fprintf(implw, "        return nullptr;\n")
+	fmt.Fprintf(implw, "    return error;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "napi_value C%sBaseClass::createError (napi_env env, std::string Message, %sResult errorCode)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    napi_value errorFunction = nullptr;\n")
+	fmt.Fprintf(implw, "    napi_value args[2] = { nullptr, nullptr };\n")
+	fmt.Fprintf(implw, "    napi_value error = nullptr;\n")
+	fmt.Fprintf(implw, "    if ((errorConstructor != nullptr) && (napi_get_reference_value (env, errorConstructor, &errorFunction) == napi_ok) &&\n")
+	fmt.Fprintf(implw, "        (napi_create_string_utf8 (env, Message.c_str (), NAPI_AUTO_LENGTH, &args[0]) == napi_ok) &&\n")
+	fmt.Fprintf(implw, "        (napi_create_int32 (env, errorCode, &args[1]) == napi_ok) &&\n")
+	fmt.Fprintf(implw, "        (napi_new_instance (env, errorFunction, 2, args, &error) == napi_ok))\n")
+	fmt.Fprintf(implw, "        return error;\n")
+	fmt.Fprintf(implw, "    return createError (env, Message);\n")
+	fmt.Fprintf(implw, "}\n")
 	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sBaseClass::InitError (napi_env env, napi_value exports)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // %sError derives from Error, like a class declared with extends\n", NameSpace)
+	fmt.Fprintf(implw, "    napi_value errorFunction = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_define_class (env, \"%sError\", NAPI_AUTO_LENGTH, NewError, nullptr, 0, nullptr, &errorFunction));\n", NameSpace)
+	fmt.Fprintf(implw, "    napi_value global = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_get_global (env, &global));\n")
+	fmt.Fprintf(implw, "    inheritClass (env, errorFunction, getProperty (env, global, \"Error\"));\n")
+	fmt.Fprintf(implw, "    napi_property_descriptor name = { \"name\", nullptr, nullptr, nullptr, nullptr, createString (env, \"%sError\"), napi_configurable, nullptr };\n", NameSpace)
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_define_properties (env, getProperty (env, errorFunction, \"prototype\"), 1, &name));\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_create_reference (env, errorFunction, 1, &errorConstructor));\n")
+	fmt.Fprintf(implw, "    setProperty (env, exports, \"%sError\", errorFunction);\n", NameSpace)
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sBaseClass::InitParentClass (napi_env env, napi_value exports, const char * pClassName, const char * pParentClassName)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    inheritClass (env, getProperty (env, exports, pClassName), getProperty (env, exports, pParentClassName));\n")
 	fmt.Fprintf(implw, "}\n")
 	fmt.Fprintf(implw, "\n")
-	fmt.Fprintf(implw, "void C%sBaseClass::RaiseError (v8::Isolate * isolate, std::string Message)\n", NameSpace)
+	fmt.Fprintf(implw, "void C%sBaseClass::inheritClass (napi_env env, napi_value classFunction, napi_value parentFunction)\n", NameSpace)
 	fmt.Fprintf(implw, "{\n")
-	fmt.Fprintf(implw, "    if (isolate != nullptr) {	\n")
-	fmt.Fprintf(implw, "        isolate->ThrowException(Exception::TypeError(\n")
-	fmt.Fprintf(implw, "            String::NewFromUtf8(isolate, Message.c_str() )));\n")
+	fmt.Fprintf(implw, "    // Sets up the prototype chain like a class declared with extends\n")
+	fmt.Fprintf(implw, "    napi_value global = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_get_global (env, &global));\n")
+	fmt.Fprintf(implw, "    napi_value objectFunction = getProperty (env, global, \"Object\");\n")
+	fmt.Fprintf(implw, "    napi_value setPrototypeOf = getProperty (env, objectFunction, \"setPrototypeOf\");\n")
+	fmt.Fprintf(implw, "    napi_value result = nullptr;\n")
+	fmt.Fprintf(implw, "    napi_value args[2] = { getProperty (env, classFunction, \"prototype\"), getProperty (env, parentFunction, \"prototype\") };\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_call_function (env, objectFunction, setPrototypeOf, 2, args, &result));\n")
+	fmt.Fprintf(implw, "    args[0] = classFunction;\n")
+	fmt.Fprintf(implw, "    args[1] = parentFunction;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_call_function (env, objectFunction, setPrototypeOf, 2, args, &result));\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "napi_value C%sBaseClass::NewError (napi_env env, napi_callback_info info)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    try {\n")
+	fmt.Fprintf(implw, "        size_t argc = 2;\n")
+	fmt.Fprintf(implw, "        napi_value args[2] = { nullptr, nullptr };\n")
+	fmt.Fprintf(implw, "        napi_value thisObject = nullptr;\n")
+	fmt.Fprintf(implw, "        CheckStatus (env, napi_get_cb_info (env, info, &argc, args, &thisObject, nullptr));\n")
+	fmt.Fprintf(implw, "        napi_property_descriptor message = { \"message\", nullptr, nullptr, nullptr, nullptr, args[0], (napi_property_attributes) (napi_writable | napi_configurable), nullptr };\n")
+	fmt.Fprintf(implw, "        CheckStatus (env, napi_define_properties (env, thisObject, 1, &message));\n")
+	fmt.Fprintf(implw, "        setProperty (env, thisObject, \"code\", args[1]);\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "        // The stack is captured like in the constructor of Error\n")
+	fmt.Fprintf(implw, "        napi_value global = nullptr;\n")
+	fmt.Fprintf(implw, "        CheckStatus (env, napi_get_global (env, &global));\n")
+	fmt.Fprintf(implw, "        napi_value baseFunction = getProperty (env, global, \"Error\");\n")
+	fmt.Fprintf(implw, "        napi_value captureStackTrace = getProperty (env, baseFunction, \"captureStackTrace\");\n")
+	fmt.Fprintf(implw, "        if (getValueType (env, captureStackTrace) == napi_function) {\n")
+	fmt.Fprintf(implw, "            napi_value result = nullptr;\n")
+	fmt.Fprintf(implw, "            CheckStatus (env, napi_call_function (env, baseFunction, captureStackTrace, 1, &thisObject, &result));\n")
+	fmt.Fprintf(implw, "        }\n")
+	fmt.Fprintf(implw, "        return thisObject;\n")
+	fmt.Fprintf(implw, "    } catch (std::exception & E) {\n")
+	fmt.Fprintf(implw, "        RaiseError (env, E.what());\n")
 	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "    return nullptr;\n")
 	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
 
 	fmt.Fprintf(implw, "void C%sBaseClass::CheckError (%sResult errorCode)\n", NameSpace, NameSpace)
 	fmt.Fprintf(implw, "{\n")
 	fmt.Fprintf(implw, "    if (errorCode != 0) {	\n")
-	fmt.Fprintf(implw, "       throw std::runtime_error (\"%s Error \" + std::to_string (errorCode));\n", NameSpace)
+	fmt.Fprintf(implw, "       throw E%sException (errorCode, \"%s Error \" + std::to_string (errorCode));\n", NameSpace, NameSpace)
 	fmt.Fprintf(implw, "    }\n")
 	fmt.Fprintf(implw, "}\n")
 
+	if hasErrorMethod {
+		fmt.Fprintf(implw, "\n")
+		fmt.Fprintf(implw, "void C%sBaseClass::CheckError (s%sDynamicWrapperTable * wrapperTable, %sHandle handle, %sResult errorCode)\n", NameSpace, NameSpace, NameSpace, NameSpace)
+		fmt.Fprintf(implw, "{\n")
+		fmt.Fprintf(implw, "    if (errorCode != 0) {\n")
+		fmt.Fprintf(implw, "        std::string sErrorMessage = \"%s Error \" + std::to_string (errorCode);\n", NameSpace)
+		fmt.Fprintf(implw, "        if ((wrapperTable != nullptr) && (wrapperTable->m_%s != nullptr)) {\n", errorMethod.MethodName)
+		fmt.Fprintf(implw, "            %s_uint32 nNeededChars = 0;\n", NameSpace)
+		fmt.Fprintf(implw, "            bool bHasError = false;\n")
+		fmt.Fprintf(implw, "            if ((wrapperTable->m_%s (handle, 0, &nNeededChars, nullptr, &bHasError) == 0) && bHasError) {\n", errorMethod.MethodName)
+		fmt.Fprintf(implw, "                std::vector<char> buffer (nNeededChars + 1);\n")
+		fmt.Fprintf(implw, "                if (wrapperTable->m_%s (handle, nNeededChars + 1, &nNeededChars, &buffer[0], &bHasError) == 0)\n", errorMethod.MethodName)
+		fmt.Fprintf(implw, "                    sErrorMessage = sErrorMessage + \": \" + std::string (&buffer[0]);\n")
+		fmt.Fprintf(implw, "            }\n")
+		fmt.Fprintf(implw, "        }\n")
+		fmt.Fprintf(implw, "        throw E%sException (errorCode, sErrorMessage);\n", NameSpace)
+		fmt.Fprintf(implw, "    }\n")
+		fmt.Fprintf(implw, "}\n")
+	}
+
 	fmt.Fprintf(implw, "\n")
-	fmt.Fprintf(implw, "void C%sBaseClass::setHandle (%sHandle pHandle)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "void C%sBaseClass::CheckStatus (napi_env env, napi_status status)\n", NameSpace)
 	fmt.Fprintf(implw, "{\n")
-	fmt.Fprintf(implw, "    \n")
+	fmt.Fprintf(implw, "    if (status != napi_ok) {\n")
+	fmt.Fprintf(implw, "        const napi_extended_error_info * pErrorInfo = nullptr;\n")
+	fmt.Fprintf(implw, "        std::string sErrorMessage = \"Node-API Error \" + std::to_string (status);\n")
+	fmt.Fprintf(implw, "        if ((napi_get_last_error_info (env, &pErrorInfo) == napi_ok) && (pErrorInfo != nullptr) && (pErrorInfo->error_message != nullptr))\n")
+	fmt.Fprintf(implw, "            sErrorMessage = pErrorInfo->error_message;\n")
+	fmt.Fprintf(implw, "        throw std::runtime_error (sErrorMessage);\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+
+	fmt.Fprintf(implw, "C%sBaseClass * C%sBaseClass::getInstance (napi_env env, napi_value object)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    void * pInstance = nullptr;\n")
+	fmt.Fprintf(implw, "    if ((napi_unwrap (env, object, &pInstance) != napi_ok) || (pInstance == nullptr))\n")
+	fmt.Fprintf(implw, "        throw std::runtime_error (\"Invalid %s object\");\n", NameSpace)
+	fmt.Fprintf(implw, "    return (C%sBaseClass *) pInstance;\n", NameSpace)
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+
+	fmt.Fprintf(implw, "%sHandle C%sBaseClass::getHandle ()\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    return m_Handle;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sBaseClass::clearHandle ()\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // The library does not call the callbacks of a released instance any more\n")
+	fmt.Fprintf(implw, "    m_Handle = nullptr;\n")
+	fmt.Fprintf(implw, "    m_CallbackSlots.clear ();\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sBaseClass::keepCallbackSlot (const std::string & sParameterName, std::unique_ptr<C%sCallbackSlot> pSlot)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // The library may keep a callback and call it later, so the instance keeps its JS function, until the same\n")
+	fmt.Fprintf(implw, "    // parameter is passed again\n")
+	fmt.Fprintf(implw, "    m_CallbackSlots[sParameterName] = std::move (pSlot);\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "s%sDynamicWrapperTable * C%sBaseClass::getDynamicWrapperTable ()\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    return m_pWrapperTable.get ();\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "std::shared_ptr<s%sDynamicWrapperTable> C%sBaseClass::getSharedWrapperTable ()\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    return m_pWrapperTable;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+
+	fmt.Fprintf(implw, "napi_value C%sBaseClass::New (napi_env env, napi_callback_info info)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    try {\n")
+	fmt.Fprintf(implw, "        // The objects are only constructed by newInstance, which passes the native instance as external value\n")
+	fmt.Fprintf(implw, "        size_t argc = 1;\n")
+	fmt.Fprintf(implw, "        napi_value args[1] = { nullptr };\n")
+	fmt.Fprintf(implw, "        napi_value thisObject = nullptr;\n")
+	fmt.Fprintf(implw, "        CheckStatus (env, napi_get_cb_info (env, info, &argc, args, &thisObject, nullptr));\n")
+	fmt.Fprintf(implw, "        void * pInstance = nullptr;\n")
+	fmt.Fprintf(implw, "        if ((argc != 1) || (napi_get_value_external (env, args[0], &pInstance) != napi_ok) || (pInstance == nullptr))\n")
+	fmt.Fprintf(implw, "            throw std::runtime_error (\"Invalid call to constructor of %s object\");\n", NameSpace)
+	fmt.Fprintf(implw, "        CheckStatus (env, napi_wrap (env, thisObject, pInstance, Finalize, nullptr, nullptr));\n")
+	fmt.Fprintf(implw, "        return thisObject;\n")
+	fmt.Fprintf(implw, "    } catch (std::exception & E) {\n")
+	fmt.Fprintf(implw, "        RaiseError (env, E.what());\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "    return nullptr;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sBaseClass::Finalize (napi_env env, void * pData, void * pHint)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    delete (C%sBaseClass *) pData;\n", NameSpace)
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sBaseClass::defineClass (napi_env env, const char * pClassName, const std::vector<napi_property_descriptor> & methods, napi_ref * pConstructor, napi_value exports)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    napi_value constructorFunction = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_define_class (env, pClassName, NAPI_AUTO_LENGTH, New, nullptr, methods.size (), methods.data (), &constructorFunction));\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_create_reference (env, constructorFunction, 1, pConstructor));\n")
+	fmt.Fprintf(implw, "    setProperty (env, exports, pClassName, constructorFunction);\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "napi_value C%sBaseClass::newInstance (napi_env env, napi_ref constructor, C%sBaseClass * pInstance)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // The object owns the instance once it is constructed\n")
+	fmt.Fprintf(implw, "    std::unique_ptr<C%sBaseClass> pOwnedInstance (pInstance);\n", NameSpace)
+	fmt.Fprintf(implw, "    napi_value constructorFunction = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_get_reference_value (env, constructor, &constructorFunction));\n")
+	fmt.Fprintf(implw, "    napi_value external = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_create_external (env, pInstance, nullptr, nullptr, &external));\n")
+	fmt.Fprintf(implw, "    napi_value instance = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_new_instance (env, constructorFunction, 1, &external, &instance));\n")
+	fmt.Fprintf(implw, "    pOwnedInstance.release ();\n")
+	fmt.Fprintf(implw, "    return instance;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+
+	fmt.Fprintf(implw, "%s_uint32 C%sBaseClass::getUInt32 (napi_env env, napi_value value, const char * pErrorMessage)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    uint32_t nValue = 0;\n")
+	fmt.Fprintf(implw, "    if (napi_get_value_uint32 (env, value, &nValue) != napi_ok)\n")
+	fmt.Fprintf(implw, "        throw std::runtime_error (pErrorMessage);\n")
+	fmt.Fprintf(implw, "    return nValue;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "%s_int32 C%sBaseClass::getInt32 (napi_env env, napi_value value, const char * pErrorMessage)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    int32_t nValue = 0;\n")
+	fmt.Fprintf(implw, "    if (napi_get_value_int32 (env, value, &nValue) != napi_ok)\n")
+	fmt.Fprintf(implw, "        throw std::runtime_error (pErrorMessage);\n")
+	fmt.Fprintf(implw, "    return nValue;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "%s_uint64 C%sBaseClass::getUInt64 (napi_env env, napi_value value, const char * pErrorMessage)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // 64bit integers are passed as Number or as BigInt\n")
+	fmt.Fprintf(implw, "    uint64_t nValue = 0;\n")
+	fmt.Fprintf(implw, "    bool bLossless = false;\n")
+	fmt.Fprintf(implw, "    if (napi_get_value_bigint_uint64 (env, value, &nValue, &bLossless) == napi_ok)\n")
+	fmt.Fprintf(implw, "        return nValue;\n")
+	fmt.Fprintf(implw, "    double dValue = 0.0;\n")
+	fmt.Fprintf(implw, "    if ((napi_get_value_double (env, value, &dValue) != napi_ok) || (dValue < 0.0))\n")
+	fmt.Fprintf(implw, "        throw std::runtime_error (pErrorMessage);\n")
+	fmt.Fprintf(implw, "    return (%s_uint64) dValue;\n", NameSpace)
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "%s_int64 C%sBaseClass::getInt64 (napi_env env, napi_value value, const char * pErrorMessage)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // 64bit integers are passed as Number or as BigInt\n")
+	fmt.Fprintf(implw, "    int64_t nValue = 0;\n")
+	fmt.Fprintf(implw, "    bool bLossless = false;\n")
+	fmt.Fprintf(implw, "    if (napi_get_value_bigint_int64 (env, value, &nValue, &bLossless) == napi_ok)\n")
+	fmt.Fprintf(implw, "        return nValue;\n")
+	fmt.Fprintf(implw, "    if (napi_get_value_int64 (env, value, &nValue) != napi_ok)\n")
+	fmt.Fprintf(implw, "        throw std::runtime_error (pErrorMessage);\n")
+	fmt.Fprintf(implw, "    return nValue;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "%s_double C%sBaseClass::getDouble (napi_env env, napi_value value, const char * pErrorMessage)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    double dValue = 0.0;\n")
+	fmt.Fprintf(implw, "    if (napi_get_value_double (env, value, &dValue) != napi_ok)\n")
+	fmt.Fprintf(implw, "        throw std::runtime_error (pErrorMessage);\n")
+	fmt.Fprintf(implw, "    return dValue;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "bool C%sBaseClass::getBool (napi_env env, napi_value value, const char * pErrorMessage)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    bool bValue = false;\n")
+	fmt.Fprintf(implw, "    if (napi_get_value_bool (env, value, &bValue) != napi_ok)\n")
+	fmt.Fprintf(implw, "        throw std::runtime_error (pErrorMessage);\n")
+	fmt.Fprintf(implw, "    return bValue;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "std::string C%sBaseClass::getString (napi_env env, napi_value value, const char * pErrorMessage)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    size_t nLength = 0;\n")
+	fmt.Fprintf(implw, "    if (napi_get_value_string_utf8 (env, value, nullptr, 0, &nLength) != napi_ok)\n")
+	fmt.Fprintf(implw, "        throw std::runtime_error (pErrorMessage);\n")
+	fmt.Fprintf(implw, "    std::vector<char> buffer (nLength + 1);\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_get_value_string_utf8 (env, value, &buffer[0], buffer.size (), &nLength));\n")
+	fmt.Fprintf(implw, "    return std::string (&buffer[0], nLength);\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "%sHandle C%sBaseClass::getObjectHandle (napi_env env, napi_value value, const char * pErrorMessage)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // null and undefined are passed as null handle\n")
+	fmt.Fprintf(implw, "    napi_valuetype valueType = napi_undefined;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_typeof (env, value, &valueType));\n")
+	fmt.Fprintf(implw, "    if ((valueType == napi_null) || (valueType == napi_undefined))\n")
+	fmt.Fprintf(implw, "        return nullptr;\n")
+	fmt.Fprintf(implw, "    void * pInstance = nullptr;\n")
+	fmt.Fprintf(implw, "    if ((valueType != napi_object) || (napi_unwrap (env, value, &pInstance) != napi_ok) || (pInstance == nullptr))\n")
+	fmt.Fprintf(implw, "        throw std::runtime_error (pErrorMessage);\n")
+	fmt.Fprintf(implw, "    return ((C%sBaseClass *) pInstance)->getHandle ();\n", NameSpace)
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+
+	fmt.Fprintf(implw, "napi_value C%sBaseClass::createUInt32 (napi_env env, %s_uint32 nValue)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    napi_value result = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_create_uint32 (env, nValue, &result));\n")
+	fmt.Fprintf(implw, "    return result;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "napi_value C%sBaseClass::createInt32 (napi_env env, %s_int32 nValue)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    napi_value result = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_create_int32 (env, nValue, &result));\n")
+	fmt.Fprintf(implw, "    return result;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "napi_value C%sBaseClass::createUInt64 (napi_env env, %s_uint64 nValue)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // 64bit integers are returned as Number\n")
+	fmt.Fprintf(implw, "    napi_value result = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_create_double (env, (double) nValue, &result));\n")
+	fmt.Fprintf(implw, "    return result;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "napi_value C%sBaseClass::createInt64 (napi_env env, %s_int64 nValue)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // 64bit integers are returned as Number\n")
+	fmt.Fprintf(implw, "    napi_value result = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_create_int64 (env, nValue, &result));\n")
+	fmt.Fprintf(implw, "    return result;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "napi_value C%sBaseClass::createDouble (napi_env env, %s_double dValue)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    napi_value result = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_create_double (env, dValue, &result));\n")
+	fmt.Fprintf(implw, "    return result;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "napi_value C%sBaseClass::createBool (napi_env env, bool bValue)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    napi_value result = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_get_boolean (env, bValue, &result));\n")
+	fmt.Fprintf(implw, "    return result;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "napi_value C%sBaseClass::createString (napi_env env, const char * pValue)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    napi_value result = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_create_string_utf8 (env, pValue, NAPI_AUTO_LENGTH, &result));\n")
+	fmt.Fprintf(implw, "    return result;\n")
 	fmt.Fprintf(implw, "}\n")
 	fmt.Fprintf(implw, "\n")
-	fmt.Fprintf(implw, "%sHandle C%sBaseClass::getHandle (v8::Handle<v8::Object> objecthandle)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "napi_value C%sBaseClass::createNull (napi_env env)\n", NameSpace)
 	fmt.Fprintf(implw, "{\n")
-	fmt.Fprintf(implw, "    auto Field = objecthandle->GetInternalField (NODEWRAPPER_HANDLEINDEX);\n")
-	fmt.Fprintf(implw, "    v8::Local<v8::External> externalField = Field.As<v8::External> ();\n")
-	fmt.Fprintf(implw, "    return (%sHandle *) externalField->Value();\n", NameSpace)
+	fmt.Fprintf(implw, "    napi_value result = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_get_null (env, &result));\n")
+	fmt.Fprintf(implw, "    return result;\n")
 	fmt.Fprintf(implw, "}\n")
 	fmt.Fprintf(implw, "\n")
-	fmt.Fprintf(implw, "s%sDynamicWrapperTable * C%sBaseClass::getDynamicWrapperTable (v8::Handle<v8::Object> objecthandle)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "napi_value C%sBaseClass::createObject (napi_env env)\n", NameSpace)
 	fmt.Fprintf(implw, "{\n")
-	fmt.Fprintf(implw, "    auto Field = objecthandle->GetInternalField (NODEWRAPPER_TABLEINDEX);\n")
-	fmt.Fprintf(implw, "    v8::Local<v8::External> externalField = Field.As<v8::External> ();\n")
-	fmt.Fprintf(implw, "    return (s%sDynamicWrapperTable *) externalField->Value();\n", NameSpace)
+	fmt.Fprintf(implw, "    napi_value result = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_create_object (env, &result));\n")
+	fmt.Fprintf(implw, "    return result;\n")
 	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sBaseClass::setProperty (napi_env env, napi_value object, const char * pName, napi_value value)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_set_named_property (env, object, pName, value));\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
 
+	fmt.Fprintf(implw, "napi_valuetype C%sBaseClass::getValueType (napi_env env, napi_value value)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    napi_valuetype valueType = napi_undefined;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_typeof (env, value, &valueType));\n")
+	fmt.Fprintf(implw, "    return valueType;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "napi_value C%sBaseClass::getProperty (napi_env env, napi_value object, const char * pName)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    napi_value result = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_get_named_property (env, object, pName, &result));\n")
+	fmt.Fprintf(implw, "    return result;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "napi_value C%sBaseClass::getArrayElement (napi_env env, napi_value array, uint32_t nIndex, const char * pErrorMessage)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    bool bIsArray = false;\n")
+	fmt.Fprintf(implw, "    uint32_t nLength = 0;\n")
+	fmt.Fprintf(implw, "    if ((napi_is_array (env, array, &bIsArray) != napi_ok) || !bIsArray)\n")
+	fmt.Fprintf(implw, "        throw std::runtime_error (pErrorMessage);\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_get_array_length (env, array, &nLength));\n")
+	fmt.Fprintf(implw, "    if (nIndex >= nLength)\n")
+	fmt.Fprintf(implw, "        throw std::runtime_error (pErrorMessage);\n")
+	fmt.Fprintf(implw, "    napi_value result = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_get_element (env, array, nIndex, &result));\n")
+	fmt.Fprintf(implw, "    return result;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sBaseClass::setArrayElement (napi_env env, napi_value array, uint32_t nIndex, napi_value value)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_set_element (env, array, nIndex, value));\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "napi_value C%sBaseClass::createArray (napi_env env, size_t nLength)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    napi_value result = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_create_array_with_length (env, nLength, &result));\n")
+	fmt.Fprintf(implw, "    return result;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "napi_value C%sBaseClass::createTypedArray (napi_env env, napi_typedarray_type arrayType, %s_uint64 nCount, size_t nElementSize, void ** ppData)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // The library writes the elements directly into the memory of the TypedArray\n")
+	fmt.Fprintf(implw, "    napi_value arrayBuffer = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_create_arraybuffer (env, (size_t) nCount * nElementSize, ppData, &arrayBuffer));\n")
+	fmt.Fprintf(implw, "    napi_value result = nullptr;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_create_typedarray (env, arrayType, (size_t) nCount, arrayBuffer, 0, &result));\n")
+	fmt.Fprintf(implw, "    return result;\n")
+	fmt.Fprintf(implw, "}\n")
 	fmt.Fprintf(implw, "\n")
+
+	err := writeNodeStructConversions(component, implw, NameSpace)
+	if err != nil {
+		return err
+	}
+
+	cancellationTypes := component.GetCancellationTypes()
+	for i := 0; i < len(component.Functions); i++ {
+		abortIndex, isCancellationType := cancellationTypes[component.Functions[i].FunctionName]
+		if !isCancellationType {
+			abortIndex = -1
+		}
+		err = writeNodeCallback(component.Functions[i], implw, NameSpace, abortIndex)
+		if err != nil {
+			return err
+		}
+	}
+
 	fmt.Fprintf(implw, "\n")
 
 	for i := 0; i < len(component.Classes); i++ {
@@ -664,76 +1596,57 @@ func buildNodeWrapperClass(component ComponentDefinition, w io.Writer, implw io.
 		fmt.Fprintf(implw, "**************************************************************************************************************************/\n")
 		fmt.Fprintf(implw, "\n")
 
-		fmt.Fprintf(implw, "C%s%s::C%s%s ()\n", NameSpace, class.ClassName, NameSpace, class.ClassName)
-		fmt.Fprintf(implw, "    : C%sBaseClass ()\n", NameSpace)
+		fmt.Fprintf(implw, "C%s%s::C%s%s (std::shared_ptr<s%sDynamicWrapperTable> pWrapperTable, %sHandle pHandle)\n", NameSpace, class.ClassName, NameSpace, class.ClassName, NameSpace, NameSpace)
+		fmt.Fprintf(implw, "    : C%sBaseClass (pWrapperTable, pHandle)\n", NameSpace)
 		fmt.Fprintf(implw, "{\n")
 		fmt.Fprintf(implw, "}\n")
 		fmt.Fprintf(implw, "\n")
-		fmt.Fprintf(implw, "C%s%s::~C%s%s()\n", NameSpace, class.ClassName, NameSpace, class.ClassName)
+		fmt.Fprintf(implw, "void C%s%s::Init (napi_env env, napi_value exports)\n", NameSpace, class.ClassName)
 		fmt.Fprintf(implw, "{\n")
+		fmt.Fprintf(implw, "    std::vector<napi_property_descriptor> methods;\n")
+		fmt.Fprintf(implw, "    addMethods (methods);\n")
+		fmt.Fprintf(implw, "    defineClass (env, \"%s\", methods, &constructor, exports);\n", class.ClassName)
 		fmt.Fprintf(implw, "}\n")
 		fmt.Fprintf(implw, "\n")
-		fmt.Fprintf(implw, "void C%s%s::Init()\n", NameSpace, class.ClassName)
+
+		fmt.Fprintf(implw, "void C%s%s::addMethods (std::vector<napi_property_descriptor> & methods)\n", NameSpace, class.ClassName)
 		fmt.Fprintf(implw, "{\n")
-		fmt.Fprintf(implw, "    Isolate* isolate = Isolate::GetCurrent();\n")
-		fmt.Fprintf(implw, "\n")
-		fmt.Fprintf(implw, "    // Prepare constructor template\n")
-		fmt.Fprintf(implw, "    Local<FunctionTemplate> tpl = FunctionTemplate::New(isolate, New);\n")
-		fmt.Fprintf(implw, "    tpl->SetClassName(String::NewFromUtf8(isolate, \"%s%s\"));\n", NameSpace, class.ClassName)
-		fmt.Fprintf(implw, "    tpl->InstanceTemplate()->SetInternalFieldCount(NODEWRAPPER_FIELDCOUNT);\n")
-		fmt.Fprintf(implw, "\n")
+		if class.ParentClass != "" {
+			fmt.Fprintf(implw, "    // Prototype of the parent class\n")
+			fmt.Fprintf(implw, "    C%s%s::addMethods (methods);\n", NameSpace, class.ParentClass)
+			fmt.Fprintf(implw, "\n")
+		}
 		fmt.Fprintf(implw, "    // Prototype\n")
-
 		for j := 0; j < len(class.Methods); j++ {
 			method := class.Methods[j]
-			fmt.Fprintf(implw, "    NODE_SET_PROTOTYPE_METHOD(tpl, \"%s\", %s);\n", method.MethodName, method.MethodName)
+			fmt.Fprintf(implw, "    methods.push_back ({ \"%s\", nullptr, %s, nullptr, nullptr, nullptr, napi_default, nullptr });\n", method.MethodName, method.MethodName)
+			if method.Async {
+				fmt.Fprintf(implw, "    methods.push_back ({ \"%sAsync\", nullptr, %sAsync, nullptr, nullptr, nullptr, napi_default, nullptr });\n", method.MethodName, method.MethodName)
+			}
 		}
-
-		fmt.Fprintf(implw, "    constructor.Reset(isolate, tpl->GetFunction());\n")
-		fmt.Fprintf(implw, "\n")
-		fmt.Fprintf(implw, "}\n")
-		fmt.Fprintf(implw, "\n")
-
-		fmt.Fprintf(implw, "void C%s%s::New(const FunctionCallbackInfo<Value>& args)\n", NameSpace, class.ClassName)
-		fmt.Fprintf(implw, "{\n")
-		fmt.Fprintf(implw, "    Isolate* isolate = Isolate::GetCurrent();\n")
-		fmt.Fprintf(implw, "    HandleScope scope(isolate);\n")
-		fmt.Fprintf(implw, "\n")
-		fmt.Fprintf(implw, "    if (args.IsConstructCall()) {\n")
-		fmt.Fprintf(implw, "        C%sBaseClass * holderObj = ObjectWrap::Unwrap<C%sBaseClass>(args.Holder());\n", NameSpace, NameSpace)
-		fmt.Fprintf(implw, "        C%s%s * %sInstance = new C%s%s ();\n", NameSpace, class.ClassName, strings.ToLower(class.ClassName), NameSpace, class.ClassName)
-
-		//fmt.Fprintf (implw, "        Local<Object> localHolderObj = args.Holder();\n");
-		//fmt.Fprintf (implw, "        %sInstance->m_pWrapperObject.Reset (localHolderObj);\n", strings.ToLower (class.ClassName));
-
-		fmt.Fprintf(implw, "        %sInstance->Wrap(args.This());\n", strings.ToLower(class.ClassName))
-		fmt.Fprintf(implw, "        args.GetReturnValue().Set(args.This());\n")
-		fmt.Fprintf(implw, "    } else {\n")
-		fmt.Fprintf(implw, "        RaiseError (isolate, \"%s%s: Invalid call to Constructor\");\n", NameSpace, class.ClassName)
-		fmt.Fprintf(implw, "    }\n")
 		fmt.Fprintf(implw, "}\n")
 		fmt.Fprintf(implw, "\n")
 
-		fmt.Fprintf(implw, "Local<Object> C%s%s::NewInstance(Local<Object> pParent, %sHandle pHandle)\n", NameSpace, class.ClassName, NameSpace)
+		fmt.Fprintf(implw, "napi_value C%s%s::NewInstance (napi_env env, std::shared_ptr<s%sDynamicWrapperTable> pWrapperTable, %sHandle pHandle)\n", NameSpace, class.ClassName, NameSpace, NameSpace)
 		fmt.Fprintf(implw, "{\n")
-		fmt.Fprintf(implw, "    Isolate* isolate = Isolate::GetCurrent();\n")
-		fmt.Fprintf(implw, "    HandleScope scope(isolate);\n")
-		fmt.Fprintf(implw, "    const unsigned argc = 1; // TODO: Find out how to not pass dummy parameters..\n")
-		fmt.Fprintf(implw, "    Handle<Value> argv[argc] = { Number::New (isolate, 0.0) };\n")
-		fmt.Fprintf(implw, "    Local<Function> cons = Local<Function>::New(isolate, constructor);\n")
-		fmt.Fprintf(implw, "    Local<Object> instance = cons->NewInstance(argc, argv);\n")
-		fmt.Fprintf(implw, "    instance->SetInternalField (NODEWRAPPER_TABLEINDEX, External::New (isolate, C%sBaseClass::getDynamicWrapperTable (pParent)));\n", NameSpace)
-		fmt.Fprintf(implw, "    instance->SetInternalField (NODEWRAPPER_HANDLEINDEX, External::New (isolate, pHandle));\n")
-		fmt.Fprintf(implw, "    return instance;\n")
+		fmt.Fprintf(implw, "    if (pHandle == nullptr)\n")
+		fmt.Fprintf(implw, "        return createNull (env);\n")
+		fmt.Fprintf(implw, "    return newInstance (env, constructor, new C%s%s (pWrapperTable, pHandle));\n", NameSpace, class.ClassName)
 		fmt.Fprintf(implw, "}\n")
 		fmt.Fprintf(implw, "\n")
 
 		for j := 0; j < len(class.Methods); j++ {
 			method := class.Methods[j]
-			err := writeNodeMethodImplementation(method, implw, NameSpace, class.ClassName, false)
+			err := writeNodeMethodImplementation(method, implw, NameSpace, class.ClassName, false, hasErrorMethod, len(component.Functions) > 0)
 			if err != nil {
 				return err
 			}
+			if method.Async {
+				err = writeNodeAsyncMethodImplementation(component, method, implw, NameSpace, class.ClassName, false, hasErrorMethod)
+				if err != nil {
+					return err
+				}
+			}
 		}
 	}
 
@@ -742,89 +1655,62 @@ func buildNodeWrapperClass(component ComponentDefinition, w io.Writer, implw io.
 	fmt.Fprintf(implw, "**************************************************************************************************************************/\n")
 	fmt.Fprintf(implw, "\n")
 
-	fmt.Fprintf(implw, "C%sWrapper::C%sWrapper()\n", NameSpace, NameSpace)
-	fmt.Fprintf(implw, "    : C%sBaseClass ()\n", NameSpace)
-	fmt.Fprintf(implw, "{\n")
-	fmt.Fprintf(implw, "}\n")
-	fmt.Fprintf(implw, "\n")
-	fmt.Fprintf(implw, "C%sWrapper::~C%sWrapper()\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "C%sWrapper::C%sWrapper (std::shared_ptr<s%sDynamicWrapperTable> pWrapperTable)\n", NameSpace, NameSpace, NameSpace)
+	fmt.Fprintf(implw, "    : C%sBaseClass (pWrapperTable, nullptr)\n", NameSpace)
 	fmt.Fprintf(implw, "{\n")
-	fmt.Fprintf(implw, "\n")
 	fmt.Fprintf(implw, "}\n")
 	fmt.Fprintf(implw, "\n")
-	fmt.Fprintf(implw, "void C%sWrapper::Init()\n", NameSpace)
+	fmt.Fprintf(implw, "void C%sWrapper::Init (napi_env env, napi_value exports)\n", NameSpace)
 	fmt.Fprintf(implw, "{\n")
-	fmt.Fprintf(implw, "    Isolate* isolate = Isolate::GetCurrent();\n")
-	fmt.Fprintf(implw, "    // Prepare constructor template\n")
-	fmt.Fprintf(implw, "    Local<FunctionTemplate> tpl = FunctionTemplate::New(isolate, New);\n")
-	fmt.Fprintf(implw, "    tpl->SetClassName(String::NewFromUtf8(isolate, \"%sWrapper\"));\n", NameSpace)
-	fmt.Fprintf(implw, "    tpl->InstanceTemplate()->SetInternalFieldCount(NODEWRAPPER_FIELDCOUNT);\n")
+	fmt.Fprintf(implw, "    std::vector<napi_property_descriptor> methods;\n")
 	fmt.Fprintf(implw, "    \n")
 	fmt.Fprintf(implw, "    // Prototype\n")
-	fmt.Fprintf(implw, "    \n")
 	global = component.Global
 	for j := 0; j < len(global.Methods); j++ {
 		method := global.Methods[j]
-		fmt.Fprintf(implw, "    NODE_SET_PROTOTYPE_METHOD(tpl, \"%s\", %s);\n", method.MethodName, method.MethodName)
+		fmt.Fprintf(implw, "    methods.push_back ({ \"%s\", nullptr, %s, nullptr, nullptr, nullptr, napi_default, nullptr });\n", method.MethodName, method.MethodName)
+		if method.Async {
+			fmt.Fprintf(implw, "    methods.push_back ({ \"%sAsync\", nullptr, %sAsync, nullptr, nullptr, nullptr, napi_default, nullptr });\n", method.MethodName, method.MethodName)
+		}
 	}
-
-	fmt.Fprintf(implw, "    constructor.Reset(isolate, tpl->GetFunction());\n")
+	fmt.Fprintf(implw, "    \n")
+	fmt.Fprintf(implw, "    defineClass (env, \"%sWrapper\", methods, &constructor, exports);\n", NameSpace)
 	fmt.Fprintf(implw, "}\n")
 	fmt.Fprintf(implw, "\n")
-	fmt.Fprintf(implw, "void C%sWrapper::New(const FunctionCallbackInfo<Value>& args)\n", NameSpace)
+	fmt.Fprintf(implw, "napi_value C%sWrapper::NewInstance (napi_env env, napi_value libraryName)\n", NameSpace)
 	fmt.Fprintf(implw, "{\n")
-	fmt.Fprintf(implw, "    Isolate* isolate = Isolate::GetCurrent();\n")
-	fmt.Fprintf(implw, "    HandleScope scope(isolate);\n")
+	fmt.Fprintf(implw, "    // Get Library Name as Argument\n")
+	fmt.Fprintf(implw, "#if defined(_WIN32)\n")
+	fmt.Fprintf(implw, "    std::string sLibraryName = \"%s.dll\";\n", BaseName)
+	fmt.Fprintf(implw, "#elif defined(__APPLE__)\n")
+	fmt.Fprintf(implw, "    std::string sLibraryName = \"%s.dylib\";\n", BaseName)
+	fmt.Fprintf(implw, "#else\n")
+	fmt.Fprintf(implw, "    std::string sLibraryName = \"%s.so\";\n", BaseName)
+	fmt.Fprintf(implw, "#endif\n")
+	fmt.Fprintf(implw, "    napi_valuetype valueType = napi_undefined;\n")
+	fmt.Fprintf(implw, "    CheckStatus (env, napi_typeof (env, libraryName, &valueType));\n")
+	fmt.Fprintf(implw, "    if (valueType != napi_undefined)\n")
+	fmt.Fprintf(implw, "        sLibraryName = getString (env, libraryName, \"Expected string parameter 0 (LibraryName)\");\n")
 	fmt.Fprintf(implw, "\n")
-	fmt.Fprintf(implw, "    try {\n")
-	fmt.Fprintf(implw, "        if (args.IsConstructCall()) {\n")
-
-	fmt.Fprintf(implw, "            // Get Library Name as Argument\n")
-	fmt.Fprintf(implw, "            std::string sLibraryName = \"%s.dll\";\n", BaseName)
-	fmt.Fprintf(implw, "            if (args[0]->IsString()) {\n")
-	fmt.Fprintf(implw, "                v8::String::Utf8Value stringParam (args[0]->ToString());\n")
-	fmt.Fprintf(implw, "                sLibraryName = *stringParam;\n")
-	fmt.Fprintf(implw, "            }\n")
-
-	fmt.Fprintf(implw, "            // Invoked as constructor: `new %sWrapper(...)`\n", NameSpace)
-	fmt.Fprintf(implw, "            C%sWrapper* obj = new C%sWrapper ();\n", NameSpace, NameSpace)
-
-	fmt.Fprintf(implw, "            Local<Object> newObject = args.This();\n")
-	fmt.Fprintf(implw, "            std::auto_ptr<s%sDynamicWrapperTable> wrapperTable ( new s%sDynamicWrapperTable );\n", NameSpace, NameSpace)
-	fmt.Fprintf(implw, "            CheckError (Load%sWrapperTable (wrapperTable.get(), sLibraryName.c_str()));\n", NameSpace)
-	fmt.Fprintf(implw, "            newObject->SetInternalField (NODEWRAPPER_TABLEINDEX, External::New (isolate, wrapperTable.release ()));\n")
+	fmt.Fprintf(implw, "    // The library is unloaded, when the last of its objects is garbage collected\n")
+	fmt.Fprintf(implw, "    std::shared_ptr<s%sDynamicWrapperTable> pWrapperTable (new s%sDynamicWrapperTable (), [] (s%sDynamicWrapperTable * pTable) {\n", NameSpace, NameSpace, NameSpace)
+	fmt.Fprintf(implw, "        Release%sWrapperTable (pTable);\n", NameSpace)
+	fmt.Fprintf(implw, "        delete pTable;\n")
+	fmt.Fprintf(implw, "    });\n")
+	fmt.Fprintf(implw, "    CheckError (Init%sWrapperTable (pWrapperTable.get ()));\n", NameSpace)
+	fmt.Fprintf(implw, "    CheckError (Load%sWrapperTable (pWrapperTable.get (), sLibraryName.c_str ()));\n", NameSpace)
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "    napi_value instance = newInstance (env, constructor, new C%sWrapper (pWrapperTable));\n", NameSpace)
 
 	// write out enums
 	for i := 0; i < len(component.Enums); i++ {
-		enum := component.Enums[i];		
-		for j := 0; j < len(enum.Options); j++ {							
+		enum := component.Enums[i];
+		for j := 0; j < len(enum.Options); j++ {
 			option := enum.Options[j];
-			fmt.Fprintf (implw, "            newObject->Set (String::NewFromUtf8(isolate, \"e%s_%s\"), Integer::New(isolate, %d));\n", enum.Name, option.Name, option.Value);
-		}		
-	}
-
-	fmt.Fprintf(implw, "            obj->Wrap(newObject);\n")
-	fmt.Fprintf(implw, "            args.GetReturnValue().Set(newObject);\n")
-	fmt.Fprintf(implw, "        } else {\n")
-	fmt.Fprintf(implw, "            // Invoked as plain function `%sWrapper(...)`, turn into construct call.\n", NameSpace)
-	fmt.Fprintf(implw, "            const int argc = 1;\n")
-	fmt.Fprintf(implw, "            Local<Value> argv[argc] = { args[0] };\n")
-	fmt.Fprintf(implw, "            Local<Function> cons = Local<Function>::New(isolate, constructor);\n")
-	fmt.Fprintf(implw, "            args.GetReturnValue().Set(cons->NewInstance(argc, argv));\n")
-	fmt.Fprintf(implw, "        }\n")
-	fmt.Fprintf(implw, "    } catch (std::exception & E) {\n")
-	fmt.Fprintf(implw, "        RaiseError (isolate, E.what());\n")
-	fmt.Fprintf(implw, "    }\n")
-	fmt.Fprintf(implw, "}\n")
-	fmt.Fprintf(implw, "\n")
-	fmt.Fprintf(implw, "Local<Object> C%sWrapper::NewInstance ()\n", NameSpace)
-	fmt.Fprintf(implw, "{\n")
-	fmt.Fprintf(implw, "    Isolate* isolate = Isolate::GetCurrent();\n")
-	fmt.Fprintf(implw, "    HandleScope scope(isolate);\n")
-	fmt.Fprintf(implw, "    const unsigned argc = 1; // TODO: Find out how to not pass dummy parameters..\n")
-	fmt.Fprintf(implw, "    Handle<Value> argv[argc] = { Number::New (isolate, 0.0) };\n")
-	fmt.Fprintf(implw, "    Local<Function> cons = Local<Function>::New(isolate, constructor);\n")
-	fmt.Fprintf(implw, "    Local<Object> instance = cons->NewInstance(argc, argv);\n")
+			fmt.Fprintf (implw, "    setProperty (env, instance, \"e%s_%s\", createInt32 (env, %d));\n", enum.Name, option.Name, option.Value);
+		}
+	}
+
 	fmt.Fprintf(implw, "    return instance;\n")
 	fmt.Fprintf(implw, "}\n")
 
@@ -834,10 +1720,20 @@ func buildNodeWrapperClass(component ComponentDefinition, w io.Writer, implw io.
 	for j := 0; j < len(global.Methods); j++ {
 		method := global.Methods[j]
 
-		err := writeNodeMethodImplementation(method, implw, NameSpace, "Wrapper", true)
+		if method.MethodName == global.ReleaseMethod {
+			writeNodeReleaseMethod(method, implw, NameSpace)
+			continue
+		}
+		err := writeNodeMethodImplementation(method, implw, NameSpace, "Wrapper", true, hasErrorMethod, len(component.Functions) > 0)
 		if err != nil {
 			return err
 		}
+		if method.Async {
+			err = writeNodeAsyncMethodImplementation(component, method, implw, NameSpace, "Wrapper", true, hasErrorMethod)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	fmt.Fprintf(implw, "\n")
@@ -845,21 +1741,1611 @@ func buildNodeWrapperClass(component ComponentDefinition, w io.Writer, implw io.
 	return nil
 }
 
+// getNodeValueReader returns the expression, which converts a JS value into a value of a basic type
+func getNodeValueReader(NameSpace string, paramType string, paramClass string, value string, errorMessage string) (string, error) {
+	switch paramType {
+	case "uint8", "uint16", "uint32":
+		return fmt.Sprintf("(%s_%s) getUInt32 (env, %s, \"%s\")", NameSpace, paramType, value, errorMessage), nil
+	case "int8", "int16", "int32":
+		return fmt.Sprintf("(%s_%s) getInt32 (env, %s, \"%s\")", NameSpace, paramType, value, errorMessage), nil
+	case "uint64":
+		return fmt.Sprintf("getUInt64 (env, %s, \"%s\")", value, errorMessage), nil
+	case "int64":
+		return fmt.Sprintf("getInt64 (env, %s, \"%s\")", value, errorMessage), nil
+	case "bool":
+		return fmt.Sprintf("getBool (env, %s, \"%s\")", value, errorMessage), nil
+	case "single":
+		return fmt.Sprintf("(%s_single) getDouble (env, %s, \"%s\")", NameSpace, value, errorMessage), nil
+	case "double":
+		return fmt.Sprintf("getDouble (env, %s, \"%s\")", value, errorMessage), nil
+	case "enum":
+		return fmt.Sprintf("(e%s%s) getInt32 (env, %s, \"%s\")", NameSpace, paramClass, value, errorMessage), nil
+	}
+	return "", fmt.Errorf("can not convert JS value to type \"%s\"", paramType)
+}
 
-func buildNodeBindingGyp(component ComponentDefinition, w io.Writer, indentString string) error {
+// getNodeValueCreator returns the expression, which converts a value of a basic type into a JS value
+func getNodeValueCreator(NameSpace string, paramType string, value string) (string, error) {
+	switch paramType {
+	case "uint8", "uint16", "uint32":
+		return fmt.Sprintf("createUInt32 (env, %s)", value), nil
+	case "int8", "int16", "int32":
+		return fmt.Sprintf("createInt32 (env, %s)", value), nil
+	case "uint64":
+		return fmt.Sprintf("createUInt64 (env, %s)", value), nil
+	case "int64":
+		return fmt.Sprintf("createInt64 (env, %s)", value), nil
+	case "bool":
+		return fmt.Sprintf("createBool (env, %s)", value), nil
+	case "single", "double":
+		return fmt.Sprintf("createDouble (env, %s)", value), nil
+	case "enum":
+		return fmt.Sprintf("createInt32 (env, (%s_int32) %s)", NameSpace, value), nil
+	}
+	return "", fmt.Errorf("can not convert type \"%s\" to JS value", paramType)
+}
 
-	BaseName := component.BaseName;
+// getNodeElementGetter returns the method, which converts a JS value into an element of a basicarray
+func getNodeElementGetter(elementType string) (string, error) {
+	switch elementType {
+	case "uint8", "uint16", "uint32":
+		return "getUInt32", nil
+	case "int8", "int16", "int32":
+		return "getInt32", nil
+	case "uint64":
+		return "getUInt64", nil
+	case "int64":
+		return "getInt64", nil
+	case "bool":
+		return "getBool", nil
+	case "single", "double":
+		return "getDouble", nil
+	}
+	return "", fmt.Errorf("invalid basicarray element type \"%s\"", elementType)
+}
 
-	fmt.Fprintf(w, "{\n")
-	fmt.Fprintf(w, "%s\"targets\": [\n", indentString)
-	fmt.Fprintf(w, "%s%s{\n", indentString, indentString)
-	fmt.Fprintf(w, "%s%s%s\"target_name\": \"%s_nodeaddon\",\n", indentString, indentString, indentString, BaseName)
-	fmt.Fprintf(w, "%s%s%s\"sources\": [ \"%s_nodeaddon.cc\", \"%s_nodewrapper.cc\", \"%s_dynamic.cpp\" ]\n", indentString, indentString, indentString, BaseName, BaseName, BaseName)
-	fmt.Fprintf(w, "%s%s}\n", indentString, indentString)
-	fmt.Fprintf(w, "%s]\n", indentString)
-	fmt.Fprintf(w, "}\n")
-	fmt.Fprintf(w, "\n")
-	
-	return nil;
-  
+// getNodeElementCreator returns the method, which converts an element of a basicarray into a JS value
+func getNodeElementCreator(elementType string) (string, error) {
+	switch elementType {
+	case "uint8", "uint16", "uint32":
+		return "createUInt32", nil
+	case "int8", "int16", "int32":
+		return "createInt32", nil
+	case "uint64":
+		return "createUInt64", nil
+	case "int64":
+		return "createInt64", nil
+	case "bool":
+		return "createBool", nil
+	case "single", "double":
+		return "createDouble", nil
+	}
+	return "", fmt.Errorf("invalid basicarray element type \"%s\"", elementType)
+}
+
+// getNodeTypedArrayType returns the TypedArray type of the elements of a basicarray, or "" if there is none
+func getNodeTypedArrayType(elementType string) string {
+	switch elementType {
+	case "uint8":
+		return "napi_uint8_array"
+	case "uint16":
+		return "napi_uint16_array"
+	case "uint32":
+		return "napi_uint32_array"
+	case "uint64":
+		return "napi_biguint64_array"
+	case "int8":
+		return "napi_int8_array"
+	case "int16":
+		return "napi_int16_array"
+	case "int32":
+		return "napi_int32_array"
+	case "int64":
+		return "napi_bigint64_array"
+	case "single":
+		return "napi_float32_array"
+	case "double":
+		return "napi_float64_array"
+	}
+	return ""
+}
+
+// writeNodeStructConversions writes the conversions between JS objects and the structs of the component
+func writeNodeStructConversions(component ComponentDefinition, implw io.Writer, NameSpace string) error {
+
+	for i := 0; i < len(component.Structs); i++ {
+		structinfo := component.Structs[i]
+
+		fmt.Fprintf(implw, "s%s%s C%sBaseClass::getStruct%s (napi_env env, napi_value value, const char * pErrorMessage)\n", NameSpace, structinfo.Name, NameSpace, structinfo.Name)
+		fmt.Fprintf(implw, "{\n")
+		fmt.Fprintf(implw, "    if (getValueType (env, value) != napi_object)\n")
+		fmt.Fprintf(implw, "        throw std::runtime_error (pErrorMessage);\n")
+		fmt.Fprintf(implw, "    s%s%s result;\n", NameSpace, structinfo.Name)
+		fmt.Fprintf(implw, "    memset (&result, 0, sizeof (result));\n")
+
+		for j := 0; j < len(structinfo.Members); j++ {
+			member := structinfo.Members[j]
+
+			// Enum members are stored as integer code
+			memberType := member.Type
+			codeSuffix := ""
+			if member.Type == "enum" {
+				memberType = "int32"
+				codeSuffix = ".m_code"
+			}
+
+			errorMessage := fmt.Sprintf("Expected %s member %s of struct %s", member.Type, member.Name, structinfo.Name)
+			if member.Rows == 0 {
+				reader, err := getNodeValueReader(NameSpace, memberType, member.Class, fmt.Sprintf("getProperty (env, value, \"%s\")", member.Name), errorMessage)
+				if err != nil {
+					return fmt.Errorf("invalid member %s of struct %s: %s", member.Name, structinfo.Name, err)
+				}
+				fmt.Fprintf(implw, "    result.m_%s%s = %s;\n", member.Name, codeSuffix, reader)
+				continue
+			}
+
+			fmt.Fprintf(implw, "    napi_value member%s = getProperty (env, value, \"%s\");\n", member.Name, member.Name)
+			if member.Columns == 0 {
+				reader, err := getNodeValueReader(NameSpace, memberType, member.Class, fmt.Sprintf("getArrayElement (env, member%s, nRow, \"%s\")", member.Name, errorMessage), errorMessage)
+				if err != nil {
+					return fmt.Errorf("invalid member %s of struct %s: %s", member.Name, structinfo.Name, err)
+				}
+				fmt.Fprintf(implw, "    for (uint32_t nRow = 0; nRow < %d; nRow++)\n", member.Rows)
+				fmt.Fprintf(implw, "        result.m_%s[nRow]%s = %s;\n", member.Name, codeSuffix, reader)
+			} else {
+				reader, err := getNodeValueReader(NameSpace, memberType, member.Class, fmt.Sprintf("getArrayElement (env, column%s, nRow, \"%s\")", member.Name, errorMessage), errorMessage)
+				if err != nil {
+					return fmt.Errorf("invalid member %s of struct %s: %s", member.Name, structinfo.Name, err)
+				}
+				fmt.Fprintf(implw, "    for (uint32_t nColumn = 0; nColumn < %d; nColumn++) {\n", member.Columns)
+				fmt.Fprintf(implw, "        napi_value column%s = getArrayElement (env, member%s, nColumn, \"%s\");\n", member.Name, member.Name, errorMessage)
+				fmt.Fprintf(implw, "        for (uint32_t nRow = 0; nRow < %d; nRow++)\n", member.Rows)
+				fmt.Fprintf(implw, "            result.m_%s[nColumn][nRow]%s = %s;\n", member.Name, codeSuffix, reader)
+				fmt.Fprintf(implw, "    }\n")
+			}
+		}
+
+		fmt.Fprintf(implw, "    return result;\n")
+		fmt.Fprintf(implw, "}\n")
+		fmt.Fprintf(implw, "\n")
+
+		fmt.Fprintf(implw, "napi_value C%sBaseClass::createStruct%s (napi_env env, s%s%s value)\n", NameSpace, structinfo.Name, NameSpace, structinfo.Name)
+		fmt.Fprintf(implw, "{\n")
+		fmt.Fprintf(implw, "    napi_value result = createObject (env);\n")
+
+		for j := 0; j < len(structinfo.Members); j++ {
+			member := structinfo.Members[j]
+
+			memberType := member.Type
+			codeSuffix := ""
+			if member.Type == "enum" {
+				memberType = "int32"
+				codeSuffix = ".m_code"
+			}
+
+			if member.Rows == 0 {
+				creator, err := getNodeValueCreator(NameSpace, memberType, fmt.Sprintf("value.m_%s%s", member.Name, codeSuffix))
+				if err != nil {
+					return fmt.Errorf("invalid member %s of struct %s: %s", member.Name, structinfo.Name, err)
+				}
+				fmt.Fprintf(implw, "    setProperty (env, result, \"%s\", %s);\n", member.Name, creator)
+				continue
+			}
+
+			// Two-dimensional members are arrays of columns
+			memberLength := member.Rows
+			if member.Columns > 0 {
+				memberLength = member.Columns
+			}
+			fmt.Fprintf(implw, "    napi_value member%s = createArray (env, %d);\n", member.Name, memberLength)
+			if member.Columns == 0 {
+				creator, err := getNodeValueCreator(NameSpace, memberType, fmt.Sprintf("value.m_%s[nRow]%s", member.Name, codeSuffix))
+				if err != nil {
+					return fmt.Errorf("invalid member %s of struct %s: %s", member.Name, structinfo.Name, err)
+				}
+				fmt.Fprintf(implw, "    for (uint32_t nRow = 0; nRow < %d; nRow++)\n", member.Rows)
+				fmt.Fprintf(implw, "        setArrayElement (env, member%s, nRow, %s);\n", member.Name, creator)
+			} else {
+				creator, err := getNodeValueCreator(NameSpace, memberType, fmt.Sprintf("value.m_%s[nColumn][nRow]%s", member.Name, codeSuffix))
+				if err != nil {
+					return fmt.Errorf("invalid member %s of struct %s: %s", member.Name, structinfo.Name, err)
+				}
+				fmt.Fprintf(implw, "    for (uint32_t nColumn = 0; nColumn < %d; nColumn++) {\n", member.Columns)
+				fmt.Fprintf(implw, "        napi_value column%s = createArray (env, %d);\n", member.Name, member.Rows)
+				fmt.Fprintf(implw, "        for (uint32_t nRow = 0; nRow < %d; nRow++)\n", member.Rows)
+				fmt.Fprintf(implw, "            setArrayElement (env, column%s, nRow, %s);\n", member.Name, creator)
+				fmt.Fprintf(implw, "        setArrayElement (env, member%s, nColumn, column%s);\n", member.Name, member.Name)
+				fmt.Fprintf(implw, "    }\n")
+			}
+			fmt.Fprintf(implw, "    setProperty (env, result, \"%s\", member%s);\n", member.Name, member.Name)
+		}
+
+		fmt.Fprintf(implw, "    return result;\n")
+		fmt.Fprintf(implw, "}\n")
+		fmt.Fprintf(implw, "\n")
+	}
+
+	return nil
+}
+
+// writeNodeCallback writes the function, which calls the JS function passed for a function type, and the table of the
+// callbacks of its slots. abortIndex is the index of the boolean output parameter, that aborts an asynchronous call,
+// if its AbortSignal is aborted, or -1.
+func writeNodeCallback(functiontype ComponentDefinitionFunctionType, implw io.Writer, NameSpace string, abortIndex int) error {
+	parameters, _, err := getCCallbackParameters(functiontype, NameSpace)
+	if err != nil {
+		return err
+	}
+
+	argumentcode := ""
+	argumentcount := 0
+	outputs := []ComponentDefinitionParam{}
+
+	for j := 0; j < len(functiontype.Params); j++ {
+		param := functiontype.Params[j]
+		cParams, err := generateCParameter(param, "", functiontype.FunctionName, NameSpace)
+		if err != nil {
+			return err
+		}
+		cParamName := cParams[0].ParamName
+
+		if param.ParamPass != "in" {
+			switch param.ParamType {
+			case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "bool", "single", "double", "enum":
+				outputs = append(outputs, param)
+			default:
+				return fmt.Errorf("can not return parameter type \"%s\" from callback %s (%s) in NodeJS", param.ParamType, functiontype.FunctionName, param.ParamName)
+			}
+			continue
+		}
+
+		argument := ""
+		switch param.ParamType {
+		case "string":
+			argument = fmt.Sprintf("createString (env, (%s != nullptr) ? %s : \"\")", cParamName, cParamName)
+		case "struct":
+			argument = fmt.Sprintf("(%s != nullptr) ? createStruct%s (env, *%s) : createNull (env)", cParamName, param.ParamClass, cParamName)
+		default:
+			argument, err = getNodeValueCreator(NameSpace, param.ParamType, cParamName)
+			if err != nil {
+				return fmt.Errorf("can not pass parameter type \"%s\" to callback %s (%s) in NodeJS", param.ParamType, functiontype.FunctionName, param.ParamName)
+			}
+		}
+		argumentcode = argumentcode + fmt.Sprintf("        args[%d] = %s;\n", argumentcount, argument)
+		argumentcount = argumentcount + 1
+	}
+
+	if len(parameters) > 0 {
+		parameters = ", " + parameters
+	}
+	fmt.Fprintf(implw, "void C%sBaseClass::call%s (int nSlot%s)\n", NameSpace, functiontype.FunctionName, parameters)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // Calls the JS function passed to the innermost running method, or else the JS function kept in the slot, which\n")
+	fmt.Fprintf(implw, "    // the library may call after the method has returned\n")
+	fmt.Fprintf(implw, "    auto callFunction = [&] (C%sCallbackScope * pScope) {\n", NameSpace)
+	fmt.Fprintf(implw, "        pScope->run ([&] (napi_env env) {\n")
+	call := "pScope->call (0, nullptr)"
+	if argumentcount > 0 {
+		fmt.Fprintf(implw, "            napi_value args[%d];\n", argumentcount)
+		fmt.Fprint(implw, argumentcode)
+		call = fmt.Sprintf("pScope->call (%d, args)", argumentcount)
+	}
+
+	// The output parameters keep their values, if the JS function returns undefined
+	if len(outputs) == 0 {
+		fmt.Fprintf(implw, "            %s;\n", call)
+	} else {
+		fmt.Fprintf(implw, "            napi_value result = %s;\n", call)
+		fmt.Fprintf(implw, "            napi_valuetype resultType = getValueType (env, result);\n")
+		fmt.Fprintf(implw, "            if (resultType != napi_undefined) {\n")
+		if len(outputs) > 1 {
+			fmt.Fprintf(implw, "                if (resultType != napi_object)\n")
+			fmt.Fprintf(implw, "                    throw std::runtime_error (\"Expected object result of callback %s\");\n", functiontype.FunctionName)
+		}
+		for _, param := range outputs {
+			cParams, err := generateCParameter(param, "", functiontype.FunctionName, NameSpace)
+			if err != nil {
+				return err
+			}
+			value := "result"
+			if len(outputs) > 1 {
+				value = fmt.Sprintf("getProperty (env, result, \"%s\")", param.ParamName)
+			}
+			errorMessage := fmt.Sprintf("Expected %s result %s of callback %s", param.ParamType, param.ParamName, functiontype.FunctionName)
+			reader, err := getNodeValueReader(NameSpace, param.ParamType, param.ParamClass, value, errorMessage)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(implw, "                if (%s != nullptr)\n", cParams[0].ParamName)
+			fmt.Fprintf(implw, "                    *%s = %s;\n", cParams[0].ParamName, reader)
+		}
+		fmt.Fprintf(implw, "            }\n")
+	}
+
+	fmt.Fprintf(implw, "        });\n")
+	fmt.Fprintf(implw, "    };\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "    C%sCallbackScope * pScope = C%sCallbackScope::find (\"%s\");\n", NameSpace, NameSpace, functiontype.FunctionName)
+	fmt.Fprintf(implw, "    if ((pScope == nullptr) || pScope->isEmpty () || (pScope->getSlot () != nSlot)) {\n")
+	fmt.Fprintf(implw, "        C%sCallbackSlot::run (\"%s\", nSlot, callFunction);\n", NameSpace, functiontype.FunctionName)
+	fmt.Fprintf(implw, "        return;\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "    callFunction (pScope);\n")
+
+	if abortIndex >= 0 {
+		cParams, err := generateCParameter(functiontype.Params[abortIndex], "", functiontype.FunctionName, NameSpace)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(implw, "    // An asynchronous call is aborted, if its AbortSignal is aborted or the JS function throws\n")
+		fmt.Fprintf(implw, "    if ((%s != nullptr) && pScope->isAborted ())\n", cParams[0].ParamName)
+		fmt.Fprintf(implw, "        *%s = true;\n", cParams[0].ParamName)
+	}
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+
+	typeName := NameSpace + functiontype.FunctionName
+	fmt.Fprintf(implw, "%s C%sBaseClass::getCallback%s (C%sCallbackScope & callbackScope)\n", typeName, NameSpace, functiontype.FunctionName, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // Each slot has a callback of its own, as the library passes no context to it\n")
+	fmt.Fprintf(implw, "    static const %s slotCallbacks[C%sCallbackSlot::SLOTCOUNT] = {\n", typeName, NameSpace)
+	for slot := 0; slot < nodeCallbackSlots; slot++ {
+		separator := ","
+		if slot == nodeCallbackSlots - 1 {
+			separator = ""
+		}
+		fmt.Fprintf(implw, "        callback%s<%d>%s\n", functiontype.FunctionName, slot, separator)
+	}
+	fmt.Fprintf(implw, "    };\n")
+	fmt.Fprintf(implw, "    if (callbackScope.isEmpty ())\n")
+	fmt.Fprintf(implw, "        return nullptr;\n")
+	fmt.Fprintf(implw, "    // An asynchronous call, to which only an AbortSignal has been passed, has no slot\n")
+	fmt.Fprintf(implw, "    if (callbackScope.getSlot () < 0)\n")
+	fmt.Fprintf(implw, "        return callback%s<-1>;\n", functiontype.FunctionName)
+	fmt.Fprintf(implw, "    return slotCallbacks[callbackScope.getSlot ()];\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+
+	return nil
+}
+
+// nodeCallbackSlots is the number of C callbacks that are generated for each function type
+const nodeCallbackSlots = 32
+
+// buildNodeCallbackSlot declares the class, which keeps the JS functions passed as callbacks, as long as the library may call them
+func buildNodeCallbackSlot(w io.Writer, NameSpace string) {
+
+	fmt.Fprintf(w, "/*************************************************************************************************************************\n")
+	fmt.Fprintf(w, " Class C%sCallbackSlot \n", NameSpace)
+	fmt.Fprintf(w, "**************************************************************************************************************************/\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "class C%sCallbackScope;\n", NameSpace)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "// A JS function kept in a slot, and the number of the callback slots, that share it\n")
+	fmt.Fprintf(w, "struct s%sStoredFunction {\n", NameSpace)
+	fmt.Fprintf(w, "    napi_ref m_FunctionReference;\n")
+	fmt.Fprintf(w, "    int m_nReferences;\n")
+	fmt.Fprintf(w, "};\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "// Keeps a JS function, that is passed as callback, as long as the library may call it. A C callback carries no context,\n")
+	fmt.Fprintf(w, "// so each distinct JS function of a function type occupies one of the slots of the type, which has its own C callback.\n")
+	fmt.Fprintf(w, "class C%sCallbackSlot {\n", NameSpace)
+	fmt.Fprintf(w, "private:\n")
+	fmt.Fprintf(w, "    const char * m_pFunctionType;\n")
+	fmt.Fprintf(w, "    int m_nSlot;\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    static napi_env m_Env;\n")
+	fmt.Fprintf(w, "    static std::thread::id m_MainThreadId;\n")
+	fmt.Fprintf(w, "    static napi_threadsafe_function m_ThreadSafeFunction;\n")
+	fmt.Fprintf(w, "    static std::map<std::string, std::vector<s%sStoredFunction>> m_Slots;\n", NameSpace)
+	fmt.Fprintf(w, "    static std::mutex m_MainThreadMutex;\n")
+	fmt.Fprintf(w, "    static std::condition_variable m_MainThreadCondition;\n")
+	fmt.Fprintf(w, "    static int m_nSynchronousCalls;\n")
+	fmt.Fprintf(w, "    static std::string m_sMissedCall;\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    static void callJS (napi_env env, napi_value function, void * pContext, void * pData);\n")
+	fmt.Fprintf(w, "    static void Finalize (napi_env env, void * pData, void * pHint);\n")
+	fmt.Fprintf(w, "    static void runOnMainThread (napi_env env, const char * pFunctionType, int nSlot, const std::function<void (C%sCallbackScope *)> & callback);\n", NameSpace)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "public:\n")
+	fmt.Fprintf(w, "    static const int SLOTCOUNT = %d;\n", nodeCallbackSlots)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    C%sCallbackSlot (napi_env env, napi_value function, const char * pFunctionType);\n", NameSpace)
+	fmt.Fprintf(w, "    ~C%sCallbackSlot ();\n", NameSpace)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    int getSlot ();\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    static void Init (napi_env env);\n")
+	fmt.Fprintf(w, "    static void run (const char * pFunctionType, int nSlot, const std::function<void (C%sCallbackScope *)> & callback);\n", NameSpace)
+	fmt.Fprintf(w, "    static void enterSynchronousCall ();\n")
+	fmt.Fprintf(w, "    static void leaveSynchronousCall (napi_env env);\n")
+	fmt.Fprintf(w, "};\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "// Marks a synchronous method call, during which the main thread can not run the callbacks of other threads\n")
+	fmt.Fprintf(w, "class C%sSynchronousCall {\n", NameSpace)
+	fmt.Fprintf(w, "private:\n")
+	fmt.Fprintf(w, "    napi_env m_Env;\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "public:\n")
+	fmt.Fprintf(w, "    C%sSynchronousCall (napi_env env)\n", NameSpace)
+	fmt.Fprintf(w, "        : m_Env (env)\n")
+	fmt.Fprintf(w, "    {\n")
+	fmt.Fprintf(w, "        C%sCallbackSlot::enterSynchronousCall ();\n", NameSpace)
+	fmt.Fprintf(w, "    }\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    ~C%sSynchronousCall ()\n", NameSpace)
+	fmt.Fprintf(w, "    {\n")
+	fmt.Fprintf(w, "        C%sCallbackSlot::leaveSynchronousCall (m_Env);\n", NameSpace)
+	fmt.Fprintf(w, "    }\n")
+	fmt.Fprintf(w, "};\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "\n")
+}
+
+// buildNodeCallbackSlotImplementation implements the class, which keeps the JS functions passed as callbacks, as long as the library may call them
+func buildNodeCallbackSlotImplementation(implw io.Writer, NameSpace string) {
+	fmt.Fprintf(implw, "/*************************************************************************************************************************\n")
+	fmt.Fprintf(implw, " Class C%sCallbackSlot Implementation\n", NameSpace)
+	fmt.Fprintf(implw, "**************************************************************************************************************************/\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "const int C%sCallbackSlot::SLOTCOUNT;\n", NameSpace)
+	fmt.Fprintf(implw, "napi_env C%sCallbackSlot::m_Env = nullptr;\n", NameSpace)
+	fmt.Fprintf(implw, "std::thread::id C%sCallbackSlot::m_MainThreadId;\n", NameSpace)
+	fmt.Fprintf(implw, "napi_threadsafe_function C%sCallbackSlot::m_ThreadSafeFunction = nullptr;\n", NameSpace)
+	fmt.Fprintf(implw, "std::map<std::string, std::vector<s%sStoredFunction>> C%sCallbackSlot::m_Slots;\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "std::mutex C%sCallbackSlot::m_MainThreadMutex;\n", NameSpace)
+	fmt.Fprintf(implw, "std::condition_variable C%sCallbackSlot::m_MainThreadCondition;\n", NameSpace)
+	fmt.Fprintf(implw, "int C%sCallbackSlot::m_nSynchronousCalls = 0;\n", NameSpace)
+	fmt.Fprintf(implw, "std::string C%sCallbackSlot::m_sMissedCall;\n", NameSpace)
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "// A call of a kept JS function from another thread, which waits until the call on the main thread is done\n")
+	fmt.Fprintf(implw, "struct s%sStoredFunctionCall {\n", NameSpace)
+	fmt.Fprintf(implw, "    const char * m_pFunctionType;\n")
+	fmt.Fprintf(implw, "    int m_nSlot;\n")
+	fmt.Fprintf(implw, "    const std::function<void (C%sCallbackScope *)> * m_pCallback;\n", NameSpace)
+	fmt.Fprintf(implw, "    bool m_bIsDone;\n")
+	fmt.Fprintf(implw, "    bool m_bIsMissed;\n")
+	fmt.Fprintf(implw, "};\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "C%sCallbackSlot::C%sCallbackSlot (napi_env env, napi_value function, const char * pFunctionType)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "    : m_pFunctionType (pFunctionType), m_nSlot (-1)\n")
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    Init (env);\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "    // Calls that pass the same JS function share its slot. If all slots are in use, the JS function is only called\n")
+	fmt.Fprintf(implw, "    // during the method call, to which it is passed.\n")
+	fmt.Fprintf(implw, "    std::vector<s%sStoredFunction> & slots = m_Slots[pFunctionType];\n", NameSpace)
+	fmt.Fprintf(implw, "    if (slots.empty ())\n")
+	fmt.Fprintf(implw, "        slots.resize (SLOTCOUNT);\n")
+	fmt.Fprintf(implw, "    int nFreeSlot = -1;\n")
+	fmt.Fprintf(implw, "    for (int nSlot = 0; nSlot < SLOTCOUNT; nSlot++) {\n")
+	fmt.Fprintf(implw, "        napi_value storedFunction = nullptr;\n")
+	fmt.Fprintf(implw, "        bool bIsEqual = false;\n")
+	fmt.Fprintf(implw, "        if (slots[nSlot].m_nReferences == 0) {\n")
+	fmt.Fprintf(implw, "            if (nFreeSlot < 0)\n")
+	fmt.Fprintf(implw, "                nFreeSlot = nSlot;\n")
+	fmt.Fprintf(implw, "        } else if ((napi_get_reference_value (env, slots[nSlot].m_FunctionReference, &storedFunction) == napi_ok) &&\n")
+	fmt.Fprintf(implw, "            (napi_strict_equals (env, storedFunction, function, &bIsEqual) == napi_ok) && bIsEqual) {\n")
+	fmt.Fprintf(implw, "            slots[nSlot].m_nReferences++;\n")
+	fmt.Fprintf(implw, "            m_nSlot = nSlot;\n")
+	fmt.Fprintf(implw, "            return;\n")
+	fmt.Fprintf(implw, "        }\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "    if (nFreeSlot < 0)\n")
+	fmt.Fprintf(implw, "        return;\n")
+	fmt.Fprintf(implw, "    C%sBaseClass::CheckStatus (env, napi_create_reference (env, function, 1, &slots[nFreeSlot].m_FunctionReference));\n", NameSpace)
+	fmt.Fprintf(implw, "    slots[nFreeSlot].m_nReferences = 1;\n")
+	fmt.Fprintf(implw, "    m_nSlot = nFreeSlot;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "C%sCallbackSlot::~C%sCallbackSlot ()\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    if (m_nSlot < 0)\n")
+	fmt.Fprintf(implw, "        return;\n")
+	fmt.Fprintf(implw, "    s%sStoredFunction & storedFunction = m_Slots[m_pFunctionType][m_nSlot];\n", NameSpace)
+	fmt.Fprintf(implw, "    storedFunction.m_nReferences--;\n")
+	fmt.Fprintf(implw, "    if (storedFunction.m_nReferences == 0) {\n")
+	fmt.Fprintf(implw, "        napi_delete_reference (m_Env, storedFunction.m_FunctionReference);\n")
+	fmt.Fprintf(implw, "        storedFunction.m_FunctionReference = nullptr;\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "int C%sCallbackSlot::getSlot ()\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    return m_nSlot;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sCallbackSlot::Init (napi_env env)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // Calls from other threads are run on the main thread by a threadsafe function, which does not keep the process alive\n")
+	fmt.Fprintf(implw, "    if (m_ThreadSafeFunction != nullptr)\n")
+	fmt.Fprintf(implw, "        return;\n")
+	fmt.Fprintf(implw, "    napi_value resourceName = nullptr;\n")
+	fmt.Fprintf(implw, "    napi_threadsafe_function threadSafeFunction = nullptr;\n")
+	fmt.Fprintf(implw, "    C%sBaseClass::CheckStatus (env, napi_create_string_utf8 (env, \"%sCallback\", NAPI_AUTO_LENGTH, &resourceName));\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "    C%sBaseClass::CheckStatus (env, napi_create_threadsafe_function (env, nullptr, nullptr, resourceName, 0, 1, nullptr, Finalize, nullptr, callJS, &threadSafeFunction));\n", NameSpace)
+	fmt.Fprintf(implw, "    napi_unref_threadsafe_function (env, threadSafeFunction);\n")
+	fmt.Fprintf(implw, "    std::lock_guard<std::mutex> lock (m_MainThreadMutex);\n")
+	fmt.Fprintf(implw, "    m_Env = env;\n")
+	fmt.Fprintf(implw, "    m_MainThreadId = std::this_thread::get_id ();\n")
+	fmt.Fprintf(implw, "    m_ThreadSafeFunction = threadSafeFunction;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sCallbackSlot::Finalize (napi_env env, void * pData, void * pHint)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // The threadsafe function is released, when the environment is torn down\n")
+	fmt.Fprintf(implw, "    std::lock_guard<std::mutex> lock (m_MainThreadMutex);\n")
+	fmt.Fprintf(implw, "    m_ThreadSafeFunction = nullptr;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sCallbackSlot::run (const char * pFunctionType, int nSlot, const std::function<void (C%sCallbackScope *)> & callback)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    if (std::this_thread::get_id () == m_MainThreadId) {\n")
+	fmt.Fprintf(implw, "        runOnMainThread (m_Env, pFunctionType, nSlot, callback);\n")
+	fmt.Fprintf(implw, "        return;\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "    // Other threads wait, until the call has run on the main thread. The main thread can not run it during a synchronous\n")
+	fmt.Fprintf(implw, "    // method call, which may wait for the calling thread. Then the call is missed, and the method throws an error.\n")
+	fmt.Fprintf(implw, "    std::shared_ptr<s%sStoredFunctionCall> pCall (new s%sStoredFunctionCall { pFunctionType, nSlot, &callback, false, false });\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "    std::unique_lock<std::mutex> lock (m_MainThreadMutex);\n")
+	fmt.Fprintf(implw, "    if ((m_ThreadSafeFunction != nullptr) && (m_nSynchronousCalls == 0)) {\n")
+	fmt.Fprintf(implw, "        std::shared_ptr<s%sStoredFunctionCall> * pData = new std::shared_ptr<s%sStoredFunctionCall> (pCall);\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "        if (napi_call_threadsafe_function (m_ThreadSafeFunction, pData, napi_tsfn_nonblocking) == napi_ok)\n")
+	fmt.Fprintf(implw, "            m_MainThreadCondition.wait (lock, [&pCall] { return pCall->m_bIsDone || pCall->m_bIsMissed || (m_nSynchronousCalls > 0); });\n")
+	fmt.Fprintf(implw, "        else\n")
+	fmt.Fprintf(implw, "            delete pData;\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "    if (!pCall->m_bIsDone) {\n")
+	fmt.Fprintf(implw, "        pCall->m_bIsMissed = true;\n")
+	fmt.Fprintf(implw, "        if (m_nSynchronousCalls > 0)\n")
+	fmt.Fprintf(implw, "            m_sMissedCall = std::string (\"Callback \") + pFunctionType + \" was called by another thread during a synchronous method call\";\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sCallbackSlot::runOnMainThread (napi_env env, const char * pFunctionType, int nSlot, const std::function<void (C%sCallbackScope *)> & callback)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // The kept JS function is called in a scope of its own, as if it had been passed to the running method\n")
+	fmt.Fprintf(implw, "    napi_handle_scope handleScope = nullptr;\n")
+	fmt.Fprintf(implw, "    if ((env == nullptr) || (napi_open_handle_scope (env, &handleScope) != napi_ok))\n")
+	fmt.Fprintf(implw, "        return;\n")
+	fmt.Fprintf(implw, "    try {\n")
+	fmt.Fprintf(implw, "        if (nSlot < 0)\n")
+	fmt.Fprintf(implw, "            throw std::runtime_error (std::string (\"Callback \") + pFunctionType +\n")
+	fmt.Fprintf(implw, "                \" was called after the method, to which it has been passed, has returned, but no slot keeps its JS function\");\n")
+	fmt.Fprintf(implw, "        std::vector<s%sStoredFunction> & slots = m_Slots[pFunctionType];\n", NameSpace)
+	fmt.Fprintf(implw, "        if ((nSlot >= (int) slots.size ()) || (slots[nSlot].m_nReferences == 0))\n")
+	fmt.Fprintf(implw, "            throw std::runtime_error (std::string (\"Callback \") + pFunctionType + \" was called after its JS function has been released\");\n")
+	fmt.Fprintf(implw, "        napi_value function = nullptr;\n")
+	fmt.Fprintf(implw, "        C%sBaseClass::CheckStatus (env, napi_get_reference_value (env, slots[nSlot].m_FunctionReference, &function));\n", NameSpace)
+	fmt.Fprintf(implw, "        C%sCallbackScope callbackScope (env, function, pFunctionType, nSlot);\n", NameSpace)
+	fmt.Fprintf(implw, "        callback (&callbackScope);\n")
+	fmt.Fprintf(implw, "    } catch (std::exception & E) {\n")
+	fmt.Fprintf(implw, "        C%sBaseClass::RaiseError (env, E.what());\n", NameSpace)
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "    napi_close_handle_scope (env, handleScope);\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sCallbackSlot::callJS (napi_env env, napi_value function, void * pContext, void * pData)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // Runs on the main thread, and env is null, if the environment is torn down. An exception of the JS function is\n")
+	fmt.Fprintf(implw, "    // thrown as uncaught exception, as there is no method call, to which it could be thrown.\n")
+	fmt.Fprintf(implw, "    std::unique_ptr<std::shared_ptr<s%sStoredFunctionCall>> pOwnedCall ((std::shared_ptr<s%sStoredFunctionCall> *) pData);\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "    s%sStoredFunctionCall & call = **pOwnedCall;\n", NameSpace)
+	fmt.Fprintf(implw, "    bool bIsWaiting = false;\n")
+	fmt.Fprintf(implw, "    {\n")
+	fmt.Fprintf(implw, "        std::lock_guard<std::mutex> lock (m_MainThreadMutex);\n")
+	fmt.Fprintf(implw, "        bIsWaiting = !call.m_bIsMissed;\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "    if (bIsWaiting && (env != nullptr))\n")
+	fmt.Fprintf(implw, "        runOnMainThread (env, call.m_pFunctionType, call.m_nSlot, *call.m_pCallback);\n")
+	fmt.Fprintf(implw, "    {\n")
+	fmt.Fprintf(implw, "        std::lock_guard<std::mutex> lock (m_MainThreadMutex);\n")
+	fmt.Fprintf(implw, "        if (env != nullptr)\n")
+	fmt.Fprintf(implw, "            call.m_bIsDone = true;\n")
+	fmt.Fprintf(implw, "        else\n")
+	fmt.Fprintf(implw, "            call.m_bIsMissed = true;\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "    m_MainThreadCondition.notify_all ();\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sCallbackSlot::enterSynchronousCall ()\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // Threads, that wait for the main thread, stop waiting\n")
+	fmt.Fprintf(implw, "    {\n")
+	fmt.Fprintf(implw, "        std::lock_guard<std::mutex> lock (m_MainThreadMutex);\n")
+	fmt.Fprintf(implw, "        m_nSynchronousCalls++;\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "    m_MainThreadCondition.notify_all ();\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sCallbackSlot::leaveSynchronousCall (napi_env env)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    std::string sMissedCall;\n")
+	fmt.Fprintf(implw, "    {\n")
+	fmt.Fprintf(implw, "        std::lock_guard<std::mutex> lock (m_MainThreadMutex);\n")
+	fmt.Fprintf(implw, "        m_nSynchronousCalls--;\n")
+	fmt.Fprintf(implw, "        if (m_nSynchronousCalls == 0)\n")
+	fmt.Fprintf(implw, "            sMissedCall.swap (m_sMissedCall);\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "    if (!sMissedCall.empty ())\n")
+	fmt.Fprintf(implw, "        C%sBaseClass::RaiseError (env, sMissedCall);\n", NameSpace)
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "\n")
+}
+
+// buildNodeCallbackScope declares the class, which keeps the JS functions passed to the running methods
+func buildNodeCallbackScope(w io.Writer, NameSpace string, hasAsyncMethods bool) {
+
+	fmt.Fprintf(w, "/*************************************************************************************************************************\n")
+	fmt.Fprintf(w, " Class C%sCallbackScope \n", NameSpace)
+	fmt.Fprintf(w, "**************************************************************************************************************************/\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "// Keeps a JS function, that is passed as callback, for the duration of a method call\n")
+	fmt.Fprintf(w, "class C%sCallbackScope {\n", NameSpace)
+	fmt.Fprintf(w, "private:\n")
+	fmt.Fprintf(w, "    napi_env m_Env;\n")
+	fmt.Fprintf(w, "    napi_value m_Function;\n")
+	fmt.Fprintf(w, "    const char * m_pFunctionType;\n")
+	fmt.Fprintf(w, "    C%sCallbackScope * m_pOuterScope;\n", NameSpace)
+	if hasAsyncMethods {
+		fmt.Fprintf(w, "    bool m_bIsAsynchronous;\n")
+		fmt.Fprintf(w, "    napi_threadsafe_function m_ThreadSafeFunction;\n")
+		fmt.Fprintf(w, "    napi_ref m_FunctionReference;\n")
+		fmt.Fprintf(w, "    napi_ref m_SignalReference;\n")
+		fmt.Fprintf(w, "    napi_ref m_ErrorReference;\n")
+		fmt.Fprintf(w, "    std::atomic<bool> m_bIsAborted;\n")
+	}
+	fmt.Fprintf(w, "    int m_nSlot;\n")
+	fmt.Fprintf(w, "    std::unique_ptr<C%sCallbackSlot> m_pSlot;\n", NameSpace)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    static thread_local C%sCallbackScope * m_pInnermostScope;\n", NameSpace)
+	if hasAsyncMethods {
+		fmt.Fprintf(w, "    static std::mutex m_MainThreadMutex;\n")
+		fmt.Fprintf(w, "    static std::condition_variable m_MainThreadCondition;\n")
+		fmt.Fprintf(w, "    static std::atomic<bool> m_bIsExiting;\n")
+		fmt.Fprintf(w, "\n")
+		fmt.Fprintf(w, "    static void callJS (napi_env env, napi_value function, void * pContext, void * pData);\n")
+		fmt.Fprintf(w, "    static napi_value OnExit (napi_env env, napi_callback_info info);\n")
+		fmt.Fprintf(w, "    void keepError (napi_env env, napi_value error);\n")
+		fmt.Fprintf(w, "    void checkSignal (napi_env env);\n")
+	}
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    C%sCallbackScope (napi_env env, napi_value function, const char * pFunctionType, int nSlot);\n", NameSpace)
+	fmt.Fprintf(w, "    friend class C%sCallbackSlot;\n", NameSpace)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "public:\n")
+	fmt.Fprintf(w, "    C%sCallbackScope (napi_env env, napi_value function, const char * pFunctionType, const char * pErrorMessage);\n", NameSpace)
+	if hasAsyncMethods {
+		fmt.Fprintf(w, "    C%sCallbackScope (napi_env env, napi_value function, napi_value signal, const char * pFunctionType, const char * pErrorMessage);\n", NameSpace)
+	}
+	fmt.Fprintf(w, "    ~C%sCallbackScope ();\n", NameSpace)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    bool isEmpty ();\n")
+	fmt.Fprintf(w, "    int getSlot ();\n")
+	fmt.Fprintf(w, "    std::unique_ptr<C%sCallbackSlot> releaseSlot ();\n", NameSpace)
+	fmt.Fprintf(w, "    napi_env getEnv ();\n")
+	fmt.Fprintf(w, "    napi_value call (size_t argc, napi_value * argv);\n")
+	fmt.Fprintf(w, "    void run (const std::function<void (napi_env)> & callback);\n")
+	if hasAsyncMethods {
+		fmt.Fprintf(w, "\n")
+		fmt.Fprintf(w, "    void enter ();\n")
+		fmt.Fprintf(w, "    void leave ();\n")
+		fmt.Fprintf(w, "    bool isAborted ();\n")
+		fmt.Fprintf(w, "    napi_value getError (napi_env env);\n")
+	}
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    static C%sCallbackScope * find (const char * pFunctionType);\n", NameSpace)
+	if hasAsyncMethods {
+		fmt.Fprintf(w, "    static void InitExit (napi_env env);\n")
+	}
+	fmt.Fprintf(w, "};\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "\n")
+}
+
+// buildNodeCallbackScopeImplementation implements the class, which keeps the JS functions passed to the running methods
+func buildNodeCallbackScopeImplementation(implw io.Writer, NameSpace string, hasAsyncMethods bool) {
+	fmt.Fprintf(implw, "/*************************************************************************************************************************\n")
+	fmt.Fprintf(implw, " Class C%sCallbackScope Implementation\n", NameSpace)
+	fmt.Fprintf(implw, "**************************************************************************************************************************/\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "thread_local C%sCallbackScope * C%sCallbackScope::m_pInnermostScope = nullptr;\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "\n")
+	if hasAsyncMethods {
+		fmt.Fprintf(implw, "std::mutex C%sCallbackScope::m_MainThreadMutex;\n", NameSpace)
+		fmt.Fprintf(implw, "std::condition_variable C%sCallbackScope::m_MainThreadCondition;\n", NameSpace)
+		fmt.Fprintf(implw, "std::atomic<bool> C%sCallbackScope::m_bIsExiting (false);\n", NameSpace)
+		fmt.Fprintf(implw, "\n")
+		fmt.Fprintf(implw, "// A call of a JS function from a worker thread, which waits until the call on the main thread is done\n")
+		fmt.Fprintf(implw, "struct s%sMainThreadCall {\n", NameSpace)
+		fmt.Fprintf(implw, "    const std::function<void (napi_env)> * m_pCallback;\n")
+		fmt.Fprintf(implw, "    bool m_bIsDone;\n")
+		fmt.Fprintf(implw, "};\n")
+		fmt.Fprintf(implw, "\n")
+	}
+	for _, isKept := range []bool{false, true} {
+		if isKept {
+			fmt.Fprintf(implw, "C%sCallbackScope::C%sCallbackScope (napi_env env, napi_value function, const char * pFunctionType, int nSlot)\n", NameSpace, NameSpace)
+		} else {
+			fmt.Fprintf(implw, "C%sCallbackScope::C%sCallbackScope (napi_env env, napi_value function, const char * pFunctionType, const char * pErrorMessage)\n", NameSpace, NameSpace)
+		}
+		function := "nullptr"
+		slot := "-1"
+		if isKept {
+			function = "function"
+			slot = "nSlot"
+		}
+		if hasAsyncMethods {
+			fmt.Fprintf(implw, "    : m_Env (env), m_Function (%s), m_pFunctionType (pFunctionType), m_pOuterScope (m_pInnermostScope), m_bIsAsynchronous (false),\n", function)
+			fmt.Fprintf(implw, "      m_ThreadSafeFunction (nullptr), m_FunctionReference (nullptr), m_SignalReference (nullptr), m_ErrorReference (nullptr), m_bIsAborted (false),\n")
+			fmt.Fprintf(implw, "      m_nSlot (%s)\n", slot)
+		} else {
+			fmt.Fprintf(implw, "    : m_Env (env), m_Function (%s), m_pFunctionType (pFunctionType), m_pOuterScope (m_pInnermostScope), m_nSlot (%s)\n", function, slot)
+		}
+		fmt.Fprintf(implw, "{\n")
+		if isKept {
+			fmt.Fprintf(implw, "    // The scope of a JS function kept in a slot, which the library calls after the method has returned\n")
+		} else {
+			fmt.Fprintf(implw, "    C%sCallbackSlot::Init (env);\n", NameSpace)
+			fmt.Fprintf(implw, "\n")
+			fmt.Fprintf(implw, "    // null and undefined are passed as null callback. A JS function occupies a slot, as the library may keep it.\n")
+			fmt.Fprintf(implw, "    napi_valuetype valueType = napi_undefined;\n")
+			fmt.Fprintf(implw, "    C%sBaseClass::CheckStatus (env, napi_typeof (env, function, &valueType));\n", NameSpace)
+			fmt.Fprintf(implw, "    if (valueType == napi_function) {\n")
+			fmt.Fprintf(implw, "        m_Function = function;\n")
+			fmt.Fprintf(implw, "        m_pSlot.reset (new C%sCallbackSlot (env, function, pFunctionType));\n", NameSpace)
+			fmt.Fprintf(implw, "        m_nSlot = m_pSlot->getSlot ();\n")
+			fmt.Fprintf(implw, "    } else if ((valueType != napi_null) && (valueType != napi_undefined)) {\n")
+			fmt.Fprintf(implw, "        throw std::runtime_error (pErrorMessage);\n")
+			fmt.Fprintf(implw, "    }\n")
+		}
+		fmt.Fprintf(implw, "    m_pInnermostScope = this;\n")
+		fmt.Fprintf(implw, "}\n")
+		fmt.Fprintf(implw, "\n")
+	}
+	if hasAsyncMethods {
+		fmt.Fprintf(implw, "C%sCallbackScope::C%sCallbackScope (napi_env env, napi_value function, napi_value signal, const char * pFunctionType, const char * pErrorMessage)\n", NameSpace, NameSpace)
+		fmt.Fprintf(implw, "    : m_Env (env), m_Function (nullptr), m_pFunctionType (pFunctionType), m_pOuterScope (nullptr), m_bIsAsynchronous (true),\n")
+		fmt.Fprintf(implw, "      m_ThreadSafeFunction (nullptr), m_FunctionReference (nullptr), m_SignalReference (nullptr), m_ErrorReference (nullptr), m_bIsAborted (false),\n")
+		fmt.Fprintf(implw, "      m_nSlot (-1)\n")
+		fmt.Fprintf(implw, "{\n")
+		fmt.Fprintf(implw, "    // The scope of an asynchronous call keeps references, and calls the JS function on the main thread\n")
+		fmt.Fprintf(implw, "    C%sCallbackSlot::Init (env);\n", NameSpace)
+		fmt.Fprintf(implw, "    napi_valuetype valueType = napi_undefined;\n")
+		fmt.Fprintf(implw, "    C%sBaseClass::CheckStatus (env, napi_typeof (env, function, &valueType));\n", NameSpace)
+		fmt.Fprintf(implw, "    if ((valueType != napi_function) && (valueType != napi_null) && (valueType != napi_undefined))\n")
+		fmt.Fprintf(implw, "        throw std::runtime_error (pErrorMessage);\n")
+		fmt.Fprintf(implw, "    napi_valuetype signalType = napi_undefined;\n")
+		fmt.Fprintf(implw, "    if (signal != nullptr)\n")
+		fmt.Fprintf(implw, "        C%sBaseClass::CheckStatus (env, napi_typeof (env, signal, &signalType));\n", NameSpace)
+		fmt.Fprintf(implw, "    if ((signalType != napi_object) && (signalType != napi_null) && (signalType != napi_undefined))\n")
+		fmt.Fprintf(implw, "        throw std::runtime_error (\"Expected AbortSignal as last parameter\");\n")
+		fmt.Fprintf(implw, "\n")
+		fmt.Fprintf(implw, "    if (valueType == napi_function) {\n")
+		fmt.Fprintf(implw, "        C%sBaseClass::CheckStatus (env, napi_create_reference (env, function, 1, &m_FunctionReference));\n", NameSpace)
+		fmt.Fprintf(implw, "        m_pSlot.reset (new C%sCallbackSlot (env, function, pFunctionType));\n", NameSpace)
+		fmt.Fprintf(implw, "        m_nSlot = m_pSlot->getSlot ();\n")
+		fmt.Fprintf(implw, "    }\n")
+		fmt.Fprintf(implw, "    if (signalType == napi_object)\n")
+		fmt.Fprintf(implw, "        C%sBaseClass::CheckStatus (env, napi_create_reference (env, signal, 1, &m_SignalReference));\n", NameSpace)
+		fmt.Fprintf(implw, "    if (!isEmpty ()) {\n")
+		fmt.Fprintf(implw, "        napi_value resourceName = nullptr;\n")
+		fmt.Fprintf(implw, "        C%sBaseClass::CheckStatus (env, napi_create_string_utf8 (env, pFunctionType, NAPI_AUTO_LENGTH, &resourceName));\n", NameSpace)
+		fmt.Fprintf(implw, "        C%sBaseClass::CheckStatus (env, napi_create_threadsafe_function (env, nullptr, nullptr, resourceName, 0, 1, nullptr, nullptr, this, callJS, &m_ThreadSafeFunction));\n", NameSpace)
+		fmt.Fprintf(implw, "    }\n")
+		fmt.Fprintf(implw, "}\n")
+		fmt.Fprintf(implw, "\n")
+	}
+	fmt.Fprintf(implw, "C%sCallbackScope::~C%sCallbackScope ()\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	if hasAsyncMethods {
+		fmt.Fprintf(implw, "    if (m_bIsAsynchronous) {\n")
+		fmt.Fprintf(implw, "        if (m_ThreadSafeFunction != nullptr)\n")
+		fmt.Fprintf(implw, "            napi_release_threadsafe_function (m_ThreadSafeFunction, napi_tsfn_release);\n")
+		fmt.Fprintf(implw, "        if (m_FunctionReference != nullptr)\n")
+		fmt.Fprintf(implw, "            napi_delete_reference (m_Env, m_FunctionReference);\n")
+		fmt.Fprintf(implw, "        if (m_SignalReference != nullptr)\n")
+		fmt.Fprintf(implw, "            napi_delete_reference (m_Env, m_SignalReference);\n")
+		fmt.Fprintf(implw, "        if (m_ErrorReference != nullptr)\n")
+		fmt.Fprintf(implw, "            napi_delete_reference (m_Env, m_ErrorReference);\n")
+		fmt.Fprintf(implw, "    } else {\n")
+		fmt.Fprintf(implw, "        m_pInnermostScope = m_pOuterScope;\n")
+		fmt.Fprintf(implw, "    }\n")
+	} else {
+		fmt.Fprintf(implw, "    m_pInnermostScope = m_pOuterScope;\n")
+	}
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "bool C%sCallbackScope::isEmpty ()\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	if hasAsyncMethods {
+		fmt.Fprintf(implw, "    return (m_Function == nullptr) && (m_FunctionReference == nullptr) && (m_SignalReference == nullptr);\n")
+	} else {
+		fmt.Fprintf(implw, "    return m_Function == nullptr;\n")
+	}
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "int C%sCallbackScope::getSlot ()\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    return m_nSlot;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "std::unique_ptr<C%sCallbackSlot> C%sCallbackScope::releaseSlot ()\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // The instance keeps the slot once the call has succeeded, otherwise the scope releases it\n")
+	fmt.Fprintf(implw, "    return std::move (m_pSlot);\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "napi_env C%sCallbackScope::getEnv ()\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    return m_Env;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "napi_value C%sCallbackScope::call (size_t argc, napi_value * argv)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // An exception of an earlier call stays pending until the method returns\n")
+	fmt.Fprintf(implw, "    bool bIsExceptionPending = false;\n")
+	fmt.Fprintf(implw, "    C%sBaseClass::CheckStatus (m_Env, napi_is_exception_pending (m_Env, &bIsExceptionPending));\n", NameSpace)
+	if hasAsyncMethods {
+		fmt.Fprintf(implw, "    if (bIsExceptionPending || (m_ErrorReference != nullptr))\n")
+	} else {
+		fmt.Fprintf(implw, "    if (bIsExceptionPending)\n")
+	}
+	fmt.Fprintf(implw, "        throw std::runtime_error (std::string (\"Callback \") + m_pFunctionType + \" was not called due to a pending exception\");\n")
+	fmt.Fprintf(implw, "    napi_value receiver = nullptr;\n")
+	fmt.Fprintf(implw, "    C%sBaseClass::CheckStatus (m_Env, napi_get_undefined (m_Env, &receiver));\n", NameSpace)
+	function := "m_Function"
+	if hasAsyncMethods {
+		function = "function"
+		fmt.Fprintf(implw, "    napi_value function = m_Function;\n")
+		fmt.Fprintf(implw, "    if (m_FunctionReference != nullptr)\n")
+		fmt.Fprintf(implw, "        C%sBaseClass::CheckStatus (m_Env, napi_get_reference_value (m_Env, m_FunctionReference, &function));\n", NameSpace)
+		fmt.Fprintf(implw, "    // An asynchronous call, to which only an AbortSignal has been passed, has no JS function\n")
+		fmt.Fprintf(implw, "    if (function == nullptr)\n")
+		fmt.Fprintf(implw, "        return receiver;\n")
+	}
+	fmt.Fprintf(implw, "    napi_value result = nullptr;\n")
+	fmt.Fprintf(implw, "    C%sBaseClass::CheckStatus (m_Env, napi_call_function (m_Env, receiver, %s, argc, argv, &result));\n", NameSpace, function)
+	fmt.Fprintf(implw, "    return result;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sCallbackScope::run (const std::function<void (napi_env)> & callback)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	if hasAsyncMethods {
+		fmt.Fprintf(implw, "    // The worker thread of an asynchronous call waits, until the callback has run on the main thread\n")
+		fmt.Fprintf(implw, "    if (m_bIsAsynchronous) {\n")
+		fmt.Fprintf(implw, "        s%sMainThreadCall mainThreadCall;\n", NameSpace)
+		fmt.Fprintf(implw, "        mainThreadCall.m_pCallback = &callback;\n")
+		fmt.Fprintf(implw, "        mainThreadCall.m_bIsDone = false;\n")
+		fmt.Fprintf(implw, "        if (m_bIsExiting || (napi_call_threadsafe_function (m_ThreadSafeFunction, &mainThreadCall, napi_tsfn_blocking) != napi_ok)) {\n")
+		fmt.Fprintf(implw, "            m_bIsAborted = true;\n")
+		fmt.Fprintf(implw, "            return;\n")
+		fmt.Fprintf(implw, "        }\n")
+		fmt.Fprintf(implw, "        std::unique_lock<std::mutex> lock (m_MainThreadMutex);\n")
+		fmt.Fprintf(implw, "        m_MainThreadCondition.wait (lock, [&mainThreadCall] { return mainThreadCall.m_bIsDone || m_bIsExiting; });\n")
+		fmt.Fprintf(implw, "        if (!mainThreadCall.m_bIsDone)\n")
+		fmt.Fprintf(implw, "            m_bIsAborted = true;\n")
+		fmt.Fprintf(implw, "        return;\n")
+		fmt.Fprintf(implw, "    }\n")
+		fmt.Fprintf(implw, "\n")
+	}
+	fmt.Fprintf(implw, "    napi_handle_scope handleScope = nullptr;\n")
+	fmt.Fprintf(implw, "    if (napi_open_handle_scope (m_Env, &handleScope) != napi_ok)\n")
+	fmt.Fprintf(implw, "        return;\n")
+	fmt.Fprintf(implw, "    try {\n")
+	fmt.Fprintf(implw, "        callback (m_Env);\n")
+	fmt.Fprintf(implw, "    } catch (std::exception & E) {\n")
+	fmt.Fprintf(implw, "        C%sBaseClass::RaiseError (m_Env, E.what());\n", NameSpace)
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "    napi_close_handle_scope (m_Env, handleScope);\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	if hasAsyncMethods {
+		fmt.Fprintf(implw, "void C%sCallbackScope::callJS (napi_env env, napi_value function, void * pContext, void * pData)\n", NameSpace)
+		fmt.Fprintf(implw, "{\n")
+		fmt.Fprintf(implw, "    C%sCallbackScope * pScope = (C%sCallbackScope *) pContext;\n", NameSpace, NameSpace)
+		fmt.Fprintf(implw, "    s%sMainThreadCall * pMainThreadCall = (s%sMainThreadCall *) pData;\n", NameSpace, NameSpace)
+		fmt.Fprintf(implw, "\n")
+		fmt.Fprintf(implw, "    // env is null, if the environment is torn down, in which case the JS function is not called\n")
+		fmt.Fprintf(implw, "    napi_handle_scope handleScope = nullptr;\n")
+		fmt.Fprintf(implw, "    if ((env != nullptr) && (napi_open_handle_scope (env, &handleScope) == napi_ok)) {\n")
+		fmt.Fprintf(implw, "        try {\n")
+		fmt.Fprintf(implw, "            (*pMainThreadCall->m_pCallback) (env);\n")
+		fmt.Fprintf(implw, "        } catch (std::exception & E) {\n")
+		fmt.Fprintf(implw, "            C%sBaseClass::RaiseError (env, E.what());\n", NameSpace)
+		fmt.Fprintf(implw, "        }\n")
+		fmt.Fprintf(implw, "        pScope->checkSignal (env);\n")
+		fmt.Fprintf(implw, "\n")
+		fmt.Fprintf(implw, "        // An exception can not be thrown to the caller, but rejects the promise\n")
+		fmt.Fprintf(implw, "        bool bIsExceptionPending = false;\n")
+		fmt.Fprintf(implw, "        napi_value error = nullptr;\n")
+		fmt.Fprintf(implw, "        if ((napi_is_exception_pending (env, &bIsExceptionPending) == napi_ok) && bIsExceptionPending &&\n")
+		fmt.Fprintf(implw, "            (napi_get_and_clear_last_exception (env, &error) == napi_ok))\n")
+		fmt.Fprintf(implw, "            pScope->keepError (env, error);\n")
+		fmt.Fprintf(implw, "        napi_close_handle_scope (env, handleScope);\n")
+		fmt.Fprintf(implw, "    }\n")
+		fmt.Fprintf(implw, "\n")
+		fmt.Fprintf(implw, "    {\n")
+		fmt.Fprintf(implw, "        std::lock_guard<std::mutex> lock (m_MainThreadMutex);\n")
+		fmt.Fprintf(implw, "        pMainThreadCall->m_bIsDone = true;\n")
+		fmt.Fprintf(implw, "    }\n")
+		fmt.Fprintf(implw, "    m_MainThreadCondition.notify_all ();\n")
+		fmt.Fprintf(implw, "}\n")
+		fmt.Fprintf(implw, "\n")
+		fmt.Fprintf(implw, "napi_value C%sCallbackScope::OnExit (napi_env env, napi_callback_info info)\n", NameSpace)
+		fmt.Fprintf(implw, "{\n")
+		fmt.Fprintf(implw, "    // process.exit stops the main thread, before it waits for the worker threads. Hence the callbacks of the running\n")
+		fmt.Fprintf(implw, "    // asynchronous calls stop waiting for it, and abort the calls, if they can be cancelled.\n")
+		fmt.Fprintf(implw, "    {\n")
+		fmt.Fprintf(implw, "        std::lock_guard<std::mutex> lock (m_MainThreadMutex);\n")
+		fmt.Fprintf(implw, "        m_bIsExiting = true;\n")
+		fmt.Fprintf(implw, "    }\n")
+		fmt.Fprintf(implw, "    m_MainThreadCondition.notify_all ();\n")
+		fmt.Fprintf(implw, "    return nullptr;\n")
+		fmt.Fprintf(implw, "}\n")
+		fmt.Fprintf(implw, "\n")
+		fmt.Fprintf(implw, "void C%sCallbackScope::InitExit (napi_env env)\n", NameSpace)
+		fmt.Fprintf(implw, "{\n")
+		fmt.Fprintf(implw, "    // process.on (\"exit\", OnExit)\n")
+		fmt.Fprintf(implw, "    napi_value global = nullptr;\n")
+		fmt.Fprintf(implw, "    napi_value process = nullptr;\n")
+		fmt.Fprintf(implw, "    napi_value onFunction = nullptr;\n")
+		fmt.Fprintf(implw, "    napi_value args[2] = { nullptr, nullptr };\n")
+		fmt.Fprintf(implw, "    napi_value result = nullptr;\n")
+		fmt.Fprintf(implw, "    C%sBaseClass::CheckStatus (env, napi_get_global (env, &global));\n", NameSpace)
+		fmt.Fprintf(implw, "    C%sBaseClass::CheckStatus (env, napi_get_named_property (env, global, \"process\", &process));\n", NameSpace)
+		fmt.Fprintf(implw, "    C%sBaseClass::CheckStatus (env, napi_get_named_property (env, process, \"on\", &onFunction));\n", NameSpace)
+		fmt.Fprintf(implw, "    C%sBaseClass::CheckStatus (env, napi_create_string_utf8 (env, \"exit\", NAPI_AUTO_LENGTH, &args[0]));\n", NameSpace)
+		fmt.Fprintf(implw, "    C%sBaseClass::CheckStatus (env, napi_create_function (env, \"OnExit\", NAPI_AUTO_LENGTH, OnExit, nullptr, &args[1]));\n", NameSpace)
+		fmt.Fprintf(implw, "    C%sBaseClass::CheckStatus (env, napi_call_function (env, process, onFunction, 2, args, &result));\n", NameSpace)
+		fmt.Fprintf(implw, "}\n")
+		fmt.Fprintf(implw, "\n")
+		fmt.Fprintf(implw, "void C%sCallbackScope::keepError (napi_env env, napi_value error)\n", NameSpace)
+		fmt.Fprintf(implw, "{\n")
+		fmt.Fprintf(implw, "    // The first exception of the JS function rejects the promise, and aborts the method, if it can be cancelled\n")
+		fmt.Fprintf(implw, "    if ((m_ErrorReference == nullptr) && (napi_create_reference (env, error, 1, &m_ErrorReference) != napi_ok)) {\n")
+		fmt.Fprintf(implw, "        napi_value callbackError = C%sBaseClass::createError (env, std::string (\"Callback \") + m_pFunctionType + \" threw an exception\");\n", NameSpace)
+		fmt.Fprintf(implw, "        if (callbackError != nullptr)\n")
+		fmt.Fprintf(implw, "            napi_create_reference (env, callbackError, 1, &m_ErrorReference);\n")
+		fmt.Fprintf(implw, "    }\n")
+		fmt.Fprintf(implw, "    m_bIsAborted = true;\n")
+		fmt.Fprintf(implw, "}\n")
+		fmt.Fprintf(implw, "\n")
+		fmt.Fprintf(implw, "void C%sCallbackScope::checkSignal (napi_env env)\n", NameSpace)
+		fmt.Fprintf(implw, "{\n")
+		fmt.Fprintf(implw, "    napi_value signal = nullptr;\n")
+		fmt.Fprintf(implw, "    napi_value aborted = nullptr;\n")
+		fmt.Fprintf(implw, "    bool bIsAborted = false;\n")
+		fmt.Fprintf(implw, "    if ((m_SignalReference != nullptr) && (napi_get_reference_value (env, m_SignalReference, &signal) == napi_ok) &&\n")
+		fmt.Fprintf(implw, "        (napi_get_named_property (env, signal, \"aborted\", &aborted) == napi_ok) &&\n")
+		fmt.Fprintf(implw, "        (napi_get_value_bool (env, aborted, &bIsAborted) == napi_ok) && bIsAborted)\n")
+		fmt.Fprintf(implw, "        m_bIsAborted = true;\n")
+		fmt.Fprintf(implw, "}\n")
+		fmt.Fprintf(implw, "\n")
+		fmt.Fprintf(implw, "void C%sCallbackScope::enter ()\n", NameSpace)
+		fmt.Fprintf(implw, "{\n")
+		fmt.Fprintf(implw, "    // The scope of an asynchronous call is entered on the worker thread, on which the callbacks are called\n")
+		fmt.Fprintf(implw, "    m_pOuterScope = m_pInnermostScope;\n")
+		fmt.Fprintf(implw, "    m_pInnermostScope = this;\n")
+		fmt.Fprintf(implw, "}\n")
+		fmt.Fprintf(implw, "\n")
+		fmt.Fprintf(implw, "void C%sCallbackScope::leave ()\n", NameSpace)
+		fmt.Fprintf(implw, "{\n")
+		fmt.Fprintf(implw, "    m_pInnermostScope = m_pOuterScope;\n")
+		fmt.Fprintf(implw, "}\n")
+		fmt.Fprintf(implw, "\n")
+		fmt.Fprintf(implw, "bool C%sCallbackScope::isAborted ()\n", NameSpace)
+		fmt.Fprintf(implw, "{\n")
+		fmt.Fprintf(implw, "    return m_bIsAborted;\n")
+		fmt.Fprintf(implw, "}\n")
+		fmt.Fprintf(implw, "\n")
+		fmt.Fprintf(implw, "napi_value C%sCallbackScope::getError (napi_env env)\n", NameSpace)
+		fmt.Fprintf(implw, "{\n")
+		fmt.Fprintf(implw, "    // An exception of the JS function takes precedence over the reason of the AbortSignal\n")
+		fmt.Fprintf(implw, "    napi_value error = nullptr;\n")
+		fmt.Fprintf(implw, "    if ((m_ErrorReference != nullptr) && (napi_get_reference_value (env, m_ErrorReference, &error) == napi_ok))\n")
+		fmt.Fprintf(implw, "        return error;\n")
+		fmt.Fprintf(implw, "    checkSignal (env);\n")
+		fmt.Fprintf(implw, "    napi_value signal = nullptr;\n")
+		fmt.Fprintf(implw, "    if (m_bIsAborted && (m_SignalReference != nullptr) && (napi_get_reference_value (env, m_SignalReference, &signal) == napi_ok) &&\n")
+		fmt.Fprintf(implw, "        (napi_get_named_property (env, signal, \"reason\", &error) == napi_ok))\n")
+		fmt.Fprintf(implw, "        return error;\n")
+		fmt.Fprintf(implw, "    return nullptr;\n")
+		fmt.Fprintf(implw, "}\n")
+		fmt.Fprintf(implw, "\n")
+	}
+	fmt.Fprintf(implw, "C%sCallbackScope * C%sCallbackScope::find (const char * pFunctionType)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    C%sCallbackScope * pScope = m_pInnermostScope;\n", NameSpace)
+	fmt.Fprintf(implw, "    while ((pScope != nullptr) && (strcmp (pScope->m_pFunctionType, pFunctionType) != 0))\n")
+	fmt.Fprintf(implw, "        pScope = pScope->m_pOuterScope;\n")
+	fmt.Fprintf(implw, "    return pScope;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "\n")
+}
+
+// buildNodeAsyncWorker declares the base class of the workers, which run the asynchronous methods
+func buildNodeAsyncWorker(w io.Writer, NameSpace string) {
+
+	fmt.Fprintf(w, "/*************************************************************************************************************************\n")
+	fmt.Fprintf(w, " Class C%sAsyncWorker \n", NameSpace)
+	fmt.Fprintf(w, "**************************************************************************************************************************/\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "// Calls a method of the library on a worker thread, and settles a promise with its results on the main thread\n")
+	fmt.Fprintf(w, "class C%sAsyncWorker {\n", NameSpace)
+	fmt.Fprintf(w, "private:\n")
+	fmt.Fprintf(w, "    napi_env m_Env;\n")
+	fmt.Fprintf(w, "    napi_deferred m_Deferred;\n")
+	fmt.Fprintf(w, "    napi_async_work m_Work;\n")
+	fmt.Fprintf(w, "    std::vector<napi_ref> m_References;\n")
+	fmt.Fprintf(w, "    std::vector<C%sCallbackScope *> m_CallbackScopes;\n", NameSpace)
+	fmt.Fprintf(w, "    bool m_bHasError;\n")
+	fmt.Fprintf(w, "    bool m_bHasErrorCode;\n")
+	fmt.Fprintf(w, "    %sResult m_nErrorCode;\n", NameSpace)
+	fmt.Fprintf(w, "    std::string m_sErrorMessage;\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    static void Execute (napi_env env, void * pData);\n")
+	fmt.Fprintf(w, "    static void Complete (napi_env env, napi_status status, void * pData);\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "protected:\n")
+	fmt.Fprintf(w, "    C%sBaseClass * m_pThis;\n", NameSpace)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    void addCallbackScope (C%sCallbackScope * pScope);\n", NameSpace)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    // Calls the library on the worker thread\n")
+	fmt.Fprintf(w, "    virtual void execute () = 0;\n")
+	fmt.Fprintf(w, "    // Returns the result on the main thread, or nullptr for undefined\n")
+	fmt.Fprintf(w, "    virtual napi_value complete (napi_env env) = 0;\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "public:\n")
+	fmt.Fprintf(w, "    C%sAsyncWorker (napi_env env, napi_value thisObject, size_t argc, napi_value * args);\n", NameSpace)
+	fmt.Fprintf(w, "    virtual ~C%sAsyncWorker ();\n", NameSpace)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "    static napi_value Queue (napi_env env, C%sAsyncWorker * pWorker, const char * pMethodName);\n", NameSpace)
+	fmt.Fprintf(w, "    static napi_value Reject (napi_env env, napi_value error);\n")
+	fmt.Fprintf(w, "};\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "\n")
+}
+
+// buildNodeAsyncWorkerImplementation implements the base class of the workers, which run the asynchronous methods
+func buildNodeAsyncWorkerImplementation(implw io.Writer, NameSpace string) {
+	fmt.Fprintf(implw, "/*************************************************************************************************************************\n")
+	fmt.Fprintf(implw, " Class C%sAsyncWorker Implementation\n", NameSpace)
+	fmt.Fprintf(implw, "**************************************************************************************************************************/\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "C%sAsyncWorker::C%sAsyncWorker (napi_env env, napi_value thisObject, size_t argc, napi_value * args)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "    : m_Env (env), m_Deferred (nullptr), m_Work (nullptr), m_bHasError (false), m_bHasErrorCode (false), m_nErrorCode (0), m_pThis (nullptr)\n")
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    m_pThis = C%sBaseClass::getInstance (env, thisObject);\n", NameSpace)
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "    // The object and the objects passed to the method are kept alive, until the promise is settled\n")
+	fmt.Fprintf(implw, "    std::vector<napi_value> values (args, args + argc);\n")
+	fmt.Fprintf(implw, "    values.push_back (thisObject);\n")
+	fmt.Fprintf(implw, "    for (napi_value value : values) {\n")
+	fmt.Fprintf(implw, "        napi_valuetype valueType = napi_undefined;\n")
+	fmt.Fprintf(implw, "        napi_ref reference = nullptr;\n")
+	fmt.Fprintf(implw, "        if ((napi_typeof (env, value, &valueType) == napi_ok) && ((valueType == napi_object) || (valueType == napi_function)) &&\n")
+	fmt.Fprintf(implw, "            (napi_create_reference (env, value, 1, &reference) == napi_ok))\n")
+	fmt.Fprintf(implw, "            m_References.push_back (reference);\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "C%sAsyncWorker::~C%sAsyncWorker ()\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    for (napi_ref reference : m_References)\n")
+	fmt.Fprintf(implw, "        napi_delete_reference (m_Env, reference);\n")
+	fmt.Fprintf(implw, "    if (m_Work != nullptr)\n")
+	fmt.Fprintf(implw, "        napi_delete_async_work (m_Env, m_Work);\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sAsyncWorker::addCallbackScope (C%sCallbackScope * pScope)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    m_CallbackScopes.push_back (pScope);\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "napi_value C%sAsyncWorker::Queue (napi_env env, C%sAsyncWorker * pWorker, const char * pMethodName)\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // The worker is deleted, once the promise is settled\n")
+	fmt.Fprintf(implw, "    std::unique_ptr<C%sAsyncWorker> pOwnedWorker (pWorker);\n", NameSpace)
+	fmt.Fprintf(implw, "    napi_value resourceName = nullptr;\n")
+	fmt.Fprintf(implw, "    napi_value promise = nullptr;\n")
+	fmt.Fprintf(implw, "    C%sBaseClass::CheckStatus (env, napi_create_string_utf8 (env, pMethodName, NAPI_AUTO_LENGTH, &resourceName));\n", NameSpace)
+	fmt.Fprintf(implw, "    C%sBaseClass::CheckStatus (env, napi_create_async_work (env, nullptr, resourceName, Execute, Complete, pWorker, &pWorker->m_Work));\n", NameSpace)
+	fmt.Fprintf(implw, "    C%sBaseClass::CheckStatus (env, napi_create_promise (env, &pWorker->m_Deferred, &promise));\n", NameSpace)
+	fmt.Fprintf(implw, "    C%sBaseClass::CheckStatus (env, napi_queue_async_work (env, pWorker->m_Work));\n", NameSpace)
+	fmt.Fprintf(implw, "    pOwnedWorker.release ();\n")
+	fmt.Fprintf(implw, "    return promise;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "napi_value C%sAsyncWorker::Reject (napi_env env, napi_value error)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // Invalid parameters reject the promise instead of throwing, in which case a pending exception takes precedence\n")
+	fmt.Fprintf(implw, "    bool bIsExceptionPending = false;\n")
+	fmt.Fprintf(implw, "    if ((napi_is_exception_pending (env, &bIsExceptionPending) == napi_ok) && bIsExceptionPending)\n")
+	fmt.Fprintf(implw, "        napi_get_and_clear_last_exception (env, &error);\n")
+	fmt.Fprintf(implw, "    if (error == nullptr)\n")
+	fmt.Fprintf(implw, "        napi_get_undefined (env, &error);\n")
+	fmt.Fprintf(implw, "    napi_deferred deferred = nullptr;\n")
+	fmt.Fprintf(implw, "    napi_value promise = nullptr;\n")
+	fmt.Fprintf(implw, "    if ((napi_create_promise (env, &deferred, &promise) != napi_ok) || (napi_reject_deferred (env, deferred, error) != napi_ok))\n")
+	fmt.Fprintf(implw, "        return nullptr;\n")
+	fmt.Fprintf(implw, "    return promise;\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sAsyncWorker::Execute (napi_env env, void * pData)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // Runs on the worker thread, on which the callbacks find the JS functions of their scopes\n")
+	fmt.Fprintf(implw, "    C%sAsyncWorker * pWorker = (C%sAsyncWorker *) pData;\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "    for (C%sCallbackScope * pScope : pWorker->m_CallbackScopes)\n", NameSpace)
+	fmt.Fprintf(implw, "        pScope->enter ();\n")
+	fmt.Fprintf(implw, "    try {\n")
+	fmt.Fprintf(implw, "        pWorker->execute ();\n")
+	fmt.Fprintf(implw, "    } catch (E%sException & E) {\n", NameSpace)
+	fmt.Fprintf(implw, "        pWorker->m_bHasError = true;\n")
+	fmt.Fprintf(implw, "        pWorker->m_bHasErrorCode = true;\n")
+	fmt.Fprintf(implw, "        pWorker->m_nErrorCode = E.getErrorCode ();\n")
+	fmt.Fprintf(implw, "        pWorker->m_sErrorMessage = E.what ();\n")
+	fmt.Fprintf(implw, "    } catch (std::exception & E) {\n")
+	fmt.Fprintf(implw, "        pWorker->m_bHasError = true;\n")
+	fmt.Fprintf(implw, "        pWorker->m_sErrorMessage = E.what ();\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "    for (size_t nIndex = pWorker->m_CallbackScopes.size (); nIndex > 0; nIndex--)\n")
+	fmt.Fprintf(implw, "        pWorker->m_CallbackScopes[nIndex - 1]->leave ();\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "void C%sAsyncWorker::Complete (napi_env env, napi_status status, void * pData)\n", NameSpace)
+	fmt.Fprintf(implw, "{\n")
+	fmt.Fprintf(implw, "    // Runs on the main thread. An exception of a JS function or an aborted AbortSignal takes precedence over the error\n")
+	fmt.Fprintf(implw, "    // of the library, as it is the cause of it.\n")
+	fmt.Fprintf(implw, "    std::unique_ptr<C%sAsyncWorker> pWorker ((C%sAsyncWorker *) pData);\n", NameSpace, NameSpace)
+	fmt.Fprintf(implw, "    napi_value error = nullptr;\n")
+	fmt.Fprintf(implw, "    napi_value result = nullptr;\n")
+	fmt.Fprintf(implw, "    bool bIsRejected = false;\n")
+	fmt.Fprintf(implw, "    for (C%sCallbackScope * pScope : pWorker->m_CallbackScopes) {\n", NameSpace)
+	fmt.Fprintf(implw, "        if (!bIsRejected) {\n")
+	fmt.Fprintf(implw, "            error = pScope->getError (env);\n")
+	fmt.Fprintf(implw, "            bIsRejected = (error != nullptr);\n")
+	fmt.Fprintf(implw, "        }\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "    if (!bIsRejected && pWorker->m_bHasError) {\n")
+	fmt.Fprintf(implw, "        if (pWorker->m_bHasErrorCode)\n")
+	fmt.Fprintf(implw, "            error = C%sBaseClass::createError (env, pWorker->m_sErrorMessage, pWorker->m_nErrorCode);\n", NameSpace)
+	fmt.Fprintf(implw, "        else\n")
+	fmt.Fprintf(implw, "            error = C%sBaseClass::createError (env, pWorker->m_sErrorMessage);\n", NameSpace)
+	fmt.Fprintf(implw, "        bIsRejected = true;\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "    if (!bIsRejected && (status != napi_ok)) {\n")
+	fmt.Fprintf(implw, "        error = C%sBaseClass::createError (env, \"The asynchronous call has been cancelled\");\n", NameSpace)
+	fmt.Fprintf(implw, "        bIsRejected = true;\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "    if (!bIsRejected) {\n")
+	fmt.Fprintf(implw, "        try {\n")
+	fmt.Fprintf(implw, "            result = pWorker->complete (env);\n")
+	fmt.Fprintf(implw, "        } catch (E%sException & E) {\n", NameSpace)
+	fmt.Fprintf(implw, "            error = C%sBaseClass::createError (env, E.what(), E.getErrorCode ());\n", NameSpace)
+	fmt.Fprintf(implw, "            bIsRejected = true;\n")
+	fmt.Fprintf(implw, "        } catch (std::exception & E) {\n")
+	fmt.Fprintf(implw, "            error = C%sBaseClass::createError (env, E.what());\n", NameSpace)
+	fmt.Fprintf(implw, "            bIsRejected = true;\n")
+	fmt.Fprintf(implw, "        }\n")
+	fmt.Fprintf(implw, "        bool bIsExceptionPending = false;\n")
+	fmt.Fprintf(implw, "        if ((napi_is_exception_pending (env, &bIsExceptionPending) == napi_ok) && bIsExceptionPending) {\n")
+	fmt.Fprintf(implw, "            napi_get_and_clear_last_exception (env, &error);\n")
+	fmt.Fprintf(implw, "            bIsRejected = true;\n")
+	fmt.Fprintf(implw, "        }\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "    if (bIsRejected) {\n")
+	fmt.Fprintf(implw, "        if (error == nullptr)\n")
+	fmt.Fprintf(implw, "            napi_get_undefined (env, &error);\n")
+	fmt.Fprintf(implw, "        napi_reject_deferred (env, pWorker->m_Deferred, error);\n")
+	fmt.Fprintf(implw, "    } else {\n")
+	fmt.Fprintf(implw, "        if (result == nullptr)\n")
+	fmt.Fprintf(implw, "            napi_get_undefined (env, &result);\n")
+	fmt.Fprintf(implw, "        napi_resolve_deferred (env, pWorker->m_Deferred, result);\n")
+	fmt.Fprintf(implw, "    }\n")
+	fmt.Fprintf(implw, "}\n")
+	fmt.Fprintf(implw, "\n")
+	fmt.Fprintf(implw, "\n")
+}
+
+func buildNodeBindingGyp(component ComponentDefinition, w io.Writer, indentString string) error {
+
+	BaseName := component.BaseName;
+	indent2 := indentString + indentString
+	indent3 := indent2 + indentString
+	indent4 := indent3 + indentString
+	indent5 := indent4 + indentString
+
+	fmt.Fprintf(w, "{\n")
+	fmt.Fprintf(w, "%s\"targets\": [\n", indentString)
+	fmt.Fprintf(w, "%s{\n", indent2)
+	fmt.Fprintf(w, "%s\"target_name\": \"%s_nodeaddon\",\n", indent3, BaseName)
+	fmt.Fprintf(w, "%s\"sources\": [ \"%s_nodeaddon.cc\", \"%s_nodewrapper.cc\", \"%s_dynamic.cpp\" ],\n", indent3, BaseName, BaseName, BaseName)
+	fmt.Fprintf(w, "%s\"defines\": [ \"NAPI_VERSION=%d\" ],\n", indent3, NodeAPIVersion)
+	fmt.Fprintf(w, "%s\"cflags_cc!\": [ \"-fno-exceptions\" ],\n", indent3)
+	fmt.Fprintf(w, "%s\"xcode_settings\": {\n", indent3)
+	fmt.Fprintf(w, "%s\"GCC_ENABLE_CPP_EXCEPTIONS\": \"YES\"\n", indent4)
+	fmt.Fprintf(w, "%s},\n", indent3)
+	fmt.Fprintf(w, "%s\"msvs_settings\": {\n", indent3)
+	fmt.Fprintf(w, "%s\"VCCLCompilerTool\": { \"ExceptionHandling\": 1 }\n", indent4)
+	fmt.Fprintf(w, "%s},\n", indent3)
+	fmt.Fprintf(w, "%s\"conditions\": [\n", indent3)
+	fmt.Fprintf(w, "%s[ \"OS=='linux'\", {\n", indent4)
+	fmt.Fprintf(w, "%s\"libraries\": [ \"-ldl\" ]\n", indent5)
+	fmt.Fprintf(w, "%s} ]\n", indent4)
+	fmt.Fprintf(w, "%s]\n", indent3)
+	fmt.Fprintf(w, "%s}\n", indent2)
+	fmt.Fprintf(w, "%s]\n", indentString)
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "\n")
+
+	return nil;
+
+}
+
+// buildNodePackageJSON writes the package.json, that builds the addon with node-gyp when the package is installed
+func buildNodePackageJSON(component ComponentDefinition, w io.Writer, indentString string) error {
+
+	BaseName := component.BaseName;
+
+	fmt.Fprintf(w, "{\n")
+	fmt.Fprintf(w, "%s\"name\": \"%s\",\n", indentString, strings.ToLower(BaseName))
+	fmt.Fprintf(w, "%s\"version\": \"%s\",\n", indentString, component.Version)
+	fmt.Fprintf(w, "%s\"description\": \"NodeJS bindings of %s\",\n", indentString, component.LibraryName)
+	fmt.Fprintf(w, "%s\"main\": \"build/Release/%s_nodeaddon.node\",\n", indentString, BaseName)
+	fmt.Fprintf(w, "%s\"types\": \"%s_nodeaddon.d.ts\",\n", indentString, BaseName)
+	fmt.Fprintf(w, "%s\"gypfile\": true,\n", indentString)
+	fmt.Fprintf(w, "%s\"scripts\": {\n", indentString)
+	fmt.Fprintf(w, "%s%s\"install\": \"node-gyp rebuild\"\n", indentString, indentString)
+	fmt.Fprintf(w, "%s},\n", indentString)
+	fmt.Fprintf(w, "%s\"binary\": {\n", indentString)
+	fmt.Fprintf(w, "%s%s\"napi_versions\": [ %d ]\n", indentString, indentString, NodeAPIVersion)
+	fmt.Fprintf(w, "%s},\n", indentString)
+	fmt.Fprintf(w, "%s\"engines\": {\n", indentString)
+	fmt.Fprintf(w, "%s%s\"node\": \">=10.20.0\"\n", indentString, indentString)
+	fmt.Fprintf(w, "%s}\n", indentString)
+	fmt.Fprintf(w, "}\n")
+
+	return nil;
+}
+
+// getNodeTypedArrayName returns the TypedArray class for the elements of a basicarray, or "" if there is none
+func getNodeTypedArrayName(elementType string) string {
+	switch elementType {
+	case "uint8":
+		return "Uint8Array"
+	case "uint16":
+		return "Uint16Array"
+	case "uint32":
+		return "Uint32Array"
+	case "uint64":
+		return "BigUint64Array"
+	case "int8":
+		return "Int8Array"
+	case "int16":
+		return "Int16Array"
+	case "int32":
+		return "Int32Array"
+	case "int64":
+		return "BigInt64Array"
+	case "single":
+		return "Float32Array"
+	case "double":
+		return "Float64Array"
+	}
+	return ""
+}
+
+// getNodeTypeScriptType returns the TypeScript type of a parameter, as accepted (in) or returned (out, return) by the NodeJS binding
+func getNodeTypeScriptType(param ComponentDefinitionParam, classNames map[string]bool) (string, error) {
+	isInput := (param.ParamPass == "in")
+
+	switch param.ParamType {
+	case "uint8", "uint16", "uint32", "int8", "int16", "int32", "single", "double":
+		return "number", nil
+	case "uint64", "int64":
+		if isInput {
+			return "number | bigint", nil
+		}
+		return "number", nil
+	case "bool":
+		return "boolean", nil
+	case "string":
+		return "string", nil
+	case "enum", "struct":
+		return param.ParamClass, nil
+	case "handle":
+		// Handles of the implicit base class accept instances of any class
+		className := param.ParamClass
+		if !classNames[className] {
+			className = "BaseClass"
+		}
+		return className + " | null", nil
+	case "functiontype":
+		return param.ParamClass + " | null", nil
+	case "structarray":
+		return param.ParamClass + "[]", nil
+	case "basicarray":
+		elementParam := ComponentDefinitionParam{ParamType: param.ParamClass, ParamPass: param.ParamPass}
+		elementType, err := getNodeTypeScriptType(elementParam, classNames)
+		if err != nil {
+			return "", err
+		}
+		arrayName := getNodeTypedArrayName(param.ParamClass)
+		if arrayName == "" {
+			return elementType + "[]", nil
+		}
+		if isInput {
+			if strings.Contains(elementType, "|") {
+				elementType = "(" + elementType + ")"
+			}
+			return arrayName + " | " + elementType + "[]", nil
+		}
+		return arrayName, nil
+	}
+	return "", fmt.Errorf("invalid parameter type \"%s\" for TypeScript declaration", param.ParamType)
+}
+
+// getNodeTypeScriptMemberType returns the TypeScript type of a member of a struct
+func getNodeTypeScriptMemberType(member ComponentDefinitionMember) string {
+	memberType := "number"
+	switch member.Type {
+	case "bool":
+		memberType = "boolean"
+	case "enum":
+		memberType = member.Class
+	}
+	if member.Rows > 0 {
+		memberType = memberType + "[]"
+	}
+	if member.Columns > 0 {
+		memberType = memberType + "[]"
+	}
+	return memberType
+}
+
+// writeNodeTypeScriptMethod declares a method of a class in the TypeScript declaration file
+// writeNodeTypeScriptMethod declares a method. The asynchronous variant of it returns a promise, and takes an AbortSignal
+// as last parameter, if it can be cancelled.
+func writeNodeTypeScriptMethod(method ComponentDefinitionMethod, w io.Writer, indent string, indentString string, classNames map[string]bool, isReleaseMethod bool, isAsync bool, isCancellable bool) error {
+	parameters := ""
+	outputs := []ComponentDefinitionParam{}
+	outputTypes := []string{}
+
+	fmt.Fprintf(w, "%s/**\n", indent)
+	fmt.Fprintf(w, "%s * %s\n", indent, method.MethodDescription)
+	if isAsync {
+		fmt.Fprintf(w, "%s *\n", indent)
+		fmt.Fprintf(w, "%s * Calls %s on a worker thread. The objects and arrays passed must not be modified or released, until the\n", indent, method.MethodName)
+		fmt.Fprintf(w, "%s * returned promise is settled.\n", indent)
+	}
+	for _, param := range method.Params {
+		paramType, err := getNodeTypeScriptType(param, classNames)
+		if err != nil {
+			return fmt.Errorf("%s (%s.%s)", err, method.MethodName, param.ParamName)
+		}
+		if param.ParamPass != "in" {
+			outputs = append(outputs, param)
+			outputTypes = append(outputTypes, paramType)
+			continue
+		}
+
+		// The release method requires an instance
+		if isReleaseMethod && (param.ParamType == "handle") {
+			paramType = strings.TrimSuffix(paramType, " | null")
+		}
+		if parameters != "" {
+			parameters = parameters + ", "
+		}
+		parameters = parameters + param.ParamName + ": " + paramType
+		fmt.Fprintf(w, "%s * @param %s %s\n", indent, param.ParamName, param.ParamDescription)
+	}
+
+	methodName := method.MethodName
+	returnPrefix := ""
+	returnSuffix := ""
+	if isAsync {
+		methodName = method.MethodName + "Async"
+		returnPrefix = "Promise<"
+		returnSuffix = ">"
+		if isCancellable {
+			if parameters != "" {
+				parameters = parameters + ", "
+			}
+			parameters = parameters + "signal?: AbortSignal"
+			fmt.Fprintf(w, "%s * @param signal Aborts the call, which rejects the promise with the reason of the signal\n", indent)
+		}
+	}
+	if len(outputs) == 1 {
+		fmt.Fprintf(w, "%s * @returns %s\n", indent, outputs[0].ParamDescription)
+	}
+	fmt.Fprintf(w, "%s */\n", indent)
+
+	switch len(outputs) {
+	case 0:
+		fmt.Fprintf(w, "%s%s (%s): %svoid%s;\n", indent, methodName, parameters, returnPrefix, returnSuffix)
+	case 1:
+		fmt.Fprintf(w, "%s%s (%s): %s%s%s;\n", indent, methodName, parameters, returnPrefix, outputTypes[0], returnSuffix)
+	default:
+		// Several outputs are returned as the properties of an object
+		fmt.Fprintf(w, "%s%s (%s): %s{\n", indent, methodName, parameters, returnPrefix)
+		for i, param := range outputs {
+			fmt.Fprintf(w, "%s%s/** %s */\n", indent, indentString, param.ParamDescription)
+			fmt.Fprintf(w, "%s%s%s: %s;\n", indent, indentString, param.ParamName, outputTypes[i])
+		}
+		fmt.Fprintf(w, "%s}%s;\n", indent, returnSuffix)
+	}
+	return nil
+}
+
+// buildNodeTypeScriptDeclaration writes the TypeScript declarations of the module of the NodeJS binding
+func buildNodeTypeScriptDeclaration(component ComponentDefinition, w io.Writer, indentString string) error {
+	NameSpace := component.NameSpace
+	indent2 := indentString + indentString
+
+	classNames := make(map[string]bool)
+	rootClasses := []string{}
+	for _, class := range component.Classes {
+		classNames[class.ClassName] = true
+		if class.ParentClass == "" {
+			rootClasses = append(rootClasses, class.ClassName)
+		}
+	}
+
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "/**\n")
+	fmt.Fprintf(w, " * Loads %s\n", component.LibraryName)
+	fmt.Fprintf(w, " * @param libraryName Path of the library, by default %s.dll, %s.dylib or %s.so\n", component.BaseName, component.BaseName, component.BaseName)
+	fmt.Fprintf(w, " */\n")
+	fmt.Fprintf(w, "declare function Load%s (libraryName?: string): Load%s.%sWrapper;\n", NameSpace, NameSpace, NameSpace)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "declare namespace Load%s {\n", NameSpace)
+	fmt.Fprintf(w, "\n")
+
+	fmt.Fprintf(w, "%s/** Error codes of %s */\n", indentString, component.LibraryName)
+	fmt.Fprintf(w, "%sconst enum ErrorCode {\n", indentString)
+	for _, merror := range component.Errors.Errors {
+		fmt.Fprintf(w, "%s/** %s */\n", indent2, merror.Description)
+		fmt.Fprintf(w, "%s%s = %d,\n", indent2, merror.Name, merror.Code)
+	}
+	fmt.Fprintf(w, "%s}\n", indentString)
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "%s/** Error, that is thrown when a call of %s fails */\n", indentString, component.LibraryName)
+	fmt.Fprintf(w, "%sclass %sError extends Error {\n", indentString, NameSpace)
+	fmt.Fprintf(w, "%sconstructor (message: string, code: ErrorCode);\n", indent2)
+	fmt.Fprintf(w, "%s/** Error code of the library */\n", indent2)
+	fmt.Fprintf(w, "%sreadonly code: ErrorCode;\n", indent2)
+	fmt.Fprintf(w, "%s}\n", indentString)
+
+	for _, enum := range component.Enums {
+		fmt.Fprintf(w, "\n")
+		fmt.Fprintf(w, "%sconst enum %s {\n", indentString, enum.Name)
+		for _, option := range enum.Options {
+			fmt.Fprintf(w, "%s%s = %d,\n", indent2, option.Name, option.Value)
+		}
+		fmt.Fprintf(w, "%s}\n", indentString)
+	}
+
+	for _, structinfo := range component.Structs {
+		fmt.Fprintf(w, "\n")
+		fmt.Fprintf(w, "%sinterface %s {\n", indentString, structinfo.Name)
+		for _, member := range structinfo.Members {
+			fmt.Fprintf(w, "%s%s: %s;\n", indent2, member.Name, getNodeTypeScriptMemberType(member))
+		}
+		fmt.Fprintf(w, "%s}\n", indentString)
+	}
+
+	for _, functiontype := range component.Functions {
+		parameters := ""
+		outputs := []string{}
+		for _, param := range functiontype.Params {
+			paramType, err := getNodeTypeScriptType(param, classNames)
+			if err != nil {
+				return fmt.Errorf("%s (%s)", err, functiontype.FunctionName)
+			}
+			if param.ParamPass != "in" {
+				// The outputs of a callback are converted with the getters of the input parameters
+				param.ParamPass = "in"
+				paramType, err = getNodeTypeScriptType(param, classNames)
+				if err != nil {
+					return err
+				}
+				outputs = append(outputs, param.ParamName + ": " + paramType)
+				continue
+			}
+			if parameters != "" {
+				parameters = parameters + ", "
+			}
+			parameters = parameters + param.ParamName + ": " + paramType
+		}
+
+		returnType := "void"
+		if len(outputs) == 1 {
+			returnType = strings.SplitN(outputs[0], ": ", 2)[1] + " | void"
+		}
+		if len(outputs) > 1 {
+			returnType = "{ " + strings.Join(outputs, "; ") + " } | void"
+		}
+
+		fmt.Fprintf(w, "\n")
+		fmt.Fprintf(w, "%s/** %s */\n", indentString, functiontype.FunctionDescription)
+		fmt.Fprintf(w, "%stype %s = (%s) => %s;\n", indentString, functiontype.FunctionName, parameters, returnType)
+	}
+
+	if !classNames["BaseClass"] {
+		baseClass := "never"
+		if len(rootClasses) > 0 {
+			baseClass = strings.Join(rootClasses, " | ")
+		}
+		fmt.Fprintf(w, "\n")
+		fmt.Fprintf(w, "%s/** Instance of any class */\n", indentString)
+		fmt.Fprintf(w, "%stype BaseClass = %s;\n", indentString, baseClass)
+	}
+
+	for _, class := range component.Classes {
+		extends := ""
+		if class.ParentClass != "" {
+			extends = " extends " + class.ParentClass
+		}
+		fmt.Fprintf(w, "\n")
+		fmt.Fprintf(w, "%s/** %s */\n", indentString, class.ClassDescription)
+		fmt.Fprintf(w, "%sclass %s%s {\n", indentString, class.ClassName, extends)
+		fmt.Fprintf(w, "%sprotected constructor ();\n", indent2)
+		for _, method := range class.Methods {
+			fmt.Fprintf(w, "\n")
+			err := writeNodeTypeScriptMethod(method, w, indent2, indentString, classNames, false, false, false)
+			if err != nil {
+				return err
+			}
+			if method.Async {
+				_, _, isCancellable := component.GetCancellationCallback(method)
+				fmt.Fprintf(w, "\n")
+				err = writeNodeTypeScriptMethod(method, w, indent2, indentString, classNames, false, true, isCancellable)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		fmt.Fprintf(w, "%s}\n", indentString)
+	}
+
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "%s/** Global functions of %s */\n", indentString, component.LibraryName)
+	fmt.Fprintf(w, "%sclass %sWrapper {\n", indentString, NameSpace)
+	fmt.Fprintf(w, "%sprotected constructor ();\n", indent2)
+	for _, enum := range component.Enums {
+		fmt.Fprintf(w, "\n")
+		for _, option := range enum.Options {
+			fmt.Fprintf(w, "%sreadonly e%s_%s: %s.%s;\n", indent2, enum.Name, option.Name, enum.Name, option.Name)
+		}
+	}
+	for _, method := range component.Global.Methods {
+		fmt.Fprintf(w, "\n")
+		err := writeNodeTypeScriptMethod(method, w, indent2, indentString, classNames, method.MethodName == component.Global.ReleaseMethod, false, false)
+		if err != nil {
+			return err
+		}
+		if method.Async {
+			_, _, isCancellable := component.GetCancellationCallback(method)
+			fmt.Fprintf(w, "\n")
+			err = writeNodeTypeScriptMethod(method, w, indent2, indentString, classNames, false, true, isCancellable)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	fmt.Fprintf(w, "%s}\n", indentString)
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "export = Load%s;\n", NameSpace)
+
+	return nil
 }
@@ -0,0 +1,284 @@
+/*++
+
+Copyright (C) 2018 Autodesk Inc. (Original Author)
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+--*/
+
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+// buildbindingnode_test.go
+// Tests of the NodeJS binding generator
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const nodeCallbackTestComponent = `<?xml version="1.0" encoding="UTF-8"?>
+<component xmlns="http://schemas.autodesk.com/netfabb/automaticcomponenttoolkit/2018" libraryname="Callback Library" namespace="Cb" copyright="Example" year="2024" basename="cb" version="1.0.0">
+	<license>
+		<line value="All rights reserved." />
+	</license>
+	<bindings>
+		<binding language="NodeJS" indentation="4spaces" />
+	</bindings>
+	<errors>
+		<error name="NOTIMPLEMENTED" code="1" description="functionality not implemented" />
+		<error name="INVALIDPARAM" code="2" description="an invalid parameter was passed" />
+		<error name="INVALIDCAST" code="3" description="a type cast failed" />
+		<error name="BUFFERTOOSMALL" code="4" description="a provided buffer is too small" />
+		<error name="GENERICEXCEPTION" code="5" description="a generic exception occurred" />
+		<error name="COULDNOTLOADLIBRARY" code="6" description="the library could not be loaded" />
+		<error name="COULDNOTFINDLIBRARYEXPORT" code="7" description="a required exported symbol could not be found in the library" />
+	</errors>
+	<functiontype name="Tick" description="Reports a tick">
+		<param name="Value" type="uint32" pass="in" description="The value" />
+	</functiontype>
+	<class name="Base" description="The base class">
+	</class>
+	<class name="Worker" parent="Base" description="A worker">
+		<method name="SetTick" description="Stores the tick callback">
+			<param name="Tick" type="functiontype" class="Tick" pass="in" description="The callback" />
+		</method>
+		<method name="Fire" description="Calls the stored callback">
+			<param name="Count" type="uint32" pass="in" description="The number of calls" />
+		</method>
+		<method name="FireInThread" description="Calls the stored callback on a thread, which the call waits for">
+			<param name="Count" type="uint32" pass="in" description="The number of calls" />
+		</method>
+		<method name="StartThread" description="Calls the stored callback on a thread, which runs after the call">
+			<param name="Count" type="uint32" pass="in" description="The number of calls" />
+		</method>
+	</class>
+	<global baseclassname="Base" releasemethod="ReleaseInstance" versionmethod="GetVersion">
+		<method name="ReleaseInstance" description="Releases an instance">
+			<param name="Instance" type="handle" class="Base" pass="in" description="The instance" />
+		</method>
+		<method name="GetVersion" description="Returns the version">
+			<param name="Major" type="uint32" pass="out" description="The major version" />
+			<param name="Minor" type="uint32" pass="out" description="The minor version" />
+			<param name="Micro" type="uint32" pass="out" description="The micro version" />
+		</method>
+		<method name="CreateWorker" description="Creates a worker">
+			<param name="Instance" type="handle" class="Worker" pass="return" description="The worker" />
+		</method>
+	</global>
+</component>
+`
+
+// nodeCallbackTestLibrary stores the callback of a worker in SetTick and calls it later, on the calling thread or on
+// threads of its own
+const nodeCallbackTestLibrary = `#include <pthread.h>
+#include <stdint.h>
+#include <stdlib.h>
+#include <unistd.h>
+
+typedef void (*CbTick) (uint32_t);
+typedef struct { CbTick tick; uint32_t count; int delayed; } Worker;
+
+static void * fire (void * pWorker)
+{
+	Worker * worker = (Worker *) pWorker;
+	if (worker->delayed) usleep (100000);
+	for (uint32_t i = 0; i < worker->count; i++)
+		if (worker->tick) worker->tick (i);
+	return NULL;
+}
+
+int32_t cb_worker_settick (void * pWorker, CbTick pTick) { ((Worker *) pWorker)->tick = pTick; return 0; }
+int32_t cb_worker_fire (void * pWorker, uint32_t nCount) { ((Worker *) pWorker)->count = nCount; fire (pWorker); return 0; }
+int32_t cb_worker_fireinthread (void * pWorker, uint32_t nCount)
+{
+	pthread_t thread;
+	((Worker *) pWorker)->count = nCount;
+	if (pthread_create (&thread, NULL, fire, pWorker) != 0) return 5;
+	pthread_join (thread, NULL);
+	return 0;
+}
+int32_t cb_worker_startthread (void * pWorker, uint32_t nCount)
+{
+	pthread_t thread;
+	((Worker *) pWorker)->count = nCount;
+	((Worker *) pWorker)->delayed = 1;
+	if (pthread_create (&thread, NULL, fire, pWorker) != 0) return 5;
+	pthread_detach (thread);
+	return 0;
+}
+int32_t cb_releaseinstance (void * pInstance) { free (pInstance); return 0; }
+int32_t cb_getversion (uint32_t * a, uint32_t * b, uint32_t * c) { *a = 1; *b = 0; *c = 0; return 0; }
+int32_t cb_createworker (void ** pInstance) { *pInstance = calloc (1, sizeof (Worker)); return 0; }
+`
+
+const nodeCallbackTestProgram = `const library = require (process.argv[2]) (process.argv[3]);
+
+const first = library.CreateWorker ();
+const second = library.CreateWorker ();
+let firstCalls = 0;
+let secondCalls = 0;
+first.SetTick ((value) => { firstCalls++; });
+second.SetTick ((value) => { secondCalls++; });
+const third = library.CreateWorker ();
+third.SetTick ((value) => { throw new Error ("tick " + value); });
+// More distinct functions than slots must not take over the slots of the stored callbacks
+const others = [];
+for (let i = 0; i < 40; i++) {
+	const other = library.CreateWorker ();
+	other.SetTick ((value) => {});
+	others.push (other);
+}
+first.Fire (10);
+second.Fire (3);
+console.log (firstCalls, secondCalls);
+// An exception of a stored callback is thrown by the method, which called it
+try {
+	third.Fire (1);
+	console.log ("no error");
+} catch (error) {
+	console.log (error.message);
+}
+
+// A thread can not call the JS function, while the main thread waits for it
+try {
+	first.FireInThread (1);
+	console.log ("no error");
+} catch (error) {
+	console.log (error.message);
+}
+
+// A thread of the library calls the JS function after the method has returned. Calls of other threads during a
+// synchronous method are refused, so the thread of the test library waits for the return.
+first.StartThread (5);
+const waitForThread = setInterval (() => {
+	if (firstCalls === 15) {
+		clearInterval (waitForThread);
+		console.log (firstCalls);
+	}
+}, 10);
+`
+
+func buildNodeCallbackTestBinding(t *testing.T) (string) {
+	component, err := UnmarshalComponentDefinition([]byte(nodeCallbackTestComponent), eComponentDefinitionFormatXML)
+	if (err != nil) {
+		t.Fatal(err)
+	}
+	err = CheckComponentDefinition(component)
+	if (err != nil) {
+		t.Fatal(err)
+	}
+	outputFolder := t.TempDir()
+	err = generateBindingNode(component, outputFolder, GeneratorOptions{Indentation: "4spaces"})
+	if (err != nil) {
+		t.Fatal(err)
+	}
+	return filepath.Join(outputFolder, "Bindings", "NodeJS")
+}
+
+func TestNodeBindingKeepsCallbackSlots(t *testing.T) {
+	outputFolder := buildNodeCallbackTestBinding(t)
+	data, err := ioutil.ReadFile(filepath.Join(outputFolder, "cb_nodewrapper.cc"))
+	if (err != nil) {
+		t.Fatal(err)
+	}
+	implementation := string(data)
+	for _, expected := range []string{
+		"pThis->keepCallbackSlot (\"SetTick.Tick\", callbackScopeTick.releaseSlot ());",
+		"m_Worker_SetTick (pThis->getHandle (), getCallbackTick (callbackScopeTick));",
+		"CCbCallbackSlot::run (\"Tick\", nSlot, callFunction);",
+		"CCbSynchronousCall synchronousCall (env);",
+	} {
+		if !strings.Contains(implementation, expected) {
+			t.Errorf("cb_nodewrapper.cc does not contain %q", expected)
+		}
+	}
+	if strings.Contains(implementation, "if ((pScope == nullptr) || pScope->isEmpty ())\n        return;") {
+		t.Error("cb_nodewrapper.cc drops the calls of callbacks without a running method")
+	}
+}
+
+func TestNodeBindingCallsStoredCallbacks(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if (err != nil) {
+		t.Skip("node is not available")
+	}
+	compiler, err := exec.LookPath("g++")
+	if (err != nil) {
+		t.Skip("g++ is not available")
+	}
+	output, err := exec.Command(node, "-p", "require ('path').join (process.execPath, '..', '..', 'include', 'node')").Output()
+	if (err != nil) {
+		t.Skipf("the headers of node can not be located: %v", err)
+	}
+	includeFolder := strings.TrimSpace(string(output))
+	if _, err := os.Stat(filepath.Join(includeFolder, "node_api.h")); (err != nil) {
+		includeFolder = "/usr/include/node"
+		if _, err := os.Stat(filepath.Join(includeFolder, "node_api.h")); (err != nil) {
+			t.Skip("the headers of node are not available")
+		}
+	}
+
+	outputFolder := buildNodeCallbackTestBinding(t)
+	testFolder := filepath.Dir(filepath.Dir(outputFolder))
+	files := map[string]string{
+		"library.c": nodeCallbackTestLibrary,
+		"program.js": nodeCallbackTestProgram,
+	}
+	for fileName, content := range files {
+		err = ioutil.WriteFile(filepath.Join(testFolder, fileName), []byte(content), 0644)
+		if (err != nil) {
+			t.Fatal(err)
+		}
+	}
+
+	libraryName := filepath.Join(testFolder, "libcb.so")
+	output, err = exec.Command(compiler, "-x", "c", "-shared", "-fPIC", "-pthread", "-o", libraryName, filepath.Join(testFolder, "library.c")).CombinedOutput()
+	if (err != nil) {
+		t.Skipf("the test library cannot be built: %v\n%s", err, output)
+	}
+
+	addOnName := filepath.Join(testFolder, "cb.node")
+	command := exec.Command(compiler, "-std=c++11", "-shared", "-fPIC", "-pthread", "-I" + includeFolder, "-DNODE_GYP_MODULE_NAME=cb_nodeaddon", "-DNAPI_VERSION=6",
+		"cb_nodeaddon.cc", "cb_nodewrapper.cc", "cb_dynamic.cpp", "-ldl", "-o", addOnName)
+	command.Dir = outputFolder
+	output, err = command.CombinedOutput()
+	if (err != nil) {
+		t.Fatalf("%v\n%s", err, output)
+	}
+
+	output, err = exec.Command(node, filepath.Join(testFolder, "program.js"), addOnName, libraryName).CombinedOutput()
+	if (err != nil) {
+		t.Fatalf("%v\n%s", err, output)
+	}
+	expected := "10 3\ntick 0\nCallback Tick was called by another thread during a synchronous method call\n15"
+	if (strings.TrimSpace(string(output)) != expected) {
+		t.Errorf("the stored callbacks returned\n%s\nexpected\n%s", strings.TrimSpace(string(output)), expected)
+	}
+}
@@ -43,9 +43,7 @@ import (
 
 // BuildBindingPascalDynamic builds dynamic Pascal bindings of a library's API in form of dynamically loaded functions
 // handles.
-func BuildBindingPascalDynamic(componentdefinition ComponentDefinition, outputFolder string, outputFolderExample string, indentString string) error {
-	forceRecreation := false
-
+func BuildBindingPascalDynamic(componentdefinition ComponentDefinition, outputFolder string, outputFolderExample string, indentString string, forceRecreation ForceRecreation) error {
 	namespace := componentdefinition.NameSpace;
 	libraryname := componentdefinition.LibraryName;
 	baseName := componentdefinition.BaseName;
@@ -58,9 +56,12 @@ func BuildBindingPascalDynamic(componentdefinition ComponentDefinition, outputFo
 	}
 
 	dynpascalfile.Writeln("{$IFDEF FPC}{$MODE DELPHI}{$ENDIF}")
-	dynpascalfile.WritePascalLicenseHeader(componentdefinition,
+	err = dynpascalfile.WritePascalLicenseHeader(componentdefinition,
 		fmt.Sprintf("This is an autogenerated Pascal Header file in order to allow an easy\n use of %s", libraryname),
 		true)
+	if err != nil {
+		return err
+	}
 	
 	err = buildDynamicPascalImplementation(componentdefinition, dynpascalfile, namespace, baseName)
 	if err != nil {
@@ -69,13 +70,20 @@ func BuildBindingPascalDynamic(componentdefinition ComponentDefinition, outputFo
 	
 	if len(outputFolderExample) > 0 {
 		DynamicPascalExample := path.Join(outputFolderExample, namespace+"_Example.lpr");
-		if (forceRecreation || !FileExists(DynamicPascalExample)) {
+		if (forceRecreation.Examples || !FileExists(DynamicPascalExample)) {
+			err = BackupFile(DynamicPascalExample)
+			if err != nil {
+				return err;
+			}
 			log.Printf("Creating \"%s\"", DynamicPascalExample)
 			dynpascalexamplefile, err := CreateLanguageFile (DynamicPascalExample, indentString)
-			dynpascalexamplefile.WritePascalLicenseHeader(componentdefinition,
+			err = dynpascalexamplefile.WritePascalLicenseHeader(componentdefinition,
 				fmt.Sprintf("This is an autogenerated Pascal application that demonstrates the\n usage of the Pascal bindings of %s", libraryname),
 				true)
-			err = buildDynamicPascalExample(dynpascalexamplefile, namespace, baseName, outputFolder)
+			if err != nil {
+				return err
+			}
+			err = buildDynamicPascalExample(componentdefinition, dynpascalexamplefile, outputFolder)
 			if err != nil {
 				return err;
 			}
@@ -84,10 +92,14 @@ func BuildBindingPascalDynamic(componentdefinition ComponentDefinition, outputFo
 		}
 
 		DynamicPascalExampleLPI := path.Join(outputFolderExample, namespace+"_Example.lpi");
-		if (forceRecreation || !FileExists(DynamicPascalExampleLPI)) {
+		if (forceRecreation.Projects || !FileExists(DynamicPascalExampleLPI)) {
+			err = BackupFile(DynamicPascalExampleLPI)
+			if err != nil {
+				return err;
+			}
 			log.Printf("Creating \"%s\"", DynamicPascalExampleLPI)
 			dynpascalexampleLPIfile, err := CreateLanguageFile (DynamicPascalExampleLPI, indentString)
-			err = buildDynamicPascalExampleLPI(dynpascalexampleLPIfile, namespace, baseName, outputFolder)
+			err = buildDynamicPascalExampleLPI(componentdefinition, dynpascalexampleLPIfile, outputFolder)
 			if err != nil {
 				return err;
 			}
@@ -364,6 +376,10 @@ func buildDynamicPascalImplementation(componentdefinition ComponentDefinition, w
 	}
 
 	w.Writeln ("    procedure CheckError (AInstance: T%sBaseClass; AErrorCode: T%sResult);", NameSpace, NameSpace);	
+	errorMethod, hasErrorMethod := componentdefinition.Global.GetErrorMethod ();
+	if (hasErrorMethod) {
+		w.Writeln ("    function GetErrorMessage (AInstance: T%sBaseClass): String;", NameSpace);
+	}
 
 	w.Writeln ("  public");	
 
@@ -535,15 +551,52 @@ func buildDynamicPascalImplementation(componentdefinition ComponentDefinition, w
 	w.Writeln ("  end;");	
 	w.Writeln ("")
 	w.Writeln ("  procedure T%sWrapper.CheckError (AInstance: T%sBaseClass; AErrorCode: T%sResult);", NameSpace, NameSpace, NameSpace);	
+	if (hasErrorMethod) {
+		w.Writeln ("  var");
+		w.Writeln ("    AErrorMessage: String;");
+	}
 	w.Writeln ("  begin")
     w.Writeln ("    if AInstance <> nil then begin");
     w.Writeln ("      if AInstance.FWrapper <> Self then");
     w.Writeln ("        raise E%sException.CreateCustomMessage (%s_ERROR_INVALIDCAST, 'invalid wrapper call');", NameSpace, strings.ToUpper (NameSpace));
     w.Writeln ("    end;");
-	w.Writeln ("    if AErrorCode <> %s_SUCCESS then", strings.ToUpper (NameSpace));
-	w.Writeln ("      raise E%sException.Create (AErrorCode);", NameSpace);
+	if (hasErrorMethod) {
+		w.Writeln ("    if AErrorCode <> %s_SUCCESS then begin", strings.ToUpper (NameSpace));
+		w.Writeln ("      AErrorMessage := GetErrorMessage (AInstance);");
+		w.Writeln ("      if AErrorMessage <> '' then");
+		w.Writeln ("        raise E%sException.CreateCustomMessage (AErrorCode, AErrorMessage);", NameSpace);
+		w.Writeln ("      raise E%sException.Create (AErrorCode);", NameSpace);
+		w.Writeln ("    end;");
+	} else {
+		w.Writeln ("    if AErrorCode <> %s_SUCCESS then", strings.ToUpper (NameSpace));
+		w.Writeln ("      raise E%sException.Create (AErrorCode);", NameSpace);
+	}
 	w.Writeln ("  end;")
 	w.Writeln ("")
+
+	if (hasErrorMethod) {
+		errorFunction := fmt.Sprintf ("F%s%sFunc", NameSpace, errorMethod.MethodName);
+		w.Writeln ("  function T%sWrapper.GetErrorMessage (AInstance: T%sBaseClass): String;", NameSpace, NameSpace);
+		w.Writeln ("  var");
+		w.Writeln ("    AHandle: T%sHandle;", NameSpace);
+		w.Writeln ("    ANeededChars: Cardinal;");
+		w.Writeln ("    AHasError: Cardinal;");
+		w.Writeln ("    ABuffer: array of Char;");
+		w.Writeln ("  begin");
+		w.Writeln ("    Result := '';");
+		w.Writeln ("    AHandle := nil;");
+		w.Writeln ("    if AInstance <> nil then");
+		w.Writeln ("      AHandle := AInstance.FHandle;");
+		w.Writeln ("    ANeededChars := 0;");
+		w.Writeln ("    AHasError := 0;");
+		w.Writeln ("    if (%s (AHandle, 0, ANeededChars, nil, AHasError) <> %s_SUCCESS) or (AHasError = 0) then", errorFunction, strings.ToUpper (NameSpace));
+		w.Writeln ("      exit;");
+		w.Writeln ("    SetLength (ABuffer, ANeededChars + 1);");
+		w.Writeln ("    if %s (AHandle, ANeededChars + 1, ANeededChars, @ABuffer[0], AHasError) = %s_SUCCESS then", errorFunction, strings.ToUpper (NameSpace));
+		w.Writeln ("      Result := StrPas (@ABuffer[0]);");
+		w.Writeln ("  end;");
+		w.Writeln ("")
+	}
 	
 	w.Writeln ("  {$IFDEF MSWINDOWS}");	
 	w.Writeln ("  function T%sWrapper.LoadFunction (AFunctionName: AnsiString; FailIfNotExistent: Boolean): FARPROC;", NameSpace);	
@@ -846,6 +899,7 @@ func writePascalClassMethodImplementation (method ComponentDefinitionMethod, w L
 				switch (param.ParamType) {
 					case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "single", "double":
 						callFunctionParameters = callFunctionParameters + "Result";
+						initCallParameters = initCallParameters + "Result";
 
 					case "string":
 						defineCommands = append (defineCommands, "  bytesNeeded" + param.ParamName + ": Cardinal;");
@@ -871,6 +925,7 @@ func writePascalClassMethodImplementation (method ComponentDefinitionMethod, w L
 						initCommands = append (initCommands, "  Result" + param.ParamName + " := 0;");
 			
 						callFunctionParameters = callFunctionParameters + "Result" + param.ParamName;
+						initCallParameters = initCallParameters + "Result" + param.ParamName;
 						resultCommands = append (resultCommands, fmt.Sprintf ("  Result := convertConstTo%s (Result%s);", param.ParamClass, param.ParamName));
 
 					case "bool":
@@ -878,10 +933,12 @@ func writePascalClassMethodImplementation (method ComponentDefinitionMethod, w L
 						initCommands = append (initCommands, "  Result" + param.ParamName + " := 0;");
 			
 						callFunctionParameters = callFunctionParameters + "Result" + param.ParamName;
+						initCallParameters = initCallParameters + "Result" + param.ParamName;
 						resultCommands = append (resultCommands, fmt.Sprintf ("  Result := (Result%s <> 0);", param.ParamName));
 						
 					case "struct":
 						callFunctionParameters = callFunctionParameters + "@A" + param.ParamName;
+						initCallParameters = initCallParameters + "@A" + param.ParamName;
 
 					case "basicarray", "structarray":
 						defineCommands = append (defineCommands, "  countNeeded" + param.ParamName + ": QWord;");
@@ -902,6 +959,7 @@ func writePascalClassMethodImplementation (method ComponentDefinitionMethod, w L
 						initCommands = append (initCommands, "  Result := nil;");
 						initCommands = append (initCommands, "  H" + param.ParamName + " := nil;");
 						callFunctionParameters = callFunctionParameters + "H" + param.ParamName;
+						initCallParameters = initCallParameters + "H" + param.ParamName;
 						resultCommands = append (resultCommands, fmt.Sprintf ("  if Assigned (H%s) then", param.ParamName));
 						resultCommands = append (resultCommands, fmt.Sprintf ("    Result := T%s%s.Create (%s, H%s);", NameSpace, param.ParamClass, wrapperInstanceName, param.ParamName));
 
@@ -1013,205 +1071,10 @@ func generatePlainPascalParameters(method ComponentDefinitionMethod, className s
 }
 
 
-func buildDynamicPascalExample(w LanguageWriter, NameSpace string, BaseName string, outputFolder string) error {
-	w.Writeln("program %sPascalTest;", NameSpace)
-	w.Writeln("")
-	w.Writeln("uses")
-	w.Writeln("  {$IFDEF UNIX}{$IFDEF UseCThreads}")
-	w.Writeln("  cthreads,")
-	w.Writeln("  {$ENDIF}{$ENDIF}")
-	w.Writeln("  Classes, SysUtils, CustApp,")
-	w.Writeln("  Unit_%s", NameSpace)
-	w.Writeln("  { you can add units after this };")
-	w.Writeln("")
-	w.Writeln("type")
-	w.Writeln("")
-	w.Writeln("T%s_Example = class(TCustomApplication)", NameSpace)
-	w.Writeln("protected")
-	w.Writeln("  procedure DoRun; override;")
-	w.Writeln("  procedure Test%s ();", NameSpace)
-	w.Writeln("public")
-	w.Writeln("  constructor Create(TheOwner: TComponent); override;")
-	w.Writeln("  destructor Destroy; override;")
-	w.Writeln("end;")
-	w.Writeln("")
-	w.Writeln("")
-	w.Writeln("procedure T%s_Example.Test%s ();", NameSpace, NameSpace)
-	w.Writeln("var")
-	w.Writeln("  A%sWrapper: T%sWrapper;", NameSpace, NameSpace)
-	w.Writeln("  AMajor, AMinor, AMicro: Cardinal;")
-	w.Writeln("  ALibPath: string;")
-	w.Writeln("begin")
-	w.Writeln("  writeln ('loading DLL');")
-	w.Writeln("  ALibPath := ''; // TODO add the location of the shared library binary here")
-	w.Writeln("  A%sWrapper := T%sWrapper.Create (ALibPath + '/' + '%s.dll');", NameSpace, NameSpace, BaseName)
-	w.Writeln("  try")
-	w.Writeln("    writeln ('loading DLL Done');")
-	w.Writeln("    A%sWrapper.GetLibraryVersion(AMajor, AMinor, AMicro);", NameSpace)
-	w.Writeln("    writeln (Format('%s.version = %s', [AMajor, AMinor, AMicro]));",NameSpace, "%d.%d.%d")
-	w.Writeln("  finally")
-	w.Writeln("    FreeAndNil(A%sWrapper);", NameSpace)
-	w.Writeln("  end;")
-	w.Writeln("end;")
-	w.Writeln("")
-	w.Writeln("procedure T%s_Example.DoRun;", NameSpace)
-	w.Writeln("begin")
-	w.Writeln("  try")
-	w.Writeln("    Test%s();", NameSpace)
-	w.Writeln("  except")
-	w.Writeln("    On E: Exception do")
-	w.Writeln("      writeln ('Fatal error: ', E.Message);")
-	w.Writeln("  end;")
-	w.Writeln("  Terminate")
-	w.Writeln("end;")
-
-	w.Writeln("")
-
-	w.Writeln("constructor T%s_Example.Create(TheOwner: TComponent);", NameSpace)
-	w.Writeln("begin")
-	w.Writeln("  inherited Create(TheOwner);")
-	w.Writeln("  StopOnException:=True;")
-	w.Writeln("end;")
-
-	w.Writeln("")
-
-	w.Writeln("destructor T%s_Example.Destroy;", NameSpace)
-	w.Writeln("begin")
-	w.Writeln("  inherited Destroy;")
-	w.Writeln("end;")
-	w.Writeln("")
-
-	w.Writeln("")
-	w.Writeln("var")
-	w.Writeln("  Application: T%s_Example;", NameSpace)
-	w.Writeln("begin")
-	w.Writeln("  Application:=T%s_Example.Create(nil);", NameSpace)
-	w.Writeln("  Application.Run;")
-	w.Writeln("  Application.Free;")
-	w.Writeln("end.")
-
-
-	return nil
+func buildDynamicPascalExample(componentdefinition ComponentDefinition, w LanguageWriter, outputFolder string) error {
+	return w.WriteTemplate("pascalexample", NewTemplateData(componentdefinition))
 }
 
-func buildDynamicPascalExampleLPI(w LanguageWriter, NameSpace string, BaseName string, outputFolder string) error {
-	w.Writeln ("<?xml version=\"1.0\" encoding=\"UTF-8\"?>");
-	w.Writeln ("<CONFIG>");
-	w.Writeln ("  <ProjectOptions>");
-	w.Writeln ("    <Version Value=\"%d\"/>", 10);
-	w.Writeln ("    <PathDelim Value=\"\\\"/>");
-	w.Writeln ("    <General>");
-	w.Writeln ("      <Flags>");
-	w.Writeln ("        <MainUnitHasCreateFormStatements Value=\"False\" />");
-	w.Writeln ("        <MainUnitHasTitleStatement Value=\"False\" />");
-	w.Writeln ("        <MainUnitHasScaledStatement Value=\"False\" />");
-	w.Writeln ("      </Flags>");
-	w.Writeln ("      <SessionStorage Value=\"InProjectDir\" />");
-	w.Writeln ("      <MainUnit Value=\"%d\"/>", 0);
-	w.Writeln ("      <Title Value=\"%s_Example\" />", NameSpace);
-	w.Writeln ("      <UseAppBundle Value=\"False\" />");
-	w.Writeln ("      <ResourceType Value=\"res\" />");
-	w.Writeln ("    </General>");
-	w.Writeln ("    <BuildModes Count=\"%d\">", 2);
-	w.Writeln ("      <Item1 Name=\"Release\" Default=\"True\"/>");
-	w.Writeln ("      <Item2 Name=\"Debug\">");
-	w.Writeln ("        <CompilerOptions>");
-	w.Writeln ("          <Version Value=\"11\" />");
-	w.Writeln ("          <PathDelim Value=\"\\\"/>");
-	w.Writeln ("          <Target>");
-	w.Writeln ("            <Filename Value=\"bin\\$(TargetCPU)-$(TargetOS)\\Release\\%s_Example\"/>", NameSpace);
-	w.Writeln ("          </Target>");
-	w.Writeln ("          <SearchPaths>");
-	w.Writeln ("            <IncludeFiles Value=\"$(ProjOutDir)\"/>");
-	w.Writeln ("            <OtherUnitFiles Value=\"..\\..\\Bindings\\Pascal\"/>");
-	w.Writeln ("            <UnitOutputDirectory Value=\"lib\\$(TargetCPU)-$(TargetOS)\"/>");
-	w.Writeln ("          </SearchPaths>");
-	w.Writeln ("          <Parsing>");
-	w.Writeln ("            <SyntaxOptions>");
-	w.Writeln ("              <IncludeAssertionCode Value=\"True\"/>");
-	w.Writeln ("            </SyntaxOptions>");
-	w.Writeln ("          </Parsing>");
-	w.Writeln ("          <CodeGeneration>");
-	w.Writeln ("            <RelocatableUnit Value=\"True\" />");
-	w.Writeln ("          </CodeGeneration>");
-	w.Writeln ("          <Linking>");
-	w.Writeln ("            <Debugging>");
-	w.Writeln ("              <UseExternalDbgSyms Value=\"True\"/>");
-	w.Writeln ("            </Debugging>");
-	w.Writeln ("            <Options>");
-	w.Writeln ("              <ExecutableType Value=\"Library\"/>");
-	w.Writeln ("            </Options>");
-	w.Writeln ("          </Linking>");
-	w.Writeln ("        </CompilerOptions>");
-	w.Writeln ("      </Item2>");
-	w.Writeln ("    </BuildModes>");
-	w.Writeln ("    <PublishOptions>");
-	w.Writeln ("      <Version Value=\"%d\"/>", 2);
-	w.Writeln ("    </PublishOptions>");
-
-	w.Writeln ("    <RunParams>");
-	w.Writeln ("      <local>");
-	w.Writeln ("        <FormatVersion Value=\"1\"/>");
-	w.Writeln ("      </local>");
-	w.Writeln ("    </RunParams>");
-
-	w.Writeln ("    <Units Count=\"%d\">", 2);
-	w.Writeln ("      <Unit0>");
-	w.Writeln ("        <Filename Value=\"%s_Example.lpr\"/>", NameSpace);
-	w.Writeln ("        <IsPartOfProject Value=\"True\"/>");
-	w.Writeln ("      </Unit0>");
-	w.Writeln ("      <Unit1>");
-	w.Writeln ("        <Filename Value=\"Unit_%s.pas\"/>", NameSpace);
-	w.Writeln ("        <IsPartOfProject Value=\"True\"/>");
-	w.Writeln ("      </Unit1>");
-	w.Writeln ("    </Units>");
-
-	w.Writeln ("  </ProjectOptions>");
-	w.Writeln ("  <CompilerOptions>");
-	w.Writeln ("    <Version Value=\"%d\"/>", 11);
-	w.Writeln ("    <PathDelim Value=\"\\\"/>");
-	w.Writeln ("    <Target>");
-	w.Writeln ("      <Filename Value=\"bin\\$(TargetCPU)-$(TargetOS)\\Release\\%s_Example\"/>", NameSpace);
-	w.Writeln ("    </Target>");
-	w.Writeln ("    <SearchPaths>");
-	w.Writeln ("      <IncludeFiles Value=\"$(ProjOutDir)\"/>");
-	w.Writeln ("      <OtherUnitFiles Value=\"..\\..\\Bindings\\Pascal\"/>");
-	w.Writeln ("      <UnitOutputDirectory Value=\"lib\\$(TargetCPU)-$(TargetOS)\"/>");
-	w.Writeln ("    </SearchPaths>");
-	w.Writeln ("    <Parsing>");
-	w.Writeln ("      <SyntaxOptions>");
-	w.Writeln ("        <IncludeAssertionCode Value=\"True\"/>");
-	w.Writeln ("      </SyntaxOptions>");
-	w.Writeln ("    </Parsing>");
-	w.Writeln ("    <CodeGeneration>");
-	w.Writeln ("      <RelocatableUnit Value=\"True\"/>");
-	w.Writeln ("    </CodeGeneration>");
-	w.Writeln ("    <Linking>");
-	w.Writeln ("      <Debugging>");
-	w.Writeln ("        <StripSymbols Value=\"True\"/>");
-	w.Writeln ("        <UseExternalDbgSyms Value=\"True\"/>");
-	w.Writeln ("      </Debugging>");
-	w.Writeln ("      <Options>");
-	w.Writeln ("        <ExecutableType Value=\"Library\"/>");
-	w.Writeln ("      </Options>");
-	w.Writeln ("    </Linking>");
-	w.Writeln ("  </CompilerOptions>");
-	w.Writeln ("  <Debugging>");
-	w.Writeln ("    <Exceptions Count=\"%d\">", 3);
-	w.Writeln ("      <Item1>");
-	w.Writeln ("        <Name Value=\"EAbort\"/>");
-	w.Writeln ("      </Item1>");
-	w.Writeln ("      <Item2>");
-	w.Writeln ("        <Name Value=\"ECodetoolError\"/>");
- 	w.Writeln ("      </Item2>");
-	w.Writeln ("      <Item3>");
-	w.Writeln ("        <Name Value=\"EFOpenError\"/>");
-	w.Writeln ("      </Item3>");
-	w.Writeln ("    </Exceptions>");     
-	w.Writeln ("  </Debugging>");
-	w.Writeln ("</CONFIG>");    	
-	w.Writeln ("");
-
-	return nil;
-	
+func buildDynamicPascalExampleLPI(componentdefinition ComponentDefinition, w LanguageWriter, outputFolder string) error {
+	return w.WriteTemplate("pascalexample.lpi", NewTemplateData(componentdefinition))
 }
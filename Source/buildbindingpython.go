@@ -36,43 +36,92 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"log"
+	"os"
 	"path"
+	"regexp"
+	"strings"
 )
 
 // BuildBindingPythonDynamic builds dynamic Python bindings of a library's API in form of dynamically loaded functions
-// handles.
-func BuildBindingPythonDynamic(componentdefinition ComponentDefinition, outputFolder string, outputFolderExample string, indentString string) error {
-	forceRecreation := false
+// handles. If packageName is not empty, the bindings are an installable package of that name.
+func BuildBindingPythonDynamic(componentdefinition ComponentDefinition, outputFolder string, outputFolderExample string, indentString string, packageName string, forceRecreation ForceRecreation) error {
 
 	namespace := componentdefinition.NameSpace
 	libraryname := componentdefinition.LibraryName
+
+	moduleFolder := outputFolder
+	if (packageName != "") {
+		moduleFolder = path.Join(outputFolder, packageName)
+		// The folder of the package data, from which the package loads the library
+		err := os.MkdirAll(path.Join(moduleFolder, "lib"), os.ModePerm)
+		if err != nil {
+			return err;
+		}
+	}
 	
-	DynamicPythonImpl := path.Join(outputFolder, namespace+".py");
+	DynamicPythonImpl := path.Join(moduleFolder, namespace+".py");
 	log.Printf("Creating \"%s\"", DynamicPythonImpl)
 	dynpythonfile, err := CreateLanguageFile (DynamicPythonImpl, indentString)
 	if err != nil {
 		return err;
 	}
 
-	dynpythonfile.WritePythonLicenseHeader(componentdefinition,
+	err = dynpythonfile.WritePythonLicenseHeader(componentdefinition,
 		fmt.Sprintf("This is an autogenerated Python file in order to allow an easy\n use of %s", libraryname),
 		true)
+	if err != nil {
+		return err
+	}
 	
 	err = buildDynamicPythonImplementation(componentdefinition, dynpythonfile)
 	if err != nil {
 		return err;
 	}
+
+	DynamicPythonStub := path.Join(moduleFolder, namespace+".pyi");
+	log.Printf("Creating \"%s\"", DynamicPythonStub)
+	dynpythonstubfile, err := CreateLanguageFile (DynamicPythonStub, indentString)
+	if err != nil {
+		return err;
+	}
+
+	err = dynpythonstubfile.WritePythonLicenseHeader(componentdefinition,
+		fmt.Sprintf("This is an autogenerated Python stub file with the type hints\n of the Python bindings of %s", libraryname),
+		true)
+	if err != nil {
+		return err
+	}
+
+	err = buildDynamicPythonStub(componentdefinition, dynpythonstubfile)
+	if err != nil {
+		return err;
+	}
+
+	if (packageName != "") {
+		err = buildPythonPackage(componentdefinition, outputFolder, packageName, indentString)
+		if err != nil {
+			return err;
+		}
+	}
 	
 	if (len(outputFolderExample) > 0) {
 		DynamicPythonExample := path.Join(outputFolderExample, namespace+"_Example"+".py");
-		if (forceRecreation || !FileExists(DynamicPythonExample)) {
+		if (forceRecreation.Examples || !FileExists(DynamicPythonExample)) {
+			err = BackupFile(DynamicPythonExample)
+			if err != nil {
+				return err;
+			}
 			log.Printf("Creating \"%s\"", DynamicPythonExample)
 			dynpythonexamplefile, err := CreateLanguageFile (DynamicPythonExample, indentString)
-			dynpythonexamplefile.WritePythonLicenseHeader(componentdefinition,
+			err = dynpythonexamplefile.WritePythonLicenseHeader(componentdefinition,
 				fmt.Sprintf("This is an autogenerated Python application that demonstrates the\n usage of the Python bindings of %s", libraryname),
 				true)
-			err = buildDynamiCPythonExample(componentdefinition, dynpythonexamplefile, outputFolder)
+			if err != nil {
+				return err
+			}
+			err = buildDynamiCPythonExample(componentdefinition, dynpythonexamplefile, outputFolder, packageName)
 			if err != nil {
 				return err;
 			}
@@ -94,6 +143,15 @@ func buildDynamicPythonImplementation(componentdefinition ComponentDefinition, w
 	w.Writeln("import ctypes")
 	w.Writeln("import platform")
 	w.Writeln("import enum")
+	if componentdefinition.HasAsyncMethods() {
+		w.Writeln("import asyncio")
+		w.Writeln("import threading")
+	}
+	w.Writeln("")
+	w.Writeln("try:")
+	w.Writeln("  import numpy")
+	w.Writeln("except ImportError:")
+	w.Writeln("  numpy = None")
 	w.Writeln("")
 
 	w.Writeln("'''Definition of domain specific exception")
@@ -215,7 +273,9 @@ func buildDynamicPythonImplementation(componentdefinition ComponentDefinition, w
 	w.Writeln("class %sWrapper:", NameSpace)
 	w.Writeln("")
 
-	w.Writeln("  def __init__(self, libraryName):")
+	w.Writeln("  def __init__(self, libraryName, useNumPy = False):")
+	w.Writeln("    # Output arrays are returned as numpy.ndarray, if useNumPy is set and NumPy is installed, and as lists otherwise")
+	w.Writeln("    self._useNumPy = useNumPy and (numpy is not None)")
 	w.Writeln("    ending = ''")
 	w.Writeln("    if platform.system() == 'Windows':")
 	w.Writeln("      ending = 'dll'")
@@ -250,7 +310,52 @@ func buildDynamicPythonImplementation(componentdefinition ComponentDefinition, w
 	w.Writeln("      if instance._wrapper != self:")
 	w.Writeln("        raise E%sException(%sErrorCodes.INVALIDCAST, 'invalid wrapper call')", NameSpace, NameSpace)
 	w.Writeln("    if errorCode != %sErrorCodes.SUCCESS.value:", NameSpace)
-	w.Writeln("      raise E%sException(errorCode)", NameSpace)
+	errorMethod, hasErrorMethod := componentdefinition.Global.GetErrorMethod()
+	if (hasErrorMethod) {
+		w.Writeln("      raise E%sException(errorCode, self._getLastErrorMessage(instance))", NameSpace)
+		w.Writeln("  ")
+		errorExport := GetCExportName(NameSpace, "Wrapper", errorMethod, true)
+		w.Writeln("  def _getLastErrorMessage(self, instance):")
+		w.Writeln("    # Calls %s directly, as its errors must not be checked with checkError", errorMethod.MethodName)
+		w.Writeln("    instanceHandle = None")
+		w.Writeln("    if instance:")
+		w.Writeln("      instanceHandle = instance._handle")
+		w.Writeln("    nNeededChars = ctypes.c_uint64(0)")
+		w.Writeln("    bHasError = ctypes.c_bool(False)")
+		w.Writeln("    if self.lib.%s(instanceHandle, 0, nNeededChars, None, bHasError) != %sErrorCodes.SUCCESS.value or not bHasError.value:", errorExport, NameSpace)
+		w.Writeln("      return ''")
+		w.Writeln("    nBufferSize = ctypes.c_uint64(nNeededChars.value + 1)")
+		w.Writeln("    pBuffer = (ctypes.c_char * nBufferSize.value)()")
+		w.Writeln("    if self.lib.%s(instanceHandle, nBufferSize, nNeededChars, pBuffer, bHasError) != %sErrorCodes.SUCCESS.value:", errorExport, NameSpace)
+		w.Writeln("      return ''")
+		w.Writeln("    return pBuffer.value.decode()")
+	} else {
+		w.Writeln("      raise E%sException(errorCode)", NameSpace)
+	}
+	w.Writeln("  ")
+	w.Writeln("  def _toCTypesArray(self, values, elementType):")
+	w.Writeln("    # Objects with the buffer protocol and the layout of the element type, e.g. array.array or numpy.ndarray, are passed without copying")
+	w.Writeln("    if isinstance(values, ctypes.Array) and values._type_ is elementType:")
+	w.Writeln("      return values")
+	w.Writeln("    try:")
+	w.Writeln("      view = memoryview(values)")
+	w.Writeln("    except TypeError:")
+	w.Writeln("      return (elementType * len(values))(*values)")
+	w.Writeln("    if view.c_contiguous and (view.format.lstrip('@') == elementType._type_) and (view.itemsize == ctypes.sizeof(elementType)):")
+	w.Writeln("      arrayType = elementType * (view.nbytes // view.itemsize)")
+	w.Writeln("      if view.readonly:")
+	w.Writeln("        return arrayType.from_buffer_copy(view)")
+	w.Writeln("      return arrayType.from_buffer(view)")
+	w.Writeln("    if numpy is not None:")
+	w.Writeln("      converted = numpy.array(values, dtype = elementType).reshape(-1)")
+	w.Writeln("      return (elementType * converted.size).from_buffer(converted)")
+	w.Writeln("    return (elementType * len(values))(*values)")
+	w.Writeln("  ")
+	w.Writeln("  def _fromCTypesArray(self, buffer):")
+	w.Writeln("    # The numpy.ndarray shares the memory of the ctypes array")
+	w.Writeln("    if self._useNumPy:")
+	w.Writeln("      return numpy.ctypeslib.as_array(buffer)")
+	w.Writeln("    return buffer[:]")
 	w.Writeln("  ")
 	
 	for j:=0; j<len(componentdefinition.Global.Methods); j++ {
@@ -259,6 +364,9 @@ func buildDynamicPythonImplementation(componentdefinition ComponentDefinition, w
 		if (err!=nil) {
 			return err
 		}
+		if (method.Async) {
+			writePythonAsyncMethod(componentdefinition, method, w, NameSpace)
+		}
 	}
 
 	w.Writeln("'''Base Class Implementation")
@@ -267,16 +375,38 @@ func buildDynamicPythonImplementation(componentdefinition ComponentDefinition, w
 	w.Writeln("  def __init__(self, handle, wrapper):")
 	w.Writeln("    if not handle or not wrapper:")
 	w.Writeln("      raise E%sException()", NameSpace)
-	w.Writeln("    self._handle = handle")
+	w.Writeln("    self._instanceHandle = handle")
 	w.Writeln("    self._wrapper = wrapper")
 	w.Writeln("  ")
+	w.Writeln("  @property")
+	w.Writeln("  def _handle(self):")
+	w.Writeln("    if self._instanceHandle is None:")
+	w.Writeln("      raise E%sException(%sErrorCodes.INVALIDPARAM, 'the instance of ' + type(self).__name__ + ' has been released')", NameSpace, NameSpace)
+	w.Writeln("    return self._instanceHandle")
+	w.Writeln("  ")
+	w.Writeln("  def close(self):")
+	w.Writeln("    '''Releases the instance. Further calls on the instance raise an exception.")
+	w.Writeln("    '''")
+	w.Writeln("    # __init__ might have failed before the handle was set")
+	w.Writeln("    if getattr(self, '_instanceHandle', None) is not None:")
+	w.Writeln("      try:")
+	w.Writeln("        self._wrapper.%s(self)", componentdefinition.Global.ReleaseMethod)
+	w.Writeln("      finally:")
+	w.Writeln("        self._instanceHandle = None")
+	w.Writeln("  ")
+	w.Writeln("  def __enter__(self):")
+	w.Writeln("    return self")
+	w.Writeln("  ")
+	w.Writeln("  def __exit__(self, exc_type, exc_value, traceback):")
+	w.Writeln("    self.close()")
+	w.Writeln("  ")
 	w.Writeln("  def __del__(self):")
-	w.Writeln("    self._wrapper.%s(self)", componentdefinition.Global.ReleaseMethod)
+	w.Writeln("    self.close()")
 
 	for i:=0; i<len(componentdefinition.Classes); i++ {
 		w.Writeln("")
 		w.Writeln("")
-		err = writeClass(componentdefinition.Classes[i], w, NameSpace)
+		err = writeClass(componentdefinition, componentdefinition.Classes[i], w, NameSpace)
 		if (err!=nil) {
 			return err
 		}
@@ -562,7 +692,7 @@ func generateCTypesParameter(param ComponentDefinitionParam, className string, m
 }
 
 
-func writeClass(class ComponentDefinitionClass, w LanguageWriter, NameSpace string) error {
+func writeClass(componentdefinition ComponentDefinition, class ComponentDefinitionClass, w LanguageWriter, NameSpace string) error {
 	w.Writeln("'''%s Class Implementation",  class.ClassName)
 	w.Writeln("'''")
 	
@@ -572,6 +702,7 @@ func writeClass(class ComponentDefinitionClass, w LanguageWriter, NameSpace stri
 	}
 
 	w.Writeln("class %s%s(%s):", NameSpace, class.ClassName, parentClass)
+	writePythonDocString(w, "  ", class.ClassDescription, nil, nil)
 	w.Writeln("  def __init__(self, handle, wrapper):")
 	w.Writeln("    %sBaseClass.__init__(self, handle, wrapper)", NameSpace)
 	w.Writeln("  ")
@@ -581,6 +712,9 @@ func writeClass(class ComponentDefinitionClass, w LanguageWriter, NameSpace stri
 		if (err != nil) {
 			return err
 		}
+		if (class.Methods[i].Async) {
+			writePythonAsyncMethod(componentdefinition, class.Methods[i], w, NameSpace)
+		}
 	}
 	return nil
 }
@@ -659,7 +793,7 @@ func writeMethod(method ComponentDefinitionMethod, w LanguageWriter, NameSpace s
 				}
 				preCallLines = append(preCallLines, fmt.Sprintf("%s = %s(0)", cParams[0].ParamName, cParams[0].ParamCallType))
 				preCallLines = append(preCallLines, fmt.Sprintf("%s = %s(0)", cParams[1].ParamName, cParams[1].ParamCallType))
-				preCallLines = append(preCallLines, fmt.Sprintf("%s = (%s*0)()", cParams[2].ParamName, cParams[2].ParamCallType))
+				preCallLines = append(preCallLines, fmt.Sprintf("%s = None", cParams[2].ParamName))
 
 				cCheckArguments = cCheckArguments + cParams[0].ParamName + ", " + cParams[1].ParamName + ", " + cParams[2].ParamName
 				checkCallLines = append(checkCallLines, fmt.Sprintf("%s = %s(%s.value)", cParams[0].ParamName, cParams[0].ParamCallType, cParams[1].ParamName))
@@ -667,7 +801,7 @@ func writeMethod(method ComponentDefinitionMethod, w LanguageWriter, NameSpace s
 				doCheckCall = true
 
 				cArguments = cArguments + cParams[0].ParamName + ", " + cParams[1].ParamName + ", " + cParams[2].ParamName
-				retVals = retVals + fmt.Sprintf("[%s[i] for i in range(%s.value)]", cParams[2].ParamName, cParams[1].ParamName)
+				retVals = retVals + fmt.Sprintf("%s._fromCTypesArray(%s)", wrapperReference, cParams[2].ParamName)
 			}
 			case "structarray": {
 				if (retVals != "") {
@@ -675,7 +809,7 @@ func writeMethod(method ComponentDefinitionMethod, w LanguageWriter, NameSpace s
 				}
 				preCallLines = append(preCallLines, fmt.Sprintf("%s = %s(0)", cParams[0].ParamName, cParams[0].ParamCallType))
 				preCallLines = append(preCallLines, fmt.Sprintf("%s = %s(0)", cParams[1].ParamName, cParams[1].ParamCallType))
-				preCallLines = append(preCallLines, fmt.Sprintf("%s = (%s*0)()", cParams[2].ParamName, cParams[2].ParamCallType))
+				preCallLines = append(preCallLines, fmt.Sprintf("%s = None", cParams[2].ParamName))
 
 				cCheckArguments = cCheckArguments + cParams[0].ParamName + ", " + cParams[1].ParamName + ", " + cParams[2].ParamName
 				checkCallLines = append(checkCallLines, fmt.Sprintf("%s = %s(%s.value)", cParams[0].ParamName, cParams[0].ParamCallType, cParams[1].ParamName))
@@ -741,8 +875,8 @@ func writeMethod(method ComponentDefinitionMethod, w LanguageWriter, NameSpace s
 				cCheckArguments = cCheckArguments  + cParams[0].ParamName
 			}
 			case "basicarray": {
-				preCallLines = append(preCallLines, fmt.Sprintf("%s = %s(len(%s))", cParams[0].ParamName, cParams[0].ParamCallType, param.ParamName))
-				preCallLines = append(preCallLines, fmt.Sprintf("%s = (%s*len(%s))(*%s)", cParams[1].ParamName, cParams[1].ParamCallType, param.ParamName, param.ParamName))
+				preCallLines = append(preCallLines, fmt.Sprintf("%s = %s._toCTypesArray(%s, %s)", cParams[1].ParamName, wrapperReference, param.ParamName, cParams[1].ParamCallType))
+				preCallLines = append(preCallLines, fmt.Sprintf("%s = %s(len(%s))", cParams[0].ParamName, cParams[0].ParamCallType, cParams[1].ParamName))
 				pythonInParams = pythonInParams + param.ParamName
 				cArguments = cArguments + cParams[0].ParamName + ", " + cParams[1].ParamName
 				cCheckArguments = cCheckArguments  + cParams[0].ParamName + ", " + cParams[1].ParamName
@@ -779,6 +913,16 @@ func writeMethod(method ComponentDefinitionMethod, w LanguageWriter, NameSpace s
 	exportName := GetCExportName(NameSpace, ClassName, method, isGlobal)
 	
 	w.Writeln ("  def %s(self%s):", method.MethodName, pythonInParams)
+	inParamDescriptions := []string{}
+	outParamDescriptions := []string{}
+	for _, param := range method.Params {
+		if (param.ParamPass == "in") {
+			inParamDescriptions = append(inParamDescriptions, fmt.Sprintf(":param %s: %s", getPythonParameterName(param), param.ParamDescription))
+		} else {
+			outParamDescriptions = append(outParamDescriptions, param.ParamDescription)
+		}
+	}
+	writePythonDocString(w, "    ", method.MethodDescription, inParamDescriptions, outParamDescriptions)
 	w.Writelns("    ", preCallLines)
 	if (doCheckCall) {
 		w.Writeln ("    %s.checkError(%s, %s.lib.%s(%s))", wrapperReference, selfReference, wrapperReference, exportName, cCheckArguments)
@@ -794,32 +938,323 @@ func writeMethod(method ComponentDefinitionMethod, w LanguageWriter, NameSpace s
 	return nil
 }
 
-func buildDynamiCPythonExample(componentdefinition ComponentDefinition, w LanguageWriter, outputFolder string) error {
+// writePythonAsyncMethod writes a coroutine, which runs a method in the default executor of the running event loop.
+// If the method reports its progress, cancelling the coroutine aborts the call through the callback.
+func writePythonAsyncMethod(componentdefinition ComponentDefinition, method ComponentDefinitionMethod, w LanguageWriter, NameSpace string) {
+	cancellationParam, abortIndex, isCancellable := componentdefinition.GetCancellationCallback(method)
+
+	pythonInParams := ""
+	arguments := ""
+	inParamDescriptions := []string{}
+	outParamDescriptions := []string{}
+	for _, param := range method.Params {
+		if (param.ParamPass != "in") {
+			outParamDescriptions = append(outParamDescriptions, param.ParamDescription)
+			continue
+		}
+		pythonInParams = pythonInParams + ", " + getPythonParameterName(param)
+		inParamDescriptions = append(inParamDescriptions, fmt.Sprintf(":param %s: %s", getPythonParameterName(param), param.ParamDescription))
+		if isCancellable && (param.ParamName == cancellationParam.ParamName) {
+			arguments = arguments + fmt.Sprintf(", %s%s(cancellableCallback)", NameSpace, param.ParamClass)
+		} else {
+			arguments = arguments + ", " + getPythonParameterName(param)
+		}
+	}
+
+	w.Writeln("  async def %sAsync(self%s):", method.MethodName, pythonInParams)
+	writePythonDocString(w, "    ", fmt.Sprintf("Calls %s in the default executor of the running event loop", method.MethodName), inParamDescriptions, outParamDescriptions)
+	if (!isCancellable) {
+		w.Writeln("    return await asyncio.get_running_loop().run_in_executor(None, self.%s%s)", method.MethodName, arguments)
+		w.Writeln("  ")
+		return
+	}
+
+	callbackName := getPythonParameterName(cancellationParam)
+	w.Writeln("    cancelled = threading.Event()")
+	w.Writeln("    def cancellableCallback(*args):")
+	w.Writeln("      if %s:", callbackName)
+	w.Writeln("        %s(*args)", callbackName)
+	w.Writeln("      if cancelled.is_set() and args[%d]:", abortIndex)
+	w.Writeln("        args[%d][0] = True", abortIndex)
+	w.Writeln("    future = asyncio.get_running_loop().run_in_executor(None, self.%s%s)", method.MethodName, arguments)
+	w.Writeln("    try:")
+	w.Writeln("      return await asyncio.shield(future)")
+	w.Writeln("    except asyncio.CancelledError:")
+	w.Writeln("      # The call is aborted the next time it reports its progress")
+	w.Writeln("      cancelled.set()")
+	w.Writeln("      await asyncio.gather(future, return_exceptions = True)")
+	w.Writeln("      raise")
+	w.Writeln("  ")
+}
+
+func buildDynamiCPythonExample(componentdefinition ComponentDefinition, w LanguageWriter, outputFolder string, packageName string) error {
+	if (packageName != "") {
+		data := NewTemplateData(componentdefinition)
+		data.PackageName = packageName
+		return w.WriteTemplate("pythonpackageexample", data)
+	}
+	return w.WriteTemplate("pythonexample", NewTemplateData(componentdefinition))
+}
+
+// validateBindingPython checks that the name of the Python package is a valid identifier
+func validateBindingPython(component ComponentDefinition, options GeneratorOptions) error {
+	if (options.Package != "") && !regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$").MatchString(options.Package) {
+		return fmt.Errorf ("invalid Python package name \"%s\"", options.Package)
+	}
+	return nil
+}
+
+// buildPythonPackage writes the files, that make the Python bindings an installable package
+func buildPythonPackage(component ComponentDefinition, outputFolder string, packageName string, indentString string) error {
+	data := NewTemplateData(component)
+	data.PackageName = packageName
+
+	PyProject := path.Join(outputFolder, "pyproject.toml")
+	log.Printf("Creating \"%s\"", PyProject)
+	pyprojectfile, err := CreateLanguageFile(PyProject, "  ")
+	if err != nil {
+		return err
+	}
+	err = pyprojectfile.WriteTemplate("pythonpackage.pyproject", data)
+	if err != nil {
+		return err
+	}
+
+	PackageInit := path.Join(outputFolder, packageName, "__init__.py")
+	log.Printf("Creating \"%s\"", PackageInit)
+	initfile, err := CreateLanguageFile(PackageInit, indentString)
+	if err != nil {
+		return err
+	}
+	err = initfile.WritePythonLicenseHeader(component,
+		fmt.Sprintf("This is the autogenerated Python package of the bindings of %s", component.LibraryName),
+		true)
+	if err != nil {
+		return err
+	}
+	err = initfile.WriteTemplate("pythonpackage.init", data)
+	if err != nil {
+		return err
+	}
+
+	// The marker of PEP 561, that the package contains type hints
+	PackageTyped := path.Join(outputFolder, packageName, "py.typed")
+	log.Printf("Creating \"%s\"", PackageTyped)
+	return ioutil.WriteFile(PackageTyped, []byte{}, 0644)
+}
+
+// getPythonParameterName returns the name of an input parameter of a method in the Python binding
+func getPythonParameterName(param ComponentDefinitionParam) string {
+	switch (param.ParamType) {
+	case "handle":
+		return param.ParamName + "Object"
+	case "functiontype":
+		return param.ParamName + "Func"
+	}
+	return param.ParamName
+}
+
+// writePythonDocString writes the docstring of a class or a method
+func writePythonDocString(w LanguageWriter, indent string, description string, inParamDescriptions []string, outParamDescriptions []string) {
+	escaper := strings.NewReplacer("\\", "\\\\", "'", "\\'")
+	w.Writeln(indent + "'''%s", escaper.Replace(description))
+	if (len(inParamDescriptions) > 0) || (len(outParamDescriptions) > 0) {
+		w.Writeln("")
+	}
+	for _, inParamDescription := range inParamDescriptions {
+		w.Writeln(indent + "%s", escaper.Replace(inParamDescription))
+	}
+	if (len(outParamDescriptions) > 0) {
+		w.Writeln(indent + ":return: %s", escaper.Replace(strings.Join(outParamDescriptions, ", ")))
+	}
+	w.Writeln(indent + "'''")
+}
+
+// getPythonTypeHint returns the type of a parameter in the Python stub
+func getPythonTypeHint(param ComponentDefinitionParam, NameSpace string) (string, error) {
+	switch (param.ParamType) {
+	case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64":
+		return "int", nil
+	case "single", "double":
+		return "float", nil
+	case "bool":
+		return "bool", nil
+	case "string":
+		return "str", nil
+	case "enum", "struct", "handle", "functiontype":
+		return NameSpace + param.ParamClass, nil
+	case "basicarray", "structarray":
+		elementParam := ComponentDefinitionParam{ParamType: param.ParamClass, ParamClass: param.ParamClass}
+		if (param.ParamType == "structarray") {
+			elementParam.ParamType = "struct"
+		}
+		elementType, err := getPythonTypeHint(elementParam, NameSpace)
+		if (err != nil) {
+			return "", err
+		}
+		if (param.ParamType == "basicarray") {
+			if (param.ParamPass == "in") {
+				return "typing.Union[typing.Sequence[" + elementType + "], _Buffer]", nil
+			}
+			return "typing.Union[typing.List[" + elementType + "], _NDArray]", nil
+		}
+		if (param.ParamPass == "in") {
+			return "typing.Sequence[" + elementType + "]", nil
+		}
+		return "typing.List[" + elementType + "]", nil
+	}
+	return "", fmt.Errorf ("invalid parameter type \"%s\" for Python stub", param.ParamType)
+}
+
+// getPythonStubMemberType returns the type of a member of a struct in the Python stub
+func getPythonStubMemberType(member ComponentDefinitionMember, NameSpace string) (string, error) {
+	memberType := "int"
+	switch (member.Type) {
+	case "single", "double":
+		memberType = "float"
+	case "bool":
+		memberType = "bool"
+	}
+	if (member.Rows == 0) {
+		return memberType, nil
+	}
+	// ctypes returns array members as ctypes arrays, not as lists
+	elementType := "ctypes.c_int32"
+	if (member.Type != "enum") {
+		var err error
+		elementType, err = getCTypesParameterTypeName(member.Type, NameSpace, member.Class, true)
+		if (err != nil) {
+			return "", err
+		}
+	}
+	memberType = "ctypes.Array[" + elementType + "]"
+	if (member.Columns > 0) {
+		memberType = "ctypes.Array[" + memberType + "]"
+	}
+	return memberType, nil
+}
+
+func writePythonStubMethod(method ComponentDefinitionMethod, w LanguageWriter, NameSpace string) error {
+	parameters := "self"
+	returnTypes := []string{}
+	for _, param := range method.Params {
+		typeHint, err := getPythonTypeHint(param, NameSpace)
+		if (err != nil) {
+			return err
+		}
+		if (param.ParamPass == "in") {
+			parameters = parameters + ", " + getPythonParameterName(param) + ": " + typeHint
+		} else {
+			returnTypes = append(returnTypes, typeHint)
+		}
+	}
+	returnType := "None"
+	if (len(returnTypes) == 1) {
+		returnType = returnTypes[0]
+	} else if (len(returnTypes) > 1) {
+		returnType = "typing.Tuple[" + strings.Join(returnTypes, ", ") + "]"
+	}
+	w.Writeln("  def %s(%s) -> %s: ...", method.MethodName, parameters, returnType)
+	if (method.Async) {
+		w.Writeln("  async def %sAsync(%s) -> %s: ...", method.MethodName, parameters, returnType)
+	}
+	return nil
+}
+
+func buildDynamicPythonStub(componentdefinition ComponentDefinition, w LanguageWriter) error {
 	NameSpace := componentdefinition.NameSpace
-	BaseName := componentdefinition.BaseName
 
 	w.Writeln("")
-	w.Writeln("import os")
-	w.Writeln("import sys")	
-	w.Writeln("sys.path.append(os.path.join(os.path.realpath(__file__),\"..\", \"..\", \"..\", \"Bindings\", \"Python\"))")
-	w.Writeln("import %s",NameSpace )
-	w.Writeln("")
+	w.Writeln("import ctypes")
+	w.Writeln("import enum")
+	w.Writeln("import typing")
 	w.Writeln("")
-
-	w.Writeln("def main():")
-	w.Writeln("  libpath = '' # TODO add the location of the shared library binary here")
-	w.Writeln("  wrapper = %s.%sWrapper(os.path.join(libpath, \"%s\"))", NameSpace, NameSpace, BaseName)
-	w.Writeln("  ")
-	w.Writeln("  major, minor, micro = wrapper.GetLibraryVersion()")
-	w.Writeln("  print(\"%s version: {:d}.{:d}.{:d}\".format(major, minor, micro))", NameSpace)
+	w.Writeln("# Objects with the buffer protocol, e.g. array.array or numpy.ndarray")
+	w.Writeln("_Buffer = typing.Any")
+	w.Writeln("# numpy.ndarray, which is returned if the wrapper uses NumPy")
+	w.Writeln("_NDArray = typing.Any")
 	w.Writeln("")
+	w.Writeln("class E%sException(Exception):", NameSpace)
+	w.Writeln("  def __init__(self, code: int, message: str = ...) -> None: ...")
 	w.Writeln("")
+	w.Writeln("class %sErrorCodes(enum.IntEnum):", NameSpace)
+	w.Writeln("  SUCCESS = 0")
+	for _, merror := range componentdefinition.Errors.Errors {
+		w.Writeln("  %s = %d", merror.Name, merror.Code)
+	}
+
+	if (len(componentdefinition.Enums) > 0) {
+		w.Writeln("")
+		w.Writeln("class CTypesEnum(enum.IntEnum):")
+		w.Writeln("  @staticmethod")
+		w.Writeln("  def from_param(obj: typing.Any) -> int: ...")
+		for _, enum := range componentdefinition.Enums {
+			w.Writeln("")
+			w.Writeln("class %s%s(CTypesEnum):", NameSpace, enum.Name)
+			for _, option := range enum.Options {
+				w.Writeln("  %s = %d", option.Name, option.Value)
+			}
+		}
+	}
 
-	w.Writeln("if __name__ == \"__main__\":")
-	w.Writeln("  try:")
-	w.Writeln("    main()")
-	w.Writeln("  except %s.E%sException as e:", NameSpace, NameSpace)
-	w.Writeln("    print(e)")
+	for _, structinfo := range componentdefinition.Structs {
+		w.Writeln("")
+		w.Writeln("class %s%s(ctypes.Structure):", NameSpace, structinfo.Name)
+		initParameters := "self"
+		for _, member := range structinfo.Members {
+			memberType, err := getPythonStubMemberType(member, NameSpace)
+			if (err != nil) {
+				return err
+			}
+			w.Writeln("  %s: %s", member.Name, memberType)
+			initParameters = initParameters + fmt.Sprintf(", %s: %s = ...", member.Name, memberType)
+		}
+		w.Writeln("  def __init__(%s) -> None: ...", initParameters)
+	}
 
+	for _, funcinfo := range componentdefinition.Functions {
+		w.Writeln("")
+		w.Writeln("class %s%s(ctypes._CFuncPtr):", NameSpace, funcinfo.FunctionName)
+		w.Writeln("  def __init__(self, callback: typing.Callable[..., None]) -> None: ...")
+	}
+
+	w.Writeln("")
+	w.Writeln("class %sWrapper:", NameSpace)
+	w.Writeln("  lib: ctypes.CDLL")
+	w.Writeln("  def __init__(self, libraryName: str, useNumPy: bool = ...) -> None: ...")
+	w.Writeln("  def checkError(self, instance: typing.Optional[%sBaseClass], errorCode: int) -> None: ...", NameSpace)
+	for _, method := range componentdefinition.Global.Methods {
+		err := writePythonStubMethod(method, w, NameSpace)
+		if (err != nil) {
+			return err
+		}
+	}
+
+	w.Writeln("")
+	w.Writeln("_Self = typing.TypeVar('_Self', bound = '%sBaseClass')", NameSpace)
+	w.Writeln("")
+	w.Writeln("class %sBaseClass:", NameSpace)
+	w.Writeln("  def __init__(self, handle: ctypes.c_void_p, wrapper: %sWrapper) -> None: ...", NameSpace)
+	w.Writeln("  def close(self) -> None: ...")
+	w.Writeln("  def __enter__(self: _Self) -> _Self: ...")
+	w.Writeln("  def __exit__(self, exc_type: typing.Any, exc_value: typing.Any, traceback: typing.Any) -> None: ...")
+
+	for _, class := range componentdefinition.Classes {
+		parentClass := fmt.Sprintf("%sBaseClass", NameSpace)
+		if (class.ParentClass != "") {
+			parentClass = fmt.Sprintf("%s%s", NameSpace, class.ParentClass)
+		}
+		w.Writeln("")
+		w.Writeln("class %s%s(%s):", NameSpace, class.ClassName, parentClass)
+		if (len(class.Methods) == 0) {
+			w.Writeln("  ...")
+		}
+		for _, method := range class.Methods {
+			err := writePythonStubMethod(method, w, NameSpace)
+			if (err != nil) {
+				return err
+			}
+		}
+	}
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,1038 @@
+/*++
+
+Copyright (C) 2018 Autodesk Inc. (Original Author)
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+--*/
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+// buildbindingrust.go
+// functions to generate a Rust crate of a library's API, that loads the library dynamically.
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// BuildBindingRust builds a Rust crate of a library's API, that loads the library dynamically
+func BuildBindingRust(component ComponentDefinition, outputFolder string, indentString string) error {
+	libraryname := component.LibraryName;
+	baseName := component.BaseName;
+
+	CargoName := path.Join(outputFolder, "Cargo.toml");
+	log.Printf ("Creating \"%s\"", CargoName);
+	cargofile, err := os.Create(CargoName);
+	if (err != nil) {
+		return err;
+	}
+	defer cargofile.Close();
+
+	fmt.Fprintf (cargofile, "[package]\n");
+	fmt.Fprintf (cargofile, "name = \"%s\"\n", getRustCrateName (component));
+	fmt.Fprintf (cargofile, "version = \"%s\"\n", component.Version);
+	fmt.Fprintf (cargofile, "description = \"Rust binding of %s\"\n", libraryname);
+	fmt.Fprintf (cargofile, "edition = \"2018\"\n");
+	fmt.Fprintf (cargofile, "\n");
+	fmt.Fprintf (cargofile, "[lib]\n");
+	fmt.Fprintf (cargofile, "path = \"%s.rs\"\n", baseName);
+	fmt.Fprintf (cargofile, "\n");
+	fmt.Fprintf (cargofile, "[dependencies]\n");
+
+	RustFileName := path.Join(outputFolder, baseName + ".rs");
+	log.Printf ("Creating \"%s\"", RustFileName);
+	rustfile, err := CreateLanguageFile (RustFileName, indentString);
+	if (err != nil) {
+		return err;
+	}
+
+	err = rustfile.WriteCLicenseHeader(component,
+		fmt.Sprintf ("This is an autogenerated Rust file in order to allow an easy\n use of %s", libraryname),
+		true)
+	if err != nil {
+		return err
+	}
+
+	return buildRustBinding (component, rustfile);
+}
+
+// validateBindingRust checks that the identifiers of the Rust binding are unique within its crate
+func validateBindingRust (component ComponentDefinition, options GeneratorOptions) (error) {
+	identifiers := make (map[string]string);
+	for _, identifier := range []string {"Self", "Error", "Wrapper", "Handle", "BaseClass", "Library", "FunctionTable",
+		"Result", "Option", "String", "Vec", "Box", "Rc", "CStr", "CString", "Deref", "Drop", "Some", "None", "Ok", "Err"} {
+		identifiers[identifier] = "the Rust binding";
+	}
+
+	add := func (identifier string, source string) (error) {
+		previous, ok := identifiers[identifier];
+		if (ok) {
+			return fmt.Errorf ("the Rust identifier \"%s\" of %s collides with %s", identifier, source, previous);
+		}
+		identifiers[identifier] = source;
+		return nil;
+	}
+
+	for _, enum := range component.Enums {
+		err := add (enum.Name, "enum " + enum.Name);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, structinfo := range component.Structs {
+		err := add (structinfo.Name, "struct " + structinfo.Name);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, functiontype := range component.Functions {
+		err := add (functiontype.FunctionName, "functiontype " + functiontype.FunctionName);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, class := range component.Classes {
+		err := add (class.ClassName, "class " + class.ClassName);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, errorcode := range component.Errors.Errors {
+		if (errorcode.Name == "Unknown") || (errorcode.Name == "Binding") || (errorcode.Name == "WithMessage") {
+			return fmt.Errorf ("the Rust variant of error \"%s\" collides with the Rust binding", errorcode.Name);
+		}
+	}
+
+	// The methods must be unique in snake case and must not collide with the constructors and accessors of the binding
+	checkMethods := func (methods []ComponentDefinitionMethod, ClassName string, reserved []string) (error) {
+		names := make (map[string]string);
+		for _, name := range reserved {
+			names[name] = "the Rust binding";
+		}
+		for _, method := range methods {
+			methodName := getRustIdentifier (method.MethodName);
+			previous, ok := names[methodName];
+			if (ok) {
+				return fmt.Errorf ("the Rust method \"%s\" of %s.%s collides with %s", methodName, ClassName, method.MethodName, previous);
+			}
+			names[methodName] = ClassName + "." + method.MethodName;
+		}
+		return nil;
+	}
+	for _, class := range component.Classes {
+		err := checkMethods (class.Methods, class.ClassName, []string {"new", "handle", "library"});
+		if (err != nil) {
+			return err;
+		}
+	}
+	err := checkMethods (component.Global.Methods, "Wrapper", []string {"load", "library"});
+	if (err != nil) {
+		return err;
+	}
+
+	return nil;
+}
+
+// getRustCrateName returns the name of the crate of the Rust binding
+func getRustCrateName (component ComponentDefinition) (string) {
+	return strings.ToLower (strings.Replace (component.BaseName, ".", "_", -1));
+}
+
+// rustKeywords are the keywords of Rust, that can not be used as identifiers
+var rustKeywords = map[string]bool {
+	"as": true, "break": true, "const": true, "continue": true, "crate": true, "else": true, "enum": true, "extern": true,
+	"false": true, "fn": true, "for": true, "if": true, "impl": true, "in": true, "let": true, "loop": true, "match": true,
+	"mod": true, "move": true, "mut": true, "pub": true, "ref": true, "return": true, "self": true, "Self": true,
+	"static": true, "struct": true, "super": true, "trait": true, "true": true, "type": true, "unsafe": true, "use": true,
+	"where": true, "while": true, "async": true, "await": true, "dyn": true, "abstract": true, "become": true, "box": true,
+	"do": true, "final": true, "macro": true, "override": true, "priv": true, "typeof": true, "unsized": true,
+	"virtual": true, "yield": true, "try": true,
+}
+
+// getRustIdentifier turns a name of the IDL into a snake case Rust identifier, e.g. "GetNameA" into "get_name_a"
+func getRustIdentifier (name string) (string) {
+	runes := []rune (name);
+	identifier := "";
+	for i, r := range runes {
+		if (i > 0) && unicode.IsUpper (r) {
+			previous := runes[i - 1];
+			nextIsLower := (i + 1 < len (runes)) && unicode.IsLower (runes[i + 1]);
+			if (unicode.IsLower (previous) || unicode.IsDigit (previous) || (unicode.IsUpper (previous) && nextIsLower)) {
+				identifier = identifier + "_";
+			}
+		}
+		identifier = identifier + string (unicode.ToLower (r));
+	}
+	return getRustSafeName (identifier);
+}
+
+// getRustSafeName appends an underscore to names that are Rust keywords
+func getRustSafeName (name string) (string) {
+	if (rustKeywords[name]) {
+		return name + "_";
+	}
+	return name;
+}
+
+// getRustBasicType returns the Rust type of a scalar type of the IDL
+func getRustBasicType (paramType string) (string, error) {
+	switch (paramType) {
+		case "uint8":
+			return "u8", nil;
+		case "uint16":
+			return "u16", nil;
+		case "uint32":
+			return "u32", nil;
+		case "uint64":
+			return "u64", nil;
+		case "int8":
+			return "i8", nil;
+		case "int16":
+			return "i16", nil;
+		case "int32":
+			return "i32", nil;
+		case "int64":
+			return "i64", nil;
+		case "bool":
+			return "bool", nil;
+		case "single":
+			return "f32", nil;
+		case "double":
+			return "f64", nil;
+	}
+	return "", fmt.Errorf ("invalid basic type \"%s\"", paramType);
+}
+
+// getRustZeroValue returns the initial value of a variable of a scalar type of the IDL
+func getRustZeroValue (paramType string) (string) {
+	switch (paramType) {
+		case "bool":
+			return "false";
+		case "single", "double":
+			return "0.0";
+	}
+	return "0";
+}
+
+// getRustMemberType returns the Rust type of a member of a struct
+func getRustMemberType (member ComponentDefinitionMember) (string, error) {
+	memberType := "";
+	if (member.Type == "enum") {
+		memberType = member.Class;
+	} else {
+		basicType, err := getRustBasicType (member.Type);
+		if (err != nil) {
+			return "", err;
+		}
+		memberType = basicType;
+	}
+
+	if (member.Rows > 0) {
+		memberType = fmt.Sprintf ("[%s; %d]", memberType, member.Rows);
+		if (member.Columns > 0) {
+			memberType = fmt.Sprintf ("[%s; %d]", memberType, member.Columns);
+		}
+	}
+	return memberType, nil;
+}
+
+// getRustCallbackParameters returns the parameters of the C function pointer of a function type
+func getRustCallbackParameters (functiontype ComponentDefinitionFunctionType) (string, string, error) {
+	parameters := "";
+	comments := "";
+	for _, param := range functiontype.Params {
+		paramType := "";
+		switch (param.ParamType) {
+			case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "bool", "single", "double":
+				basicType, err := getRustBasicType (param.ParamType);
+				if (err != nil) {
+					return "", "", err;
+				}
+				paramType = basicType;
+			case "enum":
+				paramType = param.ParamClass;
+			case "string":
+				paramType = "*const c_char";
+				if (param.ParamPass != "in") {
+					return "", "", fmt.Errorf ("parameter type \"%s\" of %s (%s) is only supported as input by Rust callbacks", param.ParamType, functiontype.FunctionName, param.ParamName);
+				}
+			case "handle":
+				paramType = "Handle";
+			default:
+				return "", "", fmt.Errorf ("parameter type \"%s\" of %s (%s) is not supported by Rust callbacks", param.ParamType, functiontype.FunctionName, param.ParamName);
+		}
+		if (param.ParamPass != "in") {
+			paramType = "*mut " + paramType;
+		}
+
+		if (parameters != "") {
+			parameters = parameters + ", ";
+		}
+		parameters = parameters + getRustIdentifier (param.ParamName) + ": " + paramType;
+		comments = comments + fmt.Sprintf ("/// * `%s` - %s\n", getRustIdentifier (param.ParamName), param.ParamDescription);
+	}
+	return parameters, comments, nil;
+}
+
+// writeRustDocComment writes a doc comment with the description of the IDL
+func writeRustDocComment (w LanguageWriter, indent string, description string, comments string) {
+	if (description != "") {
+		for _, line := range strings.Split (description, "\n") {
+			w.Writeln (indent + "/// %s", strings.TrimSpace (line));
+		}
+	}
+	if (comments != "") {
+		if (description != "") {
+			w.Writeln (indent + "///");
+		}
+		for _, line := range strings.Split (strings.TrimSuffix (comments, "\n"), "\n") {
+			w.Writeln (indent + "%s", line);
+		}
+	}
+}
+
+func buildRustBinding (component ComponentDefinition, w LanguageWriter) (error) {
+	NameSpace := component.NameSpace;
+	releaseExport := GetCExportName (NameSpace, "", ComponentDefinitionMethod {MethodName: component.Global.ReleaseMethod}, true);
+
+	w.Writeln ("");
+	w.Writeln ("//! Rust binding of %s.", component.LibraryName);
+	w.Writeln ("//!");
+	w.Writeln ("//! Load the library with `Wrapper::load` and create its classes with the methods of the `Wrapper`.");
+	w.Writeln ("//! The instances of the classes are released when they are dropped.");
+	w.Writeln ("");
+	w.Writeln ("#![allow(dead_code)]");
+	w.Writeln ("#![allow(non_camel_case_types)]");
+	w.Writeln ("#![allow(non_upper_case_globals)]");
+	w.Writeln ("");
+	w.Writeln ("use std::ffi::CString;");
+	w.Writeln ("use std::fmt;");
+	w.Writeln ("use std::os::raw::{c_char, c_void};");
+	w.Writeln ("use std::rc::Rc;");
+	w.Writeln ("");
+	w.Writeln ("/// The major version of %s, that this binding was generated for.", component.LibraryName);
+	w.Writeln ("pub const VERSION_MAJOR: u32 = %d;", majorVersion (component.Version));
+	w.Writeln ("/// The minor version of %s, that this binding was generated for.", component.LibraryName);
+	w.Writeln ("pub const VERSION_MINOR: u32 = %d;", minorVersion (component.Version));
+	w.Writeln ("/// The micro version of %s, that this binding was generated for.", component.LibraryName);
+	w.Writeln ("pub const VERSION_MICRO: u32 = %d;", microVersion (component.Version));
+	w.Writeln ("");
+
+	err := writeRustErrors (component, w);
+	if (err != nil) {
+		return err;
+	}
+	_, hasErrorMethod := component.Global.GetErrorMethod ();
+	err = writeRustTypes (component, w);
+	if (err != nil) {
+		return err;
+	}
+	err = writeRustLibrary (component, w);
+	if (err != nil) {
+		return err;
+	}
+
+	w.Writeln ("/*************************************************************************************************************************");
+	w.Writeln (" Class definition Wrapper");
+	w.Writeln ("**************************************************************************************************************************/");
+	w.Writeln ("");
+	w.Writeln ("/// Wrapper gives access to the global functions of %s.", component.LibraryName);
+	w.Writeln ("pub struct Wrapper {");
+	w.Writeln ("  library: Rc<Library>,");
+	w.Writeln ("}");
+	w.Writeln ("");
+	w.Writeln ("impl Wrapper {");
+	w.Writeln ("  /// Loads %s from a shared library.", component.LibraryName);
+	w.Writeln ("  pub fn load(file_name: &str) -> Result<Wrapper, Error> {");
+	w.Writeln ("    Ok(Wrapper { library: Rc::new(Library::load(file_name)?) })");
+	w.Writeln ("  }");
+	w.Writeln ("");
+	w.Writeln ("  fn library(&self) -> &Rc<Library> {");
+	w.Writeln ("    &self.library");
+	w.Writeln ("  }");
+	for _, method := range component.Global.Methods {
+		if (method.MethodName == component.Global.ReleaseMethod) {
+			continue;
+		}
+		err = writeRustMethod (method, w, NameSpace, "Wrapper", true, hasErrorMethod);
+		if (err != nil) {
+			return err;
+		}
+	}
+	w.Writeln ("}");
+
+	for _, class := range component.Classes {
+		w.Writeln ("");
+		w.Writeln ("/*************************************************************************************************************************");
+		w.Writeln (" Class definition %s", class.ClassName);
+		w.Writeln ("**************************************************************************************************************************/");
+		w.Writeln ("");
+		writeRustDocComment (w, "", class.ClassDescription, "");
+		w.Writeln ("pub struct %s {", class.ClassName);
+		if (class.ParentClass == "") {
+			w.Writeln ("  handle: Handle,");
+			w.Writeln ("  library: Rc<Library>,");
+		} else {
+			w.Writeln ("  parent: %s,", class.ParentClass);
+		}
+		w.Writeln ("}");
+		w.Writeln ("");
+		w.Writeln ("impl BaseClass for %s {", class.ClassName);
+		w.Writeln ("  fn handle(&self) -> Handle {");
+		if (class.ParentClass == "") {
+			w.Writeln ("    self.handle");
+		} else {
+			w.Writeln ("    self.parent.handle()");
+		}
+		w.Writeln ("  }");
+		w.Writeln ("}");
+		w.Writeln ("");
+
+		if (class.ParentClass == "") {
+			w.Writeln ("impl Drop for %s {", class.ClassName);
+			w.Writeln ("  fn drop(&mut self) {");
+			w.Writeln ("    if !self.handle.is_null() {");
+			w.Writeln ("      unsafe {");
+			w.Writeln ("        (self.library.table.%s)(self.handle);", releaseExport);
+			w.Writeln ("      }");
+			w.Writeln ("    }");
+			w.Writeln ("  }");
+			w.Writeln ("}");
+		} else {
+			w.Writeln ("impl std::ops::Deref for %s {", class.ClassName);
+			w.Writeln ("  type Target = %s;", class.ParentClass);
+			w.Writeln ("");
+			w.Writeln ("  fn deref(&self) -> &%s {", class.ParentClass);
+			w.Writeln ("    &self.parent");
+			w.Writeln ("  }");
+			w.Writeln ("}");
+		}
+		w.Writeln ("");
+
+		w.Writeln ("impl %s {", class.ClassName);
+		w.Writeln ("  fn new(handle: Handle, library: Rc<Library>) -> %s {", class.ClassName);
+		if (class.ParentClass == "") {
+			w.Writeln ("    %s { handle, library }", class.ClassName);
+		} else {
+			w.Writeln ("    %s { parent: %s::new(handle, library) }", class.ClassName, class.ParentClass);
+		}
+		w.Writeln ("  }");
+		if (class.ParentClass == "") {
+			w.Writeln ("");
+			w.Writeln ("  fn library(&self) -> &Rc<Library> {");
+			w.Writeln ("    &self.library");
+			w.Writeln ("  }");
+		}
+		for _, method := range class.Methods {
+			err = writeRustMethod (method, w, NameSpace, class.ClassName, false, hasErrorMethod);
+			if (err != nil) {
+				return err;
+			}
+		}
+		w.Writeln ("}");
+	}
+
+	return nil;
+}
+
+// writeRustErrors writes the error enum with the errors of the component
+func writeRustErrors (component ComponentDefinition, w LanguageWriter) (error) {
+	errorMethod, hasErrorMethod := component.Global.GetErrorMethod ();
+	w.Writeln ("/*************************************************************************************************************************");
+	w.Writeln (" Declaration of errors");
+	w.Writeln ("**************************************************************************************************************************/");
+	w.Writeln ("");
+	w.Writeln ("/// Error is an error that %s or the binding returned.", component.LibraryName);
+	w.Writeln ("#[derive(Debug, Clone, PartialEq, Eq)]");
+	w.Writeln ("pub enum Error {");
+	for _, errorcode := range component.Errors.Errors {
+		w.Writeln ("  /// %s", errorcode.Description);
+		w.Writeln ("  %s,", errorcode.Name);
+	}
+	w.Writeln ("  /// an error code that %s does not declare", component.LibraryName);
+	w.Writeln ("  Unknown(i32),");
+	w.Writeln ("  /// an error of the binding, e.g. if the library could not be loaded");
+	w.Writeln ("  Binding(String),");
+	if (hasErrorMethod) {
+		w.Writeln ("  /// an error with the error message that %s provides", component.LibraryName);
+		w.Writeln ("  WithMessage(Box<Error>, String),");
+	}
+	w.Writeln ("}");
+	w.Writeln ("");
+	w.Writeln ("impl Error {");
+	w.Writeln ("  /// Returns the error of an error code of %s.", component.LibraryName);
+	w.Writeln ("  pub fn from_code(code: i32) -> Error {");
+	w.Writeln ("    match code {");
+	for _, errorcode := range component.Errors.Errors {
+		w.Writeln ("      %d => Error::%s,", errorcode.Code, errorcode.Name);
+	}
+	w.Writeln ("      _ => Error::Unknown(code),");
+	w.Writeln ("    }");
+	w.Writeln ("  }");
+	w.Writeln ("");
+	w.Writeln ("  /// Returns the error code, or 0 for an error of the binding.");
+	w.Writeln ("  pub fn code(&self) -> i32 {");
+	w.Writeln ("    match self {");
+	for _, errorcode := range component.Errors.Errors {
+		w.Writeln ("      Error::%s => %d,", errorcode.Name, errorcode.Code);
+	}
+	w.Writeln ("      Error::Unknown(code) => *code,");
+	w.Writeln ("      Error::Binding(_) => 0,");
+	if (hasErrorMethod) {
+		w.Writeln ("      Error::WithMessage(error, _) => error.code(),");
+	}
+	w.Writeln ("    }");
+	w.Writeln ("  }");
+	w.Writeln ("");
+	w.Writeln ("  /// Returns the description of the error.");
+	w.Writeln ("  pub fn description(&self) -> String {");
+	w.Writeln ("    match self {");
+	for _, errorcode := range component.Errors.Errors {
+		w.Writeln ("      Error::%s => String::from(\"%s\"),", errorcode.Name, errorcode.Description);
+	}
+	w.Writeln ("      Error::Unknown(code) => format!(\"unknown error {}\", code),");
+	w.Writeln ("      Error::Binding(message) => message.clone(),");
+	if (hasErrorMethod) {
+		w.Writeln ("      Error::WithMessage(error, message) => format!(\"{}: {}\", error.description(), message),");
+	}
+	w.Writeln ("    }");
+	w.Writeln ("  }");
+	if (hasErrorMethod) {
+		w.Writeln ("");
+		w.Writeln ("  /// Returns the error without the error message of %s.", component.LibraryName);
+		w.Writeln ("  pub fn kind(&self) -> &Error {");
+		w.Writeln ("    match self {");
+		w.Writeln ("      Error::WithMessage(error, _) => error,");
+		w.Writeln ("      _ => self,");
+		w.Writeln ("    }");
+		w.Writeln ("  }");
+		w.Writeln ("");
+		w.Writeln ("  /// Returns the error message that %s provides, if any.", component.LibraryName);
+		w.Writeln ("  pub fn message(&self) -> Option<&str> {");
+		w.Writeln ("    match self {");
+		w.Writeln ("      Error::WithMessage(_, message) => Some(message),");
+		w.Writeln ("      _ => None,");
+		w.Writeln ("    }");
+		w.Writeln ("  }");
+	}
+	w.Writeln ("}");
+	w.Writeln ("");
+	w.Writeln ("impl fmt::Display for Error {");
+	w.Writeln ("  fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {");
+	w.Writeln ("    write!(f, \"%sException {}: {}\", self.code(), self.description())", component.NameSpace);
+	w.Writeln ("  }");
+	w.Writeln ("}");
+	w.Writeln ("");
+	w.Writeln ("impl std::error::Error for Error {}");
+	w.Writeln ("");
+	w.Writeln ("fn check(code: i32) -> Result<(), Error> {");
+	w.Writeln ("  if code == 0 {");
+	w.Writeln ("    Ok(())");
+	w.Writeln ("  } else {");
+	w.Writeln ("    Err(Error::from_code(code))");
+	w.Writeln ("  }");
+	w.Writeln ("}");
+	w.Writeln ("");
+	if (hasErrorMethod) {
+		errorExport := GetCExportName (component.NameSpace, "", errorMethod, true);
+		w.Writeln ("fn check_instance(library: &Library, instance: Handle, code: i32) -> Result<(), Error> {");
+		w.Writeln ("  if code == 0 {");
+		w.Writeln ("    return Ok(());");
+		w.Writeln ("  }");
+		w.Writeln ("  let error = Error::from_code(code);");
+		w.Writeln ("  match error_message(library, instance) {");
+		w.Writeln ("    Some(message) => Err(Error::WithMessage(Box::new(error), message)),");
+		w.Writeln ("    None => Err(error),");
+		w.Writeln ("  }");
+		w.Writeln ("}");
+		w.Writeln ("");
+		w.Writeln ("fn error_message(library: &Library, instance: Handle) -> Option<String> {");
+		w.Writeln ("  let mut needed_chars: u32 = 0;");
+		w.Writeln ("  let mut has_error: bool = false;");
+		w.Writeln ("  if unsafe { (library.table.%s)(instance, 0, &mut needed_chars, std::ptr::null_mut(), &mut has_error) } != 0 || !has_error {", errorExport);
+		w.Writeln ("    return None;");
+		w.Writeln ("  }");
+		w.Writeln ("  let mut buffer = vec![0u8; needed_chars as usize + 1];");
+		w.Writeln ("  if unsafe { (library.table.%s)(instance, buffer.len() as u32, &mut needed_chars, buffer.as_mut_ptr() as *mut c_char, &mut has_error) } != 0 {", errorExport);
+		w.Writeln ("    return None;");
+		w.Writeln ("  }");
+		w.Writeln ("  Some(string_from_buffer(&buffer))");
+		w.Writeln ("}");
+		w.Writeln ("");
+	}
+
+	return nil;
+}
+
+// writeRustTypes writes the enums, structs and function types of the component
+func writeRustTypes (component ComponentDefinition, w LanguageWriter) (error) {
+	w.Writeln ("/*************************************************************************************************************************");
+	w.Writeln (" Declaration of types");
+	w.Writeln ("**************************************************************************************************************************/");
+	w.Writeln ("");
+	w.Writeln ("/// Handle is the handle of an instance of a class of %s.", component.LibraryName);
+	w.Writeln ("pub type Handle = *mut c_void;");
+	w.Writeln ("");
+	w.Writeln ("/// BaseClass is implemented by all classes of %s.", component.LibraryName);
+	w.Writeln ("pub trait BaseClass {");
+	w.Writeln ("  /// Returns the handle of the instance.");
+	w.Writeln ("  fn handle(&self) -> Handle;");
+	w.Writeln ("}");
+	w.Writeln ("");
+
+	// Enums are newtypes, so that unknown values that the library returns remain valid
+	for _, enum := range component.Enums {
+		w.Writeln ("/// %s is the enum e%s%s of %s.", enum.Name, component.NameSpace, enum.Name, component.LibraryName);
+		w.Writeln ("#[repr(transparent)]");
+		w.Writeln ("#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash)]");
+		w.Writeln ("pub struct %s(pub i32);", enum.Name);
+		w.Writeln ("");
+		w.Writeln ("impl %s {", enum.Name);
+		for _, option := range enum.Options {
+			w.Writeln ("  pub const %s: %s = %s(%d);", getRustSafeName (option.Name), enum.Name, enum.Name, option.Value);
+		}
+		w.Writeln ("}");
+		w.Writeln ("");
+	}
+
+	for _, structinfo := range component.Structs {
+		w.Writeln ("/// %s is the struct s%s%s of %s.", structinfo.Name, component.NameSpace, structinfo.Name, component.LibraryName);
+		w.Writeln ("#[repr(C, packed)]");
+		w.Writeln ("#[derive(Debug, Clone, Copy, PartialEq)]");
+		w.Writeln ("pub struct %s {", structinfo.Name);
+		for _, member := range structinfo.Members {
+			memberType, err := getRustMemberType (member);
+			if (err != nil) {
+				return fmt.Errorf ("invalid member \"%s\" of struct \"%s\": %s", member.Name, structinfo.Name, err.Error ());
+			}
+			w.Writeln ("  pub %s: %s,", getRustIdentifier (member.Name), memberType);
+		}
+		w.Writeln ("}");
+		w.Writeln ("");
+		w.Writeln ("impl Default for %s {", structinfo.Name);
+		w.Writeln ("  fn default() -> %s {", structinfo.Name);
+		w.Writeln ("    // all members are numbers, bools or enums, for which zero is a valid value");
+		w.Writeln ("    unsafe { std::mem::zeroed() }");
+		w.Writeln ("  }");
+		w.Writeln ("}");
+		w.Writeln ("");
+	}
+
+	for _, functiontype := range component.Functions {
+		parameters, comments, err := getRustCallbackParameters (functiontype);
+		if (err != nil) {
+			return err;
+		}
+		writeRustDocComment (w, "", functiontype.FunctionDescription, comments);
+		w.Writeln ("pub type %s = extern \"C\" fn(%s);", functiontype.FunctionName, parameters);
+		w.Writeln ("");
+	}
+
+	return nil;
+}
+
+// getRustCParameters returns the Rust types of the C parameters of a method
+func getRustCParameters (method ComponentDefinitionMethod, ClassName string) ([]string, error) {
+	cParams := []string {};
+	for _, param := range method.Params {
+		baseType := "";
+		switch (param.ParamType) {
+			case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "bool", "single", "double":
+				basicType, err := getRustBasicType (param.ParamType);
+				if (err != nil) {
+					return nil, err;
+				}
+				baseType = basicType;
+			case "basicarray":
+				basicType, err := getRustBasicType (param.ParamClass);
+				if (err != nil) {
+					return nil, err;
+				}
+				baseType = basicType;
+			case "enum", "struct", "structarray":
+				baseType = param.ParamClass;
+			case "string":
+				baseType = "c_char";
+			case "handle":
+				baseType = "Handle";
+			case "functiontype":
+				baseType = fmt.Sprintf ("Option<%s>", param.ParamClass);
+			default:
+				return nil, fmt.Errorf ("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName);
+		}
+
+		switch (param.ParamPass) {
+			case "in":
+				switch (param.ParamType) {
+					case "string", "struct":
+						cParams = append (cParams, "*const " + baseType);
+					case "basicarray", "structarray":
+						cParams = append (cParams, "u64", "*const " + baseType);
+					default:
+						cParams = append (cParams, baseType);
+				}
+			case "out", "return":
+				switch (param.ParamType) {
+					case "string":
+						cParams = append (cParams, "u32", "*mut u32", "*mut c_char");
+					case "basicarray", "structarray":
+						cParams = append (cParams, "u64", "*mut u64", "*mut " + baseType);
+					case "functiontype":
+						return nil, fmt.Errorf ("method parameter type \"%s\" of param pass \"%s\" is not implemented for %s.%s (%s)", param.ParamType, param.ParamPass, ClassName, method.MethodName, param.ParamName);
+					default:
+						cParams = append (cParams, "*mut " + baseType);
+				}
+			default:
+				return nil, fmt.Errorf ("invalid method parameter passing \"%s\" for %s.%s (%s)", param.ParamPass, ClassName, method.MethodName, param.ParamName);
+		}
+	}
+	return cParams, nil;
+}
+
+// writeRustLibrary writes the dynamic loading of the library and its function table
+func writeRustLibrary (component ComponentDefinition, w LanguageWriter) (error) {
+	NameSpace := component.NameSpace;
+
+	type rustExport struct {
+		name string
+		parameters string
+	}
+	exports := []rustExport {};
+	addExports := func (methods []ComponentDefinitionMethod, ClassName string, isGlobal bool) (error) {
+		for _, method := range methods {
+			cParams, err := getRustCParameters (method, ClassName);
+			if (err != nil) {
+				return err;
+			}
+			if (!isGlobal) {
+				cParams = append ([]string {"Handle"}, cParams...);
+			}
+			exports = append (exports, rustExport {GetCExportName (NameSpace, ClassName, method, isGlobal), strings.Join (cParams, ", ")});
+		}
+		return nil;
+	}
+	for _, class := range component.Classes {
+		err := addExports (class.Methods, class.ClassName, false);
+		if (err != nil) {
+			return err;
+		}
+	}
+	err := addExports (component.Global.Methods, "Wrapper", true);
+	if (err != nil) {
+		return err;
+	}
+
+	w.Writeln ("/*************************************************************************************************************************");
+	w.Writeln (" Loading of the library");
+	w.Writeln ("**************************************************************************************************************************/");
+	w.Writeln ("");
+	w.Writeln ("#[cfg(unix)]");
+	w.Writeln ("mod loader {");
+	w.Writeln ("  use std::ffi::CStr;");
+	w.Writeln ("  use std::os::raw::{c_char, c_int, c_void};");
+	w.Writeln ("");
+	w.Writeln ("  #[cfg_attr(target_os = \"linux\", link(name = \"dl\"))]");
+	w.Writeln ("  extern \"C\" {");
+	w.Writeln ("    fn dlopen(file_name: *const c_char, flags: c_int) -> *mut c_void;");
+	w.Writeln ("    fn dlsym(library: *mut c_void, symbol_name: *const c_char) -> *mut c_void;");
+	w.Writeln ("    fn dlclose(library: *mut c_void) -> c_int;");
+	w.Writeln ("  }");
+	w.Writeln ("");
+	w.Writeln ("  const RTLD_NOW: c_int = 2;");
+	w.Writeln ("");
+	w.Writeln ("  pub unsafe fn load_library(file_name: &CStr) -> *mut c_void {");
+	w.Writeln ("    dlopen(file_name.as_ptr(), RTLD_NOW)");
+	w.Writeln ("  }");
+	w.Writeln ("");
+	w.Writeln ("  pub unsafe fn load_symbol(library: *mut c_void, symbol_name: &CStr) -> *mut c_void {");
+	w.Writeln ("    dlsym(library, symbol_name.as_ptr())");
+	w.Writeln ("  }");
+	w.Writeln ("");
+	w.Writeln ("  pub unsafe fn unload_library(library: *mut c_void) {");
+	w.Writeln ("    dlclose(library);");
+	w.Writeln ("  }");
+	w.Writeln ("}");
+	w.Writeln ("");
+	w.Writeln ("#[cfg(windows)]");
+	w.Writeln ("mod loader {");
+	w.Writeln ("  use std::ffi::CStr;");
+	w.Writeln ("  use std::os::raw::{c_char, c_int, c_void};");
+	w.Writeln ("");
+	w.Writeln ("  #[link(name = \"kernel32\")]");
+	w.Writeln ("  extern \"system\" {");
+	w.Writeln ("    fn LoadLibraryA(file_name: *const c_char) -> *mut c_void;");
+	w.Writeln ("    fn GetProcAddress(library: *mut c_void, symbol_name: *const c_char) -> *mut c_void;");
+	w.Writeln ("    fn FreeLibrary(library: *mut c_void) -> c_int;");
+	w.Writeln ("  }");
+	w.Writeln ("");
+	w.Writeln ("  pub unsafe fn load_library(file_name: &CStr) -> *mut c_void {");
+	w.Writeln ("    LoadLibraryA(file_name.as_ptr())");
+	w.Writeln ("  }");
+	w.Writeln ("");
+	w.Writeln ("  pub unsafe fn load_symbol(library: *mut c_void, symbol_name: &CStr) -> *mut c_void {");
+	w.Writeln ("    GetProcAddress(library, symbol_name.as_ptr())");
+	w.Writeln ("  }");
+	w.Writeln ("");
+	w.Writeln ("  pub unsafe fn unload_library(library: *mut c_void) {");
+	w.Writeln ("    FreeLibrary(library);");
+	w.Writeln ("  }");
+	w.Writeln ("}");
+	w.Writeln ("");
+	w.Writeln ("/// FunctionTable holds the exported functions of %s.", component.LibraryName);
+	w.Writeln ("struct FunctionTable {");
+	for _, export := range exports {
+		w.Writeln ("  %s: unsafe extern \"C\" fn(%s) -> i32,", export.name, export.parameters);
+	}
+	w.Writeln ("}");
+	w.Writeln ("");
+	w.Writeln ("/// Library keeps %s loaded as long as the wrapper or any instance of its classes exists.", component.LibraryName);
+	w.Writeln ("struct Library {");
+	w.Writeln ("  handle: *mut c_void,");
+	w.Writeln ("  table: FunctionTable,");
+	w.Writeln ("}");
+	w.Writeln ("");
+	w.Writeln ("impl Library {");
+	w.Writeln ("  fn load(file_name: &str) -> Result<Library, Error> {");
+	w.Writeln ("    let c_file_name = to_cstring(file_name)?;");
+	w.Writeln ("    let handle = unsafe { loader::load_library(&c_file_name) };");
+	w.Writeln ("    if handle.is_null() {");
+	w.Writeln ("      return Err(Error::Binding(format!(\"could not load library \\\"{}\\\"\", file_name)));");
+	w.Writeln ("    }");
+	w.Writeln ("");
+	w.Writeln ("    match unsafe { Library::load_table(handle) } {");
+	w.Writeln ("      Ok(table) => Ok(Library { handle, table }),");
+	w.Writeln ("      Err(error) => {");
+	w.Writeln ("        unsafe { loader::unload_library(handle) };");
+	w.Writeln ("        Err(error)");
+	w.Writeln ("      }");
+	w.Writeln ("    }");
+	w.Writeln ("  }");
+	w.Writeln ("");
+	w.Writeln ("  unsafe fn load_symbol(handle: *mut c_void, symbol_name: &str) -> Result<*mut c_void, Error> {");
+	w.Writeln ("    let c_symbol_name = to_cstring(symbol_name)?;");
+	w.Writeln ("    let symbol = loader::load_symbol(handle, &c_symbol_name);");
+	w.Writeln ("    if symbol.is_null() {");
+	w.Writeln ("      return Err(Error::Binding(format!(\"could not find the export \\\"{}\\\"\", symbol_name)));");
+	w.Writeln ("    }");
+	w.Writeln ("    Ok(symbol)");
+	w.Writeln ("  }");
+	w.Writeln ("");
+	w.Writeln ("  unsafe fn load_table(handle: *mut c_void) -> Result<FunctionTable, Error> {");
+	w.Writeln ("    Ok(FunctionTable {");
+	for _, export := range exports {
+		w.Writeln ("      %s: std::mem::transmute(Library::load_symbol(handle, \"%s\")?),", export.name, export.name);
+	}
+	w.Writeln ("    })");
+	w.Writeln ("  }");
+	w.Writeln ("}");
+	w.Writeln ("");
+	w.Writeln ("impl Drop for Library {");
+	w.Writeln ("  fn drop(&mut self) {");
+	w.Writeln ("    unsafe { loader::unload_library(self.handle) };");
+	w.Writeln ("  }");
+	w.Writeln ("}");
+	w.Writeln ("");
+	w.Writeln ("fn to_cstring(value: &str) -> Result<CString, Error> {");
+	w.Writeln ("  CString::new(value).map_err(|_| Error::Binding(format!(\"the string \\\"{}\\\" contains a null character\", value.replace('\\0', \"\\\\0\"))))");
+	w.Writeln ("}");
+	w.Writeln ("");
+	w.Writeln ("fn string_from_buffer(buffer: &[u8]) -> String {");
+	w.Writeln ("  let length = buffer.iter().position(|&c| c == 0).unwrap_or(buffer.len());");
+	w.Writeln ("  String::from_utf8_lossy(&buffer[..length]).into_owned()");
+	w.Writeln ("}");
+	w.Writeln ("");
+
+	return nil;
+}
+
+// rustStringOutput passes the buffer of a string output parameter as vector of bytes
+var rustStringOutput = CStringOutputSyntax {
+	Declaration: "let mut needed_%[1]s: u32 = 0;",
+	NeededChars: "needed_%[1]s as usize",
+	Allocation: "let mut buffer_%[1]s = vec![0u8; %[2]s];",
+	QueryArguments: "0, &mut needed_%[1]s, std::ptr::null_mut()",
+	CallArguments: "buffer_%[1]s.len() as u32, &mut needed_%[1]s, buffer_%[1]s.as_mut_ptr() as *mut c_char",
+};
+
+// writeRustMethod writes a method of a class or of the wrapper, that calls the exported function of the library
+func writeRustMethod (method ComponentDefinitionMethod, w LanguageWriter, NameSpace string, ClassName string, isGlobal bool, doErrorMessages bool) (error) {
+	parameters := "&self";
+	comments := "";
+	var calls CBufferCalls;
+	returntypes := []string {};
+	returnvalues := []string {};
+
+	if (!isGlobal) {
+		calls.AddArgument ("self.handle()");
+	}
+
+	for _, param := range method.Params {
+		name := getRustIdentifier (param.ParamName);
+		rawName := strings.TrimSuffix (name, "_");
+
+		switch (param.ParamPass) {
+		case "in":
+			paramType := "";
+			callparameter := "";
+			switch (param.ParamType) {
+				case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "bool", "single", "double":
+					basicType, err := getRustBasicType (param.ParamType);
+					if (err != nil) {
+						return err;
+					}
+					paramType = basicType;
+					callparameter = name;
+				case "enum":
+					paramType = param.ParamClass;
+					callparameter = name;
+				case "string":
+					paramType = "&str";
+					calls.Declarations = append (calls.Declarations, fmt.Sprintf ("let c_%s = to_cstring(%s)?;", rawName, name));
+					callparameter = fmt.Sprintf ("c_%s.as_ptr()", rawName);
+				case "struct":
+					paramType = "&" + param.ParamClass;
+					callparameter = name;
+				case "basicarray":
+					basicType, err := getRustBasicType (param.ParamClass);
+					if (err != nil) {
+						return err;
+					}
+					paramType = "&[" + basicType + "]";
+					callparameter = fmt.Sprintf ("%s.len() as u64, %s.as_ptr()", name, name);
+				case "structarray":
+					paramType = "&[" + param.ParamClass + "]";
+					callparameter = fmt.Sprintf ("%s.len() as u64, %s.as_ptr()", name, name);
+				case "functiontype":
+					paramType = fmt.Sprintf ("Option<%s>", param.ParamClass);
+					callparameter = name;
+				case "handle":
+					if (param.ParamClass == "BaseClass") {
+						paramType = "&dyn BaseClass";
+					} else {
+						paramType = "&" + param.ParamClass;
+					}
+					callparameter = name + ".handle()";
+				default:
+					return fmt.Errorf ("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName);
+			}
+			parameters = parameters + fmt.Sprintf (", %s: %s", name, paramType);
+			comments = comments + fmt.Sprintf ("/// * `%s` - %s\n", name, param.ParamDescription);
+			calls.AddArgument (callparameter);
+
+		case "out", "return":
+			comments = comments + fmt.Sprintf ("/// * returns %s\n", param.ParamDescription);
+			resultName := "result_" + rawName;
+			switch (param.ParamType) {
+				case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "bool", "single", "double":
+					basicType, err := getRustBasicType (param.ParamType);
+					if (err != nil) {
+						return err;
+					}
+					calls.Declarations = append (calls.Declarations, fmt.Sprintf ("let mut %s: %s = %s;", resultName, basicType, getRustZeroValue (param.ParamType)));
+					calls.AddArgument ("&mut " + resultName);
+					returntypes = append (returntypes, basicType);
+					returnvalues = append (returnvalues, resultName);
+				case "enum":
+					calls.Declarations = append (calls.Declarations, fmt.Sprintf ("let mut %s = %s(0);", resultName, param.ParamClass));
+					calls.AddArgument ("&mut " + resultName);
+					returntypes = append (returntypes, param.ParamClass);
+					returnvalues = append (returnvalues, resultName);
+				case "struct":
+					calls.Declarations = append (calls.Declarations, fmt.Sprintf ("let mut %s = %s::default();", resultName, param.ParamClass));
+					calls.AddArgument ("&mut " + resultName);
+					returntypes = append (returntypes, param.ParamClass);
+					returnvalues = append (returnvalues, resultName);
+				case "string":
+					calls.AddStringOutput (rustStringOutput, rawName);
+					returntypes = append (returntypes, "String");
+					returnvalues = append (returnvalues, fmt.Sprintf ("string_from_buffer(&buffer_%s)", rawName));
+				case "basicarray", "structarray":
+					elementType := param.ParamClass;
+					zeroValue := param.ParamClass + "::default()";
+					if (param.ParamType == "basicarray") {
+						basicType, err := getRustBasicType (param.ParamClass);
+						if (err != nil) {
+							return err;
+						}
+						elementType = basicType;
+						zeroValue = getRustZeroValue (param.ParamClass);
+					}
+					calls.Declarations = append (calls.Declarations, fmt.Sprintf ("let mut needed_%s: u64 = 0;", rawName));
+					calls.AddBufferArgument (fmt.Sprintf ("0, &mut needed_%s, std::ptr::null_mut()", rawName), fmt.Sprintf ("array_%s.len() as u64, &mut needed_%s, array_%s.as_mut_ptr()", rawName, rawName, rawName),
+						fmt.Sprintf ("let mut array_%s: Vec<%s> = vec![%s; needed_%s as usize];", rawName, elementType, zeroValue, rawName));
+					returntypes = append (returntypes, "Vec<" + elementType + ">");
+					returnvalues = append (returnvalues, "array_" + rawName);
+				case "handle":
+					if (param.ParamClass == "BaseClass") {
+						return fmt.Errorf ("the Rust binding can not return handles of class \"%s\" for %s.%s (%s)", param.ParamClass, ClassName, method.MethodName, param.ParamName);
+					}
+					calls.Declarations = append (calls.Declarations, fmt.Sprintf ("let mut handle_%s: Handle = std::ptr::null_mut();", rawName));
+					calls.AddArgument ("&mut handle_" + rawName);
+					returntypes = append (returntypes, param.ParamClass);
+					returnvalues = append (returnvalues, fmt.Sprintf ("%s::new(handle_%s, Rc::clone(self.library()))", param.ParamClass, rawName));
+				default:
+					return fmt.Errorf ("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName);
+			}
+
+		default:
+			return fmt.Errorf ("invalid method parameter passing \"%s\" for %s.%s (%s)", param.ParamPass, ClassName, method.MethodName, param.ParamName);
+		}
+	}
+
+	returntype := "()";
+	returnvalue := "()";
+	if (len (returntypes) == 1) {
+		returntype = returntypes[0];
+		returnvalue = returnvalues[0];
+	} else if (len (returntypes) > 1) {
+		returntype = "(" + strings.Join (returntypes, ", ") + ")";
+		returnvalue = "(" + strings.Join (returnvalues, ", ") + ")";
+	}
+
+	CMethodName := GetCExportName (NameSpace, ClassName, method, isGlobal);
+
+	w.Writeln ("");
+	writeRustDocComment (w, "  ", method.MethodDescription, comments);
+	w.Writeln ("  pub fn %s(%s) -> Result<%s, Error> {", getRustIdentifier (method.MethodName), parameters, returntype);
+	w.Writelns ("    ", calls.Declarations);
+	checkCall := "check(";
+	if (doErrorMessages) {
+		if (isGlobal) {
+			checkCall = "check_instance(self.library(), std::ptr::null_mut(), ";
+		} else {
+			checkCall = "check_instance(self.library(), self.handle(), ";
+		}
+	}
+	calls.WriteCalls (w, "    ", fmt.Sprintf ("%sunsafe { (self.library().table.%s)(%%s) })?;", checkCall, CMethodName), nil);
+	w.Writeln ("    Ok(%s)", returnvalue);
+	w.Writeln ("  }");
+
+	return nil;
+}
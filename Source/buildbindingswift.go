@@ -0,0 +1,913 @@
+/*++
+
+Copyright (C) 2018 Autodesk Inc. (Original Author)
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+--*/
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+// buildbindingswift.go
+// functions to generate a Swift package of a library's API, that imports the C header with a module map.
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"strings"
+)
+
+// BuildBindingSwift builds a Swift package of a library's API, that imports the C header with a module map
+func BuildBindingSwift(component ComponentDefinition, outputFolder string, indentString string) error {
+	libraryname := component.LibraryName;
+	baseName := component.BaseName;
+	NameSpace := component.NameSpace;
+	CModuleName := getSwiftCModuleName (component);
+
+	outputFolderCModule := path.Join(outputFolder, "Sources", CModuleName);
+	err := os.MkdirAll(outputFolderCModule, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+	outputFolderModule := path.Join(outputFolder, "Sources", NameSpace);
+	err = os.MkdirAll(outputFolderModule, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	PackageName := path.Join(outputFolder, "Package.swift");
+	log.Printf ("Creating \"%s\"", PackageName);
+	packagefile, err := CreateLanguageFile (PackageName, "    ");
+	if (err != nil) {
+		return err;
+	}
+	packagefile.Writeln ("// swift-tools-version:5.3");
+	packagefile.Writeln ("");
+	packagefile.Writeln ("import PackageDescription");
+	packagefile.Writeln ("");
+	packagefile.Writeln ("let package = Package(");
+	packagefile.Writeln ("  name: \"%s\",", NameSpace);
+	packagefile.Writeln ("  products: [");
+	packagefile.Writeln ("    .library(name: \"%s\", targets: [\"%s\"]),", NameSpace, NameSpace);
+	packagefile.Writeln ("  ],");
+	packagefile.Writeln ("  targets: [");
+	packagefile.Writeln ("    .systemLibrary(name: \"%s\", path: \"Sources/%s\"),", CModuleName, CModuleName);
+	packagefile.Writeln ("    .target(name: \"%s\", dependencies: [\"%s\"], path: \"Sources/%s\"),", NameSpace, CModuleName, NameSpace);
+	packagefile.Writeln ("  ]");
+	packagefile.Writeln (")");
+
+	ModuleMapName := path.Join(outputFolderCModule, "module.modulemap");
+	log.Printf ("Creating \"%s\"", ModuleMapName);
+	modulemapfile, err := CreateLanguageFile (ModuleMapName, "    ");
+	if (err != nil) {
+		return err;
+	}
+	modulemapfile.Writeln ("module %s [system] {", CModuleName);
+	modulemapfile.Writeln ("  header \"%s.h\"", baseName);
+	modulemapfile.Writeln ("  link \"%s\"", getSwiftLinkName (component));
+	modulemapfile.Writeln ("  export *");
+	modulemapfile.Writeln ("}");
+
+	CTypesHeaderName := path.Join(outputFolderCModule, baseName + "_types.h");
+	err = CreateCTypesHeader (component, CTypesHeaderName);
+	if (err != nil) {
+		return err;
+	}
+
+	CHeaderName := path.Join(outputFolderCModule, baseName + ".h");
+	err = CreateCHeader (component, CHeaderName);
+	if (err != nil) {
+		return err;
+	}
+
+	SwiftFileName := path.Join(outputFolderModule, NameSpace + ".swift");
+	log.Printf ("Creating \"%s\"", SwiftFileName);
+	swiftfile, err := CreateLanguageFile (SwiftFileName, indentString);
+	if (err != nil) {
+		return err;
+	}
+
+	err = swiftfile.WriteCLicenseHeader(component,
+		fmt.Sprintf ("This is an autogenerated Swift file in order to allow an easy\n use of %s", libraryname),
+		true)
+	if err != nil {
+		return err
+	}
+
+	return buildSwiftBinding (component, swiftfile);
+}
+
+// validateBindingSwift checks that the types and methods of the Swift module are unique and do not collide with Swift
+func validateBindingSwift (component ComponentDefinition, options GeneratorOptions) (error) {
+	NameSpace := component.NameSpace;
+
+	types := make (map[string]string);
+	for _, name := range []string {"Any", "Array", "Bool", "Double", "Error", "Float", "Int", "Int8", "Int16", "Int32", "Int64",
+		"Optional", "Result", "String", "UInt", "UInt8", "UInt16", "UInt32", "UInt64", "Swift",
+		"BaseClass", "Wrapper", NameSpace, NameSpace + "Error", getSwiftHelpersName (component), getSwiftCModuleName (component)} {
+		types[name] = "the Swift binding";
+	}
+	add := func (name string, source string) (error) {
+		previous, ok := types[name];
+		if (ok) {
+			return fmt.Errorf ("the Swift type \"%s\" of %s collides with %s", name, source, previous);
+		}
+		types[name] = source;
+		return nil;
+	}
+	for _, errorcode := range component.Errors.Errors {
+		switch (errorcode.Name) {
+			case "unknown", "binding", "code", "description", "init":
+				return fmt.Errorf ("the Swift case of error %s collides with the Swift binding", errorcode.Name);
+		}
+	}
+	for _, enum := range component.Enums {
+		err := add (enum.Name, "enum " + enum.Name);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, structinfo := range component.Structs {
+		err := add (structinfo.Name, "struct " + structinfo.Name);
+		if (err != nil) {
+			return err;
+		}
+		for _, member := range structinfo.Members {
+			if (getSwiftIdentifier (member.Name) == "cValue") {
+				return fmt.Errorf ("the Swift name of member %s.%s collides with the Swift binding", structinfo.Name, member.Name);
+			}
+		}
+	}
+	for _, functiontype := range component.Functions {
+		err := add (functiontype.FunctionName, "functiontype " + functiontype.FunctionName);
+		if (err != nil) {
+			return err;
+		}
+	}
+	for _, class := range component.Classes {
+		err := add (class.ClassName, "class " + class.ClassName);
+		if (err != nil) {
+			return err;
+		}
+	}
+
+	checkMethod := func (method ComponentDefinitionMethod, source string) (error) {
+		switch (strings.ToLower (method.MethodName)) {
+			case "init", "deinit", "subscript", "handle":
+				return fmt.Errorf ("the Swift name of %s collides with Swift or the Swift binding", source);
+		}
+		return nil;
+	}
+	for _, class := range component.Classes {
+		for _, method := range class.Methods {
+			source := "method " + class.ClassName + "." + method.MethodName;
+			err := checkMethod (method, source);
+			if (err != nil) {
+				return err;
+			}
+			parentMethod, parentClass, found := findSwiftParentMethod (component, class, method);
+			if (found) {
+				_, results, err := getSwiftSignature (parentMethod);
+				if (err != nil) {
+					return err;
+				}
+				_, methodResults, err := getSwiftSignature (method);
+				if (err != nil) {
+					return err;
+				}
+				if (results != methodResults) {
+					return fmt.Errorf ("%s overrides %s.%s with a different result, which Swift does not allow", source, parentClass, parentMethod.MethodName);
+				}
+			}
+		}
+	}
+	for _, method := range component.Global.Methods {
+		err := checkMethod (method, "global method " + method.MethodName);
+		if (err != nil) {
+			return err;
+		}
+	}
+	return nil;
+}
+
+// getSwiftCModuleName returns the name of the Clang module of the C header
+func getSwiftCModuleName (component ComponentDefinition) (string) {
+	return "C" + component.NameSpace;
+}
+
+// getSwiftHelpersName returns the name of the file private enum of the helpers of the binding
+func getSwiftHelpersName (component ComponentDefinition) (string) {
+	return component.NameSpace + "Helpers";
+}
+
+// getSwiftLinkName returns the name of the library for the linker, which adds the prefix "lib" itself
+func getSwiftLinkName (component ComponentDefinition) (string) {
+	if (strings.HasPrefix (component.BaseName, "lib")) && (len (component.BaseName) > 3) {
+		return component.BaseName[3:];
+	}
+	return component.BaseName;
+}
+
+var swiftKeywords = map[string]bool {
+	"associatedtype": true, "class": true, "deinit": true, "enum": true, "extension": true, "fileprivate": true,
+	"func": true, "import": true, "init": true, "inout": true, "internal": true, "let": true, "open": true,
+	"operator": true, "private": true, "protocol": true, "public": true, "rethrows": true, "static": true,
+	"struct": true, "subscript": true, "typealias": true, "var": true, "break": true, "case": true,
+	"continue": true, "default": true, "defer": true, "do": true, "else": true, "fallthrough": true, "for": true,
+	"guard": true, "if": true, "in": true, "repeat": true, "return": true, "switch": true, "where": true,
+	"while": true, "as": true, "catch": true, "false": true, "is": true, "nil": true, "super": true, "self": true,
+	"throw": true, "throws": true, "true": true, "try": true, "Any": true, "Self": true, "Type": true,
+}
+
+// getSwiftIdentifier returns the lower camel case name of a method, parameter, member or enum option
+func getSwiftIdentifier (name string) (string) {
+	return strings.ToLower (name[0:1]) + name[1:];
+}
+
+// getSwiftSafeIdentifier returns the Swift identifier of a name, which is escaped if it is a keyword
+func getSwiftSafeIdentifier (name string) (string) {
+	identifier := getSwiftIdentifier (name);
+	if (swiftKeywords[identifier]) {
+		return "`" + identifier + "`";
+	}
+	return identifier;
+}
+
+// getSwiftStringLiteral escapes a text for a string literal
+func getSwiftStringLiteral (text string) (string) {
+	return strings.NewReplacer ("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace (text);
+}
+
+// getSwiftBasicType returns the Swift type of a scalar type of the IDL
+func getSwiftBasicType (paramType string) (string, error) {
+	switch (paramType) {
+		case "uint8":
+			return "UInt8", nil;
+		case "uint16":
+			return "UInt16", nil;
+		case "uint32":
+			return "UInt32", nil;
+		case "uint64":
+			return "UInt64", nil;
+		case "int8":
+			return "Int8", nil;
+		case "int16":
+			return "Int16", nil;
+		case "int32":
+			return "Int32", nil;
+		case "int64":
+			return "Int64", nil;
+		case "bool":
+			return "Bool", nil;
+		case "single":
+			return "Float", nil;
+		case "double":
+			return "Double", nil;
+	}
+	return "", fmt.Errorf ("invalid basic type \"%s\" for Swift", paramType);
+}
+
+// getSwiftZeroValue returns the initial value of a scalar type of the IDL
+func getSwiftZeroValue (component ComponentDefinition, paramType string, paramClass string) (string) {
+	switch (paramType) {
+		case "bool":
+			return "false";
+		case "enum":
+			for _, enum := range component.Enums {
+				if (enum.Name == paramClass) && (len (enum.Options) > 0) {
+					return "." + getSwiftIdentifier (enum.Options[0].Name);
+				}
+			}
+	}
+	return "0";
+}
+
+// getSwiftParamType returns the Swift type of a parameter
+func getSwiftParamType (param ComponentDefinitionParam) (string, error) {
+	switch (param.ParamType) {
+		case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "bool", "single", "double":
+			return getSwiftBasicType (param.ParamType);
+		case "string":
+			return "String", nil;
+		case "enum", "struct", "functiontype":
+			return param.ParamClass, nil;
+		case "basicarray":
+			basicType, err := getSwiftBasicType (param.ParamClass);
+			if (err != nil) {
+				return "", err;
+			}
+			return "[" + basicType + "]", nil;
+		case "structarray":
+			return "[" + param.ParamClass + "]", nil;
+		case "handle":
+			return param.ParamClass + "?", nil;
+	}
+	return "", fmt.Errorf ("invalid parameter type \"%s\" for Swift (%s)", param.ParamType, param.ParamName);
+}
+
+// getSwiftSignature returns the parameters and the result type of the Swift method of a method
+func getSwiftSignature (method ComponentDefinitionMethod) (string, string, error) {
+	parameters := [] string {};
+	results := [] string {};
+	for _, param := range method.Params {
+		paramType, err := getSwiftParamType (param);
+		if (err != nil) {
+			return "", "", err;
+		}
+		if (param.ParamPass == "in") {
+			parameters = append (parameters, getSwiftSafeIdentifier (param.ParamName) + ": " + paramType);
+		} else {
+			results = append (results, getSwiftSafeIdentifier (param.ParamName) + ": " + paramType);
+		}
+	}
+
+	result := "";
+	if (len (results) == 1) {
+		result = results[0][strings.Index (results[0], ": ") + 2:];
+	} else if (len (results) > 1) {
+		result = "(" + strings.Join (results, ", ") + ")";
+	}
+	return strings.Join (parameters, ", "), result, nil;
+}
+
+// findSwiftParentMethod returns the method of a parent class, that a method overrides
+func findSwiftParentMethod (component ComponentDefinition, class ComponentDefinitionClass, method ComponentDefinitionMethod) (ComponentDefinitionMethod, string, bool) {
+	parameters, _, err := getSwiftSignature (method);
+	if (err != nil) {
+		return ComponentDefinitionMethod {}, "", false;
+	}
+	parentName := class.ParentClass;
+	for (parentName != "") {
+		found := false;
+		for _, parent := range component.Classes {
+			if (parent.ClassName != parentName) {
+				continue;
+			}
+			for _, parentMethod := range parent.Methods {
+				parentParameters, _, err := getSwiftSignature (parentMethod);
+				if (err != nil) {
+					continue;
+				}
+				if (getSwiftIdentifier (parentMethod.MethodName) == getSwiftIdentifier (method.MethodName)) && (parentParameters == parameters) {
+					return parentMethod, parent.ClassName, true;
+				}
+			}
+			parentName = parent.ParentClass;
+			found = true;
+			break;
+		}
+		if (!found) {
+			break;
+		}
+	}
+	return ComponentDefinitionMethod {}, "", false;
+}
+
+// writeSwiftComment writes a documentation comment
+func writeSwiftComment (w LanguageWriter, indent string, description string, params [][2]string, returns string) {
+	if (description != "") {
+		for _, line := range strings.Split (description, "\n") {
+			w.Writeln (indent + "/// %s", strings.TrimSpace (line));
+		}
+	}
+	if (len (params) > 0) || (returns != "") {
+		w.Writeln (indent + "///");
+	}
+	if (len (params) > 0) {
+		w.Writeln (indent + "/// - Parameters:");
+		for _, param := range params {
+			w.Writeln (indent + "///   - %s: %s", param[0], param[1]);
+		}
+	}
+	if (returns != "") {
+		w.Writeln (indent + "/// - Returns: %s", returns);
+	}
+}
+
+func buildSwiftBinding (component ComponentDefinition, w LanguageWriter) (error) {
+	NameSpace := component.NameSpace;
+
+	w.Writeln ("");
+	w.Writeln ("import %s", getSwiftCModuleName (component));
+	w.Writeln ("");
+
+	writeSwiftErrors (component, w);
+
+	err := writeSwiftTypes (component, w);
+	if (err != nil) {
+		return err;
+	}
+
+	writeSwiftHelpers (component, w);
+
+	releaseExport := GetCExportName (NameSpace, "", ComponentDefinitionMethod {MethodName: component.Global.ReleaseMethod}, true);
+	w.Writeln ("/// BaseClass is the base of all classes of %s. It releases its instance when it is deinitialized.", component.LibraryName);
+	w.Writeln ("public class BaseClass {");
+	w.Writeln ("  /// The handle of the instance");
+	w.Writeln ("  public let handle: UnsafeMutableRawPointer");
+	w.Writeln ("");
+	w.Writeln ("  /// Takes the ownership of an instance of the library");
+	w.Writeln ("  public init(handle: UnsafeMutableRawPointer) {");
+	w.Writeln ("    self.handle = handle");
+	w.Writeln ("  }");
+	w.Writeln ("");
+	w.Writeln ("  deinit {");
+	w.Writeln ("    _ = %s(handle)", releaseExport);
+	w.Writeln ("  }");
+	w.Writeln ("}");
+	w.Writeln ("");
+
+	for _, class := range component.Classes {
+		parentClass := class.ParentClass;
+		if (parentClass == "") {
+			parentClass = "BaseClass";
+		}
+		writeSwiftComment (w, "", class.ClassDescription, nil, "");
+		w.Writeln ("public class %s: %s {", class.ClassName, parentClass);
+		for index, method := range class.Methods {
+			if (index > 0) {
+				w.Writeln ("");
+			}
+			_, _, isOverride := findSwiftParentMethod (component, class, method);
+			err := writeSwiftMethod (component, method, w, class.ClassName, false, isOverride);
+			if (err != nil) {
+				return err;
+			}
+		}
+		w.Writeln ("}");
+		w.Writeln ("");
+	}
+
+	w.Writeln ("/// Wrapper contains the global functions of %s", component.LibraryName);
+	w.Writeln ("public enum Wrapper {");
+	first := true;
+	for _, method := range component.Global.Methods {
+		if (method.MethodName == component.Global.ReleaseMethod) {
+			continue;
+		}
+		if (!first) {
+			w.Writeln ("");
+		}
+		first = false;
+		err := writeSwiftMethod (component, method, w, "Wrapper", true, false);
+		if (err != nil) {
+			return err;
+		}
+	}
+	w.Writeln ("}");
+	return nil;
+}
+
+// writeSwiftErrors writes the Swift error enum of the errors of the IDL
+func writeSwiftErrors (component ComponentDefinition, w LanguageWriter) {
+	NameSpace := component.NameSpace;
+	_, hasErrorMethod := component.Global.GetErrorMethod ();
+
+	w.Writeln ("/// %sError is an error of %s", NameSpace, component.LibraryName);
+	w.Writeln ("public enum %sError: Swift.Error, CustomStringConvertible {", NameSpace);
+	for _, errorcode := range component.Errors.Errors {
+		w.Writeln ("  /// %s", errorcode.Description);
+		w.Writeln ("  case %s", errorcode.Name);
+	}
+	w.Writeln ("  /// An error code, that the IDL does not define");
+	w.Writeln ("  case unknown(code: Int32)");
+	w.Writeln ("  /// An error of the binding");
+	w.Writeln ("  case binding(message: String)");
+	if (hasErrorMethod) {
+		w.Writeln ("  /// An error with the error message, that the library provides");
+		w.Writeln ("  indirect case withMessage(error: %sError, message: String)", NameSpace);
+	}
+	w.Writeln ("");
+	w.Writeln ("  /// Creates the error of an error code of the library");
+	w.Writeln ("  public init(code: Int32) {");
+	w.Writeln ("    switch code {");
+	for _, errorcode := range component.Errors.Errors {
+		w.Writeln ("    case %d: self = .%s", errorcode.Code, errorcode.Name);
+	}
+	w.Writeln ("    default: self = .unknown(code: code)");
+	w.Writeln ("    }");
+	w.Writeln ("  }");
+	w.Writeln ("");
+	w.Writeln ("  /// The error code of the library, or 0 for errors of the binding");
+	w.Writeln ("  public var code: Int32 {");
+	w.Writeln ("    switch self {");
+	for _, errorcode := range component.Errors.Errors {
+		w.Writeln ("    case .%s: return %d", errorcode.Name, errorcode.Code);
+	}
+	w.Writeln ("    case .unknown(let code): return code");
+	w.Writeln ("    case .binding: return 0");
+	if (hasErrorMethod) {
+		w.Writeln ("    case .withMessage(let error, _): return error.code");
+	}
+	w.Writeln ("    }");
+	w.Writeln ("  }");
+	w.Writeln ("");
+	if (hasErrorMethod) {
+		w.Writeln ("  /// The error without the error message of the library");
+		w.Writeln ("  public var kind: %sError {", NameSpace);
+		w.Writeln ("    switch self {");
+		w.Writeln ("    case .withMessage(let error, _): return error");
+		w.Writeln ("    default: return self");
+		w.Writeln ("    }");
+		w.Writeln ("  }");
+		w.Writeln ("");
+		w.Writeln ("  /// The error message, that the library provides");
+		w.Writeln ("  public var message: String? {");
+		w.Writeln ("    switch self {");
+		w.Writeln ("    case .withMessage(_, let message): return message");
+		w.Writeln ("    default: return nil");
+		w.Writeln ("    }");
+		w.Writeln ("  }");
+		w.Writeln ("");
+	}
+	w.Writeln ("  public var description: String {");
+	w.Writeln ("    switch self {");
+	for _, errorcode := range component.Errors.Errors {
+		w.Writeln ("    case .%s: return \"%s\"", errorcode.Name, getSwiftStringLiteral (errorcode.Description));
+	}
+	w.Writeln ("    case .unknown(let code): return \"unknown error \\(code)\"");
+	w.Writeln ("    case .binding(let message): return message");
+	if (hasErrorMethod) {
+		w.Writeln ("    case .withMessage(let error, let message): return \"\\(error.description): \\(message)\"");
+	}
+	w.Writeln ("    }");
+	w.Writeln ("  }");
+	w.Writeln ("}");
+	w.Writeln ("");
+}
+
+// writeSwiftTypes writes the enums, structs and function types of the IDL
+func writeSwiftTypes (component ComponentDefinition, w LanguageWriter) (error) {
+	NameSpace := component.NameSpace;
+	Helpers := getSwiftHelpersName (component);
+
+	for _, enum := range component.Enums {
+		w.Writeln ("/// %s is the enum e%s%s of %s", enum.Name, NameSpace, enum.Name, component.LibraryName);
+		w.Writeln ("public enum %s: Int32 {", enum.Name);
+		for _, option := range enum.Options {
+			w.Writeln ("  case %s = %d", getSwiftSafeIdentifier (option.Name), option.Value);
+		}
+		w.Writeln ("}");
+		w.Writeln ("");
+	}
+
+	for _, structinfo := range component.Structs {
+		CStructName := "s" + NameSpace + structinfo.Name;
+		parameters := []string {};
+		assignments := []string {};
+		fromC := []string {};
+		throwsFromC := false;
+		toC := []string {};
+
+		w.Writeln ("/// %s is the struct %s of %s", structinfo.Name, CStructName, component.LibraryName);
+		w.Writeln ("public struct %s {", structinfo.Name);
+		for _, member := range structinfo.Members {
+			name := getSwiftSafeIdentifier (member.Name);
+			elementType := member.Class;
+			if (member.Type != "enum") {
+				basicType, err := getSwiftBasicType (member.Type);
+				if (err != nil) {
+					return err;
+				}
+				elementType = basicType;
+			}
+			zeroValue := getSwiftZeroValue (component, member.Type, member.Class);
+			memberType := elementType;
+			if (member.Rows > 0) {
+				memberType = "[" + elementType + "]";
+				zeroValue = fmt.Sprintf ("%s(repeating: %s, count: %d)", memberType, zeroValue, member.Rows);
+				if (member.Columns > 0) {
+					memberType = "[" + memberType + "]";
+					zeroValue = fmt.Sprintf ("%s(repeating: %s, count: %d)", memberType, zeroValue, member.Columns);
+				}
+			}
+			w.Writeln ("  public var %s: %s", name, memberType);
+			parameters = append (parameters, fmt.Sprintf ("%s: %s = %s", name, memberType, zeroValue));
+			assignments = append (assignments, fmt.Sprintf ("self.%s = %s", getSwiftIdentifier (member.Name), name));
+
+			// C arrays are tuples in Swift, whose elements are stored column by column
+			CMember := "c.m_" + member.Name;
+			loadType := elementType;
+			if (member.Type == "enum") {
+				loadType = "Int32";
+			}
+			fromValue := "";
+			toValue := "";
+			if (member.Rows > 0) {
+				fromValue = fmt.Sprintf ("%s.loadArray(%s, as: %s.self)", Helpers, CMember, loadType);
+				toValue = getSwiftIdentifier (member.Name);
+				if (member.Columns > 0) {
+					toValue = fmt.Sprintf ("Array(%s.joined())", toValue);
+				}
+				if (member.Type == "enum") {
+					throwsFromC = true;
+					fromValue = fmt.Sprintf ("%s.map { try %s.toEnum(%s.self, $0) }", fromValue, Helpers, member.Class);
+					toValue = toValue + ".map { $0.rawValue }";
+				}
+				if (member.Columns > 0) {
+					fromValue = fmt.Sprintf ("%s.splitArray(%s, count: %d)", Helpers, fromValue, member.Rows);
+				}
+				toC = append (toC, fmt.Sprintf ("%s = %s.storeArray(%s, in: %s)", CMember, Helpers, toValue, CMember));
+			} else if (member.Type == "enum") {
+				throwsFromC = true;
+				fromValue = fmt.Sprintf ("%s.toEnum(%s.self, %s.m_code)", Helpers, member.Class, CMember);
+				toC = append (toC, fmt.Sprintf ("%s.m_code = %s.rawValue", CMember, getSwiftIdentifier (member.Name)));
+			} else {
+				fromValue = CMember;
+				toC = append (toC, fmt.Sprintf ("%s = %s", CMember, getSwiftIdentifier (member.Name)));
+			}
+			fromC = append (fromC, fmt.Sprintf ("%s: %s", name, fromValue));
+		}
+		w.Writeln ("");
+		w.Writeln ("  public init(%s) {", strings.Join (parameters, ", "));
+		w.Writelns ("    ", assignments);
+		w.Writeln ("  }");
+		w.Writeln ("");
+		w.Writeln ("  fileprivate init(cValue c: %s) throws {", CStructName);
+		if (throwsFromC) {
+			w.Writeln ("    try self.init(%s)", strings.Join (fromC, ", "));
+		} else {
+			w.Writeln ("    self.init(%s)", strings.Join (fromC, ", "));
+		}
+		w.Writeln ("  }");
+		w.Writeln ("");
+		w.Writeln ("  fileprivate var cValue: %s {", CStructName);
+		w.Writeln ("    var c = %s()", CStructName);
+		w.Writelns ("    ", toC);
+		w.Writeln ("    return c");
+		w.Writeln ("  }");
+		w.Writeln ("}");
+		w.Writeln ("");
+	}
+
+	for _, functiontype := range component.Functions {
+		w.Writeln ("/// %s", functiontype.FunctionDescription);
+		w.Writeln ("public typealias %s = %s%s", functiontype.FunctionName, NameSpace, functiontype.FunctionName);
+		w.Writeln ("");
+	}
+	return nil;
+}
+
+// writeSwiftHelpers writes the helpers of the binding
+func writeSwiftHelpers (component ComponentDefinition, w LanguageWriter) {
+	NameSpace := component.NameSpace;
+
+	w.Writeln ("fileprivate enum %s {", getSwiftHelpersName (component));
+	errorMethod, hasErrorMethod := component.Global.GetErrorMethod ();
+	if (hasErrorMethod) {
+		errorExport := GetCExportName (NameSpace, "", errorMethod, true);
+		w.Writeln ("  static func check(_ code: %sResult, _ instance: UnsafeMutableRawPointer? = nil) throws {", NameSpace);
+		w.Writeln ("    if code != %s_SUCCESS {", strings.ToUpper (NameSpace));
+		w.Writeln ("      let error = %sError(code: code)", NameSpace);
+		w.Writeln ("      if let message = errorMessage(instance) {");
+		w.Writeln ("        throw %sError.withMessage(error: error, message: message)", NameSpace);
+		w.Writeln ("      }");
+		w.Writeln ("      throw error");
+		w.Writeln ("    }");
+		w.Writeln ("  }");
+		w.Writeln ("");
+		w.Writeln ("  static func errorMessage(_ instance: UnsafeMutableRawPointer?) -> String? {");
+		w.Writeln ("    var neededChars: UInt32 = 0");
+		w.Writeln ("    var hasError: Bool = false");
+		w.Writeln ("    guard %s(instance, 0, &neededChars, nil, &hasError) == %s_SUCCESS, hasError else {", errorExport, strings.ToUpper (NameSpace));
+		w.Writeln ("      return nil");
+		w.Writeln ("    }");
+		w.Writeln ("    var buffer = [CChar](repeating: 0, count: Int(neededChars) + 1)");
+		w.Writeln ("    guard %s(instance, UInt32(buffer.count), &neededChars, &buffer, &hasError) == %s_SUCCESS else {", errorExport, strings.ToUpper (NameSpace));
+		w.Writeln ("      return nil");
+		w.Writeln ("    }");
+		w.Writeln ("    return fromCString(buffer)");
+		w.Writeln ("  }");
+	} else {
+		w.Writeln ("  static func check(_ code: %sResult) throws {", NameSpace);
+		w.Writeln ("    if code != %s_SUCCESS {", strings.ToUpper (NameSpace));
+		w.Writeln ("      throw %sError(code: code)", NameSpace);
+		w.Writeln ("    }");
+		w.Writeln ("  }");
+	}
+	w.Writeln ("");
+	w.Writeln ("  static func toEnum<E: RawRepresentable>(_ type: E.Type, _ value: Int32) throws -> E where E.RawValue == Int32 {");
+	w.Writeln ("    guard let result = E(rawValue: value) else {");
+	w.Writeln ("      throw %sError.binding(message: \"invalid value \\(value) of enum \\(type)\")", NameSpace);
+	w.Writeln ("    }");
+	w.Writeln ("    return result");
+	w.Writeln ("  }");
+	w.Writeln ("");
+	w.Writeln ("  static func fromCString(_ buffer: [CChar]) -> String {");
+	w.Writeln ("    return buffer.withUnsafeBufferPointer { String(cString: $0.baseAddress!) }");
+	w.Writeln ("  }");
+	if (len (component.Structs) > 0) {
+		w.Writeln ("");
+		w.Writeln ("  static func loadArray<T, C>(_ tuple: C, as type: T.Type) -> [T] {");
+		w.Writeln ("    return withUnsafeBytes(of: tuple) { buffer in");
+		w.Writeln ("      (0 ..< buffer.count / MemoryLayout<T>.stride).map {");
+		w.Writeln ("        buffer.load(fromByteOffset: $0 * MemoryLayout<T>.stride, as: T.self)");
+		w.Writeln ("      }");
+		w.Writeln ("    }");
+		w.Writeln ("  }");
+		w.Writeln ("");
+		w.Writeln ("  static func storeArray<T, C>(_ values: [T], in tuple: C) -> C {");
+		w.Writeln ("    var result = tuple");
+		w.Writeln ("    withUnsafeMutableBytes(of: &result) { buffer in");
+		w.Writeln ("      for (index, value) in values.prefix(buffer.count / MemoryLayout<T>.stride).enumerated() {");
+		w.Writeln ("        buffer.storeBytes(of: value, toByteOffset: index * MemoryLayout<T>.stride, as: T.self)");
+		w.Writeln ("      }");
+		w.Writeln ("    }");
+		w.Writeln ("    return result");
+		w.Writeln ("  }");
+		w.Writeln ("");
+		w.Writeln ("  static func splitArray<T>(_ values: [T], count: Int) -> [[T]] {");
+		w.Writeln ("    return stride(from: 0, to: values.count, by: count).map { Array(values[$0 ..< $0 + count]) }");
+		w.Writeln ("  }");
+	}
+	w.Writeln ("}");
+	w.Writeln ("");
+}
+
+// writeSwiftMethod writes a method of a class or a static function of the Wrapper
+func writeSwiftMethod (component ComponentDefinition, method ComponentDefinitionMethod, w LanguageWriter, ClassName string, isGlobal bool, isOverride bool) (error) {
+	NameSpace := component.NameSpace;
+	Helpers := getSwiftHelpersName (component);
+	indent := "  ";
+	CMethodName := GetCExportName (NameSpace, ClassName, method, isGlobal);
+
+	parameters, result, err := getSwiftSignature (method);
+	if (err != nil) {
+		return err;
+	}
+
+	comments := [][2]string {};
+	returnComments := [] string {};
+	definitions := [] string {};
+	queryArgs := [] string {};
+	callArgs := [] string {};
+	allocations := [] string {};
+	results := [] string {};
+	throwsResults := false;
+	checkInstance := "";
+	if (!isGlobal) {
+		queryArgs = append (queryArgs, "handle");
+		callArgs = append (callArgs, "handle");
+		_, hasErrorMethod := component.Global.GetErrorMethod ();
+		if (hasErrorMethod) {
+			checkInstance = ", handle";
+		}
+	}
+
+	for _, param := range method.Params {
+		name := getSwiftSafeIdentifier (param.ParamName);
+		args := [] string {};
+		queries := [] string {};
+
+		if (param.ParamPass == "in") {
+			comments = append (comments, [2]string {getSwiftIdentifier (param.ParamName), param.ParamDescription});
+			switch (param.ParamType) {
+				case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "bool", "single", "double", "string", "functiontype":
+					args = []string {name};
+				case "enum":
+					args = []string {fmt.Sprintf ("e%s%s(rawValue: .init(truncatingIfNeeded: %s.rawValue))", NameSpace, param.ParamClass, name)};
+				case "struct":
+					definitions = append (definitions, fmt.Sprintf ("var c%s = %s.cValue", param.ParamName, name));
+					args = []string {"&c" + param.ParamName};
+				case "basicarray":
+					args = []string {fmt.Sprintf ("UInt64(%s.count)", name), name};
+				case "structarray":
+					definitions = append (definitions, fmt.Sprintf ("let c%s = %s.map { $0.cValue }", param.ParamName, name));
+					args = []string {fmt.Sprintf ("UInt64(c%s.count)", param.ParamName), "c" + param.ParamName};
+				case "handle":
+					args = []string {name + "?.handle"};
+				default:
+					return fmt.Errorf ("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName);
+			}
+			queries = args;
+		} else {
+			returnComments = append (returnComments, param.ParamDescription);
+			resultValue := "";
+			switch (param.ParamType) {
+				case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "bool", "single", "double":
+					basicType, err := getSwiftBasicType (param.ParamType);
+					if (err != nil) {
+						return err;
+					}
+					definitions = append (definitions, fmt.Sprintf ("var %s: %s = %s", name, basicType, getSwiftZeroValue (component, param.ParamType, "")));
+					args = []string {"&" + name};
+					queries = args;
+					resultValue = name;
+				case "enum":
+					definitions = append (definitions, fmt.Sprintf ("var c%s = e%s%s(rawValue: 0)", param.ParamName, NameSpace, param.ParamClass));
+					args = []string {"&c" + param.ParamName};
+					queries = args;
+					throwsResults = true;
+					resultValue = fmt.Sprintf ("%s.toEnum(%s.self, Int32(truncatingIfNeeded: c%s.rawValue))", Helpers, param.ParamClass, param.ParamName);
+				case "struct":
+					definitions = append (definitions, fmt.Sprintf ("var c%s = s%s%s()", param.ParamName, NameSpace, param.ParamClass));
+					args = []string {"&c" + param.ParamName};
+					queries = args;
+					throwsResults = true;
+					resultValue = fmt.Sprintf ("%s(cValue: c%s)", param.ParamClass, param.ParamName);
+				case "string":
+					definitions = append (definitions, fmt.Sprintf ("var needed%s: UInt32 = 0", param.ParamName));
+					allocations = append (allocations, fmt.Sprintf ("var buffer%s = [CChar](repeating: 0, count: Int(needed%s) + 1)", param.ParamName, param.ParamName));
+					queries = []string {"0", "&needed" + param.ParamName, "nil"};
+					args = []string {fmt.Sprintf ("UInt32(buffer%s.count)", param.ParamName), "&needed" + param.ParamName, "&buffer" + param.ParamName};
+					resultValue = fmt.Sprintf ("%s.fromCString(buffer%s)", Helpers, param.ParamName);
+				case "basicarray":
+					basicType, err := getSwiftBasicType (param.ParamClass);
+					if (err != nil) {
+						return err;
+					}
+					definitions = append (definitions, fmt.Sprintf ("var needed%s: UInt64 = 0", param.ParamName));
+					allocations = append (allocations, fmt.Sprintf ("var buffer%s = [%s](repeating: %s, count: Int(needed%s))", param.ParamName, basicType, getSwiftZeroValue (component, param.ParamClass, ""), param.ParamName));
+					queries = []string {"0", "&needed" + param.ParamName, "nil"};
+					args = []string {fmt.Sprintf ("UInt64(buffer%s.count)", param.ParamName), "&needed" + param.ParamName, "&buffer" + param.ParamName};
+					resultValue = fmt.Sprintf ("Array(buffer%s.prefix(Int(needed%s)))", param.ParamName, param.ParamName);
+				case "structarray":
+					definitions = append (definitions, fmt.Sprintf ("var needed%s: UInt64 = 0", param.ParamName));
+					allocations = append (allocations, fmt.Sprintf ("var buffer%s = [s%s%s](repeating: s%s%s(), count: Int(needed%s))", param.ParamName, NameSpace, param.ParamClass, NameSpace, param.ParamClass, param.ParamName));
+					queries = []string {"0", "&needed" + param.ParamName, "nil"};
+					args = []string {fmt.Sprintf ("UInt64(buffer%s.count)", param.ParamName), "&needed" + param.ParamName, "&buffer" + param.ParamName};
+					throwsResults = true;
+					resultValue = fmt.Sprintf ("buffer%s.prefix(Int(needed%s)).map { try %s(cValue: $0) }", param.ParamName, param.ParamName, param.ParamClass);
+				case "handle":
+					definitions = append (definitions, fmt.Sprintf ("var h%s: UnsafeMutableRawPointer? = nil", param.ParamName));
+					args = []string {"&h" + param.ParamName};
+					queries = args;
+					resultValue = fmt.Sprintf ("h%s.map { %s(handle: $0) }", param.ParamName, param.ParamClass);
+				default:
+					return fmt.Errorf ("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName);
+			}
+			results = append (results, resultValue);
+		}
+		queryArgs = append (queryArgs, queries...);
+		callArgs = append (callArgs, args...);
+	}
+
+	returns := strings.Join (returnComments, ", ");
+	if (len (returnComments) > 1) {
+		returns = "a tuple of " + returns;
+	}
+	writeSwiftComment (w, indent, method.MethodDescription, comments, returns);
+
+	modifiers := "public";
+	if (isGlobal) {
+		modifiers = "public static";
+	} else if (isOverride) {
+		modifiers = "public override";
+	}
+	resultType := "";
+	if (result != "") {
+		resultType = " -> " + result;
+	}
+	w.Writeln (indent + "%s func %s(%s) throws%s {", modifiers, getSwiftSafeIdentifier (method.MethodName), parameters, resultType);
+	w.Writelns (indent + "  ", definitions);
+	if (len (allocations) > 0) {
+		w.Writeln (indent + "  try %s.check(%s(%s)%s)", Helpers, CMethodName, strings.Join (queryArgs, ", "), checkInstance);
+		w.Writelns (indent + "  ", allocations);
+	}
+	w.Writeln (indent + "  try %s.check(%s(%s)%s)", Helpers, CMethodName, strings.Join (callArgs, ", "), checkInstance);
+
+	// the results of enums and structs are converted by throwing functions
+	returnStatement := "return";
+	if (throwsResults) {
+		returnStatement = "return try";
+	}
+	if (len (results) == 1) {
+		w.Writeln (indent + "  %s %s", returnStatement, results[0]);
+	} else if (len (results) > 1) {
+		elements := [] string {};
+		index := 0;
+		for _, param := range method.Params {
+			if (param.ParamPass != "in") {
+				elements = append (elements, getSwiftSafeIdentifier (param.ParamName) + ": " + results[index]);
+				index++;
+			}
+		}
+		w.Writeln (indent + "  %s (%s)", returnStatement, strings.Join (elements, ", "));
+	}
+	w.Writeln (indent + "}");
+	return nil;
+}
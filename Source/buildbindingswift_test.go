@@ -0,0 +1,159 @@
+/*++
+
+Copyright (C) 2018 Autodesk Inc. (Original Author)
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+--*/
+
+
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+// buildbindingswift_test.go
+// Tests of the Swift package generator
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const swiftTestComponent = `<?xml version="1.0" encoding="UTF-8"?>
+<component xmlns="http://schemas.autodesk.com/netfabb/automaticcomponenttoolkit/2018" libraryname="Small Library" namespace="Small" copyright="Example" year="2024" basename="small" version="1.0.0">
+	<license>
+		<line value="All rights reserved." />
+	</license>
+	<bindings>
+		<binding language="Swift" indentation="4spaces" />
+	</bindings>
+	<errors>
+		<error name="NOTIMPLEMENTED" code="1" description="functionality not implemented" />
+		<error name="INVALIDPARAM" code="2" description="an invalid parameter was passed" />
+		<error name="INVALIDCAST" code="3" description="a type cast failed" />
+		<error name="BUFFERTOOSMALL" code="4" description="a provided buffer is too small" />
+		<error name="GENERICEXCEPTION" code="5" description="a generic exception occurred" />
+		<error name="COULDNOTLOADLIBRARY" code="6" description="the library could not be loaded" />
+		<error name="COULDNOTFINDLIBRARYEXPORT" code="7" description="a required exported symbol could not be found in the library" />
+	</errors>
+	<class name="Base" description="The base class">
+	</class>
+	<class name="Counter" parent="Base" description="A counter">
+		<method name="GetName" description="Returns the name">
+			<param name="Name" type="string" pass="return" description="The name" />
+		</method>
+		<method name="Increase" description="Increases the counter">
+			<param name="Step" type="uint32" pass="in" description="The step" />
+			<param name="Value" type="uint64" pass="return" description="The new value" />
+		</method>
+	</class>
+	<global baseclassname="Base" releasemethod="ReleaseInstance" versionmethod="GetVersion">
+		<method name="ReleaseInstance" description="Releases an instance">
+			<param name="Instance" type="handle" class="Base" pass="in" description="The instance" />
+		</method>
+		<method name="GetVersion" description="Returns the version">
+			<param name="Major" type="uint32" pass="out" description="The major version" />
+			<param name="Minor" type="uint32" pass="out" description="The minor version" />
+			<param name="Micro" type="uint32" pass="out" description="The micro version" />
+		</method>
+		<method name="CreateCounter" description="Creates a counter">
+			<param name="Instance" type="handle" class="Counter" pass="return" description="The counter" />
+		</method>
+	</global>
+</component>
+`
+
+func buildSwiftTestPackage(t *testing.T) (string) {
+	component, err := UnmarshalComponentDefinition([]byte(swiftTestComponent), eComponentDefinitionFormatXML)
+	if (err != nil) {
+		t.Fatal(err)
+	}
+	err = CheckComponentDefinition(component)
+	if (err != nil) {
+		t.Fatal(err)
+	}
+	outputFolder := t.TempDir()
+	err = BuildBindingSwift(component, outputFolder, "    ")
+	if (err != nil) {
+		t.Fatal(err)
+	}
+	return outputFolder
+}
+
+func checkSwiftTestFile(t *testing.T, fileName string, expected []string) {
+	data, err := ioutil.ReadFile(fileName)
+	if (err != nil) {
+		t.Fatal(err)
+	}
+	for _, line := range expected {
+		if !strings.Contains(string(data), line) {
+			t.Errorf("%s does not contain %q:\n%s", filepath.Base(fileName), line, data)
+		}
+	}
+}
+
+func TestSwiftPackageManifest(t *testing.T) {
+	outputFolder := buildSwiftTestPackage(t)
+	checkSwiftTestFile(t, filepath.Join(outputFolder, "Package.swift"), []string{
+		"// swift-tools-version:5.3",
+		"name: \"Small\",",
+		".library(name: \"Small\", targets: [\"Small\"]),",
+		".systemLibrary(name: \"CSmall\", path: \"Sources/CSmall\"),",
+		".target(name: \"Small\", dependencies: [\"CSmall\"], path: \"Sources/Small\"),",
+	})
+}
+
+func TestSwiftModuleMap(t *testing.T) {
+	outputFolder := buildSwiftTestPackage(t)
+	moduleFolder := filepath.Join(outputFolder, "Sources", "CSmall")
+	checkSwiftTestFile(t, filepath.Join(moduleFolder, "module.modulemap"), []string{
+		"module CSmall [system] {",
+		"header \"small.h\"",
+		"link \"small\"",
+		"export *",
+	})
+	for _, fileName := range []string{"small.h", "small_types.h"} {
+		_, err := ioutil.ReadFile(filepath.Join(moduleFolder, fileName))
+		if (err != nil) {
+			t.Error(err)
+		}
+	}
+}
+
+func TestSwiftWrapper(t *testing.T) {
+	outputFolder := buildSwiftTestPackage(t)
+	checkSwiftTestFile(t, filepath.Join(outputFolder, "Sources", "Small", "Small.swift"), []string{
+		"import CSmall",
+		"public enum SmallError: Swift.Error, CustomStringConvertible {",
+		"public class Counter: Base {",
+		"public func getName() throws -> String {",
+		"try SmallHelpers.check(small_counter_getname(handle, 0, &neededName, nil))",
+		"var bufferName = [CChar](repeating: 0, count: Int(neededName) + 1)",
+		"try SmallHelpers.check(small_counter_getname(handle, UInt32(bufferName.count), &neededName, &bufferName))",
+		"public func increase(step: UInt32) throws -> UInt64 {",
+		"public static func createCounter() throws -> Counter? {",
+	})
+}
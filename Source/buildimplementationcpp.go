@@ -42,9 +42,7 @@ import (
 )
 
 // BuildImplementationCPP builds C++ interface classes, implementation stubs and wrapper code that maps to the C-header
-func BuildImplementationCPP(component ComponentDefinition, outputFolder string, stubOutputFolder string, projectOutputFolder string, implementation ComponentDefinitionImplementation) error {
-	forceRecreation := false
-
+func BuildImplementationCPP(component ComponentDefinition, outputFolder string, stubOutputFolder string, projectOutputFolder string, implementation ComponentDefinitionImplementation, forceRecreation ForceRecreation) error {
 	doJournal := len (component.Global.JournalMethod) > 0;
 	
 	namespace := component.NameSpace;
@@ -63,9 +61,12 @@ func BuildImplementationCPP(component ComponentDefinition, outputFolder string,
 	if err != nil {
 		return err
 	}
-	hInternalExceptionHeaderFile.WriteCLicenseHeader(component,
+	err = hInternalExceptionHeaderFile.WriteCLicenseHeader(component,
 		fmt.Sprintf("This is an autogenerated C++ Header file with the basic internal\n exception type in order to allow an easy use of %s", libraryname),
 		true)
+	if err != nil {
+		return err
+	}
 
 	IntfExceptionImplName := path.Join(outputFolder, baseName+"_interfaceexception.cpp");
 	log.Printf("Creating \"%s\"", IntfExceptionImplName)
@@ -73,9 +74,12 @@ func BuildImplementationCPP(component ComponentDefinition, outputFolder string,
 	if err != nil {
 		return err
 	}
-	hInternalExceptionImplFile.WriteCLicenseHeader(component,
+	err = hInternalExceptionImplFile.WriteCLicenseHeader(component,
 		fmt.Sprintf("This is an autogenerated C++ Implementation file with the basic internal\n exception type in order to allow an easy use of %s", libraryname),
 		true)
+	if err != nil {
+		return err
+	}
 
 	err = buildCPPInternalException(hInternalExceptionHeaderFile, hInternalExceptionImplFile, namespace, baseName )
 	if err != nil {
@@ -88,9 +92,12 @@ func BuildImplementationCPP(component ComponentDefinition, outputFolder string,
 	if err != nil {
 		return err
 	}
-	interfaceshppfile.WriteCLicenseHeader(component,
+	err = interfaceshppfile.WriteCLicenseHeader(component,
 		fmt.Sprintf("This is an autogenerated C++ header file in order to allow easy\ndevelopment of %s. The implementer of %s needs to\nderive concrete classes from the abstract classes in this header.", libraryname, libraryname),
 		true)
+	if err != nil {
+		return err
+	}
 	err = buildCPPInterfaces(component, interfaceshppfile, namespace, implementation.ClassIdentifier, baseName)
 	if err != nil {
 		return err
@@ -102,9 +109,12 @@ func BuildImplementationCPP(component ComponentDefinition, outputFolder string,
 	if err != nil {
 		return err
 	}
-	cppWrapperfile.WriteCLicenseHeader(component,
+	err = cppWrapperfile.WriteCLicenseHeader(component,
 		fmt.Sprintf("This is an autogenerated C++ implementation file in order to allow easy\ndevelopment of %s. The functions in this file need to be implemented. It needs to be generated only once.", libraryname),
 		true)
+	if err != nil {
+		return err
+	}
 	err = buildCPPInterfaceWrapper(component, cppWrapperfile, namespace, implementation.ClassIdentifier, baseName, doJournal)
 	if err != nil {
 		return err
@@ -117,9 +127,12 @@ func BuildImplementationCPP(component ComponentDefinition, outputFolder string,
 		if err != nil {
 			return err
 		}
-		interfacejournalhppfile.WriteCLicenseHeader(component,
+		err = interfacejournalhppfile.WriteCLicenseHeader(component,
 			fmt.Sprintf("This is an autogenerated C++ header file in order to allow easy\ndevelopment of %s. It provides an automatic Journaling mechanism for the library implementation.", libraryname),
 			true)
+		if err != nil {
+			return err
+		}
 		
 		IntfJournalImplName := path.Join(outputFolder, baseName+"_interfacejournal.cpp");
 		log.Printf("Creating \"%s\"", IntfJournalImplName)
@@ -127,9 +140,12 @@ func BuildImplementationCPP(component ComponentDefinition, outputFolder string,
 		if err != nil {
 			return err
 		}
-		interfacejournalcppfile.WriteCLicenseHeader(component,
+		err = interfacejournalcppfile.WriteCLicenseHeader(component,
 			fmt.Sprintf("This is an autogenerated C++ implementation file in order to allow easy\ndevelopment of %s. It provides an automatic Journaling mechanism for the library implementation.", libraryname),
 			true)
+		if err != nil {
+			return err
+		}
 		
 		err = buildJournalingCPP(component, interfacejournalhppfile, interfacejournalcppfile)
 		if err != nil {
@@ -137,21 +153,28 @@ func BuildImplementationCPP(component ComponentDefinition, outputFolder string,
 		}
 	}
 
-	err = buildCPPStub(component, namespace, implementation.ClassIdentifier, baseName, stubOutputFolder, indentString, stubIdentifier, forceRecreation)
+	err = buildCPPStub(component, namespace, implementation.ClassIdentifier, baseName, stubOutputFolder, indentString, stubIdentifier, forceRecreation.Stubs)
 	if err != nil {
 		return err
 	}
 
 	IntfWrapperStubName := path.Join(stubOutputFolder, baseName + stubIdentifier + ".cpp")
-	if forceRecreation || (!FileExists(IntfWrapperStubName) ) {
+	if forceRecreation.Stubs || (!FileExists(IntfWrapperStubName) ) {
+		err = BackupFile(IntfWrapperStubName)
+		if err != nil {
+			return err
+		}
 		log.Printf("Creating \"%s\"", IntfWrapperStubName)
 		stubfile, err := CreateLanguageFile (IntfWrapperStubName, indentString)
 		if err != nil {
 			return err
 		}
-		stubfile.WriteCLicenseHeader(component,
+		err = stubfile.WriteCLicenseHeader(component,
 			fmt.Sprintf("This is an autogenerated C++ implementation file in order to allow easy\ndevelopment of %s. It needs to be generated only once.", libraryname),
 			true)
+		if err != nil {
+			return err
+		}
 		
 		err = buildCPPGlobalStubFile(component, stubfile, namespace, implementation.ClassIdentifier, baseName)
 		if err != nil {
@@ -163,16 +186,26 @@ func BuildImplementationCPP(component ComponentDefinition, outputFolder string,
 
 	if ( len(projectOutputFolder) > 0 ) {
 		CMakeListsFileName := path.Join(projectOutputFolder, "CMakeLists.txt");
-		if forceRecreation || !FileExists(CMakeListsFileName) {
+		if forceRecreation.Projects || !FileExists(CMakeListsFileName) {
+			err = BackupFile(CMakeListsFileName)
+			if err != nil {
+				return err
+			}
 			log.Printf("Creating CMake-Project \"%s\" for CPP Implementation", CMakeListsFileName)
 			CMakeListsFile, err := CreateLanguageFile(CMakeListsFileName, indentString)
 			if err != nil {
 				return err
 			}
-			CMakeListsFile.WriteCMakeLicenseHeader(component,
+			err = CMakeListsFile.WriteCMakeLicenseHeader(component,
 				fmt.Sprintf("This is an autogenerated CMakeLists file for the development of %s.", libraryname),
 				true)
-			buildCMakeForCPPImplementation(component, CMakeListsFile, doJournal)
+			if err != nil {
+				return err
+			}
+			err = buildCMakeForCPPImplementation(component, CMakeListsFile, doJournal)
+			if err != nil {
+				return err
+			}
 		} else {
 			log.Printf("Omitting recreation of CMake-Project \"%s\" for CPP Implementation", CMakeListsFileName)
 		}
@@ -188,6 +221,7 @@ func buildCPPInternalException (wHeader LanguageWriter, wImpl LanguageWriter, Na
 
 	wHeader.Writeln("#include <exception>");
 	wHeader.Writeln("#include <stdexcept>");
+	wHeader.Writeln("#include <string>");
 
 	wHeader.Writeln("#include \"%s_types.h\"", BaseName);
 	wHeader.Writeln("");
@@ -215,13 +249,18 @@ func buildCPPInternalException (wHeader LanguageWriter, wImpl LanguageWriter, Na
 	wHeader.Writeln("  E%sInterfaceException (%sResult errorCode);", NameSpace, NameSpace);
 	wHeader.Writeln("");
 	wHeader.Writeln("  /**");
+	wHeader.Writeln("  * Exception Constructor with an error message.");
+	wHeader.Writeln("  */");
+	wHeader.Writeln("  E%sInterfaceException (%sResult errorCode, const std::string & errorMessage);", NameSpace, NameSpace);
+	wHeader.Writeln("");
+	wHeader.Writeln("  /**");
 	wHeader.Writeln("  * Returns error code");
 	wHeader.Writeln("  */");
 	wHeader.Writeln("  %sResult getErrorCode ();", NameSpace);
 	wHeader.Writeln("  /**");
 	wHeader.Writeln("  * Returns error message");
 	wHeader.Writeln("  */");
-	wHeader.Writeln("  const char* what () const;");
+	wHeader.Writeln("  const char* what () const noexcept;");
 	wHeader.Writeln("};");
 	wHeader.Writeln("");
 	
@@ -243,12 +282,18 @@ func buildCPPInternalException (wHeader LanguageWriter, wImpl LanguageWriter, Na
 	wImpl.Writeln("  m_errorCode = errorCode;");
 	wImpl.Writeln("}");
 	wImpl.Writeln("");
+	wImpl.Writeln("E%sInterfaceException::E%sInterfaceException(%sResult errorCode, const std::string & errorMessage)", NameSpace, NameSpace, NameSpace);
+	wImpl.Writeln("  : m_errorMessage(errorMessage)");
+	wImpl.Writeln("{");
+	wImpl.Writeln("  m_errorCode = errorCode;");
+	wImpl.Writeln("}");
+	wImpl.Writeln("");
 	wImpl.Writeln("%sResult E%sInterfaceException::getErrorCode ()", NameSpace, NameSpace);
 	wImpl.Writeln("{");
 	wImpl.Writeln("  return m_errorCode;");
 	wImpl.Writeln("}");
 	wImpl.Writeln("");
-	wImpl.Writeln("const char * E%sInterfaceException::what () const", NameSpace);
+	wImpl.Writeln("const char * E%sInterfaceException::what () const noexcept", NameSpace);
 	wImpl.Writeln("{");
 	wImpl.Writeln("  return m_errorMessage.c_str();");
 	wImpl.Writeln("}");
@@ -288,8 +333,46 @@ func buildCPPInterfaces(component ComponentDefinition, w LanguageWriter, NameSpa
 	w.Writeln("**************************************************************************************************************************/")
 	w.Writeln("")
 	w.Writeln("class I%s%sBaseClass {", ClassIdentifier, NameSpace)
+	if (component.Global.ErrorMethod != "") {
+		w.Writeln("private:")
+		w.Writeln("  std::string m_sLastErrorMessage;")
+		w.Writeln("  bool m_bHasLastError = false;")
+		w.Writeln("")
+	}
 	w.Writeln("public:")
 	w.Writeln("  virtual ~I%s%sBaseClass () {}", ClassIdentifier, NameSpace)
+	if (component.Global.ErrorMethod != "") {
+		w.Writeln("")
+		w.Writeln("  /**")
+		w.Writeln("  * I%s%sBaseClass::setLastErrorMessage - Stores the message of the last error of the instance.", ClassIdentifier, NameSpace)
+		w.Writeln("  * @param[in] sErrorMessage - the error message")
+		w.Writeln("  */")
+		w.Writeln("  void setLastErrorMessage (const std::string & sErrorMessage)")
+		w.Writeln("  {")
+		w.Writeln("    m_sLastErrorMessage = sErrorMessage;")
+		w.Writeln("    m_bHasLastError = true;")
+		w.Writeln("  }")
+		w.Writeln("")
+		w.Writeln("  /**")
+		w.Writeln("  * I%s%sBaseClass::getLastErrorMessage - Returns the message of the last error of the instance.", ClassIdentifier, NameSpace)
+		w.Writeln("  * @param[out] sErrorMessage - the error message")
+		w.Writeln("  * @return whether an error message is available")
+		w.Writeln("  */")
+		w.Writeln("  bool getLastErrorMessage (std::string & sErrorMessage)")
+		w.Writeln("  {")
+		w.Writeln("    sErrorMessage = m_sLastErrorMessage;")
+		w.Writeln("    return m_bHasLastError;")
+		w.Writeln("  }")
+		w.Writeln("")
+		w.Writeln("  /**")
+		w.Writeln("  * I%s%sBaseClass::clearLastErrorMessage - Clears the message of the last error of the instance.", ClassIdentifier, NameSpace)
+		w.Writeln("  */")
+		w.Writeln("  void clearLastErrorMessage ()")
+		w.Writeln("  {")
+		w.Writeln("    m_sLastErrorMessage.clear ();")
+		w.Writeln("    m_bHasLastError = false;")
+		w.Writeln("  }")
+	}
 	w.Writeln("};")
 	w.Writeln("")
 
@@ -333,6 +416,10 @@ func buildCPPInterfaces(component ComponentDefinition, w LanguageWriter, NameSpa
 	global := component.Global;
 	for j := 0; j < len(global.Methods); j++ {
 		method := global.Methods[j]
+		// The wrapper answers the error method itself
+		if (method.MethodName == global.ErrorMethod) {
+			continue
+		}
 
 		methodstring, _, err := buildCPPInterfaceMethodDeclaration(method, BaseName, NameSpace, ClassIdentifier, "Wrapper", w.IndentString, true, false, true)
 		if err != nil {
@@ -361,6 +448,9 @@ func buildCPPGlobalStubFile(component ComponentDefinition, stubfile LanguageWrit
 
 	for j := 0; j < len(component.Global.Methods); j++ {
 		method := component.Global.Methods[j]
+		if (method.MethodName == component.Global.ErrorMethod) {
+			continue
+		}
 
 		_, implementationdeclaration, err := buildCPPInterfaceMethodDeclaration(method, "Wrapper", NameSpace, ClassIdentifier, BaseName, stubfile.IndentString, true, false, false)
 		if err != nil {
@@ -394,6 +484,34 @@ func buildCPPInterfaceWrapper(component ComponentDefinition, w LanguageWriter, N
 		w.Writeln("P%sInterfaceJournal m_GlobalJournal;", NameSpace)
 		w.Writeln("")
 	}
+
+	doErrorMessages := (component.Global.ErrorMethod != "")
+	if (doErrorMessages) {
+		w.Writeln("/*************************************************************************************************************************")
+		w.Writeln(" Error messages")
+		w.Writeln("**************************************************************************************************************************/")
+		w.Writeln("")
+		w.Writeln("// The message of the last error of a global function in the calling thread")
+		w.Writeln("static thread_local std::string g_sLastErrorMessage;")
+		w.Writeln("static thread_local bool g_bHasLastError = false;")
+		w.Writeln("")
+		w.Writeln("static %sResult handle%sException (I%s%sBaseClass * pIBaseClass, %sResult errorCode, const char * pErrorMessage)", NameSpace, NameSpace, ClassIdentifier, NameSpace, NameSpace)
+		w.Writeln("{")
+		w.Writeln("  try {")
+		w.Writeln("    if (pIBaseClass != nullptr) {")
+		w.Writeln("      pIBaseClass->setLastErrorMessage (pErrorMessage);")
+		w.Writeln("    }")
+		w.Writeln("    else {")
+		w.Writeln("      g_sLastErrorMessage = pErrorMessage;")
+		w.Writeln("      g_bHasLastError = true;")
+		w.Writeln("    }")
+		w.Writeln("  }")
+		w.Writeln("  catch (...) {")
+		w.Writeln("  }")
+		w.Writeln("  return errorCode;")
+		w.Writeln("}")
+		w.Writeln("")
+	}
 	
 	w.Writeln("extern \"C\" {")
 	w.Writeln("")
@@ -408,7 +526,7 @@ func buildCPPInterfaceWrapper(component ComponentDefinition, w LanguageWriter, N
 
 		for j := 0; j < len(class.Methods); j++ {
 			method := class.Methods[j]
-			err := writeCImplementationMethod(method, w, BaseName, NameSpace, ClassIdentifier, class.ClassName, false, doJournal, eSpecialMethodNone)
+			err := writeCImplementationMethod(method, w, BaseName, NameSpace, ClassIdentifier, class.ClassName, false, doJournal, doErrorMessages, eSpecialMethodNone)
 			if err != nil {
 				return err
 			}
@@ -436,7 +554,7 @@ func buildCPPInterfaceWrapper(component ComponentDefinition, w LanguageWriter, N
 		}
 
 		// Write Static function implementation
-		err = writeCImplementationMethod(method, w, BaseName, NameSpace, ClassIdentifier, "Wrapper", true, doMethodJournal, isSpecialFunction)
+		err = writeCImplementationMethod(method, w, BaseName, NameSpace, ClassIdentifier, "Wrapper", true, doMethodJournal, doErrorMessages, isSpecialFunction)
 		if err != nil {
 			return err
 		}
@@ -448,7 +566,7 @@ func buildCPPInterfaceWrapper(component ComponentDefinition, w LanguageWriter, N
 	return nil
 }
 
-func writeCImplementationMethod(method ComponentDefinitionMethod, w LanguageWriter, BaseName string, NameSpace string, ClassIdentifier string, ClassName string, isGlobal bool, doJournal bool, isSpecialFunction int) error {
+func writeCImplementationMethod(method ComponentDefinitionMethod, w LanguageWriter, BaseName string, NameSpace string, ClassIdentifier string, ClassName string, isGlobal bool, doJournal bool, doErrorMessages bool, isSpecialFunction int) error {
 	indentString := w.IndentString
 	CMethodName := ""
 	cParams, err := GenerateCParameters(method, ClassName, NameSpace)
@@ -480,6 +598,27 @@ func writeCImplementationMethod(method ComponentDefinitionMethod, w LanguageWrit
 	if err != nil {
 		return err
 	}
+
+	if (isSpecialFunction == eSpecialMethodError) {
+		// The instance of the error method may be null, so it is not cast like other handles
+		errorMethod := method
+		errorMethod.Params = method.Params[1:]
+		var hasErrorVariable string
+		checkInputCPPFunctionCode, preCallCPPFunctionCode, postCallCPPFunctionCode, hasErrorVariable, _, err = generatePrePostCallCPPFunctionCode(errorMethod, NameSpace, ClassIdentifier, ClassName, w.IndentString)
+		if err != nil {
+			return err
+		}
+		instanceName := method.Params[0].ParamName
+		messageVariable := getCppVariableName(method.Params[1])
+		callCPPFunctionCode = fmt.Sprintf(indentString + indentString + "I%s%sBaseClass* pIBaseClass%s = (I%s%sBaseClass *)p%s;\n", ClassIdentifier, NameSpace, instanceName, ClassIdentifier, NameSpace, instanceName) +
+			fmt.Sprintf(indentString + indentString + "if (pIBaseClass%s != nullptr) {\n", instanceName) +
+			fmt.Sprintf(indentString + indentString + indentString + "%s = pIBaseClass%s->getLastErrorMessage (%s);\n", hasErrorVariable, instanceName, messageVariable) +
+			indentString + indentString + "}\n" +
+			indentString + indentString + "else {\n" +
+			fmt.Sprintf(indentString + indentString + indentString + "%s = g_sLastErrorMessage;\n", messageVariable) +
+			fmt.Sprintf(indentString + indentString + indentString + "%s = g_bHasLastError;\n", hasErrorVariable) +
+			indentString + indentString + "}\n";
+	}
 	
 	
 	if (isSpecialFunction == eSpecialMethodNone || isSpecialFunction == eSpecialMethodRelease || isSpecialFunction == eSpecialMethodVersion) {
@@ -527,6 +666,18 @@ func writeCImplementationMethod(method ComponentDefinitionMethod, w LanguageWrit
 		w.Writeln("%s", journalInitFunctionCode)
 	}
 
+	// Every call clears the error message of a previous call, which the error method would return otherwise
+	if (doErrorMessages) && (isSpecialFunction != eSpecialMethodError) {
+		if isGlobal {
+			w.Writeln("    g_sLastErrorMessage.clear ();")
+			w.Writeln("    g_bHasLastError = false;")
+		} else {
+			w.Writeln("    if (p%s != nullptr)", ClassName)
+			w.Writeln("      ((I%s%sBaseClass *)p%s)->clearLastErrorMessage ();", ClassIdentifier, NameSpace, ClassName)
+		}
+		w.Writeln("")
+	}
+
 	w.Writeln("%s", checkInputCPPFunctionCode)
 	w.Writeln("%s", preCallCPPFunctionCode)
 	w.Writeln("%s", callCPPFunctionCode)
@@ -544,16 +695,39 @@ func writeCImplementationMethod(method ComponentDefinitionMethod, w LanguageWrit
 		w.Writeln("    if (pJournalEntry.get() != nullptr)");
 		w.Writeln("      pJournalEntry->writeError(E.getErrorCode());");
 	}
-	w.Writeln("    return E.getErrorCode();")
-	w.Writeln("  }")
-	w.Writeln("  catch (...) {")
-	if (doJournal) {
-		w.Writeln("    if (pJournalEntry.get() != nullptr)");
-		w.Writeln("      pJournalEntry->writeError(%s_ERROR_GENERICEXCEPTION);", strings.ToUpper(NameSpace));
+	// Failures of the error method do not overwrite the error message it returns
+	if (!doErrorMessages) || (isSpecialFunction == eSpecialMethodError) {
+		w.Writeln("    return E.getErrorCode();")
+		w.Writeln("  }")
+		w.Writeln("  catch (...) {")
+		if (doJournal) {
+			w.Writeln("    if (pJournalEntry.get() != nullptr)");
+			w.Writeln("      pJournalEntry->writeError(%s_ERROR_GENERICEXCEPTION);", strings.ToUpper(NameSpace));
+		}
+		w.Writeln("    return %s_ERROR_GENERICEXCEPTION;", strings.ToUpper(NameSpace))
+		w.Writeln("  }")
+	} else {
+		instanceReference := "nullptr"
+		if (!isGlobal) {
+			instanceReference = fmt.Sprintf("(I%s%sBaseClass *)p%s", ClassIdentifier, NameSpace, ClassName)
+		}
+		w.Writeln("    return handle%sException(%s, E.getErrorCode(), E.what());", NameSpace, instanceReference)
+		w.Writeln("  }")
+		w.Writeln("  catch (std::exception & E) {")
+		if (doJournal) {
+			w.Writeln("    if (pJournalEntry.get() != nullptr)");
+			w.Writeln("      pJournalEntry->writeError(%s_ERROR_GENERICEXCEPTION);", strings.ToUpper(NameSpace));
+		}
+		w.Writeln("    return handle%sException(%s, %s_ERROR_GENERICEXCEPTION, E.what());", NameSpace, instanceReference, strings.ToUpper(NameSpace))
+		w.Writeln("  }")
+		w.Writeln("  catch (...) {")
+		if (doJournal) {
+			w.Writeln("    if (pJournalEntry.get() != nullptr)");
+			w.Writeln("      pJournalEntry->writeError(%s_ERROR_GENERICEXCEPTION);", strings.ToUpper(NameSpace));
+		}
+		w.Writeln("    return handle%sException(%s, %s_ERROR_GENERICEXCEPTION, \"unknown exception\");", NameSpace, instanceReference, strings.ToUpper(NameSpace))
+		w.Writeln("  }")
 	}
-	
-	w.Writeln("    return %s_ERROR_GENERICEXCEPTION;", strings.ToUpper(NameSpace))
-	w.Writeln("  }")
 
 	w.Writeln("}")
 	w.Writeln("")
@@ -573,24 +747,38 @@ func buildCPPStub(component ComponentDefinition, NameSpace string, ClassIdentifi
 			log.Printf("Omitting recreation of Stub implementation for \"%s\"", outClassName)
 			continue;
 		}
+		err := BackupFile(StubHeaderFileName)
+		if err != nil {
+			return err
+		}
+		err = BackupFile(StubImplFileName)
+		if err != nil {
+			return err
+		}
 
 		log.Printf("Creating \"%s\"", StubHeaderFileName)
 		stubheaderw, err := CreateLanguageFile(StubHeaderFileName, indentString)
 		if err != nil {
 			return err
 		}
-		stubheaderw.WriteCLicenseHeader(component,
+		err = stubheaderw.WriteCLicenseHeader(component,
 			fmt.Sprintf("This is the class declaration of %s", outClassName),
 			false)
+		if err != nil {
+			return err
+		}
 		
 		log.Printf("Creating \"%s\"", StubImplFileName)
 		stubimplw, err := CreateLanguageFile(StubImplFileName, indentString)
 		if err != nil {
 			return err
 		}
-		stubimplw.WriteCLicenseHeader(component,
+		err = stubimplw.WriteCLicenseHeader(component,
 			fmt.Sprintf("This is a stub class definition of %s", outClassName),
 			false)
+		if err != nil {
+			return err
+		}
 
 		stubheaderw.Writeln("")
 		stubheaderw.Writeln("#ifndef __%s_%s%s", strings.ToUpper(NameSpace), strings.ToUpper(NameSpace), strings.ToUpper(class.ClassName))
@@ -1294,47 +1482,10 @@ func generateJournalFunctionCode (method ComponentDefinitionMethod, NameSpace st
 
 }
 
-func buildCMakeForCPPImplementation(component ComponentDefinition, w LanguageWriter, doJournal bool) {
-	NameSpace := component.NameSpace
-	
-	w.Writeln("cmake_minimum_required(VERSION 3.5)")
-	w.Writeln("")
-
-	w.Writeln("### The implementation of the %s component", component.LibraryName)
-	w.Writeln("project(%s)", NameSpace)
-	w.Writeln("")
-	w.Writeln("set (CMAKE_CXX_STANDARD 11)")
-	w.Writeln("")
-	
-	w.Writeln("# The location of autogenerated interfaces")
-	w.Writeln("set(CMAKE_CURRENT_AUTOGENERATED_DIR ${CMAKE_CURRENT_SOURCE_DIR}/Interfaces)",)
-	w.Writeln("")
-
-	w.Writeln("file(GLOB %s_SRC", strings.ToUpper(NameSpace))
-	w.Writeln("  ${CMAKE_CURRENT_SOURCE_DIR}/Stub/*.cpp")
-	w.Writeln(")")
-	w.Writeln("file(GLOB %s_HDR", strings.ToUpper(NameSpace))
-	w.Writeln("  ${CMAKE_CURRENT_SOURCE_DIR}/Stub/*.hpp")
-	w.Writeln(")")
-
-	w.Writeln("set(%s_SRC ${%s_SRC} ${%s_SRC}", strings.ToUpper(NameSpace), strings.ToUpper(NameSpace), strings.ToUpper(NameSpace))
-	w.Writeln("  ${CMAKE_CURRENT_AUTOGENERATED_DIR}/%s_interfaceexception.cpp", strings.ToLower(NameSpace))
-	w.Writeln("  ${CMAKE_CURRENT_AUTOGENERATED_DIR}/%s_interfacewrapper.cpp", strings.ToLower(NameSpace))
-	if (doJournal) {
-		w.Writeln("  ${CMAKE_CURRENT_AUTOGENERATED_DIR}/%s_interfacejournal.cpp", strings.ToLower(NameSpace))
-	}
-	w.Writeln(")")
-	w.Writeln("")
-
-	targetName := strings.ToLower(NameSpace)
-	w.Writeln("add_library(%s SHARED ${%s_SRC})", targetName, strings.ToUpper(NameSpace))
-	w.Writeln("# The following two properties are crucial to reduce the number of undesirably exported symbols")
-	w.Writeln("set_target_properties(%s PROPERTIES CXX_VISIBILITY_PRESET hidden)", targetName)
-	w.Writeln("set_target_properties(%s PROPERTIES VISIBILITY_INLINES_HIDDEN ON)", targetName)
-	w.Writeln("# This makes sure symbols are exported")
-	w.Writeln("target_compile_options(%s PRIVATE \"-D__%s_EXPORTS\")", targetName, strings.ToUpper(NameSpace))
-	w.Writeln("target_include_directories(%s PRIVATE ${CMAKE_CURRENT_AUTOGENERATED_DIR})", targetName)
-	w.Writeln("target_include_directories(%s PRIVATE ${CMAKE_CURRENT_SOURCE_DIR}/Stub)", targetName)
+func buildCMakeForCPPImplementation(component ComponentDefinition, w LanguageWriter, doJournal bool) error {
+	data := NewTemplateData(component)
+	data.DoJournal = doJournal
+	return w.WriteTemplate("cppimplementation.cmake", data)
 }
 
 // buildJournalingCPP generates Declaration and Implementation of the Journaling class
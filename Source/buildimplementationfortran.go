@@ -0,0 +1,174 @@
+/*++
+
+Copyright (C) 2018 Autodesk Inc. (Original Author)
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+--*/
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+// buildimplementationfortran.go
+// functions to generate the types module and the stub of a component that is implemented in Fortran.
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"strings"
+)
+
+// BuildImplementationFortran builds the types module and the stub module of a Fortran implementation
+func BuildImplementationFortran(component ComponentDefinition, outputFolder string, implementation ComponentDefinitionImplementation, forceRecreation ForceRecreation) error {
+	libraryname := component.LibraryName;
+	baseName := component.BaseName;
+	indentString := getIndentationString (implementation.Indentation);
+
+	TypesFileName := path.Join(outputFolder, baseName + "_types.f90");
+	log.Printf ("Creating \"%s\"", TypesFileName);
+	typesfile, err := CreateLanguageFile (TypesFileName, indentString);
+	if (err != nil) {
+		return err;
+	}
+	err = typesfile.WriteFortranLicenseHeader(component,
+		fmt.Sprintf ("This is an autogenerated Fortran file with the types of %s.", libraryname),
+		true)
+	if err != nil {
+		return err
+	}
+	err = buildFortranImplementationTypes (component, typesfile);
+	if (err != nil) {
+		return err;
+	}
+
+	StubFileName := path.Join(outputFolder, baseName + "_impl.f90");
+	if (!forceRecreation.Stubs) && FileExists(StubFileName) {
+		log.Printf("Omitting recreation of Stub implementation \"%s\"", StubFileName);
+		return nil;
+	}
+	err = BackupFile(StubFileName);
+	if (err != nil) {
+		return err;
+	}
+
+	log.Printf ("Creating \"%s\"", StubFileName);
+	stubfile, err := CreateLanguageFile (StubFileName, indentString);
+	if (err != nil) {
+		return err;
+	}
+	err = stubfile.WriteFortranLicenseHeader(component,
+		fmt.Sprintf ("This is the implementation of the exported functions of %s.\n It needs to be generated only once.", libraryname),
+		true)
+	if err != nil {
+		return err
+	}
+	return buildFortranImplementationStub (component, stubfile);
+}
+
+func buildFortranImplementationTypes (component ComponentDefinition, w LanguageWriter) (error) {
+	moduleName := strings.ToLower (component.NameSpace) + "_types";
+
+	w.Writeln ("");
+	w.Writeln ("module %s", moduleName);
+	w.Writeln ("  use, intrinsic :: iso_c_binding");
+	w.Writeln ("  implicit none");
+	w.Writeln ("");
+
+	err := writeFortranTypeDeclarations (component, w);
+	if (err != nil) {
+		return err;
+	}
+
+	if (len (component.Structs) > 0) {
+		w.Writeln ("");
+		w.Writeln ("contains");
+		w.Writeln ("");
+		err = writeFortranStructProcedures (component, w);
+		if (err != nil) {
+			return err;
+		}
+	}
+	w.Writeln ("end module %s", moduleName);
+	return nil;
+}
+
+func buildFortranImplementationStub (component ComponentDefinition, w LanguageWriter) (error) {
+	NameSpace := component.NameSpace;
+	upperNameSpace := strings.ToUpper (NameSpace);
+	moduleName := strings.ToLower (NameSpace) + "_impl";
+
+	w.Writeln ("");
+	w.Writeln ("! Every exported function receives the arguments of the C interface of %s.", component.LibraryName);
+	w.Writeln ("! Handles are opaque pointers, that the implementation maps to its instances with c_loc and c_f_pointer.");
+	w.Writeln ("module %s", moduleName);
+	w.Writeln ("  use, intrinsic :: iso_c_binding");
+	w.Writeln ("  use %s_types", strings.ToLower (NameSpace));
+	w.Writeln ("  implicit none");
+	w.Writeln ("  private");
+	w.Writeln ("");
+	w.Writeln ("contains");
+
+	writeStub := func (method ComponentDefinitionMethod, ClassName string, isGlobal bool) (error) {
+		dummies, err := getFortranCDummies (method, NameSpace, ClassName, isGlobal);
+		if (err != nil) {
+			return err;
+		}
+		CMethodName := GetCExportName (NameSpace, ClassName, method, isGlobal);
+
+		w.Writeln ("");
+		writeFortranComment (w, "  ", method.MethodDescription);
+		writeFortranCFunction (w, "  ", CMethodName, dummies, CMethodName);
+		w.Writeln ("");
+		if (isGlobal) && (method.MethodName == component.Global.VersionMethod) && (len (dummies) == 3) {
+			w.Writeln ("    %s = %s_VERSION_MAJOR", dummies[0].name, upperNameSpace);
+			w.Writeln ("    %s = %s_VERSION_MINOR", dummies[1].name, upperNameSpace);
+			w.Writeln ("    %s = %s_VERSION_MICRO", dummies[2].name, upperNameSpace);
+			w.Writeln ("    %s = %s_SUCCESS", CMethodName, upperNameSpace);
+		} else {
+			w.Writeln ("    %s = %s_ERROR_NOTIMPLEMENTED", CMethodName, upperNameSpace);
+		}
+		w.Writeln ("  end function %s", CMethodName);
+		return nil;
+	}
+
+	for _, class := range component.Classes {
+		for _, method := range class.Methods {
+			err := writeStub (method, class.ClassName, false);
+			if (err != nil) {
+				return err;
+			}
+		}
+	}
+	for _, method := range component.Global.Methods {
+		err := writeStub (method, "Wrapper", true);
+		if (err != nil) {
+			return err;
+		}
+	}
+
+	w.Writeln ("");
+	w.Writeln ("end module %s", moduleName);
+	return nil;
+}
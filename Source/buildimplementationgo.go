@@ -0,0 +1,1042 @@
+/*++
+
+Copyright (C) 2018 Autodesk Inc. (Original Author)
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+--*/
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+// buildimplementationgo.go
+// functions to generate the cgo exports, interfaces and stubs of a component that is implemented in Go.
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+)
+
+// BuildImplementationGo builds the cgo exports, the Go interfaces and the implementation stubs of a component.
+// All files form the package main of a Go module, that builds the library with "go build -buildmode=c-shared".
+func BuildImplementationGo(component ComponentDefinition, outputFolder string, implementation ComponentDefinitionImplementation, forceRecreation ForceRecreation) error {
+	libraryname := component.LibraryName;
+	baseName := component.BaseName;
+	NameSpace := component.NameSpace;
+	ClassIdentifier := implementation.ClassIdentifier;
+
+	GoModName := path.Join(outputFolder, "go.mod");
+	if forceRecreation.Projects || (!FileExists(GoModName)) {
+		err := BackupFile(GoModName);
+		if (err != nil) {
+			return err;
+		}
+		log.Printf ("Creating \"%s\"", GoModName);
+		gomodfile, err := os.Create(GoModName);
+		if (err != nil) {
+			return err;
+		}
+		defer gomodfile.Close();
+
+		fmt.Fprintf (gomodfile, "module %s\n", strings.ToLower (baseName));
+		fmt.Fprintf (gomodfile, "\n");
+		fmt.Fprintf (gomodfile, "go 1.13\n");
+	} else {
+		log.Printf("Omitting recreation of Go module \"%s\"", GoModName);
+	}
+
+	err := writeGoImplementationFile (path.Join(outputFolder, baseName + "_types.go"), component,
+		fmt.Sprintf ("This is an autogenerated Go file with the types of %s.", libraryname),
+		func (w io.Writer) (error) {
+			fmt.Fprintf (w, "\n");
+			fmt.Fprintf (w, "package main\n");
+			fmt.Fprintf (w, "\n");
+			fmt.Fprintf (w, "import (\n");
+			fmt.Fprintf (w, "    \"fmt\"\n");
+			fmt.Fprintf (w, ")\n");
+			fmt.Fprintf (w, "\n");
+			return writeGoTypeDeclarations (component, w, NameSpace);
+		});
+	if (err != nil) {
+		return err;
+	}
+
+	err = writeGoImplementationFile (path.Join(outputFolder, baseName + "_interfaces.go"), component,
+		fmt.Sprintf ("This is an autogenerated Go file with the interfaces that the classes of\n %s implement.", libraryname),
+		func (w io.Writer) (error) {
+			return buildGoImplementationInterfaces (component, w);
+		});
+	if (err != nil) {
+		return err;
+	}
+
+	err = writeGoImplementationFile (path.Join(outputFolder, baseName + "_exports.go"), component,
+		fmt.Sprintf ("This is an autogenerated Go file that exports the C functions of %s\n and maps them to the Go interfaces.", libraryname),
+		func (w io.Writer) (error) {
+			return buildGoImplementationExports (component, w, NameSpace);
+		});
+	if (err != nil) {
+		return err;
+	}
+
+	if (len (component.Functions) > 0) {
+		err = writeGoImplementationFile (path.Join(outputFolder, baseName + "_callbacks.go"), component,
+			fmt.Sprintf ("This is an autogenerated Go file that calls the callbacks, that are passed\n to %s.", libraryname),
+			func (w io.Writer) (error) {
+				return buildGoImplementationCallbacks (component, w, NameSpace);
+			});
+		if (err != nil) {
+			return err;
+		}
+	}
+
+	// Stub files end with "_impl.go", so that class names like "Test" or "Windows" do not turn them into test files or platform specific files
+	for i := 0; i < len(component.Classes); i++ {
+		class := component.Classes[i];
+		StubFileName := path.Join(outputFolder, baseName + "_" + strings.ToLower (class.ClassName) + "_impl.go");
+		err = writeGoImplementationStub (StubFileName, component, forceRecreation.Stubs,
+			fmt.Sprintf ("This is the class declaration of %s%s. It needs to be generated only once.", ClassIdentifier, class.ClassName),
+			func (w io.Writer) (error) {
+				return buildGoImplementationClassStub (component, class, w, ClassIdentifier);
+			});
+		if (err != nil) {
+			return err;
+		}
+	}
+
+	StubFileName := path.Join(outputFolder, baseName + "_impl.go");
+	return writeGoImplementationStub (StubFileName, component, forceRecreation.Stubs,
+		fmt.Sprintf ("This is the implementation of the global functions of %s. It needs to be generated only once.", libraryname),
+		func (w io.Writer) (error) {
+			return buildGoImplementationGlobalStub (component, w, ClassIdentifier);
+		});
+}
+
+// validateImplementationGo checks that the Go identifiers and files of the implementation are unique
+func validateImplementationGo (component ComponentDefinition, options GeneratorOptions) (error) {
+	for _, errorName := range []string {"NOTIMPLEMENTED", "INVALIDPARAM", "INVALIDCAST", "BUFFERTOOSMALL", "GENERICEXCEPTION"} {
+		found := false;
+		for _, errorcode := range component.Errors.Errors {
+			if (errorcode.Name == errorName) {
+				found = true;
+			}
+		}
+		if (!found) {
+			return fmt.Errorf ("the Go implementation requires the error \"%s\"", errorName);
+		}
+	}
+
+	reserved := []string {"BaseClass", "Wrapper", "ErrorCode", options.ClassIdentifier + "WrapperImpl"};
+	for _, class := range component.Classes {
+		reserved = append (reserved, options.ClassIdentifier + getGoIdentifier (class.ClassName) + "Impl");
+	}
+	return validateGoIdentifiers (component, reserved, "the Go implementation");
+}
+
+// writeGoImplementationFile creates a Go file of the implementation that is generated on every run
+func writeGoImplementationFile (fileName string, component ComponentDefinition, abstract string, build func (w io.Writer) (error)) (error) {
+	log.Printf ("Creating \"%s\"", fileName);
+	gofile, err := os.Create(fileName);
+	if (err != nil) {
+		return err;
+	}
+	defer gofile.Close();
+
+	err = WriteLicenseHeader(gofile, component, abstract, true)
+	if err != nil {
+		return err
+	}
+	return build (gofile);
+}
+
+// writeGoImplementationStub creates a Go stub file of the implementation, unless it exists already
+func writeGoImplementationStub (fileName string, component ComponentDefinition, forceRecreation bool, abstract string, build func (w io.Writer) (error)) (error) {
+	if (!forceRecreation) && FileExists(fileName) {
+		log.Printf("Omitting recreation of Stub implementation \"%s\"", fileName);
+		return nil;
+	}
+
+	err := BackupFile(fileName);
+	if (err != nil) {
+		return err;
+	}
+	return writeGoImplementationFile (fileName, component, abstract, build);
+}
+
+// getGoImplementationSignature returns the parameters, the return values, the return values of a stub
+// and the parameter comments of the Go method that implements a method of the IDL
+func getGoImplementationSignature (method ComponentDefinitionMethod, ClassName string) (string, string, string, string, error) {
+	parameters := "";
+	returnvalues := "";
+	stubreturnvalues := "";
+	comments := "";
+
+	for _, param := range method.Params {
+		goType := "";
+		prefix := "";
+		zeroValue := "";
+
+		switch (param.ParamType) {
+			case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "single", "double":
+				basicType, err := getGoBasicType (param.ParamType);
+				if (err != nil) {
+					return "", "", "", "", err;
+				}
+				goType = basicType;
+				prefix = "n";
+				if (param.ParamType == "single") {
+					prefix = "f";
+				}
+				if (param.ParamType == "double") {
+					prefix = "d";
+				}
+				zeroValue = "0";
+			case "bool":
+				goType = "bool";
+				prefix = "b";
+				zeroValue = "false";
+			case "string":
+				goType = "string";
+				prefix = "s";
+				zeroValue = "\"\"";
+			case "enum":
+				goType = getGoIdentifier (param.ParamClass);
+				prefix = "e";
+				zeroValue = "0";
+			case "struct":
+				goType = getGoIdentifier (param.ParamClass);
+				prefix = "s";
+				zeroValue = goType + " {}";
+			case "basicarray":
+				basicType, err := getGoBasicType (param.ParamClass);
+				if (err != nil) {
+					return "", "", "", "", err;
+				}
+				goType = "[]" + basicType;
+				zeroValue = "nil";
+			case "structarray":
+				goType = "[]" + getGoIdentifier (param.ParamClass);
+				zeroValue = "nil";
+			case "functiontype":
+				goType = getGoIdentifier (param.ParamClass);
+				prefix = "p";
+				zeroValue = "nil";
+			case "handle":
+				goType = getGoIdentifier (param.ParamClass);
+				zeroValue = "nil";
+			default:
+				return "", "", "", "", fmt.Errorf ("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName);
+		}
+
+		switch (param.ParamPass) {
+			case "in":
+				if (parameters != "") {
+					parameters = parameters + ", ";
+				}
+				parameters = parameters + prefix + param.ParamName + " " + goType;
+				comments = comments + fmt.Sprintf ("//   %s%s - %s\n", prefix, param.ParamName, param.ParamDescription);
+
+			case "out", "return":
+				if (param.ParamType == "functiontype") {
+					return "", "", "", "", fmt.Errorf ("method parameter type \"%s\" of param pass \"%s\" is not implemented for %s.%s (%s)", param.ParamType, param.ParamPass, ClassName, method.MethodName, param.ParamName);
+				}
+				returnvalues = returnvalues + goType + ", ";
+				stubreturnvalues = stubreturnvalues + zeroValue + ", ";
+				comments = comments + fmt.Sprintf ("//   returns %s\n", param.ParamDescription);
+
+			default:
+				return "", "", "", "", fmt.Errorf ("invalid method parameter passing \"%s\" for %s.%s (%s)", param.ParamPass, ClassName, method.MethodName, param.ParamName);
+		}
+	}
+
+	return parameters, returnvalues + "error", stubreturnvalues + "ErrorNOTIMPLEMENTED", comments, nil;
+}
+
+// isGoExportedGlobalMethod returns whether a global method is implemented by the exports instead of the Wrapper
+func isGoExportedGlobalMethod (global ComponentDefinitionGlobal, method ComponentDefinitionMethod) (bool) {
+	return (method.MethodName == global.ReleaseMethod) || (method.MethodName == global.ErrorMethod);
+}
+
+// writeGoImplementationMethodComment writes the comment of a method of an interface or a stub
+func writeGoImplementationMethodComment (w io.Writer, indent string, method ComponentDefinitionMethod, comments string) {
+	writeGoDocComment (w, indent, getGoIdentifier (method.MethodName), method.MethodDescription);
+	if (comments != "") && (method.MethodDescription != "") {
+		fmt.Fprintf (w, "%s//\n", indent);
+	}
+	fmt.Fprintf (w, "%s", strings.Replace (comments, "//", indent + "//", -1));
+}
+
+func buildGoImplementationInterfaces (component ComponentDefinition, w io.Writer) (error) {
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "package main\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "/*************************************************************************************************************************\n");
+	fmt.Fprintf (w, " Declaration of interfaces\n");
+	fmt.Fprintf (w, "**************************************************************************************************************************/\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// BaseClass is the base of all classes of %s.\n", component.LibraryName);
+	fmt.Fprintf (w, "// The consumers of the library refer to its instances by handles.\n");
+	fmt.Fprintf (w, "type BaseClass interface {\n");
+	fmt.Fprintf (w, "}\n");
+
+	for _, class := range component.Classes {
+		parentClass := class.ParentClass;
+		if (parentClass == "") {
+			parentClass = "BaseClass";
+		}
+
+		fmt.Fprintf (w, "\n");
+		fmt.Fprintf (w, "// %s is the interface of the class %s of %s.\n", getGoIdentifier (class.ClassName), class.ClassName, component.LibraryName);
+		if (class.ClassDescription != "") {
+			fmt.Fprintf (w, "// %s\n", strings.TrimSpace (class.ClassDescription));
+		}
+		fmt.Fprintf (w, "type %s interface {\n", getGoIdentifier (class.ClassName));
+		fmt.Fprintf (w, "    %s\n", getGoIdentifier (parentClass));
+
+		for _, method := range class.Methods {
+			parameters, returnvalues, _, comments, err := getGoImplementationSignature (method, class.ClassName);
+			if (err != nil) {
+				return err;
+			}
+			fmt.Fprintf (w, "\n");
+			writeGoImplementationMethodComment (w, "    ", method, comments);
+			fmt.Fprintf (w, "    %s (%s) (%s)\n", getGoIdentifier (method.MethodName), parameters, returnvalues);
+		}
+		fmt.Fprintf (w, "}\n");
+	}
+
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// Wrapper is the interface of the global functions of %s.\n", component.LibraryName);
+	fmt.Fprintf (w, "// The release method is implemented by the exports, that release the handle of the instance.\n");
+	if (component.Global.ErrorMethod != "") {
+		fmt.Fprintf (w, "// The error method is implemented by the exports, that store the messages of the returned errors.\n");
+	}
+	fmt.Fprintf (w, "type Wrapper interface {\n");
+	for _, method := range component.Global.Methods {
+		if (isGoExportedGlobalMethod (component.Global, method)) {
+			continue;
+		}
+		parameters, returnvalues, _, comments, err := getGoImplementationSignature (method, "Wrapper");
+		if (err != nil) {
+			return err;
+		}
+		fmt.Fprintf (w, "\n");
+		writeGoImplementationMethodComment (w, "    ", method, comments);
+		fmt.Fprintf (w, "    %s (%s) (%s)\n", getGoIdentifier (method.MethodName), parameters, returnvalues);
+	}
+	fmt.Fprintf (w, "}\n");
+
+	return nil;
+}
+
+func buildGoImplementationClassStub (component ComponentDefinition, class ComponentDefinitionClass, w io.Writer, ClassIdentifier string) (error) {
+	implName := ClassIdentifier + getGoIdentifier (class.ClassName) + "Impl";
+
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "package main\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// %s implements the class %s of %s.\n", implName, class.ClassName, component.LibraryName);
+	fmt.Fprintf (w, "type %s struct {\n", implName);
+	if (class.ParentClass != "") {
+		fmt.Fprintf (w, "    %s%sImpl\n", ClassIdentifier, getGoIdentifier (class.ParentClass));
+	}
+	fmt.Fprintf (w, "}\n");
+
+	for _, method := range class.Methods {
+		parameters, returnvalues, stubreturnvalues, comments, err := getGoImplementationSignature (method, class.ClassName);
+		if (err != nil) {
+			return err;
+		}
+		fmt.Fprintf (w, "\n");
+		writeGoImplementationMethodComment (w, "", method, comments);
+		fmt.Fprintf (w, "func (instance *%s) %s (%s) (%s) {\n", implName, getGoIdentifier (method.MethodName), parameters, returnvalues);
+		fmt.Fprintf (w, "    return %s;\n", stubreturnvalues);
+		fmt.Fprintf (w, "}\n");
+	}
+
+	return nil;
+}
+
+func buildGoImplementationGlobalStub (component ComponentDefinition, w io.Writer, ClassIdentifier string) (error) {
+	implName := ClassIdentifier + "WrapperImpl";
+
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "package main\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// %s implements the global functions of %s.\n", implName, component.LibraryName);
+	fmt.Fprintf (w, "type %s struct {\n", implName);
+	fmt.Fprintf (w, "}\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// wrapper is called by the exported global functions of %s.\n", component.LibraryName);
+	fmt.Fprintf (w, "var wrapper Wrapper = &%s {};\n", implName);
+
+	for _, method := range component.Global.Methods {
+		if (isGoExportedGlobalMethod (component.Global, method)) {
+			continue;
+		}
+		parameters, returnvalues, stubreturnvalues, comments, err := getGoImplementationSignature (method, "Wrapper");
+		if (err != nil) {
+			return err;
+		}
+		fmt.Fprintf (w, "\n");
+		writeGoImplementationMethodComment (w, "", method, comments);
+		fmt.Fprintf (w, "func (instance *%s) %s (%s) (%s) {\n", implName, getGoIdentifier (method.MethodName), parameters, returnvalues);
+		fmt.Fprintf (w, "    return %s;\n", stubreturnvalues);
+		fmt.Fprintf (w, "}\n");
+	}
+
+	return nil;
+}
+
+func buildGoImplementationExports (component ComponentDefinition, w io.Writer, NameSpace string) (error) {
+	doErrorMessages := (component.Global.ErrorMethod != "");
+	usesBuffers := (len (component.Structs) > 0);
+	for _, class := range component.Classes {
+		for _, method := range class.Methods {
+			for _, param := range method.Params {
+				if (param.ParamType == "basicarray") {
+					usesBuffers = true;
+				}
+			}
+		}
+	}
+	for _, method := range component.Global.Methods {
+		for _, param := range method.Params {
+			if (param.ParamType == "basicarray") {
+				usesBuffers = true;
+			}
+		}
+	}
+
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "package main\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "/*\n");
+	fmt.Fprintf (w, "#include <stdint.h>\n");
+	fmt.Fprintf (w, "#include <stdbool.h>\n");
+	fmt.Fprintf (w, "#include <string.h>\n");
+	fmt.Fprintf (w, "\n");
+	err := writeGoCTypes (component, w, NameSpace, "uintptr_t");
+	if (err != nil) {
+		return err;
+	}
+	fmt.Fprintf (w, "*/\n");
+	fmt.Fprintf (w, "import \"C\"\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "import (\n");
+	if (usesBuffers) {
+		fmt.Fprintf (w, "    \"bytes\"\n");
+		fmt.Fprintf (w, "    \"encoding/binary\"\n");
+	}
+	fmt.Fprintf (w, "    \"errors\"\n");
+	fmt.Fprintf (w, "    \"sync\"\n");
+	fmt.Fprintf (w, "    \"unsafe\"\n");
+	fmt.Fprintf (w, ")\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// main is required by the c-shared build mode, but never called.\n");
+	fmt.Fprintf (w, "func main () {\n");
+	fmt.Fprintf (w, "}\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "/*************************************************************************************************************************\n");
+	fmt.Fprintf (w, " Handle table\n");
+	fmt.Fprintf (w, "**************************************************************************************************************************/\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// handleTable maps the handles that are passed to the consumers of the library to the Go instances.\n");
+	fmt.Fprintf (w, "// The instances must not be passed to C directly, as the garbage collector may move or free them.\n");
+	fmt.Fprintf (w, "type handleTable struct {\n");
+	fmt.Fprintf (w, "    mutex sync.Mutex\n");
+	fmt.Fprintf (w, "    instances map[C.%sHandle]BaseClass\n", NameSpace);
+	fmt.Fprintf (w, "    lastHandle C.%sHandle\n", NameSpace);
+	if (doErrorMessages) {
+		fmt.Fprintf (w, "    // the messages of the last errors of the instances, and of the global functions at the handle 0\n");
+		fmt.Fprintf (w, "    errorMessages map[C.%sHandle]string\n", NameSpace);
+		fmt.Fprintf (w, "}\n");
+		fmt.Fprintf (w, "\n");
+		fmt.Fprintf (w, "var handles = handleTable {instances: make (map[C.%sHandle]BaseClass), errorMessages: make (map[C.%sHandle]string)};\n", NameSpace, NameSpace);
+	} else {
+		fmt.Fprintf (w, "}\n");
+		fmt.Fprintf (w, "\n");
+		fmt.Fprintf (w, "var handles = handleTable {instances: make (map[C.%sHandle]BaseClass)};\n", NameSpace);
+	}
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "func (table *handleTable) newHandle (instance BaseClass) (C.%sHandle) {\n", NameSpace);
+	fmt.Fprintf (w, "    if (instance == nil) {\n");
+	fmt.Fprintf (w, "        return 0;\n");
+	fmt.Fprintf (w, "    }\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "    table.mutex.Lock ();\n");
+	fmt.Fprintf (w, "    defer table.mutex.Unlock ();\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "    table.lastHandle++;\n");
+	fmt.Fprintf (w, "    table.instances[table.lastHandle] = instance;\n");
+	fmt.Fprintf (w, "    return table.lastHandle;\n");
+	fmt.Fprintf (w, "}\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "func (table *handleTable) getInstance (handle C.%sHandle) (BaseClass, bool) {\n", NameSpace);
+	fmt.Fprintf (w, "    table.mutex.Lock ();\n");
+	fmt.Fprintf (w, "    defer table.mutex.Unlock ();\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "    instance, ok := table.instances[handle];\n");
+	fmt.Fprintf (w, "    return instance, ok;\n");
+	fmt.Fprintf (w, "}\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "func (table *handleTable) releaseHandle (handle C.%sHandle) (bool) {\n", NameSpace);
+	fmt.Fprintf (w, "    table.mutex.Lock ();\n");
+	fmt.Fprintf (w, "    defer table.mutex.Unlock ();\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "    _, ok := table.instances[handle];\n");
+	fmt.Fprintf (w, "    delete (table.instances, handle);\n");
+	if (doErrorMessages) {
+		fmt.Fprintf (w, "    delete (table.errorMessages, handle);\n");
+	}
+	fmt.Fprintf (w, "    return ok;\n");
+	fmt.Fprintf (w, "}\n");
+	if (doErrorMessages) {
+		fmt.Fprintf (w, "\n");
+		fmt.Fprintf (w, "func (table *handleTable) setLastError (handle C.%sHandle, message string) {\n", NameSpace);
+		fmt.Fprintf (w, "    table.mutex.Lock ();\n");
+		fmt.Fprintf (w, "    defer table.mutex.Unlock ();\n");
+		fmt.Fprintf (w, "\n");
+		fmt.Fprintf (w, "    _, ok := table.instances[handle];\n");
+		fmt.Fprintf (w, "    if (ok) || (handle == 0) {\n");
+		fmt.Fprintf (w, "        table.errorMessages[handle] = message;\n");
+		fmt.Fprintf (w, "    }\n");
+		fmt.Fprintf (w, "}\n");
+		fmt.Fprintf (w, "\n");
+		fmt.Fprintf (w, "func (table *handleTable) getLastError (handle C.%sHandle) (string, bool, bool) {\n", NameSpace);
+		fmt.Fprintf (w, "    table.mutex.Lock ();\n");
+		fmt.Fprintf (w, "    defer table.mutex.Unlock ();\n");
+		fmt.Fprintf (w, "\n");
+		fmt.Fprintf (w, "    _, ok := table.instances[handle];\n");
+		fmt.Fprintf (w, "    message, hasError := table.errorMessages[handle];\n");
+		fmt.Fprintf (w, "    return message, hasError, (ok) || (handle == 0);\n");
+		fmt.Fprintf (w, "}\n");
+	}
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "/*************************************************************************************************************************\n");
+	fmt.Fprintf (w, " Error handling\n");
+	fmt.Fprintf (w, "**************************************************************************************************************************/\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// getErrorCode returns the error code of an error that an implementation returned.\n");
+	fmt.Fprintf (w, "// Errors that do not wrap an ErrorCode become a generic exception.\n");
+	fmt.Fprintf (w, "func getErrorCode (err error) (C.%sResult) {\n", NameSpace);
+	fmt.Fprintf (w, "    var errorCode ErrorCode;\n");
+	fmt.Fprintf (w, "    if (errors.As (err, &errorCode)) {\n");
+	fmt.Fprintf (w, "        return C.%sResult (errorCode);\n", NameSpace);
+	fmt.Fprintf (w, "    }\n");
+	fmt.Fprintf (w, "    return C.%sResult (ErrorGENERICEXCEPTION);\n", NameSpace);
+	fmt.Fprintf (w, "}\n");
+	fmt.Fprintf (w, "\n");
+	if (doErrorMessages) {
+		fmt.Fprintf (w, "// handleError stores the message of an error for the error method and returns its error code.\n");
+		fmt.Fprintf (w, "// The handle is the instance whose method failed, or 0 for the global functions.\n");
+		fmt.Fprintf (w, "func handleError (handle C.%sHandle, err error) (C.%sResult) {\n", NameSpace, NameSpace);
+		fmt.Fprintf (w, "    handles.setLastError (handle, err.Error ());\n");
+		fmt.Fprintf (w, "    return getErrorCode (err);\n");
+		fmt.Fprintf (w, "}\n");
+		fmt.Fprintf (w, "\n");
+		fmt.Fprintf (w, "// recoverPanic turns a panic of an implementation into an error code, as it must not unwind into C.\n");
+		fmt.Fprintf (w, "func recoverPanic (handle C.%sHandle, errorCode *C.%sResult) {\n", NameSpace, NameSpace);
+		fmt.Fprintf (w, "    recovered := recover ();\n");
+		fmt.Fprintf (w, "    if (recovered == nil) {\n");
+		fmt.Fprintf (w, "        return;\n");
+		fmt.Fprintf (w, "    }\n");
+		fmt.Fprintf (w, "\n");
+		fmt.Fprintf (w, "    switch value := recovered.(type) {\n");
+		fmt.Fprintf (w, "        case error:\n");
+		fmt.Fprintf (w, "            *errorCode = handleError (handle, value);\n");
+		fmt.Fprintf (w, "        case string:\n");
+		fmt.Fprintf (w, "            handles.setLastError (handle, value);\n");
+		fmt.Fprintf (w, "            *errorCode = C.%sResult (ErrorGENERICEXCEPTION);\n", NameSpace);
+		fmt.Fprintf (w, "        default:\n");
+		fmt.Fprintf (w, "            handles.setLastError (handle, \"unknown panic\");\n");
+		fmt.Fprintf (w, "            *errorCode = C.%sResult (ErrorGENERICEXCEPTION);\n", NameSpace);
+		fmt.Fprintf (w, "    }\n");
+		fmt.Fprintf (w, "}\n");
+	} else {
+		fmt.Fprintf (w, "// recoverPanic turns a panic of an implementation into an error code, as it must not unwind into C.\n");
+		fmt.Fprintf (w, "func recoverPanic (errorCode *C.%sResult) {\n", NameSpace);
+		fmt.Fprintf (w, "    recovered := recover ();\n");
+		fmt.Fprintf (w, "    if (recovered == nil) {\n");
+		fmt.Fprintf (w, "        return;\n");
+		fmt.Fprintf (w, "    }\n");
+		fmt.Fprintf (w, "\n");
+		fmt.Fprintf (w, "    err, ok := recovered.(error);\n");
+		fmt.Fprintf (w, "    if (ok) {\n");
+		fmt.Fprintf (w, "        *errorCode = getErrorCode (err);\n");
+		fmt.Fprintf (w, "    } else {\n");
+		fmt.Fprintf (w, "        *errorCode = C.%sResult (ErrorGENERICEXCEPTION);\n", NameSpace);
+		fmt.Fprintf (w, "    }\n");
+		fmt.Fprintf (w, "}\n");
+	}
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "/*************************************************************************************************************************\n");
+	fmt.Fprintf (w, " Buffers\n");
+	fmt.Fprintf (w, "**************************************************************************************************************************/\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "// writeString copies a string including its null terminator into the buffer of the caller.\n");
+	fmt.Fprintf (w, "// The caller may pass no buffer to query the number of characters that are needed.\n");
+	fmt.Fprintf (w, "func writeString (value string, bufferSize C.%s_uint32, neededChars *C.%s_uint32, buffer *C.char) (C.%sResult) {\n", NameSpace, NameSpace, NameSpace);
+	fmt.Fprintf (w, "    if (neededChars != nil) {\n");
+	fmt.Fprintf (w, "        *neededChars = C.%s_uint32 (len (value));\n", NameSpace);
+	fmt.Fprintf (w, "    }\n");
+	fmt.Fprintf (w, "    if (buffer == nil) {\n");
+	fmt.Fprintf (w, "        return 0;\n");
+	fmt.Fprintf (w, "    }\n");
+	fmt.Fprintf (w, "    if (len (value) >= int (bufferSize)) {\n");
+	fmt.Fprintf (w, "        return C.%sResult (ErrorBUFFERTOOSMALL);\n", NameSpace);
+	fmt.Fprintf (w, "    }\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "    if (len (value) > 0) {\n");
+	fmt.Fprintf (w, "        source := []byte (value);\n");
+	fmt.Fprintf (w, "        C.memcpy (unsafe.Pointer (buffer), unsafe.Pointer (&source[0]), C.size_t (len (source)));\n");
+	fmt.Fprintf (w, "    }\n");
+	fmt.Fprintf (w, "    *(*C.char) (unsafe.Pointer (uintptr (unsafe.Pointer (buffer)) + uintptr (len (value)))) = 0;\n");
+	fmt.Fprintf (w, "    return 0;\n");
+	fmt.Fprintf (w, "}\n");
+
+	if (usesBuffers) {
+		writeGoNativeByteOrder (w);
+		fmt.Fprintf (w, "// readValues reads the packed values of structs or arrays from a buffer of the caller.\n");
+		fmt.Fprintf (w, "func readValues (pointer unsafe.Pointer, values interface{}) (error) {\n");
+		fmt.Fprintf (w, "    size := binary.Size (values);\n");
+		fmt.Fprintf (w, "    if (size <= 0) {\n");
+		fmt.Fprintf (w, "        return nil;\n");
+		fmt.Fprintf (w, "    }\n");
+		fmt.Fprintf (w, "    return binary.Read (bytes.NewReader (C.GoBytes (pointer, C.int (size))), nativeByteOrder, values);\n");
+		fmt.Fprintf (w, "}\n");
+		fmt.Fprintf (w, "\n");
+		fmt.Fprintf (w, "// writeValues writes the packed values of structs or arrays into a buffer of the caller.\n");
+		fmt.Fprintf (w, "func writeValues (pointer unsafe.Pointer, values interface{}) (error) {\n");
+		fmt.Fprintf (w, "    var buffer bytes.Buffer;\n");
+		fmt.Fprintf (w, "    err := binary.Write (&buffer, nativeByteOrder, values);\n");
+		fmt.Fprintf (w, "    if (err != nil) {\n");
+		fmt.Fprintf (w, "        return err;\n");
+		fmt.Fprintf (w, "    }\n");
+		fmt.Fprintf (w, "    if (buffer.Len () > 0) {\n");
+		fmt.Fprintf (w, "        C.memcpy (pointer, unsafe.Pointer (&buffer.Bytes ()[0]), C.size_t (buffer.Len ()));\n");
+		fmt.Fprintf (w, "    }\n");
+		fmt.Fprintf (w, "    return nil;\n");
+		fmt.Fprintf (w, "}\n");
+		fmt.Fprintf (w, "\n");
+		fmt.Fprintf (w, "// writeArray copies the values of an array into the buffer of the caller.\n");
+		fmt.Fprintf (w, "// The caller may pass no buffer to query the number of elements that are needed.\n");
+		fmt.Fprintf (w, "func writeArray (values interface{}, count int, bufferSize C.%s_uint64, neededCount *C.%s_uint64, buffer unsafe.Pointer) (C.%sResult) {\n", NameSpace, NameSpace, NameSpace);
+		fmt.Fprintf (w, "    if (neededCount != nil) {\n");
+		fmt.Fprintf (w, "        *neededCount = C.%s_uint64 (count);\n", NameSpace);
+		fmt.Fprintf (w, "    }\n");
+		fmt.Fprintf (w, "    if (buffer == nil) {\n");
+		fmt.Fprintf (w, "        return 0;\n");
+		fmt.Fprintf (w, "    }\n");
+		fmt.Fprintf (w, "    if (uint64 (count) > uint64 (bufferSize)) {\n");
+		fmt.Fprintf (w, "        return C.%sResult (ErrorBUFFERTOOSMALL);\n", NameSpace);
+		fmt.Fprintf (w, "    }\n");
+		fmt.Fprintf (w, "\n");
+		fmt.Fprintf (w, "    err := writeValues (buffer, values);\n");
+		fmt.Fprintf (w, "    if (err != nil) {\n");
+		fmt.Fprintf (w, "        return getErrorCode (err);\n");
+		fmt.Fprintf (w, "    }\n");
+		fmt.Fprintf (w, "    return 0;\n");
+		fmt.Fprintf (w, "}\n");
+	}
+
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "/*************************************************************************************************************************\n");
+	fmt.Fprintf (w, " Exported functions\n");
+	fmt.Fprintf (w, "**************************************************************************************************************************/\n");
+
+	for _, class := range component.Classes {
+		for _, method := range class.Methods {
+			err := writeGoImplementationExport (method, w, NameSpace, class.ClassName, false, component.Global);
+			if (err != nil) {
+				return err;
+			}
+		}
+	}
+	for _, method := range component.Global.Methods {
+		err := writeGoImplementationExport (method, w, NameSpace, "Wrapper", true, component.Global);
+		if (err != nil) {
+			return err;
+		}
+	}
+
+	return nil;
+}
+
+// writeGoImplementationExport writes the exported Go function of a method, that checks and converts the C parameters,
+// calls the Go implementation and converts its results and errors back to C
+func writeGoImplementationExport (method ComponentDefinitionMethod, w io.Writer, NameSpace string, ClassName string, isGlobal bool, global ComponentDefinitionGlobal) (error) {
+	spacing := "    ";
+	invalidParam := fmt.Sprintf ("C.%sResult (ErrorINVALIDPARAM)", NameSpace);
+	invalidCast := fmt.Sprintf ("C.%sResult (ErrorINVALIDCAST)", NameSpace);
+	doErrorMessages := (global.ErrorMethod != "");
+	isError := isGlobal && (method.MethodName == global.ErrorMethod);
+
+	// The handle, at which the messages of the errors of the method are stored
+	errorHandle := "0";
+	if (!isGlobal) {
+		errorHandle = fmt.Sprintf ("C.%sHandle (p%s)", NameSpace, ClassName);
+	}
+	returnError := func (indent string) (string) {
+		if (doErrorMessages) {
+			return fmt.Sprintf ("%sreturn handleError (%s, err);\n", indent, errorHandle);
+		}
+		return fmt.Sprintf ("%sreturn getErrorCode (err);\n", indent);
+	}
+
+	exportparameters := "";
+	checks := "";
+	conversions := "";
+	callparameters := "";
+	resultvariables := "";
+	results := "";
+	handleresults := "";
+
+	// Looks up the instance of a handle and casts it to the interface of its class
+	lookupHandle := func (variableName string, cParamName string, className string) {
+		conversions = conversions + fmt.Sprintf ("%sinstance%s, ok := handles.getInstance (C.%sHandle (%s));\n", spacing, variableName, NameSpace, cParamName);
+		conversions = conversions + fmt.Sprintf ("%sif (!ok) {\n", spacing);
+		conversions = conversions + fmt.Sprintf ("%s    return %s;\n", spacing, invalidParam);
+		conversions = conversions + fmt.Sprintf ("%s}\n", spacing);
+		conversions = conversions + fmt.Sprintf ("%simplementation%s, ok := instance%s.(%s);\n", spacing, variableName, variableName, getGoIdentifier (className));
+		conversions = conversions + fmt.Sprintf ("%sif (!ok) {\n", spacing);
+		conversions = conversions + fmt.Sprintf ("%s    return %s;\n", spacing, invalidCast);
+		conversions = conversions + fmt.Sprintf ("%s}\n", spacing);
+	}
+	// Returns the error code of a failed conversion
+	checkError := func () (string) {
+		return fmt.Sprintf ("%sif (err != nil) {\n%s%s}\n", spacing, returnError (spacing + "    "), spacing);
+	}
+
+	if (!isGlobal) {
+		exportparameters = fmt.Sprintf ("p%s C.%s_%s", ClassName, NameSpace, ClassName);
+		lookupHandle ("Self", "p" + ClassName, ClassName);
+	}
+
+	isRelease := isGlobal && (method.MethodName == global.ReleaseMethod);
+	instanceParamName := "";
+	
+	for _, param := range method.Params {
+		cParams, err := generateCParameter (param, ClassName, method.MethodName, NameSpace);
+		if (err != nil) {
+			return err;
+		}
+		for _, cParam := range cParams {
+			if (exportparameters != "") {
+				exportparameters = exportparameters + ", ";
+			}
+			exportparameters = exportparameters + cParam.ParamName + " " + getGoCType (cParam.ParamType);
+		}
+		cParamName := cParams[0].ParamName;
+		cParamType := strings.TrimPrefix (getGoCType (cParams[0].ParamType), "*");
+
+		switch (param.ParamPass) {
+		case "in":
+			if (callparameters != "") {
+				callparameters = callparameters + ", ";
+			}
+
+			switch (param.ParamType) {
+				case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "single", "double", "bool":
+					goType, err := getGoBasicType (param.ParamType);
+					if (err != nil) {
+						return err;
+					}
+					callparameters = callparameters + fmt.Sprintf ("%s (%s)", goType, cParamName);
+
+				case "enum":
+					callparameters = callparameters + fmt.Sprintf ("%s (%s)", getGoIdentifier (param.ParamClass), cParamName);
+
+				case "string":
+					checks = checks + fmt.Sprintf ("%sif (%s == nil) {\n", spacing, cParamName);
+					checks = checks + fmt.Sprintf ("%s    return %s;\n", spacing, invalidParam);
+					checks = checks + fmt.Sprintf ("%s}\n", spacing);
+					callparameters = callparameters + fmt.Sprintf ("C.GoString (%s)", cParamName);
+
+				case "struct":
+					checks = checks + fmt.Sprintf ("%sif (%s == nil) {\n", spacing, cParamName);
+					checks = checks + fmt.Sprintf ("%s    return %s;\n", spacing, invalidParam);
+					checks = checks + fmt.Sprintf ("%s}\n", spacing);
+					conversions = conversions + fmt.Sprintf ("%svar s%s %s;\n", spacing, param.ParamName, getGoIdentifier (param.ParamClass));
+					conversions = conversions + fmt.Sprintf ("%serr = readValues (unsafe.Pointer (%s), &s%s);\n", spacing, cParamName, param.ParamName);
+					conversions = conversions + checkError ();
+					callparameters = callparameters + "s" + param.ParamName;
+
+				case "basicarray", "structarray":
+					elementType := "";
+					if (param.ParamType == "basicarray") {
+						elementType, err = getGoBasicType (param.ParamClass);
+						if (err != nil) {
+							return err;
+						}
+					} else {
+						elementType = getGoIdentifier (param.ParamClass);
+					}
+					checks = checks + fmt.Sprintf ("%sif (%s == nil) && (%s > 0) {\n", spacing, cParams[1].ParamName, cParamName);
+					checks = checks + fmt.Sprintf ("%s    return %s;\n", spacing, invalidParam);
+					checks = checks + fmt.Sprintf ("%s}\n", spacing);
+					conversions = conversions + fmt.Sprintf ("%sarray%s := make ([]%s, %s);\n", spacing, param.ParamName, elementType, cParamName);
+					conversions = conversions + fmt.Sprintf ("%serr = readValues (unsafe.Pointer (%s), array%s);\n", spacing, cParams[1].ParamName, param.ParamName);
+					conversions = conversions + checkError ();
+					callparameters = callparameters + "array" + param.ParamName;
+
+				case "functiontype":
+					callparameters = callparameters + fmt.Sprintf ("wrap%s (%s)", getGoIdentifier (param.ParamClass), cParamName);
+
+				case "handle":
+					if (isRelease) || (isError) {
+						instanceParamName = cParamName;
+						break;
+					}
+					lookupHandle (param.ParamName, cParamName, param.ParamClass);
+					callparameters = callparameters + "implementation" + param.ParamName;
+
+				default:
+					return fmt.Errorf ("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName);
+			}
+
+		case "out", "return":
+			resultName := "result" + param.ParamName;
+			resultvariables = resultvariables + resultName + ", ";
+
+			switch (param.ParamType) {
+				case "uint8", "uint16", "uint32", "uint64", "int8", "int16", "int32", "int64", "single", "double", "bool", "enum":
+					checks = checks + fmt.Sprintf ("%sif (%s == nil) {\n", spacing, cParamName);
+					checks = checks + fmt.Sprintf ("%s    return %s;\n", spacing, invalidParam);
+					checks = checks + fmt.Sprintf ("%s}\n", spacing);
+					results = results + fmt.Sprintf ("%s*%s = %s (%s);\n", spacing, cParamName, cParamType, resultName);
+
+				case "string":
+					checks = checks + fmt.Sprintf ("%sif (%s == nil) && (%s == nil) {\n", spacing, cParams[1].ParamName, cParams[2].ParamName);
+					checks = checks + fmt.Sprintf ("%s    return %s;\n", spacing, invalidParam);
+					checks = checks + fmt.Sprintf ("%s}\n", spacing);
+					results = results + fmt.Sprintf ("%serrorCode = writeString (%s, %s, %s, %s);\n", spacing, resultName, cParams[0].ParamName, cParams[1].ParamName, cParams[2].ParamName);
+					results = results + fmt.Sprintf ("%sif (errorCode != 0) {\n", spacing);
+					results = results + fmt.Sprintf ("%s    return errorCode;\n", spacing);
+					results = results + fmt.Sprintf ("%s}\n", spacing);
+
+				case "struct":
+					checks = checks + fmt.Sprintf ("%sif (%s == nil) {\n", spacing, cParamName);
+					checks = checks + fmt.Sprintf ("%s    return %s;\n", spacing, invalidParam);
+					checks = checks + fmt.Sprintf ("%s}\n", spacing);
+					results = results + fmt.Sprintf ("%serr = writeValues (unsafe.Pointer (%s), &%s);\n", spacing, cParamName, resultName);
+					results = results + checkError ();
+
+				case "basicarray", "structarray":
+					checks = checks + fmt.Sprintf ("%sif (%s == nil) && (%s == nil) {\n", spacing, cParams[1].ParamName, cParams[2].ParamName);
+					checks = checks + fmt.Sprintf ("%s    return %s;\n", spacing, invalidParam);
+					checks = checks + fmt.Sprintf ("%s}\n", spacing);
+					results = results + fmt.Sprintf ("%serrorCode = writeArray (%s, len (%s), %s, %s, unsafe.Pointer (%s));\n", spacing, resultName, resultName, cParams[0].ParamName, cParams[1].ParamName, cParams[2].ParamName);
+					results = results + fmt.Sprintf ("%sif (errorCode != 0) {\n", spacing);
+					results = results + fmt.Sprintf ("%s    return errorCode;\n", spacing);
+					results = results + fmt.Sprintf ("%s}\n", spacing);
+
+				case "handle":
+					checks = checks + fmt.Sprintf ("%sif (%s == nil) {\n", spacing, cParamName);
+					checks = checks + fmt.Sprintf ("%s    return %s;\n", spacing, invalidParam);
+					checks = checks + fmt.Sprintf ("%s}\n", spacing);
+					// Handles are created last, so that no instance is left in the handle table if another result fails
+					handleresults = handleresults + fmt.Sprintf ("%s*%s = %s (handles.newHandle (%s));\n", spacing, cParamName, cParamType, resultName);
+
+				default:
+					return fmt.Errorf ("invalid method parameter type \"%s\" for %s.%s (%s)", param.ParamType, ClassName, method.MethodName, param.ParamName);
+			}
+
+		default:
+			return fmt.Errorf ("invalid method parameter passing \"%s\" for %s.%s (%s)", param.ParamPass, ClassName, method.MethodName, param.ParamName);
+		}
+	}
+
+	CMethodName := GetCExportName (NameSpace, ClassName, method, isGlobal);
+
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "//export %s\n", CMethodName);
+	fmt.Fprintf (w, "func %s (%s) (errorCode C.%sResult) {\n", CMethodName, exportparameters, NameSpace);
+	if (doErrorMessages) {
+		fmt.Fprintf (w, "    defer recoverPanic (%s, &errorCode);\n", errorHandle);
+	} else {
+		fmt.Fprintf (w, "    defer recoverPanic (&errorCode);\n");
+	}
+	fmt.Fprintf (w, "\n");
+
+	if (isRelease) {
+		fmt.Fprintf (w, "    if (!handles.releaseHandle (C.%sHandle (%s))) {\n", NameSpace, instanceParamName);
+		fmt.Fprintf (w, "        return %s;\n", invalidParam);
+		fmt.Fprintf (w, "    }\n");
+		fmt.Fprintf (w, "    return 0;\n");
+		fmt.Fprintf (w, "}\n");
+		return nil;
+	}
+
+	if (isError) {
+		// The instance may be null for the message of the last error of a global function
+		fmt.Fprintf (w, "%s", checks);
+		fmt.Fprintf (w, "    %sok := handles.getLastError (C.%sHandle (%s));\n", resultvariables, NameSpace, instanceParamName);
+		fmt.Fprintf (w, "    if (!ok) {\n");
+		fmt.Fprintf (w, "        return %s;\n", invalidParam);
+		fmt.Fprintf (w, "    }\n");
+		fmt.Fprintf (w, "\n");
+		fmt.Fprintf (w, "%s", results);
+		fmt.Fprintf (w, "    return 0;\n");
+		fmt.Fprintf (w, "}\n");
+		return nil;
+	}
+
+	fmt.Fprintf (w, "    var err error = nil;\n");
+	fmt.Fprintf (w, "%s", checks);
+	fmt.Fprintf (w, "%s", conversions);
+	fmt.Fprintf (w, "\n");
+
+	implementation := "wrapper";
+	if (!isGlobal) {
+		implementation = "implementationSelf";
+	}
+	if (resultvariables != "") {
+		fmt.Fprintf (w, "    %serr := %s.%s (%s);\n", resultvariables, implementation, getGoIdentifier (method.MethodName), callparameters);
+	} else {
+		fmt.Fprintf (w, "    err = %s.%s (%s);\n", implementation, getGoIdentifier (method.MethodName), callparameters);
+	}
+	fmt.Fprintf (w, "    if (err != nil) {\n");
+	fmt.Fprintf (w, "%s", returnError ("        "));
+	fmt.Fprintf (w, "    }\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "%s", results);
+	fmt.Fprintf (w, "%s", handleresults);
+	fmt.Fprintf (w, "    return 0;\n");
+	fmt.Fprintf (w, "}\n");
+
+	return nil;
+}
+
+// buildGoImplementationCallbacks writes the Go funcs that call the C function pointers of the function types,
+// which the consumers of the library pass in
+func buildGoImplementationCallbacks (component ComponentDefinition, w io.Writer, NameSpace string) (error) {
+	usesStrings := false;
+	for _, functiontype := range component.Functions {
+		for _, param := range functiontype.Params {
+			if (param.ParamType == "string") {
+				usesStrings = true;
+			}
+		}
+	}
+
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "package main\n");
+	fmt.Fprintf (w, "\n");
+	fmt.Fprintf (w, "/*\n");
+	fmt.Fprintf (w, "#include <stdint.h>\n");
+	fmt.Fprintf (w, "#include <stdbool.h>\n");
+	fmt.Fprintf (w, "#include <stdlib.h>\n");
+	fmt.Fprintf (w, "\n");
+	err := writeGoCTypes (component, w, NameSpace, "uintptr_t");
+	if (err != nil) {
+		return err;
+	}
+	for _, functiontype := range component.Functions {
+		cParams, err := getGoCallbackCParameters (functiontype, NameSpace);
+		if (err != nil) {
+			return err;
+		}
+		parameters := "";
+		names := "";
+		for _, cParam := range cParams {
+			parameters = parameters + ", " + cParam.ParamType + " " + cParam.ParamName;
+			if (names != "") {
+				names = names + ", ";
+			}
+			names = names + cParam.ParamName;
+		}
+		fmt.Fprintf (w, "\n");
+		fmt.Fprintf (w, "static void %s_call (%s%s pFunction%s) { pFunction (%s); }\n", strings.ToLower (NameSpace + "_" + functiontype.FunctionName), NameSpace, functiontype.FunctionName, parameters, names);
+	}
+	fmt.Fprintf (w, "*/\n");
+	fmt.Fprintf (w, "import \"C\"\n");
+	if (usesStrings) {
+		fmt.Fprintf (w, "\n");
+		fmt.Fprintf (w, "import (\n");
+		fmt.Fprintf (w, "    \"unsafe\"\n");
+		fmt.Fprintf (w, ")\n");
+	}
+
+	for _, functiontype := range component.Functions {
+		functionName := getGoIdentifier (functiontype.FunctionName);
+		parameters, returnvalues, _, err := getGoFunctionTypeSignature (functiontype);
+		if (err != nil) {
+			return err;
+		}
+		cParams, err := getGoCallbackCParameters (functiontype, NameSpace);
+		if (err != nil) {
+			return err;
+		}
+
+		declarations := "";
+		callparameters := "";
+		results := "";
+		for k, param := range functiontype.Params {
+			goType, prefix, err := getGoCallbackParamType (functiontype, param);
+			if (err != nil) {
+				return err;
+			}
+			cParamType := strings.TrimPrefix (getGoCType (cParams[k].ParamType), "*");
+
+			if (param.ParamPass == "in") {
+				if (param.ParamType == "string") {
+					declarations = declarations + fmt.Sprintf ("        %s := C.CString (%s%s);\n", cParams[k].ParamName, prefix, param.ParamName);
+					declarations = declarations + fmt.Sprintf ("        defer C.free (unsafe.Pointer (%s));\n", cParams[k].ParamName);
+					callparameters = callparameters + ", " + cParams[k].ParamName;
+				} else {
+					callparameters = callparameters + fmt.Sprintf (", %s (%s%s)", cParamType, prefix, param.ParamName);
+				}
+			} else {
+				declarations = declarations + fmt.Sprintf ("        var %s %s;\n", cParams[k].ParamName, cParamType);
+				callparameters = callparameters + ", &" + cParams[k].ParamName;
+				if (results != "") {
+					results = results + ", ";
+				}
+				results = results + fmt.Sprintf ("%s (%s)", goType, cParams[k].ParamName);
+			}
+		}
+
+		fmt.Fprintf (w, "\n");
+		fmt.Fprintf (w, "// wrap%s returns a Go func that calls a C function pointer of the type %s%s.\n", functionName, NameSpace, functiontype.FunctionName);
+		fmt.Fprintf (w, "func wrap%s (pFunction C.%s%s) (%s) {\n", functionName, NameSpace, functiontype.FunctionName, functionName);
+		fmt.Fprintf (w, "    if (pFunction == nil) {\n");
+		fmt.Fprintf (w, "        return nil;\n");
+		fmt.Fprintf (w, "    }\n");
+		fmt.Fprintf (w, "    return func (%s) (%s) {\n", parameters, returnvalues);
+		fmt.Fprintf (w, "%s", declarations);
+		fmt.Fprintf (w, "        C.%s_call (pFunction%s);\n", strings.ToLower (NameSpace + "_" + functiontype.FunctionName), callparameters);
+		if (results != "") {
+			fmt.Fprintf (w, "        return %s;\n", results);
+		}
+		fmt.Fprintf (w, "    }\n");
+		fmt.Fprintf (w, "}\n");
+	}
+
+	return nil;
+}
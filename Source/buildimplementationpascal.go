@@ -45,9 +45,8 @@ import (
 
 
 // BuildImplementationPascal builds Pascal interface classes, implementation stubs and wrapper code that maps to the Pascal header
-func BuildImplementationPascal(component ComponentDefinition, outputFolder string, stubOutputFolder string, projectOutputFolder string, implementation ComponentDefinitionImplementation) error {
+func BuildImplementationPascal(component ComponentDefinition, outputFolder string, stubOutputFolder string, projectOutputFolder string, implementation ComponentDefinitionImplementation, forceRecreation ForceRecreation) error {
 	//doJournal := len (component.Global.JournalMethod) > 0;
-	forceRecreation := false
 
 	namespace := component.NameSpace;
 	libraryname := component.LibraryName;
@@ -70,9 +69,12 @@ func BuildImplementationPascal(component ComponentDefinition, outputFolder strin
 	if err != nil {
 		return err
 	}
-	typesWrapperfile.WritePascalLicenseHeader(component,
+	err = typesWrapperfile.WritePascalLicenseHeader(component,
 		fmt.Sprintf("This is an autogenerated Pascal type definition file in order to allow easy\ndevelopment of %s. The functions in this file need to be implemented. It needs to be generated only once.", libraryname),
 		true)
+	if err != nil {
+		return err
+	}
 	buildPascalTypeDefinition (component, typesWrapperfile, namespace, baseName);
 
 
@@ -82,9 +84,12 @@ func BuildImplementationPascal(component ComponentDefinition, outputFolder strin
 	if err != nil {
 		return err
 	}
-	exceptionWrapperfile.WritePascalLicenseHeader(component,
+	err = exceptionWrapperfile.WritePascalLicenseHeader(component,
 		fmt.Sprintf("This is an autogenerated Pascal exception class definition file in order to allow easy\ndevelopment of %s. The functions in this file need to be implemented. It needs to be generated only once.", libraryname),
 		true)
+	if err != nil {
+		return err
+	}
 	buildPascalExceptionDefinition (component, exceptionWrapperfile, namespace, baseName);
 	
 
@@ -94,9 +99,12 @@ func BuildImplementationPascal(component ComponentDefinition, outputFolder strin
 	if err != nil {
 		return err
 	}
-	interfaceWrapperfile.WritePascalLicenseHeader(component,
+	err = interfaceWrapperfile.WritePascalLicenseHeader(component,
 		fmt.Sprintf("This is an autogenerated Pascal interface definition file in order to allow easy\ndevelopment of %s. The functions in this file need to be implemented. It needs to be generated only once.", libraryname),
 		true)
+	if err != nil {
+		return err
+	}
 	buildPascalInterfaceDefinition (component, interfaceWrapperfile, namespace, baseName);
 
 
@@ -106,22 +114,32 @@ func BuildImplementationPascal(component ComponentDefinition, outputFolder strin
 	if err != nil {
 		return err
 	}
-	exportWrapperfile.WritePascalLicenseHeader(component,
+	err = exportWrapperfile.WritePascalLicenseHeader(component,
 		fmt.Sprintf("This is an autogenerated Pascal export implementation file in order to allow easy\ndevelopment of %s. The functions in this file need to be implemented. It needs to be generated only once.", libraryname),
 		true)
+	if err != nil {
+		return err
+	}
 		
 	buildPascalExportsDefinition (component, exportWrapperfile, namespace, baseName, stubIdentifier, implementation.ClassIdentifier);
 	
 	IntfWrapperStubName := path.Join(stubOutputFolder, baseName + stubIdentifier + ".pas")
-	if forceRecreation || (!FileExists(IntfWrapperStubName) ) {
+	if forceRecreation.Stubs || (!FileExists(IntfWrapperStubName) ) {
+		err = BackupFile(IntfWrapperStubName)
+		if err != nil {
+			return err
+		}
 		log.Printf("Creating \"%s\"", IntfWrapperStubName)
 		templatefile, err := CreateLanguageFile (IntfWrapperStubName, indentString)
 		if err != nil {
 			return err
 		}
-		templatefile.WritePascalLicenseHeader(component,
+		err = templatefile.WritePascalLicenseHeader(component,
 			fmt.Sprintf("This is an autogenerated Pascal implementation file in order to allow easy\ndevelopment of %s. It needs to be generated only once.", libraryname),
 			true)
+		if err != nil {
+			return err
+		}
 		
 		err = buildStubImplementation (component, templatefile, namespace, baseName, stubIdentifier);
 		if err != nil {
@@ -150,9 +168,12 @@ func BuildImplementationPascal(component ComponentDefinition, outputFolder strin
 	if err != nil {
 		return err
 	}
-	lprfile.WritePascalLicenseHeader(component,
+	err = lprfile.WritePascalLicenseHeader(component,
 		fmt.Sprintf("This is an autogenerated Pascal project file in order to allow easy\ndevelopment of %s.", libraryname),
-		true);
+		true)
+	if err != nil {
+		return err
+	}
 	err = buildLPRImplementation (component, lprfile, namespace, baseName);
 	if err != nil {
 		return err
@@ -169,7 +190,7 @@ func BuildImplementationPascal(component ComponentDefinition, outputFolder strin
 		return err
 	}
 
-	err = buildPascalStub(component, namespace, implementation.ClassIdentifier, baseName, stubOutputFolder, indentString, stubIdentifier, forceRecreation)
+	err = buildPascalStub(component, namespace, implementation.ClassIdentifier, baseName, stubOutputFolder, indentString, stubIdentifier, forceRecreation.Stubs)
 	if err != nil {
 		return err
 	}
@@ -923,125 +944,7 @@ func buildLPRImplementation(componentdefinition ComponentDefinition, w LanguageW
 
 
 func buildLPIImplementation(componentdefinition ComponentDefinition, w LanguageWriter, NameSpace string, BaseName string) error {
-
-	w.Writeln ("<?xml version=\"1.0\" encoding=\"UTF-8\"?>");
-	w.Writeln ("<CONFIG>");
-	w.Writeln ("  <ProjectOptions>");
-	w.Writeln ("    <Version Value=\"%d\"/>", 10);
-	
-	
-	w.Writeln ("    <PathDelim Value=\"\\\"/>");
-	w.Writeln ("    <General>");
-	w.Writeln ("      <Flags>");
-	w.Writeln ("        <MainUnitHasCreateFormStatements Value=\"False\" />");
-	w.Writeln ("        <MainUnitHasTitleStatement Value=\"False\" />");
-	w.Writeln ("        <MainUnitHasScaledStatement Value=\"False\" />");
-	w.Writeln ("      </Flags>");
-	w.Writeln ("      <SessionStorage Value=\"InProjectDir\" />");
-	w.Writeln ("      <MainUnit Value=\"%d\"/>", 0);
-	w.Writeln ("      <Title Value=\"%s\" />", NameSpace);
-	w.Writeln ("      <UseAppBundle Value=\"False\" />");
-	w.Writeln ("      <ResourceType Value=\"res\" />");
-	w.Writeln ("    </General>");
-	w.Writeln ("    <BuildModes Count=\"%d\">", 2);
-	w.Writeln ("      <Item1 Name=\"Release\" Default=\"True\"/>");
-	w.Writeln ("      <Item2 Name=\"Debug\">");
-	w.Writeln ("        <CompilerOptions>");
-	w.Writeln ("          <Version Value=\"11\" />");
-	w.Writeln ("          <PathDelim Value=\"\\\"/>");
-	w.Writeln ("          <Target>");
-	w.Writeln ("            <Filename Value=\"bin\\$(TargetCPU)-$(TargetOS)\\Release\\project%s\"/>", BaseName);
-	w.Writeln ("          </Target>");
-	w.Writeln ("          <SearchPaths>");
-	w.Writeln ("            <IncludeFiles Value=\"$(ProjOutDir)\"/>");
-	w.Writeln ("            <OtherUnitFiles Value=\"Stub;Interfaces\"/>");
-	w.Writeln ("            <UnitOutputDirectory Value=\"lib\\$(TargetCPU)-$(TargetOS)\"/>");
-	w.Writeln ("          </SearchPaths>");
-	w.Writeln ("          <Parsing>");
-	w.Writeln ("            <SyntaxOptions>");
-	w.Writeln ("              <IncludeAssertionCode Value=\"True\"/>");
-	w.Writeln ("            </SyntaxOptions>");
-	w.Writeln ("          </Parsing>");
-	w.Writeln ("          <CodeGeneration>");
-	w.Writeln ("            <RelocatableUnit Value=\"True\" />");
-	w.Writeln ("          </CodeGeneration>");
-	w.Writeln ("          <Linking>");
-	w.Writeln ("            <Debugging>");
-	w.Writeln ("              <UseExternalDbgSyms Value=\"True\"/>");
-	w.Writeln ("            </Debugging>");
-	w.Writeln ("            <Options>");
-	w.Writeln ("              <ExecutableType Value=\"Library\"/>");
-	w.Writeln ("            </Options>");
-	w.Writeln ("          </Linking>");
-	w.Writeln ("        </CompilerOptions>");
-	w.Writeln ("      </Item2>");
-	w.Writeln ("    </BuildModes>");
-	w.Writeln ("    <PublishOptions>");
-	w.Writeln ("      <Version Value=\"%d\"/>", 2);
-	w.Writeln ("    </PublishOptions>");
-	w.Writeln ("    <RunParams>");
-	w.Writeln ("      <local>");
-	w.Writeln ("        <FormatVersion Value=\"1\"/>");
-	w.Writeln ("      </local>");
-	w.Writeln ("    </RunParams>");
-	w.Writeln ("    <Units Count=\"%d\">", 2);
-	w.Writeln ("      <Unit0>");
-	w.Writeln ("        <Filename Value=\"Interfaces\\%s.lpr\"/>", BaseName);
-	w.Writeln ("        <IsPartOfProject Value=\"True\"/>");	
-	w.Writeln ("      </Unit0>");
-	w.Writeln ("      <Unit1>");
-	w.Writeln ("        <Filename Value=\"Stub\\%s.pas\"/>", BaseName);
-	w.Writeln ("        <IsPartOfProject Value=\"True\"/>");	
-	w.Writeln ("      </Unit1>");
-	w.Writeln ("    </Units>");
-	w.Writeln ("  </ProjectOptions>");
-	w.Writeln ("  <CompilerOptions>");
-	w.Writeln ("    <Version Value=\"%d\"/>", 11);
-	w.Writeln ("    <PathDelim Value=\"\\\"/>");
-	w.Writeln ("    <Target>");
-	w.Writeln ("      <Filename Value=\"bin\\$(TargetCPU)-$(TargetOS)\\Release\\%s\"/>", BaseName);
-	w.Writeln ("    </Target>");
-	w.Writeln ("    <SearchPaths>");
-	w.Writeln ("      <IncludeFiles Value=\"$(ProjOutDir)\"/>");	
-	w.Writeln ("      <OtherUnitFiles Value=\"Stub;Interfaces\"/>");
-	w.Writeln ("      <UnitOutputDirectory Value=\"lib\\$(TargetCPU)-$(TargetOS)\"/>");
-	w.Writeln ("    </SearchPaths>");
-	w.Writeln ("    <Parsing>");
-	w.Writeln ("      <SyntaxOptions>");
-	w.Writeln ("        <IncludeAssertionCode Value=\"True\"/>");
-	w.Writeln ("      </SyntaxOptions>");
-	w.Writeln ("    </Parsing>");
-	w.Writeln ("    <CodeGeneration>");
-	w.Writeln ("      <RelocatableUnit Value=\"True\"/>");
-	w.Writeln ("    </CodeGeneration>");
-	w.Writeln ("    <Linking>");
-	w.Writeln ("      <Debugging>");
-	w.Writeln ("        <StripSymbols Value=\"True\"/>");
-	w.Writeln ("        <UseExternalDbgSyms Value=\"True\"/>");
-	w.Writeln ("      </Debugging>");
-	w.Writeln ("      <Options>");
-	w.Writeln ("        <ExecutableType Value=\"Library\"/>");
-	w.Writeln ("      </Options>");
-	w.Writeln ("    </Linking>");
-	w.Writeln ("  </CompilerOptions>");
-	w.Writeln ("  <Debugging>");
-	w.Writeln ("    <Exceptions Count=\"%d\">", 3);
-	w.Writeln ("      <Item1>");
-	w.Writeln ("        <Name Value=\"EAbort\"/>");
-	w.Writeln ("      </Item1>");
-	w.Writeln ("      <Item2>");
-	w.Writeln ("        <Name Value=\"ECodetoolError\"/>");
- 	w.Writeln ("      </Item2>");
-	w.Writeln ("      <Item3>");
-	w.Writeln ("        <Name Value=\"EFOpenError\"/>");
-	w.Writeln ("      </Item3>");
-	w.Writeln ("    </Exceptions>");     
-	w.Writeln ("  </Debugging>");
-	w.Writeln ("</CONFIG>");    	
-	w.Writeln ("");
-
-	return nil;
-	
+	return w.WriteTemplate("pascalimplementation.lpi", NewTemplateData(componentdefinition))
 }
 
 
@@ -1141,14 +1044,21 @@ func buildPascalStub(component ComponentDefinition, NameSpace string, ClassIdent
 	baseClassName := "T" + ClassIdentifier + NameSpace + "BaseClass"
 	StubFileName := path.Join(outputFolder, BaseName + stubIdentifier + "_" +"baseclass.pas");
 	if forceRecreation || !FileExists(StubFileName) {
+		err := BackupFile(StubFileName)
+		if err != nil {
+			return err
+		}
 		log.Printf("Creating \"%s\"", StubFileName)
 		w, err := CreateLanguageFile(StubFileName, indentString)
 		if err != nil {
 			return err
 		}
-		w.WritePascalLicenseHeader(component,
+		err = w.WritePascalLicenseHeader(component,
 			fmt.Sprintf("This is the class declaration of %s", baseClassName),
 			false)
+		if err != nil {
+			return err
+		}
 		
 		w.Writeln ("{$MODE DELPHI}");
 		w.Writeln ("unit %s%s_%s;", BaseName, stubIdentifier, strings.ToLower("BaseClass"));
@@ -1191,15 +1101,22 @@ func buildPascalStub(component ComponentDefinition, NameSpace string, ClassIdent
 			log.Printf("Omitting recreation of Stub implementation for \"%s\"", outClassName)
 			continue;
 		}
+		err := BackupFile(StubFileName)
+		if err != nil {
+			return err
+		}
 
 		log.Printf("Creating \"%s\"", StubFileName)
 		w, err := CreateLanguageFile(StubFileName, indentString)
 		if err != nil {
 			return err
 		}
-		w.WritePascalLicenseHeader(component,
+		err = w.WritePascalLicenseHeader(component,
 			fmt.Sprintf("This is the class declaration of %s", outClassName),
 			false)
+		if err != nil {
+			return err
+		}
 
 		w.Writeln ("{$MODE DELPHI}");
 		w.Writeln ("unit %s%s_%s;", BaseName, stubIdentifier, strings.ToLower(class.ClassName));
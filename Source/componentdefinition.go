@@ -49,172 +49,179 @@ const (
 	eSpecialMethodRelease = 1
 	eSpecialMethodVersion = 2
 	eSpecialMethodJournal = 3
+	eSpecialMethodError = 4
 )
 
 // ComponentDefinitionParam definition of a method parameter used in the component's API
 type ComponentDefinitionParam struct {
-	ComponentDiffableElement
-	XMLName xml.Name `xml:"param"`
-	ParamName string `xml:"name,attr"`
-	ParamType string `xml:"type,attr"`
-	ParamPass string `xml:"pass,attr"`
-	ParamClass string `xml:"class,attr"`
-	ParamDescription string `xml:"description,attr"`
+	ComponentDiffableElement `json:"-"`
+	XMLName xml.Name `xml:"param" json:"-"`
+	ParamName string `xml:"name,attr" json:"name"`
+	ParamType string `xml:"type,attr" json:"type"`
+	ParamPass string `xml:"pass,attr" json:"pass"`
+	ParamClass string `xml:"class,attr" json:"class"`
+	ParamDescription string `xml:"description,attr" json:"description"`
 }
 
 // ComponentDefinitionMethod definition of a method provided by the component's API
 type ComponentDefinitionMethod struct {
-	ComponentDiffableElement
-	XMLName xml.Name `xml:"method"`
-	MethodName string `xml:"name,attr"`
-	MethodDescription string `xml:"description,attr"`
-	DLLSuffix string `xml:"dllsuffix,attr"`
-	Params   []ComponentDefinitionParam `xml:"param"`
+	ComponentDiffableElement `json:"-"`
+	XMLName xml.Name `xml:"method" json:"-"`
+	MethodName string `xml:"name,attr" json:"name"`
+	MethodDescription string `xml:"description,attr" json:"description"`
+	DLLSuffix string `xml:"dllsuffix,attr" json:"dllsuffix"`
+	Async bool `xml:"async,attr,omitempty" json:"async,omitempty"`
+	Params   []ComponentDefinitionParam `xml:"param" json:"param"`
 }
 
 // ComponentDefinitionClass definition of a class provided by the component's API
 type ComponentDefinitionClass struct {
-	ComponentDiffableElement
-	XMLName xml.Name `xml:"class"`
-	ClassName string `xml:"name,attr"`
-	ClassDescription string `xml:"description,attr"`
-	ParentClass string `xml:"parent,attr"`
-	Methods   []ComponentDefinitionMethod `xml:"method"`
+	ComponentDiffableElement `json:"-"`
+	XMLName xml.Name `xml:"class" json:"-"`
+	ClassName string `xml:"name,attr" json:"name"`
+	ClassDescription string `xml:"description,attr" json:"description"`
+	ParentClass string `xml:"parent,attr" json:"parent"`
+	Methods   []ComponentDefinitionMethod `xml:"method" json:"method"`
 }
 
 // ComponentDefinitionFunctionType definition of a function interface provided by the component's API
 type ComponentDefinitionFunctionType struct {
-	ComponentDiffableElement
-	XMLName xml.Name `xml:"functiontype"`
-	FunctionName string `xml:"name,attr"`
-	FunctionDescription string `xml:"description,attr"`
-	Params   []ComponentDefinitionParam `xml:"param"`
+	ComponentDiffableElement `json:"-"`
+	XMLName xml.Name `xml:"functiontype" json:"-"`
+	FunctionName string `xml:"name,attr" json:"name"`
+	FunctionDescription string `xml:"description,attr" json:"description"`
+	Params   []ComponentDefinitionParam `xml:"param" json:"param"`
 }
 
 // ComponentDefinitionBindingList definition of the language bindings to be generated for the component's API
 type ComponentDefinitionBindingList struct {
-	ComponentDiffableElement
-	Bindings []ComponentDefinitionBinding `xml:"binding"`
+	ComponentDiffableElement `json:"-"`
+	Bindings []ComponentDefinitionBinding `xml:"binding" json:"binding"`
 }
 
 // ComponentDefinitionImplementationList definition of the implementation interfaces or stubs to be generated for the component's API
 type ComponentDefinitionImplementationList struct {
-	ComponentDiffableElement
-	Implementations []ComponentDefinitionImplementation `xml:"implementation"`
+	ComponentDiffableElement `json:"-"`
+	Implementations []ComponentDefinitionImplementation `xml:"implementation" json:"implementation"`
 }
 
 // ComponentDefinitionGlobal definition of global functions provided the component's API
 type ComponentDefinitionGlobal struct {
-	ComponentDiffableElement
-	XMLName xml.Name `xml:"global"`
-	ReleaseMethod string `xml:"releasemethod,attr"`
-	JournalMethod string `xml:"journalmethod,attr"`
-	VersionMethod string `xml:"versionmethod,attr"`
-	Methods   []ComponentDefinitionMethod `xml:"method"`
+	ComponentDiffableElement `json:"-"`
+	XMLName xml.Name `xml:"global" json:"-"`
+	ReleaseMethod string `xml:"releasemethod,attr" json:"releasemethod"`
+	JournalMethod string `xml:"journalmethod,attr" json:"journalmethod"`
+	VersionMethod string `xml:"versionmethod,attr" json:"versionmethod"`
+	ErrorMethod string `xml:"errormethod,attr,omitempty" json:"errormethod,omitempty"`
+	Methods   []ComponentDefinitionMethod `xml:"method" json:"method"`
 }
 
 // ComponentDefinitionBinding definition of a specific languages for which bindings to the component's API will be generated
 type ComponentDefinitionBinding struct {
-	ComponentDiffableElement
-	XMLName xml.Name `xml:"binding"`
-	Language string `xml:"language,attr"`
-	Indentation string `xml:"indentation,attr"`
+	ComponentDiffableElement `json:"-"`
+	XMLName xml.Name `xml:"binding" json:"-"`
+	Language string `xml:"language,attr" json:"language"`
+	Indentation string `xml:"indentation,attr" json:"indentation"`
+	ImportPath string `xml:"importpath,attr,omitempty" json:"importpath,omitempty"`
+	Package string `xml:"package,attr,omitempty" json:"package,omitempty"`
+	HeaderOnly bool `xml:"headeronly,attr,omitempty" json:"headeronly,omitempty"`
+	CppStandard string `xml:"cppstandard,attr,omitempty" json:"cppstandard,omitempty"`
 }
 
 // ComponentDefinitionImplementation definition of a specific languages for which bindings to the component's API will be generated
 type ComponentDefinitionImplementation struct {
-	ComponentDiffableElement
-	XMLName xml.Name `xml:"implementation"`
-	Language string `xml:"language,attr"`
-	Indentation string `xml:"indentation,attr"`
-	ClassIdentifier string `xml:"classidentifier,attr"`
-	StubIdentifier string `xml:"stubidentifier,attr"`
+	ComponentDiffableElement `json:"-"`
+	XMLName xml.Name `xml:"implementation" json:"-"`
+	Language string `xml:"language,attr" json:"language"`
+	Indentation string `xml:"indentation,attr" json:"indentation"`
+	ClassIdentifier string `xml:"classidentifier,attr" json:"classidentifier"`
+	StubIdentifier string `xml:"stubidentifier,attr" json:"stubidentifier"`
 }
 
 // ComponentDefinitionEnumOption definition of an enum used in the component's API
 type ComponentDefinitionEnumOption struct {
-	ComponentDiffableElement
-	XMLName xml.Name `xml:"option"`
-	Name string `xml:"name,attr"`
-	Value int `xml:"value,attr"`
+	ComponentDiffableElement `json:"-"`
+	XMLName xml.Name `xml:"option" json:"-"`
+	Name string `xml:"name,attr" json:"name"`
+	Value int `xml:"value,attr" json:"value"`
 }
 
 // ComponentDefinitionEnum definition of all enums used in the component's API
 type ComponentDefinitionEnum struct {
-	ComponentDiffableElement
-	XMLName xml.Name `xml:"enum"`
-	Name string `xml:"name,attr"`
-	Options []ComponentDefinitionEnumOption `xml:"option"`
+	ComponentDiffableElement `json:"-"`
+	XMLName xml.Name `xml:"enum" json:"-"`
+	Name string `xml:"name,attr" json:"name"`
+	Options []ComponentDefinitionEnumOption `xml:"option" json:"option"`
 }
 
 // ComponentDefinitionError definition of an error used in the component's API
 type ComponentDefinitionError struct {
-	ComponentDiffableElement
-	XMLName xml.Name `xml:"error"`
-	Name string `xml:"name,attr"`
-	Code int `xml:"code,attr"`
-	Description string `xml:"description,attr"`
+	ComponentDiffableElement `json:"-"`
+	XMLName xml.Name `xml:"error" json:"-"`
+	Name string `xml:"name,attr" json:"name"`
+	Code int `xml:"code,attr" json:"code"`
+	Description string `xml:"description,attr" json:"description"`
 }
 
 // ComponentDefinitionErrors definition of errors in the component's API
 type ComponentDefinitionErrors struct {
-	ComponentDiffableElement
-	XMLName xml.Name `xml:"errors"`
-	Errors []ComponentDefinitionError `xml:"error"`
+	ComponentDiffableElement `json:"-"`
+	XMLName xml.Name `xml:"errors" json:"-"`
+	Errors []ComponentDefinitionError `xml:"error" json:"error"`
 }
 
 // ComponentDefinitionMember definition of a single struct provided by the component's API
 type ComponentDefinitionMember struct {
-	ComponentDiffableElement
-	XMLName xml.Name `xml:"member"`
-	Name string `xml:"name,attr"`
-	Type string `xml:"type,attr"`
-	Class string `xml:"class,attr"`
-	Rows int `xml:"rows,attr"`
-	Columns int `xml:"columns,attr"`
+	ComponentDiffableElement `json:"-"`
+	XMLName xml.Name `xml:"member" json:"-"`
+	Name string `xml:"name,attr" json:"name"`
+	Type string `xml:"type,attr" json:"type"`
+	Class string `xml:"class,attr" json:"class"`
+	Rows int `xml:"rows,attr" json:"rows"`
+	Columns int `xml:"columns,attr" json:"columns"`
 }
 
 // ComponentDefinitionStruct definition of all structs provided by the component's API
 type ComponentDefinitionStruct struct {
-	ComponentDiffableElement
-	XMLName xml.Name `xml:"struct"`
-	Name string `xml:"name,attr"`
-	Members []ComponentDefinitionMember `xml:"member"`
+	ComponentDiffableElement `json:"-"`
+	XMLName xml.Name `xml:"struct" json:"-"`
+	Name string `xml:"name,attr" json:"name"`
+	Members []ComponentDefinitionMember `xml:"member" json:"member"`
 }
 
 // ComponentDefinitionLicenseLine a single line of the component's license
 type ComponentDefinitionLicenseLine struct {
-	ComponentDiffableElement
-	XMLName xml.Name `xml:"line"`
-	Value string `xml:"value,attr"`
+	ComponentDiffableElement `json:"-"`
+	XMLName xml.Name `xml:"line" json:"-"`
+	Value string `xml:"value,attr" json:"value"`
 }
 
 // ComponentDefinitionLicense the component's license
 type ComponentDefinitionLicense struct {
-	ComponentDiffableElement
-	XMLName xml.Name `xml:"license"`
-	Lines   []ComponentDefinitionLicenseLine `xml:"line"`
+	ComponentDiffableElement `json:"-"`
+	XMLName xml.Name `xml:"license" json:"-"`
+	Lines   []ComponentDefinitionLicenseLine `xml:"line" json:"line"`
 }
 
 // ComponentDefinition the complete definition of the component's API
 type ComponentDefinition struct {
-	ACTVersion string
-	XMLName xml.Name `xml:"component"`
-	Version string `xml:"version,attr"`
-	Copyright string `xml:"copyright,attr"`
-	Year int `xml:"year,attr"`
-	NameSpace string `xml:"namespace,attr"`
-	LibraryName string `xml:"libraryname,attr"`
-	BaseName string `xml:"basename,attr"`
-	License ComponentDefinitionLicense `xml:"license"`
-	Classes []ComponentDefinitionClass `xml:"class"`
-	Functions []ComponentDefinitionFunctionType `xml:"functiontype"`
-	BindingList ComponentDefinitionBindingList `xml:"bindings"`
-	ImplementationList ComponentDefinitionImplementationList `xml:"implementations"`
-	Enums []ComponentDefinitionEnum `xml:"enum"`
-	Structs []ComponentDefinitionStruct `xml:"struct"`
-	Global ComponentDefinitionGlobal `xml:"global"`
-	Errors ComponentDefinitionErrors `xml:"errors"`
+	ACTVersion string `xml:"-" json:"actversion,omitempty"`
+	XMLName xml.Name `xml:"component" json:"-"`
+	Version string `xml:"version,attr" json:"version"`
+	Copyright string `xml:"copyright,attr" json:"copyright"`
+	Year int `xml:"year,attr" json:"year"`
+	NameSpace string `xml:"namespace,attr" json:"namespace"`
+	LibraryName string `xml:"libraryname,attr" json:"libraryname"`
+	BaseName string `xml:"basename,attr" json:"basename"`
+	License ComponentDefinitionLicense `xml:"license" json:"license"`
+	Classes []ComponentDefinitionClass `xml:"class" json:"class"`
+	Functions []ComponentDefinitionFunctionType `xml:"functiontype" json:"functiontype"`
+	BindingList ComponentDefinitionBindingList `xml:"bindings" json:"bindings"`
+	ImplementationList ComponentDefinitionImplementationList `xml:"implementations" json:"implementations"`
+	Enums []ComponentDefinitionEnum `xml:"enum" json:"enum"`
+	Structs []ComponentDefinitionStruct `xml:"struct" json:"struct"`
+	Global ComponentDefinitionGlobal `xml:"global" json:"global"`
+	Errors ComponentDefinitionErrors `xml:"errors" json:"errors"`
 }
 
 func getIndentationString (str string) string {
@@ -692,6 +699,16 @@ func CheckComponentDefinition (component ComponentDefinition) (error) {
 		return err
 	}
 
+	err = checkErrorMethod(component.Global)
+	if err != nil {
+		return err
+	}
+
+	err = checkAsyncMethods(component.Global)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -718,6 +735,10 @@ func CheckHeaderSpecialFunction (method ComponentDefinitionMethod, global Compon
 	if (global.JournalMethod == global.VersionMethod) {
 		return eSpecialMethodNone, errors.New ("Journal method can not be the same as the Version method");
 	}
+
+	if (global.ErrorMethod != "") && ((global.ErrorMethod == global.ReleaseMethod) || (global.ErrorMethod == global.VersionMethod) || (global.ErrorMethod == global.JournalMethod)) {
+		return eSpecialMethodNone, errors.New ("Error method can not be the same as the Release, Version or Journal method");
+	}
 	
 	if (method.MethodName == global.ReleaseMethod) {
 		if (len (method.Params) != 1) {
@@ -756,8 +777,123 @@ func CheckHeaderSpecialFunction (method ComponentDefinitionMethod, global Compon
 		
 		return eSpecialMethodVersion, nil;
 	}
+
+	if (method.MethodName == global.ErrorMethod) {
+		if (len (method.Params) != 3) {
+			return eSpecialMethodNone, errors.New ("Error method does not match the expected function template");
+		}
+
+		if (method.Params[0].ParamType != "handle") || (method.Params[0].ParamClass != "BaseClass") || (method.Params[0].ParamPass != "in") ||
+			(method.Params[1].ParamType != "string") || (method.Params[1].ParamPass != "out") ||
+			(method.Params[2].ParamType != "bool") || (method.Params[2].ParamPass != "return") {
+			return eSpecialMethodNone, errors.New ("Error method does not match the expected function template");
+		}
+
+		return eSpecialMethodError, nil;
+	}
 	
 	return eSpecialMethodNone, nil;
 }
 
+// GetErrorMethod returns the error method of the global functions, if the component has one
+func (global ComponentDefinitionGlobal) GetErrorMethod () (ComponentDefinitionMethod, bool) {
+	if (global.ErrorMethod != "") {
+		for _, method := range global.Methods {
+			if (method.MethodName == global.ErrorMethod) {
+				return method, true
+			}
+		}
+	}
+	return ComponentDefinitionMethod{}, false
+}
+
+// checkErrorMethod checks that the optional error method exists and matches its function template
+func checkErrorMethod (global ComponentDefinitionGlobal) (error) {
+	if (global.ErrorMethod == "") {
+		return nil
+	}
+	method, found := global.GetErrorMethod()
+	if (!found) {
+		return fmt.Errorf ("Error method \"%s\" is not a global method", global.ErrorMethod)
+	}
+	_, err := CheckHeaderSpecialFunction (method, global)
+	return err
+}
+
+// checkAsyncMethods checks that none of the special global methods is asynchronous
+func checkAsyncMethods (global ComponentDefinitionGlobal) (error) {
+	for _, method := range global.Methods {
+		if (!method.Async) {
+			continue
+		}
+		specialMethod, err := CheckHeaderSpecialFunction (method, global)
+		if (err != nil) {
+			return err
+		}
+		if (specialMethod != eSpecialMethodNone) {
+			return fmt.Errorf ("special method \"%s\" can not be asynchronous", method.MethodName)
+		}
+	}
+	return nil
+}
+
+// HasAsyncMethods returns whether a method of the component is asynchronous
+func (component ComponentDefinition) HasAsyncMethods () (bool) {
+	for _, method := range component.Global.Methods {
+		if (method.Async) {
+			return true
+		}
+	}
+	for _, class := range component.Classes {
+		for _, method := range class.Methods {
+			if (method.Async) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetCancellationCallback returns the callback parameter of a method, through which an asynchronous call of it can be
+// cancelled, and the index of the boolean output parameter of its function type, that aborts the call.
+// This is the first callback, whose function type has a boolean output, e.g. a progress callback.
+func (component ComponentDefinition) GetCancellationCallback (method ComponentDefinitionMethod) (ComponentDefinitionParam, int, bool) {
+	for _, param := range method.Params {
+		if (param.ParamType != "functiontype") || (param.ParamPass != "in") {
+			continue
+		}
+		for _, functiontype := range component.Functions {
+			if (functiontype.FunctionName != param.ParamClass) {
+				continue
+			}
+			for i, functionParam := range functiontype.Params {
+				if (functionParam.ParamType == "bool") && (functionParam.ParamPass != "in") {
+					return param, i, true
+				}
+			}
+		}
+	}
+	return ComponentDefinitionParam{}, -1, false
+}
+
+// GetCancellationTypes returns the function types, through which asynchronous calls are cancelled, together with
+// the index of their boolean output parameter, that aborts the call
+func (component ComponentDefinition) GetCancellationTypes () (map[string]int) {
+	cancellationTypes := make(map[string]int)
+	methods := append([]ComponentDefinitionMethod{}, component.Global.Methods...)
+	for _, class := range component.Classes {
+		methods = append(methods, class.Methods...)
+	}
+	for _, method := range methods {
+		if (!method.Async) {
+			continue
+		}
+		param, abortIndex, isCancellable := component.GetCancellationCallback(method)
+		if (isCancellable) {
+			cancellationTypes[param.ParamClass] = abortIndex
+		}
+	}
+	return cancellationTypes
+}
+
 
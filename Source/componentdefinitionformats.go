@@ -0,0 +1,123 @@
+/*++
+
+Copyright (C) 2018 Autodesk Inc. (Original Author)
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+--*/
+
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+// componentdefinitionformats.go
+// Reads and writes component definitions as XML, JSON or YAML
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	eComponentDefinitionFormatXML = "xml"
+	eComponentDefinitionFormatJSON = "json"
+	eComponentDefinitionFormatYAML = "yaml"
+)
+
+// ACTNamespace is the XML namespace of component definitions
+const ACTNamespace = "http://schemas.autodesk.com/netfabb/automaticcomponenttoolkit/2018"
+
+// GetComponentDefinitionFormat determines the format of a component definition file from its extension
+func GetComponentDefinitionFormat(fileName string) (string, error) {
+	switch (strings.ToLower(filepath.Ext(fileName))) {
+		case ".xml":
+			return eComponentDefinitionFormatXML, nil
+		case ".json":
+			return eComponentDefinitionFormatJSON, nil
+		case ".yaml", ".yml":
+			return eComponentDefinitionFormatYAML, nil
+	}
+	return "", fmt.Errorf ("unknown format of component definition \"%s\", use one of the extensions .xml, .json, .yaml or .yml", fileName)
+}
+
+// UnmarshalComponentDefinition parses a component definition in the given format
+func UnmarshalComponentDefinition(data []byte, format string) (ComponentDefinition, error) {
+	var component ComponentDefinition
+	var err error
+	switch (format) {
+		case eComponentDefinitionFormatXML:
+			err = xml.Unmarshal(data, &component)
+		case eComponentDefinitionFormatJSON:
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.DisallowUnknownFields()
+			err = decoder.Decode(&component)
+		case eComponentDefinitionFormatYAML:
+			err = UnmarshalYAML(data, &component)
+		default:
+			err = fmt.Errorf ("unknown format of component definition \"%s\"", format)
+	}
+	return component, err
+}
+
+// MarshalComponentDefinition writes a component definition in the given format
+func MarshalComponentDefinition(component ComponentDefinition, format string) ([]byte, error) {
+	component.ACTVersion = ""
+	switch (format) {
+		case eComponentDefinitionFormatXML:
+			output, err := xml.MarshalIndent(component, "", "\t")
+			if (err != nil) {
+				return nil, err
+			}
+			// the tag of XMLName takes precedence over its namespace, so the namespace is added here
+			document := strings.Replace(string(output), "<component", "<component xmlns=\"" + ACTNamespace + "\"", 1)
+			return []byte(xml.Header + document + "\n"), nil
+		case eComponentDefinitionFormatJSON:
+			output, err := json.MarshalIndent(component, "", "\t")
+			if (err != nil) {
+				return nil, err
+			}
+			return append(output, '\n'), nil
+		case eComponentDefinitionFormatYAML:
+			return MarshalYAML(component)
+	}
+	return nil, fmt.Errorf ("unknown format of component definition \"%s\"", format)
+}
+
+// WriteComponentDefinition writes a component definition into a file, the format is determined by its extension
+func WriteComponentDefinition(component ComponentDefinition, fileName string) (error) {
+	format, err := GetComponentDefinitionFormat(fileName)
+	if (err != nil) {
+		return err
+	}
+	output, err := MarshalComponentDefinition(component, format)
+	if (err != nil) {
+		return err
+	}
+	return ioutil.WriteFile(fileName, output, 0644)
+}
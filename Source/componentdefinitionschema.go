@@ -0,0 +1,223 @@
+/*++
+
+Copyright (C) 2018 Autodesk Inc. (Original Author)
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+--*/
+
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+// componentdefinitionschema.go
+// Checks component definitions for unknown elements and attributes and exports the XSD and JSON schema
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+//go:embed ACT.xsd
+var componentDefinitionXSD []byte
+
+// CheckComponentDefinitionXMLStructure returns a message for every element, attribute and text in a
+// component definition that does not correspond to a field of ComponentDefinition
+func CheckComponentDefinitionXMLStructure(data []byte) ([]string, error) {
+	issues := make([]string, 0)
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	types := make([]reflect.Type, 0)
+	names := make([]string, 0)
+	for {
+		line, _ := decoder.InputPos()
+		token, err := decoder.Token()
+		if (err == io.EOF) {
+			break
+		}
+		if (err != nil) {
+			return issues, err
+		}
+
+		switch element := token.(type) {
+			case xml.StartElement:
+				var elementType reflect.Type
+				if (len(types) == 0) {
+					if (element.Name.Local != "component") {
+						return issues, fmt.Errorf ("the root element must be <component>, not <%s>", element.Name.Local)
+					}
+					elementType = reflect.TypeOf(ComponentDefinition{})
+				} else {
+					if (element.Name.Space != "") && (element.Name.Space != ACTNamespace) {
+						// elements of other namespaces are allowed as extensions
+						err = decoder.Skip()
+						if (err != nil) {
+							return issues, err
+						}
+						continue
+					}
+					elementType = xmlChildType(types[len(types) - 1], element.Name.Local)
+					if (elementType == nil) {
+						issues = append(issues, fmt.Sprintf ("unknown element <%s> in <%s> in line %d", element.Name.Local, names[len(names) - 1], line))
+						err = decoder.Skip()
+						if (err != nil) {
+							return issues, err
+						}
+						continue
+					}
+				}
+
+				for _, attribute := range element.Attr {
+					if (attribute.Name.Space != "") || (attribute.Name.Local == "xmlns") {
+						continue
+					}
+					if (!xmlHasAttribute(elementType, attribute.Name.Local)) {
+						issues = append(issues, fmt.Sprintf ("unknown attribute \"%s\" of <%s> in line %d", attribute.Name.Local, element.Name.Local, line))
+					}
+				}
+				types = append(types, elementType)
+				names = append(names, element.Name.Local)
+
+			case xml.EndElement:
+				types = types[:len(types) - 1]
+				names = names[:len(names) - 1]
+
+			case xml.CharData:
+				text := strings.TrimSpace(string(element))
+				if (text != "") && (len(names) > 0) {
+					issues = append(issues, fmt.Sprintf ("unexpected text \"%s\" in <%s> in line %d", text, names[len(names) - 1], line))
+				}
+		}
+	}
+	return issues, nil
+}
+
+// xmlChildType returns the type of the child element with the given name, or nil if there is none
+func xmlChildType(parentType reflect.Type, name string) (reflect.Type) {
+	for i := 0; i < parentType.NumField(); i++ {
+		field := parentType.Field(i)
+		tag := strings.Split(field.Tag.Get("xml"), ",")
+		if (field.Name == "XMLName") || (len(tag) > 1) || (tag[0] != name) {
+			continue
+		}
+		if (field.Type.Kind() == reflect.Slice) {
+			return field.Type.Elem()
+		}
+		return field.Type
+	}
+	return nil
+}
+
+func xmlHasAttribute(elementType reflect.Type, name string) (bool) {
+	for i := 0; i < elementType.NumField(); i++ {
+		tag := strings.Split(elementType.Field(i).Tag.Get("xml"), ",")
+		if (len(tag) > 1) && (tag[0] == name) && (tag[1] == "attr") {
+			return true
+		}
+	}
+	return false
+}
+
+// ComponentDefinitionJSONSchema returns a JSON schema of component definitions in JSON or YAML
+func ComponentDefinitionJSONSchema() ([]byte, error) {
+	definitions := make(map[string]interface{})
+	schema := jsonSchemaOfType(reflect.TypeOf(ComponentDefinition{}), definitions)
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "ACT component definition"
+	schema["definitions"] = definitions
+	output, err := json.MarshalIndent(schema, "", "\t")
+	if (err != nil) {
+		return nil, err
+	}
+	return append(output, '\n'), nil
+}
+
+func jsonSchemaOfType(valueType reflect.Type, definitions map[string]interface{}) (map[string]interface{}) {
+	schema := make(map[string]interface{})
+	switch (valueType.Kind()) {
+		case reflect.String:
+			schema["type"] = "string"
+		case reflect.Int:
+			schema["type"] = "integer"
+		case reflect.Bool:
+			schema["type"] = "boolean"
+		case reflect.Slice:
+			schema["type"] = []string{"array", "null"}
+			schema["items"] = jsonSchemaOfType(valueType.Elem(), definitions)
+		case reflect.Struct:
+			properties := make(map[string]interface{})
+			for i := 0; i < valueType.NumField(); i++ {
+				name := yamlFieldName(valueType.Field(i))
+				if (name == "") {
+					continue
+				}
+				fieldType := valueType.Field(i).Type
+				if (fieldType.Kind() == reflect.Struct) || ((fieldType.Kind() == reflect.Slice) && (fieldType.Elem().Kind() == reflect.Struct)) {
+					structType := fieldType
+					if (fieldType.Kind() == reflect.Slice) {
+						structType = fieldType.Elem()
+					}
+					if _, ok := definitions[structType.Name()]; !ok {
+						definitions[structType.Name()] = nil
+						definitions[structType.Name()] = jsonSchemaOfType(structType, definitions)
+					}
+					reference := map[string]interface{}{"$ref": "#/definitions/" + structType.Name()}
+					if (fieldType.Kind() == reflect.Slice) {
+						properties[name] = map[string]interface{}{"type": []string{"array", "null"}, "items": reference}
+					} else {
+						properties[name] = reference
+					}
+					continue
+				}
+				properties[name] = jsonSchemaOfType(fieldType, definitions)
+			}
+			schema["type"] = "object"
+			schema["properties"] = properties
+			schema["additionalProperties"] = false
+	}
+	return schema
+}
+
+// ExportComponentDefinitionSchema writes the XSD (.xsd) or the JSON schema (.json) of component definitions into a file
+func ExportComponentDefinitionSchema(fileName string) (error) {
+	switch (strings.ToLower(filepath.Ext(fileName))) {
+		case ".xsd":
+			return ioutil.WriteFile(fileName, componentDefinitionXSD, 0644)
+		case ".json":
+			schema, err := ComponentDefinitionJSONSchema()
+			if (err != nil) {
+				return err
+			}
+			return ioutil.WriteFile(fileName, schema, 0644)
+	}
+	return fmt.Errorf ("unknown format of schema \"%s\", use the extension .xsd or .json", fileName)
+}
@@ -37,6 +37,7 @@ package main
 
 import (
 	"encoding/xml"
+	"strconv"
 )
 
 // ComponentDiffBase is the base class for all component diff bases
@@ -143,6 +144,13 @@ func diffMethod(path string, methodA ComponentDefinitionMethod, methodB Componen
 		change.NewValue = methodB.MethodDescription
 		changes = append(changes, change)
 	}
+	if (methodA.Async != methodB.Async) {
+		var change ComponentDiffAttributeChange
+		change.Path = pathA + "/async"
+		change.OldValue = strconv.FormatBool(methodA.Async)
+		change.NewValue = strconv.FormatBool(methodB.Async)
+		changes = append(changes, change)
+	}
 	
 	IFirstChangedParam := len(methodA.Params)
 	for iA, paramA := range(methodA.Params) {
@@ -494,6 +502,13 @@ func diffGlobal(path string, globalA ComponentDefinitionGlobal, globalB Componen
 		change.NewValue = globalB.VersionMethod
 		changes = append(changes, change)
 	}
+	if (globalA.ErrorMethod != globalB.ErrorMethod) {
+		var change ComponentDiffAttributeChange
+		change.Path = pathA + "/errormethod"
+		change.OldValue = globalA.ErrorMethod
+		change.NewValue = globalB.ErrorMethod
+		changes = append(changes, change)
+	}
 
 	for _, methodA := range(globalA.Methods) {
 		BHasMethodA := false
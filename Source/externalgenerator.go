@@ -0,0 +1,203 @@
+/*++
+
+Copyright (C) 2018 Autodesk Inc. (Original Author)
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+--*/
+
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+// externalgenerator.go
+// Runs generators of bindings and implementations that are not part of ACT as subprocesses
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	eExternalGeneratorCommandValidate = "validate"
+	eExternalGeneratorCommandGenerate = "generate"
+)
+
+const (
+	eExternalGeneratorFileKindStub = "stub"
+	eExternalGeneratorFileKindExample = "example"
+	eExternalGeneratorFileKindProject = "project"
+)
+
+// ExternalGeneratorRequest is written as JSON to the standard input of an external generator
+type ExternalGeneratorRequest struct {
+	ACTVersion string `json:"actversion"`
+	Command string `json:"command"`
+	Options GeneratorOptions `json:"options"`
+	Component ComponentDefinition `json:"component"`
+}
+
+// ExternalGeneratorFile is a single file created by an external generator
+type ExternalGeneratorFile struct {
+	Path string `json:"path"`
+	Content string `json:"content"`
+	Kind string `json:"kind"`
+}
+
+// ExternalGeneratorResponse is read as JSON from the standard output of an external generator
+type ExternalGeneratorResponse struct {
+	Error string `json:"error"`
+	Files []ExternalGeneratorFile `json:"files"`
+}
+
+// ExternalGenerator is a Generator that runs an executable for a language
+type ExternalGenerator struct {
+	Language string
+	Executable string
+}
+
+// RegisterExternalGenerator makes an executable available as generator of bindings and implementations.
+// The definition has the form "LANGUAGE=EXECUTABLE"
+func RegisterExternalGenerator(definition string) (error) {
+	index := strings.Index(definition, "=")
+	if (index < 1) || (index == len(definition) - 1) {
+		return fmt.Errorf ("invalid plugin definition \"%s\", expected \"LANGUAGE=EXECUTABLE\"", definition)
+	}
+	generator := ExternalGenerator{Language: definition[:index], Executable: definition[index+1:]}
+	RegisterBindingGenerator(generator)
+	RegisterImplementationGenerator(generator)
+	return nil
+}
+
+// Name returns the language of the generator
+func (generator ExternalGenerator) Name() (string) {
+	return generator.Language
+}
+
+// Validate asks the executable whether it can generate code for the component with the given options
+func (generator ExternalGenerator) Validate(component ComponentDefinition, options GeneratorOptions) (error) {
+	_, err := generator.run(eExternalGeneratorCommandValidate, component, options)
+	return err
+}
+
+// Generate asks the executable for the generated files and writes them into the output folder of the component
+func (generator ExternalGenerator) Generate(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	response, err := generator.run(eExternalGeneratorCommandGenerate, component, options)
+	if (err != nil) {
+		return err
+	}
+
+	for _, file := range response.Files {
+		err = writeExternalGeneratorFile(file, outputFolder, options.ForceRecreation)
+		if (err != nil) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (generator ExternalGenerator) run(command string, component ComponentDefinition, options GeneratorOptions) (ExternalGeneratorResponse, error) {
+	var response ExternalGeneratorResponse
+
+	var request ExternalGeneratorRequest
+	request.ACTVersion = component.ACTVersion
+	request.Command = command
+	request.Options = options
+	request.Component = component
+	input, err := json.Marshal(request)
+	if (err != nil) {
+		return response, err
+	}
+
+	var output bytes.Buffer
+	cmd := exec.Command(generator.Executable)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &output
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if (err != nil) {
+		return response, fmt.Errorf ("plugin \"%s\" for language \"%s\" failed: %v", generator.Executable, generator.Language, err)
+	}
+
+	err = json.Unmarshal(output.Bytes(), &response)
+	if (err != nil) {
+		return response, fmt.Errorf ("plugin \"%s\" for language \"%s\" returned an invalid response: %v", generator.Executable, generator.Language, err)
+	}
+	if (response.Error != "") {
+		return response, fmt.Errorf ("plugin \"%s\" for language \"%s\": %s", generator.Executable, generator.Language, response.Error)
+	}
+	return response, nil
+}
+
+func writeExternalGeneratorFile(file ExternalGeneratorFile, outputFolder string, forceRecreation ForceRecreation) (error) {
+	relativePath := filepath.ToSlash(file.Path)
+	if (relativePath == "") || path.IsAbs(relativePath) || filepath.IsAbs(file.Path) {
+		return fmt.Errorf ("plugin file path \"%s\" must be relative to the component folder", file.Path)
+	}
+	for _, element := range strings.Split(relativePath, "/") {
+		if (element == "..") {
+			return fmt.Errorf ("plugin file path \"%s\" must not leave the component folder", file.Path)
+		}
+	}
+	fileName := path.Join(outputFolder, relativePath)
+
+	force := true
+	switch (file.Kind) {
+		case "":
+		case eExternalGeneratorFileKindStub:
+			force = forceRecreation.Stubs
+		case eExternalGeneratorFileKindExample:
+			force = forceRecreation.Examples
+		case eExternalGeneratorFileKindProject:
+			force = forceRecreation.Projects
+		default:
+			return fmt.Errorf ("invalid kind \"%s\" of plugin file \"%s\"", file.Kind, file.Path)
+	}
+
+	if (file.Kind != "") && FileExists(fileName) {
+		if (!force) {
+			log.Printf("Omitting recreation of %s \"%s\"", file.Kind, fileName)
+			return nil
+		}
+		err := BackupFile(fileName)
+		if (err != nil) {
+			return err
+		}
+	}
+
+	err := os.MkdirAll(path.Dir(fileName), os.ModePerm)
+	if (err != nil) {
+		return err
+	}
+	log.Printf("Creating \"%s\"", fileName)
+	return ioutil.WriteFile(fileName, []byte(file.Content), 0644)
+}
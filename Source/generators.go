@@ -0,0 +1,492 @@
+/*++
+
+Copyright (C) 2018 Autodesk Inc. (Original Author)
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+--*/
+
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+// generators.go
+// The interface of all generators of bindings and implementations, and the registry of the available generators
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+)
+
+const (
+	eGeneratorKindBinding = "binding"
+	eGeneratorKindImplementation = "implementation"
+)
+
+// GeneratorOptions contains the settings of a binding or implementation element of the IDL and of the command line
+type GeneratorOptions struct {
+	Kind string `json:"kind"`
+	Language string `json:"language"`
+	Indentation string `json:"indentation"`
+	ClassIdentifier string `json:"classidentifier"`
+	StubIdentifier string `json:"stubidentifier"`
+	ImportPath string `json:"importpath"`
+	Package string `json:"package"`
+	HeaderOnly bool `json:"headeronly"`
+	CppStandard string `json:"cppstandard"`
+	ForceRecreation ForceRecreation `json:"forcerecreation"`
+}
+
+// Generator generates the bindings or the implementation of a component's API in a specific language
+type Generator interface {
+	Name() string
+	Validate(component ComponentDefinition, options GeneratorOptions) error
+	Generate(component ComponentDefinition, outputFolder string, options GeneratorOptions) error
+}
+
+// builtinGenerator is a Generator that is compiled into ACT
+type builtinGenerator struct {
+	name string
+	validate func(component ComponentDefinition, options GeneratorOptions) error
+	generate func(component ComponentDefinition, outputFolder string, options GeneratorOptions) error
+}
+
+// Name returns the language of the generator
+func (generator builtinGenerator) Name() (string) {
+	return generator.name
+}
+
+// Validate checks whether the generator can generate code for the component with the given options
+func (generator builtinGenerator) Validate(component ComponentDefinition, options GeneratorOptions) (error) {
+	if (generator.validate == nil) {
+		return nil
+	}
+	return generator.validate(component, options)
+}
+
+// Generate writes the generated files into the output folder of the component
+func (generator builtinGenerator) Generate(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	return generator.generate(component, outputFolder, options)
+}
+
+var bindingGenerators = make(map[string]Generator)
+var implementationGenerators = make(map[string]Generator)
+
+// RegisterBindingGenerator makes a generator available for the bindings of its language
+func RegisterBindingGenerator(generator Generator) {
+	bindingGenerators[generator.Name()] = generator
+}
+
+// RegisterImplementationGenerator makes a generator available for the implementations of its language
+func RegisterImplementationGenerator(generator Generator) {
+	implementationGenerators[generator.Name()] = generator
+}
+
+// GetBindingGenerator returns the generator of bindings for a language
+func GetBindingGenerator(language string) (Generator, error) {
+	generator, ok := bindingGenerators[language]
+	if (!ok) {
+		return nil, fmt.Errorf ("Unknown binding export \"%s\", available languages are %v", language, generatorNames(bindingGenerators))
+	}
+	return generator, nil
+}
+
+// GetImplementationGenerator returns the generator of implementations for a language
+func GetImplementationGenerator(language string) (Generator, error) {
+	generator, ok := implementationGenerators[language]
+	if (!ok) {
+		return nil, fmt.Errorf ("Unknown implementation export \"%s\", available languages are %v", language, generatorNames(implementationGenerators))
+	}
+	return generator, nil
+}
+
+func generatorNames(generators map[string]Generator) ([]string) {
+	names := make([]string, 0)
+	for name := range generators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	RegisterBindingGenerator(builtinGenerator{name: "C", generate: generateBindingC})
+	RegisterBindingGenerator(builtinGenerator{name: "CDynamic", generate: generateBindingCDynamic})
+	RegisterBindingGenerator(builtinGenerator{name: "CppDynamic", validate: validateBindingCpp, generate: generateBindingCppDynamic})
+	RegisterBindingGenerator(builtinGenerator{name: "Cpp", validate: validateBindingCpp, generate: generateBindingCpp})
+	RegisterBindingGenerator(builtinGenerator{name: "CSharp", validate: validateBindingCSharp, generate: generateBindingCSharp})
+	RegisterBindingGenerator(builtinGenerator{name: "Fortran", validate: validateBindingFortran, generate: generateBindingFortran})
+	RegisterBindingGenerator(builtinGenerator{name: "Go", validate: validateBindingGo, generate: generateBindingGo})
+	RegisterBindingGenerator(builtinGenerator{name: "Java", validate: validateBindingJava, generate: generateBindingJava})
+	RegisterBindingGenerator(builtinGenerator{name: "Lua", validate: validateBindingLua, generate: generateBindingLua})
+	RegisterBindingGenerator(builtinGenerator{name: "Node", generate: generateBindingNode})
+	RegisterBindingGenerator(builtinGenerator{name: "Pascal", generate: generateBindingPascal})
+	RegisterBindingGenerator(builtinGenerator{name: "Python", validate: validateBindingPython, generate: generateBindingPython})
+	RegisterBindingGenerator(builtinGenerator{name: "Rust", validate: validateBindingRust, generate: generateBindingRust})
+	RegisterBindingGenerator(builtinGenerator{name: "Swift", validate: validateBindingSwift, generate: generateBindingSwift})
+
+	RegisterImplementationGenerator(builtinGenerator{name: "Cpp", generate: generateImplementationCpp})
+	RegisterImplementationGenerator(builtinGenerator{name: "Go", validate: validateImplementationGo, generate: generateImplementationGo})
+	RegisterImplementationGenerator(builtinGenerator{name: "Pascal", validate: validateImplementationPascal, generate: generateImplementationPascal})
+	RegisterImplementationGenerator(builtinGenerator{name: "Fortran", validate: validateImplementationFortran, generate: generateImplementationFortran})
+}
+
+func generateBindingC(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	outputFolderBindingC := path.Join(outputFolder, "Bindings", "C");
+	err := os.MkdirAll(outputFolderBindingC, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	return BuildBindingC(component, outputFolderBindingC)
+}
+
+func generateBindingCDynamic(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	outputFolderBindingCDynamic := path.Join(outputFolder, "Bindings", "CDynamic");
+	err := os.MkdirAll(outputFolderBindingCDynamic, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	CTypesHeaderName := path.Join(outputFolderBindingCDynamic, component.BaseName + "_types.h");
+	err = CreateCTypesHeader (component, CTypesHeaderName);
+	if (err != nil) {
+		return err;
+	}
+
+	return BuildBindingCDynamic(component, outputFolderBindingCDynamic, getIndentationString(options.Indentation));
+}
+
+func generateBindingCppDynamic(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	outputFolderBindingCppDynamic := path.Join(outputFolder, "Bindings", "CppDynamic");
+	err := os.MkdirAll(outputFolderBindingCppDynamic, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+	outputFolderExampleCppDynamic := path.Join(outputFolder, "Examples", "CppDynamic");
+	err = os.MkdirAll(outputFolderExampleCppDynamic, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	CTypesHeaderName := path.Join(outputFolderBindingCppDynamic, component.BaseName + "_types.h");
+	err = CreateCTypesHeader (component, CTypesHeaderName);
+	if (err != nil) {
+		return err;
+	}
+
+	return BuildBindingCppDynamic(component, outputFolderBindingCppDynamic, outputFolderExampleCppDynamic,
+		getIndentationString(options.Indentation), options.CppStandard, options.ForceRecreation);
+}
+
+func generateBindingCpp(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	outputFolderBindingCpp := path.Join(outputFolder, "Bindings", "Cpp");
+	err := os.MkdirAll(outputFolderBindingCpp, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+	outputFolderExampleCPP := path.Join(outputFolder, "Examples", "CPP");
+	err = os.MkdirAll(outputFolderExampleCPP, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	CTypesHeaderName := path.Join(outputFolderBindingCpp, component.BaseName + "_types.h");
+	err = CreateCTypesHeader (component, CTypesHeaderName);
+	if (err != nil) {
+		return err;
+	}
+
+	CHeaderName := path.Join(outputFolderBindingCpp, component.BaseName + ".h");
+	err = CreateCHeader (component, CHeaderName);
+	if (err != nil) {
+		return err;
+	}
+
+	return BuildBindingCPP(component, outputFolderBindingCpp, outputFolderExampleCPP,
+		getIndentationString(options.Indentation), options.HeaderOnly, options.CppStandard);
+}
+
+func generateBindingCSharp(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	outputFolderBindingCSharp := path.Join(outputFolder, "Bindings", "CSharp");
+	err := os.MkdirAll(outputFolderBindingCSharp, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	return BuildBindingCSharp(component, outputFolderBindingCSharp, getIndentationString(options.Indentation));
+}
+
+func generateBindingFortran(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	outputFolderBindingFortran := path.Join(outputFolder, "Bindings", "Fortran");
+	err := os.MkdirAll(outputFolderBindingFortran, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	return BuildBindingFortran(component, outputFolderBindingFortran, getIndentationString(options.Indentation));
+}
+
+func generateBindingGo(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	outputFolderBindingGo := path.Join(outputFolder, "Bindings", "Go");
+	err := os.MkdirAll(outputFolderBindingGo, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	return BuildBindingGo(component, outputFolderBindingGo, options.ImportPath);
+}
+
+func generateBindingJava(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	outputFolderBindingJava := path.Join(outputFolder, "Bindings", "Java");
+	err := os.MkdirAll(outputFolderBindingJava, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	return BuildBindingJava(component, outputFolderBindingJava, getIndentationString(options.Indentation));
+}
+
+func generateBindingLua(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	outputFolderBindingLua := path.Join(outputFolder, "Bindings", "Lua");
+	err := os.MkdirAll(outputFolderBindingLua, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	CTypesHeaderName := path.Join(outputFolderBindingLua, component.BaseName + "_types.h");
+	err = CreateCTypesHeader (component, CTypesHeaderName);
+	if (err != nil) {
+		return err;
+	}
+
+	err = BuildBindingCDynamic(component, outputFolderBindingLua, getIndentationString(options.Indentation));
+	if (err != nil) {
+		return err;
+	}
+
+	return BuildBindingLua(component, outputFolderBindingLua, getIndentationString(options.Indentation));
+}
+
+func generateBindingNode(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	outputFolderBindingNode := path.Join(outputFolder, "Bindings", "NodeJS");
+	err := os.MkdirAll(outputFolderBindingNode, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	indentString := getIndentationString(options.Indentation)
+	CTypesHeaderName := path.Join(outputFolderBindingNode, component.BaseName + "_types.h");
+	err = CreateCTypesHeader (component, CTypesHeaderName);
+	if (err != nil) {
+		return err;
+	}
+
+	err = BuildBindingCDynamic(component, outputFolderBindingNode, indentString);
+	if (err != nil) {
+		return err;
+	}
+
+	return BuildBindingNode(component, outputFolderBindingNode, indentString);
+}
+
+func generateBindingPascal(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	outputFolderBindingPascal := path.Join(outputFolder, "Bindings", "Pascal");
+	err := os.MkdirAll(outputFolderBindingPascal, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+	outputFolderExamplePascal := path.Join(outputFolder, "Examples", "Pascal");
+	err = os.MkdirAll(outputFolderExamplePascal, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	return BuildBindingPascalDynamic(component, outputFolderBindingPascal, outputFolderExamplePascal,
+		getIndentationString(options.Indentation), options.ForceRecreation);
+}
+
+func generateBindingPython(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	outputFolderBindingPython := path.Join(outputFolder, "Bindings", "Python");
+	err := os.MkdirAll(outputFolderBindingPython, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+	outputFolderExamplePython := path.Join(outputFolder, "Examples", "Python");
+	err = os.MkdirAll(outputFolderExamplePython, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	return BuildBindingPythonDynamic(component, outputFolderBindingPython, outputFolderExamplePython,
+		getIndentationString(options.Indentation), options.Package, options.ForceRecreation);
+}
+
+func generateBindingRust(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	outputFolderBindingRust := path.Join(outputFolder, "Bindings", "Rust");
+	err := os.MkdirAll(outputFolderBindingRust, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	return BuildBindingRust(component, outputFolderBindingRust, getIndentationString(options.Indentation));
+}
+
+func generateBindingSwift(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	outputFolderBindingSwift := path.Join(outputFolder, "Bindings", "Swift");
+	err := os.MkdirAll(outputFolderBindingSwift, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	return BuildBindingSwift(component, outputFolderBindingSwift, getIndentationString(options.Indentation));
+}
+
+func generateImplementationCpp(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	outputFolderImplementationProject := path.Join(outputFolder, "Implementations", "Cpp");
+	outputFolderImplementationCpp := path.Join(outputFolderImplementationProject, "Interfaces");
+	outputFolderImplementationCppStub := path.Join(outputFolderImplementationProject, "Stub");
+
+	err := os.MkdirAll(outputFolderImplementationCpp, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+	err = os.MkdirAll(outputFolderImplementationCppStub, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	CTypesHeaderName := path.Join(outputFolderImplementationCpp, component.BaseName + "_types.h");
+	err = CreateCTypesHeader (component, CTypesHeaderName);
+	if (err != nil) {
+		return err;
+	}
+
+	CHeaderName := path.Join(outputFolderImplementationCpp, component.BaseName + ".h");
+	err = CreateCHeader (component, CHeaderName);
+	if (err != nil) {
+		return err;
+	}
+
+	return BuildImplementationCPP(component, outputFolderImplementationCpp, outputFolderImplementationCppStub,
+		outputFolderImplementationProject, options.implementation(), options.ForceRecreation);
+}
+
+func validateImplementationPascal(component ComponentDefinition, options GeneratorOptions) (error) {
+	if (options.StubIdentifier == "") {
+		return fmt.Errorf ("pascal Stub Identifier must not be empty");
+	}
+	return nil
+}
+
+func generateImplementationPascal(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	outputFolderImplementationProject := path.Join(outputFolder, "Implementations", "Pascal");
+	outputFolderImplementationPascal := path.Join(outputFolderImplementationProject, "Interfaces");
+	outputFolderImplementationPascalStub := path.Join(outputFolderImplementationProject, "Stub");
+
+	err := os.MkdirAll(outputFolderImplementationPascal, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+	err = os.MkdirAll(outputFolderImplementationPascalStub, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	return BuildImplementationPascal(component, outputFolderImplementationPascal, outputFolderImplementationPascalStub,
+		outputFolderImplementationProject, options.implementation(), options.ForceRecreation);
+}
+
+func generateImplementationGo(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	outputFolderImplementationGo := path.Join(outputFolder, "Implementations", "Go");
+
+	err := os.MkdirAll(outputFolderImplementationGo, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	return BuildImplementationGo(component, outputFolderImplementationGo, options.implementation(), options.ForceRecreation);
+}
+
+func validateImplementationFortran(component ComponentDefinition, options GeneratorOptions) (error) {
+	found := false;
+	for _, errorcode := range component.Errors.Errors {
+		if (errorcode.Name == "NOTIMPLEMENTED") {
+			found = true;
+		}
+	}
+	if (!found) {
+		return fmt.Errorf ("the Fortran implementation requires the error \"NOTIMPLEMENTED\"");
+	}
+	return validateBindingFortran(component, options)
+}
+
+func generateImplementationFortran(component ComponentDefinition, outputFolder string, options GeneratorOptions) (error) {
+	outputFolderImplementationFortran := path.Join(outputFolder, "Implementations", "Fortran");
+
+	err := os.MkdirAll(outputFolderImplementationFortran, os.ModePerm);
+	if (err != nil) {
+		return err;
+	}
+
+	return BuildImplementationFortran(component, outputFolderImplementationFortran, options.implementation(), options.ForceRecreation);
+}
+
+// bindingOptions returns the generator options of a binding element of the IDL
+func bindingOptions(binding ComponentDefinitionBinding, forceRecreation ForceRecreation) (GeneratorOptions) {
+	var options GeneratorOptions
+	options.Kind = eGeneratorKindBinding
+	options.Language = binding.Language
+	options.Indentation = binding.Indentation
+	options.ImportPath = binding.ImportPath
+	options.Package = binding.Package
+	options.HeaderOnly = binding.HeaderOnly
+	options.CppStandard = binding.CppStandard
+	options.ForceRecreation = forceRecreation
+	return options
+}
+
+// implementationOptions returns the generator options of an implementation element of the IDL
+func implementationOptions(implementation ComponentDefinitionImplementation, forceRecreation ForceRecreation) (GeneratorOptions) {
+	var options GeneratorOptions
+	options.Kind = eGeneratorKindImplementation
+	options.Language = implementation.Language
+	options.Indentation = implementation.Indentation
+	options.ClassIdentifier = implementation.ClassIdentifier
+	options.StubIdentifier = implementation.StubIdentifier
+	options.ForceRecreation = forceRecreation
+	return options
+}
+
+// implementation returns the implementation element of the IDL that corresponds to the generator options
+func (options GeneratorOptions) implementation() (ComponentDefinitionImplementation) {
+	var implementation ComponentDefinitionImplementation
+	implementation.Language = options.Language
+	implementation.Indentation = options.Indentation
+	implementation.ClassIdentifier = options.ClassIdentifier
+	implementation.StubIdentifier = options.StubIdentifier
+	return implementation
+}
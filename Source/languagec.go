@@ -65,73 +65,22 @@ func CreateCTypesHeader (component ComponentDefinition, CTypesHeaderName string)
 	if (err != nil) {
 		return err;
 	}
-	hTypesFile.WriteCLicenseHeader (component,
+	err = hTypesFile.WriteCLicenseHeader (component,
 		fmt.Sprintf ("This is an autogenerated plain C Header file with basic types in\norder to allow an easy use of %s", component.LibraryName),
-		true);
+		true)
+	if err != nil {
+		return err
+	}
 
 	err = buildCTypesHeader(component, hTypesFile, component.NameSpace);
 	return err;
 }
 
 func buildCTypesHeader (component ComponentDefinition, w LanguageWriter, NameSpace string) (error) {
-	w.Writeln("#ifndef __%s_TYPES_HEADER", strings.ToUpper (NameSpace));
-	w.Writeln("#define __%s_TYPES_HEADER", strings.ToUpper (NameSpace));
-	w.Writeln("");
-
-
-	w.Writeln("/*************************************************************************************************************************");
-	w.Writeln(" Scalar types definition");
-	w.Writeln("**************************************************************************************************************************/")
-	w.Writeln("")
-	w.Writeln("#ifdef %s_USELEGACYINTEGERTYPES", strings.ToUpper(NameSpace));
-	w.Writeln("")
-	w.Writeln("typedef unsigned char %s_uint8;", NameSpace);
-	w.Writeln("typedef unsigned short %s_uint16 ;", NameSpace);
-	w.Writeln("typedef unsigned int %s_uint32;", NameSpace);
-	w.Writeln("typedef unsigned long long %s_uint64;", NameSpace);
-	w.Writeln("typedef char %s_int8;", NameSpace);
-	w.Writeln("typedef short %s_int16;", NameSpace);
-	w.Writeln("typedef int %s_int32;", NameSpace);
-	w.Writeln("typedef long long %s_int64;", NameSpace);
-	w.Writeln("")
-	w.Writeln("#else // %s_USELEGACYINTEGERTYPES", strings.ToUpper(NameSpace));
-	w.Writeln("")
-	w.Writeln("#include <stdint.h>")
-	w.Writeln("")
-	w.Writeln("typedef uint8_t %s_uint8;", NameSpace);
-	w.Writeln("typedef uint16_t %s_uint16;", NameSpace);
-	w.Writeln("typedef uint32_t %s_uint32;", NameSpace);
-	w.Writeln("typedef uint64_t %s_uint64;", NameSpace);
-	w.Writeln("typedef int8_t %s_int8;", NameSpace);
-	w.Writeln("typedef int16_t %s_int16;", NameSpace);
-	w.Writeln("typedef int32_t %s_int32;", NameSpace);
-	w.Writeln("typedef int64_t %s_int64 ;", NameSpace);
-	w.Writeln("")
-	w.Writeln("#endif // %s_USELEGACYINTEGERTYPES", strings.ToUpper(NameSpace));
-	w.Writeln("")
-	w.Writeln("typedef float %s_single;", NameSpace);
-	w.Writeln("typedef double %s_double;", NameSpace);
-	w.Writeln("")
-
-
-	w.Writeln("/*************************************************************************************************************************");
-	w.Writeln(" General type definitions");
-	w.Writeln("**************************************************************************************************************************/");
-
-	w.Writeln("");
-	w.Writeln("typedef %s_int32 %sResult;", NameSpace, NameSpace);
-	w.Writeln("typedef void * %sHandle;", NameSpace);
-	
-	w.Writeln("");
-	w.Writeln("/*************************************************************************************************************************");
-	w.Writeln(" Version for %s", NameSpace);
-	w.Writeln("**************************************************************************************************************************/");
-	w.Writeln("");
-	w.Writeln("#define %s_VERSION_MAJOR %d", strings.ToUpper (NameSpace), majorVersion(component.Version));
-	w.Writeln("#define %s_VERSION_MINOR %d", strings.ToUpper (NameSpace), minorVersion(component.Version));
-	w.Writeln("#define %s_VERSION_MICRO %d", strings.ToUpper (NameSpace), microVersion(component.Version));
-
-	w.Writeln("");
+	err := w.WriteTemplate("ctypesheader.begin", NewTemplateData(component));
+	if (err != nil) {
+		return err;
+	}
 
 	w.Writeln("/*************************************************************************************************************************");
 	w.Writeln(" Error constants for %s", NameSpace);
@@ -168,7 +117,7 @@ func buildCTypesHeader (component ComponentDefinition, w LanguageWriter, NameSpa
 
 		for i := 0; i < len(component.Enums); i++ {
 			enum := component.Enums[i];
-			w.Writeln("enum e%s%s {", NameSpace, enum.Name);
+			w.Writeln("typedef enum e%s%s {", NameSpace, enum.Name);
 			
 			for j := 0; j < len(enum.Options); j++ {			
 			
@@ -181,7 +130,7 @@ func buildCTypesHeader (component ComponentDefinition, w LanguageWriter, NameSpa
 				w.Writeln("  e%s%s = %d%s", enum.Name, option.Name, option.Value, comma);
 			}
 			
-			w.Writeln("};");
+			w.Writeln("} e%s%s;", NameSpace, enum.Name);
 			w.Writeln("");
 		}
 		
@@ -314,9 +263,7 @@ func buildCTypesHeader (component ComponentDefinition, w LanguageWriter, NameSpa
 		w.Writeln("");
 	}
 	
-	w.Writeln("#endif // __%s_TYPES_HEADER", strings.ToUpper (NameSpace));
-
-	return nil;
+	return w.WriteTemplate("ctypesheader.end", NewTemplateData(component));
 }
 
 // CreateCHeader creates a C header file for the component's API
@@ -325,34 +272,21 @@ func CreateCHeader (component ComponentDefinition, CHeaderName string) (error) {
 	if (err != nil) {
 		return err;
 	}
-	hfile.WriteCLicenseHeader (component,
+	err = hfile.WriteCLicenseHeader (component,
 		fmt.Sprintf ("This is an autogenerated plain C Header file in order to allow an easy\n use of %s", component.LibraryName),
-		true);
+		true)
+	if err != nil {
+		return err
+	}
 	err = buildCHeader (component, hfile, component.NameSpace, component.BaseName);
 	return err;
 }
 
 func buildCHeader (component ComponentDefinition, w LanguageWriter, NameSpace string, BaseName string) (error) {
-	w.Writeln("#ifndef __%s_HEADER", strings.ToUpper (NameSpace));
-	w.Writeln("#define __%s_HEADER", strings.ToUpper (NameSpace));
-	w.Writeln("");
-	w.Writeln("#ifdef __%s_EXPORTS", strings.ToUpper (NameSpace));
-	
-	w.Writeln("#ifdef WIN32");
-	w.Writeln("#define %s_DECLSPEC __declspec (dllexport)", strings.ToUpper (NameSpace));
-	w.Writeln("#else // WIN32");
-	w.Writeln("#define %s_DECLSPEC __attribute__((visibility(\"default\")))", strings.ToUpper (NameSpace));
-	w.Writeln("#endif // WIN32");
-	
-	w.Writeln("#else // __%s_EXPORTS", strings.ToUpper (NameSpace));
-	w.Writeln("#define %s_DECLSPEC", strings.ToUpper (NameSpace));
-	w.Writeln("#endif // __%s_EXPORTS", strings.ToUpper (NameSpace));
-	w.Writeln("");
-
-	w.Writeln("#include \"%s_types.h\"", BaseName);
-	w.Writeln("");
-
-	w.Writeln("extern \"C\" {");
+	err := w.WriteTemplate("cheader.begin", NewTemplateData(component));
+	if (err != nil) {
+		return err;
+	}
 
 	for i := 0; i < len(component.Classes); i++ {
 		class := component.Classes[i];		
@@ -383,13 +317,7 @@ func buildCHeader (component ComponentDefinition, w LanguageWriter, NameSpace st
 		}
 	}
 	
-	w.Writeln("");
-	w.Writeln("}");
-	w.Writeln("");
-	w.Writeln("#endif // __%s_HEADER", strings.ToUpper (NameSpace));
-	w.Writeln("");
-	
-	return nil;
+	return w.WriteTemplate("cheader.end", NewTemplateData(component));
 }
 
 
@@ -406,6 +334,68 @@ func GetCExportName (NameSpace string, ClassName string, method ComponentDefinit
 }
 
 
+// CStringOutputSyntax describes how a binding passes the buffer of a string output parameter to the C DLL.
+// All formats get the name of the parameter as %[1]s.
+type CStringOutputSyntax struct {
+	// Declaration declares the variable that receives the needed number of characters
+	Declaration string
+	// NeededChars is the expression of the needed number of characters
+	NeededChars string
+	// Allocation allocates the buffer, its number of characters is passed as %[2]s
+	Allocation string
+	// QueryArguments are the arguments of the call that queries the needed number of characters
+	QueryArguments string
+	// CallArguments are the arguments of the call that passes the buffer and its size
+	CallArguments string
+}
+
+// CBufferCalls collects the statements and arguments of a call of an export of the C DLL.
+// Strings and arrays are returned in two calls: the first one queries the needed buffer sizes,
+// the second one fills the buffers that are allocated in between.
+type CBufferCalls struct {
+	Declarations []string
+	Preparations []string
+	QueryArguments []string
+	CallArguments []string
+	RequiresQuery bool
+}
+
+// AddArgument adds an argument that is passed in both calls
+func (calls *CBufferCalls) AddArgument (argument string) {
+	calls.QueryArguments = append (calls.QueryArguments, argument);
+	calls.CallArguments = append (calls.CallArguments, argument);
+}
+
+// AddBufferArgument adds the arguments of an output buffer, that is allocated by preparations after its size is queried
+func (calls *CBufferCalls) AddBufferArgument (queryArgument string, callArgument string, preparations ...string) {
+	calls.RequiresQuery = true;
+	calls.QueryArguments = append (calls.QueryArguments, queryArgument);
+	calls.CallArguments = append (calls.CallArguments, callArgument);
+	calls.Preparations = append (calls.Preparations, preparations...);
+}
+
+// AddStringOutput adds a string output parameter. Its buffer has room for the terminating zero, as the
+// exports reject buffers that are not larger than the string.
+func (calls *CBufferCalls) AddStringOutput (syntax CStringOutputSyntax, name string) {
+	calls.Declarations = append (calls.Declarations, fmt.Sprintf (syntax.Declaration, name));
+	bufferSize := fmt.Sprintf (syntax.NeededChars, name) + " + 1";
+	calls.AddBufferArgument (fmt.Sprintf (syntax.QueryArguments, name), fmt.Sprintf (syntax.CallArguments, name),
+		fmt.Sprintf (syntax.Allocation, name, bufferSize));
+}
+
+// WriteCalls writes the query call, the preparations of the buffers and the call that fills them.
+// callFormat gets the comma separated arguments as %s, checks are written after each call.
+func (calls CBufferCalls) WriteCalls (w LanguageWriter, indent string, callFormat string, checks []string) {
+	if (calls.RequiresQuery) {
+		w.Writeln (indent + callFormat, strings.Join (calls.QueryArguments, ", "));
+		w.Writelns (indent, checks);
+		w.Writelns (indent, calls.Preparations);
+	}
+	w.Writeln (indent + callFormat, strings.Join (calls.CallArguments, ", "));
+	w.Writelns (indent, checks);
+}
+
+
 // WriteCMethod writes a method as a C funtion
 func WriteCMethod (method ComponentDefinitionMethod, w LanguageWriter, NameSpace string, ClassName string, isGlobal bool, writeCallbacks bool) (error) {
 
@@ -679,3 +669,32 @@ func GenerateCParameters(method ComponentDefinitionMethod, className string, Nam
 
 	return parameters, nil;
 }
+
+// getCCallbackParameters returns the parameters of a function pointer of a function type, and the names of the parameters
+func getCCallbackParameters(functiontype ComponentDefinitionFunctionType, NameSpace string) (string, []string, error) {
+	parameters := ""
+	names := []string{}
+	for j := 0; j < len(functiontype.Params); j++ {
+		param := functiontype.Params[j]
+
+		cParamTypeName, err := getCParameterTypeName(param.ParamType, NameSpace, param.ParamClass)
+		if err != nil {
+			return "", nil, err
+		}
+		cParams, err := generateCParameter(param, "", functiontype.FunctionName, NameSpace)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if parameters != "" {
+			parameters = parameters + ", "
+		}
+		if param.ParamPass == "in" {
+			parameters = parameters + cParamTypeName + " " + cParams[0].ParamName
+		} else {
+			parameters = parameters + cParamTypeName + "* " + cParams[0].ParamName
+		}
+		names = append(names, cParams[0].ParamName)
+	}
+	return parameters, names, nil
+}
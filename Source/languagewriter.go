@@ -34,6 +34,7 @@ SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -67,11 +68,10 @@ func (writer *LanguageWriter) ResetIndentationLevel () (error) {
 	return nil
 }
 
-// Writeln formats a string and writes it to a line. Pairs of leadin spaces will be replaced by the indent IndentString.
-func (writer *LanguageWriter) Writeln (format string, a ...interface{}) (int, error) {	
-
+// indentLine replaces pairs of leading spaces of a line by the IndentString and adds the current indentation
+func (writer *LanguageWriter) indentLine (line string) (string) {
 	leadingSpaces := 0;
-	for _, rune := range format {
+	for _, rune := range line {
 		if rune == ' ' {
 			leadingSpaces = leadingSpaces + 1;
 		} else {
@@ -80,10 +80,36 @@ func (writer *LanguageWriter) Writeln (format string, a ...interface{}) (int, er
 	}
 	leadingIndents := leadingSpaces / 2;
 	
-	indentedFormat := strings.Repeat (writer.IndentString, leadingIndents + writer.Indentation) + format[leadingIndents * 2:];
+	return strings.Repeat (writer.IndentString, leadingIndents + writer.Indentation) + line[leadingIndents * 2:];
+}
+
+// Writeln formats a string and writes it to a line. Pairs of leadin spaces will be replaced by the indent IndentString.
+func (writer *LanguageWriter) Writeln (format string, a ...interface{}) (int, error) {	
+	indentedFormat := writer.indentLine(format);
 	return fmt.Fprintf (writer.Writer, indentedFormat + "\n", a...);
 }
 
+// WriteTemplate executes a template of the generated code and writes its output line by line. Pairs of leading spaces will be replaced by the indent IndentString.
+func (writer *LanguageWriter) WriteTemplate (name string, data TemplateData) (error) {
+	var buffer bytes.Buffer
+	err := WriteTemplate(&buffer, name, data)
+	if err != nil {
+		return err;
+	}
+
+	lines := strings.Split(buffer.String(), "\n")
+	if (lines[len(lines) - 1] == "") {
+		lines = lines[:len(lines) - 1]
+	}
+	for _, line := range lines {
+		_, err = io.WriteString (writer.Writer, writer.indentLine(line) + "\n");
+		if err != nil {
+			return err;
+		}
+	}
+	return nil;
+}
+
 // Writelns writes multiple lines and processes indentation
 func (writer *LanguageWriter) Writelns (prefix string, lines []string) (error) {	
 	for idx := 0; idx < len(lines); idx++ {
@@ -113,68 +139,62 @@ func (writer *LanguageWriter) EndLine () (int, error) {
 
 
 // WriteCMakeLicenseHeader writes a license header into a writer with CMake-style comments
-func (writer *LanguageWriter) WriteCMakeLicenseHeader (component ComponentDefinition, abstract string, includeVersion bool) {
-	writeLicenseHeaderEx (writer.Writer, component, abstract, includeVersion, "#[[", "\n]]");
+func (writer *LanguageWriter) WriteCMakeLicenseHeader (component ComponentDefinition, abstract string, includeVersion bool) (error) {
+	return writeLicenseHeaderEx (writer.Writer, component, abstract, includeVersion, "#[[", "\n]]");
 }
 
 // WriteCLicenseHeader writes a license header into a writer with C-style comments
-func (writer *LanguageWriter) WriteCLicenseHeader (component ComponentDefinition, abstract string, includeVersion bool) {
-	writeLicenseHeaderEx (writer.Writer, component, abstract, includeVersion, "/*", "*/");
+func (writer *LanguageWriter) WriteCLicenseHeader (component ComponentDefinition, abstract string, includeVersion bool) (error) {
+	return writeLicenseHeaderEx (writer.Writer, component, abstract, includeVersion, "/*", "*/");
 }
 
 // WritePascalLicenseHeader writes a license header into a writer Pascal-style comments
-func (writer *LanguageWriter) WritePascalLicenseHeader (component ComponentDefinition, abstract string, includeVersion bool) {
-	writeLicenseHeaderEx (writer.Writer, component, abstract, includeVersion, "(*", "*)");
+func (writer *LanguageWriter) WritePascalLicenseHeader (component ComponentDefinition, abstract string, includeVersion bool) (error) {
+	return writeLicenseHeaderEx (writer.Writer, component, abstract, includeVersion, "(*", "*)");
 }
 
 // WritePythonLicenseHeader writes a license header into a writer Python-style comments
-func (writer *LanguageWriter) WritePythonLicenseHeader (component ComponentDefinition, abstract string, includeVersion bool) {
-	writeLicenseHeaderEx (writer.Writer, component, abstract, includeVersion, "'''", "'''");
+func (writer *LanguageWriter) WritePythonLicenseHeader (component ComponentDefinition, abstract string, includeVersion bool) (error) {
+	return writeLicenseHeaderEx (writer.Writer, component, abstract, includeVersion, "'''", "'''");
+}
+
+// WriteFortranLicenseHeader writes a license header into a writer with Fortran-style comments
+func (writer *LanguageWriter) WriteFortranLicenseHeader (component ComponentDefinition, abstract string, includeVersion bool) (error) {
+	var buffer bytes.Buffer
+	err := writeLicenseHeaderEx (&buffer, component, abstract, includeVersion, "", "");
+	if (err != nil) {
+		return err;
+	}
+
+	lines := strings.Split(strings.TrimRight(buffer.String(), "\n"), "\n")
+	for _, line := range lines {
+		_, err = io.WriteString (writer.Writer, strings.TrimRight("! " + line, " ") + "\n");
+		if (err != nil) {
+			return err;
+		}
+	}
+	return nil;
 }
 
 // WritePlainLicenseHeader writes a license header into a writer without comments
-func (writer *LanguageWriter) WritePlainLicenseHeader (component ComponentDefinition, abstract string, includeVersion bool) {
-	writeLicenseHeaderEx (writer.Writer, component, abstract, includeVersion, "", "");
+func (writer *LanguageWriter) WritePlainLicenseHeader (component ComponentDefinition, abstract string, includeVersion bool) (error) {
+	return writeLicenseHeaderEx (writer.Writer, component, abstract, includeVersion, "", "");
 }
 
 // WriteLicenseHeader writes a license header into a writer with C-style comments
-func WriteLicenseHeader (w io.Writer, component ComponentDefinition, abstract string, includeVersion bool) {
-	writeLicenseHeaderEx (w, component, abstract, includeVersion, "/*", "*/");
+func WriteLicenseHeader (w io.Writer, component ComponentDefinition, abstract string, includeVersion bool) (error) {
+	return writeLicenseHeaderEx (w, component, abstract, includeVersion, "/*", "*/");
 }
 
 // writeLicenseHeaderEx writes a license header into a writer.
-func writeLicenseHeaderEx (w io.Writer, component ComponentDefinition, abstract string, includeVersion bool, CommandStart string, CommandEnd string) {
-	ACTVersion := component.ACTVersion;
-	version := component.Version;
-	copyright := component.Copyright;
-	year := component.Year;
-
-	if (len(CommandStart) > 0) {
-		fmt.Fprintf (w, "%s++\n", CommandStart);
-		fmt.Fprintf (w, "\n");
-	}
-	fmt.Fprintf (w, "Copyright (C) %d %s\n", year, copyright);
-	fmt.Fprintf (w, "\n");
-	for i := 0; i < len(component.License.Lines); i++ {
-		line := component.License.Lines[i];
-		fmt.Fprintf (w, "%s\n", line.Value);
-	}
-	fmt.Fprintf (w, "\n");
-	if (includeVersion) {
-		fmt.Fprintf (w, "This file has been generated by the Automatic Component Toolkit (ACT) version %s.\n", ACTVersion)
-		fmt.Fprintf (w, "\n");
-	}
-	if (len(abstract) > 0 ) {
-		fmt.Fprintf (w, "Abstract: %s\n", abstract);
-		if (includeVersion) {
-			fmt.Fprintf (w, "\nInterface version: %d.%d.%d\n", majorVersion(version), minorVersion(version), microVersion(version))
-		}
-	}
-	fmt.Fprintf (w, "\n");
-	if (len(CommandEnd) > 0) {
-		fmt.Fprintf (w, "%s\n", CommandEnd);
-	fmt.Fprintf (w, "\n");
-	}
+func writeLicenseHeaderEx (w io.Writer, component ComponentDefinition, abstract string, includeVersion bool, CommandStart string, CommandEnd string) (error) {
+	data := NewTemplateData(component)
+	data.Abstract = abstract
+	data.IncludeVersion = includeVersion
+	data.CommentStart = CommandStart
+	data.CommentEnd = CommandEnd
+
+	return WriteTemplate(w, "licenseheader", data)
 }
 
 
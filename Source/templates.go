@@ -0,0 +1,137 @@
+/*++
+
+Copyright (C) 2018 Autodesk Inc. (Original Author)
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+--*/
+
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+// templates.go
+// Templates of the generated code, which are built into ACT and can be overridden by the user
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var defaultTemplateFiles embed.FS
+
+var codeTemplates *template.Template
+
+// TemplateData contains the data the templates of the generated code are executed with
+type TemplateData struct {
+	Component ComponentDefinition
+	NameSpace string
+	LibraryName string
+	BaseName string
+	BindingFolder string
+	PackageName string
+	DoJournal bool
+	HasAsyncMethods bool
+	HeaderOnly bool
+	Cpp17 bool
+	Abstract string
+	IncludeVersion bool
+	CommentStart string
+	CommentEnd string
+}
+
+// NewTemplateData creates the data to execute the templates of the generated code of a component with
+func NewTemplateData(component ComponentDefinition) (TemplateData) {
+	var data TemplateData
+	data.Component = component
+	data.NameSpace = component.NameSpace
+	data.LibraryName = component.LibraryName
+	data.BaseName = component.BaseName
+	data.DoJournal = len(component.Global.JournalMethod) > 0
+	data.HasAsyncMethods = component.HasAsyncMethods()
+	return data
+}
+
+func templateFunctions() (template.FuncMap) {
+	return template.FuncMap {
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"majorVersion": majorVersion,
+		"minorVersion": minorVersion,
+		"microVersion": microVersion,
+	}
+}
+
+// LoadTemplates parses the built-in templates and overrides them by the templates defined in the *.tmpl-files of a folder
+func LoadTemplates(overrideFolder string) (error) {
+	templates, err := template.New("act").Funcs(templateFunctions()).ParseFS(defaultTemplateFiles, "templates/*.tmpl")
+	if (err != nil) {
+		return err
+	}
+
+	if (overrideFolder != "") {
+		fileNames, err := filepath.Glob(filepath.Join(overrideFolder, "*.tmpl"))
+		if (err != nil) {
+			return err
+		}
+		if (len(fileNames) == 0) {
+			return fmt.Errorf ("no templates found in \"%s\"", overrideFolder)
+		}
+		for _, fileName := range fileNames {
+			log.Printf("Loading templates from \"%s\"", fileName)
+			templates, err = templates.ParseFiles(fileName)
+			if (err != nil) {
+				return err
+			}
+		}
+	}
+
+	codeTemplates = templates
+	return nil
+}
+
+// WriteTemplate executes a template of the generated code and writes its output into a writer
+func WriteTemplate(w io.Writer, name string, data TemplateData) (error) {
+	if (codeTemplates == nil) {
+		err := LoadTemplates("")
+		if (err != nil) {
+			return err
+		}
+	}
+
+	var buffer bytes.Buffer
+	err := codeTemplates.ExecuteTemplate(&buffer, name, data)
+	if (err != nil) {
+		return err
+	}
+	_, err = w.Write(buffer.Bytes())
+	return err
+}
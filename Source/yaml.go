@@ -0,0 +1,497 @@
+/*++
+
+Copyright (C) 2018 Autodesk Inc. (Original Author)
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+--*/
+
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+// yaml.go
+// Reads and writes the subset of YAML that is needed for component definitions.
+// Supported are block mappings, block sequences, plain and quoted scalars, comments and the empty flow collections "[]" and "{}".
+// The keys of a mapping are the json-tags of the fields of the corresponding go struct.
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	eYAMLNodeNull = 0
+	eYAMLNodeScalar = 1
+	eYAMLNodeMapping = 2
+	eYAMLNodeSequence = 3
+)
+
+type yamlNode struct {
+	kind int
+	value string
+	keys []string
+	children []*yamlNode
+	line int
+}
+
+type yamlLine struct {
+	indent int
+	text string
+	number int
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos int
+}
+
+// UnmarshalYAML parses a YAML document into the struct that value points to
+func UnmarshalYAML(data []byte, value interface{}) (error) {
+	lines, err := splitYAMLLines(string(data))
+	if (err != nil) {
+		return err
+	}
+	parser := yamlParser{lines: lines}
+	node := &yamlNode{kind: eYAMLNodeNull}
+	if (len(lines) > 0) {
+		node, err = parser.parseNode(lines[0].indent)
+		if (err != nil) {
+			return err
+		}
+		if (parser.pos < len(lines)) {
+			return fmt.Errorf ("yaml: invalid indentation in line %d", lines[parser.pos].number)
+		}
+	}
+	return decodeYAMLNode(node, reflect.ValueOf(value).Elem())
+}
+
+// MarshalYAML writes a struct as YAML document. Empty strings, empty lists and empty structs are omitted
+func MarshalYAML(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	err := encodeYAMLStruct(&buffer, reflect.ValueOf(value), 0, false)
+	if (err != nil) {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func splitYAMLLines(document string) ([]yamlLine, error) {
+	lines := make([]yamlLine, 0)
+	for index, text := range strings.Split(strings.Replace(document, "\r\n", "\n", -1), "\n") {
+		text = strings.TrimRight(stripYAMLComment(text), " \t")
+		trimmed := strings.TrimLeft(text, " ")
+		if (trimmed == "") || (trimmed == "---") || (trimmed == "...") {
+			continue
+		}
+		if (trimmed[0] == '\t') {
+			return nil, fmt.Errorf ("yaml: tabs must not be used for indentation in line %d", index + 1)
+		}
+		lines = append(lines, yamlLine{indent: len(text) - len(trimmed), text: trimmed, number: index + 1})
+	}
+	return lines, nil
+}
+
+func stripYAMLComment(text string) (string) {
+	quote := byte(0)
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if (quote != 0) {
+			if (c == '\\') && (quote == '"') {
+				i++
+			} else if (c == quote) {
+				quote = 0
+			}
+			continue
+		}
+		startOfToken := (i == 0) || (text[i-1] == ' ')
+		if (c == '#') && startOfToken {
+			return text[:i]
+		}
+		if ((c == '"') || (c == '\'')) && startOfToken {
+			quote = c
+		}
+	}
+	return text
+}
+
+func isYAMLSequenceItem(text string) (bool) {
+	return (text == "-") || strings.HasPrefix(text, "- ")
+}
+
+// splitYAMLKey returns the key and the remaining value of a line of a mapping
+func splitYAMLKey(text string) (string, string, bool) {
+	if (text[0] == '"') || (text[0] == '\'') {
+		end := 1
+		for ; end < len(text); end++ {
+			if (text[end] == '\\') && (text[0] == '"') {
+				end++
+			} else if (text[end] == text[0]) {
+				break
+			}
+		}
+		if (end >= len(text) - 1) || (text[end+1] != ':') {
+			return "", "", false
+		}
+		if (end + 2 < len(text)) && (text[end+2] != ' ') {
+			return "", "", false
+		}
+		key, err := parseYAMLQuotedScalar(text[:end+1])
+		if (err != nil) {
+			return "", "", false
+		}
+		return key, strings.TrimSpace(text[end+2:]), true
+	}
+
+	index := strings.Index(text, ": ")
+	if (index < 0) {
+		if strings.HasSuffix(text, ":") {
+			return text[:len(text)-1], "", true
+		}
+		return "", "", false
+	}
+	return text[:index], strings.TrimSpace(text[index+2:]), true
+}
+
+func (parser *yamlParser) parseNode(indent int) (*yamlNode, error) {
+	line := parser.lines[parser.pos]
+	if isYAMLSequenceItem(line.text) {
+		return parser.parseSequence(indent)
+	}
+	if _, _, ok := splitYAMLKey(line.text); ok {
+		return parser.parseMapping(indent)
+	}
+	parser.pos++
+	return parseYAMLScalar(line.text, line.number)
+}
+
+func (parser *yamlParser) parseSequence(indent int) (*yamlNode, error) {
+	node := &yamlNode{kind: eYAMLNodeSequence, line: parser.lines[parser.pos].number}
+	for (parser.pos < len(parser.lines)) && (parser.lines[parser.pos].indent == indent) && isYAMLSequenceItem(parser.lines[parser.pos].text) {
+		line := parser.lines[parser.pos]
+		content := strings.TrimLeft(line.text[1:], " ")
+		var item *yamlNode
+		var err error
+		if (content == "") {
+			parser.pos++
+			item, err = parser.parseChild(indent, line.number)
+		} else {
+			// the content of the item is parsed as if it started in a line of its own
+			parser.lines[parser.pos] = yamlLine{indent: indent + len(line.text) - len(content), text: content, number: line.number}
+			item, err = parser.parseNode(parser.lines[parser.pos].indent)
+		}
+		if (err != nil) {
+			return nil, err
+		}
+		node.children = append(node.children, item)
+	}
+	if (parser.pos < len(parser.lines)) && (parser.lines[parser.pos].indent > indent) {
+		return nil, fmt.Errorf ("yaml: invalid indentation in line %d", parser.lines[parser.pos].number)
+	}
+	return node, nil
+}
+
+func (parser *yamlParser) parseMapping(indent int) (*yamlNode, error) {
+	node := &yamlNode{kind: eYAMLNodeMapping, line: parser.lines[parser.pos].number}
+	for (parser.pos < len(parser.lines)) && (parser.lines[parser.pos].indent == indent) && !isYAMLSequenceItem(parser.lines[parser.pos].text) {
+		line := parser.lines[parser.pos]
+		key, value, ok := splitYAMLKey(line.text)
+		if (!ok) {
+			return nil, fmt.Errorf ("yaml: expected \"key: value\" in line %d", line.number)
+		}
+		for _, existingKey := range node.keys {
+			if (existingKey == key) {
+				return nil, fmt.Errorf ("yaml: duplicate key \"%s\" in line %d", key, line.number)
+			}
+		}
+		parser.pos++
+
+		var child *yamlNode
+		var err error
+		if (value != "") {
+			child, err = parseYAMLScalar(value, line.number)
+		} else {
+			child, err = parser.parseChild(indent, line.number)
+		}
+		if (err != nil) {
+			return nil, err
+		}
+		node.keys = append(node.keys, key)
+		node.children = append(node.children, child)
+	}
+	if (parser.pos < len(parser.lines)) && (parser.lines[parser.pos].indent > indent) {
+		return nil, fmt.Errorf ("yaml: invalid indentation in line %d", parser.lines[parser.pos].number)
+	}
+	return node, nil
+}
+
+// parseChild parses the block below a mapping key or a sequence item without inline content.
+// Sequences may start at the same indentation as the key they belong to.
+func (parser *yamlParser) parseChild(indent int, lineNumber int) (*yamlNode, error) {
+	if (parser.pos < len(parser.lines)) {
+		next := parser.lines[parser.pos]
+		if (next.indent > indent) || ((next.indent == indent) && isYAMLSequenceItem(next.text) && !parser.isSequenceAt(indent)) {
+			return parser.parseNode(next.indent)
+		}
+	}
+	return &yamlNode{kind: eYAMLNodeNull, line: lineNumber}, nil
+}
+
+// isSequenceAt returns whether the line before the current one is an item of a sequence at the given indentation
+func (parser *yamlParser) isSequenceAt(indent int) (bool) {
+	if (parser.pos == 0) {
+		return false
+	}
+	previous := parser.lines[parser.pos - 1]
+	return (previous.indent == indent) && isYAMLSequenceItem(previous.text)
+}
+
+func parseYAMLScalar(text string, lineNumber int) (*yamlNode, error) {
+	node := &yamlNode{kind: eYAMLNodeScalar, line: lineNumber}
+	switch {
+		case (text == "~") || (text == "null"):
+			node.kind = eYAMLNodeNull
+		case (text == "[]"):
+			node.kind = eYAMLNodeSequence
+		case (text == "{}"):
+			node.kind = eYAMLNodeMapping
+		case (text[0] == '"') || (text[0] == '\''):
+			value, err := parseYAMLQuotedScalar(text)
+			if (err != nil) {
+				return nil, fmt.Errorf ("yaml: invalid quoted string in line %d", lineNumber)
+			}
+			node.value = value
+		case strings.ContainsAny(text[:1], "[{|>&*!%@`"):
+			return nil, fmt.Errorf ("yaml: unsupported value \"%s\" in line %d", text, lineNumber)
+		default:
+			node.value = text
+	}
+	return node, nil
+}
+
+func parseYAMLQuotedScalar(text string) (string, error) {
+	if (len(text) < 2) || (text[len(text)-1] != text[0]) {
+		return "", fmt.Errorf ("unterminated string")
+	}
+	if (text[0] == '\'') {
+		inner := text[1:len(text)-1]
+		if strings.Contains(strings.Replace(inner, "''", "", -1), "'") {
+			return "", fmt.Errorf ("unescaped quote")
+		}
+		return strings.Replace(inner, "''", "'", -1), nil
+	}
+	return strconv.Unquote(text)
+}
+
+func yamlFieldName(field reflect.StructField) (string) {
+	if (field.PkgPath != "") || (field.Type.Kind() == reflect.Interface) {
+		return ""
+	}
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if (name == "-") {
+		return ""
+	}
+	if (name == "") {
+		return field.Name
+	}
+	return name
+}
+
+func decodeYAMLNode(node *yamlNode, value reflect.Value) (error) {
+	if (node.kind == eYAMLNodeNull) {
+		value.Set(reflect.Zero(value.Type()))
+		return nil
+	}
+
+	switch (value.Kind()) {
+		case reflect.String:
+			if (node.kind != eYAMLNodeScalar) {
+				return fmt.Errorf ("yaml: expected a string in line %d", node.line)
+			}
+			value.SetString(node.value)
+
+		case reflect.Int:
+			if (node.kind != eYAMLNodeScalar) {
+				return fmt.Errorf ("yaml: expected an integer in line %d", node.line)
+			}
+			number, err := strconv.ParseInt(node.value, 0, 64)
+			if (err != nil) {
+				return fmt.Errorf ("yaml: invalid integer \"%s\" in line %d", node.value, node.line)
+			}
+			value.SetInt(number)
+
+		case reflect.Bool:
+			if (node.kind != eYAMLNodeScalar) {
+				return fmt.Errorf ("yaml: expected a boolean in line %d", node.line)
+			}
+			flag, err := strconv.ParseBool(node.value)
+			if (err != nil) {
+				return fmt.Errorf ("yaml: invalid boolean \"%s\" in line %d", node.value, node.line)
+			}
+			value.SetBool(flag)
+
+		case reflect.Slice:
+			if (node.kind != eYAMLNodeSequence) {
+				return fmt.Errorf ("yaml: expected a list in line %d", node.line)
+			}
+			slice := reflect.MakeSlice(value.Type(), len(node.children), len(node.children))
+			for i, child := range node.children {
+				err := decodeYAMLNode(child, slice.Index(i))
+				if (err != nil) {
+					return err
+				}
+			}
+			value.Set(slice)
+
+		case reflect.Struct:
+			if (node.kind != eYAMLNodeMapping) {
+				return fmt.Errorf ("yaml: expected a mapping in line %d", node.line)
+			}
+			for i, key := range node.keys {
+				found := false
+				for j := 0; j < value.NumField(); j++ {
+					if (yamlFieldName(value.Type().Field(j)) == key) {
+						err := decodeYAMLNode(node.children[i], value.Field(j))
+						if (err != nil) {
+							return err
+						}
+						found = true
+						break
+					}
+				}
+				if (!found) {
+					return fmt.Errorf ("yaml: unknown key \"%s\" in line %d", key, node.children[i].line)
+				}
+			}
+
+		default:
+			return fmt.Errorf ("yaml: unsupported type %s", value.Type())
+	}
+	return nil
+}
+
+func isEmptyYAMLValue(value reflect.Value) (bool) {
+	switch (value.Kind()) {
+		case reflect.String, reflect.Slice:
+			return value.Len() == 0
+		case reflect.Struct:
+			for i := 0; i < value.NumField(); i++ {
+				if (yamlFieldName(value.Type().Field(i)) != "") && !isEmptyYAMLValue(value.Field(i)) {
+					return false
+				}
+			}
+			return true
+	}
+	return false
+}
+
+func quoteYAMLScalar(text string) (string) {
+	if (text == "") || (text != strings.TrimSpace(text)) || strings.ContainsAny(text[:1], "-?:,[]{}#&*!|>'\"%@`~") {
+		return strconv.Quote(text)
+	}
+	if strings.Contains(text, ": ") || strings.Contains(text, " #") || strings.HasSuffix(text, ":") {
+		return strconv.Quote(text)
+	}
+	for _, c := range text {
+		if (c < ' ') || (c == 0x7f) {
+			return strconv.Quote(text)
+		}
+	}
+	switch (strings.ToLower(text)) {
+		case "true", "false", "yes", "no", "on", "off", "y", "n", "null":
+			return strconv.Quote(text)
+	}
+	if _, err := strconv.ParseFloat(text, 64); err == nil {
+		return strconv.Quote(text)
+	}
+	return text
+}
+
+func encodeYAMLStruct(buffer *bytes.Buffer, value reflect.Value, indent int, sequenceItem bool) (error) {
+	first := true
+	for i := 0; i < value.NumField(); i++ {
+		name := yamlFieldName(value.Type().Field(i))
+		field := value.Field(i)
+		if (name == "") || ((field.Kind() != reflect.Int) && (field.Kind() != reflect.Bool) && isEmptyYAMLValue(field)) {
+			continue
+		}
+		// Flags, that are omitted in XML and JSON when they are false, are omitted in YAML as well
+		if (field.Kind() == reflect.Bool) && !field.Bool() && strings.Contains(value.Type().Field(i).Tag.Get("json"), ",omitempty") {
+			continue
+		}
+
+		prefix := strings.Repeat(" ", indent)
+		if (first && sequenceItem) {
+			prefix = strings.Repeat(" ", indent - 2) + "- "
+		}
+		first = false
+
+		switch (field.Kind()) {
+			case reflect.String:
+				fmt.Fprintf(buffer, "%s%s: %s\n", prefix, name, quoteYAMLScalar(field.String()))
+			case reflect.Int:
+				fmt.Fprintf(buffer, "%s%s: %d\n", prefix, name, field.Int())
+			case reflect.Bool:
+				fmt.Fprintf(buffer, "%s%s: %t\n", prefix, name, field.Bool())
+			case reflect.Struct:
+				fmt.Fprintf(buffer, "%s%s:\n", prefix, name)
+				err := encodeYAMLStruct(buffer, field, indent + 2, false)
+				if (err != nil) {
+					return err
+				}
+			case reflect.Slice:
+				fmt.Fprintf(buffer, "%s%s:\n", prefix, name)
+				for j := 0; j < field.Len(); j++ {
+					err := encodeYAMLSequenceItem(buffer, field.Index(j), indent + 2)
+					if (err != nil) {
+						return err
+					}
+				}
+			default:
+				return fmt.Errorf ("yaml: unsupported type %s", field.Type())
+		}
+	}
+	if (first && sequenceItem) {
+		fmt.Fprintf(buffer, "%s- {}\n", strings.Repeat(" ", indent - 2))
+	}
+	return nil
+}
+
+func encodeYAMLSequenceItem(buffer *bytes.Buffer, item reflect.Value, indent int) (error) {
+	prefix := strings.Repeat(" ", indent) + "- "
+	switch (item.Kind()) {
+		case reflect.String:
+			fmt.Fprintf(buffer, "%s%s\n", prefix, quoteYAMLScalar(item.String()))
+		case reflect.Int:
+			fmt.Fprintf(buffer, "%s%d\n", prefix, item.Int())
+		case reflect.Struct:
+			return encodeYAMLStruct(buffer, item, indent + 2, true)
+		default:
+			return fmt.Errorf ("yaml: unsupported type %s", item.Type())
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+/*++
+
+Copyright (C) 2018 Autodesk Inc. (Original Author)
+
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without modification,
+are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+list of conditions and the following disclaimer.
+2. Redistributions in binary form must reproduce the above copyright notice,
+this list of conditions and the following disclaimer in the documentation
+and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND
+ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED
+WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR
+ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES
+(INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES;
+LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND
+ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+(INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS
+SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+
+--*/
+
+
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+// yaml_test.go
+// Tests of the YAML reader and writer of component definitions
+//////////////////////////////////////////////////////////////////////////////////////////////////////
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type yamlTestItem struct {
+	Name string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Count int `json:"count"`
+	Enabled bool `json:"enabled,omitempty"`
+	Values []string `json:"values,omitempty"`
+	Children []yamlTestItem `json:"children,omitempty"`
+}
+
+func TestQuoteYAMLScalar(t *testing.T) {
+	tests := []struct {
+		text string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"two words", "two words"},
+		{"", "\"\""},
+		{" leading", "\" leading\""},
+		{"trailing ", "\"trailing \""},
+		{"- item", "\"- item\""},
+		{"key: value", "\"key: value\""},
+		{"ends with:", "\"ends with:\""},
+		{"text #comment", "\"text #comment\""},
+		{"#comment", "\"#comment\""},
+		{"'single'", "\"'single'\""},
+		{"\"double\"", "\"\\\"double\\\"\""},
+		{"true", "\"true\""},
+		{"No", "\"No\""},
+		{"null", "\"null\""},
+		{"42", "\"42\""},
+		{"1.5", "\"1.5\""},
+		{"line\nbreak", "\"line\\nbreak\""},
+		{"tab\there", "\"tab\\there\""},
+		{"a:b", "a:b"},
+		{"C++ #1", "\"C++ #1\""},
+	}
+	for _, test := range tests {
+		result := quoteYAMLScalar(test.text)
+		if (result != test.expected) {
+			t.Errorf("quoteYAMLScalar(%q) = %s, expected %s", test.text, result, test.expected)
+		}
+	}
+}
+
+func TestUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		document string
+		expected yamlTestItem
+	}{
+		{"plain", "name: item\ncount: 3\n", yamlTestItem{Name: "item", Count: 3}},
+		{"document markers", "---\nname: item\n...\n", yamlTestItem{Name: "item"}},
+		{"comments", "# a comment\nname: item # trailing comment\n", yamlTestItem{Name: "item"}},
+		{"hash in word", "name: C#\n", yamlTestItem{Name: "C#"}},
+		{"double quoted", "name: \"a: b # c\"\n", yamlTestItem{Name: "a: b # c"}},
+		{"single quoted", "name: 'it''s'\n", yamlTestItem{Name: "it's"}},
+		{"escapes", "name: \"tab\\tquote\\\"backslash\\\\unicode\\u00e9\"\n", yamlTestItem{Name: "tab\tquote\"backslash\\unicode\u00e9"}},
+		{"multiline description", "description: \"first line\\nsecond line\"\n", yamlTestItem{Description: "first line\nsecond line"}},
+		{"quoted key", "\"name\": item\n", yamlTestItem{Name: "item"}},
+		{"boolean", "enabled: true\n", yamlTestItem{Enabled: true}},
+		{"hexadecimal", "count: 0x10\n", yamlTestItem{Count: 16}},
+		{"null", "name: ~\ncount: null\n", yamlTestItem{}},
+		{"empty list", "values: []\n", yamlTestItem{Values: []string{}}},
+		{"empty list without value", "values:\nname: item\n", yamlTestItem{Name: "item"}},
+		{"list", "values:\n  - a\n  - \"b c\"\n", yamlTestItem{Values: []string{"a", "b c"}}},
+		{"list at key indentation", "values:\n- a\n- b\nname: item\n", yamlTestItem{Name: "item", Values: []string{"a", "b"}}},
+		{"nested mappings", "children:\n  - name: a\n    count: 1\n  - name: b\n    values:\n      - x\n", yamlTestItem{Children: []yamlTestItem{
+			{Name: "a", Count: 1}, {Name: "b", Values: []string{"x"}}}}},
+		{"empty mapping", "children:\n  - {}\n", yamlTestItem{Children: []yamlTestItem{{}}}},
+		{"windows line endings", "name: item\r\ncount: 2\r\n", yamlTestItem{Name: "item", Count: 2}},
+	}
+	for _, test := range tests {
+		var item yamlTestItem
+		err := UnmarshalYAML([]byte(test.document), &item)
+		if (err != nil) {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(item, test.expected) {
+			t.Errorf("%s: got %+v, expected %+v", test.name, item, test.expected)
+		}
+	}
+}
+
+func TestUnmarshalYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		document string
+	}{
+		{"unknown key", "unknown: 1\n"},
+		{"duplicate key", "name: a\nname: b\n"},
+		{"tab indentation", "children:\n\t- name: a\n"},
+		{"invalid indentation", "name: a\n  count: 1\n"},
+		{"unterminated string", "name: \"abc\n"},
+		{"invalid escape", "name: \"\\q\"\n"},
+		{"unescaped single quote", "name: 'it's'\n"},
+		{"block scalar", "description: |\n  text\n"},
+		{"flow sequence", "values: [a, b]\n"},
+		{"anchor", "name: &anchor item\n"},
+		{"invalid integer", "count: many\n"},
+		{"invalid boolean", "enabled: maybe\n"},
+		{"list instead of string", "name:\n  - a\n"},
+		{"string instead of list", "values: a\n"},
+		{"missing colon", "name\n"},
+	}
+	for _, test := range tests {
+		var item yamlTestItem
+		err := UnmarshalYAML([]byte(test.document), &item)
+		if (err == nil) {
+			t.Errorf("%s: expected an error, got %+v", test.name, item)
+		}
+	}
+}
+
+func TestMarshalYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		item yamlTestItem
+		expected string
+	}{
+		{"plain", yamlTestItem{Name: "item", Count: 3}, "name: item\ncount: 3\n"},
+		{"omitted false flag", yamlTestItem{Count: 1, Enabled: false}, "count: 1\n"},
+		{"flag", yamlTestItem{Enabled: true}, "count: 0\nenabled: true\n"},
+		{"empty list", yamlTestItem{Name: "item", Values: []string{}}, "name: item\ncount: 0\n"},
+		{"multiline description", yamlTestItem{Name: "item", Description: "first\nsecond"}, "name: item\ndescription: \"first\\nsecond\"\ncount: 0\n"},
+		{"list", yamlTestItem{Name: "item", Values: []string{"a", "true"}}, "name: item\ncount: 0\nvalues:\n  - a\n  - \"true\"\n"},
+		{"nested mappings", yamlTestItem{Name: "item", Children: []yamlTestItem{{Name: "a"}}}, "name: item\ncount: 0\nchildren:\n  - name: a\n    count: 0\n"},
+	}
+	for _, test := range tests {
+		output, err := MarshalYAML(test.item)
+		if (err != nil) {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if (string(output) != test.expected) {
+			t.Errorf("%s: got\n%s\nexpected\n%s", test.name, output, test.expected)
+		}
+	}
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	tests := []yamlTestItem{
+		{},
+		{Name: "item", Description: "line one\nline two\twith tab", Count: -1, Enabled: true},
+		{Name: "- not a list", Description: "key: value # not a comment", Values: []string{"", " padded ", "null", "3.14", "'quoted'"}},
+		{Name: "unicode \u00e4\u00f6\u00fc", Children: []yamlTestItem{{}, {Name: "child", Children: []yamlTestItem{{Name: "grandchild"}}}}},
+	}
+	for index, test := range tests {
+		output, err := MarshalYAML(test)
+		if (err != nil) {
+			t.Errorf("item %d: %v", index, err)
+			continue
+		}
+		var item yamlTestItem
+		err = UnmarshalYAML(output, &item)
+		if (err != nil) {
+			t.Errorf("item %d: %v\n%s", index, err, output)
+			continue
+		}
+		if !reflect.DeepEqual(item, test) {
+			t.Errorf("item %d: got %+v, expected %+v\n%s", index, item, test, output)
+		}
+	}
+}
+
+func TestYAMLRoundTripOfExamples(t *testing.T) {
+	fileNames, err := filepath.Glob(filepath.Join("..", "Examples", "*", "*.xml"))
+	if (err != nil) {
+		t.Fatal(err)
+	}
+	if (len(fileNames) == 0) {
+		t.Fatal("no example component definitions found")
+	}
+	for _, fileName := range fileNames {
+		data, err := ioutil.ReadFile(fileName)
+		if (err != nil) {
+			t.Errorf("%s: %v", fileName, err)
+			continue
+		}
+		component, err := UnmarshalComponentDefinition(data, eComponentDefinitionFormatXML)
+		if (err != nil) {
+			// e.g. the template of the versioning example is no valid XML
+			t.Logf("skipping %s: %v", fileName, err)
+			continue
+		}
+		expected, err := MarshalComponentDefinition(component, eComponentDefinitionFormatXML)
+		if (err != nil) {
+			t.Errorf("%s: %v", fileName, err)
+			continue
+		}
+
+		yamlDocument, err := MarshalComponentDefinition(component, eComponentDefinitionFormatYAML)
+		if (err != nil) {
+			t.Errorf("%s: %v", fileName, err)
+			continue
+		}
+		yamlComponent, err := UnmarshalComponentDefinition(yamlDocument, eComponentDefinitionFormatYAML)
+		if (err != nil) {
+			t.Errorf("%s: %v", fileName, err)
+			continue
+		}
+		result, err := MarshalComponentDefinition(yamlComponent, eComponentDefinitionFormatXML)
+		if (err != nil) {
+			t.Errorf("%s: %v", fileName, err)
+			continue
+		}
+		if (string(result) != string(expected)) {
+			t.Errorf("%s: the XML differs after a round-trip through YAML", fileName)
+		}
+	}
+}
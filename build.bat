@@ -1,6 +1,6 @@
 @echo off
 cd Source
-set Sources=actutils.go automaticcomponenttoolkit.go buildbindingcdynamic.go buildbindingcpp.go buildbindinggo.go buildbindingnode.go buildbindingpascal.go buildbindingpython.go buildimplementationcpp.go buildimplementationpascal.go componentdefinition.go languagewriter.go languagec.go languagepascal.go
+set Sources=actutils.go automaticcomponenttoolkit.go buildbindingcdynamic.go buildbindingcpp.go buildbindingcsharp.go buildbindingfortran.go buildbindinggo.go buildbindingjava.go buildbindinglua.go buildbindingnode.go buildbindingpascal.go buildbindingpython.go buildbindingrust.go buildbindingswift.go buildimplementationcpp.go buildimplementationfortran.go buildimplementationgo.go buildimplementationpascal.go componentdefinition.go componentdefinitionformats.go componentdefinitionschema.go componentdiff.go externalgenerator.go generators.go languagewriter.go languagec.go languagepascal.go templates.go yaml.go
 set GOOS=windows
 set GOARCH=amd64
 go build -o ..\act.exe %Sources%